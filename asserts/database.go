@@ -136,6 +136,10 @@ type DatabaseConfig struct {
 	Trusted []Assertion
 	// predefined assertions but that do not establish foundational trust
 	OtherPredefined []Assertion
+	// locally trusted set of assertions (account and account-key
+	// supported), established by the device administrator; their keys
+	// can only be used to sign validation-set assertions
+	LocalTrusted []Assertion
 	// backstore for assertions, left unset storing assertions will error
 	Backstore Backstore
 	// manager/backstore for keypairs, defaults to in-memory implementation
@@ -260,6 +264,8 @@ type Database struct {
 
 	trusted    Backstore
 	predefined Backstore
+	// locally trusted accounts and keys, they are also part of predefined
+	localTrusted Backstore
 	// all backstores to consider for find
 	backstores []Backstore
 	// backstores of dbs this was built on by stacking
@@ -312,6 +318,29 @@ func OpenDatabase(cfg *DatabaseConfig) (*Database, error) {
 		}
 	}
 
+	localTrustedBackstore := NewMemoryBackstore()
+
+	for _, a := range cfg.LocalTrusted {
+		var accountID string
+		switch accepted := a.(type) {
+		case *AccountKey:
+			accountID = accepted.AccountID()
+		case *Account:
+			accountID = accepted.AccountID()
+		default:
+			return nil, fmt.Errorf("cannot locally trust assertions that are not account-key or account: %s", a.Type().Name)
+		}
+		if _, err := trustedBackstore.Get(AccountType, []string{accountID}, AccountType.MaxSupportedFormat()); err == nil {
+			return nil, fmt.Errorf("cannot locally trust %v: account %q is already trusted", a.Ref(), accountID)
+		}
+		if err := localTrustedBackstore.Put(a.Type(), a); err != nil {
+			return nil, fmt.Errorf("cannot locally trust %v: %v", a.Ref(), err)
+		}
+		if err := otherPredefinedBackstore.Put(a.Type(), a); err != nil {
+			return nil, fmt.Errorf("cannot locally trust %v: %v", a.Ref(), err)
+		}
+	}
+
 	checkers := cfg.Checkers
 	if len(checkers) == 0 {
 		checkers = DefaultCheckers
@@ -320,10 +349,11 @@ func OpenDatabase(cfg *DatabaseConfig) (*Database, error) {
 	copy(dbCheckers, checkers)
 
 	return &Database{
-		bs:         bs,
-		keypairMgr: keypairMgr,
-		trusted:    trustedBackstore,
-		predefined: otherPredefinedBackstore,
+		bs:           bs,
+		keypairMgr:   keypairMgr,
+		trusted:      trustedBackstore,
+		predefined:   otherPredefinedBackstore,
+		localTrusted: localTrustedBackstore,
 		// order here is relevant, Find* precedence and
 		// findAccountKey depend on it, trusted should win over the
 		// general backstore!
@@ -346,13 +376,14 @@ func (db *Database) WithStackedBackstore(backstore Backstore) *Database {
 	backstores = append(backstores, backstore)
 	backstores = append(backstores, stackedOn...)
 	return &Database{
-		bs:         backstore,
-		keypairMgr: db.keypairMgr,
-		trusted:    db.trusted,
-		predefined: db.predefined,
-		backstores: backstores,
-		stackedOn:  stackedOn,
-		checkers:   db.checkers,
+		bs:           backstore,
+		keypairMgr:   db.keypairMgr,
+		trusted:      db.trusted,
+		predefined:   db.predefined,
+		localTrusted: db.localTrusted,
+		backstores:   backstores,
+		stackedOn:    stackedOn,
+		checkers:     db.checkers,
 	}
 }
 
@@ -424,6 +455,16 @@ func (db *Database) IsTrustedAccount(accountID string) bool {
 	return err == nil
 }
 
+// IsLocallyTrustedAccount returns whether the account is part of the
+// locally trusted set.
+func (db *Database) IsLocallyTrustedAccount(accountID string) bool {
+	if accountID == "" {
+		return false
+	}
+	_, err := db.localTrusted.Get(AccountType, []string{accountID}, AccountType.MaxSupportedFormat())
+	return err == nil
+}
+
 var timeNow = time.Now
 
 // SetEarliestTime affects how key expiration is checked.
@@ -460,6 +501,12 @@ func (db *Database) Check(assert Assertion) error {
 		if err != nil {
 			return fmt.Errorf("error finding matching public key for signature: %v", err)
 		}
+		if typ != ValidationSetType {
+			_, err := db.localTrusted.Get(AccountKeyType, []string{accKey.PublicKeyID()}, AccountKeyType.MaxSupportedFormat())
+			if err == nil {
+				return fmt.Errorf("cannot accept %s assertion signed by locally trusted key %q: only validation-set assertions are allowed", typ.Name, accKey.PublicKeyID())
+			}
+		}
 	} else {
 		if assert.AuthorityID() != "" {
 			return fmt.Errorf("internal error: %q assertion cannot have authority-id set", typ.Name)
@@ -107,6 +107,85 @@ func (opens *openSuite) TestOpenDatabaseTrustedWrongType(c *C) {
 	c.Assert(err, ErrorMatches, "cannot predefine trusted assertions that are not account-key or account: test-only")
 }
 
+func (opens *openSuite) TestOpenDatabaseLocalTrusted(c *C) {
+	localDB := assertstest.NewSigningDB("on-prem", testPrivKey1)
+	localAcct := assertstest.NewAccount(localDB, "on-prem", map[string]any{
+		"account-id": "on-prem",
+	}, "")
+	localAccKey := assertstest.NewAccountKey(localDB, localAcct, nil, testPrivKey1.PublicKey(), "")
+
+	cfg := &asserts.DatabaseConfig{
+		Backstore: asserts.NewMemoryBackstore(),
+		Trusted: []asserts.Assertion{
+			asserts.BootstrapAccountForTest("canonical"),
+			asserts.BootstrapAccountKeyForTest("canonical", testPrivKey0.PublicKey()),
+		},
+		LocalTrusted: []asserts.Assertion{localAcct, localAccKey},
+	}
+	db, err := asserts.OpenDatabase(cfg)
+	c.Assert(err, IsNil)
+
+	c.Check(db.IsLocallyTrustedAccount("on-prem"), Equals, true)
+	c.Check(db.IsTrustedAccount("on-prem"), Equals, false)
+	c.Check(db.IsLocallyTrustedAccount("canonical"), Equals, false)
+	c.Check(db.IsLocallyTrustedAccount(""), Equals, false)
+
+	// locally trusted accounts and keys are predefined
+	_, err = db.FindPredefined(asserts.AccountKeyType, map[string]string{
+		"public-key-sha3-384": testPrivKey1.PublicKey().ID(),
+	})
+	c.Check(err, IsNil)
+
+	vs, err := localDB.Sign(asserts.ValidationSetType, map[string]any{
+		"series":     "16",
+		"account-id": "on-prem",
+		"name":       "pinned",
+		"sequence":   "1",
+		"snaps": []any{
+			map[string]any{
+				"name":     "foo",
+				"id":       "fooididididididididididididididi",
+				"presence": "required",
+			},
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	c.Check(db.Add(vs), IsNil)
+
+	// other assertions cannot be signed with locally trusted keys
+	a, err := asserts.AssembleAndSignInTest(asserts.TestOnlyType, map[string]any{
+		"authority-id": "on-prem",
+		"primary-key":  "0",
+	}, nil, testPrivKey1)
+	c.Assert(err, IsNil)
+	err = db.Check(a)
+	c.Check(err, ErrorMatches, `cannot accept test-only assertion signed by locally trusted key ".*": only validation-set assertions are allowed`)
+}
+
+func (opens *openSuite) TestOpenDatabaseLocalTrustedErrors(c *C) {
+	a, err := asserts.AssembleAndSignInTest(asserts.TestOnlyType, map[string]any{
+		"authority-id": "canonical",
+		"primary-key":  "0",
+	}, nil, testPrivKey0)
+	c.Assert(err, IsNil)
+
+	_, err = asserts.OpenDatabase(&asserts.DatabaseConfig{
+		LocalTrusted: []asserts.Assertion{a},
+	})
+	c.Check(err, ErrorMatches, "cannot locally trust assertions that are not account-key or account: test-only")
+
+	_, err = asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Trusted: []asserts.Assertion{
+			asserts.BootstrapAccountForTest("canonical"),
+		},
+		LocalTrusted: []asserts.Assertion{
+			asserts.BootstrapAccountKeyForTest("canonical", testPrivKey1.PublicKey()),
+		},
+	})
+	c.Check(err, ErrorMatches, `cannot locally trust .*: account "canonical" is already trusted`)
+}
+
 type databaseSuite struct {
 	topDir string
 	db     *asserts.Database
@@ -453,6 +453,25 @@ func validateSnapName(name string, headerName string) error {
 	return nil
 }
 
+func checkFactoryResetPreserveData(headers map[string]any) ([]string, error) {
+	const name = "factory-reset-preserve-data"
+	snaps, err := checkStringList(headers, name)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(snaps))
+	for _, snapName := range snaps {
+		if err := validateSnapName(snapName, name); err != nil {
+			return nil, err
+		}
+		if seen[snapName] {
+			return nil, fmt.Errorf("cannot list the same snap %q multiple times in %q header", snapName, name)
+		}
+		seen[snapName] = true
+	}
+	return snaps, nil
+}
+
 func checkRequiredSnap(name string, headerName string, snapType string) (*ModelSnap, error) {
 	if err := validateSnapName(name, headerName); err != nil {
 		return nil, err
@@ -584,6 +603,8 @@ type Model struct {
 
 	storageSafety StorageSafety
 
+	factoryResetPreserveData []string
+
 	allSnaps []*ModelSnap
 	// consumers of this info should care only about snap identity =>
 	// snapRef
@@ -656,6 +677,13 @@ func (mod *Model) StorageSafety() StorageSafety {
 	return mod.storageSafety
 }
 
+// FactoryResetPreserveData returns the names of the snaps whose system data
+// is preserved across factory reset, in addition to the ones declared by the
+// gadget. Will be empty for Core 16/18 models.
+func (mod *Model) FactoryResetPreserveData() []string {
+	return mod.factoryResetPreserveData
+}
+
 // GadgetSnap returns the details of the gadget snap the model uses.
 func (mod *Model) GadgetSnap() *ModelSnap {
 	return mod.gadgetSnap
@@ -1075,6 +1103,7 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 	var modSnaps *modelSnaps
 	grade := ModelGradeUnset
 	storageSafety := StorageSafetyUnset
+	var factoryResetPreserveData []string
 	if extended {
 		gradeStr, err := checkOptionalString(assert.headers, "grade")
 		if err != nil {
@@ -1109,6 +1138,11 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 			return nil, fmt.Errorf(`secured grade model must not have storage-safety overridden, only "encrypted" is valid`)
 		}
 
+		factoryResetPreserveData, err = checkFactoryResetPreserveData(assert.headers)
+		if err != nil {
+			return nil, err
+		}
+
 		modSnaps, err = checkExtendedSnaps(extendedSnaps, base, grade, classic)
 		if err != nil {
 			return nil, err
@@ -1215,6 +1249,7 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		kernelSnap:                 modSnaps.kernel,
 		grade:                      grade,
 		storageSafety:              storageSafety,
+		factoryResetPreserveData:   factoryResetPreserveData,
 		allSnaps:                   allSnaps,
 		requiredWithEssentialSnaps: requiredWithEssentialSnaps,
 		numEssentialSnaps:          numEssentialSnaps,
@@ -1041,6 +1041,20 @@ func (mods *modelSuite) TestCore20ValidStorageSafety(c *C) {
 	}
 }
 
+func (mods *modelSuite) TestCore20FactoryResetPreserveData(c *C) {
+	encoded := strings.Replace(core20ModelExample, "TSLINE", mods.tsLine, 1)
+	encoded = strings.Replace(encoded, "OTHER", "", 1)
+
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.Model).FactoryResetPreserveData(), HasLen, 0)
+
+	encoded = strings.Replace(encoded, "grade: secured\n", "factory-reset-preserve-data:\n  - myapp\n  - other-app\ngrade: secured\n", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.Model).FactoryResetPreserveData(), DeepEquals, []string{"myapp", "other-app"})
+}
+
 func (mods *modelSuite) TestCore20DefaultStorageSafetySecured(c *C) {
 	encoded := strings.Replace(core20ModelExample, "TSLINE", mods.tsLine, 1)
 	encoded = strings.Replace(encoded, "OTHER", "", 1)
@@ -1119,6 +1133,9 @@ func (mods *modelSuite) testWithSnapsDecodeInvalid(c *C, modelRaw string, isClas
 		{"grade: secured\n", "grade: foo\n", `grade for model must be secured|signed|dangerous`},
 		{"storage-safety: encrypted\n", "storage-safety: foo\n", `storage-safety for model must be encrypted\|prefer-encrypted\|prefer-unencrypted, not "foo"`},
 		{"storage-safety: encrypted\n", "storage-safety: prefer-unencrypted\n", `secured grade model must not have storage-safety overridden, only "encrypted" is valid`},
+		{"OTHER", "factory-reset-preserve-data: myapp\n", `"factory-reset-preserve-data" header must be a list of strings`},
+		{"OTHER", "factory-reset-preserve-data:\n  - myapp_2\n", `invalid snap name in "factory-reset-preserve-data" header: myapp_2`},
+		{"OTHER", "factory-reset-preserve-data:\n  - myapp\n  - myapp\n", `cannot list the same snap "myapp" multiple times in "factory-reset-preserve-data" header`},
 	}
 	if isClassic {
 		classicInvalid := []struct{ original, invalid, expectedErr string }{
@@ -0,0 +1,105 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sysdb
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+)
+
+// LocalTrusted returns the account and account-key assertions that the
+// device administrator placed in the local trust directory. Their keys can
+// only sign validation-set assertions. Files that cannot be used are
+// skipped with a logged notice.
+func LocalTrusted() []asserts.Assertion {
+	return localTrustedAt(dirs.SnapAssertsLocalTrustDir)
+}
+
+func localTrustedAt(dir string) []asserts.Assertion {
+	files, err := filepath.Glob(filepath.Join(dir, "*.assert"))
+	if err != nil {
+		// the pattern is valid
+		return nil
+	}
+
+	trustedAccounts := make(map[string]bool)
+	for _, a := range Trusted() {
+		if acct, ok := a.(*asserts.Account); ok {
+			trustedAccounts[acct.AccountID()] = true
+		}
+	}
+
+	var local []asserts.Assertion
+	seen := make(map[string]bool)
+	for _, fn := range files {
+		as, err := readLocalTrusted(fn, trustedAccounts)
+		if err != nil {
+			logger.Noticef("cannot use locally trusted assertions from %s: %v", fn, err)
+			continue
+		}
+		for _, a := range as {
+			if k := a.Ref().Unique(); !seen[k] {
+				seen[k] = true
+				local = append(local, a)
+			}
+		}
+	}
+	return local
+}
+
+func readLocalTrusted(fn string, trustedAccounts map[string]bool) ([]asserts.Assertion, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var as []asserts.Assertion
+	dec := asserts.NewDecoder(f)
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var accountID string
+		switch x := a.(type) {
+		case *asserts.Account:
+			accountID = x.AccountID()
+		case *asserts.AccountKey:
+			accountID = x.AccountID()
+		default:
+			return nil, fmt.Errorf("unsupported %s assertion, expected account or account-key", a.Type().Name)
+		}
+		if trustedAccounts[accountID] {
+			return nil, fmt.Errorf("account %q is already trusted", accountID)
+		}
+		as = append(as, a)
+	}
+	return as, nil
+}
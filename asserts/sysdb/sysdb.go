@@ -40,11 +40,12 @@ func openDatabaseAt(path string, cfg *asserts.DatabaseConfig) (*asserts.Database
 }
 
 // OpenAt opens a system assertion database at the given location with
-// the trusted assertions set configured.
+// the trusted and locally trusted assertions sets configured.
 func OpenAt(path string) (*asserts.Database, error) {
 	cfg := &asserts.DatabaseConfig{
 		Trusted:         Trusted(),
 		OtherPredefined: Generic(),
+		LocalTrusted:    LocalTrusted(),
 	}
 	return openDatabaseAt(path, cfg)
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/snapcore/snapd/asserts/assertstest"
 	"github.com/snapcore/snapd/asserts/sysdb"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 )
 
 func TestSysDB(t *testing.T) { TestingT(t) }
@@ -192,6 +193,54 @@ func (sdbs *sysDBSuite) TestOpenSysDatabaseExtras(c *C) {
 	c.Check(err, IsNil)
 }
 
+func (sdbs *sysDBSuite) writeLocalTrust(c *C, name string, as ...asserts.Assertion) {
+	c.Assert(os.MkdirAll(dirs.SnapAssertsLocalTrustDir, 0755), IsNil)
+	var content []byte
+	for _, a := range as {
+		content = append(content, asserts.Encode(a)...)
+		content = append(content, '\n')
+	}
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapAssertsLocalTrustDir, name), content, 0644), IsNil)
+}
+
+func (sdbs *sysDBSuite) TestLocalTrusted(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	c.Check(sysdb.LocalTrusted(), HasLen, 0)
+
+	pk, _ := assertstest.GenerateKey(752)
+	localDB := assertstest.NewSigningDB("on-prem", pk)
+	localAcct := assertstest.NewAccount(localDB, "on-prem", map[string]any{
+		"account-id": "on-prem",
+	}, "")
+	localAccKey := assertstest.NewAccountKey(localDB, localAcct, nil, pk.PublicKey(), "")
+
+	sdbs.writeLocalTrust(c, "on-prem.assert", localAcct, localAccKey)
+	// duplicates are ignored
+	sdbs.writeLocalTrust(c, "on-prem-key.assert", localAccKey)
+	// files with other assertions are skipped
+	sdbs.writeLocalTrust(c, "model.assert", sdbs.otherModel)
+	// as are files for already trusted accounts
+	sdbs.writeLocalTrust(c, "canonical.assert", sysdb.Trusted()...)
+	// and files without the .assert extension
+	sdbs.writeLocalTrust(c, "README", sdbs.otherModel)
+
+	local := sysdb.LocalTrusted()
+	c.Assert(local, HasLen, 2)
+	// files are read in lexical order
+	c.Check(local[0].Ref(), DeepEquals, localAccKey.Ref())
+	c.Check(local[1].Ref(), DeepEquals, localAcct.Ref())
+
+	c.Check(logbuf.String(), Matches, `(?s).*cannot use locally trusted assertions from .*/canonical.assert: account "canonical" is already trusted.*`)
+	c.Check(logbuf.String(), Matches, `(?s).*cannot use locally trusted assertions from .*/model.assert: unsupported model assertion, expected account or account-key.*`)
+
+	db, err := sysdb.Open()
+	c.Assert(err, IsNil)
+	c.Check(db.IsLocallyTrustedAccount("on-prem"), Equals, true)
+	c.Check(db.IsTrustedAccount("on-prem"), Equals, false)
+}
+
 func (sdbs *sysDBSuite) TestOpenSysDatabaseBackstoreOpenFail(c *C) {
 	// make it not world-writeable
 	oldUmask := syscall.Umask(0)
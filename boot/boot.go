@@ -393,6 +393,7 @@ func MarkBootSuccessful(dev snap.Device) error {
 			trustedCommandLineBootState(dev),
 			recoverySystemsBootState(dev),
 			modelBootState(dev),
+			bootSlotBootState(dev),
 		} {
 			var err error
 			u, err = bs.markSuccessful(u)
@@ -313,6 +313,10 @@ func setupUC20Bootenv(c *C, bl bootloader.Bootloader, opts *bootenv20Setup) (res
 		cleanups = append(cleanups, r)
 		// don't count any calls to SetBootVars made thus far
 		vbl.SetBootVarsCalls = 0
+	case *bootloadertest.MockBootSlotBootloader:
+		r := setupUC20MockBootloaderEnv(c, bl, opts)
+		cleanups = append(cleanups, r)
+		vbl.SetBootVarsCalls = 0
 	default:
 		c.Fatalf("unsupported bootloader %T", bl)
 	}
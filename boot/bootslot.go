@@ -0,0 +1,172 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package boot
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/snap"
+)
+
+// ActiveBootSlot returns the A/B slot of the gadget structures the device
+// currently boots from.
+func ActiveBootSlot(dev snap.Device) (string, error) {
+	if !dev.HasModeenv() {
+		return "", fmt.Errorf("internal error: A/B boot slots are not supported on pre-UC20 devices")
+	}
+	modeenvLock()
+	defer modeenvUnlock()
+
+	m, err := loadModeenv()
+	if err != nil {
+		return "", err
+	}
+	return activeBootSlot(m), nil
+}
+
+// ErrBootSlotNotReady is returned by BootedSlot when a boot slot is being
+// tried and the boot was not marked successful yet.
+var ErrBootSlotNotReady = errors.New("boot slot is not ready yet")
+
+// BootedSlot returns the A/B slot the device booted from after a boot slot
+// was tried, that is either the tried slot when it was committed, or the
+// previously active slot when the bootloader fell back to it.
+func BootedSlot(dev snap.Device) (string, error) {
+	if !dev.HasModeenv() {
+		return "", fmt.Errorf("internal error: A/B boot slots are not supported on pre-UC20 devices")
+	}
+	modeenvLock()
+	defer modeenvUnlock()
+
+	m, err := loadModeenv()
+	if err != nil {
+		return "", err
+	}
+	if m.TryBootSlot != "" {
+		return "", ErrBootSlotNotReady
+	}
+	return activeBootSlot(m), nil
+}
+
+func activeBootSlot(m *Modeenv) string {
+	if m.CurrentBootSlot == "" {
+		return gadget.SlotA
+	}
+	return m.CurrentBootSlot
+}
+
+func findBootSlotBootloader() (bootloader.BootSlotBootloader, error) {
+	bl, err := bootloader.Find("", &bootloader.Options{
+		Role: bootloader.RoleRunMode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bsb, ok := bl.(bootloader.BootSlotBootloader)
+	if !ok {
+		return nil, fmt.Errorf("bootloader %q does not support A/B boot slots", bl.Name())
+	}
+	return bsb, nil
+}
+
+// SetTryBootSlot configures the next boot to try the given A/B slot, after
+// a gadget update has written the structures of that slot. The slot becomes
+// the active one once the boot is marked successful, otherwise the
+// bootloader falls back to the slot that was active before.
+func SetTryBootSlot(dev snap.Device, slot string) error {
+	if !dev.HasModeenv() {
+		return fmt.Errorf("internal error: A/B boot slots are not supported on pre-UC20 devices")
+	}
+	modeenvLock()
+	defer modeenvUnlock()
+
+	bsb, err := findBootSlotBootloader()
+	if err != nil {
+		return err
+	}
+	m, err := loadModeenv()
+	if err != nil {
+		return err
+	}
+	if slot == activeBootSlot(m) {
+		return fmt.Errorf("internal error: cannot try the active boot slot %q", slot)
+	}
+
+	// the modeenv is written first, a try slot that the bootloader does
+	// not know about is cleared when the boot is marked successful
+	m.TryBootSlot = slot
+	if err := m.Write(); err != nil {
+		return err
+	}
+	return bsb.EnableTryBootSlot(slot)
+}
+
+// bootState20BootSlot implements the successfulBootState interface for A/B
+// boot slots.
+type bootState20BootSlot struct {
+	dev snap.Device
+}
+
+func (bbs20 *bootState20BootSlot) markSuccessful(update bootStateUpdate) (bootStateUpdate, error) {
+	u20, err := toBootStateUpdate20(update)
+	if err != nil {
+		return nil, err
+	}
+
+	trySlot := u20.writeModeenv.TryBootSlot
+	if trySlot == "" {
+		// no slot is being tried
+		return u20, nil
+	}
+
+	bsb, err := findBootSlotBootloader()
+	if err != nil {
+		return nil, fmt.Errorf("cannot mark successful boot slot: %v", err)
+	}
+	current, try, status, err := bsb.BootSlots()
+	if err != nil {
+		return nil, fmt.Errorf("cannot mark successful boot slot: %v", err)
+	}
+
+	switch {
+	case status == TryingStatus && try == trySlot:
+		// the slot was tried and we got this far, commit it in the
+		// bootloader before the modeenv
+		u20.preModeenv(bsb.CommitTryBootSlot)
+		u20.writeModeenv.CurrentBootSlot = trySlot
+	case current == trySlot:
+		// the bootloader committed the slot already
+		u20.writeModeenv.CurrentBootSlot = trySlot
+	default:
+		// the bootloader fell back to the previously active slot
+		logger.Noticef("boot slot %q was not booted successfully, using slot %q", trySlot, activeBootSlot(u20.writeModeenv))
+		u20.preModeenv(bsb.DisableTryBootSlot)
+	}
+	u20.writeModeenv.TryBootSlot = ""
+	return u20, nil
+}
+
+func bootSlotBootState(dev snap.Device) *bootState20BootSlot {
+	return &bootState20BootSlot{dev: dev}
+}
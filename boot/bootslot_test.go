@@ -0,0 +1,203 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package boot_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/boot/boottest"
+	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/bootloader/bootloadertest"
+)
+
+type bootSlotSuite struct {
+	baseBootenv20Suite
+
+	bootloader *bootloadertest.MockBootSlotBootloader
+}
+
+var _ = Suite(&bootSlotSuite{})
+
+func (s *bootSlotSuite) SetUpTest(c *C) {
+	s.baseBootenv20Suite.SetUpTest(c)
+
+	s.bootloader = bootloadertest.Mock("mock", c.MkDir()).WithBootSlots()
+	s.forceBootloader(s.bootloader)
+}
+
+func (s *bootSlotSuite) setupModeenv(c *C, currentSlot, trySlot string) {
+	m := &boot.Modeenv{
+		Mode:            "run",
+		Base:            s.base1.Filename(),
+		CurrentKernels:  []string{s.kern1.Filename()},
+		CurrentBootSlot: currentSlot,
+		TryBootSlot:     trySlot,
+	}
+	r := setupUC20Bootenv(c, s.bootloader, &bootenv20Setup{
+		modeenv:    m,
+		kern:       s.kern1,
+		kernStatus: boot.DefaultStatus,
+	})
+	s.AddCleanup(r)
+}
+
+func (s *bootSlotSuite) checkSlots(c *C, current, try string, blVars map[string]string) {
+	m, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	c.Check(m.CurrentBootSlot, Equals, current)
+	c.Check(m.TryBootSlot, Equals, try)
+
+	vars, err := s.bootloader.GetBootVars("snapd_boot_slot", "snapd_try_boot_slot", "boot_slot_status")
+	c.Assert(err, IsNil)
+	c.Check(vars, DeepEquals, blVars)
+}
+
+func (s *bootSlotSuite) TestActiveBootSlot(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+
+	s.setupModeenv(c, "", "")
+	slot, err := boot.ActiveBootSlot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(slot, Equals, "a")
+
+	s.setupModeenv(c, "b", "")
+	slot, err = boot.ActiveBootSlot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(slot, Equals, "b")
+
+	_, err = boot.ActiveBootSlot(boottest.MockDevice("some-snap"))
+	c.Assert(err, ErrorMatches, "internal error: A/B boot slots are not supported on pre-UC20 devices")
+}
+
+func (s *bootSlotSuite) TestBootedSlot(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+
+	// the boot was not marked successful yet
+	s.setupModeenv(c, "", "b")
+	_, err := boot.BootedSlot(coreDev)
+	c.Assert(err, Equals, boot.ErrBootSlotNotReady)
+
+	// the tried slot was committed
+	s.setupModeenv(c, "b", "")
+	slot, err := boot.BootedSlot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(slot, Equals, "b")
+
+	// the bootloader fell back
+	s.setupModeenv(c, "", "")
+	slot, err = boot.BootedSlot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(slot, Equals, "a")
+
+	_, err = boot.BootedSlot(boottest.MockDevice("some-snap"))
+	c.Assert(err, ErrorMatches, "internal error: A/B boot slots are not supported on pre-UC20 devices")
+}
+
+func (s *bootSlotSuite) TestSetTryBootSlot(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+	s.setupModeenv(c, "", "")
+
+	err := boot.SetTryBootSlot(coreDev, "a")
+	c.Assert(err, ErrorMatches, `internal error: cannot try the active boot slot "a"`)
+
+	err = boot.SetTryBootSlot(coreDev, "b")
+	c.Assert(err, IsNil)
+	s.checkSlots(c, "", "b", map[string]string{
+		"snapd_boot_slot":     "",
+		"snapd_try_boot_slot": "b",
+		"boot_slot_status":    "try",
+	})
+}
+
+func (s *bootSlotSuite) TestSetTryBootSlotUnsupportedBootloader(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+	s.setupModeenv(c, "", "")
+	bootloader.Force(bootloadertest.Mock("other", c.MkDir()))
+
+	err := boot.SetTryBootSlot(coreDev, "b")
+	c.Assert(err, ErrorMatches, `bootloader "other" does not support A/B boot slots`)
+}
+
+func (s *bootSlotSuite) TestMarkBootSuccessfulCommitsTriedSlot(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+	s.setupModeenv(c, "a", "b")
+	// the boot script moved the status along when booting the slot
+	c.Assert(s.bootloader.SetBootVars(map[string]string{
+		"snapd_boot_slot":     "a",
+		"snapd_try_boot_slot": "b",
+		"boot_slot_status":    "trying",
+	}), IsNil)
+
+	err := boot.MarkBootSuccessful(coreDev)
+	c.Assert(err, IsNil)
+	s.checkSlots(c, "b", "", map[string]string{
+		"snapd_boot_slot":     "b",
+		"snapd_try_boot_slot": "",
+		"boot_slot_status":    "",
+	})
+
+	// and again, nothing changes
+	err = boot.MarkBootSuccessful(coreDev)
+	c.Assert(err, IsNil)
+	s.checkSlots(c, "b", "", map[string]string{
+		"snapd_boot_slot":     "b",
+		"snapd_try_boot_slot": "",
+		"boot_slot_status":    "",
+	})
+}
+
+func (s *bootSlotSuite) TestMarkBootSuccessfulFallback(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+	s.setupModeenv(c, "a", "b")
+	// the boot script found the status at trying and fell back
+	c.Assert(s.bootloader.SetBootVars(map[string]string{
+		"snapd_boot_slot":     "a",
+		"snapd_try_boot_slot": "b",
+		"boot_slot_status":    "",
+	}), IsNil)
+
+	err := boot.MarkBootSuccessful(coreDev)
+	c.Assert(err, IsNil)
+	s.checkSlots(c, "a", "", map[string]string{
+		"snapd_boot_slot":     "a",
+		"snapd_try_boot_slot": "",
+		"boot_slot_status":    "",
+	})
+}
+
+func (s *bootSlotSuite) TestMarkBootSuccessfulAlreadyCommitted(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+	s.setupModeenv(c, "a", "b")
+	// the bootloader was updated but not the modeenv
+	c.Assert(s.bootloader.SetBootVars(map[string]string{
+		"snapd_boot_slot":     "b",
+		"snapd_try_boot_slot": "",
+		"boot_slot_status":    "",
+	}), IsNil)
+
+	err := boot.MarkBootSuccessful(coreDev)
+	c.Assert(err, IsNil)
+	s.checkSlots(c, "b", "", map[string]string{
+		"snapd_boot_slot":     "b",
+		"snapd_try_boot_slot": "",
+		"boot_slot_status":    "",
+	})
+}
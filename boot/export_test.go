@@ -70,6 +70,8 @@ var (
 	BootVarsForTrustedCommandLineFromGadget = bootVarsForTrustedCommandLineFromGadget
 
 	WriteModelToUbuntuBoot = writeModelToUbuntuBoot
+
+	KeyfileKeyProtectorFactory = keyfileKeyProtectorFactory
 )
 
 type BootAssetsMap = bootAssetsMap
@@ -169,8 +169,8 @@ func updateNotScriptableBootloaderStatus(bl bootloader.NotScriptableBootloader)
 }
 
 // InitramfsRunModeUpdateBootloaderVars updates bootloader variables
-// from the initramfs. This is necessary only for piboot at the
-// moment.
+// from the initramfs. This is necessary only for piboot and
+// systemd-boot at the moment.
 func InitramfsRunModeUpdateBootloaderVars() error {
 	// For very limited bootloaders we need to change the kernel
 	// status from the initramfs as we cannot do that from the
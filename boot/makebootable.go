@@ -639,6 +639,9 @@ func makeRunnableSystem(model *asserts.Model, bootWith *BootableSet, observer Tr
 
 	if observer != nil && observerImpl.useEncryption {
 		protector, err := HookKeyProtectorFactory(bootWith.Kernel)
+		if errors.Is(err, secboot.ErrNoKeyProtector) {
+			protector, err = keyfileKeyProtectorFactory(bootWith.UnpackedGadgetDir, model)
+		}
 		if err != nil && !errors.Is(err, secboot.ErrNoKeyProtector) {
 			return fmt.Errorf("cannot check for fde-setup hook key protector: %v", err)
 		}
@@ -734,6 +737,20 @@ func buildOptionalKernelCommandLine(model *asserts.Model, gadgetSnapOrDir string
 	return cmdlineAppend, nil
 }
 
+// keyfileKeyProtectorFactory returns a key protector factory using the
+// keyfile declared by the gadget, or secboot.ErrNoKeyProtector if the gadget
+// does not declare one.
+func keyfileKeyProtectorFactory(gadgetDir string, model *asserts.Model) (secboot.KeyProtectorFactory, error) {
+	info, err := gadget.ReadInfo(gadgetDir, model)
+	if err != nil {
+		return nil, err
+	}
+	if info.FDEKeyfile == nil {
+		return nil, secboot.ErrNoKeyProtector
+	}
+	return secboot.KeyfileKeyProtectorFactory(*info.FDEKeyfile), nil
+}
+
 // MakeRunnableSystem is like MakeBootableImage in that it sets up a system to
 // be able to boot, but is unique in that it is intended to be called from UC20
 // install mode and makes the run system bootable (hence it is called
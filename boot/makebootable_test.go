@@ -2521,3 +2521,24 @@ func (s *makeBootable20Suite) TestMakeBootableImageOptionalKernelArgsSignedAndDa
 	// The option is ignored if non-dangerous model
 	s.testMakeBootableImageOptionalKernelArgs(c, model, options, "", "")
 }
+
+func (s *makeBootable20Suite) TestKeyfileKeyProtectorFactory(c *C) {
+	model := boottest.MakeMockUC20Model()
+
+	unpackedGadgetDir := c.MkDir()
+	snaptest.PopulateDir(unpackedGadgetDir, [][]string{
+		{"meta/snap.yaml", gadgetSnapYaml},
+		{"meta/gadget.yaml", gadgetYaml},
+	})
+	_, err := boot.KeyfileKeyProtectorFactory(unpackedGadgetDir, model)
+	c.Check(err, Equals, secboot.ErrNoKeyProtector)
+
+	snaptest.PopulateDir(unpackedGadgetDir, [][]string{
+		{"meta/gadget.yaml", gadgetYaml + "fde-keyfile:\n  filesystem-label: fde-key\n"},
+	})
+	kpf, err := boot.KeyfileKeyProtectorFactory(unpackedGadgetDir, model)
+	c.Assert(err, IsNil)
+	c.Check(secboot.ProtectorKeyfileLocation(kpf), DeepEquals, &device.KeyfileLocation{
+		FilesystemLabel: "fde-key",
+	})
+}
@@ -104,6 +104,13 @@ type Modeenv struct {
 	CurrentKernelCommandLines bootCommandLines `key:"current_kernel_command_lines"`
 	// TODO:UC20 add a per recovery system list of kernel command lines
 
+	// CurrentBootSlot is the A/B slot of the gadget structures that the
+	// system boots from, an unset value means slot "a".
+	CurrentBootSlot string `key:"current_boot_slot"`
+	// TryBootSlot is the A/B slot that was written by a gadget update and
+	// is being tried, it is cleared once the boot is marked successful.
+	TryBootSlot string `key:"try_boot_slot"`
+
 	// read is set to true when a modenv was read successfully
 	read bool
 
@@ -210,6 +217,8 @@ func ReadModeenv(rootdir string) (*Modeenv, error) {
 	unmarshalModeenvValueFromCfg(cfg, "current_trusted_boot_assets", &m.CurrentTrustedBootAssets)
 	unmarshalModeenvValueFromCfg(cfg, "current_trusted_recovery_boot_assets", &m.CurrentTrustedRecoveryBootAssets)
 	unmarshalModeenvValueFromCfg(cfg, "current_kernel_command_lines", &m.CurrentKernelCommandLines)
+	unmarshalModeenvValueFromCfg(cfg, "current_boot_slot", &m.CurrentBootSlot)
+	unmarshalModeenvValueFromCfg(cfg, "try_boot_slot", &m.TryBootSlot)
 
 	// save all the rest of the keys we don't understand
 	keys, err := cfg.Options("")
@@ -329,6 +338,8 @@ func (m *Modeenv) WriteTo(rootdir string) error {
 	marshalModeenvEntryTo(buf, "current_trusted_boot_assets", m.CurrentTrustedBootAssets)
 	marshalModeenvEntryTo(buf, "current_trusted_recovery_boot_assets", m.CurrentTrustedRecoveryBootAssets)
 	marshalModeenvEntryTo(buf, "current_kernel_command_lines", m.CurrentKernelCommandLines)
+	marshalModeenvEntryTo(buf, "current_boot_slot", m.CurrentBootSlot)
+	marshalModeenvEntryTo(buf, "try_boot_slot", m.TryBootSlot)
 
 	// write all the extra keys at the end
 	// sort them for test convenience
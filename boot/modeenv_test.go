@@ -77,6 +77,8 @@ func (s *modeenvSuite) TestKnownKnown(c *C) {
 		"current_kernel_command_lines":         true,
 		"current_trusted_boot_assets":          true,
 		"current_trusted_recovery_boot_assets": true,
+		"current_boot_slot":                    true,
+		"try_boot_slot":                        true,
 	})
 }
 
@@ -798,6 +800,30 @@ current_kernel_command_lines=["snapd_recovery_mode=run panic=-1 console=ttyS0,io
 	})
 }
 
+func (s *modeenvSuite) TestMarshalBootSlots(c *C) {
+	c.Assert(s.mockModeenvPath, testutil.FileAbsent)
+
+	modeenv := &boot.Modeenv{
+		Mode:            "run",
+		RecoverySystem:  "20191128",
+		CurrentBootSlot: "a",
+		TryBootSlot:     "b",
+	}
+	err := modeenv.WriteTo(s.tmpdir)
+	c.Assert(err, IsNil)
+
+	c.Assert(s.mockModeenvPath, testutil.FileEquals, `mode=run
+recovery_system=20191128
+current_boot_slot=a
+try_boot_slot=b
+`)
+
+	modeenvRead, err := boot.ReadModeenv(s.tmpdir)
+	c.Assert(err, IsNil)
+	c.Check(modeenvRead.CurrentBootSlot, Equals, "a")
+	c.Check(modeenvRead.TryBootSlot, Equals, "b")
+}
+
 func (s *modeenvSuite) TestModeenvWithModelGradeSignKeyID(c *C) {
 	s.makeMockModeenvFile(c, `mode=run
 model=canonical/ubuntu-core-20-amd64
@@ -278,6 +278,73 @@ type UefiBootloader interface {
 	ParametersForEfiLoadOption(updatedAssets []string) (description string, assetPath string, optionalData []byte, err error)
 }
 
+// BootSlotBootloader can boot from either slot of the A/B structures of a
+// gadget. The slot is selected by the boot script provided by the gadget
+// using the snapd_boot_slot, snapd_try_boot_slot and boot_slot_status
+// variables of the bootloader environment, where boot_slot_status follows
+// the same "" -> "try" -> "trying" semantics as kernel_status.
+type BootSlotBootloader interface {
+	Bootloader
+
+	// BootSlots returns the slot booted by default, the slot being tried,
+	// if any, and the status of the try.
+	BootSlots() (current, try, status string, err error)
+
+	// EnableTryBootSlot makes the next boot try the given slot.
+	EnableTryBootSlot(slot string) error
+
+	// CommitTryBootSlot makes the slot that was tried the one booted by
+	// default and clears the try state.
+	CommitTryBootSlot() error
+
+	// DisableTryBootSlot clears the try state, leaving the slot booted by
+	// default untouched.
+	DisableTryBootSlot() error
+}
+
+const (
+	bootSlotVar       = "snapd_boot_slot"
+	tryBootSlotVar    = "snapd_try_boot_slot"
+	bootSlotStatusVar = "boot_slot_status"
+)
+
+func genericBootSlots(bl Bootloader) (current, try, status string, err error) {
+	m, err := bl.GetBootVars(bootSlotVar, tryBootSlotVar, bootSlotStatusVar)
+	if err != nil {
+		return "", "", "", err
+	}
+	return m[bootSlotVar], m[tryBootSlotVar], m[bootSlotStatusVar], nil
+}
+
+func genericEnableTryBootSlot(bl Bootloader, slot string) error {
+	return bl.SetBootVars(map[string]string{
+		tryBootSlotVar:    slot,
+		bootSlotStatusVar: "try",
+	})
+}
+
+func genericCommitTryBootSlot(bl Bootloader) error {
+	_, try, _, err := genericBootSlots(bl)
+	if err != nil {
+		return err
+	}
+	if try == "" {
+		return fmt.Errorf("cannot commit boot slot: no boot slot is being tried")
+	}
+	return bl.SetBootVars(map[string]string{
+		bootSlotVar:       try,
+		tryBootSlotVar:    "",
+		bootSlotStatusVar: "",
+	})
+}
+
+func genericDisableTryBootSlot(bl Bootloader) error {
+	return bl.SetBootVars(map[string]string{
+		tryBootSlotVar:    "",
+		bootSlotStatusVar: "",
+	})
+}
+
 func genericInstallBootConfig(gadgetFile, systemFile string) error {
 	if err := os.MkdirAll(filepath.Dir(systemFile), 0755); err != nil {
 		return err
@@ -347,6 +414,7 @@ var (
 		newAndroidBoot,
 		newLk,
 		newPiboot,
+		newSystemdBoot,
 	}
 )
 
@@ -66,6 +66,7 @@ var _ bootloader.NotScriptableBootloader = (*MockNotScriptableBootloader)(nil)
 var _ bootloader.NotScriptableBootloader = (*MockExtractedRecoveryKernelNotScriptableBootloader)(nil)
 var _ bootloader.ExtractedRecoveryKernelImageBootloader = (*MockExtractedRecoveryKernelNotScriptableBootloader)(nil)
 var _ bootloader.RebootBootloader = (*MockRebootBootloader)(nil)
+var _ bootloader.BootSlotBootloader = (*MockBootSlotBootloader)(nil)
 
 func Mock(name, bootdir string) *MockBootloader {
 	return &MockBootloader{
@@ -662,3 +663,45 @@ func (b *MockBootloader) WithRebootBootloader() *MockRebootBootloader {
 		MockBootloader: b,
 	}
 }
+
+// MockBootSlotBootloader mocks a bootloader implementing the
+// bootloader.BootSlotBootloader interface, keeping the A/B slot state in
+// the boot variables.
+type MockBootSlotBootloader struct {
+	*MockBootloader
+}
+
+func (b *MockBootloader) WithBootSlots() *MockBootSlotBootloader {
+	return &MockBootSlotBootloader{
+		MockBootloader: b,
+	}
+}
+
+func (b *MockBootSlotBootloader) BootSlots() (current, try, status string, err error) {
+	return b.BootVars["snapd_boot_slot"], b.BootVars["snapd_try_boot_slot"], b.BootVars["boot_slot_status"], nil
+}
+
+func (b *MockBootSlotBootloader) EnableTryBootSlot(slot string) error {
+	return b.SetBootVars(map[string]string{
+		"snapd_try_boot_slot": slot,
+		"boot_slot_status":    "try",
+	})
+}
+
+func (b *MockBootSlotBootloader) CommitTryBootSlot() error {
+	if b.BootVars["snapd_try_boot_slot"] == "" {
+		return fmt.Errorf("cannot commit boot slot: no boot slot is being tried")
+	}
+	return b.SetBootVars(map[string]string{
+		"snapd_boot_slot":     b.BootVars["snapd_try_boot_slot"],
+		"snapd_try_boot_slot": "",
+		"boot_slot_status":    "",
+	})
+}
+
+func (b *MockBootSlotBootloader) DisableTryBootSlot() error {
+	return b.SetBootVars(map[string]string{
+		"snapd_try_boot_slot": "",
+		"boot_slot_status":    "",
+	})
+}
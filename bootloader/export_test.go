@@ -326,3 +326,24 @@ var (
 	ConfigAssetFrom                      = configAssetFrom
 	StaticCommandLineForGrubAssetEdition = staticCommandLineForGrubAssetEdition
 )
+
+func NewSystemdBoot(rootdir string, opts *Options) ExtractedRecoveryKernelImageBootloader {
+	return newSystemdBoot(rootdir, opts).(ExtractedRecoveryKernelImageBootloader)
+}
+
+func MockSystemdBootFiles(c *C, rootdir string, blOpts *Options) {
+	sb := &systemdBoot{rootdir: rootdir}
+	sb.setDefaults()
+	sb.processBlOpts(blOpts)
+	err := os.MkdirAll(filepath.Dir(sb.envFile()), 0755)
+	c.Assert(err, IsNil)
+
+	env, err := ubootenv.Create(sb.envFile(), 4096, ubootenv.CreateOptions{HeaderFlagByte: true})
+	c.Assert(err, IsNil)
+	err = env.Save()
+	c.Assert(err, IsNil)
+}
+
+func SystemdBootDir(b Bootloader) string {
+	return b.(*systemdBoot).dir()
+}
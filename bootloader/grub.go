@@ -40,6 +40,7 @@ var (
 	_ RecoveryAwareBootloader           = (*grub)(nil)
 	_ ExtractedRunKernelImageBootloader = (*grub)(nil)
 	_ TrustedAssetsBootloader           = (*grub)(nil)
+	_ BootSlotBootloader                = (*grub)(nil)
 )
 
 type grub struct {
@@ -314,6 +315,26 @@ func (g *grub) DisableTryKernel() error {
 	return g.unlinkKernelEfiSymlink("try-kernel.efi")
 }
 
+// BootSlots returns the A/B slot state from the grub environment.
+func (g *grub) BootSlots() (current, try, status string, err error) {
+	return genericBootSlots(g)
+}
+
+// EnableTryBootSlot makes the next boot try the given A/B slot.
+func (g *grub) EnableTryBootSlot(slot string) error {
+	return genericEnableTryBootSlot(g, slot)
+}
+
+// CommitTryBootSlot makes the tried A/B slot the default one.
+func (g *grub) CommitTryBootSlot() error {
+	return genericCommitTryBootSlot(g)
+}
+
+// DisableTryBootSlot clears the A/B slot try state.
+func (g *grub) DisableTryBootSlot() error {
+	return genericDisableTryBootSlot(g)
+}
+
 // Kernel will return the kernel snap currently installed in the bootloader
 // partition, pointed to by the kernel.efi symlink.
 func (g *grub) Kernel() (snap.PlaceInfo, error) {
@@ -171,6 +171,30 @@ func (s *grubTestSuite) TestSetBootVer(c *C) {
 	c.Check(s.grubEditenvGet(c, "k2"), Equals, "v2")
 }
 
+func (s *grubTestSuite) TestBootSlots(c *C) {
+	s.makeFakeGrubEnv(c)
+
+	g := bootloader.NewGrub(s.rootdir, nil)
+	bsb, ok := g.(bootloader.BootSlotBootloader)
+	c.Assert(ok, Equals, true)
+
+	err := bsb.EnableTryBootSlot("b")
+	c.Assert(err, IsNil)
+	c.Check(s.grubEditenvGet(c, "snapd_try_boot_slot"), Equals, "b")
+	c.Check(s.grubEditenvGet(c, "boot_slot_status"), Equals, "try")
+
+	s.grubEditenvSet(c, "boot_slot_status", "trying")
+	current, try, status, err := bsb.BootSlots()
+	c.Assert(err, IsNil)
+	c.Check([]string{current, try, status}, DeepEquals, []string{"", "b", "trying"})
+
+	err = bsb.CommitTryBootSlot()
+	c.Assert(err, IsNil)
+	c.Check(s.grubEditenvGet(c, "snapd_boot_slot"), Equals, "b")
+	c.Check(s.grubEditenvGet(c, "snapd_try_boot_slot"), Equals, "")
+	c.Check(s.grubEditenvGet(c, "boot_slot_status"), Equals, "")
+}
+
 func (s *grubTestSuite) TestExtractKernelAssetsNoUnpacksKernelForGrub(c *C) {
 	s.makeFakeGrubEnv(c)
 
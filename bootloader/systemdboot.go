@@ -0,0 +1,596 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package bootloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/arch"
+	"github.com/snapcore/snapd/bootloader/ubootenv"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/kcmdline"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// ensure systemd-boot implements the required interfaces
+var (
+	_ Bootloader                             = (*systemdBoot)(nil)
+	_ ExtractedRecoveryKernelImageBootloader = (*systemdBoot)(nil)
+	_ NotScriptableBootloader                = (*systemdBoot)(nil)
+	_ TrustedAssetsBootloader                = (*systemdBoot)(nil)
+)
+
+// systemd-boot cannot be scripted, so much like for piboot, the boot
+// variables are kept in an environment file that the bootloader does not
+// read, and loader entries are generated from it whenever relevant
+// variables change.
+//
+// systemd-boot is installed on ubuntu-seed, which carries loader.conf and
+// the entries for the recovery systems. The ubuntu-boot partition is used as
+// the XBOOTLDR partition holding the run mode entries and the extracted run
+// mode kernels, the entries of both partitions are merged by systemd-boot.
+//
+// A kernel is tried through an extra run mode entry that uses boot counting
+// and sorts before the regular one. systemd-boot decrements the counter when
+// booting it, if that boot does not succeed the entry is marked bad and is
+// sorted last so the regular entry is booted instead. The try entry passes
+// kernel_status=trying on the kernel command line for the initramfs to
+// update the kernel status, see boot.updateNotScriptableBootloaderStatus().
+const (
+	systemdBootEnvFilename  = "snapd.env"
+	systemdBootLoaderConf   = "loader/loader.conf"
+	systemdBootEntriesDir   = "loader/entries"
+	systemdBootKernelPrefix = "EFI/ubuntu"
+
+	// entry for the current recovery or install system
+	systemdBootSystemEntry = "snapd-system"
+	// prefix of the run mode entries, used as default when booting to run
+	// mode
+	systemdBootRunEntry = "snapd-run"
+	// prefix of the try run mode entry, it uses boot counting
+	systemdBootTryEntry = "snapd-run-try"
+	// prefix of the entries for each recovery system
+	systemdBootRecoverEntryPrefix = "snapd-recover-"
+
+	// a try kernel gets a single boot attempt, as the kernel status cannot
+	// be reset to "try" by a second boot of the same entry
+	systemdBootTryCount = 1
+
+	systemdBootStaticCmdline = "console=ttyS0 console=tty1 panic=-1"
+)
+
+type systemdBoot struct {
+	rootdir string
+	basedir string
+
+	recovery         bool
+	prepareImageTime bool
+}
+
+func (sb *systemdBoot) setDefaults() {
+	sb.basedir = "/boot/systemd-boot/"
+}
+
+func (sb *systemdBoot) processBlOpts(blOpts *Options) {
+	if blOpts == nil {
+		return
+	}
+
+	sb.recovery = blOpts.Role == RoleRecovery
+	sb.prepareImageTime = blOpts.PrepareImageTime
+	if blOpts.Role == RoleRecovery || blOpts.NoSlashBoot {
+		// RoleRecovery or NoSlashBoot imply we use the
+		// layout as it exists on the partition directly
+		sb.basedir = "/"
+	}
+}
+
+// newSystemdBoot creates a new systemd-boot bootloader object
+func newSystemdBoot(rootdir string, blOpts *Options) Bootloader {
+	sb := &systemdBoot{
+		rootdir: rootdir,
+	}
+	sb.setDefaults()
+	sb.processBlOpts(blOpts)
+	return sb
+}
+
+func (sb *systemdBoot) Name() string {
+	return "systemd-boot"
+}
+
+func (sb *systemdBoot) dir() string {
+	if sb.rootdir == "" {
+		panic("internal error: unset rootdir")
+	}
+	return filepath.Join(sb.rootdir, sb.basedir)
+}
+
+func (sb *systemdBoot) envFile() string {
+	return filepath.Join(sb.dir(), "loader", systemdBootEnvFilename)
+}
+
+// systemd-boot enabled if env file exists
+func (sb *systemdBoot) Present() (bool, error) {
+	return osutil.FileExists(sb.envFile()), nil
+}
+
+func (sb *systemdBoot) InstallBootConfig(gadgetDir string, blOpts *Options) error {
+	if blOpts != nil && blOpts.Role == RoleRecovery {
+		// the gadget provides the reference loader.conf, the default
+		// entry is set when the boot variables are
+		gadgetFile := filepath.Join(gadgetDir, sb.Name()+".conf")
+		systemFile := filepath.Join(sb.dir(), systemdBootLoaderConf)
+		if err := genericInstallBootConfig(gadgetFile, systemFile); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(sb.envFile()), 0755); err != nil {
+		return err
+	}
+	env, err := ubootenv.Create(sb.envFile(), 4096, ubootenv.CreateOptions{HeaderFlagByte: true})
+	if err != nil {
+		return err
+	}
+	return env.Save()
+}
+
+func (sb *systemdBoot) GetBootVars(names ...string) (map[string]string, error) {
+	env, err := ubootenv.OpenWithFlags(sb.envFile(), ubootenv.OpenBestEffort)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		out[name] = env.Get(name)
+	}
+
+	return out, nil
+}
+
+// variables that affect the generated loader configuration
+var systemdBootConfigVars = map[string]bool{
+	"snapd_recovery_mode":      true,
+	"snapd_recovery_system":    true,
+	"kernel_status":            true,
+	"snap_kernel":              true,
+	"snap_try_kernel":          true,
+	"snapd_extra_cmdline_args": true,
+	"snapd_full_cmdline_args":  true,
+}
+
+// Variables stored in ubuntu-seed:
+//
+//	snapd_recovery_system
+//	snapd_recovery_mode
+//
+// Variables stored in ubuntu-boot:
+//
+//	kernel_status
+//	snap_kernel
+//	snap_try_kernel
+//	snapd_extra_cmdline_args
+//	snapd_full_cmdline_args
+func (sb *systemdBoot) SetBootVars(values map[string]string) error {
+	env, err := ubootenv.OpenWithFlags(sb.envFile(), ubootenv.OpenBestEffort)
+	if err != nil {
+		return err
+	}
+
+	dirtyEnv := false
+	reconfigBootloader := false
+	for k, v := range values {
+		// already set to the right value, nothing to do
+		if env.Get(k) == v {
+			continue
+		}
+		env.Set(k, v)
+		dirtyEnv = true
+		if systemdBootConfigVars[k] {
+			reconfigBootloader = true
+		}
+	}
+
+	if dirtyEnv {
+		if err := env.Save(); err != nil {
+			return err
+		}
+	}
+
+	if reconfigBootloader {
+		return sb.applyConfig(env)
+	}
+	return nil
+}
+
+// SetBootVarsFromInitramfs sets the boot variables without touching the
+// loader entries.
+func (sb *systemdBoot) SetBootVarsFromInitramfs(values map[string]string) error {
+	env, err := ubootenv.OpenWithFlags(sb.envFile(), ubootenv.OpenBestEffort)
+	if err != nil {
+		return err
+	}
+
+	dirtyEnv := false
+	for k, v := range values {
+		if env.Get(k) == v {
+			continue
+		}
+		env.Set(k, v)
+		dirtyEnv = true
+	}
+
+	if dirtyEnv {
+		return env.Save()
+	}
+	return nil
+}
+
+// entryCommandLine returns the kernel command line for an entry booting
+// with the given snapd arguments.
+func (sb *systemdBoot) entryCommandLine(env *ubootenv.Env, snapdArgs ...string) string {
+	// boot sets snapd_full_cmdline_args to a single space when a full but
+	// empty command line is wanted
+	args := env.Get("snapd_full_cmdline_args")
+	if args == "" {
+		args = strutil.JoinNonEmpty([]string{systemdBootStaticCmdline, env.Get("snapd_extra_cmdline_args")}, " ")
+	}
+	return strutil.JoinNonEmpty(append(snapdArgs, strings.TrimSpace(args)), " ")
+}
+
+func (sb *systemdBoot) writeEntry(name, sortKey, title, efi, options string) error {
+	entry := fmt.Sprintf("title %s\nsort-key %s\nefi %s\noptions %s\n", title, sortKey, efi, options)
+	entryFile := filepath.Join(sb.dir(), systemdBootEntriesDir, name+".conf")
+	if err := os.MkdirAll(filepath.Dir(entryFile), 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(entryFile, []byte(entry), 0644, 0)
+}
+
+// removeEntries removes the entries with the given prefix, including any
+// boot counting suffix systemd-boot may have added to their names.
+func (sb *systemdBoot) removeEntries(prefix string) error {
+	matches, err := filepath.Glob(filepath.Join(sb.dir(), systemdBootEntriesDir, prefix+"*.conf"))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := os.Remove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func kernelEfiPath(dir string) string {
+	return "/" + filepath.Join(dir, "kernel.efi")
+}
+
+// applyConfig generates the loader configuration from the environment.
+func (sb *systemdBoot) applyConfig(env *ubootenv.Env) error {
+	if mode := env.Get("snapd_recovery_mode"); mode != "" {
+		// ubuntu-seed environment
+		defaultEntry := systemdBootRunEntry + "*"
+		if mode != "run" {
+			label := env.Get("snapd_recovery_system")
+			efi := kernelEfiPath(filepath.Join("systems", label, "kernel"))
+			options := sb.entryCommandLine(env, "snapd_recovery_mode="+mode, "snapd_recovery_system="+label)
+			title := fmt.Sprintf("Ubuntu Core %s %s", mode, label)
+			if err := sb.writeEntry(systemdBootSystemEntry, systemdBootSystemEntry, title, efi, options); err != nil {
+				return err
+			}
+			defaultEntry = systemdBootSystemEntry + ".conf"
+		}
+		if err := sb.writeLoaderConfDefault(defaultEntry); err != nil {
+			return err
+		}
+	}
+
+	if kernel := env.Get("snap_kernel"); kernel != "" {
+		// ubuntu-boot environment
+		efi := kernelEfiPath(filepath.Join(systemdBootKernelPrefix, kernel))
+		options := sb.entryCommandLine(env, "snapd_recovery_mode=run")
+		if err := sb.writeEntry(systemdBootRunEntry, systemdBootRunEntry+"-1", "Ubuntu Core", efi, options); err != nil {
+			return err
+		}
+	}
+
+	// any previous try entry is stale now
+	if err := sb.removeEntries(systemdBootTryEntry); err != nil {
+		return err
+	}
+	tryKernel := env.Get("snap_try_kernel")
+	if env.Get("kernel_status") == "try" && tryKernel != "" {
+		efi := kernelEfiPath(filepath.Join(systemdBootKernelPrefix, tryKernel))
+		options := sb.entryCommandLine(env, "snapd_recovery_mode=run", "kernel_status=trying")
+		name := fmt.Sprintf("%s+%d", systemdBootTryEntry, systemdBootTryCount)
+		// the try entry sorts before the regular run mode entry, unless
+		// systemd-boot marked it bad after a failed boot
+		logger.Debugf("enabling systemd-boot try entry for %s", tryKernel)
+		if err := sb.writeEntry(name, systemdBootRunEntry+"-0", "Ubuntu Core (try)", efi, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLoaderConfDefault sets the default entry in loader.conf, keeping the
+// other settings from the gadget.
+func (sb *systemdBoot) writeLoaderConfDefault(defaultEntry string) error {
+	loaderConf := filepath.Join(sb.dir(), systemdBootLoaderConf)
+	buf, err := os.ReadFile(loaderConf)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(buf), "\n"), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "default" {
+			continue
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, "default "+defaultEntry, "")
+
+	if err := os.MkdirAll(filepath.Dir(loaderConf), 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(loaderConf, []byte(strings.Join(lines, "\n")), 0644, 0)
+}
+
+func (sb *systemdBoot) ExtractKernelAssets(s snap.PlaceInfo, snapf snap.Container) error {
+	dstDir := filepath.Join(sb.dir(), systemdBootKernelPrefix, s.Filename())
+	return extractKernelAssetsToBootDir(dstDir, snapf, []string{"kernel.efi"})
+}
+
+func (sb *systemdBoot) ExtractRecoveryKernelAssets(recoverySystemDir string, s snap.PlaceInfo, snapf snap.Container) error {
+	if recoverySystemDir == "" {
+		return fmt.Errorf("internal error: recoverySystemDir unset")
+	}
+
+	kernelDir := filepath.Join(recoverySystemDir, "kernel")
+	if err := extractKernelAssetsToBootDir(filepath.Join(sb.dir(), kernelDir), snapf, []string{"kernel.efi"}); err != nil {
+		return err
+	}
+
+	// add an entry to be able to pick the recovery system from the
+	// systemd-boot menu
+	label := filepath.Base(recoverySystemDir)
+	options := strutil.JoinNonEmpty([]string{"snapd_recovery_mode=recover", "snapd_recovery_system=" + label, systemdBootStaticCmdline}, " ")
+	name := systemdBootRecoverEntryPrefix + label
+	return sb.writeEntry(name, name, "Ubuntu Core recover "+label, kernelEfiPath(kernelDir), options)
+}
+
+func (sb *systemdBoot) RemoveKernelAssets(s snap.PlaceInfo) error {
+	return removeKernelAssetsFromBootDir(filepath.Join(sb.dir(), systemdBootKernelPrefix), s)
+}
+
+// UpdateBootConfig does nothing as the loader configuration is generated
+// from the boot variables.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (sb *systemdBoot) UpdateBootConfig() (bool, error) {
+	return false, nil
+}
+
+// ManagedAssets returns a list relative paths to boot assets inside the root
+// directory of the filesystem.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (sb *systemdBoot) ManagedAssets() []string {
+	return []string{
+		systemdBootLoaderConf,
+		systemdBootEntriesDir,
+	}
+}
+
+// CommandLine returns the kernel command line composed of mode and system
+// arguments, followed by either the built-in static arguments and any extra
+// arguments, or a separate set of arguments provided in the components.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (sb *systemdBoot) CommandLine(pieces CommandLineComponents) (string, error) {
+	if err := pieces.Validate(); err != nil {
+		return "", err
+	}
+
+	nonSnapdCmdline := pieces.FullArgs
+	if nonSnapdCmdline == "" {
+		keepDefaultArgs := kcmdline.RemoveMatchingFilter(systemdBootStaticCmdline, pieces.RemoveArgs)
+		nonSnapdCmdline = strutil.JoinNonEmpty(append(keepDefaultArgs, pieces.ExtraArgs), " ")
+	}
+	args, err := kcmdline.Split(nonSnapdCmdline)
+	if err != nil {
+		return "", fmt.Errorf("cannot use badly formatted kernel command line: %v", err)
+	}
+	snapdArgs := make([]string, 0, 2)
+	if pieces.ModeArg != "" {
+		snapdArgs = append(snapdArgs, pieces.ModeArg)
+	}
+	if pieces.SystemArg != "" {
+		snapdArgs = append(snapdArgs, pieces.SystemArg)
+	}
+	return strings.Join(append(snapdArgs, args...), " "), nil
+}
+
+// CandidateCommandLine is the same as CommandLine, as the static arguments
+// are not tied to an edition of a boot config asset.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (sb *systemdBoot) CandidateCommandLine(pieces CommandLineComponents) (string, error) {
+	return sb.CommandLine(pieces)
+}
+
+// DefaultCommandLine returns the default kernel command-line used by
+// the bootloader excluding the recovery mode and system parameters.
+func (sb *systemdBoot) DefaultCommandLine(candidate bool) (string, error) {
+	return systemdBootStaticCmdline, nil
+}
+
+// systemdBootAssetPath contains the paths for assets in the boot chain.
+type systemdBootAssetPath struct {
+	defaultBinary     taggedPath
+	systemdBootBinary taggedPath
+}
+
+// systemdBootAssetsForArch contains the paths for assets for different
+// architectures in a map.
+var systemdBootAssetsForArch = map[string]systemdBootAssetPath{
+	"amd64": {
+		defaultBinary: taggedPath{
+			tag:  "boot",
+			path: filepath.Join("EFI/boot/", "bootx64.efi"),
+		},
+		systemdBootBinary: taggedPath{
+			tag:  "systemd",
+			path: filepath.Join("EFI/systemd/", "systemd-bootx64.efi"),
+		},
+	},
+	"arm64": {
+		defaultBinary: taggedPath{
+			tag:  "boot",
+			path: filepath.Join("EFI/boot/", "bootaa64.efi"),
+		},
+		systemdBootBinary: taggedPath{
+			tag:  "systemd",
+			path: filepath.Join("EFI/systemd/", "systemd-bootaa64.efi"),
+		},
+	},
+}
+
+func (sb *systemdBoot) getBootAssetsForArch() (*systemdBootAssetPath, error) {
+	if sb.prepareImageTime {
+		return nil, fmt.Errorf("internal error: retrieving boot assets at prepare image time")
+	}
+	archi := arch.DpkgArchitecture()
+	assets, ok := systemdBootAssetsForArch[archi]
+	if !ok {
+		return nil, fmt.Errorf("cannot find systemd-boot assets for %q", archi)
+	}
+	return &assets, nil
+}
+
+// getRecoveryModeTrustedAssets returns the list of ordered asset chains,
+// which is systemd-boot from the seed partition either loaded through the
+// removable media path or through its own boot entry.
+func (sb *systemdBoot) getRecoveryModeTrustedAssets() ([][]taggedPath, error) {
+	assets, err := sb.getBootAssetsForArch()
+	if err != nil {
+		return nil, err
+	}
+	return [][]taggedPath{{assets.systemdBootBinary}, {assets.defaultBinary}}, nil
+}
+
+// TrustedAssets returns the map of relative paths to asset
+// identifers. There are none in run mode as systemd-boot only runs from the
+// seed partition.
+func (sb *systemdBoot) TrustedAssets() (map[string]string, error) {
+	if sb.basedir != "/" {
+		return nil, fmt.Errorf("internal error: trusted assets called without native host-partition layout")
+	}
+	if !sb.recovery {
+		return nil, nil
+	}
+	chains, err := sb.getRecoveryModeTrustedAssets()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]string)
+	for _, chain := range chains {
+		for _, asset := range chain {
+			ret[asset.path] = asset.Id()
+		}
+	}
+	return ret, nil
+}
+
+// RecoveryBootChains returns the list of load chains for recovery modes.
+// It should be called on a RoleRecovery bootloader.
+func (sb *systemdBoot) RecoveryBootChains(kernelPath string) ([][]BootFile, error) {
+	if !sb.recovery {
+		return nil, fmt.Errorf("not a recovery bootloader")
+	}
+
+	assetsSet, err := sb.getRecoveryModeTrustedAssets()
+	if err != nil {
+		return nil, err
+	}
+	chains := make([][]BootFile, 0, len(assetsSet))
+	for _, assets := range assetsSet {
+		chain := make([]BootFile, 0, len(assets)+1)
+		for _, ta := range assets {
+			chain = append(chain, NewBootFile("", ta.path, RoleRecovery))
+		}
+		chain = append(chain, NewBootFile(kernelPath, "kernel.efi", RoleRecovery))
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
+// BootChains returns the list of load chains for run mode. systemd-boot
+// loads the run mode kernel directly from ubuntu-boot.
+// It should be called on a RoleRecovery bootloader passing the
+// RoleRunMode bootloader.
+func (sb *systemdBoot) BootChains(runBl Bootloader, kernelPath string) ([][]BootFile, error) {
+	if !sb.recovery {
+		return nil, fmt.Errorf("not a recovery bootloader")
+	}
+	if runBl.Name() != sb.Name() {
+		return nil, fmt.Errorf("run mode bootloader must be systemd-boot")
+	}
+
+	assetsSet, err := sb.getRecoveryModeTrustedAssets()
+	if err != nil {
+		return nil, err
+	}
+	chains := make([][]BootFile, 0, len(assetsSet))
+	for _, assets := range assetsSet {
+		chain := make([]BootFile, 0, len(assets)+1)
+		for _, ta := range assets {
+			chain = append(chain, NewBootFile("", ta.path, RoleRecovery))
+		}
+		chain = append(chain, NewBootFile(kernelPath, "kernel.efi", RoleRunMode))
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
+// RevocationTriggeringAssets returns the first stage binaries, whose update
+// may come with revocations.
+func (sb *systemdBoot) RevocationTriggeringAssets() ([]string, error) {
+	if !sb.recovery {
+		return nil, nil
+	}
+
+	assets, err := sb.getBootAssetsForArch()
+	if err != nil {
+		return nil, err
+	}
+	return []string{assets.systemdBootBinary.Id(), assets.defaultBinary.Id()}, nil
+}
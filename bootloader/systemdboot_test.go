@@ -0,0 +1,329 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package bootloader_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/arch/archtest"
+	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapfile"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type systemdBootTestSuite struct {
+	baseBootenvTestSuite
+}
+
+var _ = Suite(&systemdBootTestSuite{})
+
+func (s *systemdBootTestSuite) SetUpTest(c *C) {
+	s.baseBootenvTestSuite.SetUpTest(c)
+	s.AddCleanup(archtest.MockArchitecture("amd64"))
+}
+
+func (s *systemdBootTestSuite) TestNewSystemdBoot(c *C) {
+	// no files means bl is not present, but we can still create the bl object
+	sb := bootloader.NewSystemdBoot(s.rootdir, nil)
+	c.Assert(sb, NotNil)
+	c.Assert(sb.Name(), Equals, "systemd-boot")
+
+	present, err := sb.Present()
+	c.Assert(err, IsNil)
+	c.Assert(present, Equals, false)
+
+	// now with files present, the bl is present
+	bootloader.MockSystemdBootFiles(c, s.rootdir, nil)
+	present, err = sb.Present()
+	c.Assert(err, IsNil)
+	c.Assert(present, Equals, true)
+}
+
+func (s *systemdBootTestSuite) TestGetBootloaderWithSystemdBoot(c *C) {
+	bootloader.MockSystemdBootFiles(c, s.rootdir, nil)
+
+	bl, err := bootloader.Find(s.rootdir, nil)
+	c.Assert(err, IsNil)
+	c.Assert(bl.Name(), Equals, "systemd-boot")
+}
+
+func (s *systemdBootTestSuite) TestInstallBootConfig(c *C) {
+	gadgetDir := c.MkDir()
+	err := os.WriteFile(filepath.Join(gadgetDir, "systemd-boot.conf"), []byte("timeout 3\n"), 0644)
+	c.Assert(err, IsNil)
+
+	opts := &bootloader.Options{Role: bootloader.RoleRecovery}
+	bl, err := bootloader.ForGadget(gadgetDir, s.rootdir, opts)
+	c.Assert(err, IsNil)
+	c.Assert(bl.Name(), Equals, "systemd-boot")
+
+	err = bl.InstallBootConfig(gadgetDir, opts)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(s.rootdir, "loader/loader.conf"), testutil.FileEquals, "timeout 3\n")
+	c.Check(filepath.Join(s.rootdir, "loader/snapd.env"), testutil.FilePresent)
+
+	present, err := bl.Present()
+	c.Assert(err, IsNil)
+	c.Check(present, Equals, true)
+}
+
+func (s *systemdBootTestSuite) TestSetGetBootVars(c *C) {
+	opts := &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true}
+	bootloader.MockSystemdBootFiles(c, s.rootdir, opts)
+	sb := bootloader.NewSystemdBoot(s.rootdir, opts)
+
+	err := sb.SetBootVars(map[string]string{
+		"snap_mode": "",
+		"snap_core": "4",
+	})
+	c.Assert(err, IsNil)
+
+	m, err := sb.GetBootVars("snap_mode", "snap_core")
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, map[string]string{
+		"snap_mode": "",
+		"snap_core": "4",
+	})
+
+	// no boot related variables were set, so no entries are generated
+	c.Check(filepath.Join(s.rootdir, "loader/entries"), testutil.FileAbsent)
+}
+
+func (s *systemdBootTestSuite) TestRunModeEntries(c *C) {
+	opts := &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true}
+	bootloader.MockSystemdBootFiles(c, s.rootdir, opts)
+	sb := bootloader.NewSystemdBoot(s.rootdir, opts)
+	entriesDir := filepath.Join(s.rootdir, "loader/entries")
+
+	err := sb.SetBootVars(map[string]string{
+		"snap_kernel":              "pc-kernel_1.snap",
+		"snapd_extra_cmdline_args": "extra=1",
+	})
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(entriesDir, "snapd-run.conf"), testutil.FileEquals, `title Ubuntu Core
+sort-key snapd-run-1
+efi /EFI/ubuntu/pc-kernel_1.snap/kernel.efi
+options snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1 extra=1
+`)
+	c.Check(filepath.Join(entriesDir, "snapd-run-try+1.conf"), testutil.FileAbsent)
+
+	// try a new kernel
+	err = sb.SetBootVars(map[string]string{
+		"snap_try_kernel": "pc-kernel_2.snap",
+		"kernel_status":   "try",
+	})
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(entriesDir, "snapd-run.conf"), testutil.FilePresent)
+	c.Check(filepath.Join(entriesDir, "snapd-run-try+1.conf"), testutil.FileEquals, `title Ubuntu Core (try)
+sort-key snapd-run-0
+efi /EFI/ubuntu/pc-kernel_2.snap/kernel.efi
+options snapd_recovery_mode=run kernel_status=trying console=ttyS0 console=tty1 panic=-1 extra=1
+`)
+
+	// the initramfs does not touch the entries
+	nsbl, ok := sb.(bootloader.NotScriptableBootloader)
+	c.Assert(ok, Equals, true)
+	err = nsbl.SetBootVarsFromInitramfs(map[string]string{"kernel_status": "trying"})
+	c.Assert(err, IsNil)
+	m, err := sb.GetBootVars("kernel_status")
+	c.Assert(err, IsNil)
+	c.Check(m, DeepEquals, map[string]string{"kernel_status": "trying"})
+
+	// systemd-boot renamed the entry when booting it
+	err = os.Rename(filepath.Join(entriesDir, "snapd-run-try+1.conf"), filepath.Join(entriesDir, "snapd-run-try+0-1.conf"))
+	c.Assert(err, IsNil)
+
+	// the new kernel is marked successful, using a full command line
+	err = sb.SetBootVars(map[string]string{
+		"snap_kernel":             "pc-kernel_2.snap",
+		"snap_try_kernel":         "",
+		"kernel_status":           "",
+		"snapd_full_cmdline_args": "full=1",
+	})
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(entriesDir, "snapd-run.conf"), testutil.FileEquals, `title Ubuntu Core
+sort-key snapd-run-1
+efi /EFI/ubuntu/pc-kernel_2.snap/kernel.efi
+options snapd_recovery_mode=run full=1
+`)
+	c.Check(filepath.Join(entriesDir, "snapd-run-try+0-1.conf"), testutil.FileAbsent)
+}
+
+func (s *systemdBootTestSuite) TestSeedEntries(c *C) {
+	opts := &bootloader.Options{Role: bootloader.RoleRecovery}
+	bootloader.MockSystemdBootFiles(c, s.rootdir, opts)
+	loaderConf := filepath.Join(s.rootdir, "loader/loader.conf")
+	err := os.WriteFile(loaderConf, []byte("timeout 3\ndefault foo\n"), 0644)
+	c.Assert(err, IsNil)
+	sb := bootloader.NewSystemdBoot(s.rootdir, opts)
+
+	err = sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode":   "install",
+		"snapd_recovery_system": "20260101",
+	})
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(s.rootdir, "loader/entries/snapd-system.conf"), testutil.FileEquals, `title Ubuntu Core install 20260101
+sort-key snapd-system
+efi /systems/20260101/kernel/kernel.efi
+options snapd_recovery_mode=install snapd_recovery_system=20260101 console=ttyS0 console=tty1 panic=-1
+`)
+	c.Check(loaderConf, testutil.FileEquals, "timeout 3\ndefault snapd-system.conf\n")
+
+	// switching to run mode boots the entries from ubuntu-boot
+	err = sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode":   "run",
+		"snapd_recovery_system": "20260101",
+	})
+	c.Assert(err, IsNil)
+	c.Check(loaderConf, testutil.FileEquals, "timeout 3\ndefault snapd-run*\n")
+}
+
+func (s *systemdBootTestSuite) TestExtractKernelAssetsAndRemove(c *C) {
+	opts := &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true}
+	bootloader.MockSystemdBootFiles(c, s.rootdir, opts)
+	sb := bootloader.NewSystemdBoot(s.rootdir, opts)
+
+	files := [][]string{
+		{"kernel.efi", "I'm a kernel.efi"},
+		// must be last
+		{"meta/kernel.yaml", "version: 4.2"},
+	}
+	si := &snap.SideInfo{
+		RealName: "ubuntu-kernel",
+		Revision: snap.R(42),
+	}
+	fn := snaptest.MakeTestSnapWithFiles(c, packageKernel, files)
+	snapf, err := snapfile.Open(fn)
+	c.Assert(err, IsNil)
+	info, err := snap.ReadInfoFromSnapFile(snapf, si)
+	c.Assert(err, IsNil)
+
+	err = sb.ExtractKernelAssets(info, snapf)
+	c.Assert(err, IsNil)
+	kernelDir := filepath.Join(s.rootdir, "EFI/ubuntu", info.Filename())
+	c.Check(filepath.Join(kernelDir, "kernel.efi"), testutil.FileEquals, "I'm a kernel.efi")
+
+	err = sb.RemoveKernelAssets(info)
+	c.Assert(err, IsNil)
+	c.Check(kernelDir, testutil.FileAbsent)
+
+	// recovery kernels get a menu entry
+	err = sb.ExtractRecoveryKernelAssets("", info, snapf)
+	c.Assert(err, ErrorMatches, "internal error: recoverySystemDir unset")
+	err = sb.ExtractRecoveryKernelAssets("systems/20260101", info, snapf)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(s.rootdir, "systems/20260101/kernel/kernel.efi"), testutil.FileEquals, "I'm a kernel.efi")
+	c.Check(filepath.Join(s.rootdir, "loader/entries/snapd-recover-20260101.conf"), testutil.FileEquals, `title Ubuntu Core recover 20260101
+sort-key snapd-recover-20260101
+efi /systems/20260101/kernel/kernel.efi
+options snapd_recovery_mode=recover snapd_recovery_system=20260101 console=ttyS0 console=tty1 panic=-1
+`)
+}
+
+func (s *systemdBootTestSuite) TestTrustedAssets(c *C) {
+	sb := bootloader.NewSystemdBoot(s.rootdir, &bootloader.Options{Role: bootloader.RoleRecovery})
+	tab, ok := sb.(bootloader.TrustedAssetsBootloader)
+	c.Assert(ok, Equals, true)
+
+	ta, err := tab.TrustedAssets()
+	c.Assert(err, IsNil)
+	c.Check(ta, DeepEquals, map[string]string{
+		"EFI/boot/bootx64.efi":            "boot:bootx64.efi",
+		"EFI/systemd/systemd-bootx64.efi": "systemd:systemd-bootx64.efi",
+	})
+
+	revs, err := tab.RevocationTriggeringAssets()
+	c.Assert(err, IsNil)
+	c.Check(revs, DeepEquals, []string{"systemd:systemd-bootx64.efi", "boot:bootx64.efi"})
+
+	// no trusted assets in run mode
+	runBl := bootloader.NewSystemdBoot(s.rootdir, &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true})
+	ta, err = runBl.(bootloader.TrustedAssetsBootloader).TrustedAssets()
+	c.Assert(err, IsNil)
+	c.Check(ta, HasLen, 0)
+
+	// not a native layout
+	_, err = bootloader.NewSystemdBoot(s.rootdir, nil).(bootloader.TrustedAssetsBootloader).TrustedAssets()
+	c.Assert(err, ErrorMatches, "internal error: trusted assets called without native host-partition layout")
+}
+
+func (s *systemdBootTestSuite) TestBootChains(c *C) {
+	sb := bootloader.NewSystemdBoot(s.rootdir, &bootloader.Options{Role: bootloader.RoleRecovery})
+	tab := sb.(bootloader.TrustedAssetsBootloader)
+
+	chains, err := tab.RecoveryBootChains("kernel.snap")
+	c.Assert(err, IsNil)
+	c.Check(chains, DeepEquals, [][]bootloader.BootFile{
+		{
+			bootloader.NewBootFile("", "EFI/systemd/systemd-bootx64.efi", bootloader.RoleRecovery),
+			bootloader.NewBootFile("kernel.snap", "kernel.efi", bootloader.RoleRecovery),
+		}, {
+			bootloader.NewBootFile("", "EFI/boot/bootx64.efi", bootloader.RoleRecovery),
+			bootloader.NewBootFile("kernel.snap", "kernel.efi", bootloader.RoleRecovery),
+		},
+	})
+
+	runBl := bootloader.NewSystemdBoot(s.rootdir, &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true})
+	chains, err = tab.BootChains(runBl, "kernel.snap")
+	c.Assert(err, IsNil)
+	c.Check(chains, DeepEquals, [][]bootloader.BootFile{
+		{
+			bootloader.NewBootFile("", "EFI/systemd/systemd-bootx64.efi", bootloader.RoleRecovery),
+			bootloader.NewBootFile("kernel.snap", "kernel.efi", bootloader.RoleRunMode),
+		}, {
+			bootloader.NewBootFile("", "EFI/boot/bootx64.efi", bootloader.RoleRecovery),
+			bootloader.NewBootFile("kernel.snap", "kernel.efi", bootloader.RoleRunMode),
+		},
+	})
+
+	_, err = tab.BootChains(bootloader.NewPiboot(s.rootdir, nil), "kernel.snap")
+	c.Assert(err, ErrorMatches, "run mode bootloader must be systemd-boot")
+	_, err = runBl.(bootloader.TrustedAssetsBootloader).RecoveryBootChains("kernel.snap")
+	c.Assert(err, ErrorMatches, "not a recovery bootloader")
+}
+
+func (s *systemdBootTestSuite) TestCommandLine(c *C) {
+	sb := bootloader.NewSystemdBoot(s.rootdir, &bootloader.Options{Role: bootloader.RoleRecovery})
+	tab := sb.(bootloader.TrustedAssetsBootloader)
+
+	args, err := tab.CommandLine(bootloader.CommandLineComponents{
+		ModeArg:   "snapd_recovery_mode=recover",
+		SystemArg: "snapd_recovery_system=1234",
+		ExtraArgs: "extra",
+	})
+	c.Assert(err, IsNil)
+	c.Check(args, Equals, "snapd_recovery_mode=recover snapd_recovery_system=1234 console=ttyS0 console=tty1 panic=-1 extra")
+
+	args, err = tab.CandidateCommandLine(bootloader.CommandLineComponents{
+		ModeArg:  "snapd_recovery_mode=run",
+		FullArgs: "full",
+	})
+	c.Assert(err, IsNil)
+	c.Check(args, Equals, "snapd_recovery_mode=run full")
+
+	args, err = tab.DefaultCommandLine(false)
+	c.Assert(err, IsNil)
+	c.Check(args, Equals, "console=ttyS0 console=tty1 panic=-1")
+}
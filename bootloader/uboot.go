@@ -33,6 +33,7 @@ import (
 var (
 	_ Bootloader                             = (*uboot)(nil)
 	_ ExtractedRecoveryKernelImageBootloader = (*uboot)(nil)
+	_ BootSlotBootloader                     = (*uboot)(nil)
 )
 
 type uboot struct {
@@ -198,6 +199,26 @@ func (u *uboot) GetBootVars(names ...string) (map[string]string, error) {
 	return out, nil
 }
 
+// BootSlots returns the A/B slot state from the u-boot environment.
+func (u *uboot) BootSlots() (current, try, status string, err error) {
+	return genericBootSlots(u)
+}
+
+// EnableTryBootSlot makes the next boot try the given A/B slot.
+func (u *uboot) EnableTryBootSlot(slot string) error {
+	return genericEnableTryBootSlot(u, slot)
+}
+
+// CommitTryBootSlot makes the tried A/B slot the default one.
+func (u *uboot) CommitTryBootSlot() error {
+	return genericCommitTryBootSlot(u)
+}
+
+// DisableTryBootSlot clears the A/B slot try state.
+func (u *uboot) DisableTryBootSlot() error {
+	return genericDisableTryBootSlot(u)
+}
+
 func (u *uboot) ExtractKernelAssets(s snap.PlaceInfo, snapf snap.Container) error {
 	dstDir := filepath.Join(u.dir(), s.Filename())
 	assets := []string{"kernel.img", "initrd.img", "dtbs/*"}
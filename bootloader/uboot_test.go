@@ -84,6 +84,44 @@ func (s *ubootTestSuite) TestGetBootloaderWithUboot(c *C) {
 	c.Assert(bootloader.Name(), Equals, "uboot")
 }
 
+func (s *ubootTestSuite) TestUbootBootSlots(c *C) {
+	bootloader.MockUbootFiles(c, s.rootdir, nil)
+	u := bootloader.NewUboot(s.rootdir, nil)
+	bsb, ok := u.(bootloader.BootSlotBootloader)
+	c.Assert(ok, Equals, true)
+
+	err := u.SetBootVars(map[string]string{"snapd_boot_slot": "a"})
+	c.Assert(err, IsNil)
+
+	// nothing to commit yet
+	err = bsb.CommitTryBootSlot()
+	c.Assert(err, ErrorMatches, "cannot commit boot slot: no boot slot is being tried")
+
+	err = bsb.EnableTryBootSlot("b")
+	c.Assert(err, IsNil)
+	current, try, status, err := bsb.BootSlots()
+	c.Assert(err, IsNil)
+	c.Check([]string{current, try, status}, DeepEquals, []string{"a", "b", "try"})
+
+	// fallback
+	err = bsb.DisableTryBootSlot()
+	c.Assert(err, IsNil)
+	current, try, status, err = bsb.BootSlots()
+	c.Assert(err, IsNil)
+	c.Check([]string{current, try, status}, DeepEquals, []string{"a", "", ""})
+
+	// successful try, as set by the boot script
+	err = bsb.EnableTryBootSlot("b")
+	c.Assert(err, IsNil)
+	err = u.SetBootVars(map[string]string{"boot_slot_status": "trying"})
+	c.Assert(err, IsNil)
+	err = bsb.CommitTryBootSlot()
+	c.Assert(err, IsNil)
+	current, try, status, err = bsb.BootSlots()
+	c.Assert(err, IsNil)
+	c.Check([]string{current, try, status}, DeepEquals, []string{"b", "", ""})
+}
+
 func (s *ubootTestSuite) TestUbootSetEnvNoUselessWrites(c *C) {
 	bootloader.MockUbootFiles(c, s.rootdir, nil)
 	u := bootloader.NewUboot(s.rootdir, nil)
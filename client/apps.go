@@ -39,7 +39,7 @@ import (
 // system.
 type AppActivator struct {
 	Name string `json:"name"`
-	// Type describes the type of the unit, either dbus, socket, or timer
+	// Type describes the type of the unit, either dbus, socket, timer, path or device
 	Type    string `json:"type"`
 	Active  bool   `json:"active"`
 	Enabled bool   `json:"enabled"`
@@ -56,6 +56,28 @@ type AppInfo struct {
 	Active      bool             `json:"active,omitempty"`
 	CommonID    string           `json:"common-id,omitempty"`
 	Activators  []AppActivator   `json:"activators,omitempty"`
+	// History holds the recent state transitions of the service, oldest
+	// first, if requested.
+	History []ServiceStatusEvent `json:"history,omitempty"`
+	// Overrides holds the settings of the service overridden by the
+	// system administrator, if any.
+	Overrides *ServiceOverrides `json:"overrides,omitempty"`
+}
+
+// ServiceOverrides holds the settings of a service overridden through the
+// services.<snap>.<app>.* system options.
+type ServiceOverrides struct {
+	RestartCondition string            `json:"restart-condition,omitempty"`
+	RestartDelay     string            `json:"restart-delay,omitempty"`
+	Timer            string            `json:"timer,omitempty"`
+	Environment      map[string]string `json:"environment,omitempty"`
+}
+
+// ServiceStatusEvent is the transition of a service to a state, one of
+// "active", "inactive" or "failed".
+type ServiceStatusEvent struct {
+	Time  time.Time `json:"time"`
+	State string    `json:"state"`
 }
 
 // MarshalJSON marshals the AppActivator in such a way to retain
@@ -103,6 +125,9 @@ type AppOptions struct {
 	// of the services for the current user, or the global enable status.
 	// For root-users, global is always implied.
 	Global bool
+	// History if set, also returns the recent state transitions of the
+	// services. Only relevant together with Service.
+	History bool
 }
 
 // Apps returns information about all matching apps. Each name can be
@@ -119,6 +144,9 @@ func (client *Client) Apps(names []string, opts AppOptions) ([]*AppInfo, error)
 	if opts.Global {
 		q.Add("global", fmt.Sprintf("%t", opts.Global))
 	}
+	if opts.History {
+		q.Add("history", fmt.Sprintf("%t", opts.History))
+	}
 
 	var appInfos []*AppInfo
 	_, err := client.doSync("GET", "/v2/apps", q, nil, nil, &appInfos)
@@ -130,14 +158,22 @@ func (client *Client) Apps(names []string, opts AppOptions) ([]*AppInfo, error)
 type LogOptions struct {
 	N      int  // The maximum number of log lines to retrieve initially. If <0, no limit.
 	Follow bool // Whether to continue returning new lines as they appear
+
+	Priority  string    // The priority, or range of priorities, of the lines, e.g. "err" or "warning..emerg"
+	Since     time.Time // Only return lines logged at or after this time
+	Until     time.Time // Only return lines logged at or before this time
+	Grep      string    // Only return lines whose message matches this pattern
+	Boot      string    // Only return lines logged during the given boot, by ID or offset
+	AllFields bool      // Whether to return all the journal fields of each line
 }
 
 // A Log holds the information of a single syslog entry
 type Log struct {
-	Timestamp time.Time `json:"timestamp"` // Timestamp of the event, in RFC3339 format to µs precision.
-	Message   string    `json:"message"`   // The log message itself
-	SID       string    `json:"sid"`       // The syslog identifier
-	PID       string    `json:"pid"`       // The process identifier
+	Timestamp time.Time         `json:"timestamp"`        // Timestamp of the event, in RFC3339 format to µs precision.
+	Message   string            `json:"message"`          // The log message itself
+	SID       string            `json:"sid"`              // The syslog identifier
+	PID       string            `json:"pid"`              // The process identifier
+	Fields    map[string]string `json:"fields,omitempty"` // All the journal fields, if requested
 }
 
 // String will format the log entry with the timestamp in the local timezone
@@ -168,6 +204,24 @@ func (client *Client) Logs(names []string, opts LogOptions) (<-chan Log, error)
 	if opts.Follow {
 		query.Set("follow", strconv.FormatBool(opts.Follow))
 	}
+	if opts.Priority != "" {
+		query.Set("priority", opts.Priority)
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", opts.Since.Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		query.Set("until", opts.Until.Format(time.RFC3339))
+	}
+	if opts.Grep != "" {
+		query.Set("grep", opts.Grep)
+	}
+	if opts.Boot != "" {
+		query.Set("boot", opts.Boot)
+	}
+	if opts.AllFields {
+		query.Set("fields", "all")
+	}
 
 	rsp, err := client.raw(context.Background(), "GET", "/v2/logs", query, nil, nil)
 	if err != nil {
@@ -22,8 +22,10 @@ package client_test
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -77,7 +79,20 @@ func testClientAppsGlobal(cs *clientSuite, c *check.C) ([]*client.AppInfo, error
 	return services, err
 }
 
-var appcheckers = []func(*clientSuite, *check.C) ([]*client.AppInfo, error){testClientApps, testClientAppsService, testClientAppsGlobal}
+func testClientAppsServiceHistory(cs *clientSuite, c *check.C) ([]*client.AppInfo, error) {
+	services, err := cs.cli.Apps([]string{"foo", "bar"}, client.AppOptions{Service: true, History: true})
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/apps")
+	c.Check(cs.req.Method, check.Equals, "GET")
+	query := cs.req.URL.Query()
+	c.Check(query, check.HasLen, 3)
+	c.Check(query.Get("names"), check.Equals, "foo,bar")
+	c.Check(query.Get("select"), check.Equals, "service")
+	c.Check(query.Get("history"), check.Equals, "true")
+
+	return services, err
+}
+
+var appcheckers = []func(*clientSuite, *check.C) ([]*client.AppInfo, error){testClientApps, testClientAppsService, testClientAppsGlobal, testClientAppsServiceHistory}
 
 func (cs *clientSuite) TestClientAppActivatorsMarshalJSON(c *check.C) {
 	appInfo := []*client.AppInfo{
@@ -248,6 +263,39 @@ func (cs *clientSuite) TestClientLogsOpts(c *check.C) {
 	}
 }
 
+func (cs *clientSuite) TestClientLogsFilterOpts(c *check.C) {
+	cs.rsp = "\x1e" + `{"message":"hello","fields":{"MESSAGE":"hello","PRIORITY":"3"}}` + "\n"
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ch, err := cs.cli.Logs([]string{"foo"}, client.LogOptions{
+		N:         10,
+		Priority:  "err",
+		Since:     since,
+		Until:     since.Add(time.Hour),
+		Grep:      "oops",
+		Boot:      "-1",
+		AllFields: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"names":    {"foo"},
+		"n":        {"10"},
+		"priority": {"err"},
+		"since":    {"2024-01-02T03:04:05Z"},
+		"until":    {"2024-01-02T04:04:05Z"},
+		"grep":     {"oops"},
+		"boot":     {"-1"},
+		"fields":   {"all"},
+	})
+	var logs []client.Log
+	for log := range ch {
+		logs = append(logs, log)
+	}
+	c.Check(logs, check.DeepEquals, []client.Log{{
+		Message: "hello",
+		Fields:  map[string]string{"MESSAGE": "hello", "PRIORITY": "3"},
+	}})
+}
+
 func (cs *clientSuite) TestClientLogsNotFound(c *check.C) {
 	cs.rsp = `{"type":"error","status-code":404,"status":"Not Found","result":{"message":"snap \"foo\" not found","kind":"snap-not-found","value":"foo"}}`
 	cs.status = 404
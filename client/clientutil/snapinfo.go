@@ -101,7 +101,7 @@ func ClientAppInfoNotes(app *client.AppInfo) string {
 	if app.DaemonScope == snap.UserDaemon {
 		notes = append(notes, "user")
 	}
-	var seenTimer, seenSocket, seenDbus bool
+	var seenTimer, seenSocket, seenDbus, seenPath, seenDevice bool
 	for _, act := range app.Activators {
 		switch act.Type {
 		case "timer":
@@ -110,6 +110,10 @@ func ClientAppInfoNotes(app *client.AppInfo) string {
 			seenSocket = true
 		case "dbus":
 			seenDbus = true
+		case "path":
+			seenPath = true
+		case "device":
+			seenDevice = true
 		}
 	}
 	if seenTimer {
@@ -121,6 +125,12 @@ func ClientAppInfoNotes(app *client.AppInfo) string {
 	if seenDbus {
 		notes = append(notes, "dbus-activated")
 	}
+	if seenPath {
+		notes = append(notes, "path-activated")
+	}
+	if seenDevice {
+		notes = append(notes, "device-activated")
+	}
 	if len(notes) == 0 {
 		return "-"
 	}
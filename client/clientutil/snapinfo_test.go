@@ -110,6 +110,7 @@ func (*cmdSuite) TestClientSnapFromSnapInfo(c *C) {
 		"DownloadSize",
 		"InstalledSize",
 		"Health",
+		"HealthHistory",
 		"Status",
 		"TrackingChannel",
 		"IgnoreValidation",
@@ -308,6 +309,22 @@ func (*cmdSuite) TestAppStatusNotes(c *C) {
 	}
 	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "dbus-activated")
 
+	ai = client.AppInfo{
+		Daemon: "simple",
+		Activators: []client.AppActivator{
+			{Type: "path"},
+		},
+	}
+	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "path-activated")
+
+	ai = client.AppInfo{
+		Daemon: "simple",
+		Activators: []client.AppActivator{
+			{Type: "device"},
+		},
+	}
+	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "device-activated")
+
 	// check that the output is stable regardless of the order of activators
 	ai = client.AppInfo{
 		Daemon: "oneshot",
@@ -0,0 +1,192 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/snapcore/snapd/asserts"
+)
+
+// ClusterDevice is a device of a cluster.
+type ClusterDevice struct {
+	ID        int      `json:"id"`
+	Device    string   `json:"device"`
+	Addresses []string `json:"addresses"`
+}
+
+// ClusterSnap is a snap expected in a subcluster.
+type ClusterSnap struct {
+	Instance string `json:"instance"`
+	State    string `json:"state"`
+	Channel  string `json:"channel,omitempty"`
+}
+
+// ClusterSubcluster is a subcluster of a cluster, made of some of its
+// devices and the snaps they run.
+type ClusterSubcluster struct {
+	Name    string        `json:"name"`
+	Devices []int         `json:"devices"`
+	Snaps   []ClusterSnap `json:"snaps,omitempty"`
+}
+
+// Cluster holds the details of the current cluster assertion.
+type Cluster struct {
+	ClusterID   string              `json:"cluster-id"`
+	Sequence    int                 `json:"sequence"`
+	AuthorityID string              `json:"authority-id"`
+	Devices     []ClusterDevice     `json:"devices"`
+	Subclusters []ClusterSubcluster `json:"subclusters"`
+}
+
+// ClusterAssembled is the result of the last successful assemble session.
+type ClusterAssembled struct {
+	ChangeID  string          `json:"change-id"`
+	Completed time.Time       `json:"completed"`
+	Devices   []ClusterDevice `json:"devices"`
+}
+
+// ClusterDrift describes a snap that differs from the state expected by a
+// subcluster.
+type ClusterDrift struct {
+	Subcluster string `json:"subcluster"`
+	Snap       string `json:"snap"`
+	// Kind is one of "missing", "channel" or "present".
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// ClusterCompliance is the result of the last comparison of the snaps of the
+// device with the state expected by its subclusters.
+type ClusterCompliance struct {
+	ClusterID string         `json:"cluster-id"`
+	Sequence  int            `json:"sequence"`
+	Checked   time.Time      `json:"checked"`
+	Drift     []ClusterDrift `json:"drift,omitempty"`
+}
+
+// ClusterStatus describes the clustering state of the device.
+type ClusterStatus struct {
+	ClusterID      string             `json:"cluster-id,omitempty"`
+	Sequence       int                `json:"sequence,omitempty"`
+	DeviceID       int                `json:"device-id,omitempty"`
+	Subclusters    []string           `json:"subclusters,omitempty"`
+	ApplyChanges   []string           `json:"apply-changes,omitempty"`
+	AssembleChange string             `json:"assemble-change,omitempty"`
+	Assembled      *ClusterAssembled  `json:"assembled,omitempty"`
+	Compliance     *ClusterCompliance `json:"compliance,omitempty"`
+}
+
+// ClusterAssembleOptions are the parameters of an assemble session.
+type ClusterAssembleOptions struct {
+	// Secret is shared by all the devices taking part in the session.
+	Secret string
+	// Address is the ip:port address the device listens on for its peers,
+	// optional when discovering peers.
+	Address string
+	// ExpectedSize is the number of devices expected in the cluster.
+	ExpectedSize int
+	// Peers are addresses of peers known upfront.
+	Peers []string
+	// Discover enables the discovery of peers on the local network.
+	Discover bool
+	// Interface is the network interface peers are discovered on.
+	Interface string
+	// Subnet restricts the discovered peers to the given subnet.
+	Subnet string
+	// Timeout bounds the duration of the session.
+	Timeout time.Duration
+}
+
+type clusterAssembleData struct {
+	Action       string   `json:"action"`
+	Secret       string   `json:"secret"`
+	Address      string   `json:"address,omitempty"`
+	ExpectedSize int      `json:"expected-size,omitempty"`
+	Peers        []string `json:"peers,omitempty"`
+	Discover     bool     `json:"discover,omitempty"`
+	Interface    string   `json:"interface,omitempty"`
+	Subnet       string   `json:"subnet,omitempty"`
+	Timeout      string   `json:"timeout,omitempty"`
+}
+
+// ClusterAssemble starts an assemble session with the devices that share the
+// given secret.
+func (client *Client) ClusterAssemble(opts ClusterAssembleOptions) (changeID string, err error) {
+	data := clusterAssembleData{
+		Action:       "assemble",
+		Secret:       opts.Secret,
+		Address:      opts.Address,
+		ExpectedSize: opts.ExpectedSize,
+		Peers:        opts.Peers,
+		Discover:     opts.Discover,
+		Interface:    opts.Interface,
+		Subnet:       opts.Subnet,
+	}
+	if opts.Timeout != 0 {
+		data.Timeout = opts.Timeout.String()
+	}
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal cluster assemble data: %v", err)
+	}
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	return client.doAsync("POST", "/v2/cluster", nil, headers, bytes.NewReader(b))
+}
+
+// Cluster returns the current cluster assertion of the device.
+func (client *Client) Cluster() (*Cluster, error) {
+	var cluster Cluster
+	if _, err := client.doSync("GET", "/v2/cluster", nil, nil, nil, &cluster); err != nil {
+		return nil, xerrors.Errorf("cannot get cluster: %w", err)
+	}
+	return &cluster, nil
+}
+
+// ClusterStatus returns the clustering state of the device.
+func (client *Client) ClusterStatus() (*ClusterStatus, error) {
+	var status ClusterStatus
+	if _, err := client.doSync("GET", "/v2/cluster/status", nil, nil, nil, &status); err != nil {
+		return nil, xerrors.Errorf("cannot get cluster status: %w", err)
+	}
+	return &status, nil
+}
+
+// ClusterUpdate imports a bundle with a cluster assertion and its
+// prerequisites, the cluster assertion becomes the current one.
+func (client *Client) ClusterUpdate(bundle io.Reader) error {
+	headers := map[string]string{
+		"Content-Type": asserts.MediaType,
+	}
+	if _, err := client.doSync("POST", "/v2/cluster", nil, headers, bundle, nil); err != nil {
+		return err
+	}
+	return nil
+}
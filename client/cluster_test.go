@@ -0,0 +1,190 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+)
+
+func (cs *clientSuite) TestClientClusterAssemble(c *C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": {},
+		"change": "42"
+	}`
+	id, err := cs.cli.ClusterAssemble(client.ClusterAssembleOptions{
+		Secret:       "secret",
+		Address:      "10.0.0.1:8001",
+		ExpectedSize: 3,
+		Peers:        []string{"10.0.0.2:8001"},
+		Timeout:      10 * time.Minute,
+	})
+	c.Assert(err, IsNil)
+	c.Check(id, Equals, "42")
+	c.Check(cs.req.Method, Equals, "POST")
+	c.Check(cs.req.URL.Path, Equals, "/v2/cluster")
+	c.Check(cs.req.Header.Get("Content-Type"), Equals, "application/json")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var data map[string]any
+	c.Assert(json.Unmarshal(body, &data), IsNil)
+	c.Check(data, DeepEquals, map[string]any{
+		"action":        "assemble",
+		"secret":        "secret",
+		"address":       "10.0.0.1:8001",
+		"expected-size": 3.0,
+		"peers":         []any{"10.0.0.2:8001"},
+		"timeout":       "10m0s",
+	})
+}
+
+func (cs *clientSuite) TestClientClusterAssembleDiscover(c *C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": {},
+		"change": "42"
+	}`
+	_, err := cs.cli.ClusterAssemble(client.ClusterAssembleOptions{
+		Secret:    "secret",
+		Discover:  true,
+		Interface: "eth0",
+		Subnet:    "10.0.0.0/24",
+	})
+	c.Assert(err, IsNil)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var data map[string]any
+	c.Assert(json.Unmarshal(body, &data), IsNil)
+	c.Check(data, DeepEquals, map[string]any{
+		"action":    "assemble",
+		"secret":    "secret",
+		"discover":  true,
+		"interface": "eth0",
+		"subnet":    "10.0.0.0/24",
+	})
+}
+
+func (cs *clientSuite) TestClientCluster(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"cluster-id": "cluster-id",
+			"sequence": 2,
+			"authority-id": "my-brand",
+			"devices": [{"id": 1, "device": "serial-1.my-model.my-brand", "addresses": ["10.0.0.1:8001"]}],
+			"subclusters": [{"name": "default", "devices": [1], "snaps": [{"instance": "hello", "state": "clustered", "channel": "stable"}]}]
+		}
+	}`
+	cluster, err := cs.cli.Cluster()
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/cluster")
+	c.Check(cluster, DeepEquals, &client.Cluster{
+		ClusterID:   "cluster-id",
+		Sequence:    2,
+		AuthorityID: "my-brand",
+		Devices: []client.ClusterDevice{
+			{ID: 1, Device: "serial-1.my-model.my-brand", Addresses: []string{"10.0.0.1:8001"}},
+		},
+		Subclusters: []client.ClusterSubcluster{{
+			Name:    "default",
+			Devices: []int{1},
+			Snaps:   []client.ClusterSnap{{Instance: "hello", State: "clustered", Channel: "stable"}},
+		}},
+	})
+}
+
+func (cs *clientSuite) TestClientClusterNotFound(c *C) {
+	cs.status = 404
+	cs.rsp = `{
+		"type": "error",
+		"status-code": 404,
+		"result": {"message": "no cluster assertion yet", "kind": "assertion-not-found", "value": "cluster"}
+	}`
+	_, err := cs.cli.Cluster()
+	c.Assert(err, ErrorMatches, "cannot get cluster: no cluster assertion yet")
+}
+
+func (cs *clientSuite) TestClientClusterStatus(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"cluster-id": "cluster-id",
+			"sequence": 2,
+			"device-id": 1,
+			"subclusters": ["default"],
+			"apply-changes": ["12"],
+			"assembled": {
+				"change-id": "7",
+				"completed": "2026-01-02T03:04:05Z",
+				"devices": [{"id": 1, "device": "serial-1.my-model.my-brand", "addresses": ["10.0.0.1:8001"]}]
+			}
+		}
+	}`
+	status, err := cs.cli.ClusterStatus()
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/cluster/status")
+	c.Check(status, DeepEquals, &client.ClusterStatus{
+		ClusterID:    "cluster-id",
+		Sequence:     2,
+		DeviceID:     1,
+		Subclusters:  []string{"default"},
+		ApplyChanges: []string{"12"},
+		Assembled: &client.ClusterAssembled{
+			ChangeID:  "7",
+			Completed: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Devices: []client.ClusterDevice{
+				{ID: 1, Device: "serial-1.my-model.my-brand", Addresses: []string{"10.0.0.1:8001"}},
+			},
+		},
+	})
+}
+
+func (cs *clientSuite) TestClientClusterUpdate(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {}
+	}`
+	err := cs.cli.ClusterUpdate(strings.NewReader("bundle"))
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "POST")
+	c.Check(cs.req.URL.Path, Equals, "/v2/cluster")
+	c.Check(cs.req.Header.Get("Content-Type"), Equals, "application/x.ubuntu.assertion")
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	c.Check(string(body), Equals, "bundle")
+}
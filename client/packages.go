@@ -85,6 +85,8 @@ type Snap struct {
 	Tracks []string `json:"tracks,omitempty"`
 
 	Health *SnapHealth `json:"health,omitempty"`
+	// HealthHistory holds the most recent healths of the snap, oldest first.
+	HealthHistory []SnapHealth `json:"health-history,omitempty"`
 
 	// Hold is the time until which the snap's refreshes are held by the user.
 	Hold *time.Time `json:"hold,omitempty"`
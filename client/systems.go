@@ -352,6 +352,10 @@ type CreateSystemOptions struct {
 	// the store. In the JSON variant of the API, only pre-installed
 	// snaps/assertions will be considered.
 	Offline bool `json:"offline,omitempty"`
+	// CaptureState is true if the system should capture the installed
+	// revisions of all snaps, including the ones not in the model, and their
+	// configuration.
+	CaptureState bool `json:"capture-state,omitempty"`
 }
 
 // QualityCheckOptions contains the passphrase or PIN whose quality should be checked.
@@ -0,0 +1,361 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package discovery finds the peers taking part in an assemble session on the
+// local network, using DNS-SD over multicast DNS. Each device advertises the
+// address of its assemble listener as an instance of [ServiceType] and browses
+// for the instances advertised by the other devices.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+)
+
+// ServiceType is the DNS-SD service type of assemble listeners.
+const ServiceType = "_snapd-assemble._tcp.local."
+
+const (
+	// ttls recommended by RFC 6762, section 10
+	hostTTL    = 120
+	serviceTTL = 4500
+
+	maxPacketSize = 9000
+)
+
+var (
+	mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+	interfaceByName    = net.InterfaceByName
+	interfaceAddrs     = net.InterfaceAddrs
+	listenMulticastUDP = net.ListenMulticastUDP
+)
+
+// Config contains the parameters of the discovery of the peers of an
+// assemble session.
+type Config struct {
+	// Instance is the name this device advertises its listener under. It
+	// must be unique among the devices on the network.
+	Instance string
+	// Address is the ip:port address of the assemble listener of this
+	// device, see [AdvertiseAddress].
+	Address string
+	// Subnet restricts the discovered peers to the ones with an address
+	// within it, when set.
+	Subnet *net.IPNet
+	// Period is how often the listener is announced, the peers are queried
+	// and the discovered addresses are published.
+	Period time.Duration
+}
+
+// Listen joins the multicast DNS group on the named interface, or on the
+// default multicast interface of the system when no name is given.
+func Listen(iface string) (net.PacketConn, error) {
+	var ifi *net.Interface
+	if iface != "" {
+		var err error
+		ifi, err = interfaceByName(iface)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return listenMulticastUDP("udp4", ifi, mdnsGroup)
+}
+
+// AdvertiseAddress returns the address to advertise for an assemble listener
+// listening on the given address. When the listener is bound to the
+// unspecified address, the first IPv4 address of the named interface, or of
+// any interface when no name is given, that is within the subnet is used.
+func AdvertiseAddress(listen string, iface string, subnet *net.IPNet) (string, error) {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", err
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && !ip.IsUnspecified() {
+		if ip.To4() == nil {
+			return "", fmt.Errorf("cannot advertise non-IPv4 address %s", ip)
+		}
+		if subnet != nil && !subnet.Contains(ip) {
+			return "", fmt.Errorf("cannot advertise address %s outside of subnet %s", ip, subnet)
+		}
+		return listen, nil
+	}
+
+	var addrs []net.Addr
+	if iface != "" {
+		ifi, err := interfaceByName(iface)
+		if err != nil {
+			return "", err
+		}
+		addrs, err = ifi.Addrs()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		addrs, err = interfaceAddrs()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipnet.IP.To4()
+		if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+			continue
+		}
+		if subnet != nil && !subnet.Contains(ip) {
+			continue
+		}
+		return net.JoinHostPort(ip.String(), port), nil
+	}
+
+	if subnet != nil {
+		return "", fmt.Errorf("cannot find an IPv4 address to advertise within subnet %s", subnet)
+	}
+	return "", errors.New("cannot find an IPv4 address to advertise")
+}
+
+type discoverer struct {
+	conn     net.PacketConn
+	config   Config
+	instance string
+	host     string
+	ip       net.IP
+	port     uint16
+
+	// found maps the instances of the peers to their addresses.
+	found map[string]string
+}
+
+// Run advertises the assemble listener of this device and sends the addresses
+// of the discovered peers to discoveries, until the context is cancelled. All
+// the addresses discovered so far are sent again periodically, so that peers
+// that could not be reached at first are eventually retried. Run takes
+// ownership of the connection and closes it when returning.
+func Run(ctx context.Context, conn net.PacketConn, config Config, discoveries chan<- []string) error {
+	defer conn.Close()
+
+	if config.Instance == "" || strings.Contains(config.Instance, ".") {
+		return fmt.Errorf("invalid instance name %q", config.Instance)
+	}
+	if config.Period <= 0 {
+		return errors.New("discovery period must be positive")
+	}
+
+	host, port, err := net.SplitHostPort(config.Address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil || ip.IsUnspecified() {
+		return fmt.Errorf("cannot advertise address %q: must be an IPv4 address", config.Address)
+	}
+	portNum, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return fmt.Errorf("cannot advertise address %q: invalid port", config.Address)
+	}
+
+	d := discoverer{
+		conn:     conn,
+		config:   config,
+		instance: config.Instance + "." + ServiceType,
+		host:     config.Instance + ".local.",
+		ip:       ip,
+		port:     uint16(portNum),
+		found:    make(map[string]string),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	packets := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			buf := make([]byte, maxPacketSize)
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case packets <- buf[:n]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(config.Period)
+	defer ticker.Stop()
+
+	d.announce()
+	for {
+		var discovered []string
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("cannot receive multicast dns messages: %w", err)
+		case <-ticker.C:
+			d.announce()
+			discovered = d.addresses()
+		case packet := <-packets:
+			discovered = d.handle(packet)
+		}
+
+		if len(discovered) == 0 {
+			continue
+		}
+
+		select {
+		case discoveries <- discovered:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// records returns the records advertising the assemble listener.
+func (d *discoverer) records() []record {
+	return []record{
+		{name: ServiceType, rtype: typePTR, class: classIN, ttl: serviceTTL, target: d.instance},
+		{name: d.instance, rtype: typeSRV, class: classIN | cacheFlush, ttl: hostTTL, target: d.host, port: d.port},
+		{name: d.instance, rtype: typeTXT, class: classIN | cacheFlush, ttl: serviceTTL},
+		{name: d.host, rtype: typeA, class: classIN | cacheFlush, ttl: hostTTL, ip: d.ip},
+	}
+}
+
+// announce sends an unsolicited response advertising our listener, followed
+// by a query for the listeners of our peers.
+func (d *discoverer) announce() {
+	d.send(&message{response: true, answers: d.records()})
+	d.send(&message{questions: []question{{name: ServiceType, qtype: typePTR}}})
+}
+
+func (d *discoverer) send(m *message) {
+	b, err := m.pack()
+	if err != nil {
+		logger.Debugf("cannot pack multicast dns message: %v", err)
+		return
+	}
+	if _, err := d.conn.WriteTo(b, mdnsGroup); err != nil {
+		logger.Debugf("cannot send multicast dns message: %v", err)
+	}
+}
+
+// handle answers queries for our service type and records the peers found in
+// responses, returning the addresses of the newly discovered ones.
+func (d *discoverer) handle(packet []byte) []string {
+	m, err := unpackMessage(packet)
+	if err != nil {
+		logger.Debugf("cannot unpack multicast dns message: %v", err)
+		return nil
+	}
+
+	if !m.response {
+		for _, q := range m.questions {
+			if strings.EqualFold(q.name, ServiceType) && (q.qtype == typePTR || q.qtype == typeAny) {
+				d.send(&message{response: true, answers: d.records()})
+				break
+			}
+		}
+		return nil
+	}
+
+	// we only consider peers for which the whole chain of records is part
+	// of the same response, which is how they are sent by all the devices
+	// taking part in the session
+	var instances []string
+	srvs := make(map[string]record)
+	ips := make(map[string]net.IP)
+	for _, r := range m.answers {
+		// records with a zero ttl announce that they are going away
+		if r.class&classMask != classIN || r.ttl == 0 {
+			continue
+		}
+		name := strings.ToLower(r.name)
+		switch r.rtype {
+		case typePTR:
+			if name == ServiceType {
+				instances = append(instances, strings.ToLower(r.target))
+			}
+		case typeSRV:
+			srvs[name] = r
+		case typeA:
+			ips[name] = r.ip
+		}
+	}
+
+	var discovered []string
+	for _, instance := range instances {
+		if instance == strings.ToLower(d.instance) {
+			continue
+		}
+		srv, ok := srvs[instance]
+		if !ok {
+			continue
+		}
+		ip, ok := ips[strings.ToLower(srv.target)]
+		if !ok {
+			continue
+		}
+		if d.config.Subnet != nil && !d.config.Subnet.Contains(ip) {
+			logger.Debugf("ignoring peer %s outside of subnet %s", ip, d.config.Subnet)
+			continue
+		}
+
+		addr := net.JoinHostPort(ip.String(), strconv.Itoa(int(srv.port)))
+		if d.found[instance] == addr {
+			continue
+		}
+		logger.Debugf("discovered peer %s at %s", instance, addr)
+		d.found[instance] = addr
+		discovered = append(discovered, addr)
+	}
+
+	return discovered
+}
+
+// addresses returns the addresses of all the peers discovered so far.
+func (d *discoverer) addresses() []string {
+	addrs := make([]string, 0, len(d.found))
+	for _, addr := range d.found {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
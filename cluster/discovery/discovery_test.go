@@ -0,0 +1,311 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/discovery"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type discoverySuite struct{}
+
+var _ = check.Suite(&discoverySuite{})
+
+// bus simulates a multicast group, messages sent by one connection are
+// received by all the others.
+type bus struct {
+	lock  sync.Mutex
+	conns []*busConn
+}
+
+func (b *bus) conn() *busConn {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	conn := &busConn{
+		bus:    b,
+		in:     make(chan []byte, 64),
+		closed: make(chan struct{}),
+	}
+	b.conns = append(b.conns, conn)
+	return conn
+}
+
+type busConn struct {
+	bus    *bus
+	in     chan []byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (c *busConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	select {
+	case msg := <-c.in:
+		return copy(p, msg), &net.UDPAddr{}, nil
+	case <-c.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (c *busConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	c.bus.lock.Lock()
+	defer c.bus.lock.Unlock()
+	for _, other := range c.bus.conns {
+		if other == c {
+			continue
+		}
+		select {
+		case other.in <- append([]byte(nil), p...):
+		default:
+			// like any multicast packet, it might be dropped
+		}
+	}
+	return len(p), nil
+}
+
+func (c *busConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *busConn) LocalAddr() net.Addr                { return &net.UDPAddr{} }
+func (c *busConn) SetDeadline(t time.Time) error      { return nil }
+func (c *busConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *busConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type runner struct {
+	discoveries chan []string
+	done        chan error
+}
+
+func run(ctx context.Context, conn net.PacketConn, config discovery.Config) *runner {
+	r := &runner{
+		discoveries: make(chan []string),
+		done:        make(chan error, 1),
+	}
+	go func() {
+		r.done <- discovery.Run(ctx, conn, config, r.discoveries)
+	}()
+	return r
+}
+
+func (r *runner) next(c *check.C) []string {
+	select {
+	case addrs := <-r.discoveries:
+		return addrs
+	case err := <-r.done:
+		c.Fatalf("discovery stopped unexpectedly: %v", err)
+	case <-time.After(5 * time.Second):
+		c.Fatal("timed out waiting for discoveries")
+	}
+	return nil
+}
+
+func (s *discoverySuite) TestDiscoverPeers(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	c.Assert(err, check.IsNil)
+
+	var b bus
+	one := run(ctx, b.conn(), discovery.Config{
+		Instance: "one",
+		Address:  "10.0.0.1:8001",
+		Subnet:   subnet,
+		Period:   10 * time.Millisecond,
+	})
+	two := run(ctx, b.conn(), discovery.Config{
+		Instance: "two",
+		Address:  "10.0.0.2:8002",
+		Period:   10 * time.Millisecond,
+	})
+	// outside of the subnet of device one
+	three := run(ctx, b.conn(), discovery.Config{
+		Instance: "three",
+		Address:  "10.0.1.3:8003",
+		Period:   10 * time.Millisecond,
+	})
+
+	// device one only ever reports device two, first when it is discovered
+	// and then periodically
+	for i := 0; i < 5; i++ {
+		c.Check(one.next(c), check.DeepEquals, []string{"10.0.0.2:8002"})
+	}
+
+	// device two finds everyone else, and not itself
+	found := make(map[string]bool)
+	for len(found) < 2 {
+		for _, addr := range two.next(c) {
+			found[addr] = true
+		}
+	}
+	c.Check(found, check.DeepEquals, map[string]bool{
+		"10.0.0.1:8001": true,
+		"10.0.1.3:8003": true,
+	})
+
+	// drain device three so that it doesn't block
+	go func() {
+		for range three.discoveries {
+		}
+	}()
+
+	cancel()
+	for _, r := range []*runner{one, two, three} {
+		select {
+		case err := <-r.done:
+			c.Check(err, check.IsNil)
+		case <-time.After(5 * time.Second):
+			c.Fatal("discovery did not stop")
+		}
+	}
+}
+
+func (s *discoverySuite) TestDiscoverLateJoiner(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// with a long period, device one only announces itself once, before
+	// device two is up. device two still finds it, since device one answers
+	// its query.
+	var b bus
+	one := run(ctx, b.conn(), discovery.Config{
+		Instance: "one",
+		Address:  "10.0.0.1:8001",
+		Period:   time.Hour,
+	})
+	// give device one time to send its announcement to nobody
+	time.Sleep(20 * time.Millisecond)
+
+	two := run(ctx, b.conn(), discovery.Config{
+		Instance: "two",
+		Address:  "10.0.0.2:8002",
+		Period:   time.Hour,
+	})
+
+	c.Check(one.next(c), check.DeepEquals, []string{"10.0.0.2:8002"})
+	c.Check(two.next(c), check.DeepEquals, []string{"10.0.0.1:8001"})
+}
+
+func (s *discoverySuite) TestRunInvalidConfig(c *check.C) {
+	for _, tc := range []struct {
+		config discovery.Config
+		err    string
+	}{
+		{discovery.Config{Address: "10.0.0.1:8001", Period: time.Second}, `invalid instance name ""`},
+		{discovery.Config{Instance: "a.b", Address: "10.0.0.1:8001", Period: time.Second}, `invalid instance name "a.b"`},
+		{discovery.Config{Instance: "one", Address: "10.0.0.1:8001"}, `discovery period must be positive`},
+		{discovery.Config{Instance: "one", Address: "10.0.0.1", Period: time.Second}, `.*missing port in address`},
+		{discovery.Config{Instance: "one", Address: "0.0.0.0:8001", Period: time.Second}, `cannot advertise address "0.0.0.0:8001": must be an IPv4 address`},
+		{discovery.Config{Instance: "one", Address: "[::1]:8001", Period: time.Second}, `cannot advertise address "\[::1\]:8001": must be an IPv4 address`},
+		{discovery.Config{Instance: "one", Address: "10.0.0.1:port", Period: time.Second}, `cannot advertise address "10.0.0.1:port": invalid port`},
+	} {
+		var b bus
+		conn := b.conn()
+		err := discovery.Run(context.Background(), conn, tc.config, nil)
+		c.Check(err, check.ErrorMatches, tc.err)
+
+		// the connection is closed either way
+		select {
+		case <-conn.closed:
+		default:
+			c.Errorf("connection not closed")
+		}
+	}
+}
+
+func (s *discoverySuite) TestAdvertiseAddress(c *check.C) {
+	restore := discovery.MockInterfaceAddrs(func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.IPv4(127, 0, 0, 1), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+			&net.IPNet{IP: net.IPv4(169, 254, 0, 1), Mask: net.CIDRMask(16, 32)},
+			&net.IPNet{IP: net.IPv4(192, 168, 0, 10), Mask: net.CIDRMask(24, 32)},
+			&net.IPNet{IP: net.IPv4(10, 0, 0, 10), Mask: net.CIDRMask(24, 32)},
+		}, nil
+	})
+	defer restore()
+
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	c.Assert(err, check.IsNil)
+	_, otherSubnet, err := net.ParseCIDR("172.16.0.0/16")
+	c.Assert(err, check.IsNil)
+
+	for _, tc := range []struct {
+		listen string
+		subnet *net.IPNet
+		addr   string
+		err    string
+	}{
+		{listen: "10.0.0.1:8001", addr: "10.0.0.1:8001"},
+		{listen: "10.0.0.1:8001", subnet: subnet, addr: "10.0.0.1:8001"},
+		{listen: "0.0.0.0:8001", addr: "192.168.0.10:8001"},
+		{listen: ":8001", subnet: subnet, addr: "10.0.0.10:8001"},
+		{listen: "0.0.0.0:8001", subnet: otherSubnet, err: `cannot find an IPv4 address to advertise within subnet 172.16.0.0/16`},
+		{listen: "10.0.0.1:8001", subnet: otherSubnet, err: `cannot advertise address 10.0.0.1 outside of subnet 172.16.0.0/16`},
+		{listen: "[fd00::1]:8001", err: `cannot advertise non-IPv4 address fd00::1`},
+		{listen: "10.0.0.1", err: `.*missing port in address`},
+	} {
+		addr, err := discovery.AdvertiseAddress(tc.listen, "", tc.subnet)
+		if tc.err != "" {
+			c.Check(err, check.ErrorMatches, tc.err, check.Commentf(tc.listen))
+			continue
+		}
+		c.Check(err, check.IsNil, check.Commentf(tc.listen))
+		c.Check(addr, check.Equals, tc.addr, check.Commentf(tc.listen))
+	}
+
+	restore = discovery.MockInterfaceAddrs(func() ([]net.Addr, error) {
+		return nil, nil
+	})
+	defer restore()
+	_, err = discovery.AdvertiseAddress("0.0.0.0:8001", "", nil)
+	c.Check(err, check.ErrorMatches, "cannot find an IPv4 address to advertise")
+}
+
+func (s *discoverySuite) TestListen(c *check.C) {
+	var calls int
+	restore := discovery.MockListenMulticastUDP(func(network string, ifi *net.Interface, gaddr *net.UDPAddr) (*net.UDPConn, error) {
+		calls++
+		c.Check(network, check.Equals, "udp4")
+		c.Check(ifi, check.IsNil)
+		c.Check(gaddr.String(), check.Equals, "224.0.0.251:5353")
+		return nil, errors.New("boom")
+	})
+	defer restore()
+
+	_, err := discovery.Listen("")
+	c.Check(err, check.ErrorMatches, "boom")
+	c.Check(calls, check.Equals, 1)
+
+	_, err = discovery.Listen("does-not-exist")
+	c.Check(err, check.ErrorMatches, ".*no such network interface")
+	c.Check(calls, check.Equals, 1)
+}
@@ -0,0 +1,38 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package discovery
+
+import (
+	"net"
+
+	"github.com/snapcore/snapd/testutil"
+)
+
+func MockInterfaceAddrs(f func() ([]net.Addr, error)) (restore func()) {
+	restore = testutil.Backup(&interfaceAddrs)
+	interfaceAddrs = f
+	return restore
+}
+
+func MockListenMulticastUDP(f func(network string, ifi *net.Interface, gaddr *net.UDPAddr) (*net.UDPConn, error)) (restore func()) {
+	restore = testutil.Backup(&listenMulticastUDP)
+	listenMulticastUDP = f
+	return restore
+}
@@ -0,0 +1,287 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package discovery
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// The subset of the DNS wire format (RFC 1035) needed for DNS-SD over
+// multicast DNS (RFC 6762, RFC 6763).
+const (
+	typeA   uint16 = 1
+	typePTR uint16 = 12
+	typeTXT uint16 = 16
+	typeSRV uint16 = 33
+	typeAny uint16 = 255
+
+	classIN uint16 = 1
+	// classMask strips the cache-flush bit of records and the unicast
+	// response bit of questions.
+	classMask uint16 = 0x7fff
+	// cacheFlush marks records this host is authoritative for.
+	cacheFlush uint16 = 0x8000
+
+	flagResponse      uint16 = 0x8000
+	flagAuthoritative uint16 = 0x0400
+
+	maxLabelLength = 63
+	maxPointers    = 16
+)
+
+var errTruncated = errors.New("truncated dns message")
+
+type question struct {
+	name  string
+	qtype uint16
+}
+
+type record struct {
+	name  string
+	rtype uint16
+	class uint16
+	ttl   uint32
+
+	// target is the name pointed to by PTR and SRV records.
+	target string
+	// port is the port of SRV records.
+	port uint16
+	// ip is the address of A records.
+	ip net.IP
+}
+
+type message struct {
+	response  bool
+	questions []question
+	answers   []record
+}
+
+func (m *message) pack() ([]byte, error) {
+	var flags uint16
+	if m.response {
+		flags = flagResponse | flagAuthoritative
+	}
+
+	// the ID is always zero in multicast DNS
+	b := make([]byte, 12, 512)
+	binary.BigEndian.PutUint16(b[2:], flags)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(m.questions)))
+	binary.BigEndian.PutUint16(b[6:], uint16(len(m.answers)))
+
+	var err error
+	for _, q := range m.questions {
+		if b, err = appendName(b, q.name); err != nil {
+			return nil, err
+		}
+		b = appendUint16(b, q.qtype)
+		b = appendUint16(b, classIN)
+	}
+
+	for _, r := range m.answers {
+		if b, err = appendName(b, r.name); err != nil {
+			return nil, err
+		}
+		b = appendUint16(b, r.rtype)
+		b = appendUint16(b, r.class)
+		b = appendUint32(b, r.ttl)
+
+		var data []byte
+		switch r.rtype {
+		case typeA:
+			ip := r.ip.To4()
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ipv4 address %v", r.ip)
+			}
+			data = ip
+		case typePTR:
+			if data, err = appendName(nil, r.target); err != nil {
+				return nil, err
+			}
+		case typeSRV:
+			// priority and weight are unused
+			data = make([]byte, 4, 6)
+			data = appendUint16(data, r.port)
+			if data, err = appendName(data, r.target); err != nil {
+				return nil, err
+			}
+		case typeTXT:
+			// DNS-SD requires a TXT record, a single empty string is the
+			// smallest valid one
+			data = []byte{0}
+		default:
+			return nil, fmt.Errorf("unsupported record type %d", r.rtype)
+		}
+		b = appendUint16(b, uint16(len(data)))
+		b = append(b, data...)
+	}
+
+	return b, nil
+}
+
+func appendName(b []byte, name string) ([]byte, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if label == "" || len(label) > maxLabelLength {
+				return nil, fmt.Errorf("invalid dns name %q", name)
+			}
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	return append(b, 0), nil
+}
+
+func unpackMessage(b []byte) (*message, error) {
+	if len(b) < 12 {
+		return nil, errTruncated
+	}
+
+	m := &message{
+		response: binary.BigEndian.Uint16(b[2:])&flagResponse != 0,
+	}
+	qdcount := int(binary.BigEndian.Uint16(b[4:]))
+	// authority and additional records are treated as answers, responders
+	// commonly put the SRV and A records of a service in the additional
+	// section
+	ancount := int(binary.BigEndian.Uint16(b[6:])) +
+		int(binary.BigEndian.Uint16(b[8:])) +
+		int(binary.BigEndian.Uint16(b[10:]))
+
+	off := 12
+	for i := 0; i < qdcount; i++ {
+		name, next, err := readName(b, off)
+		if err != nil {
+			return nil, err
+		}
+		if next+4 > len(b) {
+			return nil, errTruncated
+		}
+		m.questions = append(m.questions, question{
+			name:  name,
+			qtype: binary.BigEndian.Uint16(b[next:]),
+		})
+		off = next + 4
+	}
+
+	for i := 0; i < ancount; i++ {
+		name, next, err := readName(b, off)
+		if err != nil {
+			return nil, err
+		}
+		if next+10 > len(b) {
+			return nil, errTruncated
+		}
+		r := record{
+			name:  name,
+			rtype: binary.BigEndian.Uint16(b[next:]),
+			class: binary.BigEndian.Uint16(b[next+2:]),
+			ttl:   binary.BigEndian.Uint32(b[next+4:]),
+		}
+		length := int(binary.BigEndian.Uint16(b[next+8:]))
+		start := next + 10
+		end := start + length
+		if end > len(b) {
+			return nil, errTruncated
+		}
+
+		switch r.rtype {
+		case typeA:
+			if length != net.IPv4len {
+				return nil, fmt.Errorf("invalid A record length %d", length)
+			}
+			r.ip = net.IP(append([]byte(nil), b[start:end]...))
+		case typePTR:
+			if r.target, _, err = readName(b, start); err != nil {
+				return nil, err
+			}
+		case typeSRV:
+			if length < 7 {
+				return nil, fmt.Errorf("invalid SRV record length %d", length)
+			}
+			r.port = binary.BigEndian.Uint16(b[start+4:])
+			if r.target, _, err = readName(b, start+6); err != nil {
+				return nil, err
+			}
+		}
+
+		// records of other types are kept so that their presence is
+		// visible, but their data is ignored
+		m.answers = append(m.answers, r)
+		off = end
+	}
+
+	return m, nil
+}
+
+// readName reads the possibly compressed name at the given offset, returning
+// it in its fully qualified form along with the offset following it.
+func readName(b []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	pointers := 0
+	for {
+		if off >= len(b) {
+			return "", 0, errTruncated
+		}
+		length := int(b[off])
+		switch {
+		case length == 0:
+			if next == -1 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case length&0xc0 == 0xc0:
+			if off+1 >= len(b) {
+				return "", 0, errTruncated
+			}
+			pointers++
+			if pointers > maxPointers {
+				return "", 0, errors.New("too many compression pointers in dns name")
+			}
+			if next == -1 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(b[off:]) & 0x3fff)
+		case length > maxLabelLength:
+			return "", 0, fmt.Errorf("invalid dns label length %d", length)
+		default:
+			if off+1+length > len(b) {
+				return "", 0, errTruncated
+			}
+			labels = append(labels, string(b[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// TODO:GOVERSION: replace with binary.BigEndian.AppendUint16 and
+// binary.BigEndian.AppendUint32 when we're on go >= 1.19
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,116 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package discovery
+
+import (
+	"net"
+
+	"gopkg.in/check.v1"
+)
+
+type messageSuite struct{}
+
+var _ = check.Suite(&messageSuite{})
+
+func (s *messageSuite) TestPackUnpackRoundTrip(c *check.C) {
+	m := &message{
+		response: true,
+		answers: []record{
+			{name: ServiceType, rtype: typePTR, class: classIN, ttl: serviceTTL, target: "one." + ServiceType},
+			{name: "one." + ServiceType, rtype: typeSRV, class: classIN | cacheFlush, ttl: hostTTL, target: "one.local.", port: 8001},
+			{name: "one.local.", rtype: typeA, class: classIN | cacheFlush, ttl: hostTTL, ip: net.IPv4(10, 0, 0, 1).To4()},
+		},
+	}
+	b, err := m.pack()
+	c.Assert(err, check.IsNil)
+
+	unpacked, err := unpackMessage(b)
+	c.Assert(err, check.IsNil)
+	c.Check(unpacked, check.DeepEquals, m)
+
+	q := &message{questions: []question{{name: ServiceType, qtype: typePTR}}}
+	b, err = q.pack()
+	c.Assert(err, check.IsNil)
+
+	unpacked, err = unpackMessage(b)
+	c.Assert(err, check.IsNil)
+	c.Check(unpacked, check.DeepEquals, q)
+}
+
+func (s *messageSuite) TestPackInvalid(c *check.C) {
+	for _, m := range []*message{
+		{questions: []question{{name: "a..local.", qtype: typePTR}}},
+		{answers: []record{{name: "host.local.", rtype: typeA, ip: net.ParseIP("::1")}}},
+		{answers: []record{{name: "host.local.", rtype: 99}}},
+	} {
+		_, err := m.pack()
+		c.Check(err, check.NotNil)
+	}
+}
+
+func (s *messageSuite) TestUnpackCompressedNames(c *check.C) {
+	b := []byte{
+		// header: response with one answer and one additional record
+		0, 0, 0x84, 0, 0, 0, 0, 1, 0, 0, 0, 1,
+		// offset 12: _snapd-assemble._tcp.local., with local. at offset 33
+		15, '_', 's', 'n', 'a', 'p', 'd', '-', 'a', 's', 's', 'e', 'm', 'b', 'l', 'e',
+		4, '_', 't', 'c', 'p',
+		5, 'l', 'o', 'c', 'a', 'l', 0,
+		// PTR, IN, ttl 4500, 6 bytes of data
+		0, 12, 0, 1, 0, 0, 0x11, 0x94, 0, 6,
+		// one.<pointer to the service type>
+		3, 'o', 'n', 'e', 0xc0, 12,
+		// one.<pointer to local.>
+		3, 'o', 'n', 'e', 0xc0, 33,
+		// A, cache flush IN, ttl 120, 4 bytes of data
+		0, 1, 0x80, 1, 0, 0, 0, 120, 0, 4,
+		10, 0, 0, 1,
+	}
+
+	m, err := unpackMessage(b)
+	c.Assert(err, check.IsNil)
+	c.Check(m.response, check.Equals, true)
+	c.Assert(m.answers, check.HasLen, 2)
+	c.Check(m.answers[0].name, check.Equals, ServiceType)
+	c.Check(m.answers[0].target, check.Equals, "one."+ServiceType)
+	c.Check(m.answers[1].name, check.Equals, "one.local.")
+	c.Check(m.answers[1].class&classMask, check.Equals, classIN)
+	c.Check(m.answers[1].ip.String(), check.Equals, "10.0.0.1")
+}
+
+func (s *messageSuite) TestUnpackInvalid(c *check.C) {
+	for _, b := range [][]byte{
+		// short header
+		{0, 0, 0},
+		// missing question
+		{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+		// truncated label
+		{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 5, 'a'},
+		// pointer loop
+		{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0xc0, 12, 0, 12, 0, 1},
+		// invalid label length
+		{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0x40, 0},
+		// record data past the end of the message
+		{0, 0, 0x84, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 4, 10},
+	} {
+		_, err := unpackMessage(b)
+		c.Check(err, check.NotNil, check.Commentf("%v", b))
+	}
+}
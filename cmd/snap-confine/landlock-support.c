@@ -0,0 +1,305 @@
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+#include "landlock-support.h"
+#include "config.h"
+
+#include <errno.h>
+#include <fcntl.h>
+#include <limits.h>
+#include <pwd.h>
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/stat.h>
+#include <sys/syscall.h>
+#include <unistd.h>
+
+#include "../libsnap-confine-private/cleanup-funcs.h"
+#include "../libsnap-confine-private/string-utils.h"
+#include "../libsnap-confine-private/utils.h"
+
+static const char *landlock_ruleset_dir = "/var/lib/snapd/landlock";
+
+/* The definitions below are replicated from linux/landlock.h so that
+ * snap-confine builds on systems with older kernel headers. */
+
+#ifndef __NR_landlock_create_ruleset
+#define __NR_landlock_create_ruleset 444
+#endif
+#ifndef __NR_landlock_add_rule
+#define __NR_landlock_add_rule 445
+#endif
+#ifndef __NR_landlock_restrict_self
+#define __NR_landlock_restrict_self 446
+#endif
+
+#define SC_LANDLOCK_CREATE_RULESET_VERSION (1U << 0)
+#define SC_LANDLOCK_RULE_PATH_BENEATH 1
+
+#define SC_LANDLOCK_ACCESS_FS_EXECUTE (1ULL << 0)
+#define SC_LANDLOCK_ACCESS_FS_WRITE_FILE (1ULL << 1)
+#define SC_LANDLOCK_ACCESS_FS_READ_FILE (1ULL << 2)
+#define SC_LANDLOCK_ACCESS_FS_READ_DIR (1ULL << 3)
+#define SC_LANDLOCK_ACCESS_FS_REMOVE_DIR (1ULL << 4)
+#define SC_LANDLOCK_ACCESS_FS_REMOVE_FILE (1ULL << 5)
+#define SC_LANDLOCK_ACCESS_FS_MAKE_CHAR (1ULL << 6)
+#define SC_LANDLOCK_ACCESS_FS_MAKE_DIR (1ULL << 7)
+#define SC_LANDLOCK_ACCESS_FS_MAKE_REG (1ULL << 8)
+#define SC_LANDLOCK_ACCESS_FS_MAKE_SOCK (1ULL << 9)
+#define SC_LANDLOCK_ACCESS_FS_MAKE_FIFO (1ULL << 10)
+#define SC_LANDLOCK_ACCESS_FS_MAKE_BLOCK (1ULL << 11)
+#define SC_LANDLOCK_ACCESS_FS_MAKE_SYM (1ULL << 12)
+/* ABI 2 */
+#define SC_LANDLOCK_ACCESS_FS_REFER (1ULL << 13)
+/* ABI 3 */
+#define SC_LANDLOCK_ACCESS_FS_TRUNCATE (1ULL << 14)
+
+/* ABI 4 */
+#define SC_LANDLOCK_ACCESS_NET_BIND_TCP (1ULL << 0)
+#define SC_LANDLOCK_ACCESS_NET_CONNECT_TCP (1ULL << 1)
+
+struct sc_landlock_ruleset_attr {
+    uint64_t handled_access_fs;
+    uint64_t handled_access_net;
+};
+
+struct sc_landlock_path_beneath_attr {
+    uint64_t allowed_access;
+    int32_t parent_fd;
+} __attribute__((packed));
+
+/* Access rights which apply to regular files, the remaining ones only make
+ * sense for directories. */
+#define SC_LANDLOCK_ACCESS_FS_FILE                                                                   \
+    (SC_LANDLOCK_ACCESS_FS_EXECUTE | SC_LANDLOCK_ACCESS_FS_WRITE_FILE | SC_LANDLOCK_ACCESS_FS_READ_FILE | \
+     SC_LANDLOCK_ACCESS_FS_TRUNCATE)
+
+static uint64_t sc_landlock_read_access(void) {
+    return SC_LANDLOCK_ACCESS_FS_READ_FILE | SC_LANDLOCK_ACCESS_FS_READ_DIR;
+}
+
+static uint64_t sc_landlock_write_access(int abi) {
+    uint64_t access = SC_LANDLOCK_ACCESS_FS_WRITE_FILE | SC_LANDLOCK_ACCESS_FS_REMOVE_DIR |
+                      SC_LANDLOCK_ACCESS_FS_REMOVE_FILE | SC_LANDLOCK_ACCESS_FS_MAKE_CHAR |
+                      SC_LANDLOCK_ACCESS_FS_MAKE_DIR | SC_LANDLOCK_ACCESS_FS_MAKE_REG |
+                      SC_LANDLOCK_ACCESS_FS_MAKE_SOCK | SC_LANDLOCK_ACCESS_FS_MAKE_FIFO |
+                      SC_LANDLOCK_ACCESS_FS_MAKE_BLOCK | SC_LANDLOCK_ACCESS_FS_MAKE_SYM;
+    if (abi >= 2) {
+        access |= SC_LANDLOCK_ACCESS_FS_REFER;
+    }
+    if (abi >= 3) {
+        access |= SC_LANDLOCK_ACCESS_FS_TRUNCATE;
+    }
+    return access;
+}
+
+static int sc_landlock_abi_version(void) {
+    long abi = syscall(__NR_landlock_create_ruleset, NULL, 0, SC_LANDLOCK_CREATE_RULESET_VERSION);
+    if (abi < 0) {
+        return -1;
+    }
+    return (int)abi;
+}
+
+/**
+ * Parse the access string of a rule, e.g. "rw", into landlock access rights.
+ **/
+static uint64_t sc_landlock_parse_access(const char *str, int abi, const char *ruleset_path) {
+    uint64_t access = 0;
+    for (const char *c = str; *c != '\0'; c++) {
+        switch (*c) {
+            case 'r':
+                access |= sc_landlock_read_access();
+                break;
+            case 'w':
+                access |= sc_landlock_write_access(abi);
+                break;
+            case 'x':
+                access |= SC_LANDLOCK_ACCESS_FS_EXECUTE;
+                break;
+            default:
+                die("cannot parse landlock ruleset %s: invalid access %s", ruleset_path, str);
+        }
+    }
+    return access;
+}
+
+/**
+ * Expand $HOME at the start of a path and $UID anywhere in it.
+ **/
+static void sc_landlock_expand_path(const char *path, const char *home, uid_t uid, char *buf, size_t buf_size) {
+    char uid_str[32] = {0};
+    sc_must_snprintf(uid_str, sizeof uid_str, "%u", (unsigned)uid);
+
+    sc_string_init(buf, buf_size);
+    if (sc_startswith(path, "$HOME")) {
+        sc_string_append(buf, buf_size, home);
+        path += strlen("$HOME");
+    }
+    while (*path != '\0') {
+        if (sc_startswith(path, "$UID")) {
+            sc_string_append(buf, buf_size, uid_str);
+            path += strlen("$UID");
+        } else {
+            sc_string_append_char(buf, buf_size, *path);
+            path++;
+        }
+    }
+}
+
+static void sc_landlock_add_path_rule(int ruleset_fd, const char *path, uint64_t access) {
+    int fd SC_CLEANUP(sc_cleanup_close) = open(path, O_PATH | O_CLOEXEC);
+    if (fd < 0) {
+        if (errno == ENOENT) {
+            debug("ignoring landlock rule for non-existing path %s", path);
+            return;
+        }
+        die("cannot open %s for landlock rule", path);
+    }
+    struct stat st;
+    if (fstat(fd, &st) != 0) {
+        die("cannot stat %s", path);
+    }
+    if (!S_ISDIR(st.st_mode)) {
+        access &= SC_LANDLOCK_ACCESS_FS_FILE;
+    }
+    struct sc_landlock_path_beneath_attr attr = {
+        .allowed_access = access,
+        .parent_fd = fd,
+    };
+    if (syscall(__NR_landlock_add_rule, ruleset_fd, SC_LANDLOCK_RULE_PATH_BENEATH, &attr, 0) != 0) {
+        die("cannot add landlock rule for %s", path);
+    }
+    debug("landlock rule for %s: %#llx", path, (unsigned long long)access);
+}
+
+int sc_landlock_prepare_ruleset(const char *security_tag, uid_t real_uid, bool required) {
+    struct stat st;
+    if (stat(landlock_ruleset_dir, &st) != 0) {
+        debug("no landlock rulesets in %s", landlock_ruleset_dir);
+        return -1;
+    }
+
+    char ruleset_path[PATH_MAX] = {0};
+    sc_must_snprintf(ruleset_path, sizeof ruleset_path, "%s/%s.ruleset", landlock_ruleset_dir, security_tag);
+    FILE *file SC_CLEANUP(sc_cleanup_file) = fopen(ruleset_path, "re");
+    if (file == NULL) {
+        if (errno == ENOENT && !required) {
+            debug("no landlock ruleset for %s", security_tag);
+            return -1;
+        }
+        die("cannot open landlock ruleset %s", ruleset_path);
+    }
+    // The ruleset must be maintained by root, just like seccomp profiles.
+    if (fstat(fileno(file), &st) != 0) {
+        die("cannot stat landlock ruleset %s", ruleset_path);
+    }
+    if (st.st_uid != 0 || (st.st_mode & (S_IWGRP | S_IWOTH)) != 0) {
+        die("landlock ruleset %s must be owned by root and not writable by others", ruleset_path);
+    }
+
+    int abi = sc_landlock_abi_version();
+    if (abi < 1) {
+        if (required) {
+            die("cannot use landlock ruleset %s: landlock is not supported", ruleset_path);
+        }
+        debug("landlock is not supported, not restricting %s", security_tag);
+        return -1;
+    }
+    debug("landlock ABI version %d", abi);
+
+    // First pass, look for directives affecting the whole ruleset.
+    char line[PATH_MAX + 64] = {0};
+    uint64_t allowed_net = 0;
+    while (fgets(line, sizeof line, file) != NULL) {
+        sc_str_chomp(line);
+        if (sc_streq(line, "@unrestricted")) {
+            debug("landlock ruleset for %s is unrestricted", security_tag);
+            return -1;
+        } else if (sc_streq(line, "net bind-tcp")) {
+            allowed_net |= SC_LANDLOCK_ACCESS_NET_BIND_TCP;
+        } else if (sc_streq(line, "net connect-tcp")) {
+            allowed_net |= SC_LANDLOCK_ACCESS_NET_CONNECT_TCP;
+        }
+    }
+    if (ferror(file)) {
+        die("cannot read landlock ruleset %s", ruleset_path);
+    }
+
+    struct sc_landlock_ruleset_attr ruleset_attr = {
+        .handled_access_fs = sc_landlock_read_access() | sc_landlock_write_access(abi) | SC_LANDLOCK_ACCESS_FS_EXECUTE,
+        .handled_access_net = 0,
+    };
+    if (abi >= 4) {
+        ruleset_attr.handled_access_net =
+            (SC_LANDLOCK_ACCESS_NET_BIND_TCP | SC_LANDLOCK_ACCESS_NET_CONNECT_TCP) & ~allowed_net;
+    }
+    int ruleset_fd = syscall(__NR_landlock_create_ruleset, &ruleset_attr, sizeof ruleset_attr, 0);
+    if (ruleset_fd < 0) {
+        die("cannot create landlock ruleset");
+    }
+
+    struct passwd *pw = getpwuid(real_uid);
+    const char *home = pw != NULL ? pw->pw_dir : NULL;
+
+    // Second pass, add filesystem rules.
+    rewind(file);
+    while (fgets(line, sizeof line, file) != NULL) {
+        sc_str_chomp(line);
+        if (line[0] == '\0' || line[0] == '#' || sc_startswith(line, "net ")) {
+            continue;
+        }
+        if (!sc_startswith(line, "fs ")) {
+            die("cannot parse landlock ruleset %s: unexpected line %s", ruleset_path, line);
+        }
+        char *access_str = line + strlen("fs ");
+        char *path = strchr(access_str, ' ');
+        if (path == NULL) {
+            die("cannot parse landlock ruleset %s: unexpected line %s", ruleset_path, line);
+        }
+        *path = '\0';
+        path++;
+
+        if (sc_startswith(path, "$HOME") && home == NULL) {
+            debug("ignoring landlock rule for %s, cannot find home directory of user %u", path, (unsigned)real_uid);
+            continue;
+        }
+        char expanded[PATH_MAX] = {0};
+        sc_landlock_expand_path(path, home, real_uid, expanded, sizeof expanded);
+        if (expanded[0] != '/') {
+            die("cannot parse landlock ruleset %s: path %s is not absolute", ruleset_path, expanded);
+        }
+        sc_landlock_add_path_rule(ruleset_fd, expanded, sc_landlock_parse_access(access_str, abi, ruleset_path));
+    }
+    if (ferror(file)) {
+        die("cannot read landlock ruleset %s", ruleset_path);
+    }
+
+    return ruleset_fd;
+}
+
+void sc_landlock_restrict_self(int ruleset_fd) {
+    if (ruleset_fd < 0) {
+        return;
+    }
+    if (syscall(__NR_landlock_restrict_self, ruleset_fd, 0) != 0) {
+        die("cannot enforce landlock ruleset");
+    }
+    close(ruleset_fd);
+    debug("landlock ruleset enforced");
+}
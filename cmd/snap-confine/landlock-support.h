@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+#ifndef SNAP_CONFINE_LANDLOCK_SUPPORT_H
+#define SNAP_CONFINE_LANDLOCK_SUPPORT_H
+
+#include <stdbool.h>
+#include <sys/types.h>
+
+/**
+ * sc_landlock_prepare_ruleset prepares the Landlock ruleset of a snap.
+ *
+ * The ruleset is described by the file "/var/lib/snapd/landlock/" followed by
+ * the security tag and the extension ".ruleset". Such files are written by
+ * snapd on systems without AppArmor. Each line of the file is either a comment
+ * or one of:
+ *
+ *   fs <access> <path>   allow access ("r", "w", "x") beneath path
+ *   net bind-tcp         do not restrict binding TCP sockets
+ *   net connect-tcp      do not restrict connecting TCP sockets
+ *   @unrestricted        do not restrict the snap at all
+ *
+ * Paths may start with $HOME and contain $UID, those are replaced with the
+ * home directory and the user ID of the calling user. Paths that do not exist
+ * are ignored. Access rights not supported by the running kernel are ignored
+ * as well.
+ *
+ * The rules are resolved in the current mount namespace, so this must be
+ * called after the snap mount namespace is entered. The ruleset is not applied
+ * yet, the returned descriptor must be passed to sc_landlock_restrict_self().
+ *
+ * When the ruleset file does not exist the snap is not restricted unless
+ * required is true, in which case the process dies.
+ *
+ * The return value is the ruleset file descriptor or -1 when the snap is not
+ * restricted with Landlock.
+ **/
+int sc_landlock_prepare_ruleset(const char *security_tag, uid_t real_uid, bool required);
+
+/**
+ * sc_landlock_restrict_self restricts the calling process with the ruleset
+ * prepared with sc_landlock_prepare_ruleset() and closes the ruleset.
+ *
+ * The process must either have CAP_SYS_ADMIN or the no_new_privs bit set.
+ * Passing -1 does nothing.
+ **/
+void sc_landlock_restrict_self(int ruleset_fd);
+
+#endif
@@ -17,8 +17,13 @@
 #include "selinux-support.h"
 #include "config.h"
 
+#include <errno.h>
+#include <limits.h>
 #include <selinux/context.h>
 #include <selinux/selinux.h>
+#include <stdio.h>
+#include <sys/stat.h>
+#include <unistd.h>
 
 #include "../libsnap-confine-private/cleanup-funcs.h"
 #include "../libsnap-confine-private/string-utils.h"
@@ -38,12 +43,39 @@ static void sc_context_free(context_t *ctx) {
     }
 }
 
+static const char *selinux_module_dir = "/var/lib/snapd/selinux";
+
+/**
+ * Compute the name of the policy module of a security tag.
+ *
+ * Dots are replaced with two underscores and any other character which is
+ * neither a letter nor a digit with an underscore followed by its hexadecimal
+ * code, e.g. snap.foo-bar.baz becomes snap__foo_2dbar__baz.
+ *
+ * NOTE: This mirrors ModuleName in interfaces/selinux.
+ **/
+static void sc_selinux_module_name(const char *security_tag, char *buf, size_t buf_size) {
+    sc_string_init(buf, buf_size);
+    for (const char *c = security_tag; *c != '\0'; c++) {
+        if ((*c >= 'a' && *c <= 'z') || (*c >= 'A' && *c <= 'Z') || (*c >= '0' && *c <= '9')) {
+            sc_string_append_char(buf, buf_size, *c);
+        } else if (*c == '.') {
+            sc_string_append(buf, buf_size, "__");
+        } else {
+            char escaped[4] = {0};
+            sc_must_snprintf(escaped, sizeof escaped, "_%02x", (unsigned char)*c);
+            sc_string_append(buf, buf_size, escaped);
+        }
+    }
+}
+
 /**
  * Set security context for the snap.
  *
- * Sets up SELinux context transition to unconfined_service_t.
+ * Sets up SELinux context transition to the domain of the application, or to
+ * unconfined_service_t when there is no policy module for it.
  **/
-int sc_selinux_set_snap_execcon(void) {
+int sc_selinux_set_snap_execcon(const char *security_tag) {
     if (is_selinux_enabled() < 1) {
         debug("SELinux not enabled");
         return 0;
@@ -70,15 +102,31 @@ int sc_selinux_set_snap_execcon(void) {
     if (sc_streq(ctx_type, "snappy_confine_t")) {
         /* We are running under a targeted policy which ended up transitioning
          * to snappy_confine_t domain, at this point we are right before
-         * executing snap-exec. However we do not have a full SELinux support
-         * for services running in snaps, only the snapd bits and helpers are
-         * covered by the policy.
+         * executing snap-exec.
          *
-         * At this point transition to the unconfined_service_t domain (allowed
-         * by snap_confine_t policy) upon the next exec() call.
+         * When snapd installed a policy module for the application, transition
+         * to the domain it declares upon the next exec() call. Otherwise, as is
+         * the case for classic snaps or when snapd does not manage policy
+         * modules, transition to the unconfined_service_t domain (allowed by
+         * snap_confine_t policy).
          */
-        if (context_type_set(ctx, "unconfined_service_t") != 0) {
-            die("cannot update SELinux context %s type to unconfined_service_t", ctx_str);
+        char module[PATH_MAX] = {0};
+        sc_selinux_module_name(security_tag, module, sizeof module);
+        char module_path[PATH_MAX] = {0};
+        sc_must_snprintf(module_path, sizeof module_path, "%s/%s.cil", selinux_module_dir, module);
+
+        char new_type[PATH_MAX] = {0};
+        struct stat st;
+        if (stat(module_path, &st) == 0) {
+            sc_must_snprintf(new_type, sizeof new_type, "%s_t", module);
+        } else if (errno == ENOENT) {
+            debug("no SELinux policy module for %s", security_tag);
+            sc_must_snprintf(new_type, sizeof new_type, "unconfined_service_t");
+        } else {
+            die("cannot stat %s", module_path);
+        }
+        if (context_type_set(ctx, new_type) != 0) {
+            die("cannot update SELinux context %s type to %s", ctx_str, new_type);
         }
 
         /* freed by context_free(ctx) */
@@ -86,6 +134,12 @@ int sc_selinux_set_snap_execcon(void) {
         if (new_ctx_str == NULL) {
             die("cannot obtain updated SELinux context string");
         }
+        /* Policy modules are installed right after being written, report
+         * a missing one clearly rather than failing to execute snap-exec. */
+        if (security_check_context(new_ctx_str) != 0) {
+            die("SELinux context %s is not valid, is the policy module for %s installed?", new_ctx_str,
+                security_tag);
+        }
         if (setexeccon(new_ctx_str) < 0) {
             die("cannot set SELinux exec context to %s", new_ctx_str);
         }
@@ -53,6 +53,7 @@
 #include "../libsnap-confine-private/utils.h"
 #include "cookie-support.h"
 #include "group-policy.h"
+#include "landlock-support.h"
 #include "mount-support.h"
 #include "ns-support.h"
 #include "seccomp-support.h"
@@ -566,7 +567,7 @@ int main(int argc, char **argv) {
     sc_maybe_aa_change_onexec(&apparmor, invocation.security_tag);
 #ifdef HAVE_SELINUX
     // For classic and confined snaps
-    sc_selinux_set_snap_execcon();
+    sc_selinux_set_snap_execcon(invocation.security_tag);
 #endif
     if (snap_context != NULL) {
         setenv("SNAP_COOKIE", snap_context, 1);
@@ -603,10 +604,22 @@ int main(int argc, char **argv) {
 
     sc_debug_capabilities("before seccomp");
 
+    // Prepare the landlock ruleset, if any, while the filesystem is still
+    // fully accessible. Without AppArmor confinement the ruleset is mandatory
+    // for strictly confined snaps.
+    int landlock_fd = -1;
+    if (!invocation.classic_confinement) {
+        landlock_fd = sc_landlock_prepare_ruleset(invocation.security_tag, real_uid, !apparmor.is_confined);
+    }
+
     // Now that we've dropped and regained SYS_ADMIN, we can load the
     // seccomp profiles.
     sc_apply_seccomp_profile_for_security_tag(invocation.security_tag);
 
+    // The landlock ruleset is enforced last as it would otherwise prevent
+    // reading the seccomp profile.
+    sc_landlock_restrict_self(landlock_fd);
+
     if (is_regular_user) {
         debug("dropping all capabilities for user");
 
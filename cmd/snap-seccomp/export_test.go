@@ -34,6 +34,16 @@ var (
 	Dump              = dump
 )
 
+// SplitRange returns the mask and value pairs of the blocks covering the
+// range.
+func SplitRange(low, high uint64) [][2]uint64 {
+	var res [][2]uint64
+	for _, block := range splitRange(low, high) {
+		res = append(res, [2]uint64{block.mask, block.value})
+	}
+	return res
+}
+
 func MockArchDpkgArchitecture(f func() string) (restore func()) {
 	realArchDpkgArchitecture := archDpkgArchitecture
 	archDpkgArchitecture = f
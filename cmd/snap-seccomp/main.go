@@ -327,6 +327,17 @@ var seccompResolver = map[string]uint64{
 	"SOCK_RDM":       syscall.SOCK_RDM,
 	"SOCK_PACKET":    syscall.SOCK_PACKET,
 
+	// man 7 ip, man 7 ipv6 - protocol
+	"IPPROTO_IP":      syscall.IPPROTO_IP,
+	"IPPROTO_ICMP":    syscall.IPPROTO_ICMP,
+	"IPPROTO_TCP":     syscall.IPPROTO_TCP,
+	"IPPROTO_UDP":     syscall.IPPROTO_UDP,
+	"IPPROTO_IPV6":    syscall.IPPROTO_IPV6,
+	"IPPROTO_ICMPV6":  syscall.IPPROTO_ICMPV6,
+	"IPPROTO_SCTP":    syscall.IPPROTO_SCTP,
+	"IPPROTO_UDPLITE": syscall.IPPROTO_UDPLITE,
+	"IPPROTO_RAW":     syscall.IPPROTO_RAW,
+
 	// man 2 prctl
 	"PR_CAP_AMBIENT":              C.PR_CAP_AMBIENT,
 	"PR_CAP_AMBIENT_RAISE":        C.PR_CAP_AMBIENT_RAISE,
@@ -404,7 +415,25 @@ var seccompResolver = map[string]uint64{
 	"CLONE_NEWUTS":  syscall.CLONE_NEWUTS,
 
 	// man 4 tty_ioctl
-	"TIOCSTI": syscall.TIOCSTI,
+	"TIOCSTI":    syscall.TIOCSTI,
+	"TCGETS":     C.TCGETS,
+	"TCSETS":     C.TCSETS,
+	"TCSETSW":    C.TCSETSW,
+	"TCSETSF":    C.TCSETSF,
+	"TCFLSH":     C.TCFLSH,
+	"TIOCGWINSZ": C.TIOCGWINSZ,
+	"TIOCSWINSZ": C.TIOCSWINSZ,
+	"TIOCGPGRP":  C.TIOCGPGRP,
+	"TIOCSPGRP":  C.TIOCSPGRP,
+	"TIOCGPTN":   C.TIOCGPTN,
+	"TIOCSPTLCK": C.TIOCSPTLCK,
+
+	// generic file ioctl requests, see man 2 ioctl_fionread
+	"FIOCLEX":  C.FIOCLEX,
+	"FIONCLEX": C.FIONCLEX,
+	"FIONBIO":  C.FIONBIO,
+	"FIOASYNC": C.FIOASYNC,
+	"FIONREAD": C.FIONREAD,
 
 	// man 2 ioctl_console
 	"TIOCLINUX": C.TIOCLINUX,
@@ -608,6 +637,59 @@ var (
 	errnoOnImplicitDenial int16 = C.EPERM
 )
 
+func readRange(token string, syscallName string) (uint64, uint64, error) {
+	l := strings.Split(token, "..")
+	if len(l) != 2 {
+		return 0, 0, fmt.Errorf("cannot parse range: unexpected number of tokens %v", len(l))
+	}
+	low, err := readNumber(l[0], syscallName)
+	if err != nil {
+		return 0, 0, err
+	}
+	high, err := readNumber(l[1], syscallName)
+	if err != nil {
+		return 0, 0, err
+	}
+	if low > high {
+		return 0, 0, fmt.Errorf("cannot parse range: %v is greater than %v", low, high)
+	}
+	return low, high, nil
+}
+
+// maskedRange is a block of values matched by a masked equality comparison.
+type maskedRange struct {
+	mask, value uint64
+}
+
+// splitRange splits the inclusive range low..high into the aligned blocks of
+// values covering it, so that the range can be compiled into one masked
+// equality rule per block. libseccomp does not allow comparing the same
+// argument twice in a rule, which would be needed to express the range
+// with a lower and an upper bound.
+func splitRange(low, high uint64) []maskedRange {
+	var blocks []maskedRange
+	for {
+		// find the largest block of 2^bits values starting at low that
+		// does not go past high
+		bits := 0
+		for bits < 64 {
+			size := uint64(1) << (bits + 1)
+			if low&(size-1) != 0 || high-low < size-1 {
+				break
+			}
+			bits++
+		}
+		// shifts by 64 give 0 in Go, which is the mask matching any value
+		mask := ^uint64(0) << bits
+		blocks = append(blocks, maskedRange{mask: mask, value: low})
+		last := low | ^mask
+		if last >= high {
+			return blocks
+		}
+		low = last + 1
+	}
+}
+
 func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) error {
 	// ignore comments and empty lines
 	if strings.HasPrefix(line, "#") || line == "" {
@@ -643,9 +725,14 @@ func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) e
 	}
 
 	var conds []seccomp.ScmpCondition
+	// a range is compiled into several rules which only differ in the
+	// comparison of the argument with the range
+	var rangePos int
+	var rangeBlocks []maskedRange
 	for pos, arg := range tokens[1:] {
 		var cmpOp seccomp.ScmpCompareOp
 		var value, value2 uint64
+		var isRange bool
 		var err error
 
 		if arg == "-" { // skip arg
@@ -667,6 +754,13 @@ func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) e
 		} else if strings.HasPrefix(arg, ">") {
 			cmpOp = seccomp.CompareGreater
 			value, err = readNumber(arg[1:], syscallName)
+		} else if strings.Contains(arg, "..") {
+			// inclusive range
+			if !argumentRangesSupported() {
+				return fmt.Errorf("cannot parse token %q (line %q): argument ranges are not supported by this version of libseccomp", arg, line)
+			}
+			isRange = true
+			value, value2, err = readRange(arg, syscallName)
 		} else if strings.HasPrefix(arg, "|") {
 			cmpOp = seccomp.CompareMaskedEqual
 			value, err = readNumber(arg[1:], syscallName)
@@ -698,11 +792,21 @@ func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) e
 		// this, be sure to adjust readNumber accordingly and use
 		// libseccomp carefully.
 		if syscallsWithNegArgsMaskHi32[syscallName] {
-			if cmpOp != seccomp.CompareEqual {
+			if isRange || cmpOp != seccomp.CompareEqual {
 				return fmt.Errorf("cannot parse token %q (line %q): unsupported comparison", arg, line)
 			}
 		}
 
+		if isRange {
+			// the rules would multiply with each range
+			if rangeBlocks != nil {
+				return fmt.Errorf("cannot parse token %q (line %q): only one range is supported", arg, line)
+			}
+			rangePos = pos
+			rangeBlocks = splitRange(value, value2)
+			continue
+		}
+
 		var scmpCond seccomp.ScmpCondition
 		if cmpOp == seccomp.CompareMaskedEqual {
 			scmpCond, err = seccomp.MakeCondition(uint(pos), cmpOp, value, value2)
@@ -717,15 +821,32 @@ func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) e
 		conds = append(conds, scmpCond)
 	}
 
-	// Default to adding a precise match if possible. Otherwise
-	// let seccomp figure out the architecture specifics.
-	if err = secFilter.AddRuleConditionalExact(secSyscall, action, conds); err != nil {
-		err = secFilter.AddRuleConditional(secSyscall, action, conds)
-	}
-	if err != nil {
-		return fmt.Errorf("cannot add rule for line %q: %v", line, err)
+	addRule := func(conds []seccomp.ScmpCondition) error {
+		// Default to adding a precise match if possible. Otherwise
+		// let seccomp figure out the architecture specifics.
+		err := secFilter.AddRuleConditionalExact(secSyscall, action, conds)
+		if err != nil {
+			err = secFilter.AddRuleConditional(secSyscall, action, conds)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot add rule for line %q: %v", line, err)
+		}
+		return nil
 	}
 
+	if rangeBlocks == nil {
+		return addRule(conds)
+	}
+	for _, block := range rangeBlocks {
+		blockCond, err := seccomp.MakeCondition(uint(rangePos), seccomp.CompareMaskedEqual, block.mask, block.value)
+		if err != nil {
+			return fmt.Errorf("cannot parse line %q: %s", line, err)
+		}
+		blockConds := append([]seccomp.ScmpCondition{blockCond}, conds...)
+		if err := addRule(blockConds); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
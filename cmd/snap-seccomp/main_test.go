@@ -451,7 +451,7 @@ func (s *snapSeccompSuite) TestCompile(c *C) {
 
 		// test argument filtering syntax, we currently support:
 		//   >=, <=, !, <, >, |
-		// modifiers.
+		// modifiers and inclusive ranges (a..b).
 
 		// reads >= 2 are ok
 		{"read >=2", "read;native;2", Allow},
@@ -491,6 +491,33 @@ func (s *snapSeccompSuite) TestCompile(c *C) {
 		// FIXME: test maskedEqual better
 		{"read |1", "read;native;1", Allow},
 		{"read |1", "read;native;2", Deny},
+		// masked equal with a distinct mask and value
+		{"read 12|4", "read;native;4", Allow},
+		{"read 12|4", "read;native;5", Allow},
+		{"read 12|4", "read;native;3", Deny},
+		{"read 12|4", "read;native;8", Deny},
+
+		// reads in the range 2..4 are ok
+		{"read 2..4", "read;native;2", Allow},
+		{"read 2..4", "read;native;3", Allow},
+		{"read 2..4", "read;native;4", Allow},
+		// but not those outside of it
+		{"read 2..4", "read;native;1", Deny},
+		{"read 2..4", "read;native;5", Deny},
+		// single element range
+		{"read 2..2", "read;native;2", Allow},
+		{"read 2..2", "read;native;3", Deny},
+		// unaligned range split into several rules
+		{"read 3..9", "read;native;2", Deny},
+		{"read 3..9", "read;native;3", Allow},
+		{"read 3..9", "read;native;4", Allow},
+		{"read 3..9", "read;native;8", Allow},
+		{"read 3..9", "read;native;9", Allow},
+		{"read 3..9", "read;native;10", Deny},
+		// together with other comparisons
+		{"mprotect - 4096..8191 >=2", "mprotect;native;-,4096,2", Allow},
+		{"mprotect - 4096..8191 >=2", "mprotect;native;-,5000,1", Deny},
+		{"mprotect - 4096..8191 >=2", "mprotect;native;-,8192,2", Deny},
 
 		// exact match, reads == 2 are ok
 		{"read 2", "read;native;2", Allow},
@@ -513,6 +540,13 @@ func (s *snapSeccompSuite) TestCompile(c *C) {
 		{"ioctl\n~ioctl - 4294967295|TIOCSTI", "ioctl;native;-,TIOCSTI", DenyExplicit},
 		{"ioctl\n~ioctl - 4294967295|TIOCLINUX", "ioctl;native;-,TIOCLINUX", DenyExplicit},
 
+		// allowing specific ioctl requests only
+		{"ioctl - 4294967295|TIOCSTI", "ioctl;native;-,TIOCSTI", Allow},
+		{"ioctl - 4294967295|TIOCSTI", "ioctl;native;-,99", Deny},
+		{"ioctl - TIOCSTI..TIOCLINUX", "ioctl;native;-,TIOCSTI", Allow},
+		{"ioctl - TIOCSTI..TIOCLINUX", "ioctl;native;-,TIOCLINUX", Allow},
+		{"ioctl - TIOCSTI..TIOCLINUX", "ioctl;native;-,99", Deny},
+
 		// test_bad_seccomp_filter_args_clone
 		{"setns - CLONE_NEWNET", "setns;native;-,99", Deny},
 		{"setns - CLONE_NEWNET", "setns;native;-,CLONE_NEWNET", Allow},
@@ -565,6 +599,71 @@ func (s *snapSeccompSuite) TestCompile(c *C) {
 // TestCompileSocket runs in a separate tests so that only this part
 // can be skipped when "socketcall()" is used instead of "socket()".
 //
+// TestCompileRestrictedIoctl checks the filter generated when the interfaces
+// restrict ioctl() to the requests they allow, see restrictedIoctlSyscalls in
+// interfaces/seccomp/template.go and the i2c interface.
+func (s *snapSeccompSuite) TestCompileRestrictedIoctl(c *C) {
+	restricted := `
+~ioctl - TIOCSTI
+~ioctl - TIOCLINUX
+~ioctl - 4294967295|TIOCSTI
+~ioctl - 4294967295|TIOCLINUX
+ioctl - 4294967295|1795
+ioctl - 4294967295|1824
+ioctl - 4294967295|FIOCLEX
+ioctl - 4294967295|FIONCLEX
+ioctl - 4294967295|FIONBIO
+ioctl - 4294967295|FIOASYNC
+ioctl - 4294967295|FIONREAD
+ioctl - 4294967295|TCGETS
+ioctl - 4294967295|TCSETS
+ioctl - 4294967295|TCSETSW
+ioctl - 4294967295|TCSETSF
+ioctl - 4294967295|TCFLSH
+ioctl - 4294967295|TIOCGWINSZ
+ioctl - 4294967295|TIOCSWINSZ
+ioctl - 4294967295|TIOCGPGRP
+ioctl - 4294967295|TIOCSPGRP
+ioctl - 4294967295|TIOCGPTN
+ioctl - 4294967295|TIOCSPTLCK
+`
+	for _, t := range []struct {
+		bpfInput string
+		expected int
+	}{
+		// the requests allowed by the interface
+		{"ioctl;native;-,1795", Allow},
+		{"ioctl;native;-,1824", Allow},
+		// the common requests
+		{"ioctl;native;-,FIOCLEX", Allow},
+		{"ioctl;native;-,FIONREAD", Allow},
+		{"ioctl;native;-,TCGETS", Allow},
+		{"ioctl;native;-,TIOCGWINSZ", Allow},
+		{"ioctl;native;-,TIOCGPTN", Allow},
+		// any other request is denied
+		{"ioctl;native;-,1796", Deny},
+		{"ioctl;native;-,99", Deny},
+		// and the ones faking input explicitly so
+		{"ioctl;native;-,TIOCSTI", DenyExplicit},
+		{"ioctl;native;-,TIOCLINUX", DenyExplicit},
+	} {
+		s.runBpf(c, restricted, t.bpfInput, t.expected)
+	}
+
+	// without restrictions any request but the ones faking input is
+	// allowed
+	for _, t := range []struct {
+		bpfInput string
+		expected int
+	}{
+		{"ioctl;native;-,1796", Allow},
+		{"ioctl;native;-,99", Allow},
+		{"ioctl;native;-,TIOCSTI", DenyExplicit},
+	} {
+		s.runBpf(c, "~ioctl - 4294967295|TIOCSTI\nioctl\n", t.bpfInput, t.expected)
+	}
+}
+
 // Some architectures (i386, s390x) use the "socketcall" syscall instead
 // of "socket". This is the case on Ubuntu 14.04, 17.04, 17.10
 func (s *snapSeccompSuite) TestCompileSocket(c *C) {
@@ -585,12 +684,50 @@ func (s *snapSeccompSuite) TestCompileSocket(c *C) {
 		{"socket - SOCK_STREAM", "socket;native;-,99", Deny},
 		{"socket AF_CONN", "socket;native;AF_CONN", Allow},
 		{"socket AF_CONN", "socket;native;99", Deny},
+		{"socket AF_INET - IPPROTO_TCP", "socket;native;AF_INET,-,IPPROTO_TCP", Allow},
+		{"socket AF_INET - IPPROTO_TCP", "socket;native;AF_INET,-,IPPROTO_UDP", Deny},
+		{"socket AF_INET - IPPROTO_TCP", "socket;native;AF_INET6,-,IPPROTO_TCP", Deny},
 	} {
 		s.runBpf(c, t.seccompAllowlist, t.bpfInput, t.expected)
 	}
 
 }
 
+func (s *snapSeccompSuite) TestSplitRange(c *C) {
+	const all = ^uint64(0)
+	for _, t := range []struct {
+		low, high uint64
+		blocks    [][2]uint64
+	}{
+		{2, 2, [][2]uint64{{all, 2}}},
+		{2, 3, [][2]uint64{{all << 1, 2}}},
+		{2, 4, [][2]uint64{{all << 1, 2}, {all, 4}}},
+		{3, 9, [][2]uint64{{all, 3}, {all << 2, 4}, {all << 1, 8}}},
+		{0x5400, 0x54ff, [][2]uint64{{all << 8, 0x5400}}},
+		{0, all, [][2]uint64{{0, 0}}},
+		{all - 1, all, [][2]uint64{{all << 1, all - 1}}},
+	} {
+		c.Check(main.SplitRange(t.low, t.high), DeepEquals, t.blocks, Commentf("%d..%d", t.low, t.high))
+	}
+
+	// every value of the range is matched by exactly one block, and none
+	// outside of it
+	blocks := main.SplitRange(5, 1000)
+	for v := uint64(0); v < 1100; v++ {
+		matches := 0
+		for _, block := range blocks {
+			if v&block[0] == block[1] {
+				matches++
+			}
+		}
+		if v >= 5 && v <= 1000 {
+			c.Check(matches, Equals, 1, Commentf("%d", v))
+		} else {
+			c.Check(matches, Equals, 0, Commentf("%d", v))
+		}
+	}
+}
+
 func (s *snapSeccompSuite) TestCompileBadInput(c *C) {
 	for _, t := range []struct {
 		inp    string
@@ -665,6 +802,17 @@ func (s *snapSeccompSuite) TestCompileBadInput(c *C) {
 		{"setpriority <=", `cannot parse line: cannot parse token "<=" .*`},
 		{"setpriority |", `cannot parse line: cannot parse token "|" .*`},
 		{"setpriority !", `cannot parse line: cannot parse token "!" .*`},
+		// ensure bad ranges are caught
+		{"setpriority ..", `cannot parse line: cannot parse token ".." .*`},
+		{"setpriority 1..", `cannot parse line: cannot parse token "1.." .*`},
+		{"setpriority ..1", `cannot parse line: cannot parse token "..1" .*`},
+		{"setpriority 1..2..3", `cannot parse line: cannot parse token "1..2..3" .*`},
+		{"setpriority 2..1", `cannot parse line: cannot parse token "2..1" .*`},
+		{"setpriority 1...2", `cannot parse line: cannot parse token "1...2" .*`},
+		// only one range per line
+		{"mprotect 0..1 0..1", `cannot parse line: cannot parse token "0..1" \(line "mprotect 0..1 0..1"\): only one range is supported`},
+		// ranges are not supported with negative arguments
+		{"chown - 0..2 -1", `cannot parse line: cannot parse token "0..2" \(line "chown - 0..2 -1"\): unsupported comparison`},
 
 		// u:<username>
 		{"setuid :root", `cannot parse line: cannot parse token ":root" .*`},
@@ -73,9 +73,21 @@ func goSeccompFeatures() string {
 	if actLogSupported() {
 		features = append(features, "bpf-actlog")
 	}
+	if argumentRangesSupported() {
+		features = append(features, "bpf-argument-ranges")
+	}
 
 	if len(features) == 0 {
 		return "-"
 	}
 	return strings.Join(features, ":")
 }
+
+// argumentRangesSupported returns whether ranges of argument values
+// (expressed as "low..high") can be compiled. Ranges are compiled into masked
+// equality comparisons of the whole 64-bit argument, which libseccomp only
+// generates correctly since 2.4.0 (CVE-2019-9893).
+func argumentRangesSupported() bool {
+	major, minor, _ := seccomp.GetLibraryVersion()
+	return major > 2 || (major == 2 && minor >= 4)
+}
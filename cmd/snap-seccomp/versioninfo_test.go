@@ -28,6 +28,7 @@ import (
 
 	main "github.com/snapcore/snapd/cmd/snap-seccomp"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/strutil"
 )
 
 type versionInfoSuite struct{}
@@ -72,3 +73,10 @@ func (s *versionInfoSuite) TestVersionInfo(c *C) {
 	c.Assert(err, IsNil)
 	c.Check(vi, Equals, prefix+readHash+suffix)
 }
+
+func (s *versionInfoSuite) TestGoSeccompFeaturesArgumentRanges(c *C) {
+	m, i, _ := seccomp.GetLibraryVersion()
+	rangesSupported := m > 2 || (m == 2 && i >= 4)
+	features := strings.Split(main.GoSeccompFeatures(), ":")
+	c.Check(strutil.ListContains(features, "bpf-argument-ranges"), Equals, rangesSupported)
+}
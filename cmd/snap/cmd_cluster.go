@@ -0,0 +1,314 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+var (
+	shortClusterHelp = i18n.G("Manage the cluster of this device")
+	longClusterHelp  = i18n.G(`
+The cluster command manages the cluster this device is part of.
+
+The assemble sub-command runs an assemble session, in which the devices that
+share a secret find each other and establish which addresses they can be
+reached at. The result is displayed by the status sub-command, to be used in a
+cluster assertion. With --discover, the devices find each other on the local
+network using multicast DNS, so that only the secret needs to be shared.
+
+The show sub-command displays the current cluster assertion, its subclusters
+and devices, while the update sub-command imports a bundle with a new cluster
+assertion and its prerequisites.
+`)
+)
+
+type cmdCluster struct {
+	Assemble cmdClusterAssemble `command:"assemble"`
+	Show     cmdClusterShow     `command:"show"`
+	Update   cmdClusterUpdate   `command:"update"`
+	Status   cmdClusterStatus   `command:"status"`
+}
+
+func (x *cmdCluster) setClient(cli *client.Client) {
+	x.Assemble.setClient(cli)
+	x.Show.setClient(cli)
+	x.Update.setClient(cli)
+	x.Status.setClient(cli)
+}
+
+func (x *cmdCluster) Execute(args []string) error {
+	return flag.ErrHelp
+}
+
+type cmdClusterAssemble struct {
+	waitMixin
+	Secret       string        `long:"secret" required:"yes"`
+	Address      string        `long:"address"`
+	ExpectedSize int           `long:"expected-size"`
+	Peers        []string      `long:"peer"`
+	Discover     bool          `long:"discover"`
+	Interface    string        `long:"interface"`
+	Subnet       string        `long:"subnet"`
+	Timeout      time.Duration `long:"timeout"`
+}
+
+type cmdClusterShow struct {
+	clientMixin
+}
+
+type cmdClusterUpdate struct {
+	clientMixin
+	Positional struct {
+		Bundle flags.Filename `required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type cmdClusterStatus struct {
+	clientMixin
+	timeMixin
+}
+
+func init() {
+	cmd := addCommand("cluster", shortClusterHelp, longClusterHelp, func() flags.Commander {
+		return &cmdCluster{}
+	}, nil, nil)
+	cmd.extra = func(cmd *flags.Command) {
+		assemble := cmd.Find("assemble")
+		assemble.ShortDescription = i18n.G("Assemble a cluster with the devices sharing a secret")
+		for name, desc := range waitDescs.also(map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"secret": i18n.G("Secret shared by the devices of the cluster"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"address": i18n.G("The ip:port address to listen on for the other devices (required unless discovering peers)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"expected-size": i18n.G("Finish once this many devices are connected"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"peer": i18n.G("The ip:port address of a device known to take part"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"discover": i18n.G("Discover the other devices on the local network"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"interface": i18n.G("Network interface to discover the other devices on"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"subnet": i18n.G("Only consider discovered devices within this subnet (e.g. 10.0.0.0/24)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"timeout": i18n.G("Give up the session after this long (e.g. 10m)"),
+		}) {
+			assemble.FindOptionByLongName(name).Description = desc
+		}
+
+		cmd.Find("show").ShortDescription = i18n.G("Show the current cluster assertion")
+
+		update := cmd.Find("update")
+		update.ShortDescription = i18n.G("Import a cluster assertion bundle")
+		bundle := update.Args()[0]
+		// TRANSLATORS: This needs to begin with < and end with >
+		bundle.Name = i18n.G("<bundle file>")
+		// TRANSLATORS: This should not start with a lowercase letter.
+		bundle.Description = i18n.G("File with a cluster assertion and its prerequisites")
+
+		status := cmd.Find("status")
+		status.ShortDescription = i18n.G("Show the clustering state of this device")
+		for name, desc := range timeDescs {
+			status.FindOptionByLongName(name).Description = desc
+		}
+	}
+}
+
+func (x *cmdClusterAssemble) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	if x.Address == "" && !x.Discover {
+		return errors.New(i18n.G("cannot assemble a cluster without --address or --discover"))
+	}
+	if !x.Discover && (x.Interface != "" || x.Subnet != "") {
+		return errors.New(i18n.G("--interface and --subnet can only be used with --discover"))
+	}
+
+	changeID, err := x.client.ClusterAssemble(client.ClusterAssembleOptions{
+		Secret:       x.Secret,
+		Address:      x.Address,
+		ExpectedSize: x.ExpectedSize,
+		Peers:        x.Peers,
+		Discover:     x.Discover,
+		Interface:    x.Interface,
+		Subnet:       x.Subnet,
+		Timeout:      x.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(changeID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	status, err := x.client.ClusterStatus()
+	if err != nil {
+		return err
+	}
+	if status.Assembled == nil {
+		return errors.New(i18n.G("internal error: assemble session finished without a result"))
+	}
+	fmt.Fprintf(Stdout, i18n.G("Assembled cluster of %d devices\n"), len(status.Assembled.Devices))
+	return nil
+}
+
+func (x *cmdClusterShow) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	cluster, err := x.client.Cluster()
+	if err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintf(w, "cluster-id:\t%s\n", cluster.ClusterID)
+	fmt.Fprintf(w, "sequence:\t%d\n", cluster.Sequence)
+	fmt.Fprintf(w, "authority-id:\t%s\n", cluster.AuthorityID)
+	fmt.Fprintln(w, "devices:")
+	for _, dev := range cluster.Devices {
+		fmt.Fprintf(w, "  - id:\t%d\n", dev.ID)
+		fmt.Fprintf(w, "    device:\t%s\n", dev.Device)
+		fmt.Fprintf(w, "    addresses:\t%s\n", strings.Join(dev.Addresses, ", "))
+	}
+	fmt.Fprintln(w, "subclusters:")
+	for _, sc := range cluster.Subclusters {
+		ids := make([]string, 0, len(sc.Devices))
+		for _, id := range sc.Devices {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		fmt.Fprintf(w, "  - name:\t%s\n", sc.Name)
+		fmt.Fprintf(w, "    devices:\t%s\n", strings.Join(ids, ", "))
+		if len(sc.Snaps) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, "    snaps:")
+		for _, sn := range sc.Snaps {
+			channel := sn.Channel
+			if channel == "" {
+				channel = "-"
+			}
+			fmt.Fprintf(w, "      - %s\t%s\t%s\n", sn.Instance, sn.State, channel)
+		}
+	}
+	return nil
+}
+
+func (x *cmdClusterUpdate) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	f, err := os.Open(string(x.Positional.Bundle))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := x.client.ClusterUpdate(f); err != nil {
+		return err
+	}
+	fmt.Fprintf(Stdout, i18n.G("Cluster assertion bundle %s imported\n"), x.Positional.Bundle)
+	return nil
+}
+
+func (x *cmdClusterStatus) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	status, err := x.client.ClusterStatus()
+	if err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	if status.ClusterID == "" {
+		fmt.Fprintf(w, "cluster:\t-\n")
+	} else {
+		fmt.Fprintf(w, "cluster:\t%s (sequence %d)\n", status.ClusterID, status.Sequence)
+		if status.DeviceID == 0 {
+			fmt.Fprintf(w, "device-id:\t-\n")
+		} else {
+			fmt.Fprintf(w, "device-id:\t%d\n", status.DeviceID)
+		}
+		if len(status.Subclusters) == 0 {
+			fmt.Fprintf(w, "subclusters:\t-\n")
+		} else {
+			fmt.Fprintf(w, "subclusters:\t%s\n", strings.Join(status.Subclusters, ", "))
+		}
+	}
+	if len(status.ApplyChanges) > 0 {
+		fmt.Fprintf(w, "applying:\t%s\n", strings.Join(status.ApplyChanges, ", "))
+	}
+	if status.AssembleChange != "" {
+		fmt.Fprintf(w, "assembling:\t%s\n", status.AssembleChange)
+	}
+	if status.Compliance != nil {
+		compliance := status.Compliance
+		if len(compliance.Drift) == 0 {
+			fmt.Fprintf(w, "compliance:\tcompliant (sequence %d, checked %s)\n", compliance.Sequence, x.fmtTime(compliance.Checked))
+		} else {
+			fmt.Fprintf(w, "compliance:\tdrifted (sequence %d, checked %s)\n", compliance.Sequence, x.fmtTime(compliance.Checked))
+			fmt.Fprintf(w, "drift:\n")
+			for _, d := range compliance.Drift {
+				detail := d.Kind
+				if d.Kind == "channel" {
+					detail = fmt.Sprintf("channel %s, expected %s", d.Current, d.Expected)
+				}
+				fmt.Fprintf(w, "  - %s\t%s\t%s\n", d.Subcluster, d.Snap, detail)
+			}
+		}
+	}
+	if status.Assembled == nil {
+		return nil
+	}
+
+	fmt.Fprintf(w, "assembled:\t%s (change %s)\n", x.fmtTime(status.Assembled.Completed), status.Assembled.ChangeID)
+	fmt.Fprintf(w, "assembled-devices:\n")
+	for _, dev := range status.Assembled.Devices {
+		fmt.Fprintf(w, "  - id:\t%d\n", dev.ID)
+		fmt.Fprintf(w, "    device:\t%s\n", dev.Device)
+		fmt.Fprintf(w, "    addresses:\t%s\n", strings.Join(dev.Addresses, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const clusterStatusAssembled = `{
+  "type": "sync",
+  "status-code": 200,
+  "result": {
+    "assembled": {
+      "change-id": "42",
+      "completed": "2026-01-02T03:04:05Z",
+      "devices": [
+        {"id": 1, "device": "serial-1.my-model.my-brand", "addresses": ["10.0.0.1:8001"]},
+        {"id": 2, "device": "serial-2.my-model.my-brand", "addresses": ["10.0.0.2:8001"]}
+      ]
+    }
+  }
+}`
+
+func (s *SnapSuite) TestClusterAssemble(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/cluster")
+			var data map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&data), IsNil)
+			c.Check(data, DeepEquals, map[string]any{
+				"action":        "assemble",
+				"secret":        "secret",
+				"address":       "10.0.0.1:8001",
+				"expected-size": 2.0,
+				"peers":         []any{"10.0.0.2:8001"},
+				"timeout":       "5m0s",
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+		case 1:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+		case 2:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/cluster/status")
+			fmt.Fprintln(w, clusterStatusAssembled)
+		default:
+			c.Fatalf("expected to get 3 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble",
+		"--secret", "secret", "--address", "10.0.0.1:8001", "--expected-size", "2",
+		"--peer", "10.0.0.2:8001", "--timeout", "5m"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(n, Equals, 3)
+	c.Check(s.Stdout(), Equals, "Assembled cluster of 2 devices\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterAssembleNoWait(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v2/cluster")
+		w.WriteHeader(202)
+		fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble",
+		"--secret", "secret", "--address", "10.0.0.1:8001", "--no-wait"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, "42\n")
+}
+
+func (s *SnapSuite) TestClusterAssembleDiscover(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v2/cluster")
+		var data map[string]any
+		c.Assert(json.NewDecoder(r.Body).Decode(&data), IsNil)
+		c.Check(data, DeepEquals, map[string]any{
+			"action":    "assemble",
+			"secret":    "secret",
+			"discover":  true,
+			"interface": "eth0",
+			"subnet":    "10.0.0.0/24",
+		})
+		w.WriteHeader(202)
+		fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble",
+		"--secret", "secret", "--discover", "--interface", "eth0", "--subnet", "10.0.0.0/24", "--no-wait"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, "42\n")
+}
+
+func (s *SnapSuite) TestClusterAssembleInvalidFlags(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--secret", "secret"})
+	c.Check(err, ErrorMatches, "cannot assemble a cluster without --address or --discover")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--secret", "secret", "--address", "10.0.0.1:8001", "--subnet", "10.0.0.0/24"})
+	c.Check(err, ErrorMatches, "--interface and --subnet can only be used with --discover")
+}
+
+func (s *SnapSuite) TestClusterAssembleMissingSecret(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--address", "10.0.0.1:8001"})
+	c.Assert(err, ErrorMatches, "the required flag `--secret' was not specified")
+}
+
+func (s *SnapSuite) TestClusterShow(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/cluster")
+		fmt.Fprintln(w, `{
+  "type": "sync",
+  "status-code": 200,
+  "result": {
+    "cluster-id": "cluster-id",
+    "sequence": 2,
+    "authority-id": "my-brand",
+    "devices": [
+      {"id": 1, "device": "serial-1.my-model.my-brand", "addresses": ["10.0.0.1", "192.168.0.1"]},
+      {"id": 2, "device": "serial-2.my-model.my-brand", "addresses": ["10.0.0.2"]}
+    ],
+    "subclusters": [
+      {"name": "default", "devices": [1, 2], "snaps": [{"instance": "hello", "state": "clustered", "channel": "stable"}]}
+    ]
+  }
+}`)
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "show"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `
+cluster-id:    cluster-id
+sequence:      2
+authority-id:  my-brand
+devices:
+  - id:         1
+    device:     serial-1.my-model.my-brand
+    addresses:  10.0.0.1, 192.168.0.1
+  - id:         2
+    device:     serial-2.my-model.my-brand
+    addresses:  10.0.0.2
+subclusters:
+  - name:     default
+    devices:  1, 2
+    snaps:
+      - hello  clustered  stable
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterShowNoCluster(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 404, "result": {"message": "no cluster assertion yet", "kind": "assertion-not-found", "value": "cluster"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "show"})
+	c.Assert(err, ErrorMatches, "cannot get cluster: no cluster assertion yet")
+}
+
+func (s *SnapSuite) TestClusterUpdate(c *C) {
+	bundlePath := filepath.Join(c.MkDir(), "cluster.assert")
+	c.Assert(os.WriteFile(bundlePath, []byte("bundle"), 0644), IsNil)
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v2/cluster")
+		c.Check(r.Header.Get("Content-Type"), Equals, "application/x.ubuntu.assertion")
+		body, err := io.ReadAll(r.Body)
+		c.Assert(err, IsNil)
+		c.Check(string(body), Equals, "bundle")
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": null}`)
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "update", bundlePath})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, fmt.Sprintf("Cluster assertion bundle %s imported\n", bundlePath))
+}
+
+func (s *SnapSuite) TestClusterUpdateMissingFile(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "update", "/does/not/exist"})
+	c.Assert(err, ErrorMatches, "open /does/not/exist: no such file or directory")
+}
+
+func (s *SnapSuite) TestClusterStatus(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/cluster/status")
+		fmt.Fprintln(w, `{
+  "type": "sync",
+  "status-code": 200,
+  "result": {
+    "cluster-id": "cluster-id",
+    "sequence": 2,
+    "device-id": 1,
+    "subclusters": ["default"],
+    "apply-changes": ["12"],
+    "assembled": {
+      "change-id": "42",
+      "completed": "2026-01-02T03:04:05Z",
+      "devices": [{"id": 1, "device": "serial-1.my-model.my-brand", "addresses": ["10.0.0.1:8001"]}]
+    },
+    "compliance": {
+      "cluster-id": "cluster-id",
+      "sequence": 2,
+      "checked": "2026-01-02T04:00:00Z",
+      "drift": [
+        {"subcluster": "default", "snap": "hello", "kind": "channel", "expected": "stable", "current": "edge"},
+        {"subcluster": "default", "snap": "other", "kind": "missing"}
+      ]
+    }
+  }
+}`)
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `
+cluster:      cluster-id (sequence 2)
+device-id:    1
+subclusters:  default
+applying:     12
+compliance:   drifted (sequence 2, checked 2026-01-02T04:00:00Z)
+drift:
+  - default  hello  channel edge, expected stable
+  - default  other  missing
+assembled:   2026-01-02T03:04:05Z (change 42)
+assembled-devices:
+  - id:         1
+    device:     serial-1.my-model.my-brand
+    addresses:  10.0.0.1:8001
+`[1:])
+}
+
+func (s *SnapSuite) TestClusterStatusNoCluster(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"assemble-change": "7"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+cluster:     -
+assembling:  7
+`[1:])
+}
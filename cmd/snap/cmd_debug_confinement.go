@@ -21,6 +21,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 
@@ -31,16 +32,27 @@ var shortConfinementHelp = i18n.G("Print the confinement mode the system operate
 var longConfinementHelp = i18n.G(`
 The confinement command will print the confinement mode (strict,
 partial or none) the system operates in.
+
+With --verbose, the security backends enforcing the confinement of
+snaps and their features are listed as well.
 `)
 
 type cmdConfinement struct {
 	clientMixin
+	Verbose bool `long:"verbose"`
 }
 
+// confinementBackends are the sandbox features reported by the verbose
+// output, in order.
+var confinementBackends = []string{"apparmor", "selinux", "landlock", "seccomp"}
+
 func init() {
 	addDebugCommand("confinement", shortConfinementHelp, longConfinementHelp, func() flags.Commander {
 		return &cmdConfinement{}
-	}, nil, nil)
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"verbose": i18n.G("Show the security backends in use"),
+	}, nil)
 }
 
 func (cmd cmdConfinement) Execute(args []string) error {
@@ -53,5 +65,15 @@ func (cmd cmdConfinement) Execute(args []string) error {
 		return err
 	}
 	fmt.Fprintf(Stdout, "%s\n", sysInfo.Confinement)
+	if !cmd.Verbose {
+		return nil
+	}
+	for _, backend := range confinementBackends {
+		features, ok := sysInfo.SandboxFeatures[backend]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(Stdout, "%s: %s\n", backend, strings.Join(features, " "))
+	}
 	return nil
 }
@@ -37,3 +37,22 @@ func (s *SnapSuite) TestConfinement(c *C) {
 	c.Assert(s.Stdout(), Equals, "strict\n")
 	c.Assert(s.Stderr(), Equals, "")
 }
+
+func (s *SnapSuite) TestConfinementVerbose(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": {"confinement": "strict", "sandbox-features": {
+			"landlock": ["abi:4", "fs", "net-tcp"],
+			"mount": ["freezer-cgroup-v1"],
+			"seccomp": ["bpf-actlog", "bpf-argument-ranges"],
+			"selinux": ["policy-modules", "mode:enforcing"]
+		}}}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confinement", "--verbose"})
+	c.Assert(err, IsNil)
+	c.Assert(s.Stdout(), Equals, `strict
+selinux: policy-modules mode:enforcing
+landlock: abi:4 fs net-tcp
+seccomp: bpf-actlog bpf-argument-ranges
+`)
+	c.Assert(s.Stderr(), Equals, "")
+}
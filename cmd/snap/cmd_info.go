@@ -51,6 +51,7 @@ type infoCmd struct {
 	timeMixin
 
 	Verbose    bool `long:"verbose"`
+	Health     bool `long:"health"`
 	Positional struct {
 		Snaps []anySnapName `positional-arg-name:"<snap>" required:"1"`
 	} `positional-args:"yes" required:"yes"`
@@ -75,6 +76,8 @@ func init() {
 		}, colorDescs.also(timeDescs).also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"verbose": i18n.G("Include more details on the snap (expanded notes, base, etc.)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"health": i18n.G("Include the history of the snap health checks"),
 		}), nil)
 }
 
@@ -164,6 +167,7 @@ type infoWriter struct {
 	fmtTime   func(time.Time) string
 	absTime   bool
 	verbose   bool
+	health    bool
 }
 
 func (iw *infoWriter) setupDiskSnap(path string, diskSnap *client.Snap) {
@@ -222,7 +226,7 @@ func (iw *infoWriter) maybePrintHealth() {
 	}
 	health := iw.localSnap.Health
 	if health == nil {
-		if !iw.verbose {
+		if !iw.verbose && !iw.health {
 			return
 		}
 		health = &client.SnapHealth{
@@ -230,25 +234,38 @@ func (iw *infoWriter) maybePrintHealth() {
 			Message: "health has not been set",
 		}
 	}
-	if health.Status == "okay" && !iw.verbose {
+	if health.Status == "okay" && !iw.verbose && !iw.health {
 		return
 	}
 
 	fmt.Fprintln(iw, "health:")
-	fmt.Fprintf(iw, "  status:\t%s\n", health.Status)
+	iw.printHealthEntry(health, "  ", "  ")
+	if iw.health && len(iw.localSnap.HealthHistory) > 0 {
+		fmt.Fprintln(iw, "  history:")
+		// most recent first
+		for i := len(iw.localSnap.HealthHistory) - 1; i >= 0; i-- {
+			iw.printHealthEntry(&iw.localSnap.HealthHistory[i], "    - ", "      ")
+		}
+	}
+	iw.Flush()
+}
+
+// printHealthEntry prints the given health, with first prefixing its first
+// line and indent the following ones.
+func (iw *infoWriter) printHealthEntry(health *client.SnapHealth, first, indent string) {
+	fmt.Fprintf(iw, "%sstatus:\t%s\n", first, health.Status)
 	if health.Message != "" {
-		strutil.WordWrap(iw, quotedIfNeeded(health.Message), "  message:\t", "    ", iw.termWidth)
+		strutil.WordWrap(iw, quotedIfNeeded(health.Message), indent+"message:\t", indent+"  ", iw.termWidth)
 	}
 	if health.Code != "" {
-		fmt.Fprintf(iw, "  code:\t%s\n", health.Code)
+		fmt.Fprintf(iw, "%scode:\t%s\n", indent, health.Code)
 	}
 	if !health.Timestamp.IsZero() {
-		fmt.Fprintf(iw, "  checked:\t%s\n", iw.fmtTime(health.Timestamp))
+		fmt.Fprintf(iw, "%schecked:\t%s\n", indent, iw.fmtTime(health.Timestamp))
 	}
 	if !health.Revision.Unset() {
-		fmt.Fprintf(iw, "  revision:\t%s\n", health.Revision)
+		fmt.Fprintf(iw, "%srevision:\t%s\n", indent, health.Revision)
 	}
-	iw.Flush()
 }
 
 func (iw *infoWriter) maybePrintTrackingChannel() {
@@ -637,6 +654,7 @@ func (x *infoCmd) Execute([]string) error {
 		esc:          esc,
 		termWidth:    termWidth,
 		verbose:      x.Verbose,
+		health:       x.Health,
 		fmtTime:      x.fmtTime,
 		absTime:      x.AbsTime,
 	}
@@ -1165,6 +1165,43 @@ func (infoSuite) TestMaybePrintHealth(c *check.C) {
 	}
 }
 
+func (infoSuite) TestMaybePrintHealthHistory(c *check.C) {
+	t0 := time.Date(1970, 1, 1, 10, 24, 0, 0, time.UTC)
+	localSnap := &client.Snap{
+		Health: &client.SnapHealth{Status: "okay", Revision: snaplib.R("42"), Timestamp: t0.Add(time.Hour)},
+		HealthHistory: []client.SnapHealth{
+			{Status: "error", Message: "on fire", Revision: snaplib.R("42"), Timestamp: t0},
+			{Status: "okay", Revision: snaplib.R("42"), Timestamp: t0.Add(time.Hour)},
+		},
+	}
+
+	var buf flushBuffer
+	iw := snap.NewInfoWriter(&buf)
+	defer snap.MockIsStdoutTTY(false)()
+
+	snap.SetupSnap(iw, localSnap, nil, nil)
+	// an okay health is not shown by default
+	snap.MaybePrintHealth(iw)
+	c.Check(buf.String(), check.Equals, "")
+
+	snap.SetHealth(iw, true)
+	snap.MaybePrintHealth(iw)
+	c.Check(buf.String(), check.Equals, `health:
+  status:	okay
+  checked:	11:24AM
+  revision:	42
+  history:
+    - status:	okay
+      checked:	11:24AM
+      revision:	42
+    - status:	error
+      message:	on
+        fire
+      checked:	10:24AM
+      revision:	42
+`)
+}
+
 func (infoSuite) TestBug1828425(c *check.C) {
 	const s = `This is a description
                                   that has
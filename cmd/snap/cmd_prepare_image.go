@@ -61,6 +61,7 @@ type cmdPrepareImage struct {
 	WriteRevisionsFile       string   `long:"write-revisions" optional:"true" optional-value:"./seed.manifest"`
 	Validation               string   `long:"validation" choice:"ignore" choice:"enforce"`
 	AllowSnapdKernelMismatch bool     `long:"allow-snapd-kernel-mismatch"`
+	DiskImageDir             string   `long:"disk-image-dir" value-name:"<dir>"`
 
 	// Filenames for extra assertions
 	ExtraAssertionFiles []string `long:"assert" value-name:"<filename>"`
@@ -111,6 +112,8 @@ For preparing classic images it supports a --classic mode`),
 			"allow-snapd-kernel-mismatch": i18n.G("Whether a mismatch between versions of the snapd snap and snapd in kernel is allowed"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"assert": i18n.G("Include the assertion from the local file"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"disk-image-dir": i18n.G("Write a disk image for each gadget volume to the given directory (UC20+ and classic only)"),
 		}, []argDesc{
 			{
 				// TRANSLATORS: This needs to begin with < and end with >
@@ -144,6 +147,7 @@ func (x *cmdPrepareImage) Execute(args []string) error {
 		SeedManifestPath:         x.WriteRevisionsFile,
 		AllowSnapdKernelMismatch: x.AllowSnapdKernelMismatch,
 		ExtraAssertionsFiles:     x.ExtraAssertionFiles,
+		DiskImageDir:             x.DiskImageDir,
 	}
 
 	if x.RevisionsFile != "" {
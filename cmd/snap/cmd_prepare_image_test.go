@@ -285,6 +285,26 @@ func (s *SnapPrepareImageSuite) TestPrepareImagePreseed(c *C) {
 	})
 }
 
+func (s *SnapPrepareImageSuite) TestPrepareImageDiskImageDir(c *C) {
+	var opts *image.Options
+	prep := func(o *image.Options) error {
+		opts = o
+		return nil
+	}
+	r := cmdsnap.MockImagePrepare(prep)
+	defer r()
+
+	rest, err := cmdsnap.Parser(cmdsnap.Client()).ParseArgs([]string{"prepare-image", "--disk-image-dir", "images", "model", "prepare-dir"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+
+	c.Check(opts, DeepEquals, &image.Options{
+		ModelFile:    "model",
+		PrepareDir:   "prepare-dir",
+		DiskImageDir: "images",
+	})
+}
+
 func (s *SnapPrepareImageSuite) TestPrepareImageWriteRevisions(c *C) {
 	var opts *image.Options
 	prep := func(o *image.Options) error {
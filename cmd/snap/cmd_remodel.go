@@ -20,13 +20,22 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/snapfile"
 )
 
 var (
@@ -42,6 +51,14 @@ Snaps and assertions are downloaded from the store unless they are provided as
 local files specified by --snap and --assertion options. If using these
 options, it is expected that all the needed snaps and assertions are provided
 locally, otherwise the remodel will fail.
+
+Instead of a model file, a directory with all that is needed for an offline
+remodel can be given. The directory must contain the new model in a single
+*.model file, the snaps and components in *.snap and *.comp files and the
+assertions in *.assert files. Snaps of the new model that are not in the
+directory must already be installed with the right revision, otherwise the
+remodel will fail. The remodel is checked for completeness before any change
+is made to the device.
 `)
 )
 
@@ -69,9 +86,9 @@ func init() {
 		}),
 		[]argDesc{{
 			// TRANSLATORS: This needs to begin with < and end with >
-			name: i18n.G("<new model file>"),
+			name: i18n.G("<new model file or bundle directory>"),
 			// TRANSLATORS: This should not start with a lowercase letter.
-			desc: i18n.G("New model file"),
+			desc: i18n.G("New model file, or directory with everything needed to remodel offline"),
 		}})
 }
 
@@ -80,16 +97,36 @@ func (x *cmdRemodel) Execute(args []string) error {
 		return ErrExtraArgs
 	}
 	newModelFile := x.RemodelOptions.NewModelFile
+	snapFiles, assertionFiles := x.SnapFiles, x.AssertionFiles
+	st, err := os.Stat(string(newModelFile))
+	if err != nil {
+		return err
+	}
+	bundle := st.IsDir()
+	if bundle {
+		if len(x.SnapFiles) > 0 || len(x.AssertionFiles) > 0 {
+			return errors.New(i18n.G("cannot use --snap or --assertion with a remodel bundle directory"))
+		}
+		var bundleModelFile string
+		bundleModelFile, snapFiles, assertionFiles, err = readRemodelBundle(string(newModelFile))
+		if err != nil {
+			return err
+		}
+		if err := checkRemodelBundle(x.client, bundleModelFile, snapFiles, assertionFiles); err != nil {
+			return err
+		}
+		newModelFile = flags.Filename(bundleModelFile)
+	}
 	modelData, err := os.ReadFile(string(newModelFile))
 	if err != nil {
 		return err
 	}
 
 	var changeID string
-	if len(x.SnapFiles) > 0 || len(x.AssertionFiles) > 0 {
+	if bundle || len(snapFiles) > 0 || len(assertionFiles) > 0 {
 		// don't log the request's body as it will be large
 		x.client.SetMayLogBody(false)
-		changeID, err = x.client.RemodelWithLocalSnaps(modelData, x.SnapFiles, x.AssertionFiles)
+		changeID, err = x.client.RemodelWithLocalSnaps(modelData, snapFiles, assertionFiles)
 		if err != nil {
 			return fmt.Errorf("cannot do offline remodel: %v", err)
 		}
@@ -111,3 +148,239 @@ func (x *cmdRemodel) Execute(args []string) error {
 	fmt.Fprintf(Stdout, i18n.G("New model %s set\n"), newModelFile)
 	return nil
 }
+
+// readRemodelBundle returns the model, the snap and component files and the
+// assertion files found in the given remodel bundle directory.
+func readRemodelBundle(dir string) (modelFile string, snapFiles, assertionFiles []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	var modelFiles []string
+	for _, e := range entries {
+		if e.IsDir() {
+			return "", nil, nil, fmt.Errorf(i18n.G("unexpected directory %q in remodel bundle"), e.Name())
+		}
+		path := filepath.Join(dir, e.Name())
+		switch filepath.Ext(e.Name()) {
+		case ".model":
+			modelFiles = append(modelFiles, path)
+		case ".snap", ".comp":
+			snapFiles = append(snapFiles, path)
+		case ".assert":
+			assertionFiles = append(assertionFiles, path)
+		default:
+			return "", nil, nil, fmt.Errorf(i18n.G("unexpected file %q in remodel bundle"), e.Name())
+		}
+	}
+	if len(modelFiles) != 1 {
+		return "", nil, nil, fmt.Errorf(i18n.G("remodel bundle must contain exactly one model file (%d found)"), len(modelFiles))
+	}
+	return modelFiles[0], snapFiles, assertionFiles, nil
+}
+
+type bundleSnap struct {
+	path string
+	name string
+}
+
+type bundleComponent struct {
+	path string
+	ref  naming.ComponentRef
+}
+
+// checkRemodelBundle checks that the remodel bundle provides, or that the
+// device already has, every required snap and component of the new model and
+// that the bundle carries the assertions for the snaps and components in it.
+func checkRemodelBundle(cli *client.Client, modelFile string, snapFiles, assertionFiles []string) error {
+	modelData, err := os.ReadFile(modelFile)
+	if err != nil {
+		return err
+	}
+	a, err := asserts.Decode(modelData)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot decode model in remodel bundle: %v"), err)
+	}
+	model, ok := a.(*asserts.Model)
+	if !ok {
+		return fmt.Errorf(i18n.G("model file in remodel bundle contains a %s assertion instead of a model"), a.Type().Name)
+	}
+
+	var snaps []bundleSnap
+	var comps []bundleComponent
+	bundled := make(map[string]bool)
+	for _, path := range snapFiles {
+		cont, err := snapfile.Open(path)
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(path) == ".comp" {
+			cinfo, err := snap.ReadComponentInfoFromContainer(cont, nil, nil)
+			if err != nil {
+				return fmt.Errorf(i18n.G("cannot read component %q in remodel bundle: %v"), filepath.Base(path), err)
+			}
+			comps = append(comps, bundleComponent{path: path, ref: cinfo.Component})
+			bundled[cinfo.Component.String()] = true
+			continue
+		}
+		info, err := snap.ReadInfoFromSnapFile(cont, nil)
+		if err != nil {
+			return fmt.Errorf(i18n.G("cannot read snap %q in remodel bundle: %v"), filepath.Base(path), err)
+		}
+		snaps = append(snaps, bundleSnap{path: path, name: info.SnapName()})
+		bundled[info.SnapName()] = true
+	}
+
+	var missing []string
+
+	// required snaps and components not in the bundle must be installed
+	var installed map[string]*client.Snap
+	isInstalled := func(snapName, compName string) (bool, error) {
+		if installed == nil {
+			list, err := cli.List(nil, nil)
+			if err != nil && !errors.Is(err, client.ErrNoSnapsInstalled) {
+				return false, err
+			}
+			installed = make(map[string]*client.Snap, len(list))
+			for _, sn := range list {
+				installed[sn.Name] = sn
+			}
+		}
+		sn := installed[snapName]
+		if sn == nil {
+			return false, nil
+		}
+		if compName == "" {
+			return true, nil
+		}
+		for _, comp := range sn.Components {
+			if comp.Name == compName && comp.InstallDate != nil {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for _, modelSnap := range model.AllSnaps() {
+		if modelSnap.Presence != "required" {
+			continue
+		}
+		if !bundled[modelSnap.Name] {
+			ok, err := isInstalled(modelSnap.Name, "")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missing = append(missing, fmt.Sprintf(i18n.G("snap %q"), modelSnap.Name))
+				continue
+			}
+		}
+		compNames := make([]string, 0, len(modelSnap.Components))
+		for compName, comp := range modelSnap.Components {
+			if comp.Presence == "required" {
+				compNames = append(compNames, compName)
+			}
+		}
+		sort.Strings(compNames)
+		for _, compName := range compNames {
+			ref := naming.NewComponentRef(modelSnap.Name, compName)
+			if bundled[ref.String()] {
+				continue
+			}
+			ok, err := isInstalled(modelSnap.Name, compName)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missing = append(missing, fmt.Sprintf(i18n.G("component %q"), ref))
+			}
+		}
+	}
+
+	assertMissing, err := checkRemodelBundleAssertions(model, snaps, comps, assertionFiles)
+	if err != nil {
+		return err
+	}
+	missing = append(missing, assertMissing...)
+
+	if len(missing) > 0 {
+		return fmt.Errorf(i18n.G("remodel bundle is incomplete, missing:\n- %s"), strings.Join(missing, "\n- "))
+	}
+	return nil
+}
+
+// checkRemodelBundleAssertions returns the assertions missing from the bundle
+// for the snaps and the components in it. Models of dangerous grade can use
+// snaps and components without assertions.
+func checkRemodelBundleAssertions(model *asserts.Model, snaps []bundleSnap, comps []bundleComponent, assertionFiles []string) ([]string, error) {
+	snapRevs := make(map[string]*asserts.SnapRevision)
+	snapDecls := make(map[string]bool)
+	resRevs := make(map[string]*asserts.SnapResourceRevision)
+	resPairs := make(map[string]bool)
+	pairKey := func(snapID, resName string, resRev int) string {
+		return fmt.Sprintf("%s/%s/%d", snapID, resName, resRev)
+	}
+	for _, fn := range assertionFiles {
+		f, err := os.Open(fn)
+		if err != nil {
+			return nil, err
+		}
+		dec := asserts.NewDecoder(f)
+		for {
+			a, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf(i18n.G("cannot decode assertions in %q: %v"), filepath.Base(fn), err)
+			}
+			switch a := a.(type) {
+			case *asserts.SnapRevision:
+				snapRevs[a.SnapSHA3_384()] = a
+			case *asserts.SnapDeclaration:
+				snapDecls[a.SnapID()] = true
+			case *asserts.SnapResourceRevision:
+				resRevs[a.ResourceSHA3_384()] = a
+			case *asserts.SnapResourcePair:
+				resPairs[pairKey(a.SnapID(), a.ResourceName(), a.ResourceRevision())] = true
+			}
+		}
+		f.Close()
+	}
+
+	dangerous := model.Grade() == asserts.ModelDangerous
+	var missing []string
+	for _, sn := range snaps {
+		digest, _, err := asserts.SnapFileSHA3_384(sn.path)
+		if err != nil {
+			return nil, err
+		}
+		snapRev := snapRevs[digest]
+		if snapRev == nil {
+			if !dangerous {
+				missing = append(missing, fmt.Sprintf(i18n.G("snap-revision assertion for snap %q"), sn.name))
+			}
+			continue
+		}
+		if !snapDecls[snapRev.SnapID()] {
+			missing = append(missing, fmt.Sprintf(i18n.G("snap-declaration assertion for snap %q"), sn.name))
+		}
+	}
+	for _, comp := range comps {
+		digest, _, err := asserts.SnapFileSHA3_384(comp.path)
+		if err != nil {
+			return nil, err
+		}
+		resRev := resRevs[digest]
+		if resRev == nil {
+			if !dangerous {
+				missing = append(missing, fmt.Sprintf(i18n.G("snap-resource-revision assertion for component %q"), comp.ref))
+			}
+			continue
+		}
+		if !resPairs[pairKey(resRev.SnapID(), resRev.ResourceName(), resRev.ResourceRevision())] {
+			missing = append(missing, fmt.Sprintf(i18n.G("snap-resource-pair assertion for component %q"), comp.ref))
+		}
+	}
+	return missing, nil
+}
@@ -20,16 +20,21 @@
 package main_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
 	snap "github.com/snapcore/snapd/cmd/snap"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/snap/snaptest"
 )
 
 const remodelOk = `{
@@ -142,3 +147,228 @@ func (s *SnapSuite) TestRemodelLocalSnapsError(c *C) {
 
 	s.ResetStdStreams()
 }
+
+type remodelBundleOpts struct {
+	skipGadget      bool
+	skipDeclaration bool
+	skipPair        bool
+}
+
+// makeRemodelBundle creates a remodel bundle with a new model requiring the
+// pc gadget and the wifi component of the pc-kernel snap, the gadget and the
+// component are in the bundle along with their assertions.
+func makeRemodelBundle(c *C, opts remodelBundleOpts) (bundleDir string, modelData []byte) {
+	storeSigning := assertstest.NewStoreStack("canonical", nil)
+	model, err := storeSigning.Sign(asserts.ModelType, map[string]any{
+		"series":       "16",
+		"brand-id":     "canonical",
+		"model":        "pc-new",
+		"architecture": "amd64",
+		"base":         "core20",
+		"grade":        "signed",
+		"snaps": []any{
+			map[string]any{
+				"name":            "pc-kernel",
+				"id":              snaptest.AssertedSnapID("pc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+				"components": map[string]any{
+					"wifi": "required",
+				},
+			},
+			map[string]any{
+				"name":            "pc",
+				"id":              snaptest.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+
+	bundleDir = filepath.Join(c.MkDir(), "bundle")
+	c.Assert(os.MkdirAll(bundleDir, 0755), IsNil)
+	modelData = asserts.Encode(model)
+	c.Assert(os.WriteFile(filepath.Join(bundleDir, "new.model"), modelData, 0644), IsNil)
+
+	var assertions []asserts.Assertion
+	if !opts.skipGadget {
+		gadgetFile := filepath.Join(bundleDir, "pc.snap")
+		c.Assert(os.Rename(snaptest.MakeTestSnapWithFiles(c, "name: pc\nversion: 1\ntype: gadget\n", nil), gadgetFile), IsNil)
+		digest, size, err := asserts.SnapFileSHA3_384(gadgetFile)
+		c.Assert(err, IsNil)
+		snapRev, err := storeSigning.Sign(asserts.SnapRevisionType, map[string]any{
+			"snap-sha3-384": digest,
+			"snap-size":     fmt.Sprint(size),
+			"snap-id":       snaptest.AssertedSnapID("pc"),
+			"snap-revision": "10",
+			"developer-id":  "canonical",
+			"timestamp":     time.Now().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, IsNil)
+		assertions = append(assertions, snapRev)
+		if !opts.skipDeclaration {
+			snapDecl, err := storeSigning.Sign(asserts.SnapDeclarationType, map[string]any{
+				"series":       "16",
+				"snap-id":      snaptest.AssertedSnapID("pc"),
+				"snap-name":    "pc",
+				"publisher-id": "canonical",
+				"timestamp":    time.Now().Format(time.RFC3339),
+			}, nil, "")
+			c.Assert(err, IsNil)
+			assertions = append(assertions, snapDecl)
+		}
+	}
+
+	compFile := filepath.Join(bundleDir, "pc-kernel+wifi.comp")
+	c.Assert(os.Rename(snaptest.MakeTestComponent(c, "component: pc-kernel+wifi\ntype: kernel-modules\nversion: 1\n"), compFile), IsNil)
+	digest, size, err := asserts.SnapFileSHA3_384(compFile)
+	c.Assert(err, IsNil)
+	resRev, err := storeSigning.Sign(asserts.SnapResourceRevisionType, map[string]any{
+		"snap-id":           snaptest.AssertedSnapID("pc-kernel"),
+		"resource-name":     "wifi",
+		"resource-sha3-384": digest,
+		"resource-size":     fmt.Sprint(size),
+		"resource-revision": "3",
+		"developer-id":      "canonical",
+		"timestamp":         time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	assertions = append(assertions, resRev)
+	if !opts.skipPair {
+		resPair, err := storeSigning.Sign(asserts.SnapResourcePairType, map[string]any{
+			"snap-id":           snaptest.AssertedSnapID("pc-kernel"),
+			"resource-name":     "wifi",
+			"resource-revision": "3",
+			"snap-revision":     "20",
+			"developer-id":      "canonical",
+			"timestamp":         time.Now().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, IsNil)
+		assertions = append(assertions, resPair)
+	}
+
+	var buf bytes.Buffer
+	enc := asserts.NewEncoder(&buf)
+	for _, a := range assertions {
+		c.Assert(enc.Encode(a), IsNil)
+	}
+	c.Assert(os.WriteFile(filepath.Join(bundleDir, "all.assert"), buf.Bytes(), 0644), IsNil)
+
+	return bundleDir, modelData
+}
+
+// the snaps of the model which are not in the remodel bundle are installed
+const remodelBundleInstalledSnaps = `{
+  "type": "sync",
+  "status-code": 200,
+  "status": "OK",
+  "result": [{"name": "core20"}, {"name": "pc-kernel"}]
+}`
+
+func (s *SnapSuite) TestRemodelBundle(c *C) {
+	bundleDir, modelData := makeRemodelBundle(c, remodelBundleOpts{})
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/snaps")
+			fmt.Fprint(w, remodelBundleInstalledSnaps)
+		case 1:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/model")
+
+			form := testForm(r, c)
+			c.Check(form.Value["new-model"], DeepEquals, []string{string(modelData)})
+			c.Check(form.Value["assertion"], HasLen, 1)
+			var snapFiles []string
+			for _, fh := range form.File["snap"] {
+				snapFiles = append(snapFiles, fh.Filename)
+			}
+			c.Check(snapFiles, DeepEquals, []string{"pc-kernel+wifi.comp", "pc.snap"})
+
+			w.WriteHeader(202)
+			fmt.Fprint(w, remodelOk)
+		default:
+			c.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"remodel", "--no-wait", "--offline", bundleDir})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Assert(n, Equals, 2)
+
+	c.Check(s.Stdout(), Matches, "101\n")
+	c.Check(s.Stderr(), Equals, "")
+
+	s.ResetStdStreams()
+}
+
+func (s *SnapSuite) TestRemodelBundleIncomplete(c *C) {
+	bundleDir, _ := makeRemodelBundle(c, remodelBundleOpts{
+		skipGadget: true,
+		skipPair:   true,
+	})
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/snaps")
+		fmt.Fprint(w, remodelBundleInstalledSnaps)
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"remodel", "--no-wait", bundleDir})
+	c.Check(err, ErrorMatches, `remodel bundle is incomplete, missing:
+- snap "pc"
+- snap-resource-pair assertion for component "pc-kernel\+wifi"`)
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestRemodelBundleMissingDeclaration(c *C) {
+	bundleDir, _ := makeRemodelBundle(c, remodelBundleOpts{skipDeclaration: true})
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, remodelBundleInstalledSnaps)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"remodel", "--no-wait", bundleDir})
+	c.Check(err, ErrorMatches, `remodel bundle is incomplete, missing:
+- snap-declaration assertion for snap "pc"`)
+}
+
+func (s *SnapSuite) TestRemodelBundleErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, t := range []struct {
+		files []string
+		args  []string
+		err   string
+	}{
+		{[]string{"pc.snap"}, nil, `remodel bundle must contain exactly one model file \(0 found\)`},
+		{[]string{"a.model", "b.model"}, nil, `remodel bundle must contain exactly one model file \(2 found\)`},
+		{[]string{"a.model", "README"}, nil, `unexpected file "README" in remodel bundle`},
+		{[]string{"a.model"}, []string{"--snap", "pc.snap"}, `cannot use --snap or --assertion with a remodel bundle directory`},
+	} {
+		bundleDir := c.MkDir()
+		for _, name := range t.files {
+			c.Assert(os.WriteFile(filepath.Join(bundleDir, name), nil, 0644), IsNil)
+		}
+
+		args := append([]string{"remodel", "--no-wait"}, t.args...)
+		_, err := snap.Parser(snap.Client()).ParseArgs(append(args, bundleDir))
+		c.Check(err, ErrorMatches, t.err)
+	}
+
+	bundleDir := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(bundleDir, "snaps"), 0755), IsNil)
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"remodel", "--no-wait", bundleDir})
+	c.Check(err, ErrorMatches, `unexpected directory "snaps" in remodel bundle`)
+}
@@ -20,9 +20,13 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
@@ -37,8 +41,9 @@ type svcStatus struct {
 	Positional struct {
 		ServiceNames []serviceName
 	} `positional-args:"yes"`
-	Global bool `long:"global" short:"g"`
-	User   bool `long:"user" short:"u"`
+	Global  bool `long:"global" short:"g"`
+	User    bool `long:"user" short:"u"`
+	Verbose bool `long:"verbose" short:"v"`
 }
 
 type svcLogs struct {
@@ -46,6 +51,12 @@ type svcLogs struct {
 	timeMixin
 	N          string `short:"n" default:"10"`
 	Follow     bool   `short:"f"`
+	Since      string `long:"since"`
+	Until      string `long:"until"`
+	Priority   string `short:"p" long:"priority"`
+	Grep       string `short:"g" long:"grep"`
+	Boot       string `short:"b" long:"boot"`
+	Output     string `long:"output" default:"short" choice:"short" choice:"json"`
 	Positional struct {
 		ServiceNames []serviceName `required:"1"`
 	} `positional-args:"yes" required:"yes"`
@@ -64,11 +75,21 @@ status of the user services for the root user itself, --user can be provided.
 If executed as a non-root user, the 'Startup'|'Current' status of user services 
 will be the current status for the invoking user. To view the global enablement
 status of user services, --global can be provided.
+
+With --verbose, the settings of services overridden by the system administrator
+through the services.<snap>.<app>.* system options are shown as well.
 `)
 	shortLogsHelp = i18n.G("Retrieve logs for services")
 	longLogsHelp  = i18n.G(`
 The logs command fetches logs of the given services and displays them in
-chronological order.
+chronological order. The logs of user services are read from the journal of
+the calling user, unless called as root.
+
+The --since and --until options take either an RFC3339 timestamp, or a
+duration, such as 1h30m, meaning that long ago.
+
+With --output=json every log entry is printed as a JSON object on its own
+line, including all the journal fields of the entry.
 `)
 	shortStartHelp = i18n.G("Start services")
 	longStartHelp  = i18n.G(`
@@ -99,6 +120,8 @@ func init() {
 		"global": i18n.G("Show the global enable status for user services instead of the status for the current user."),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"user": i18n.G("Show the current status of the user services instead of the global enable status."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"verbose": i18n.G("Show the settings overridden by the system administrator."),
 	}, argdescs)
 	addCommand("logs", shortLogsHelp, longLogsHelp, func() flags.Commander { return &svcLogs{} },
 		timeDescs.also(map[string]string{
@@ -106,6 +129,18 @@ func init() {
 			"n": i18n.G("Show only the given number of lines, or 'all'."),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"f": i18n.G("Wait for new lines and print them as they come in."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"since": i18n.G("Show only lines logged at or after the given time."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"until": i18n.G("Show only lines logged at or before the given time."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"priority": i18n.G("Show only lines of the given priority, or range of priorities (e.g. 'err' or 'warning..emerg')."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"grep": i18n.G("Show only lines whose message matches the given pattern."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"boot": i18n.G("Show only lines logged during the given boot, by ID or offset (e.g. '0' or '-1')."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"output": i18n.G("Output format: short or json."),
 		}), argdescs)
 
 	addCommand("start", shortStartHelp, longStartHelp, func() flags.Commander { return &svcStart{} },
@@ -181,15 +216,53 @@ func (s *svcStatus) Execute(args []string) error {
 	w := tabWriter()
 	defer w.Flush()
 
-	fmt.Fprintln(w, i18n.G("Service\tStartup\tCurrent\tNotes"))
+	if s.Verbose {
+		fmt.Fprintln(w, i18n.G("Service\tStartup\tCurrent\tNotes\tOverrides"))
+	} else {
+		fmt.Fprintln(w, i18n.G("Service\tStartup\tCurrent\tNotes"))
+	}
 	for _, svc := range services {
-		fmt.Fprintln(w, clientutil.FmtServiceStatus(svc, clientutil.FmtServiceStatusOptions{
+		line := clientutil.FmtServiceStatus(svc, clientutil.FmtServiceStatusOptions{
 			IsUserGlobal: isGlobal,
-		}))
+		})
+		if s.Verbose {
+			line += "\t" + fmtServiceOverrides(svc.Overrides)
+		}
+		fmt.Fprintln(w, line)
 	}
 	return nil
 }
 
+// fmtServiceOverrides formats the overridden settings of a service as a
+// space separated list of <option>=<value>.
+func fmtServiceOverrides(ovr *client.ServiceOverrides) string {
+	if ovr == nil {
+		return "-"
+	}
+	var settings []string
+	if ovr.RestartCondition != "" {
+		settings = append(settings, "restart-condition="+ovr.RestartCondition)
+	}
+	if ovr.RestartDelay != "" {
+		settings = append(settings, "restart-delay="+ovr.RestartDelay)
+	}
+	if ovr.Timer != "" {
+		settings = append(settings, "timer="+ovr.Timer)
+	}
+	names := make([]string, 0, len(ovr.Environment))
+	for name := range ovr.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		settings = append(settings, fmt.Sprintf("environment.%s=%s", name, ovr.Environment[name]))
+	}
+	if len(settings) == 0 {
+		return "-"
+	}
+	return strings.Join(settings, " ")
+}
+
 func (s *svcLogs) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -204,15 +277,40 @@ func (s *svcLogs) Execute(args []string) error {
 		sN = int(n)
 	}
 
-	logs, err := s.client.Logs(svcNames(s.Positional.ServiceNames), client.LogOptions{N: sN, Follow: s.Follow})
+	since, err := parseLogTime(s.Since)
+	if err != nil {
+		return fmt.Errorf(i18n.G("invalid argument for flag ‘--since’: %v"), err)
+	}
+	until, err := parseLogTime(s.Until)
+	if err != nil {
+		return fmt.Errorf(i18n.G("invalid argument for flag ‘--until’: %v"), err)
+	}
+
+	asJSON := s.Output == "json"
+	logs, err := s.client.Logs(svcNames(s.Positional.ServiceNames), client.LogOptions{
+		N:         sN,
+		Follow:    s.Follow,
+		Priority:  s.Priority,
+		Since:     since,
+		Until:     until,
+		Grep:      s.Grep,
+		Boot:      s.Boot,
+		AllFields: asJSON,
+	})
 	if err != nil {
 		return err
 	}
 
+	enc := json.NewEncoder(Stdout)
 	for log := range logs {
-		if s.AbsTime {
+		switch {
+		case asJSON:
+			if err := enc.Encode(log); err != nil {
+				return err
+			}
+		case s.AbsTime:
 			fmt.Fprintln(Stdout, log.StringInUTC())
-		} else {
+		default:
 			fmt.Fprintln(Stdout, log)
 		}
 	}
@@ -220,6 +318,22 @@ func (s *svcLogs) Execute(args []string) error {
 	return nil
 }
 
+// parseLogTime parses either an RFC3339 timestamp, or a duration which is
+// taken as that long ago.
+func parseLogTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf(i18n.G("expected an RFC3339 timestamp or a duration, got %q"), s)
+	}
+	return timeNow().Add(-d), nil
+}
+
 var userAndScopeDescs = mixinDescs{
 	// TRANSLATORS: This should not start with a lowercase letter.
 	"system": i18n.G("The operation should only affect system services."),
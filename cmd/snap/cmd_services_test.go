@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
 	"strings"
 	"time"
@@ -484,6 +485,76 @@ func (s *appOpSuite) TestServiceCompletion(c *check.C) {
 	c.Check(n, check.Equals, 7)
 }
 
+func (s *appOpSuite) TestAppStatusVerbose(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/apps")
+			c.Check(r.URL.Query().Get("select"), check.Equals, "service")
+			w.WriteHeader(200)
+			enc := json.NewEncoder(w)
+			enc.Encode(map[string]any{
+				"type": "sync",
+				"result": []map[string]any{
+					{
+						"snap":         "foo",
+						"name":         "bar",
+						"daemon":       "simple",
+						"daemon-scope": "system",
+						"active":       true,
+						"enabled":      true,
+						"overrides": map[string]any{
+							"restart-condition": "always",
+							"restart-delay":     "10s",
+							"environment":       map[string]string{"PORT": "8080", "DEBUG": "1"},
+						},
+					}, {
+						"snap":         "foo",
+						"name":         "baz",
+						"daemon":       "oneshot",
+						"daemon-scope": "system",
+						"enabled":      true,
+						"activators": []map[string]any{
+							{"name": "baz", "type": "timer", "active": true, "enabled": true},
+						},
+						"overrides": map[string]any{
+							"timer": "mon,10:00",
+						},
+					}, {
+						"snap":         "foo",
+						"name":         "qux",
+						"daemon":       "simple",
+						"daemon-scope": "system",
+						"active":       true,
+						"enabled":      true,
+					},
+				},
+				"status":      "OK",
+				"status-code": 200,
+			})
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+	r := snap.MockUserCurrent(func() (*user.User, error) {
+		return &user.User{Uid: "0"}, nil
+	})
+	defer r()
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"services", "--verbose"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `Service  Startup  Current   Notes            Overrides
+foo.bar  enabled  active    -                restart-condition=always restart-delay=10s environment.DEBUG=1 environment.PORT=8080
+foo.baz  enabled  inactive  timer-activated  timer=mon,10:00
+foo.qux  enabled  active    -                -
+`)
+	c.Check(n, check.Equals, 1)
+}
+
 func (s *appOpSuite) TestAppStatusUserFailed(c *check.C) {
 	r := snap.MockUserCurrent(func() (*user.User, error) {
 		return nil, fmt.Errorf("oh-no")
@@ -580,6 +651,52 @@ func (s *appOpSuite) TestLogsCommand(c *check.C) {
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *appOpSuite) TestLogsCommandFilterJSON(c *check.C) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	restore := snap.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/logs")
+			c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+				"names":    {"snap"},
+				"n":        {"10"},
+				"priority": {"err"},
+				"since":    {"2024-01-02T02:04:05Z"},
+				"until":    {"2024-01-02T03:00:00Z"},
+				"grep":     {"oops"},
+				"boot":     {"-1"},
+				"fields":   {"all"},
+			})
+			w.WriteHeader(200)
+			_, err := w.Write([]byte{0x1E})
+			c.Assert(err, check.IsNil)
+			_, err = w.Write([]byte(`{"timestamp":"2024-01-02T02:30:00Z","message":"oops","sid":"service1","pid":"1000","fields":{"MESSAGE":"oops","PRIORITY":"3"}}` + "\n"))
+			c.Assert(err, check.IsNil)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"logs", "snap", "--since=1h", "--until=2024-01-02T03:00:00Z", "-p", "err", "--grep=oops", "--boot=-1", "--output=json"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.HasLen, 0)
+
+	c.Check(s.Stdout(), check.Equals, `{"timestamp":"2024-01-02T02:30:00Z","message":"oops","sid":"service1","pid":"1000","fields":{"MESSAGE":"oops","PRIORITY":"3"}}
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *appOpSuite) TestLogsCommandBadSince(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"logs", "snap", "--since=yesterday"})
+	c.Assert(err, check.ErrorMatches, `invalid argument for flag ‘--since’: expected an RFC3339 timestamp or a duration, got "yesterday"`)
+}
+
 func (s *appOpSuite) TestLogsCommandWithAbsTimeFlag(c *check.C) {
 	n := 0
 	timestamp := "2021-08-16T17:33:55Z"
@@ -136,6 +136,10 @@ func SetVerbose(iw *infoWriter, verbose bool) {
 	iw.verbose = verbose
 }
 
+func SetHealth(iw *infoWriter, health bool) {
+	iw.health = health
+}
+
 var (
 	ClientSnapFromPath          = clientSnapFromPath
 	SetupDiskSnap               = (*infoWriter).setupDiskSnap
@@ -29,16 +29,21 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/overlord/swfeats"
 	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/wrappers"
 )
 
 var (
@@ -95,6 +100,13 @@ func getAppsInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	if err != nil {
 		return BadRequest(err.Error())
 	}
+	history, err := readMaybeBoolValue(query, "history")
+	if err != nil {
+		return BadRequest(err.Error())
+	}
+	if history && !opts.service {
+		return BadRequest("history parameter requires select=service")
+	}
 
 	appInfos, rspe := appInfosFor(c.d.overlord.State(), strutil.CommaSeparatedList(query.Get("names")), opts)
 	if rspe != nil {
@@ -117,10 +129,71 @@ func getAppsInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	if err != nil {
 		return InternalError("%v", err)
 	}
+	if history {
+		if err := addServiceHistory(c.d.overlord.State(), clientAppInfos); err != nil {
+			return InternalError("cannot get service history: %v", err)
+		}
+	}
+	if opts.service {
+		if err := addServiceOverrides(c.d.overlord.State(), clientAppInfos); err != nil {
+			return InternalError("cannot get service overrides: %v", err)
+		}
+	}
 
 	return SyncResponse(clientAppInfos)
 }
 
+func addServiceHistory(st *state.State, appInfos []client.AppInfo) error {
+	st.Lock()
+	defer st.Unlock()
+	for i := range appInfos {
+		app := &appInfos[i]
+		events, err := servicestate.ServiceHistory(st, app.Snap, app.Name)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			app.History = append(app.History, client.ServiceStatusEvent{
+				Time:  ev.Time,
+				State: ev.State,
+			})
+		}
+	}
+	return nil
+}
+
+func addServiceOverrides(st *state.State, appInfos []client.AppInfo) error {
+	st.Lock()
+	defer st.Unlock()
+	tr := config.NewTransaction(st)
+	snapOverrides := make(map[string]map[string]*wrappers.ServiceOverrides)
+	for i := range appInfos {
+		app := &appInfos[i]
+		overrides, ok := snapOverrides[app.Snap]
+		if !ok {
+			var err error
+			overrides, err = servicestate.ServiceOverrides(tr, app.Snap)
+			if err != nil {
+				return err
+			}
+			snapOverrides[app.Snap] = overrides
+		}
+		ovr := overrides[app.Name]
+		if ovr == nil {
+			continue
+		}
+		app.Overrides = &client.ServiceOverrides{
+			RestartCondition: string(ovr.RestartCondition),
+			Timer:            ovr.Timer,
+			Environment:      ovr.Environment,
+		}
+		if ovr.RestartDelay > 0 {
+			app.Overrides.RestartDelay = ovr.RestartDelay.String()
+		}
+	}
+	return nil
+}
+
 type appInfoOptions struct {
 	service bool
 }
@@ -245,6 +318,18 @@ func getLogs(c *Command, r *http.Request, user *auth.UserState) Response {
 		}
 		follow = f
 	}
+	filter, rspe := logFilterFromQuery(query)
+	if rspe != nil {
+		return rspe
+	}
+	allFields := false
+	switch s := query.Get("fields"); s {
+	case "":
+	case "all":
+		allFields = true
+	default:
+		return BadRequest(`invalid value for fields: %q`, s)
+	}
 
 	// only services have logs for now
 	opts := appInfoOptions{service: true}
@@ -256,18 +341,91 @@ func getLogs(c *Command, r *http.Request, user *auth.UserState) Response {
 		return AppNotFound("no matching services")
 	}
 
-	reader, err := servicestate.LogReader(appInfos, n, follow)
+	u, err := systemUserFromRequest(r)
 	if err != nil {
-		return InternalError("cannot get logs: %v", err)
+		return BadRequest("cannot get logs: %v", err)
+	}
+
+	// The logs of user daemons are in the journal of each user, so for
+	// users other than root they are read through the session agent of
+	// the requesting user. Root reads them for all users from the system
+	// journal.
+	var sysAppInfos, userAppInfos []*snap.AppInfo
+	for _, appInfo := range appInfos {
+		if appInfo.DaemonScope == snap.UserDaemon && u.Uid != "0" {
+			userAppInfos = append(userAppInfos, appInfo)
+		} else {
+			sysAppInfos = append(sysAppInfos, appInfo)
+		}
+	}
+
+	var readers []io.ReadCloser
+	closeReaders := func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}
+	if len(sysAppInfos) > 0 {
+		reader, err := servicestate.LogReader(sysAppInfos, n, follow, filter)
+		if err != nil {
+			return InternalError("cannot get logs: %v", err)
+		}
+		readers = append(readers, reader)
+	}
+	if len(userAppInfos) > 0 {
+		uid, err := strconv.Atoi(u.Uid)
+		if err != nil {
+			closeReaders()
+			return InternalError("cannot get logs: %v", err)
+		}
+		reader, err := servicestateUserLogReader(r.Context(), uid, userAppInfos, n, follow, filter)
+		if err != nil {
+			closeReaders()
+			return InternalError("cannot get logs of user services: %v", err)
+		}
+		readers = append(readers, reader)
 	}
 
 	return &journalLineReaderSeqResponse{
-		ReadCloser: reader,
+		ReadCloser: systemd.MergeLogReaders(readers, n, follow),
 		follow:     follow,
+		allFields:  allFields,
+	}
+}
+
+func logFilterFromQuery(query url.Values) (*systemd.LogFilter, *apiError) {
+	filter := &systemd.LogFilter{
+		Priority: query.Get("priority"),
+		Grep:     query.Get("grep"),
+		Boot:     query.Get("boot"),
+	}
+	for _, t := range []struct {
+		name string
+		to   *time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	} {
+		s := query.Get(t.name)
+		if s == "" {
+			continue
+		}
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, BadRequest(`invalid value for %s: %q: %v`, t.name, s, err)
+		}
+		*t.to = tm
 	}
+	if err := filter.Validate(); err != nil {
+		return nil, BadRequest("%v", err)
+	}
+	return filter, nil
 }
 
-var servicestateControl = servicestate.Control
+var (
+	servicestateControl       = servicestate.Control
+	servicestateUserLogReader = servicestate.UserLogReader
+)
 
 func decodeServiceInstruction(body io.ReadCloser, u *user.User) (*servicestate.Instruction, error) {
 	var inst servicestate.Instruction
@@ -32,6 +32,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/osutil/user"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -64,6 +66,7 @@ type appsSuite struct {
 	jctlNs             []int
 	jctlFollows        []bool
 	jctlNamespaces     []bool
+	jctlFilters        []*systemd.LogFilter
 	jctlRCs            []io.ReadCloser
 	jctlErrs           []error
 	decoratorResults   map[string]appsSuiteDecoratorResult
@@ -74,11 +77,12 @@ type appsSuite struct {
 	infoA, infoB, infoC, infoD, infoE *snap.Info
 }
 
-func (s *appsSuite) journalctl(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+func (s *appsSuite) journalctl(svcs []string, n int, follow, namespaces bool, filter *systemd.LogFilter) (rc io.ReadCloser, err error) {
 	s.jctlSvcses = append(s.jctlSvcses, svcs)
 	s.jctlNs = append(s.jctlNs, n)
 	s.jctlFollows = append(s.jctlFollows, follow)
 	s.jctlNamespaces = append(s.jctlNamespaces, namespaces)
+	s.jctlFilters = append(s.jctlFilters, filter)
 
 	if len(s.jctlErrs) > 0 {
 		err, s.jctlErrs = s.jctlErrs[0], s.jctlErrs[1:]
@@ -153,6 +157,7 @@ func (s *appsSuite) SetUpTest(c *check.C) {
 	s.jctlNs = nil
 	s.jctlFollows = nil
 	s.jctlNamespaces = nil
+	s.jctlFilters = nil
 	s.jctlRCs = nil
 	s.jctlErrs = nil
 
@@ -500,6 +505,95 @@ func (s *appsSuite) TestGetUserAppsInfoServices(c *check.C) {
 	c.Check(sort.StringsAreSorted(appNames), check.Equals, true)
 }
 
+func (s *appsSuite) TestGetAppsInfoServicesHistory(c *check.C) {
+	r := daemon.MockNewStatusDecorator(func(ctx context.Context, isGlobal bool, uid string) clientutil.StatusDecorator {
+		return s
+	})
+	defer r()
+	s.decoratorResults = map[string]appsSuiteDecoratorResult{
+		"snap-a.svc1": {daemonType: "simple", enabled: true},
+		"snap-a.svc2": {daemonType: "simple", active: true, enabled: true},
+	}
+
+	t0 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	st := s.d.Overlord().State()
+	st.Lock()
+	st.Set("service-history", map[string]map[string][]*servicestate.ServiceStatusEvent{
+		"snap-a": {
+			"svc1": {
+				{Time: t0, State: "active"},
+				{Time: t0.Add(time.Hour), State: "failed"},
+			},
+		},
+	})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/apps?select=service&names=snap-a&history=true", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+	svcs := rsp.Result.([]client.AppInfo)
+	c.Assert(svcs, check.HasLen, 2)
+	c.Check(svcs[0].Name, check.Equals, "svc1")
+	c.Check(svcs[0].History, check.DeepEquals, []client.ServiceStatusEvent{
+		{Time: t0, State: "active"},
+		{Time: t0.Add(time.Hour), State: "failed"},
+	})
+	c.Check(svcs[1].Name, check.Equals, "svc2")
+	c.Check(svcs[1].History, check.HasLen, 0)
+}
+
+func (s *appsSuite) TestGetAppsInfoServicesOverrides(c *check.C) {
+	r := daemon.MockNewStatusDecorator(func(ctx context.Context, isGlobal bool, uid string) clientutil.StatusDecorator {
+		return s
+	})
+	defer r()
+	s.decoratorResults = map[string]appsSuiteDecoratorResult{
+		"snap-a.svc1": {daemonType: "simple", enabled: true},
+		"snap-a.svc2": {daemonType: "simple", active: true, enabled: true},
+	}
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	c.Assert(tr.Set("core", "services.snap-a.svc2.restart-delay", "90s"), check.IsNil)
+	c.Assert(tr.Set("core", "services.snap-a.svc2.environment", map[string]any{"FOO": "bar"}), check.IsNil)
+	tr.Commit()
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/apps?select=service&names=snap-a", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+	svcs := rsp.Result.([]client.AppInfo)
+	c.Assert(svcs, check.HasLen, 2)
+	c.Check(svcs[0].Name, check.Equals, "svc1")
+	c.Check(svcs[0].Overrides, check.IsNil)
+	c.Check(svcs[1].Name, check.Equals, "svc2")
+	c.Check(svcs[1].Overrides, check.DeepEquals, &client.ServiceOverrides{
+		RestartDelay: "1m30s",
+		Environment:  map[string]string{"FOO": "bar"},
+	})
+}
+
+func (s *appsSuite) TestGetAppsInfoHistoryRequiresServices(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/apps?history=true", nil)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "history parameter requires select=service")
+
+	req, err = http.NewRequest("GET", "/v2/apps?select=service&history=maybe", nil)
+	c.Assert(err, check.IsNil)
+
+	rspe = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `invalid history parameter: "maybe"`)
+}
+
 func (s *appsSuite) TestGetAppsInfoBadSelect(c *check.C) {
 	req, err := http.NewRequest("GET", "/v2/apps?select=potato", nil)
 	c.Assert(err, check.IsNil)
@@ -1001,6 +1095,178 @@ func (s *appsSuite) TestLogs(c *check.C) {
 `[1:])
 }
 
+func (s *appsSuite) TestLogsUserServices(c *check.C) {
+	s.expectLogsAccess()
+
+	restore := daemon.MockSystemUserFromRequest(func(r *http.Request) (*user.User, error) {
+		return &user.User{Uid: "1000", Gid: "1000", Username: "user"}, nil
+	})
+	defer restore()
+
+	var userCalls int
+	restore = daemon.MockServicestateUserLogReader(func(ctx context.Context, uid int, appInfos []*snap.AppInfo, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+		userCalls++
+		c.Check(uid, check.Equals, 1000)
+		c.Assert(appInfos, check.HasLen, 1)
+		c.Check(appInfos[0].ServiceName(), check.Equals, "snap.snap-e.svc4.service")
+		c.Check(n, check.Equals, 2)
+		c.Check(follow, check.Equals, false)
+		c.Check(filter.Priority, check.Equals, "err")
+		return io.NopCloser(strings.NewReader(`
+{"MESSAGE": "user1", "SYSLOG_IDENTIFIER": "snap-e.svc4", "_PID": "43", "__REALTIME_TIMESTAMP": "43"}
+{"MESSAGE": "user2", "SYSLOG_IDENTIFIER": "snap-e.svc4", "_PID": "43", "__REALTIME_TIMESTAMP": "47"}
+`)), nil
+	})
+	defer restore()
+
+	s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(`
+{"MESSAGE": "system1", "SYSLOG_IDENTIFIER": "snap-a.svc2", "_PID": "42", "__REALTIME_TIMESTAMP": "42"}
+{"MESSAGE": "system2", "SYSLOG_IDENTIFIER": "snap-a.svc2", "_PID": "42", "__REALTIME_TIMESTAMP": "46"}
+`))}
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-a.svc2,snap-e.svc4&n=2&priority=err", nil)
+	c.Assert(err, check.IsNil)
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+
+	// only the system service is read from the system journal
+	c.Check(s.jctlSvcses, check.DeepEquals, [][]string{{"snap.snap-a.svc2.service"}})
+	c.Check(userCalls, check.Equals, 1)
+
+	// the last entries of both journals are returned
+	c.Check(rec.Body.String(), check.Equals, "\x1e"+`{"timestamp":"1970-01-01T00:00:00.000046Z","message":"system2","sid":"snap-a.svc2","pid":"42"}
+`+"\x1e"+`{"timestamp":"1970-01-01T00:00:00.000047Z","message":"user2","sid":"snap-e.svc4","pid":"43"}
+`)
+}
+
+func (s *appsSuite) TestLogsUserServicesAsRoot(c *check.C) {
+	s.expectLogsAccess()
+
+	restore := daemon.MockServicestateUserLogReader(func(ctx context.Context, uid int, appInfos []*snap.AppInfo, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil
+	})
+	defer restore()
+
+	// root reads the logs of the user daemons of all users from the
+	// system journal, matching them as user units
+	var userUnitsCalls [][]string
+	restore = systemd.MockUserUnitsJournalctl(func(svcs []string, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+		userUnitsCalls = append(userUnitsCalls, svcs)
+		c.Check(n, check.Equals, 2)
+		return io.NopCloser(strings.NewReader(`
+{"MESSAGE": "user1", "SYSLOG_IDENTIFIER": "snap-e.svc4", "_PID": "43", "__REALTIME_TIMESTAMP": "43"}
+{"MESSAGE": "user2", "SYSLOG_IDENTIFIER": "snap-e.svc4", "_PID": "43", "__REALTIME_TIMESTAMP": "47"}
+`)), nil
+	})
+	defer restore()
+
+	s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(`
+{"MESSAGE": "system1", "SYSLOG_IDENTIFIER": "snap-a.svc2", "_PID": "42", "__REALTIME_TIMESTAMP": "42"}
+{"MESSAGE": "system2", "SYSLOG_IDENTIFIER": "snap-a.svc2", "_PID": "42", "__REALTIME_TIMESTAMP": "46"}
+`))}
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-a.svc2,snap-e.svc4&n=2", nil)
+	c.Assert(err, check.IsNil)
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	c.Check(s.jctlSvcses, check.DeepEquals, [][]string{{"snap.snap-a.svc2.service"}})
+	c.Check(userUnitsCalls, check.DeepEquals, [][]string{{"snap.snap-e.svc4.service"}})
+
+	c.Check(rec.Body.String(), check.Equals, "\x1e"+`{"timestamp":"1970-01-01T00:00:00.000046Z","message":"system2","sid":"snap-a.svc2","pid":"42"}
+`+"\x1e"+`{"timestamp":"1970-01-01T00:00:00.000047Z","message":"user2","sid":"snap-e.svc4","pid":"43"}
+`)
+}
+
+func (s *appsSuite) TestLogsUserServicesError(c *check.C) {
+	s.expectLogsAccess()
+
+	restore := daemon.MockSystemUserFromRequest(func(r *http.Request) (*user.User, error) {
+		return &user.User{Uid: "1000", Gid: "1000", Username: "user"}, nil
+	})
+	defer restore()
+
+	restore = daemon.MockServicestateUserLogReader(func(ctx context.Context, uid int, appInfos []*snap.AppInfo, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+		return nil, errors.New("no session agent")
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-e.svc4", nil)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 500)
+	c.Check(rspe.Message, check.Equals, "cannot get logs of user services: no session agent")
+	c.Check(s.jctlSvcses, check.HasLen, 0)
+}
+
+func (s *appsSuite) TestLogsFilter(c *check.C) {
+	s.expectLogsAccess()
+
+	s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(""))}
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-a.svc2&priority=warning..emerg&since=2024-01-02T03:04:05Z&until=2024-01-02T05:00:00%2B01:00&grep=oops&boot=-1", nil)
+	c.Assert(err, check.IsNil)
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+
+	c.Assert(s.jctlFilters, check.HasLen, 1)
+	filter := s.jctlFilters[0]
+	c.Check(filter.Priority, check.Equals, "warning..emerg")
+	c.Check(filter.Since.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), check.Equals, true)
+	c.Check(filter.Until.Equal(time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC)), check.Equals, true)
+	c.Check(filter.Grep, check.Equals, "oops")
+	c.Check(filter.Boot, check.Equals, "-1")
+}
+
+func (s *appsSuite) TestLogsBadFilter(c *check.C) {
+	s.expectLogsAccess()
+
+	for _, t := range []struct {
+		query string
+		err   string
+	}{
+		{"priority=error", `invalid log priority "error"`},
+		{"since=yesterday", `invalid value for since: "yesterday": .*`},
+		{"until=1h", `invalid value for until: "1h": .*`},
+		{"since=2024-01-02T03:04:05Z&until=2024-01-01T03:04:05Z", `invalid log time range: until is before since`},
+		{"boot=latest", `invalid boot "latest"`},
+		{"fields=some", `invalid value for fields: "some"`},
+	} {
+		req, err := http.NewRequest("GET", "/v2/logs?"+t.query, nil)
+		c.Assert(err, check.IsNil)
+
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, 400, check.Commentf(t.query))
+		c.Check(rspe.Message, check.Matches, t.err, check.Commentf(t.query))
+	}
+	c.Check(s.jctlFilters, check.HasLen, 0)
+}
+
+func (s *appsSuite) TestLogsAllFields(c *check.C) {
+	s.expectLogsAccess()
+
+	s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(`
+{"MESSAGE": "hello1", "SYSLOG_IDENTIFIER": "xyzzy", "_PID": "42", "__REALTIME_TIMESTAMP": "42", "PRIORITY": "6", "_BOOT_ID": "1234"}
+	`))}
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-a.svc2&fields=all", nil)
+	c.Assert(err, check.IsNil)
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+
+	c.Check(rec.Code, check.Equals, 200)
+	c.Check(rec.Body.String(), check.Equals, "\x1e"+`{"timestamp":"1970-01-01T00:00:00.000042Z","message":"hello1","sid":"xyzzy","pid":"42","fields":{"MESSAGE":"hello1","PRIORITY":"6","SYSLOG_IDENTIFIER":"xyzzy","_BOOT_ID":"1234","_PID":"42","__REALTIME_TIMESTAMP":"42"}}
+`)
+}
+
 func (s *appsSuite) TestLogsNoNamespaceOption(c *check.C) {
 	restore := systemd.MockSystemdVersion(237, nil)
 	defer restore()
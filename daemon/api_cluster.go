@@ -0,0 +1,279 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+)
+
+var (
+	clusterCmd = &Command{
+		Path:        "/v2/cluster",
+		GET:         getCluster,
+		POST:        postCluster,
+		Actions:     []string{"assemble"},
+		ReadAccess:  openAccess{},
+		WriteAccess: rootAccess{},
+	}
+	clusterStatusCmd = &Command{
+		Path:       "/v2/cluster/status",
+		GET:        getClusterStatus,
+		ReadAccess: openAccess{},
+	}
+)
+
+var clusterstateAssemble = clusterstate.Assemble
+
+func getCluster(c *Command, r *http.Request, _ *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	cluster, err := clusterstate.CurrentCluster(st)
+	if errors.Is(err, clusterstate.ErrNoClusterAssertion) {
+		return &apiError{
+			Status:  404,
+			Message: "no cluster assertion yet",
+			Kind:    client.ErrorKindAssertionNotFound,
+			Value:   "cluster",
+		}
+	}
+	if err != nil {
+		return InternalError("cannot get cluster: %v", err)
+	}
+
+	result := client.Cluster{
+		ClusterID:   cluster.ClusterID(),
+		Sequence:    cluster.Sequence(),
+		AuthorityID: cluster.AuthorityID(),
+		Devices:     make([]client.ClusterDevice, 0, len(cluster.Devices())),
+		Subclusters: make([]client.ClusterSubcluster, 0, len(cluster.Subclusters())),
+	}
+	for _, dev := range cluster.Devices() {
+		result.Devices = append(result.Devices, client.ClusterDevice{
+			ID:        dev.ID,
+			Device:    dev.DeviceID.String(),
+			Addresses: dev.Addresses,
+		})
+	}
+	for _, sc := range cluster.Subclusters() {
+		subcluster := client.ClusterSubcluster{
+			Name:    sc.Name,
+			Devices: sc.Devices,
+		}
+		for _, sn := range sc.Snaps {
+			subcluster.Snaps = append(subcluster.Snaps, client.ClusterSnap{
+				Instance: sn.Instance,
+				State:    string(sn.State),
+				Channel:  sn.Channel,
+			})
+		}
+		result.Subclusters = append(result.Subclusters, subcluster)
+	}
+
+	return SyncResponse(result)
+}
+
+type postClusterData struct {
+	Action       string   `json:"action"`
+	Secret       string   `json:"secret"`
+	Address      string   `json:"address"`
+	ExpectedSize int      `json:"expected-size"`
+	Peers        []string `json:"peers"`
+	Discover     bool     `json:"discover"`
+	Interface    string   `json:"interface"`
+	Subnet       string   `json:"subnet"`
+	Timeout      string   `json:"timeout"`
+}
+
+func postCluster(c *Command, r *http.Request, _ *auth.UserState) Response {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "application/json"
+	}
+
+	switch mediaType {
+	case "application/json":
+		return postClusterAction(c, r)
+	case asserts.MediaType:
+		return postClusterBundle(c, r)
+	default:
+		return BadRequest("unexpected media type %q", mediaType)
+	}
+}
+
+func postClusterAction(c *Command, r *http.Request) Response {
+	var data postClusterData
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&data); err != nil {
+		return BadRequest("cannot decode request body into cluster action: %v", err)
+	}
+
+	switch data.Action {
+	case "assemble":
+		return clusterAssemble(c, &data)
+	case "":
+		return BadRequest("cluster action is required")
+	default:
+		return BadRequest("unsupported cluster action %q", data.Action)
+	}
+}
+
+func clusterAssemble(c *Command, data *postClusterData) Response {
+	opts := clusterstate.AssembleOptions{
+		Secret:       data.Secret,
+		Address:      data.Address,
+		ExpectedSize: data.ExpectedSize,
+		Peers:        data.Peers,
+		Discover:     data.Discover,
+		Interface:    data.Interface,
+		Subnet:       data.Subnet,
+	}
+	if data.Timeout != "" {
+		timeout, err := time.ParseDuration(data.Timeout)
+		if err != nil {
+			return BadRequest("invalid assemble timeout: %v", err)
+		}
+		opts.Timeout = timeout
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	chg, err := clusterstateAssemble(st, opts)
+	if err != nil {
+		return errToResponse(err, nil, BadRequest, "cannot assemble cluster: %v")
+	}
+	ensureStateSoon(st)
+
+	return AsyncResponse(nil, chg.ID())
+}
+
+func postClusterBundle(c *Command, r *http.Request) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err := clusterstate.CurrentCluster(st)
+	switch {
+	case errors.Is(err, clusterstate.ErrNoClusterAssertion):
+		err = clusterstate.InitializeNewCluster(st, r.Body)
+	case err == nil:
+		err = clusterstate.UpdateCluster(st, r.Body)
+	}
+	if err != nil {
+		return BadRequest("cannot import cluster bundle: %v", err)
+	}
+	ensureStateSoon(st)
+
+	return SyncResponse(nil)
+}
+
+func getClusterStatus(c *Command, r *http.Request, _ *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	status, err := clusterstate.ClusterStatus(st)
+	if err != nil {
+		return InternalError("cannot get cluster status: %v", err)
+	}
+
+	result := client.ClusterStatus{
+		ClusterID:      status.ClusterID,
+		Sequence:       status.Sequence,
+		DeviceID:       status.DeviceID,
+		Subclusters:    status.Subclusters,
+		ApplyChanges:   status.ApplyChanges,
+		AssembleChange: status.AssembleChange,
+	}
+	if status.Assembled != nil {
+		devices, err := assembledDevices(status.Assembled.Devices)
+		if err != nil {
+			return InternalError("cannot get cluster status: %v", err)
+		}
+		result.Assembled = &client.ClusterAssembled{
+			ChangeID:  status.Assembled.ChangeID,
+			Completed: status.Assembled.Completed,
+			Devices:   devices,
+		}
+	}
+	if status.Compliance != nil {
+		result.Compliance = &client.ClusterCompliance{
+			ClusterID: status.Compliance.ClusterID,
+			Sequence:  status.Compliance.Sequence,
+			Checked:   status.Compliance.Checked,
+		}
+		for _, d := range status.Compliance.Drift {
+			result.Compliance.Drift = append(result.Compliance.Drift, client.ClusterDrift{
+				Subcluster: d.Subcluster,
+				Snap:       d.Snap,
+				Kind:       string(d.Kind),
+				Expected:   d.Expected,
+				Current:    d.Current,
+			})
+		}
+	}
+
+	return SyncResponse(result)
+}
+
+// assembledDevices converts the devices found by an assemble session, in the
+// form of the devices header of a cluster assertion.
+func assembledDevices(devices []any) ([]client.ClusterDevice, error) {
+	result := make([]client.ClusterDevice, 0, len(devices))
+	for _, d := range devices {
+		device, ok := d.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid assembled device %v", d)
+		}
+		id, _ := device["id"].(string)
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assembled device id %q", id)
+		}
+		name, _ := device["device"].(string)
+		var addresses []string
+		addrs, _ := device["addresses"].([]any)
+		for _, a := range addrs {
+			if addr, ok := a.(string); ok {
+				addresses = append(addresses, addr)
+			}
+		}
+		result = append(result, client.ClusterDevice{
+			ID:        n,
+			Device:    name,
+			Addresses: addresses,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,288 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type clusterSuite struct {
+	apiBaseSuite
+}
+
+var _ = Suite(&clusterSuite{})
+
+func (s *clusterSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.expectReadAccess(daemon.OpenAccess{})
+	s.expectWriteAccess(daemon.RootAccess{})
+}
+
+func (s *clusterSuite) clusterBundle(c *C, sequence int) []byte {
+	cluster, err := s.Brands.Signing("my-brand").Sign(asserts.ClusterType, map[string]any{
+		"type":       "cluster",
+		"cluster-id": "cluster-id",
+		"sequence":   strconv.Itoa(sequence),
+		"devices": []any{
+			map[string]any{
+				"id":        "1",
+				"device":    "serial-1.my-model.my-brand",
+				"addresses": []any{"10.0.0.1"},
+			},
+		},
+		"subclusters": []any{
+			map[string]any{
+				"name":    "default",
+				"devices": []any{"1"},
+				"snaps": []any{
+					map[string]any{
+						"state":    "clustered",
+						"instance": "hello",
+						"channel":  "stable",
+					},
+				},
+			},
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+
+	var buf bytes.Buffer
+	enc := asserts.NewEncoder(&buf)
+	for _, a := range []asserts.Assertion{
+		s.StoreSigning.StoreAccountKey(""),
+		s.Brands.Account("my-brand"),
+		s.Brands.AccountKey("my-brand"),
+		cluster,
+	} {
+		c.Assert(enc.Encode(a), IsNil)
+	}
+	return buf.Bytes()
+}
+
+func (s *clusterSuite) postBundle(c *C, bundle []byte) *http.Request {
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewReader(bundle))
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", asserts.MediaType)
+	return req
+}
+
+func (s *clusterSuite) TestGetClusterNone(c *C) {
+	s.daemonWithOverlordMockAndStore()
+
+	req, err := http.NewRequest("GET", "/v2/cluster", nil)
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 404)
+	c.Check(rspe.Kind, Equals, client.ErrorKindAssertionNotFound)
+	c.Check(rspe.Message, Equals, "no cluster assertion yet")
+}
+
+func (s *clusterSuite) TestImportAndGetCluster(c *C) {
+	s.daemonWithOverlordMockAndStore()
+
+	rsp := s.syncReq(c, s.postBundle(c, s.clusterBundle(c, 1)), nil, actionIsUnexpected)
+	c.Check(rsp.Status, Equals, 200)
+
+	req, err := http.NewRequest("GET", "/v2/cluster", nil)
+	c.Assert(err, IsNil)
+	rsp = s.syncReq(c, req, nil, actionIsUnexpected)
+	c.Check(rsp.Result, DeepEquals, client.Cluster{
+		ClusterID:   "cluster-id",
+		Sequence:    1,
+		AuthorityID: "my-brand",
+		Devices: []client.ClusterDevice{
+			{ID: 1, Device: "serial-1.my-model.my-brand", Addresses: []string{"10.0.0.1"}},
+		},
+		Subclusters: []client.ClusterSubcluster{{
+			Name:    "default",
+			Devices: []int{1},
+			Snaps:   []client.ClusterSnap{{Instance: "hello", State: "clustered", Channel: "stable"}},
+		}},
+	})
+
+	// a newer bundle updates the cluster
+	s.syncReq(c, s.postBundle(c, s.clusterBundle(c, 2)), nil, actionIsUnexpected)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	cluster, err := clusterstate.CurrentCluster(st)
+	st.Unlock()
+	c.Assert(err, IsNil)
+	c.Check(cluster.Sequence(), Equals, 2)
+
+	// but not an older one
+	rspe := s.errorReq(c, s.postBundle(c, s.clusterBundle(c, 1)), nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Matches, `cannot import cluster bundle: cluster assertion sequence 1 must be greater than current sequence 2`)
+}
+
+func (s *clusterSuite) TestImportClusterInvalidBundle(c *C) {
+	s.daemonWithOverlordMockAndStore()
+
+	rspe := s.errorReq(c, s.postBundle(c, []byte("garbage")), nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Matches, `cannot import cluster bundle: .*`)
+}
+
+func (s *clusterSuite) TestPostClusterUnexpectedMediaType(c *C) {
+	s.daemonWithOverlordMockAndStore()
+
+	req, err := http.NewRequest("POST", "/v2/cluster", strings.NewReader("data"))
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", "text/plain")
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `unexpected media type "text/plain"`)
+}
+
+func (s *clusterSuite) TestPostClusterAssemble(c *C) {
+	d := s.daemonWithOverlordMockAndStore()
+
+	var gotOpts clusterstate.AssembleOptions
+	restore := daemon.MockClusterstateAssemble(func(st *state.State, opts clusterstate.AssembleOptions) (*state.Change, error) {
+		gotOpts = opts
+		chg := st.NewChange("assemble-cluster", "Assemble cluster")
+		return chg, nil
+	})
+	defer restore()
+
+	body := `{"action": "assemble", "secret": "secret", "address": "10.0.0.1:8001", "expected-size": 3, "peers": ["10.0.0.2:8001"], "discover": true, "interface": "eth0", "subnet": "10.0.0.0/24", "timeout": "10m"}`
+	req, err := http.NewRequest("POST", "/v2/cluster", strings.NewReader(body))
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	c.Check(gotOpts, DeepEquals, clusterstate.AssembleOptions{
+		Secret:       "secret",
+		Address:      "10.0.0.1:8001",
+		ExpectedSize: 3,
+		Peers:        []string{"10.0.0.2:8001"},
+		Discover:     true,
+		Interface:    "eth0",
+		Subnet:       "10.0.0.0/24",
+		Timeout:      10 * time.Minute,
+	})
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "assemble-cluster")
+}
+
+func (s *clusterSuite) TestPostClusterAssembleErrors(c *C) {
+	s.daemonWithOverlordMockAndStore()
+
+	restore := daemon.MockClusterstateAssemble(func(st *state.State, opts clusterstate.AssembleOptions) (*state.Change, error) {
+		if opts.Secret == "busy" {
+			return nil, &snapstate.ChangeConflictError{
+				Message:    "cluster assembly already in progress",
+				ChangeKind: "assemble-cluster",
+				ChangeID:   "1",
+			}
+		}
+		return nil, errors.New("cannot assemble a cluster without a secret")
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		body    string
+		status  int
+		message string
+	}{
+		{`{}`, 400, "cluster action is required"},
+		{`{"action": "foo"}`, 400, `unsupported cluster action "foo"`},
+		{`{"action": "assemble", "timeout": "soon"}`, 400, `invalid assemble timeout: .*`},
+		{`{"action": "assemble"}`, 400, "cannot assemble cluster: cannot assemble a cluster without a secret"},
+		{`{"action": "assemble", "secret": "busy"}`, 409, "cluster assembly already in progress"},
+	} {
+		req, err := http.NewRequest("POST", "/v2/cluster", strings.NewReader(tc.body))
+		c.Assert(err, IsNil)
+		req.Header.Set("Content-Type", "application/json")
+		rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+		c.Check(rspe.Status, Equals, tc.status, Commentf(tc.body))
+		c.Check(rspe.Message, Matches, tc.message, Commentf(tc.body))
+	}
+}
+
+func (s *clusterSuite) TestGetClusterStatus(c *C) {
+	d := s.daemonWithOverlordMockAndStore()
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("cluster-assembled", clusterstate.AssembledCluster{
+		ChangeID:  "7",
+		Completed: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Devices: []any{
+			map[string]any{
+				"id":        "1",
+				"device":    "serial-1.my-model.my-brand",
+				"addresses": []any{"10.0.0.1:8001"},
+			},
+		},
+	})
+	st.Set("cluster-compliance", clusterstate.Compliance{
+		ClusterID: "cluster-id",
+		Sequence:  2,
+		Checked:   time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC),
+		Drift: []clusterstate.Drift{
+			{Subcluster: "default", Snap: "hello", Kind: clusterstate.DriftMissing},
+		},
+	})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/cluster/status", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsUnexpected)
+	c.Check(rsp.Result, DeepEquals, client.ClusterStatus{
+		Assembled: &client.ClusterAssembled{
+			ChangeID:  "7",
+			Completed: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Devices: []client.ClusterDevice{
+				{ID: 1, Device: "serial-1.my-model.my-brand", Addresses: []string{"10.0.0.1:8001"}},
+			},
+		},
+		Compliance: &client.ClusterCompliance{
+			ClusterID: "cluster-id",
+			Sequence:  2,
+			Checked:   time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC),
+			Drift: []client.ClusterDrift{
+				{Subcluster: "default", Snap: "hello", Kind: "missing"},
+			},
+		},
+	})
+}
@@ -35,6 +35,7 @@ import (
 
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/randutil"
 	"github.com/snapcore/snapd/snap"
@@ -152,7 +153,15 @@ func streamOneSnap(ctx context.Context, c *Command, action snapDownloadAction, u
 		if err != nil {
 			return InternalError(err.Error())
 		}
-		ss.stream = stream
+		// share the configured download bandwidth with refreshes
+		st := c.d.overlord.State()
+		st.Lock()
+		rate, rateSched := snapstate.RefreshRateLimit(st)
+		st.Unlock()
+		ss.stream = store.RateLimitedStream(stream, &store.DownloadOptions{
+			RateLimit:         rate,
+			RateLimitSchedule: rateSched,
+		})
 		if status != 206 {
 			// store/cdn has no partial content (valid
 			// reply per RFC)
@@ -36,6 +36,7 @@ var noticeReadInterfaces = map[state.NoticeType][]string{
 	state.SnapRunInhibitNotice:               {"snap-refresh-observe"},
 	state.InterfacesRequestsPromptNotice:     {"snap-interfaces-requests-control"},
 	state.InterfacesRequestsRuleUpdateNotice: {"snap-interfaces-requests-control"},
+	state.SnapHealthRevertNotice:             {"snap-refresh-observe"},
 }
 
 var (
@@ -1183,6 +1183,9 @@ UnitFileState=enabled
 	st.Set("health", map[string]healthstate.HealthState{
 		"foo": {Status: healthstate.OkayStatus},
 	})
+	st.Set("health-history", map[string][]healthstate.HealthState{
+		"foo": {{Status: healthstate.ErrorStatus, Message: "oops"}, {Status: healthstate.OkayStatus}},
+	})
 	err := snapstate.Get(st, "foo", &snapst)
 	st.Unlock()
 	c.Assert(err, check.IsNil)
@@ -1230,8 +1233,12 @@ UnitFileState=enabled
 				DisplayName: "Bar",
 				Validation:  "unproven",
 			},
-			Status:      "active",
-			Health:      &client.SnapHealth{Status: "okay"},
+			Status: "active",
+			Health: &client.SnapHealth{Status: "okay"},
+			HealthHistory: []client.SnapHealth{
+				{Status: "error", Message: "oops"},
+				{Status: "okay"},
+			},
 			Icon:        "/v2/icons/foo/icon",
 			Type:        string(snap.TypeApp),
 			Base:        "base18",
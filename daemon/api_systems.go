@@ -622,6 +622,7 @@ func postSystemActionCreate(c *Command, req *systemActionRequest) Response {
 		TestSystem:     req.TestSystem,
 		MarkDefault:    req.MarkDefault,
 		Offline:        req.Offline,
+		CaptureState:   req.CaptureState,
 	})
 	if err != nil {
 		return InternalError("cannot create recovery system %q: %v", req.Label, err)
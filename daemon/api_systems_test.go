@@ -3102,6 +3102,41 @@ func (s *systemsCreateSuite) TestCreateSystemActionOfflinePreinstalledJSON(c *ch
 	c.Check(st.Change(res.Change), check.NotNil)
 }
 
+func (s *systemsCreateSuite) TestCreateSystemActionCaptureState(c *check.C) {
+	const (
+		expectedLabel = "golden"
+	)
+
+	daemon.MockDevicestateCreateRecoverySystem(func(st *state.State, label string, opts devicestate.CreateRecoverySystemOptions) (*state.Change, error) {
+		c.Check(expectedLabel, check.Equals, label)
+		c.Check(opts.CaptureState, check.Equals, true)
+		c.Check(opts.MarkDefault, check.Equals, true)
+
+		return st.NewChange("change", "..."), nil
+	})
+
+	body := map[string]any{
+		"action":        "create",
+		"label":         expectedLabel,
+		"capture-state": true,
+		"mark-default":  true,
+	}
+
+	b, err := json.Marshal(body)
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("POST", "/v2/systems", bytes.NewBuffer(b))
+	c.Assert(err, check.IsNil)
+
+	res := s.asyncReq(c, req, nil, actionIsExpected)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+
+	c.Check(st.Change(res.Change), check.NotNil)
+}
+
 func (s *systemsCreateSuite) TestCreateSystemActionOfflinePreinstalledForm(c *check.C) {
 	const (
 		expectedLabel = "1234"
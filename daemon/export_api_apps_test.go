@@ -20,11 +20,15 @@
 package daemon
 
 import (
+	"context"
+	"io"
+
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/systemd"
 )
 
 func MockServicestateControl(f func(st *state.State, appInfos []*snap.AppInfo, inst *servicestate.Instruction, cu *user.User, flags *servicestate.Flags, context *hookstate.Context) ([]*state.TaskSet, error)) (restore func()) {
@@ -35,6 +39,14 @@ func MockServicestateControl(f func(st *state.State, appInfos []*snap.AppInfo, i
 	}
 }
 
+func MockServicestateUserLogReader(f func(ctx context.Context, uid int, appInfos []*snap.AppInfo, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error)) (restore func()) {
+	old := servicestateUserLogReader
+	servicestateUserLogReader = f
+	return func() {
+		servicestateUserLogReader = old
+	}
+}
+
 type (
 	AppInfoOptions = appInfoOptions
 )
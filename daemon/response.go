@@ -283,6 +283,9 @@ func (f fileResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 type journalLineReaderSeqResponse struct {
 	io.ReadCloser
 	follow bool
+	// allFields is whether all the fields of the journal entries are
+	// included in the response
+	allFields bool
 }
 
 func (rr *journalLineReaderSeqResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -304,12 +307,16 @@ func (rr *journalLineReaderSeqResponse) ServeHTTP(w http.ResponseWriter, r *http
 
 		// ignore the error...
 		t, _ := log.Time()
-		if err = enc.Encode(client.Log{
+		entry := client.Log{
 			Timestamp: t,
 			Message:   log.Message(),
 			SID:       log.SID(),
 			PID:       log.PID(),
-		}); err != nil {
+		}
+		if rr.allFields {
+			entry.Fields = log.Fields()
+		}
+		if err = enc.Encode(entry); err != nil {
 			break
 		}
 
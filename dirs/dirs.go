@@ -52,6 +52,8 @@ var (
 	SnapLdconfigDir      string
 	SnapSeccompBase      string
 	SnapSeccompDir       string
+	SnapLandlockDir      string
+	SnapSELinuxDir       string
 	SnapMountPolicyDir   string
 	SnapCgroupPolicyDir  string
 	SnapUdevRulesDir     string
@@ -76,11 +78,12 @@ var (
 	SnapSeedDir   string
 	SnapDeviceDir string
 
-	SnapAssertsDBDir      string
-	SnapCookieDir         string
-	SnapTrustedAccountKey string
-	SnapAssertsSpoolDir   string
-	SnapSeqDir            string
+	SnapAssertsDBDir         string
+	SnapAssertsLocalTrustDir string
+	SnapCookieDir            string
+	SnapTrustedAccountKey    string
+	SnapAssertsSpoolDir      string
+	SnapSeqDir               string
 
 	SnapStateFile     string
 	SnapStateLockFile string
@@ -575,6 +578,8 @@ func SetRootDir(rootdir string) {
 	SnapDownloadCacheDir = filepath.Join(rootdir, snappyDir, "cache")
 	SnapSeccompBase = filepath.Join(rootdir, snappyDir, "seccomp")
 	SnapSeccompDir = filepath.Join(SnapSeccompBase, "bpf")
+	SnapLandlockDir = filepath.Join(rootdir, snappyDir, "landlock")
+	SnapSELinuxDir = filepath.Join(rootdir, snappyDir, "selinux")
 	SnapMountPolicyDir = filepath.Join(rootdir, snappyDir, "mount")
 	SnapCgroupPolicyDir = filepath.Join(rootdir, snappyDir, "cgroup")
 	SnapdMaintenanceFile = filepath.Join(rootdir, snappyDir, "maintenance.json")
@@ -602,6 +607,7 @@ func SetRootDir(rootdir string) {
 	SnapSocket = filepath.Join(rootdir, "/run/snapd-snap.socket")
 
 	SnapAssertsDBDir = filepath.Join(rootdir, snappyDir, "assertions")
+	SnapAssertsLocalTrustDir = filepath.Join(rootdir, snappyDir, "assertions-local-trust")
 	SnapCookieDir = filepath.Join(rootdir, snappyDir, "cookie")
 	SnapAssertsSpoolDir = filepath.Join(rootdir, "run/snapd/auto-import")
 	SnapSeqDir = filepath.Join(rootdir, snappyDir, "sequence")
@@ -79,6 +79,9 @@ const (
 	ContentCompatLabel
 	// Clustering enables experimental clustering support.
 	Clustering
+	// SELinuxConfinement enables strict confinement of snaps with per-app
+	// SELinux policy modules on systems without AppArmor.
+	SELinuxConfinement
 	// lastFeature is the final known feature, it is only used for testing.
 	lastFeature
 )
@@ -129,6 +132,8 @@ var featureNames = map[SnapdFeature]string{
 	AppArmorPrompting:  "apparmor-prompting",
 	ContentCompatLabel: "content-compatibility-label",
 	Clustering:         "clustering",
+
+	SELinuxConfinement: "selinux-confinement",
 }
 
 // featuresEnabledWhenUnset contains a set of features that are enabled when not explicitly configured.
@@ -152,6 +157,8 @@ var featuresExported = map[SnapdFeature]bool{
 	RefreshAppAwarenessUX: true,
 	Confdb:                true,
 	AppArmorPrompting:     true,
+
+	SELinuxConfinement: true,
 }
 
 var (
@@ -66,6 +66,7 @@ func (*featureSuite) TestName(c *C) {
 	check(features.AppArmorPrompting, "apparmor-prompting")
 	check(features.ContentCompatLabel, "content-compatibility-label")
 	check(features.Clustering, "clustering")
+	check(features.SELinuxConfinement, "selinux-confinement")
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 	c.Check(func() { _ = features.SnapdFeature(1000).String() }, PanicMatches, "unknown feature flag code 1000")
@@ -108,6 +109,7 @@ func (*featureSuite) TestIsExported(c *C) {
 	check(features.AppArmorPrompting, true)
 	check(features.ContentCompatLabel, false)
 	check(features.Clustering, false)
+	check(features.SELinuxConfinement, true)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
@@ -235,6 +237,7 @@ func (*featureSuite) TestIsEnabledWhenUnset(c *C) {
 	check(features.ConfdbControl, false)
 	check(features.ContentCompatLabel, false)
 	check(features.Clustering, false)
+	check(features.SELinuxConfinement, false)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
@@ -248,6 +251,7 @@ func (*featureSuite) TestControlFile(c *C) {
 	c.Check(features.RefreshAppAwarenessUX.ControlFile(), Equals, "/var/lib/snapd/features/refresh-app-awareness-ux")
 	c.Check(features.Confdb.ControlFile(), Equals, "/var/lib/snapd/features/confdb")
 	c.Check(features.AppArmorPrompting.ControlFile(), Equals, "/var/lib/snapd/features/apparmor-prompting")
+	c.Check(features.SELinuxConfinement.ControlFile(), Equals, "/var/lib/snapd/features/selinux-confinement")
 	// Features that are not exported don't have a control file.
 	c.Check(features.Layouts.ControlFile, PanicMatches, `cannot compute the control file of feature "layouts" because that feature is not exported`)
 }
@@ -20,10 +20,12 @@
 package device
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/snapcore/snapd/dirs"
@@ -107,6 +109,80 @@ func PreinstallCheckResultUnder(deviceFDEDir string) string {
 	return filepath.Join(deviceFDEDir, "preinstall")
 }
 
+// KeyfileLocationUnder returns the path of the file recording where the
+// keyfile protecting the encryption keys is found.
+func KeyfileLocationUnder(deviceFDEDir string) string {
+	return filepath.Join(deviceFDEDir, "keyfile-location")
+}
+
+// DefaultKeyfilePath is the path of the keyfile on its filesystem when the
+// gadget does not specify one.
+const DefaultKeyfilePath = "ubuntu-fde.keyfile"
+
+// KeyfileLocation describes where the keyfile protecting the encryption keys
+// of devices without a TPM is found. The keyfile lives on a filesystem that is
+// not part of the encrypted system, either on a separate partition or on a
+// removable device.
+type KeyfileLocation struct {
+	// FilesystemLabel is the label of the filesystem carrying the keyfile.
+	FilesystemLabel string `yaml:"filesystem-label" json:"filesystem-label"`
+	// Path is the path of the keyfile relative to the root of the
+	// filesystem, DefaultKeyfilePath when empty.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// KeyfilePath returns the path of the keyfile relative to the root of its
+// filesystem.
+func (l *KeyfileLocation) KeyfilePath() string {
+	if l.Path == "" {
+		return DefaultKeyfilePath
+	}
+	return l.Path
+}
+
+// Validate checks that the keyfile location is well formed.
+func (l *KeyfileLocation) Validate() error {
+	if l.FilesystemLabel == "" {
+		return fmt.Errorf("keyfile filesystem label cannot be empty")
+	}
+	if strings.HasPrefix(l.FilesystemLabel, "ubuntu-") {
+		return fmt.Errorf("keyfile cannot be on filesystem %q reserved for the system", l.FilesystemLabel)
+	}
+	if l.Path != "" {
+		if filepath.IsAbs(l.Path) || filepath.Clean(l.Path) != l.Path || strings.HasPrefix(l.Path, "../") || l.Path == ".." {
+			return fmt.Errorf("keyfile path %q must be a clean path relative to the root of its filesystem", l.Path)
+		}
+	}
+	return nil
+}
+
+// ReadKeyfileLocation reads the keyfile location recorded under the given
+// directory.
+func ReadKeyfileLocation(deviceFDEDir string) (*KeyfileLocation, error) {
+	content, err := os.ReadFile(KeyfileLocationUnder(deviceFDEDir))
+	if err != nil {
+		return nil, err
+	}
+	var loc KeyfileLocation
+	if err := json.Unmarshal(content, &loc); err != nil {
+		return nil, fmt.Errorf("cannot decode keyfile location: %v", err)
+	}
+	return &loc, nil
+}
+
+// WriteKeyfileLocation records the keyfile location under the given
+// directory.
+func WriteKeyfileLocation(deviceFDEDir string, loc *KeyfileLocation) error {
+	content, err := json.Marshal(loc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(deviceFDEDir, 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(KeyfileLocationUnder(deviceFDEDir), content, 0644, 0)
+}
+
 // ErrNoSealedKeys error if there are no sealed keys
 var ErrNoSealedKeys = errors.New("no sealed keys")
 
@@ -270,3 +270,47 @@ func (s *deviceSuite) TestCheckAuthQualityCalculationError(c *C) {
 	c.Assert(err, ErrorMatches, "boom!")
 	c.Check(result, Equals, device.AuthQuality{})
 }
+
+func (s *deviceSuite) TestKeyfileLocationValidate(c *C) {
+	for _, tc := range []struct {
+		loc device.KeyfileLocation
+		err string
+	}{
+		{device.KeyfileLocation{FilesystemLabel: "fde-key"}, ""},
+		{device.KeyfileLocation{FilesystemLabel: "fde-key", Path: "keys/device.key"}, ""},
+		{device.KeyfileLocation{}, "keyfile filesystem label cannot be empty"},
+		{device.KeyfileLocation{FilesystemLabel: "ubuntu-seed"}, `keyfile cannot be on filesystem "ubuntu-seed" reserved for the system`},
+		{device.KeyfileLocation{FilesystemLabel: "fde-key", Path: "/device.key"}, `keyfile path "/device.key" must be a clean path relative to the root of its filesystem`},
+		{device.KeyfileLocation{FilesystemLabel: "fde-key", Path: "../device.key"}, `keyfile path "../device.key" must be a clean path relative to the root of its filesystem`},
+		{device.KeyfileLocation{FilesystemLabel: "fde-key", Path: "keys//device.key"}, `keyfile path "keys//device.key" must be a clean path relative to the root of its filesystem`},
+	} {
+		err := tc.loc.Validate()
+		if tc.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, tc.err)
+		}
+	}
+}
+
+func (s *deviceSuite) TestKeyfileLocationPath(c *C) {
+	loc := device.KeyfileLocation{FilesystemLabel: "fde-key"}
+	c.Check(loc.KeyfilePath(), Equals, "ubuntu-fde.keyfile")
+	loc.Path = "keys/device.key"
+	c.Check(loc.KeyfilePath(), Equals, "keys/device.key")
+}
+
+func (s *deviceSuite) TestKeyfileLocationRoundtrip(c *C) {
+	d := filepath.Join(c.MkDir(), "device/fde")
+
+	_, err := device.ReadKeyfileLocation(d)
+	c.Check(errors.Is(err, os.ErrNotExist), Equals, true)
+
+	loc := &device.KeyfileLocation{FilesystemLabel: "fde-key", Path: "device.key"}
+	c.Assert(device.WriteKeyfileLocation(d, loc), IsNil)
+	c.Check(device.KeyfileLocationUnder(d), testutil.FileEquals, `{"filesystem-label":"fde-key","path":"device.key"}`)
+
+	read, err := device.ReadKeyfileLocation(d)
+	c.Assert(err, IsNil)
+	c.Check(read, DeepEquals, loc)
+}
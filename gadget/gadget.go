@@ -67,6 +67,10 @@ const (
 	SystemSeedNull = "system-seed-null"
 	SystemSave     = "system-save"
 
+	// SlotA and SlotB are the slots of the structures of A/B pairs
+	SlotA = "a"
+	SlotB = "b"
+
 	// extracted kernels for all uc systems
 	bootImage = "system-boot-image"
 
@@ -135,6 +139,20 @@ type Info struct {
 	Connections []Connection `yaml:"connections"`
 
 	KernelCmdline KernelCmdline `yaml:"kernel-cmdline"`
+
+	FactoryReset FactoryReset `yaml:"factory-reset,omitempty"`
+
+	// FDEKeyfile is the location of the keyfile protecting the
+	// encryption keys on devices without a TPM.
+	FDEKeyfile *device.KeyfileLocation `yaml:"fde-keyfile,omitempty"`
+}
+
+// FactoryReset describes how the device behaves when it is factory reset.
+type FactoryReset struct {
+	// PreserveData is the list of instance names of the snaps whose
+	// system data is saved to ubuntu-save before a factory reset and
+	// restored on the first boot after it.
+	PreserveData []string `yaml:"preserve-data,omitempty"`
 }
 
 // HasRole returns true if any of the volume structures in this Info has the
@@ -143,6 +161,17 @@ func (i *Info) HasRole(role string) bool {
 	return VolumesHaveRole(i.Volumes, role)
 }
 
+// HasSlots returns true if any of the volumes in this Info has structures
+// in A/B slots.
+func (i *Info) HasSlots() bool {
+	for _, v := range i.Volumes {
+		if volumeHasSlots(v) {
+			return true
+		}
+	}
+	return false
+}
+
 // PartialProperty is a gadget property that can be partially defined.
 type PartialProperty string
 
@@ -338,6 +367,11 @@ type VolumeStructure struct {
 	// 'system-boot-select' or 'system-recovery-select'. Structures of type 'mbr', must have a
 	// size of 446 bytes and must start at 0 offset.
 	Role string `yaml:"role" json:"role"`
+	// Slot, when set to "a" or "b", marks the structure as one copy of an
+	// A/B pair made of the structures named <name>-a and <name>-b. Gadget
+	// updates are only ever written to the copy in the inactive slot.
+	// Structures with a system role cannot be in a slot.
+	Slot string `yaml:"slot,omitempty" json:"slot,omitempty"`
 	// ID is the GPT partition ID, this should always be made upper case for
 	// comparison purposes.
 	ID string `yaml:"id" json:"id"`
@@ -1198,6 +1232,23 @@ func InfoFromGadgetYaml(gadgetYaml []byte, model Model) (*Info, error) {
 		}
 	}
 
+	seenPreserved := make(map[string]bool, len(gi.FactoryReset.PreserveData))
+	for _, name := range gi.FactoryReset.PreserveData {
+		if err := naming.ValidateInstance(name); err != nil {
+			return nil, fmt.Errorf("invalid factory-reset preserve-data snap: %v", err)
+		}
+		if seenPreserved[name] {
+			return nil, fmt.Errorf("factory-reset preserve-data snap %q listed more than once", name)
+		}
+		seenPreserved[name] = true
+	}
+
+	if gi.FDEKeyfile != nil {
+		if err := gi.FDEKeyfile.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid fde-keyfile: %v", err)
+		}
+	}
+
 	if len(gi.Volumes) == 0 && classicOrUndetermined(model) {
 		// volumes can be left out on classic
 		// can still specify defaults though
@@ -1233,19 +1284,22 @@ func InfoFromGadgetYaml(gadgetYaml []byte, model Model) (*Info, error) {
 		if err := validateVolume(v); err != nil {
 			return nil, fmt.Errorf("invalid volume %q: %v", name, err)
 		}
+		if whichVolRuleset(model) == volRuleset16 && volumeHasSlots(v) {
+			return nil, fmt.Errorf("invalid volume %q: A/B slot structures valid only for UC20 onwards", name)
+		}
 
 		switch v.Bootloader {
 		case "":
 			// pass
 		case "grub", "u-boot", "android-boot", "lk":
 			bootloadersFound += 1
-		case "piboot":
+		case "piboot", "systemd-boot":
 			if !compatWithPibootOrIndeterminate(model) {
-				return nil, errors.New("piboot bootloader valid only for UC20 onwards")
+				return nil, fmt.Errorf("%s bootloader valid only for UC20 onwards", v.Bootloader)
 			}
 			bootloadersFound += 1
 		default:
-			return nil, errors.New("bootloader must be one of grub, u-boot, android-boot, piboot or lk")
+			return nil, errors.New("bootloader must be one of grub, u-boot, android-boot, piboot, systemd-boot or lk")
 		}
 	}
 	switch {
@@ -1559,9 +1613,58 @@ func validateVolume(vol *Volume) error {
 		}
 	}
 
+	if err := validateVolumeSlots(vol); err != nil {
+		return err
+	}
+
 	return validateCrossVolumeStructure(vol)
 }
 
+// OtherSlot returns the slot paired with the given A/B slot.
+func OtherSlot(slot string) string {
+	if slot == SlotB {
+		return SlotA
+	}
+	return SlotB
+}
+
+func volumeHasSlots(vol *Volume) bool {
+	for i := range vol.Structure {
+		if vol.Structure[i].Slot != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateVolumeSlots checks that each structure in an A/B slot has a
+// matching counterpart in the other slot.
+func validateVolumeSlots(vol *Volume) error {
+	byName := make(map[string]*VolumeStructure, len(vol.Structure))
+	for i := range vol.Structure {
+		byName[vol.Structure[i].Name] = &vol.Structure[i]
+	}
+	for i := range vol.Structure {
+		vs := &vol.Structure[i]
+		if vs.Slot == "" {
+			continue
+		}
+		group := strings.TrimSuffix(vs.Name, "-"+vs.Slot)
+		if group == vs.Name || group == "" {
+			return fmt.Errorf("structure %q in slot %q must be named <name>-%s", vs.Name, vs.Slot, vs.Slot)
+		}
+		otherName := group + "-" + OtherSlot(vs.Slot)
+		other := byName[otherName]
+		if other == nil || other.Slot != OtherSlot(vs.Slot) {
+			return fmt.Errorf("structure %q in slot %q has no counterpart %q in slot %q", vs.Name, vs.Slot, otherName, OtherSlot(vs.Slot))
+		}
+		if vs.Type != other.Type || vs.Size != other.Size || vs.MinSize != other.MinSize || vs.Filesystem != other.Filesystem {
+			return fmt.Errorf("structures %q and %q of an A/B pair must have the same type, size and filesystem", vs.Name, otherName)
+		}
+	}
+	return nil
+}
+
 // isMBR returns whether the structure is the MBR and can be used before setImplicitForVolume
 func isMBR(vs *VolumeStructure) bool {
 	if vs.Role == schemaMBR {
@@ -1682,6 +1785,9 @@ func validateEMMCVolumeStructure(vs *VolumeStructure) error {
 	if vs.Role != "" {
 		return fielderr("role")
 	}
+	if vs.Slot != "" {
+		return fielderr("slot")
+	}
 
 	for i, c := range vs.Content {
 		if err := validateEMMCContent(&c); err != nil {
@@ -1719,6 +1825,9 @@ func validateVolumeStructure(vs *VolumeStructure, vol *Volume) error {
 	if vs.Filesystem != "" && !strutil.ListContains([]string{"ext4", "vfat", "vfat-16", "vfat-32", "none"}, vs.Filesystem) {
 		return fmt.Errorf("invalid filesystem %q", vs.Filesystem)
 	}
+	if err := validateSlot(vs); err != nil {
+		return fmt.Errorf("invalid slot %q: %v", vs.Slot, err)
+	}
 
 	contentChecker := contentCheckerCreate(vs, vol)
 	for i, c := range vs.Content {
@@ -1897,6 +2006,29 @@ func validateFilesystemContent(vc *VolumeContent) error {
 	return nil
 }
 
+func validateSlot(vs *VolumeStructure) error {
+	switch vs.Slot {
+	case "":
+		return nil
+	case SlotA, SlotB:
+	default:
+		return fmt.Errorf("must be one of %q or %q", SlotA, SlotB)
+	}
+	if vs.Name == "" {
+		return errors.New("structures in a slot must be named")
+	}
+	if isMBR(vs) {
+		return errors.New("cannot be set for the MBR")
+	}
+	switch vs.Role {
+	case SystemBoot, SystemData, SystemSeed, SystemSeedNull, SystemSave:
+		// the system structures are found by role when installing
+		// and in the initramfs, there can be only one of each
+		return fmt.Errorf("cannot be set for structures with role %q", vs.Role)
+	}
+	return nil
+}
+
 func validateStructureUpdate(vs *VolumeStructure) error {
 	if !vs.HasFilesystem() && len(vs.Update.Preserve) > 0 {
 		return errors.New("preserving files during update is not supported for non-filesystem structures")
@@ -953,7 +953,25 @@ volumes:
 	c.Assert(err, IsNil)
 
 	_, err = gadget.ReadInfo(s.dir, nil)
-	c.Assert(err, ErrorMatches, "bootloader must be one of grub, u-boot, android-boot, piboot or lk")
+	c.Assert(err, ErrorMatches, "bootloader must be one of grub, u-boot, android-boot, piboot, systemd-boot or lk")
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlSystemdBoot(c *C) {
+	mockGadgetYaml := []byte(`
+volumes:
+ name:
+  bootloader: systemd-boot
+`)
+
+	err := os.WriteFile(s.gadgetYamlPath, mockGadgetYaml, 0644)
+	c.Assert(err, IsNil)
+
+	ginfo, err := gadget.ReadInfo(s.dir, uc20Mod)
+	c.Assert(err, IsNil)
+	c.Check(ginfo.Volumes["name"].Bootloader, Equals, "systemd-boot")
+
+	_, err = gadget.ReadInfo(s.dir, coreMod)
+	c.Assert(err, ErrorMatches, "systemd-boot bootloader valid only for UC20 onwards")
 }
 
 func (s *gadgetYamlTestSuite) TestReadGadgetYamlEmptyBootloader(c *C) {
@@ -1585,6 +1603,116 @@ volumes:
 	}
 }
 
+func (s *gadgetYamlTestSuite) TestGadgetSlots(c *C) {
+	yaml := `
+volumes:
+   pc:
+     bootloader: grub
+     structure:
+       - name: firmware-a
+         slot: a
+         type: DA,21686148-6449-6E6F-744E-656564454649
+         size: 1M
+       - name: firmware-b
+         slot: b
+         type: DA,21686148-6449-6E6F-744E-656564454649
+         size: 1M
+       - name: other
+         type: DA,21686148-6449-6E6F-744E-656564454649
+         size: 1M
+`
+	info, err := gadget.InfoFromGadgetYaml([]byte(yaml), uc20Mod)
+	c.Assert(err, IsNil)
+	vol := info.Volumes["pc"]
+	c.Check(vol.Structure[0].Slot, Equals, "a")
+	c.Check(vol.Structure[1].Slot, Equals, "b")
+	c.Check(vol.Structure[2].Slot, Equals, "")
+
+	_, err = gadget.InfoFromGadgetYaml([]byte(yaml), coreMod)
+	c.Assert(err, ErrorMatches, `invalid volume "pc": A/B slot structures valid only for UC20 onwards`)
+
+	c.Check(gadget.OtherSlot("a"), Equals, "b")
+	c.Check(gadget.OtherSlot("b"), Equals, "a")
+}
+
+func (s *gadgetYamlTestSuite) TestGadgetFactoryResetPreserveData(c *C) {
+	yaml := `
+factory-reset:
+  preserve-data:
+    - foo
+    - bar_instance
+`
+	info, err := gadget.InfoFromGadgetYaml([]byte(yaml), classicMod)
+	c.Assert(err, IsNil)
+	c.Check(info.FactoryReset.PreserveData, DeepEquals, []string{"foo", "bar_instance"})
+
+	for _, t := range []struct {
+		names string
+		err   string
+	}{
+		{"[Foo]", `invalid factory-reset preserve-data snap: invalid snap name: "Foo"`},
+		{"[foo, foo]", `factory-reset preserve-data snap "foo" listed more than once`},
+	} {
+		yaml := fmt.Sprintf("factory-reset:\n  preserve-data: %s\n", t.names)
+		_, err := gadget.InfoFromGadgetYaml([]byte(yaml), classicMod)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *gadgetYamlTestSuite) TestGadgetFDEKeyfile(c *C) {
+	yaml := `
+fde-keyfile:
+  filesystem-label: fde-key
+  path: keys/device.key
+`
+	info, err := gadget.InfoFromGadgetYaml([]byte(yaml), classicMod)
+	c.Assert(err, IsNil)
+	c.Check(info.FDEKeyfile, DeepEquals, &device.KeyfileLocation{
+		FilesystemLabel: "fde-key",
+		Path:            "keys/device.key",
+	})
+
+	info, err = gadget.InfoFromGadgetYaml([]byte("defaults: {}\n"), classicMod)
+	c.Assert(err, IsNil)
+	c.Check(info.FDEKeyfile, IsNil)
+
+	_, err = gadget.InfoFromGadgetYaml([]byte("fde-keyfile:\n  filesystem-label: ubuntu-boot\n"), classicMod)
+	c.Check(err, ErrorMatches, `invalid fde-keyfile: keyfile cannot be on filesystem "ubuntu-boot" reserved for the system`)
+}
+
+func (s *gadgetYamlTestSuite) TestGadgetSlotsErrors(c *C) {
+	yamlTemplate := `
+volumes:
+   pc:
+     bootloader: grub
+     structure:
+       - name: %s
+         slot: %s
+         type: DA,21686148-6449-6E6F-744E-656564454649
+         size: 1M
+%s
+       - name: firmware-b
+         slot: b
+         type: DA,21686148-6449-6E6F-744E-656564454649
+         size: 1M
+`
+	for _, tc := range []struct {
+		name, slot, extra string
+		err               string
+	}{
+		{"firmware-a", "c", "", `invalid volume "pc": invalid structure #0 \("firmware-a"\): invalid slot "c": must be one of "a" or "b"`},
+		{"firmware-a", "a", "         role: system-save\n         filesystem: ext4", `invalid volume "pc": invalid structure #0 \("firmware-a"\): invalid slot "a": cannot be set for structures with role "system-save"`},
+		{"firmware", "a", "", `invalid volume "pc": structure "firmware" in slot "a" must be named <name>-a`},
+		{"firmware-a", "b", "", `invalid volume "pc": structure "firmware-a" in slot "b" must be named <name>-b`},
+		{"firmwar-a", "a", "", `invalid volume "pc": structure "firmwar-a" in slot "a" has no counterpart "firmwar-b" in slot "b"`},
+		{"firmware-a", "a", "         filesystem: ext4", `invalid volume "pc": structures "firmware-a" and "firmware-b" of an A/B pair must have the same type, size and filesystem`},
+	} {
+		yaml := fmt.Sprintf(yamlTemplate, tc.name, tc.slot, tc.extra)
+		_, err := gadget.InfoFromGadgetYaml([]byte(yaml), uc20Mod)
+		c.Check(err, ErrorMatches, tc.err, Commentf("%+v", tc))
+	}
+}
+
 func (s *gadgetYamlTestSuite) TestGadgetDuplicateFsLabelWithCase(c *C) {
 	yamlTemplate := `
 volumes:
@@ -690,6 +690,10 @@ func EncryptPartitions(
 	kernelRoot string,
 	perfTimings timings.Measurer,
 ) (*EncryptionSetupData, error) {
+	if err := checkFDEKeyfile(volumesAuth, gadgetRoot, model); err != nil {
+		return nil, err
+	}
+
 	setupData := &EncryptionSetupData{
 		parts:                  make(map[string]partEncryptionData),
 		volumesAuth:            volumesAuth,
@@ -740,6 +744,22 @@ func EncryptPartitions(
 	return setupData, nil
 }
 
+// checkFDEKeyfile checks that the volumes authentication options can be used
+// with the keyfile declared by the gadget, if any.
+func checkFDEKeyfile(volumesAuth *device.VolumesAuthOptions, gadgetRoot string, model *asserts.Model) error {
+	if volumesAuth == nil || gadgetRoot == "" {
+		return nil
+	}
+	info, err := gadget.ReadInfo(gadgetRoot, model)
+	if err != nil {
+		return err
+	}
+	if info.FDEKeyfile != nil && volumesAuth.Mode != device.AuthModePassphrase {
+		return fmt.Errorf("cannot use %q authentication mode with a keyfile", volumesAuth.Mode)
+	}
+	return nil
+}
+
 func BootstrappedContainersForRole(setupData *EncryptionSetupData) map[string]secboot.BootstrappedContainer {
 	installKeyForRole := make(map[string]secboot.BootstrappedContainer)
 	for _, p := range setupData.parts {
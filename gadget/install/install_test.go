@@ -117,6 +117,59 @@ func (s *installSuite) TestVolumeAssignmentDeviceNameMissing(c *C) {
 	c.Assert(err, ErrorMatches, `device name "/dev/fakedevice1" not mocked`)
 }
 
+func (s *installSuite) TestInstallRunSlotsSystemRoles(c *C) {
+	uc20Mod := &gadgettest.ModelCharacteristics{
+		HasModes: true,
+	}
+
+	// the partitions of the system are found by role, so they cannot be
+	// part of an A/B pair
+	gadgetRoot, err := gadgettest.WriteGadgetYaml(c.MkDir(), `
+volumes:
+  pi:
+    bootloader: u-boot
+    schema: mbr
+    structure:
+    - filesystem: vfat
+      name: ubuntu-seed
+      role: system-seed
+      size: 1200M
+      type: 0C
+    - filesystem: vfat
+      name: ubuntu-boot-a
+      slot: a
+      filesystem-label: ubuntu-boot-a
+      role: system-boot
+      size: 750M
+      type: 0C
+    - filesystem: vfat
+      name: ubuntu-boot-b
+      slot: b
+      filesystem-label: ubuntu-boot-b
+      role: system-boot
+      size: 750M
+      type: 0C
+    - filesystem: ext4
+      name: ubuntu-save
+      role: system-save
+      size: 16M
+      type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+    - filesystem: ext4
+      name: ubuntu-data
+      role: system-data
+      size: 1500M
+      type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+`)
+	c.Assert(err, IsNil)
+
+	mockSfdisk := testutil.MockCommand(c, "sfdisk", "")
+	defer mockSfdisk.Restore()
+
+	_, err = install.Run(uc20Mod, gadgetRoot, &install.KernelSnapInfo{}, "", install.Options{}, nil, timings.New(nil))
+	c.Assert(err, ErrorMatches, `invalid volume "pi": invalid structure #1 \("ubuntu-boot-a"\): invalid slot "a": cannot be set for structures with role "system-boot"`)
+	c.Check(mockSfdisk.Calls(), HasLen, 0)
+}
+
 func (s *installSuite) TestInstallSeedDiskDoesNotMatchAssignedDisk(c *C) {
 	uc20Mod := &gadgettest.ModelCharacteristics{
 		HasModes: true,
@@ -1497,6 +1550,8 @@ func (s *installSuite) TestInstallWriteContentDeviceNotFound(c *C) {
 type encryptPartitionsOpts struct {
 	encryptType device.EncryptionType
 	volumesAuth *device.VolumesAuthOptions
+	keyfile     bool
+	expectedErr string
 }
 
 func (s *installSuite) testEncryptPartitions(c *C, opts encryptPartitionsOpts) {
@@ -1507,8 +1562,12 @@ func (s *installSuite) testEncryptPartitions(c *C, opts encryptPartitionsOpts) {
 	})
 	defer restore()
 
+	gadgetYaml := gadgettest.SingleVolumeClassicWithModesGadgetYaml
+	if opts.keyfile {
+		gadgetYaml += "fde-keyfile:\n  filesystem-label: fde-key\n"
+	}
 	gadgetRoot := filepath.Join(c.MkDir(), "gadget")
-	ginfo, _, model, restore, err := gadgettest.MockGadgetPartitionedDisk(gadgettest.SingleVolumeClassicWithModesGadgetYaml, gadgetRoot)
+	ginfo, _, model, restore, err := gadgettest.MockGadgetPartitionedDisk(gadgetYaml, gadgetRoot)
 	c.Assert(err, IsNil)
 	defer restore()
 
@@ -1540,6 +1599,11 @@ func (s *installSuite) testEncryptPartitions(c *C, opts encryptPartitionsOpts) {
 	checkContext := &secboot.PreinstallCheckContext{}
 
 	encryptSetup, err := install.EncryptPartitions(ginfo.Volumes, opts.volumesAuth, opts.encryptType, checkContext, model, gadgetRoot, "", timings.New(nil))
+	if opts.expectedErr != "" {
+		c.Check(err, ErrorMatches, opts.expectedErr)
+		c.Check(encryptSetup, IsNil)
+		return
+	}
 	c.Assert(err, IsNil)
 	c.Assert(encryptSetup, NotNil)
 	c.Assert(encryptSetup.VolumesAuth(), Equals, opts.volumesAuth)
@@ -1564,6 +1628,23 @@ func (s *installSuite) TestInstallEncryptPartitions(c *C) {
 	})
 }
 
+func (s *installSuite) TestInstallEncryptPartitionsKeyfileWithPassphrase(c *C) {
+	s.testEncryptPartitions(c, encryptPartitionsOpts{
+		encryptType: device.EncryptionTypeLUKS,
+		volumesAuth: &device.VolumesAuthOptions{Mode: device.AuthModePassphrase, Passphrase: "test"},
+		keyfile:     true,
+	})
+}
+
+func (s *installSuite) TestInstallEncryptPartitionsKeyfileWithPIN(c *C) {
+	s.testEncryptPartitions(c, encryptPartitionsOpts{
+		encryptType: device.EncryptionTypeLUKS,
+		volumesAuth: &device.VolumesAuthOptions{Mode: device.AuthModePIN, PIN: "1234"},
+		keyfile:     true,
+		expectedErr: `cannot use "pin" authentication mode with a keyfile`,
+	})
+}
+
 func (s *installSuite) TestInstallEncryptPartitionsNoDeviceSet(c *C) {
 	vdaSysPath := "/sys/devices/pci0000:00/0000:00:03.0/virtio1/block/vda"
 	restore := gadget.MockSysfsPathForBlockDevice(func(device string) (string, error) {
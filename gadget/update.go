@@ -1699,6 +1699,31 @@ func KernelUpdatePolicy(from, to *LaidOutStructure) (bool, ResolvedContentFilter
 	return false, nil
 }
 
+// SlotUpdatePolicy wraps an update policy so that structures of A/B pairs
+// are updated only in the inactive slot, the copy in the active slot being
+// what the device currently boots from. The given policy, or the default
+// edition based one when nil, decides for all other structures. slotUpdated,
+// when set, is called whenever a structure of the inactive slot is selected
+// for the update.
+func SlotUpdatePolicy(activeSlot string, policy UpdatePolicyFunc, slotUpdated func()) UpdatePolicyFunc {
+	if policy == nil {
+		policy = defaultPolicy
+	}
+	return func(from, to *LaidOutStructure) (bool, ResolvedContentFilterFunc) {
+		switch to.VolumeStructure.Slot {
+		case "":
+			return policy(from, to)
+		case activeSlot:
+			return false, nil
+		}
+		update, filter := policy(from, to)
+		if update && slotUpdated != nil {
+			slotUpdated()
+		}
+		return update, filter
+	}
+}
+
 func resolveUpdate(oldVol *PartiallyLaidOutVolume, newVol *LaidOutVolume, policy UpdatePolicyFunc, newGadgetRootDir, newKernelRootDir string, kernelInfo *kernel.Info) (updates []updatePair, err error) {
 	if len(oldVol.LaidOutStructure) != len(newVol.LaidOutStructure) {
 		return nil, errors.New("internal error: the number of structures in new and old volume definitions is different")
@@ -31,6 +31,7 @@ import (
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/edition"
 	"github.com/snapcore/snapd/gadget/gadgettest"
 	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/logger"
@@ -4260,6 +4261,53 @@ func (u *updateTestSuite) TestKernelUpdatePolicyFunc(c *C) {
 	c.Check(filter(&to.ResolvedContent[1]), Equals, true)
 }
 
+func (u *updateTestSuite) TestSlotUpdatePolicy(c *C) {
+	slotUpdated := 0
+	policy := gadget.SlotUpdatePolicy("a", nil, func() { slotUpdated++ })
+
+	for _, tc := range []struct {
+		slot             string
+		fromEd, toEd     edition.Number
+		update           bool
+		slotUpdatedCalls int
+	}{
+		// not in a slot, default policy
+		{slot: "", fromEd: 1, toEd: 2, update: true},
+		{slot: "", fromEd: 1, toEd: 1, update: false},
+		// the active slot is never touched
+		{slot: "a", fromEd: 1, toEd: 2, update: false},
+		// the inactive slot follows the policy
+		{slot: "b", fromEd: 1, toEd: 1, update: false},
+		{slot: "b", fromEd: 1, toEd: 2, update: true, slotUpdatedCalls: 1},
+	} {
+		slotUpdated = 0
+		from := &gadget.LaidOutStructure{
+			VolumeStructure: &gadget.VolumeStructure{Slot: tc.slot, Update: gadget.VolumeUpdate{Edition: tc.fromEd}},
+		}
+		to := &gadget.LaidOutStructure{
+			VolumeStructure: &gadget.VolumeStructure{Slot: tc.slot, Update: gadget.VolumeUpdate{Edition: tc.toEd}},
+		}
+		update, filter := policy(from, to)
+		c.Check(update, Equals, tc.update, Commentf("%+v", tc))
+		c.Check(filter, IsNil)
+		c.Check(slotUpdated, Equals, tc.slotUpdatedCalls, Commentf("%+v", tc))
+	}
+}
+
+func (u *updateTestSuite) TestSlotUpdatePolicyWrapsPolicy(c *C) {
+	policy := gadget.SlotUpdatePolicy("b", gadget.RemodelUpdatePolicy, nil)
+
+	from := &gadget.LaidOutStructure{VolumeStructure: &gadget.VolumeStructure{Slot: "a"}}
+	to := &gadget.LaidOutStructure{VolumeStructure: &gadget.VolumeStructure{Slot: "a"}}
+	update, _ := policy(from, to)
+	c.Check(update, Equals, true)
+
+	from.VolumeStructure.Slot = "b"
+	to.VolumeStructure.Slot = "b"
+	update, _ = policy(from, to)
+	c.Check(update, Equals, false)
+}
+
 func (u *updateTestSuite) TestUpdateApplyUpdatesWithKernelPolicy(c *C) {
 	// prepare the stage
 	fsStruct := gadget.VolumeStructure{
@@ -0,0 +1,246 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package image
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/mkfs"
+)
+
+// diskImageSectorSize is the sector size assumed for the created disk
+// images.
+const diskImageSectorSize = quantity.Size(512)
+
+// space reserved at the end of GPT images for the backup partition table,
+// the image size is rounded up to a MiB anyway
+const gptBackupSize = quantity.Size(33 * 512)
+
+var mkfsMakeWithContent = mkfs.MakeWithContent
+
+// diskImageParams describes where to find the inputs needed to write the
+// disk images of a gadget.
+type diskImageParams struct {
+	// ImageDir is the directory the <volume-name>.img files are written to.
+	ImageDir string
+	// ContentDir is the directory holding the resolved filesystem content
+	// for each structure, see writeResolvedContent.
+	ContentDir string
+
+	GadgetRootDir string
+	KernelRootDir string
+
+	// SkipCreatableAtInstall is set when the structures that the install
+	// process creates (ubuntu-boot, ubuntu-save and ubuntu-data) must not
+	// be part of the images.
+	SkipCreatableAtInstall bool
+}
+
+// writeDiskImages writes a raw disk image for each non-eMMC volume of the
+// gadget, with the partition table, the filesystems and their content and
+// the raw content of bare structures.
+func writeDiskImages(info *gadget.Info, params *diskImageParams) error {
+	if err := os.MkdirAll(params.ImageDir, 0755); err != nil {
+		return err
+	}
+
+	volNames := make([]string, 0, len(info.Volumes))
+	for volName := range info.Volumes {
+		volNames = append(volNames, volName)
+	}
+	sort.Strings(volNames)
+
+	for _, volName := range volNames {
+		vol := info.Volumes[volName]
+		if vol.Schema == "emmc" {
+			// eMMC volumes are hardware partitions that are not
+			// created, there is no disk image for them
+			fmt.Fprintf(Stderr, "WARNING: skipping disk image for eMMC volume %q\n", volName)
+			continue
+		}
+		if err := writeDiskImage(volName, vol, params); err != nil {
+			return fmt.Errorf("cannot write disk image for volume %q: %v", volName, err)
+		}
+	}
+	return nil
+}
+
+func writeDiskImage(volName string, vol *gadget.Volume, params *diskImageParams) error {
+	opts := &gadget.LayoutOptions{
+		GadgetRootDir: params.GadgetRootDir,
+		KernelRootDir: params.KernelRootDir,
+	}
+	lv, err := gadget.LayoutVolume(vol, gadget.OnDiskStructsFromGadget(vol), opts)
+	if err != nil {
+		return err
+	}
+
+	// keep the structures in the image and their index in the volume, as
+	// the resolved content is stored per index
+	var included []int
+	end := quantity.Offset(0)
+	for i := range lv.LaidOutStructure {
+		ps := &lv.LaidOutStructure[i]
+		if params.SkipCreatableAtInstall && gadget.IsCreatableAtInstall(ps.VolumeStructure) {
+			continue
+		}
+		included = append(included, i)
+		if structEnd := ps.StartOffset + quantity.Offset(ps.Size); structEnd > end {
+			end = structEnd
+		}
+	}
+	size := quantity.Size(end)
+	if vol.Schema == "gpt" {
+		size += gptBackupSize
+	}
+	size = roundUpToMiB(size)
+
+	imgPath := filepath.Join(params.ImageDir, volName+".img")
+	fmt.Fprintf(Stdout, "Writing disk image %q (%s)\n", imgPath, size.IECString())
+	img, err := os.Create(imgPath)
+	if err != nil {
+		return err
+	}
+	defer img.Close()
+	if err := img.Truncate(int64(size)); err != nil {
+		return err
+	}
+
+	if err := writePartitionTable(imgPath, vol.Schema, lv, included); err != nil {
+		return err
+	}
+
+	for _, i := range included {
+		ps := &lv.LaidOutStructure[i]
+		if !ps.HasFilesystem() {
+			rw, err := gadget.NewRawStructureWriter(params.GadgetRootDir, ps)
+			if err != nil {
+				return err
+			}
+			// offsets of raw content are absolute within the volume
+			if err := rw.Write(img); err != nil {
+				return err
+			}
+			continue
+		}
+		contentDir := filepath.Join(params.ContentDir, volName, fmt.Sprintf("part%d", i))
+		if err := writeFilesystemImage(img, ps, contentDir); err != nil {
+			return fmt.Errorf("cannot create filesystem for %s: %v", ps, err)
+		}
+	}
+	return img.Sync()
+}
+
+func roundUpToMiB(size quantity.Size) quantity.Size {
+	return (size + quantity.SizeMiB - 1) / quantity.SizeMiB * quantity.SizeMiB
+}
+
+// partitionTableType returns the type to use for a partition, which is
+// either the MBR or the GPT type of hybrid partition types.
+func partitionTableType(schema, ptype string) string {
+	t := strings.Split(ptype, ",")
+	if len(t) == 2 && schema == "gpt" {
+		return t[1]
+	}
+	return t[0]
+}
+
+// writePartitionTable creates the partitions of the included structures in
+// the image with sfdisk.
+func writePartitionTable(imgPath, schema string, lv *gadget.LaidOutVolume, included []int) error {
+	label := "gpt"
+	if schema == "mbr" {
+		label = "dos"
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "label: %s\n", label)
+	partitions := 0
+	for _, i := range included {
+		ps := &lv.LaidOutStructure[i]
+		if !ps.IsPartition() {
+			continue
+		}
+		partitions++
+		fmt.Fprintf(buf, "start=%d, size=%d, type=%s", uint64(ps.StartOffset)/uint64(diskImageSectorSize),
+			uint64(ps.Size)/uint64(diskImageSectorSize), partitionTableType(schema, ps.Type()))
+		if label == "gpt" {
+			fmt.Fprintf(buf, ", name=%q", ps.Name())
+		}
+		fmt.Fprintf(buf, "\n")
+	}
+	if partitions == 0 {
+		return nil
+	}
+
+	cmd := exec.Command("sfdisk", "--no-reread", "--no-tell-kernel", imgPath)
+	cmd.Stdin = buf
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cannot create partition table: %v", osutil.OutputErr(output, err))
+	}
+	return nil
+}
+
+// writeFilesystemImage creates the filesystem of a structure populated with
+// the content of the given directory, and copies it into the disk image at
+// the structure offset.
+func writeFilesystemImage(img *os.File, ps *gadget.LaidOutStructure, contentDir string) error {
+	if resolved, err := filepath.EvalSymlinks(contentDir); err == nil {
+		// the system-seed content is a link to the seed
+		contentDir = resolved
+	} else if os.IsNotExist(err) {
+		// a structure without content gets an empty filesystem
+		contentDir = ""
+	} else {
+		return err
+	}
+
+	fsImg, err := os.CreateTemp(filepath.Dir(img.Name()), ".part-*.img")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fsImg.Name())
+	defer fsImg.Close()
+	if err := fsImg.Truncate(int64(ps.Size)); err != nil {
+		return err
+	}
+
+	if err := mkfsMakeWithContent(ps.Filesystem(), fsImg.Name(), ps.Label(), contentDir, ps.Size, diskImageSectorSize); err != nil {
+		return err
+	}
+
+	if _, err := img.Seek(int64(ps.StartOffset), io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(img, io.LimitReader(fsImg, int64(ps.Size))); err != nil {
+		return err
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ package image
 import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/image/preseed"
 	"github.com/snapcore/snapd/store/tooling"
 	"github.com/snapcore/snapd/testutil"
@@ -65,3 +66,9 @@ func MockSetupSeed(f func(tsto *tooling.ToolingStore, model *asserts.Model, opts
 	setupSeed = f
 	return r
 }
+
+func MockMkfsMakeWithContent(f func(typ, img, label, contentRootDir string, deviceSize, sectorSize quantity.Size) error) (restore func()) {
+	r := testutil.Backup(&mkfsMakeWithContent)
+	mkfsMakeWithContent = f
+	return r
+}
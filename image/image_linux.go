@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -138,6 +139,15 @@ func Prepare(opts *Options) error {
 		}
 	}
 
+	if opts.DiskImageDir != "" {
+		if !model.Classic() && model.Grade() == asserts.ModelGradeUnset {
+			return fmt.Errorf("cannot create disk images for a core model older than UC20")
+		}
+		if model.Gadget() == "" {
+			return fmt.Errorf("cannot create disk images for a model without a gadget")
+		}
+	}
+
 	tsto, err := newToolingStoreFromModel(model, opts.Architecture)
 	if err != nil {
 		return err
@@ -193,12 +203,38 @@ func Prepare(opts *Options) error {
 			AppArmorKernelFeaturesDir: opts.AppArmorKernelFeaturesDir,
 			SysfsOverlay:              opts.SysfsOverlay,
 		}
-		return preseedCore20(coreOpts)
+		if err := preseedCore20(coreOpts); err != nil {
+			return err
+		}
+	}
+
+	// disk images for classic models are written when finishing the
+	// seed, for core models they need to include the preseeded seed
+	if opts.DiskImageDir != "" && !model.Classic() {
+		return writeCoreDiskImages(model, opts)
 	}
 
 	return nil
 }
 
+// writeCoreDiskImages writes the disk images of a UC20+ model from the
+// gadget, kernel and resolved content left in the prepare directory. The
+// structures created at install are not part of the images.
+func writeCoreDiskImages(model *asserts.Model, opts *Options) error {
+	gadgetUnpackDir := filepath.Join(opts.PrepareDir, "gadget")
+	gadgetInfo, err := gadget.ReadInfoAndValidate(gadgetUnpackDir, model, nil)
+	if err != nil {
+		return err
+	}
+	return writeDiskImages(gadgetInfo, &diskImageParams{
+		ImageDir:               opts.DiskImageDir,
+		ContentDir:             filepath.Join(opts.PrepareDir, "resolved-content"),
+		GadgetRootDir:          gadgetUnpackDir,
+		KernelRootDir:          filepath.Join(opts.PrepareDir, "kernel"),
+		SkipCreatableAtInstall: true,
+	})
+}
+
 // these are postponed, not implemented or abandoned, not finalized,
 // don't let them sneak in into a used model assertion
 var reserved = []string{"core", "os", "class", "allowed-modes"}
@@ -307,6 +343,7 @@ type imageSeeder struct {
 	customizations           *Customizations
 	architecture             string
 	allowSnapdKernelMismatch bool
+	diskImageDir             string
 
 	hasModes    bool
 	rootDir     string
@@ -316,6 +353,9 @@ type imageSeeder struct {
 	db          *asserts.Database
 	w           *seedwriter.Writer
 	f           seedwriter.SeedAssertionFetcher
+
+	// seededSnaps maps snap names to their path in the seed
+	seededSnaps map[string]string
 }
 
 func newImageSeeder(tsto *tooling.ToolingStore, model *asserts.Model, opts *Options) (*imageSeeder, error) {
@@ -334,10 +374,13 @@ func newImageSeeder(tsto *tooling.ToolingStore, model *asserts.Model, opts *Opti
 		customizations:           &opts.Customizations,
 		architecture:             determineImageArchitecture(model, opts),
 		allowSnapdKernelMismatch: opts.AllowSnapdKernelMismatch,
+		diskImageDir:             opts.DiskImageDir,
 
 		hasModes: model.Grade() != asserts.ModelGradeUnset,
 		model:    model,
 		tsto:     tsto,
+
+		seededSnaps: make(map[string]string),
 	}
 
 	if os.Getenv("SNAPD_ALLOW_SNAPD_KERNEL_MISMATCH") == "true" {
@@ -724,6 +767,8 @@ func (s *imageSeeder) downloadAllSnaps(localSnaps localSnapRefs, fetchAsserts se
 			if err := s.w.SetRedirectChannel(sn, dlsn.RedirectChannel); err != nil {
 				return err
 			}
+			// downloaded snaps are fetched directly into the seed
+			s.seededSnaps[sn.SnapName()] = sn.Path
 
 			curSnaps = append(curSnaps, &tooling.CurrentSnap{
 				SnapName: sn.Info.SnapName(),
@@ -762,10 +807,59 @@ func (s *imageSeeder) finishSeedClassic() error {
 			fmt.Fprintf(Stderr, "WARNING: ensure that the contents under %s are owned by root:root in the (final) image\n", s.seedDir)
 		}
 	}
+	if s.diskImageDir != "" {
+		return s.writeClassicDiskImages()
+	}
 	// done already
 	return nil
 }
 
+// writeClassicDiskImages writes the disk images of a classic model. The
+// gadget and kernel are unpacked in a temporary directory as the prepare
+// directory is the root filesystem for classic models without modes. The
+// system-data filesystem is left empty, for the image builder to populate
+// with the root filesystem.
+func (s *imageSeeder) writeClassicDiskImages() error {
+	workDir, err := os.MkdirTemp("", "snap-prepare-image-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	gadgetUnpackDir := filepath.Join(workDir, "gadget")
+	if err := unpackSnap(s.seededSnaps[s.model.Gadget()], gadgetUnpackDir); err != nil {
+		return err
+	}
+	kernelUnpackDir := filepath.Join(workDir, "kernel")
+	if kernelPath := s.seededSnaps[s.model.Kernel()]; s.model.Kernel() != "" && kernelPath != "" {
+		if err := unpackSnap(kernelPath, kernelUnpackDir); err != nil {
+			return err
+		}
+	}
+
+	gadgetInfo, err := gadget.ReadInfoAndValidate(gadgetUnpackDir, s.model, nil)
+	if err != nil {
+		return err
+	}
+	if s.hasModes {
+		// the gadget content of system-seed is written along with a copy
+		// of the seed, the seed in the root filesystem is left untouched
+		if output, err := exec.Command("cp", "-a", s.seedDir, filepath.Join(workDir, "system-seed")).CombinedOutput(); err != nil {
+			return fmt.Errorf("cannot copy the seed: %v", osutil.OutputErr(output, err))
+		}
+	}
+	if err := writeResolvedContent(workDir, gadgetInfo, gadgetUnpackDir, kernelUnpackDir); err != nil {
+		return err
+	}
+
+	return writeDiskImages(gadgetInfo, &diskImageParams{
+		ImageDir:      s.diskImageDir,
+		ContentDir:    filepath.Join(workDir, "resolved-content"),
+		GadgetRootDir: gadgetUnpackDir,
+		KernelRootDir: kernelUnpackDir,
+	})
+}
+
 func (s *imageSeeder) finishSeedCore() error {
 	gadgetUnpackDir := filepath.Join(s.prepareDir, "gadget")
 	kernelUnpackDir := filepath.Join(s.prepareDir, "kernel")
@@ -898,6 +992,7 @@ func (s *imageSeeder) finish() error {
 
 	copySnap := func(name, src, dst string) error {
 		fmt.Fprintf(Stdout, "Copying %q (%s)\n", src, name)
+		s.seededSnaps[name] = dst
 		return osutil.CopyFile(src, dst, 0)
 	}
 	if err := s.w.SeedSnaps(copySnap); err != nil {
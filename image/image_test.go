@@ -44,6 +44,7 @@ import (
 	"github.com/snapcore/snapd/bootloader/ubootenv"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/image"
 	"github.com/snapcore/snapd/image/preseed"
 	"github.com/snapcore/snapd/osutil"
@@ -2984,6 +2985,110 @@ func (s *imageSuite) TestSetupSeedClassicUC20(c *C) {
 	})
 }
 
+const pcUC20DiskImageGadgetYaml = `
+ volumes:
+   pc:
+     bootloader: grub
+     structure:
+       - name: ubuntu-seed
+         role: system-seed
+         filesystem: vfat
+         type: EF,C12A7328-F81F-11D2-BA4B-00A0C93EC93B
+         size: 4M
+         content:
+           - source: grub-recovery.conf
+             target: EFI/ubuntu/grub.cfg
+       - name: ubuntu-boot
+         role: system-boot
+         filesystem: ext4
+         type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+         size: 4M
+       - name: ubuntu-data
+         role: system-data
+         filesystem: ext4
+         type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+         size: 8M
+`
+
+func (s *imageSuite) TestSetupSeedClassicUC20DiskImageLeavesSeedUntouched(c *C) {
+	restore := image.MockTrusted(s.StoreSigning.Trusted)
+	defer restore()
+
+	s.makeSnap(c, "snapd", [][]string{snapdInfoFile}, snap.R(1), "")
+	s.makeSnap(c, "core20", nil, snap.R(20), "")
+	s.makeSnap(c, "pc-kernel=20", nil, snap.R(1), "")
+	gadgetContent := [][]string{
+		{"grub-recovery.conf", "# recovery grub.cfg"},
+		{"grub.conf", "# boot grub.cfg"},
+		{"meta/gadget.yaml", pcUC20DiskImageGadgetYaml},
+	}
+	s.makeSnap(c, "pc=20", gadgetContent, snap.R(22), "")
+
+	model := s.Brands.Model("my-brand", "my-model", map[string]any{
+		"classic":      "true",
+		"distribution": "ubuntu",
+		"display-name": "my model",
+		"architecture": "amd64",
+		"base":         "core20",
+		"snaps": []any{
+			map[string]any{
+				"name":            "pc-kernel",
+				"id":              s.AssertedSnapID("pc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+			},
+			map[string]any{
+				"name":            "pc",
+				"id":              s.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+	})
+
+	// the content is resolved for real to be written to the images
+	restore = image.MockWriteResolvedContent(image.WriteResolvedContent)
+	defer restore()
+
+	mockSfdisk := testutil.MockCommand(c, "sfdisk", "")
+	defer mockSfdisk.Restore()
+
+	var seedContent []string
+	restore = image.MockMkfsMakeWithContent(func(typ, img, label, contentRootDir string, deviceSize, sectorSize quantity.Size) error {
+		if label != "ubuntu-seed" {
+			return nil
+		}
+		// the filesystem gets both the seed and the gadget content
+		c.Check(filepath.Join(contentRootDir, "EFI/ubuntu/grub.cfg"), testutil.FileEquals, "# recovery grub.cfg")
+		entries, err := os.ReadDir(contentRootDir)
+		c.Assert(err, IsNil)
+		for _, e := range entries {
+			seedContent = append(seedContent, e.Name())
+		}
+		return nil
+	})
+	defer restore()
+
+	prepareDir := c.MkDir()
+	imageDir := filepath.Join(c.MkDir(), "images")
+	err := image.SetupSeed(s.tsto, model, &image.Options{
+		Classic:      true,
+		PrepareDir:   prepareDir,
+		DiskImageDir: imageDir,
+	})
+	c.Assert(err, IsNil)
+	c.Check(seedContent, DeepEquals, []string{"EFI", "snaps", "systems"})
+	c.Check(filepath.Join(imageDir, "pc.img"), testutil.FilePresent)
+
+	// the gadget content was not written to the seed
+	seeddir := filepath.Join(prepareDir, "system-seed")
+	dirs, err := filepath.Glob(seeddir + "/*")
+	c.Assert(err, IsNil)
+	c.Check(dirs, DeepEquals, []string{
+		seeddir + "/snaps", seeddir + "/systems",
+	})
+}
+
 func (s *imageSuite) TestSetupSeedClassicWithLocalClassicSnap(c *C) {
 	restore := image.MockTrusted(s.StoreSigning.Trusted)
 	defer restore()
@@ -3672,6 +3777,151 @@ func (s *imageSuite) TestPrepareWithUC20Preseed(c *C) {
 	c.Check(preseedCalled, Equals, true)
 }
 
+const diskImageGadgetYaml = `
+volumes:
+  pc:
+    bootloader: grub
+    schema: gpt
+    structure:
+      - name: mbr
+        type: mbr
+        size: 440
+        content:
+          - image: pc-boot.img
+      - name: BIOS Boot
+        type: DA,21686148-6449-6E6F-744E-656564454649
+        size: 1M
+        offset: 1M
+        content:
+          - image: pc-core.img
+      - name: ubuntu-seed
+        role: system-seed
+        filesystem: vfat
+        type: EF,C12A7328-F81F-11D2-BA4B-00A0C93EC93B
+        size: 4M
+      - name: ubuntu-boot
+        role: system-boot
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 4M
+      - name: ubuntu-data
+        role: system-data
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 8M
+`
+
+func (s *imageSuite) TestPrepareWithUC20DiskImage(c *C) {
+	prepareDir := c.MkDir()
+	imageDir := filepath.Join(c.MkDir(), "images")
+
+	restoreSetupSeed := image.MockSetupSeed(func(tsto *tooling.ToolingStore, model *asserts.Model, opts *image.Options) error {
+		// what seeding leaves in the prepare directory
+		gadgetDir := filepath.Join(prepareDir, "gadget")
+		for _, f := range [][]string{
+			{"meta/gadget.yaml", diskImageGadgetYaml},
+			{"pc-boot.img", "mbr boot code"},
+			{"pc-core.img", "core image"},
+		} {
+			c.Assert(os.MkdirAll(filepath.Join(gadgetDir, filepath.Dir(f[0])), 0755), IsNil)
+			c.Assert(os.WriteFile(filepath.Join(gadgetDir, f[0]), []byte(f[1]), 0644), IsNil)
+		}
+		c.Assert(os.MkdirAll(filepath.Join(prepareDir, "kernel"), 0755), IsNil)
+		c.Assert(os.MkdirAll(filepath.Join(prepareDir, "system-seed/systems"), 0755), IsNil)
+		c.Assert(os.MkdirAll(filepath.Join(prepareDir, "resolved-content/pc"), 0755), IsNil)
+		return os.Symlink(filepath.Join(prepareDir, "system-seed"), filepath.Join(prepareDir, "resolved-content/pc/part2"))
+	})
+	defer restoreSetupSeed()
+
+	sfdiskInput := filepath.Join(c.MkDir(), "sfdisk.input")
+	mockSfdisk := testutil.MockCommand(c, "sfdisk", fmt.Sprintf("cat > %s", sfdiskInput))
+	defer mockSfdisk.Restore()
+
+	type mkfsCall struct {
+		typ, label, contentDir string
+		size                   quantity.Size
+	}
+	var mkfsCalls []mkfsCall
+	restore := image.MockMkfsMakeWithContent(func(typ, img, label, contentRootDir string, deviceSize, sectorSize quantity.Size) error {
+		mkfsCalls = append(mkfsCalls, mkfsCall{typ, label, contentRootDir, deviceSize})
+		c.Check(sectorSize, Equals, quantity.Size(512))
+		f, err := os.OpenFile(img, os.O_WRONLY, 0)
+		c.Assert(err, IsNil)
+		defer f.Close()
+		_, err = f.WriteString("fs:" + label)
+		return err
+	})
+	defer restore()
+
+	model := s.makeUC20Model(nil)
+	fn := filepath.Join(c.MkDir(), "model.assertion")
+	c.Assert(os.WriteFile(fn, asserts.Encode(model), 0644), IsNil)
+
+	err := image.Prepare(&image.Options{
+		ModelFile:    fn,
+		PrepareDir:   prepareDir,
+		DiskImageDir: imageDir,
+	})
+	c.Assert(err, IsNil)
+
+	// ubuntu-boot and ubuntu-data are created at install
+	c.Check(mockSfdisk.Calls(), HasLen, 1)
+	c.Check(sfdiskInput, testutil.FileEquals, `label: gpt
+start=2048, size=2048, type=21686148-6449-6E6F-744E-656564454649, name="BIOS Boot"
+start=4096, size=8192, type=C12A7328-F81F-11D2-BA4B-00A0C93EC93B, name="ubuntu-seed"
+`)
+	c.Check(mkfsCalls, DeepEquals, []mkfsCall{
+		{"vfat", "ubuntu-seed", filepath.Join(prepareDir, "system-seed"), 4 * quantity.SizeMiB},
+	})
+
+	img, err := os.ReadFile(filepath.Join(imageDir, "pc.img"))
+	c.Assert(err, IsNil)
+	// the seed ends at 6MiB, plus the space for the backup GPT
+	c.Assert(img, HasLen, 7*1024*1024)
+	c.Check(string(img[:13]), Equals, "mbr boot code")
+	c.Check(string(img[1024*1024:1024*1024+10]), Equals, "core image")
+	c.Check(string(img[2*1024*1024:2*1024*1024+14]), Equals, "fs:ubuntu-seed")
+	c.Check(s.stdout.String(), Matches, `(?ms).*Writing disk image ".*/pc.img" \(7 MiB\)`)
+
+	// no leftovers
+	files, err := filepath.Glob(filepath.Join(imageDir, "*"))
+	c.Assert(err, IsNil)
+	c.Check(files, DeepEquals, []string{filepath.Join(imageDir, "pc.img")})
+}
+
+func (s *imageSuite) TestPrepareDiskImageErrors(c *C) {
+	restoreSetupSeed := image.MockSetupSeed(func(tsto *tooling.ToolingStore, model *asserts.Model, opts *image.Options) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	defer restoreSetupSeed()
+
+	model := s.Brands.Model("my-brand", "my-model", map[string]any{
+		"architecture": "amd64",
+		"gadget":       "pc18",
+		"kernel":       "pc-kernel=18",
+		"base":         "core18",
+	})
+	fn := filepath.Join(c.MkDir(), "model.assertion")
+	c.Assert(os.WriteFile(fn, asserts.Encode(model), 0644), IsNil)
+
+	err := image.Prepare(&image.Options{
+		ModelFile:    fn,
+		PrepareDir:   c.MkDir(),
+		DiskImageDir: c.MkDir(),
+	})
+	c.Assert(err, ErrorMatches, `cannot create disk images for a core model older than UC20`)
+
+	restore := sysdb.MockGenericClassicModel(s.StoreSigning.GenericClassicModel)
+	defer restore()
+	err = image.Prepare(&image.Options{
+		Classic:      true,
+		PrepareDir:   c.MkDir(),
+		DiskImageDir: c.MkDir(),
+	})
+	c.Assert(err, ErrorMatches, `cannot create disk images for a model without a gadget`)
+}
+
 func (s *imageSuite) TestPrepareWithClassicPreseedError(c *C) {
 	restoreSetupSeed := image.MockSetupSeed(func(tsto *tooling.ToolingStore, model *asserts.Model, opts *image.Options) error {
 		return nil
@@ -68,6 +68,11 @@ type Options struct {
 
 	PrepareDir string
 
+	// DiskImageDir if set requests a raw disk image to be written there
+	// for each gadget volume, with the gadget content and the seed in
+	// place (only for UC20+ and classic models with a gadget).
+	DiskImageDir string
+
 	// Architecture to use if none is specified by the model,
 	// useful only for classic mode. If set must match the model otherwise.
 	Architecture string
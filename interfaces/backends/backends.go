@@ -20,20 +20,25 @@
 package backends
 
 import (
+	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/configfiles"
 	"github.com/snapcore/snapd/interfaces/dbus"
 	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/ldconfig"
 	"github.com/snapcore/snapd/interfaces/mount"
 	"github.com/snapcore/snapd/interfaces/polkit"
 	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/selinux"
 	"github.com/snapcore/snapd/interfaces/symlinks"
 	"github.com/snapcore/snapd/interfaces/systemd"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/logger"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
+	landlock_sandbox "github.com/snapcore/snapd/sandbox/landlock"
+	selinux_sandbox "github.com/snapcore/snapd/sandbox/selinux"
 )
 
 // All returns a set of all available security backends.
@@ -80,5 +85,35 @@ func All() []interfaces.SecurityBackend {
 	case apparmor_sandbox.Partial, apparmor_sandbox.Full:
 		all = append(all, &apparmor.Backend{})
 	}
+
+	// Without AppArmor, fall back to restricting strictly confined snaps
+	// with Landlock when the kernel supports it. Landlock is far coarser
+	// than AppArmor so it is not stacked on top of it, not even when
+	// AppArmor is only partially supported.
+	if apparmor_sandbox.ProbedLevel() == apparmor_sandbox.Unsupported {
+		logger.Noticef("Landlock status: %s\n", landlock_sandbox.Summary())
+		if landlock_sandbox.IsSupported() {
+			all = append(all, &landlock.Backend{})
+		}
+	}
+
+	selinuxLevel, selinuxSummary := selinux_sandbox.Status()
+	if selinuxSummary != "" {
+		logger.Noticef("SELinux status: %s\n", selinuxSummary)
+	}
+	// Without full AppArmor support, confine snaps with per-app SELinux
+	// policy modules when the experimental feature is enabled and those can
+	// be installed. The feature is only checked when snapd starts, the
+	// modules installed while it was enabled are removed once it is
+	// disabled so that snap-confine does not switch to their domains.
+	if apparmor_sandbox.ProbedLevel() != apparmor_sandbox.Full &&
+		selinuxLevel != selinux_sandbox.Unsupported &&
+		selinux_sandbox.PolicyModulesSupported() {
+		if features.SELinuxConfinement.IsEnabled() {
+			all = append(all, &selinux.Backend{})
+		} else if err := selinux.RemoveAll(); err != nil {
+			logger.Noticef("%v", err)
+		}
+	}
 	return all
 }
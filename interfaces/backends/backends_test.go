@@ -18,13 +18,21 @@
 package backends_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/backends"
+	"github.com/snapcore/snapd/logger"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
+	landlock_sandbox "github.com/snapcore/snapd/sandbox/landlock"
+	selinux_sandbox "github.com/snapcore/snapd/sandbox/selinux"
 	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -33,10 +41,24 @@ func Test(t *testing.T) {
 	TestingT(t)
 }
 
-type backendsSuite struct{}
+type backendsSuite struct {
+	testutil.BaseTest
+}
 
 var _ = Suite(&backendsSuite{})
 
+func (s *backendsSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+	s.AddCleanup(selinux_sandbox.MockIsEnabled(func() (bool, error) { return false, nil }))
+}
+
+func enableSELinuxConfinement(c *C) {
+	c.Assert(os.MkdirAll(dirs.FeaturesDir, 0755), IsNil)
+	c.Assert(os.WriteFile(features.SELinuxConfinement.ControlFile(), nil, 0644), IsNil)
+}
+
 func (s *backendsSuite) TestIsAppArmorEnabled(c *C) {
 	for _, level := range []apparmor_sandbox.LevelType{apparmor_sandbox.Unsupported, apparmor_sandbox.Unusable, apparmor_sandbox.Partial, apparmor_sandbox.Full} {
 		restore := apparmor_sandbox.MockLevel(level)
@@ -51,6 +73,143 @@ func (s *backendsSuite) TestIsAppArmorEnabled(c *C) {
 	}
 }
 
+func (s *backendsSuite) TestIsLandlockEnabled(c *C) {
+	restore := landlock_sandbox.MockABIVersion(4, nil)
+	defer restore()
+
+	for _, level := range []apparmor_sandbox.LevelType{apparmor_sandbox.Unsupported, apparmor_sandbox.Unusable, apparmor_sandbox.Partial, apparmor_sandbox.Full} {
+		restore := apparmor_sandbox.MockLevel(level)
+		defer restore()
+
+		switch level {
+		case apparmor_sandbox.Unsupported:
+			c.Assert(backendNames(backends.All()), testutil.Contains, "landlock")
+		default:
+			c.Assert(backendNames(backends.All()), Not(testutil.Contains), "landlock")
+		}
+	}
+}
+
+func (s *backendsSuite) TestLandlockUnsupported(c *C) {
+	restore := landlock_sandbox.MockABIVersion(0, errors.New("not supported"))
+	defer restore()
+	restore = apparmor_sandbox.MockLevel(apparmor_sandbox.Unsupported)
+	defer restore()
+
+	c.Assert(backendNames(backends.All()), Not(testutil.Contains), "landlock")
+}
+
+func (s *backendsSuite) TestIsSELinuxEnabled(c *C) {
+	enableSELinuxConfinement(c)
+	restore := selinux_sandbox.MockPolicyModulesSupported(true)
+	defer restore()
+	restore = selinux_sandbox.MockIsEnabled(func() (bool, error) { return true, nil })
+	defer restore()
+	restore = selinux_sandbox.MockIsEnforcing(func() (bool, error) { return true, nil })
+	defer restore()
+
+	for _, level := range []apparmor_sandbox.LevelType{apparmor_sandbox.Unsupported, apparmor_sandbox.Unusable, apparmor_sandbox.Partial, apparmor_sandbox.Full} {
+		restore := apparmor_sandbox.MockLevel(level)
+		defer restore()
+
+		switch level {
+		case apparmor_sandbox.Full:
+			c.Assert(backendNames(backends.All()), Not(testutil.Contains), "selinux")
+		default:
+			c.Assert(backendNames(backends.All()), testutil.Contains, "selinux")
+		}
+	}
+}
+
+func (s *backendsSuite) TestSELinuxUnsupported(c *C) {
+	enableSELinuxConfinement(c)
+	restore := apparmor_sandbox.MockLevel(apparmor_sandbox.Unsupported)
+	defer restore()
+
+	// SELinux is disabled
+	restore = selinux_sandbox.MockPolicyModulesSupported(true)
+	defer restore()
+	restore = selinux_sandbox.MockIsEnabled(func() (bool, error) { return false, nil })
+	defer restore()
+	c.Assert(backendNames(backends.All()), Not(testutil.Contains), "selinux")
+
+	// semodule is not available
+	restore = selinux_sandbox.MockPolicyModulesSupported(false)
+	defer restore()
+	restore = selinux_sandbox.MockIsEnabled(func() (bool, error) { return true, nil })
+	defer restore()
+	restore = selinux_sandbox.MockIsEnforcing(func() (bool, error) { return true, nil })
+	defer restore()
+	c.Assert(backendNames(backends.All()), Not(testutil.Contains), "selinux")
+}
+
+func (s *backendsSuite) TestSELinuxFeatureDisabled(c *C) {
+	restore := apparmor_sandbox.MockLevel(apparmor_sandbox.Unsupported)
+	defer restore()
+	restore = selinux_sandbox.MockPolicyModulesSupported(true)
+	defer restore()
+	restore = selinux_sandbox.MockIsEnabled(func() (bool, error) { return true, nil })
+	defer restore()
+	restore = selinux_sandbox.MockIsEnforcing(func() (bool, error) { return true, nil })
+	defer restore()
+
+	c.Assert(backendNames(backends.All()), Not(testutil.Contains), "selinux")
+}
+
+func (s *backendsSuite) TestSELinuxFeatureDisabledRemovesModules(c *C) {
+	restore := apparmor_sandbox.MockLevel(apparmor_sandbox.Unsupported)
+	defer restore()
+	restore = selinux_sandbox.MockPolicyModulesSupported(true)
+	defer restore()
+	restore = selinux_sandbox.MockIsEnabled(func() (bool, error) { return true, nil })
+	defer restore()
+	restore = selinux_sandbox.MockIsEnforcing(func() (bool, error) { return true, nil })
+	defer restore()
+	semodule := testutil.MockCommand(c, "semodule", "")
+	defer semodule.Restore()
+
+	// modules installed while the feature was enabled
+	c.Assert(os.MkdirAll(dirs.SnapSELinuxDir, 0755), IsNil)
+	for _, name := range []string{"snappy_apps.cil", "snap__foo__bar.cil"} {
+		c.Assert(os.WriteFile(filepath.Join(dirs.SnapSELinuxDir, name), nil, 0644), IsNil)
+	}
+
+	enableSELinuxConfinement(c)
+	c.Assert(backendNames(backends.All()), testutil.Contains, "selinux")
+	c.Check(semodule.Calls(), HasLen, 0)
+
+	c.Assert(os.Remove(features.SELinuxConfinement.ControlFile()), IsNil)
+	c.Assert(backendNames(backends.All()), Not(testutil.Contains), "selinux")
+	c.Check(semodule.Calls(), DeepEquals, [][]string{
+		{"semodule", "-r", "snap__foo__bar", "-r", "snappy_apps"},
+	})
+	matches, err := filepath.Glob(filepath.Join(dirs.SnapSELinuxDir, "*"))
+	c.Assert(err, IsNil)
+	c.Check(matches, HasLen, 0)
+}
+
+func (s *backendsSuite) TestSELinuxStatusLogged(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+	restore = selinux_sandbox.MockIsEnabled(func() (bool, error) { return true, nil })
+	defer restore()
+	restore = selinux_sandbox.MockIsEnforcing(func() (bool, error) { return true, nil })
+	defer restore()
+
+	// the status is logged even with full AppArmor support
+	restore = apparmor_sandbox.MockLevel(apparmor_sandbox.Full)
+	defer restore()
+	backends.All()
+	c.Check(logbuf.String(), testutil.Contains, "SELinux status: SELinux is enabled and in enforcing mode\n")
+
+	// but not when SELinux is disabled
+	logbuf.Reset()
+	restore = selinux_sandbox.MockIsEnabled(func() (bool, error) { return false, nil })
+	defer restore()
+	backends.All()
+	c.Check(logbuf.String(), Not(testutil.Contains), "SELinux status")
+}
+
 func (s *backendsSuite) TestEssentialOrdering(c *C) {
 	restore := apparmor_sandbox.MockLevel(apparmor_sandbox.Full)
 	defer restore()
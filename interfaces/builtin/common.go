@@ -26,8 +26,10 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/mount"
 	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/selinux"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
@@ -76,6 +78,11 @@ type commonInterface struct {
 	connectedPlugUpdateNSAppArmor string
 	connectedPlugMount            []osutil.MountEntry
 
+	connectedPlugLandlockPaths   map[string]landlock.Access
+	connectedPlugLandlockNetwork landlock.NetworkAccess
+
+	connectedPlugSELinux string
+
 	connectedPlugKModModules []string
 	connectedSlotKModModules []string
 	permanentPlugKModModules []string
@@ -211,6 +218,31 @@ func (iface *commonInterface) SecCompConnectedPlug(spec *seccomp.Specification,
 	return nil
 }
 
+func (iface *commonInterface) LandlockConnectedPlug(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	// the access granted by interfaces which are not mapped to Landlock
+	// rules is unknown
+	if iface.connectedPlugLandlockPaths == nil && iface.connectedPlugLandlockNetwork == 0 {
+		spec.Unrestrict()
+		return nil
+	}
+	for path, access := range iface.connectedPlugLandlockPaths {
+		if err := spec.AllowPath(path, access); err != nil {
+			return err
+		}
+	}
+	if iface.connectedPlugLandlockNetwork != 0 {
+		spec.AllowNetwork(iface.connectedPlugLandlockNetwork)
+	}
+	return nil
+}
+
+func (iface *commonInterface) SELinuxConnectedPlug(spec *selinux.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if iface.connectedPlugSELinux != "" {
+		spec.AddSnippet(iface.connectedPlugSELinux)
+	}
+	return nil
+}
+
 func (iface *commonInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
 	// don't tag devices if the interface controls its own device cgroup
 	if iface.controlsDeviceCgroup {
@@ -27,6 +27,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/snap"
 )
@@ -172,3 +173,29 @@ func (iface *commonFilesInterface) AppArmorConnectedPlug(spec *apparmor.Specific
 
 	return nil
 }
+
+func (iface *commonFilesInterface) LandlockConnectedPlug(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	var reads, writes []any
+	_ = plug.Attr("read", &reads)
+	_ = plug.Attr("write", &writes)
+
+	errPrefix := fmt.Sprintf(`cannot connect plug %s: `, plug.Name())
+	for _, paths := range []struct {
+		paths  []any
+		access landlock.Access
+	}{
+		{reads, landlock.AccessRead},
+		{writes, landlock.AccessReadWrite},
+	} {
+		for _, rawPath := range paths.paths {
+			p, ok := rawPath.(string)
+			if !ok {
+				return fmt.Errorf("%s%[2]v (%[2]T) is not a string", errPrefix, rawPath)
+			}
+			if err := spec.AllowPath(p, paths.access); err != nil {
+				return fmt.Errorf("%s%v", errPrefix, err)
+			}
+		}
+	}
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -278,3 +279,44 @@ slots:
 	c.Assert(spec.AddConnectedPlug(iface, plug, slot), IsNil)
 	c.Assert(spec.ControlsDeviceCgroup(), Equals, true)
 }
+
+func (s *commonIfaceSuite) TestLandlockSpec(c *C) {
+	plug, _ := MockConnectedPlug(c, `
+name: consumer
+version: 0
+apps:
+  app:
+    plugs: [common]
+`, nil, "common")
+	slot, _ := MockConnectedSlot(c, `
+name: producer
+version: 0
+slots:
+  common:
+`, nil, "common")
+
+	// common interface can define connected plug landlock rules
+	iface := &commonInterface{
+		name: "common",
+		connectedPlugLandlockPaths: map[string]landlock.Access{
+			"/foo": landlock.AccessRead,
+		},
+		connectedPlugLandlockNetwork: landlock.NetworkConnectTCP,
+	}
+	spec := landlock.NewSpecification(plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(iface, plug, slot), IsNil)
+	c.Check(spec.Paths("snap.consumer.app"), DeepEquals, map[string]landlock.Access{
+		"/foo": landlock.AccessRead,
+	})
+	c.Check(spec.Network("snap.consumer.app"), Equals, landlock.NetworkConnectTCP)
+	c.Check(spec.Unrestricted("snap.consumer.app"), Equals, false)
+
+	// interfaces without landlock rules lift the restrictions
+	iface = &commonInterface{
+		name: "common",
+	}
+	spec = landlock.NewSpecification(plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(iface, plug, slot), IsNil)
+	c.Check(spec.Paths("snap.consumer.app"), IsNil)
+	c.Check(spec.Unrestricted("snap.consumer.app"), Equals, true)
+}
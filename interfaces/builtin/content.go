@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/compatibility"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/mount"
 	"github.com/snapcore/snapd/osutil"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
@@ -328,6 +329,42 @@ func (iface *contentInterface) AppArmorConnectedSlot(spec *apparmor.Specificatio
 	return nil
 }
 
+// Interactions with the landlock backend.
+
+func (iface *contentInterface) LandlockConnectedPlug(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	// Allow the slot implementation's files as well as the mount targets,
+	// which may be within the read-only $SNAP of the plugging snap.
+	for _, w := range iface.path(slot, "write") {
+		source, target := sourceTarget(plug, slot, w)
+		for _, p := range []string{source, target} {
+			if err := spec.AllowPath(filepath.Clean(p), landlock.AccessReadWrite); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range iface.path(slot, "read") {
+		source, target := sourceTarget(plug, slot, r)
+		for _, p := range []string{source, target} {
+			if err := spec.AllowPath(filepath.Clean(p), landlock.AccessReadExecute); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (iface *contentInterface) LandlockConnectedSlot(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	// Allow the slot implementation to access the exported files at the
+	// plugging snap's mountpoint, as done for AppArmor.
+	for _, w := range iface.path(slot, "write") {
+		_, target := sourceTarget(plug, slot, w)
+		if err := spec.AllowPath(filepath.Clean(target), landlock.AccessReadWrite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (iface *contentInterface) AutoConnect(plug *snap.PlugInfo, slot *snap.SlotInfo) bool {
 	// allow what declarations allowed
 	return true
@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/mount"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
@@ -1216,6 +1217,49 @@ apps:
 	c.Assert(apparmorSpec.SnippetForTag("snap.producer.app"), Equals, expected)
 }
 
+func (s *ContentSuite) TestLandlockConnectedPlugAndSlot(c *C) {
+	const consumerYaml = `name: consumer
+version: 0
+plugs:
+ content:
+  target: $SNAP/import
+apps:
+ app:
+  command: foo
+`
+	plug, _ := MockConnectedPlug(c, consumerYaml, &snap.SideInfo{Revision: snap.R(7)}, "content")
+	const producerYaml = `name: producer
+version: 0
+slots:
+ content:
+  read:
+   - $SNAP/lib
+  write:
+   - $SNAP_DATA/export
+apps:
+ app:
+  command: bar
+`
+	slot, _ := MockConnectedSlot(c, producerYaml, &snap.SideInfo{Revision: snap.R(5)}, "content")
+
+	landlockSpec := landlock.NewSpecification(plug.AppSet())
+	c.Assert(landlockSpec.AddConnectedPlug(s.iface, plug, slot), IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(landlockSpec.Unrestricted("snap.consumer.app"), Equals, false)
+	c.Check(landlockSpec.Paths("snap.consumer.app"), DeepEquals, map[string]landlock.Access{
+		"/snap/producer/5/lib":        landlock.AccessReadExecute,
+		"/var/snap/producer/5/export": landlock.AccessReadWrite,
+		"/snap/consumer/7/import":     landlock.AccessReadWrite | landlock.AccessExecute,
+	})
+
+	landlockSpec = landlock.NewSpecification(slot.AppSet())
+	c.Assert(landlockSpec.AddConnectedSlot(s.iface, plug, slot), IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.producer.app"})
+	c.Check(landlockSpec.Paths("snap.producer.app"), DeepEquals, map[string]landlock.Access{
+		"/snap/consumer/7/import": landlock.AccessReadWrite,
+	})
+}
+
 func (s *ContentSuite) TestStaticInfo(c *C) {
 	si := interfaces.StaticInfoOf(s.iface)
 	c.Assert(si.ImplicitOnCore, Equals, false)
@@ -24,6 +24,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/snap"
 )
 
@@ -101,6 +102,18 @@ capability dac_read_search,
 @{HOME}/{s,sn,sna}{,/} r,
 `
 
+// Files in $HOME are labeled user_home_t, except for the data of the snaps
+// in $HOME/snap and the files of applications which have a policy of their
+// own, such as ssh or gpg. Unlike AppArmor, SELinux cannot exclude the other
+// hidden files.
+const homeConnectedPlugSELinux = `
+; Description: Can access files in user's $HOME.
+(allow ###TYPE### user_home_dir_t (dir (add_name getattr open read remove_name search write)))
+(allow ###TYPE### user_home_t (dir (add_name create getattr ioctl lock open read remove_name rename reparent rmdir search setattr write)))
+(allow ###TYPE### user_home_t (file (append create getattr ioctl link lock map open read rename setattr unlink write)))
+(allow ###TYPE### user_home_t (lnk_file (create getattr read rename unlink)))
+`
+
 type homeInterface struct {
 	commonInterface
 }
@@ -136,5 +149,11 @@ func init() {
 		implicitOnCore:       true,
 		implicitOnClassic:    true,
 		baseDeclarationSlots: homeBaseDeclarationSlots,
+		// Landlock cannot tell files owned by the user apart nor exclude
+		// the hidden files in $HOME protected by the AppArmor rules.
+		connectedPlugLandlockPaths: map[string]landlock.Access{
+			"$HOME": landlock.AccessReadWrite,
+		},
+		connectedPlugSELinux: homeConnectedPlugSELinux,
 	}})
 }
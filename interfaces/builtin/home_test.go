@@ -25,6 +25,8 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/landlock"
+	"github.com/snapcore/snapd/interfaces/selinux"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
@@ -172,6 +174,25 @@ apps:
 	c.Check(apparmorSpec.SnippetForTag("snap.home-plug-snap.app2"), testutil.Contains, `# Allow non-owner read`)
 }
 
+func (s *HomeInterfaceSuite) TestConnectedPlugLandlock(c *C) {
+	landlockSpec := landlock.NewSpecification(s.plug.AppSet())
+	err := landlockSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Check(landlockSpec.Paths("snap.other.app"), DeepEquals, map[string]landlock.Access{
+		"$HOME": landlock.AccessReadWrite,
+	})
+	c.Check(landlockSpec.Network("snap.other.app"), Equals, landlock.NetworkAccess(0))
+}
+
+func (s *HomeInterfaceSuite) TestConnectedPlugSELinux(c *C) {
+	selinuxSpec := selinux.NewSpecification(s.plug.AppSet())
+	err := selinuxSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(selinuxSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Check(selinuxSpec.SnippetForTag("snap.other.app"), testutil.Contains, "(allow ###TYPE### user_home_t (file (")
+}
+
 func (s *HomeInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
@@ -27,6 +27,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 )
@@ -136,6 +137,29 @@ func (iface *i2cInterface) AppArmorConnectedPlug(spec *apparmor.Specification, p
 	return nil
 }
 
+// i2cIoctlRequests are the ioctl requests of the I2C device nodes, see
+// linux/i2c-dev.h. They are the same on all architectures.
+var i2cIoctlRequests = []uint32{
+	0x0701, // I2C_RETRIES
+	0x0702, // I2C_TIMEOUT
+	0x0703, // I2C_SLAVE
+	0x0704, // I2C_TENBIT
+	0x0705, // I2C_FUNCS
+	0x0706, // I2C_SLAVE_FORCE
+	0x0707, // I2C_RDWR
+	0x0708, // I2C_PEC
+	0x0720, // I2C_SMBUS
+}
+
+func (iface *i2cInterface) SecCompConnectedPlug(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	// only the device node is used with ioctl()
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return nil
+	}
+	return spec.AllowIoctl(i2cIoctlRequests...)
+}
+
 func (iface *i2cInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
 	var path string
 	if err := slot.Attr("path", &path); err != nil {
@@ -28,6 +28,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
@@ -277,6 +278,30 @@ func (s *I2cInterfaceSuite) TestAppArmorSpecSysfsName(c *C) {
 `)
 }
 
+func (s *I2cInterfaceSuite) TestSecCompSpecPath(c *C) {
+	spec := seccomp.NewSpecification(s.testPlugPort1.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.testPlugPort1, s.testUDev1), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-1-port"})
+	c.Check(spec.SnippetForTag("snap.client-snap.app-accessing-1-port"), Equals, `ioctl - 4294967295|1793
+ioctl - 4294967295|1794
+ioctl - 4294967295|1795
+ioctl - 4294967295|1796
+ioctl - 4294967295|1797
+ioctl - 4294967295|1798
+ioctl - 4294967295|1799
+ioctl - 4294967295|1800
+ioctl - 4294967295|1824
+`)
+	c.Check(spec.RestrictsIoctl("snap.client-snap.app-accessing-1-port"), Equals, true)
+}
+
+func (s *I2cInterfaceSuite) TestSecCompSpecSysfsName(c *C) {
+	spec := seccomp.NewSpecification(s.testPlugPort1.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.testPlugPort1, s.testSysfsName1), IsNil)
+	c.Check(spec.SecurityTags(), HasLen, 0)
+	c.Check(spec.RestrictsIoctl("snap.client-snap.app-accessing-1-port"), Equals, false)
+}
+
 func (s *I2cInterfaceSuite) TestAutoConnect(c *C) {
 	c.Check(s.iface.AutoConnect(nil, nil), Equals, true)
 }
@@ -19,6 +19,11 @@
 
 package builtin
 
+import (
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+)
+
 // https://www.kernel.org/doc/html/latest/crypto/userspace-if.html
 // https://www.kernel.org/doc/html/latest/crypto/intro.html
 const kernelCryptoAPISummary = `allows access to the Linux kernel crypto API`
@@ -48,19 +53,28 @@ network netlink raw,
 
 const kernelCryptoAPIConnectedPlugSeccomp = `
 # Description: Can access the Linux kernel crypto API
-socket AF_NETLINK - NETLINK_CRYPTO
 bind
 accept
 `
 
+type kernelCryptoAPIInterface struct {
+	commonInterface
+}
+
+func (iface *kernelCryptoAPIInterface) SecCompConnectedPlug(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	spec.AddSnippet(kernelCryptoAPIConnectedPlugSeccomp)
+	return spec.AllowSocket("AF_NETLINK", "NETLINK_CRYPTO")
+}
+
 func init() {
-	registerIface(&commonInterface{
+	registerIface(&kernelCryptoAPIInterface{commonInterface{
 		name:                  "kernel-crypto-api",
 		summary:               kernelCryptoAPISummary,
 		implicitOnCore:        true,
 		implicitOnClassic:     true,
 		connectedPlugAppArmor: kernelCryptoAPIConnectedPlugAppArmor,
-		connectedPlugSecComp:  kernelCryptoAPIConnectedPlugSeccomp,
-		baseDeclarationSlots:  kernelCryptoAPIBaseDeclarationSlots,
-	})
+		// handled by SecCompConnectedPlug
+		connectedPlugSecComp: "",
+		baseDeclarationSlots: kernelCryptoAPIBaseDeclarationSlots,
+	}})
 }
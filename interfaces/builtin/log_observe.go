@@ -19,6 +19,10 @@
 
 package builtin
 
+import (
+	"github.com/snapcore/snapd/interfaces/landlock"
+)
+
 const logObserveSummary = `allows read access to system logs`
 
 const logObserveBaseDeclarationSlots = `
@@ -91,6 +95,15 @@ func init() {
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  logObserveBaseDeclarationSlots,
 		connectedPlugAppArmor: logObserveConnectedPlugAppArmor,
-		connectedPlugUDev:     logObserveConnectedPlugUDev,
+		connectedPlugLandlockPaths: map[string]landlock.Access{
+			"/var/log":                             landlock.AccessRead,
+			"/run/log/journal":                     landlock.AccessRead,
+			"/dev/kmsg":                            landlock.AccessRead,
+			"/var/lib/systemd/catalog/database":    landlock.AccessRead,
+			"/var/lib/snapd/hostfs/bin/journalctl": landlock.AccessReadExecute,
+			"/var/lib/snapd/hostfs/lib/systemd":    landlock.AccessReadExecute,
+			"/proc/sys/kernel/printk_ratelimit":    landlock.AccessReadWrite,
+		},
+		connectedPlugUDev: logObserveConnectedPlugUDev,
 	})
 }
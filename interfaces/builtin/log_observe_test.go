@@ -25,6 +25,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
@@ -90,6 +91,18 @@ func (s *LogObserveInterfaceSuite) TestUsedSecuritySystems(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
 	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/var/log/")
+
+	// connected plugs have access to the logs under landlock
+	landlockSpec := landlock.NewSpecification(s.plug.AppSet())
+	err = landlockSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Check(landlockSpec.Unrestricted("snap.other.app"), Equals, false)
+	paths := landlockSpec.Paths("snap.other.app")
+	c.Check(paths["/var/log"], Equals, landlock.AccessRead)
+	c.Check(paths["/run/log/journal"], Equals, landlock.AccessRead)
+	c.Check(paths["/var/lib/snapd/hostfs/bin/journalctl"], Equals, landlock.AccessReadExecute)
+	c.Check(paths["/proc/sys/kernel/printk_ratelimit"], Equals, landlock.AccessReadWrite)
 }
 
 func (s *LogObserveInterfaceSuite) TestInterfaces(c *C) {
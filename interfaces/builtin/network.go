@@ -19,6 +19,10 @@
 
 package builtin
 
+import (
+	"github.com/snapcore/snapd/interfaces/landlock"
+)
+
 const networkSummary = `allows access to the network`
 
 const networkBaseDeclarationSlots = `
@@ -90,6 +94,15 @@ socket AF_NETLINK - NETLINK_ROUTE
 socket AF_CONN
 `
 
+const networkConnectedPlugSELinux = `
+; Description: Can access the network as a client.
+(allow ###TYPE### self (tcp_socket (connect create getattr getopt ioctl read setopt shutdown write)))
+(allow ###TYPE### self (udp_socket (connect create getattr getopt ioctl read setopt shutdown write)))
+(allow ###TYPE### self (rawip_socket (create getattr getopt read setopt write)))
+(allow ###TYPE### port_type (tcp_socket (name_connect)))
+(allow ###TYPE### node_t (udp_socket (node_bind)))
+`
+
 func init() {
 	registerIface(&commonInterface{
 		name:                  "network",
@@ -99,5 +112,8 @@ func init() {
 		baseDeclarationSlots:  networkBaseDeclarationSlots,
 		connectedPlugAppArmor: networkConnectedPlugAppArmor,
 		connectedPlugSecComp:  networkConnectedPlugSecComp,
+
+		connectedPlugLandlockNetwork: landlock.NetworkConnectTCP,
+		connectedPlugSELinux:         networkConnectedPlugSELinux,
 	})
 }
@@ -19,6 +19,10 @@
 
 package builtin
 
+import (
+	"github.com/snapcore/snapd/interfaces/landlock"
+)
+
 const networkBindSummary = `allows operating as a network service`
 
 const networkBindBaseDeclarationSlots = `
@@ -95,6 +99,16 @@ listen
 socket AF_NETLINK - NETLINK_ROUTE
 `
 
+const networkBindConnectedPlugSELinux = `
+; Description: Can access the network as a server.
+(allow ###TYPE### self (tcp_socket (accept bind connect create getattr getopt ioctl listen read setopt shutdown write)))
+(allow ###TYPE### self (udp_socket (bind connect create getattr getopt ioctl read setopt shutdown write)))
+(allow ###TYPE### port_type (tcp_socket (name_bind name_connect)))
+(allow ###TYPE### port_type (udp_socket (name_bind)))
+(allow ###TYPE### node_t (tcp_socket (node_bind)))
+(allow ###TYPE### node_t (udp_socket (node_bind)))
+`
+
 func init() {
 	registerIface(&commonInterface{
 		name:                  "network-bind",
@@ -104,5 +118,8 @@ func init() {
 		baseDeclarationSlots:  networkBindBaseDeclarationSlots,
 		connectedPlugAppArmor: networkBindConnectedPlugAppArmor,
 		connectedPlugSecComp:  networkBindConnectedPlugSecComp,
+
+		connectedPlugLandlockNetwork: landlock.NetworkBindTCP | landlock.NetworkConnectTCP,
+		connectedPlugSELinux:         networkBindConnectedPlugSELinux,
 	})
 }
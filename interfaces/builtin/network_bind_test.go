@@ -25,7 +25,9 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/selinux"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -88,6 +90,20 @@ func (s *NetworkBindInterfaceSuite) TestUsedSecuritySystems(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(seccompSpec.SecurityTags(), DeepEquals, []string{"snap.other.app2"})
 	c.Check(seccompSpec.SnippetForTag("snap.other.app2"), testutil.Contains, "listen\n")
+
+	// connected plugs can bind and connect TCP sockets under landlock
+	landlockSpec := landlock.NewSpecification(s.plug.AppSet())
+	err = landlockSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.other.app2"})
+	c.Check(landlockSpec.Network("snap.other.app2"), Equals, landlock.NetworkBindTCP|landlock.NetworkConnectTCP)
+
+	// connected plugs can bind TCP sockets under SELinux
+	selinuxSpec := selinux.NewSpecification(s.plug.AppSet())
+	err = selinuxSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(selinuxSpec.SecurityTags(), DeepEquals, []string{"snap.other.app2"})
+	c.Check(selinuxSpec.SnippetForTag("snap.other.app2"), testutil.Contains, "(allow ###TYPE### port_type (tcp_socket (name_bind name_connect)))\n")
 }
 
 func (s *NetworkBindInterfaceSuite) TestInterfaces(c *C) {
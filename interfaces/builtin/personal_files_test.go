@@ -27,6 +27,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/mount"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
@@ -100,6 +101,21 @@ owner "@{HOME}/.local/share/dir1/dir2/target{,/,/**}" rwkl,
   owner @{HOME}/.local/share/dir1/dir2/ rw,`)
 }
 
+func (s *personalFilesInterfaceSuite) TestConnectedPlugLandlock(c *C) {
+	landlockSpec := landlock.NewSpecification(s.plug.AppSet())
+	err := landlockSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Check(landlockSpec.Paths("snap.other.app"), DeepEquals, map[string]landlock.Access{
+		"$HOME/.read-dir":                     landlock.AccessRead,
+		"$HOME/.read-file":                    landlock.AccessRead,
+		"$HOME/.write-dir":                    landlock.AccessReadWrite,
+		"$HOME/.write-file":                   landlock.AccessReadWrite,
+		"$HOME/.local/share/target":           landlock.AccessReadWrite,
+		"$HOME/.local/share/dir1/dir2/target": landlock.AccessReadWrite,
+	})
+}
+
 func (s *personalFilesInterfaceSuite) TestConnectedPlugApparmorErrorNotString(c *C) {
 	const mockPlugSnapInfo = `name: other
 version: 1.0
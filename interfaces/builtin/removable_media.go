@@ -19,6 +19,10 @@
 
 package builtin
 
+import (
+	"github.com/snapcore/snapd/interfaces/landlock"
+)
+
 const removableMediaSummary = `allows access to mounted removable storage`
 
 const removableMediaBaseDeclarationSlots = `
@@ -49,6 +53,15 @@ const removableMediaConnectedPlugAppArmor = `
 /mnt/** mrwklix,
 `
 
+const removableMediaConnectedPlugSELinux = `
+; Description: Can access removable storage filesystems
+(typeattribute ###TYPE###_removable_media)
+(typeattributeset ###TYPE###_removable_media (mnt_t dosfs_t fusefs_t iso9660_t))
+(allow ###TYPE### ###TYPE###_removable_media (dir (add_name create getattr ioctl lock open read remove_name rename reparent rmdir search setattr write)))
+(allow ###TYPE### ###TYPE###_removable_media (file (append create execute getattr ioctl link lock map open read rename setattr unlink write)))
+(allow ###TYPE### ###TYPE###_removable_media (lnk_file (create getattr read rename unlink)))
+`
+
 func init() {
 	registerIface(&commonInterface{
 		name:                  "removable-media",
@@ -57,5 +70,12 @@ func init() {
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  removableMediaBaseDeclarationSlots,
 		connectedPlugAppArmor: removableMediaConnectedPlugAppArmor,
+
+		connectedPlugLandlockPaths: map[string]landlock.Access{
+			"/media":     landlock.AccessReadWrite | landlock.AccessExecute,
+			"/run/media": landlock.AccessReadWrite | landlock.AccessExecute,
+			"/mnt":       landlock.AccessReadWrite | landlock.AccessExecute,
+		},
+		connectedPlugSELinux: removableMediaConnectedPlugSELinux,
 	})
 }
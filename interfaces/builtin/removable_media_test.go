@@ -25,6 +25,8 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/landlock"
+	"github.com/snapcore/snapd/interfaces/selinux"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -80,6 +82,25 @@ func (s *RemovableMediaInterfaceSuite) TestUsedSecuritySystems(c *C) {
 	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.client-snap.other"})
 	c.Check(apparmorSpec.SnippetForTag("snap.client-snap.other"), testutil.Contains, "/{,run/}media/*/ r")
 	c.Check(apparmorSpec.SnippetForTag("snap.client-snap.other"), testutil.Contains, "/mnt/** mrwklix,")
+
+	// connected plugs have access to removable media under landlock
+	landlockSpec := landlock.NewSpecification(s.plug.AppSet())
+	err = landlockSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.client-snap.other"})
+	rwx := landlock.AccessReadWrite | landlock.AccessExecute
+	c.Check(landlockSpec.Paths("snap.client-snap.other"), DeepEquals, map[string]landlock.Access{
+		"/media":     rwx,
+		"/run/media": rwx,
+		"/mnt":       rwx,
+	})
+
+	// connected plugs have access to removable media under SELinux
+	selinuxSpec := selinux.NewSpecification(s.plug.AppSet())
+	err = selinuxSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(selinuxSpec.SecurityTags(), DeepEquals, []string{"snap.client-snap.other"})
+	c.Check(selinuxSpec.SnippetForTag("snap.client-snap.other"), testutil.Contains, "(typeattributeset ###TYPE###_removable_media (mnt_t dosfs_t fusefs_t iso9660_t))\n")
 }
 
 func (s *RemovableMediaInterfaceSuite) TestInterfaces(c *C) {
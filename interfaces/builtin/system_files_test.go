@@ -27,6 +27,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
@@ -87,6 +88,21 @@ func (s *systemFilesInterfaceSuite) TestConnectedPlugAppArmor(c *C) {
 `)
 }
 
+func (s *systemFilesInterfaceSuite) TestConnectedPlugLandlock(c *C) {
+	landlockSpec := landlock.NewSpecification(s.plug.AppSet())
+	err := landlockSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(landlockSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Check(landlockSpec.Unrestricted("snap.other.app"), Equals, false)
+	c.Check(landlockSpec.Paths("snap.other.app"), DeepEquals, map[string]landlock.Access{
+		"/etc/read-dir2":   landlock.AccessRead,
+		"/etc/read-file2":  landlock.AccessRead,
+		"/etc/write-dir2":  landlock.AccessReadWrite,
+		"/etc/write-file2": landlock.AccessReadWrite,
+		"/dev/foo@bar":     landlock.AccessReadWrite,
+	})
+}
+
 func (s *systemFilesInterfaceSuite) TestSanitizeSlot(c *C) {
 	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
 }
@@ -0,0 +1,202 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/landlock"
+	landlock_sandbox "github.com/snapcore/snapd/sandbox/landlock"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type backendSuite struct {
+	ifacetest.BackendSuite
+}
+
+var _ = Suite(&backendSuite{})
+
+func (s *backendSuite) SetUpTest(c *C) {
+	s.Backend = &landlock.Backend{}
+	s.BackendSuite.SetUpTest(c)
+	c.Assert(s.Repo.AddBackend(s.Backend), IsNil)
+}
+
+func (s *backendSuite) TearDownTest(c *C) {
+	s.BackendSuite.TearDownTest(c)
+}
+
+func (s *backendSuite) TestName(c *C) {
+	c.Check(s.Backend.Name(), Equals, interfaces.SecurityLandlock)
+}
+
+func (s *backendSuite) TestInstallingSnapWritesRulesets(c *C) {
+	s.Iface.LandlockConnectedPlugCallback = func(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+		return nil
+	}
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlWithHook, 0)
+	for _, tag := range []string{"snap.samba.smbd", "snap.samba.nmbd", "snap.samba.hook.configure"} {
+		ruleset := filepath.Join(dirs.SnapLandlockDir, tag+".ruleset")
+		c.Check(ruleset, testutil.FileContains, "# Landlock ruleset for "+tag+"\n")
+		c.Check(ruleset, testutil.FileContains, "\nfs rx /usr\n")
+		c.Check(ruleset, testutil.FileContains, "\nfs rw /var/snap/samba\n")
+		c.Check(ruleset, testutil.FileContains, "\nfs rw $HOME/snap/samba\n")
+		c.Check(ruleset, testutil.FileContains, "\nfs rw /run/user/$UID/snap.samba\n")
+		c.Check(ruleset, testutil.FileContains, "\nfs rw /dev/null\n")
+		c.Check(ruleset, Not(testutil.FileContains), "\nfs rw /dev\n")
+		c.Check(ruleset, Not(testutil.FileContains), "@unrestricted")
+		c.Check(ruleset, Not(testutil.FileContains), "net ")
+	}
+
+	s.RemoveSnap(c, snapInfo)
+	matches, err := filepath.Glob(filepath.Join(dirs.SnapLandlockDir, "*"))
+	c.Assert(err, IsNil)
+	c.Check(matches, HasLen, 0)
+}
+
+func (s *backendSuite) TestInstallingParallelInstance(c *C) {
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "samba_foo", ifacetest.SambaYamlV1, 0)
+	ruleset := filepath.Join(dirs.SnapLandlockDir, "snap.samba_foo.smbd.ruleset")
+	// data directories are mapped to the name of the snap in the mount
+	// namespace
+	c.Check(ruleset, testutil.FileContains, "\nfs rw /var/snap/samba\n")
+	c.Check(ruleset, testutil.FileContains, "\nfs rw $HOME/snap/samba\n")
+	c.Check(ruleset, testutil.FileContains, "\nfs rw /run/snap.samba_foo\n")
+}
+
+func (s *backendSuite) TestInstallingSnapWithInterfaceRules(c *C) {
+	s.Iface.LandlockPermanentSlotCallback = func(spec *landlock.Specification, slot *snap.SlotInfo) error {
+		if err := spec.AllowPath("/srv/samba", landlock.AccessReadWrite); err != nil {
+			return err
+		}
+		if err := spec.AllowPath("/etc/samba", landlock.AccessRead); err != nil {
+			return err
+		}
+		spec.AllowNetwork(landlock.NetworkBindTCP | landlock.NetworkConnectTCP)
+		return nil
+	}
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 0)
+	ruleset := filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.ruleset")
+	c.Check(ruleset, testutil.FileContains, `
+# Description: rules of connected interfaces
+fs r /etc/samba
+fs rw /srv/samba
+net bind-tcp
+net connect-tcp
+`)
+}
+
+func (s *backendSuite) TestInstallingSnapUnrestricted(c *C) {
+	for _, opts := range []interfaces.ConfinementOptions{
+		{DevMode: true},
+		{Classic: true},
+	} {
+		snapInfo := s.InstallSnap(c, opts, "", ifacetest.SambaYamlV1, 0)
+		ruleset := filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.ruleset")
+		c.Check(ruleset, testutil.FileEquals, "# Landlock ruleset for snap.samba.smbd\n@unrestricted\n")
+		s.RemoveSnap(c, snapInfo)
+	}
+
+	// jailmode snaps are restricted
+	for _, opts := range []interfaces.ConfinementOptions{
+		{DevMode: true, JailMode: true},
+		{Classic: true, JailMode: true},
+	} {
+		snapInfo := s.InstallSnap(c, opts, "", ifacetest.SambaYamlV1, 0)
+		ruleset := filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.ruleset")
+		c.Check(ruleset, Not(testutil.FileContains), "@unrestricted")
+		c.Check(ruleset, testutil.FileContains, "\nfs rx /usr\n")
+		s.RemoveSnap(c, snapInfo)
+	}
+}
+
+func (s *backendSuite) TestInstallingSnapWithUnmappedInterface(c *C) {
+	s.Iface.LandlockPermanentSlotCallback = func(spec *landlock.Specification, slot *snap.SlotInfo) error {
+		if err := spec.AllowPath("/srv/samba", landlock.AccessReadWrite); err != nil {
+			return err
+		}
+		spec.Unrestrict()
+		return nil
+	}
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 0)
+	ruleset := filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.ruleset")
+	c.Check(ruleset, testutil.FileEquals, "# Landlock ruleset for snap.samba.smbd\n@unrestricted\n")
+}
+
+func (s *backendSuite) TestUpdatingSnapRemovesStaleRulesets(c *C) {
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1WithNmbd, 0)
+	nmbd := filepath.Join(dirs.SnapLandlockDir, "snap.samba.nmbd.ruleset")
+	c.Check(nmbd, testutil.FilePresent)
+
+	snapInfo = s.UpdateSnap(c, snapInfo, interfaces.ConfinementOptions{}, ifacetest.SambaYamlV1, 0)
+	c.Check(nmbd, testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.ruleset"), testutil.FilePresent)
+	s.RemoveSnap(c, snapInfo)
+}
+
+func (s *backendSuite) TestSetupFailsOnBadSpecification(c *C) {
+	s.Iface.LandlockPermanentSlotCallback = func(spec *landlock.Specification, slot *snap.SlotInfo) error {
+		return spec.AllowPath("relative", landlock.AccessRead)
+	}
+	appSet := s.mockAppSet(c, ifacetest.SambaYamlV1)
+	err := s.Backend.Setup(appSet, interfaces.ConfinementOptions{}, s.Repo, nil)
+	c.Assert(err, ErrorMatches, `cannot obtain landlock specification for snap "samba": cannot allow access to "relative": path must be clean and absolute or start with \$HOME`)
+}
+
+func (s *backendSuite) TestSetupFailsWhenDirectoryCannotBeCreated(c *C) {
+	c.Assert(os.MkdirAll(filepath.Dir(dirs.SnapLandlockDir), 0755), IsNil)
+	c.Assert(os.WriteFile(dirs.SnapLandlockDir, nil, 0644), IsNil)
+
+	appSet := s.mockAppSet(c, ifacetest.SambaYamlV1)
+	err := s.Backend.Setup(appSet, interfaces.ConfinementOptions{}, s.Repo, nil)
+	c.Assert(err, ErrorMatches, `cannot create directory for landlock rulesets ".*": .*`)
+}
+
+func (s *backendSuite) mockAppSet(c *C, snapYaml string) *interfaces.SnapAppSet {
+	snapInfo := snaptest.MockInfo(c, snapYaml, &snap.SideInfo{Revision: snap.R(1)})
+	appSet, err := interfaces.NewSnapAppSet(snapInfo, nil)
+	c.Assert(err, IsNil)
+	c.Assert(s.Repo.AddAppSet(appSet), IsNil)
+	return appSet
+}
+
+func (s *backendSuite) TestSandboxFeatures(c *C) {
+	restore := landlock_sandbox.MockABIVersion(4, nil)
+	defer restore()
+	c.Check(s.Backend.SandboxFeatures(), DeepEquals, []string{"abi:4", "fs", "fs-refer", "fs-truncate", "net-tcp"})
+
+	restore = landlock_sandbox.MockABIVersion(0, errors.New("boom"))
+	defer restore()
+	c.Check(s.Backend.SandboxFeatures(), HasLen, 0)
+}
@@ -0,0 +1,289 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap"
+)
+
+// Access describes the filesystem access granted to a path hierarchy.
+type Access uint
+
+const (
+	// AccessRead allows reading files and listing directories.
+	AccessRead Access = 1 << iota
+	// AccessWrite allows writing, truncating, creating, renaming and
+	// removing files and directories.
+	AccessWrite
+	// AccessExecute allows executing files.
+	AccessExecute
+
+	AccessReadWrite   = AccessRead | AccessWrite
+	AccessReadExecute = AccessRead | AccessExecute
+)
+
+// String returns the representation of the access used in ruleset files,
+// e.g. "rw".
+func (a Access) String() string {
+	var sb strings.Builder
+	if a&AccessRead != 0 {
+		sb.WriteRune('r')
+	}
+	if a&AccessWrite != 0 {
+		sb.WriteRune('w')
+	}
+	if a&AccessExecute != 0 {
+		sb.WriteRune('x')
+	}
+	return sb.String()
+}
+
+// NetworkAccess describes network operations which are not restricted.
+type NetworkAccess uint
+
+const (
+	// NetworkBindTCP allows binding TCP sockets to any port.
+	NetworkBindTCP NetworkAccess = 1 << iota
+	// NetworkConnectTCP allows connecting TCP sockets to any port.
+	NetworkConnectTCP
+)
+
+// homeVar is expanded by snap-confine to the home directory of the user
+// running the snap.
+const homeVar = "$HOME"
+
+// Specification keeps the Landlock rules of each security tag.
+type Specification struct {
+	appSet *interfaces.SnapAppSet
+	// paths and network access are indexed by security tag
+	paths        map[string]map[string]Access
+	network      map[string]NetworkAccess
+	unrestricted map[string]bool
+	securityTags []string
+}
+
+// NewSpecification returns an empty Landlock specification for the given
+// app set.
+func NewSpecification(appSet *interfaces.SnapAppSet) *Specification {
+	return &Specification{appSet: appSet}
+}
+
+// AllowPath grants access to the file or directory hierarchy at the given
+// path. The path must be absolute or start with "$HOME", which refers to the
+// home directory of the user running the snap. Access granted to the same
+// path multiple times is combined.
+func (spec *Specification) AllowPath(path string, access Access) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	if access == 0 || access&^(AccessRead|AccessWrite|AccessExecute) != 0 {
+		return fmt.Errorf("cannot allow access to %q: invalid access %d", path, access)
+	}
+	if len(spec.securityTags) == 0 {
+		return nil
+	}
+	if spec.paths == nil {
+		spec.paths = make(map[string]map[string]Access)
+	}
+	for _, tag := range spec.securityTags {
+		if spec.paths[tag] == nil {
+			spec.paths[tag] = make(map[string]Access)
+		}
+		spec.paths[tag][path] |= access
+	}
+	return nil
+}
+
+func validatePath(path string) error {
+	rel := path
+	if strings.HasPrefix(path, homeVar) {
+		rel = strings.TrimPrefix(path, homeVar)
+		if rel == "" {
+			return nil
+		}
+	}
+	if !filepath.IsAbs(rel) || filepath.Clean(rel) != rel || strings.ContainsAny(rel, "\n\x00$") {
+		return fmt.Errorf("cannot allow access to %q: path must be clean and absolute or start with %s", path, homeVar)
+	}
+	return nil
+}
+
+// AllowNetwork lifts restrictions on the given network operations.
+func (spec *Specification) AllowNetwork(access NetworkAccess) {
+	if len(spec.securityTags) == 0 {
+		return
+	}
+	if spec.network == nil {
+		spec.network = make(map[string]NetworkAccess)
+	}
+	for _, tag := range spec.securityTags {
+		spec.network[tag] |= access
+	}
+}
+
+// Unrestrict lifts the Landlock restrictions entirely. It is used for
+// interfaces granting access which is not mapped to Landlock rules, as the
+// apps and hooks would otherwise not work as expected.
+func (spec *Specification) Unrestrict() {
+	if len(spec.securityTags) == 0 {
+		return
+	}
+	if spec.unrestricted == nil {
+		spec.unrestricted = make(map[string]bool)
+	}
+	for _, tag := range spec.securityTags {
+		spec.unrestricted[tag] = true
+	}
+}
+
+// Unrestricted returns whether the Landlock restrictions are lifted for a
+// given security tag.
+func (spec *Specification) Unrestricted(tag string) bool {
+	return spec.unrestricted[tag]
+}
+
+// Paths returns a copy of the paths and their access for a given security
+// tag.
+func (spec *Specification) Paths(tag string) map[string]Access {
+	if len(spec.paths[tag]) == 0 {
+		return nil
+	}
+	result := make(map[string]Access, len(spec.paths[tag]))
+	for path, access := range spec.paths[tag] {
+		result[path] = access
+	}
+	return result
+}
+
+// Network returns the network operations allowed for a given security tag.
+func (spec *Specification) Network(tag string) NetworkAccess {
+	return spec.network[tag]
+}
+
+// SecurityTags returns a list of security tags which have rules.
+func (spec *Specification) SecurityTags() []string {
+	seen := make(map[string]bool)
+	for t := range spec.paths {
+		seen[t] = true
+	}
+	for t := range spec.network {
+		seen[t] = true
+	}
+	for t := range spec.unrestricted {
+		seen[t] = true
+	}
+	tags := make([]string, 0, len(seen))
+	for t := range seen {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// unrestrict lifts the Landlock restrictions for the given security tags.
+func (spec *Specification) unrestrict(tags []string) {
+	spec.securityTags = tags
+	defer func() { spec.securityTags = nil }()
+	spec.Unrestrict()
+}
+
+// Implementation of methods required by interfaces.Specification
+
+// AddConnectedPlug records landlock-specific side-effects of having a
+// connected plug. The restrictions are lifted if the interface does not map
+// its access to Landlock rules.
+func (spec *Specification) AddConnectedPlug(iface interfaces.Interface, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	type definer interface {
+		LandlockConnectedPlug(spec *Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
+	}
+	tags, err := spec.appSet.SecurityTagsForConnectedPlug(plug)
+	if err != nil {
+		return err
+	}
+	if iface, ok := iface.(definer); ok {
+		spec.securityTags = tags
+		defer func() { spec.securityTags = nil }()
+		return iface.LandlockConnectedPlug(spec, plug, slot)
+	}
+	spec.unrestrict(tags)
+	return nil
+}
+
+// AddConnectedSlot records landlock-specific side-effects of having a
+// connected slot. The restrictions are lifted if the interface does not map
+// its access to Landlock rules.
+func (spec *Specification) AddConnectedSlot(iface interfaces.Interface, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	type definer interface {
+		LandlockConnectedSlot(spec *Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
+	}
+	tags, err := spec.appSet.SecurityTagsForConnectedSlot(slot)
+	if err != nil {
+		return err
+	}
+	if iface, ok := iface.(definer); ok {
+		spec.securityTags = tags
+		defer func() { spec.securityTags = nil }()
+		return iface.LandlockConnectedSlot(spec, plug, slot)
+	}
+	spec.unrestrict(tags)
+	return nil
+}
+
+// AddPermanentPlug records landlock-specific side-effects of having a plug.
+func (spec *Specification) AddPermanentPlug(iface interfaces.Interface, plug *snap.PlugInfo) error {
+	type definer interface {
+		LandlockPermanentPlug(spec *Specification, plug *snap.PlugInfo) error
+	}
+	if iface, ok := iface.(definer); ok {
+		tags, err := spec.appSet.SecurityTagsForPlug(plug)
+		if err != nil {
+			return err
+		}
+
+		spec.securityTags = tags
+		defer func() { spec.securityTags = nil }()
+		return iface.LandlockPermanentPlug(spec, plug)
+	}
+	return nil
+}
+
+// AddPermanentSlot records landlock-specific side-effects of having a slot.
+func (spec *Specification) AddPermanentSlot(iface interfaces.Interface, slot *snap.SlotInfo) error {
+	type definer interface {
+		LandlockPermanentSlot(spec *Specification, slot *snap.SlotInfo) error
+	}
+	if iface, ok := iface.(definer); ok {
+		tags, err := spec.appSet.SecurityTagsForSlot(slot)
+		if err != nil {
+			return err
+		}
+
+		spec.securityTags = tags
+		defer func() { spec.securityTags = nil }()
+		return iface.LandlockPermanentSlot(spec, slot)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/landlock"
+	"github.com/snapcore/snapd/snap"
+)
+
+type specSuite struct {
+	iface    *ifacetest.TestInterface
+	plugInfo *snap.PlugInfo
+	plug     *interfaces.ConnectedPlug
+	slotInfo *snap.SlotInfo
+	slot     *interfaces.ConnectedSlot
+}
+
+var _ = Suite(&specSuite{
+	iface: &ifacetest.TestInterface{
+		InterfaceName: "test",
+		LandlockConnectedPlugCallback: func(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AllowNetwork(landlock.NetworkConnectTCP)
+			return spec.AllowPath("/connected-plug", landlock.AccessRead)
+		},
+		LandlockConnectedSlotCallback: func(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			return spec.AllowPath("/connected-slot", landlock.AccessReadWrite)
+		},
+		LandlockPermanentPlugCallback: func(spec *landlock.Specification, plug *snap.PlugInfo) error {
+			// access to the same path is combined
+			return spec.AllowPath("/connected-plug", landlock.AccessExecute)
+		},
+		LandlockPermanentSlotCallback: func(spec *landlock.Specification, slot *snap.SlotInfo) error {
+			spec.AllowNetwork(landlock.NetworkBindTCP)
+			return nil
+		},
+	},
+})
+
+func (s *specSuite) SetUpTest(c *C) {
+	const plugYaml = `name: snap1
+version: 1
+apps:
+ app1:
+  plugs: [name]
+`
+	s.plug, s.plugInfo = ifacetest.MockConnectedPlug(c, plugYaml, nil, "name")
+
+	const slotYaml = `name: snap2
+version: 1
+slots:
+ name:
+  interface: test
+apps:
+ app2:
+`
+	s.slot, s.slotInfo = ifacetest.MockConnectedSlot(c, slotYaml, nil, "name")
+}
+
+// The landlock.Specification can be used through the interfaces.Specification interface
+func (s *specSuite) TestSpecificationIface(c *C) {
+	spec := landlock.NewSpecification(s.plug.AppSet())
+	var r interfaces.Specification = spec
+	c.Assert(r.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(r.AddPermanentPlug(s.iface, s.plugInfo), IsNil)
+	c.Check(spec.SecurityTags(), DeepEquals, []string{"snap.snap1.app1"})
+	c.Check(spec.Paths("snap.snap1.app1"), DeepEquals, map[string]landlock.Access{
+		"/connected-plug": landlock.AccessReadExecute,
+	})
+	c.Check(spec.Network("snap.snap1.app1"), Equals, landlock.NetworkConnectTCP)
+
+	spec = landlock.NewSpecification(s.slot.AppSet())
+	r = spec
+	c.Assert(r.AddConnectedSlot(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(r.AddPermanentSlot(s.iface, s.slotInfo), IsNil)
+	c.Check(spec.SecurityTags(), DeepEquals, []string{"snap.snap2.app2"})
+	c.Check(spec.Paths("snap.snap2.app2"), DeepEquals, map[string]landlock.Access{
+		"/connected-slot": landlock.AccessReadWrite,
+	})
+	c.Check(spec.Network("snap.snap2.app2"), Equals, landlock.NetworkBindTCP)
+
+	c.Check(spec.Paths("non-existing"), IsNil)
+	c.Check(spec.Network("non-existing"), Equals, landlock.NetworkAccess(0))
+}
+
+func (s *specSuite) TestNetworkOnlySecurityTags(c *C) {
+	spec := landlock.NewSpecification(s.slot.AppSet())
+	c.Assert(spec.AddPermanentSlot(s.iface, s.slotInfo), IsNil)
+	c.Check(spec.SecurityTags(), DeepEquals, []string{"snap.snap2.app2"})
+	c.Check(spec.Paths("snap.snap2.app2"), IsNil)
+}
+
+// unmappedInterface does not map its access to Landlock rules.
+type unmappedInterface struct{}
+
+func (unmappedInterface) Name() string { return "unmapped" }
+
+func (unmappedInterface) AutoConnect(plug *snap.PlugInfo, slot *snap.SlotInfo) bool { return true }
+
+func (s *specSuite) TestUnmappedInterfaceUnrestricts(c *C) {
+	spec := landlock.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(unmappedInterface{}, s.plug, s.slot), IsNil)
+	c.Check(spec.SecurityTags(), DeepEquals, []string{"snap.snap1.app1"})
+	c.Check(spec.Unrestricted("snap.snap1.app1"), Equals, true)
+	c.Check(spec.Paths("snap.snap1.app1"), IsNil)
+
+	spec = landlock.NewSpecification(s.slot.AppSet())
+	c.Assert(spec.AddConnectedSlot(unmappedInterface{}, s.plug, s.slot), IsNil)
+	c.Check(spec.SecurityTags(), DeepEquals, []string{"snap.snap2.app2"})
+	c.Check(spec.Unrestricted("snap.snap2.app2"), Equals, true)
+
+	// mapped interfaces do not lift the restrictions
+	spec = landlock.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Check(spec.Unrestricted("snap.snap1.app1"), Equals, false)
+}
+
+func (s *specSuite) TestUnrestrict(c *C) {
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "test",
+		LandlockConnectedPlugCallback: func(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.Unrestrict()
+			return nil
+		},
+	}
+	spec := landlock.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(iface, s.plug, s.slot), IsNil)
+	c.Check(spec.SecurityTags(), DeepEquals, []string{"snap.snap1.app1"})
+	c.Check(spec.Unrestricted("snap.snap1.app1"), Equals, true)
+	c.Check(spec.Unrestricted("non-existing"), Equals, false)
+}
+
+func (s *specSuite) TestAllowPathValidation(c *C) {
+	iface := &ifacetest.TestInterface{InterfaceName: "test"}
+	var path string
+	var access landlock.Access
+	iface.LandlockConnectedPlugCallback = func(spec *landlock.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+		return spec.AllowPath(path, access)
+	}
+
+	for _, t := range []struct {
+		path   string
+		access landlock.Access
+		err    string
+	}{
+		{"/foo", landlock.AccessRead, ""},
+		{"/", landlock.AccessRead, ""},
+		{"$HOME", landlock.AccessReadWrite, ""},
+		{"$HOME/Documents", landlock.AccessReadWrite, ""},
+		{"", landlock.AccessRead, `cannot allow access to "": path must be clean and absolute or start with \$HOME`},
+		{"foo", landlock.AccessRead, `cannot allow access to "foo": path must be clean and absolute or start with \$HOME`},
+		{"/foo/", landlock.AccessRead, `cannot allow access to "/foo/": path must be clean and absolute or start with \$HOME`},
+		{"/foo/../bar", landlock.AccessRead, `cannot allow access to "/foo/../bar": path must be clean and absolute or start with \$HOME`},
+		{"$HOMEDIR", landlock.AccessRead, `cannot allow access to "\$HOMEDIR": path must be clean and absolute or start with \$HOME`},
+		{"/foo/$UID", landlock.AccessRead, `cannot allow access to "/foo/\$UID": path must be clean and absolute or start with \$HOME`},
+		{"/foo\nfs rwx /", landlock.AccessRead, `cannot allow access to "/foo\\nfs rwx /": path must be clean and absolute or start with \$HOME`},
+		{"/foo", 0, `cannot allow access to "/foo": invalid access 0`},
+		{"/foo", 8, `cannot allow access to "/foo": invalid access 8`},
+	} {
+		path, access = t.path, t.access
+		spec := landlock.NewSpecification(s.plug.AppSet())
+		err := spec.AddConnectedPlug(iface, s.plug, s.slot)
+		if t.err == "" {
+			c.Check(err, IsNil, Commentf("%q", t.path))
+			c.Check(spec.Paths("snap.snap1.app1"), DeepEquals, map[string]landlock.Access{t.path: t.access})
+		} else {
+			c.Check(err, ErrorMatches, t.err, Commentf("%q", t.path))
+		}
+	}
+}
+
+func (s *specSuite) TestAccessString(c *C) {
+	c.Check(landlock.AccessRead.String(), Equals, "r")
+	c.Check(landlock.AccessReadWrite.String(), Equals, "rw")
+	c.Check(landlock.AccessReadExecute.String(), Equals, "rx")
+	c.Check((landlock.AccessReadWrite | landlock.AccessExecute).String(), Equals, "rwx")
+}
@@ -0,0 +1,79 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+// defaultTemplate contains the rules granted to every strictly confined snap.
+//
+// Each rule has the form "fs <access> <path>" where access is a combination
+// of "r" (read), "w" (write) and "x" (execute). Access to a directory extends
+// to everything below it. Rules are resolved by snap-confine inside the mount
+// namespace of the snap, right before the application is executed, so paths
+// refer to the view of the snap. Paths which do not exist are ignored.
+// "$HOME" and "$UID" are replaced by the home directory and the user ID of
+// the user running the snap.
+//
+// Landlock cannot express the fine grained rules of the AppArmor template,
+// these rules only confine a snap to the parts of the filesystem that a
+// strictly confined snap is expected to use at all.
+const defaultTemplate = `
+# Description: base rules for all strictly confined snaps
+
+# the base snap, the snap itself and other snaps
+fs rx /usr
+fs rx /bin
+fs rx /sbin
+fs rx /lib
+fs rx /lib32
+fs rx /lib64
+fs rx /libx32
+fs rx /snap
+fs r /etc
+
+# libraries provided by the host, e.g. for graphics
+fs rx /var/lib/snapd/lib
+
+# kernel interfaces, access to devices other than the basic ones below
+# needs interfaces
+fs r /proc
+fs r /sys
+fs rw /dev/null
+fs rw /dev/zero
+fs rw /dev/full
+fs r /dev/random
+fs r /dev/urandom
+fs rw /dev/tty
+fs rw /dev/ptmx
+fs rw /dev/pts
+fs rw /dev/shm
+
+# private per-snap temporary directories
+fs rw /tmp
+fs rw /var/tmp
+
+# runtime state
+fs r /run
+fs rw /run/user/$UID/snap.###SNAP_INSTANCE_NAME###
+fs rw /run/snap.###SNAP_INSTANCE_NAME###
+
+# SNAP_DATA, SNAP_COMMON and SNAP_USER_DATA, parallel instances of snaps see
+# their data directories under the name of the snap
+fs rw /var/snap/###SNAP_NAME###
+fs rw $HOME/snap/###SNAP_NAME###
+`
@@ -290,7 +290,7 @@ func (b *Backend) deriveContent(spec *Specification, opts interfaces.Confinement
 
 		path := r.SecurityTag + ".src"
 		content[path] = &osutil.MemoryFileState{
-			Content: generateContent(opts, spec.SnippetForTag(r.SecurityTag), addSocketcall, b.versionInfo, uidGidChownSyscalls.String()),
+			Content: generateContent(opts, spec.SnippetForTag(r.SecurityTag), spec.RestrictsIoctl(r.SecurityTag), addSocketcall, b.versionInfo, uidGidChownSyscalls.String()),
 			Mode:    0644,
 		}
 	}
@@ -298,7 +298,7 @@ func (b *Backend) deriveContent(spec *Specification, opts interfaces.Confinement
 	return content, nil
 }
 
-func generateContent(opts interfaces.ConfinementOptions, snippetForTag string, addSocketcall bool, versionInfo seccomp.VersionInfo, uidGidChownSyscalls string) []byte {
+func generateContent(opts interfaces.ConfinementOptions, snippetForTag string, restrictIoctl, addSocketcall bool, versionInfo seccomp.VersionInfo, uidGidChownSyscalls string) []byte {
 	var buffer bytes.Buffer
 
 	if versionInfo != "" {
@@ -320,6 +320,11 @@ func generateContent(opts interfaces.ConfinementOptions, snippetForTag string, a
 
 	buffer.Write(defaultTemplate)
 	buffer.WriteString(snippetForTag)
+	if restrictIoctl {
+		buffer.WriteString(restrictedIoctlSyscalls)
+	} else {
+		buffer.WriteString(unrestrictedIoctlSyscall)
+	}
 	buffer.WriteString(uidGidChownSyscalls)
 
 	// For systems with partial or missing AppArmor support we need to apply
@@ -356,6 +361,9 @@ func (b *Backend) SandboxFeatures() []string {
 	if res, err := b.versionInfo.HasFeature("bpf-actlog"); err == nil && res {
 		tags = append(tags, "bpf-actlog")
 	}
+	if res, err := b.versionInfo.HasFeature("bpf-argument-ranges"); err == nil && res {
+		tags = append(tags, "bpf-argument-ranges")
+	}
 
 	return tags
 }
@@ -504,6 +504,36 @@ func (s *backendSuite) TestBindIsAddedForNonFullApparmorSystems(c *C) {
 	c.Assert(profile+".src", testutil.FileContains, "# Add bind() for systems with only Seccomp enabled to workaround\n# LP #1644573\nbind\n")
 }
 
+func (s *backendSuite) TestIoctlIsAllowedByDefault(c *C) {
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	appSet, err := interfaces.NewSnapAppSet(snapInfo, nil)
+	c.Assert(err, IsNil)
+	// NOTE: we don't call seccomp.MockTemplate()
+	err = s.Backend.Setup(appSet, interfaces.ConfinementOptions{}, s.Repo, s.meas)
+	c.Assert(err, IsNil)
+	profile := filepath.Join(dirs.SnapSeccompDir, "snap.samba.smbd")
+	c.Check(profile+".src", testutil.FileContains, "\nioctl\n")
+	c.Check(profile+".src", Not(testutil.FileContains), "ioctl - 4294967295|TCGETS\n")
+	// requests faking input are denied in any case
+	c.Check(profile+".src", testutil.FileContains, "~ioctl - 4294967295|TIOCSTI\n")
+}
+
+func (s *backendSuite) TestIoctlIsRestrictedByInterfaces(c *C) {
+	s.Iface.SecCompPermanentSlotCallback = func(spec *seccomp.Specification, slot *snap.SlotInfo) error {
+		return spec.AllowIoctl(0x0703)
+	}
+
+	// NOTE: we don't call seccomp.MockTemplate()
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 0)
+	profile := filepath.Join(dirs.SnapSeccompDir, "snap.samba.smbd")
+	c.Check(profile+".src", Not(testutil.FileContains), "\nioctl\n")
+	c.Check(profile+".src", testutil.FileContains, "\nioctl - 4294967295|1795\n")
+	// the common file and terminal requests are still allowed
+	c.Check(profile+".src", testutil.FileContains, "\nioctl - 4294967295|FIOCLEX\n")
+	c.Check(profile+".src", testutil.FileContains, "\nioctl - 4294967295|TCGETS\n")
+	c.Check(profile+".src", testutil.FileContains, "~ioctl - 4294967295|TIOCSTI\n")
+}
+
 func (s *backendSuite) TestSocketcallIsAddedWhenRequired(c *C) {
 	restore := seccomp.MockRequiresSocketcall(func(string) bool { return true })
 	defer restore()
@@ -598,6 +628,17 @@ fi`)
 	err := s.Backend.Initialize(nil)
 	c.Assert(err, IsNil)
 	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{"kernel:foo", "kernel:bar", "bpf-argument-filtering", "bpf-actlog"})
+
+	// snap-seccomp can compile argument ranges
+	snapSeccomp = testutil.MockLockedCommand(c, filepath.Join(dirs.DistroLibExecDir, "snap-seccomp"), `
+if [ "$1" = "version-info" ]; then
+    echo "abcdef 2.5.4 1234abcd bpf-actlog:bpf-argument-ranges"
+fi`)
+	defer snapSeccomp.Restore()
+
+	err = s.Backend.Initialize(nil)
+	c.Assert(err, IsNil)
+	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{"kernel:foo", "kernel:bar", "bpf-argument-filtering", "bpf-actlog", "bpf-argument-ranges"})
 }
 
 func (s *backendSuite) TestRequiresSocketcallByNotNeededArch(c *C) {
@@ -30,11 +30,17 @@ import (
 func MockTemplate(fakeTemplate []byte) (restore func()) {
 	orig := defaultTemplate
 	origBarePrivDropSyscalls := barePrivDropSyscalls
+	origUnrestrictedIoctlSyscall := unrestrictedIoctlSyscall
+	origRestrictedIoctlSyscalls := restrictedIoctlSyscalls
 	defaultTemplate = fakeTemplate
 	barePrivDropSyscalls = ""
+	unrestrictedIoctlSyscall = ""
+	restrictedIoctlSyscalls = ""
 	return func() {
 		defaultTemplate = orig
 		barePrivDropSyscalls = origBarePrivDropSyscalls
+		unrestrictedIoctlSyscall = origUnrestrictedIoctlSyscall
+		restrictedIoctlSyscalls = origRestrictedIoctlSyscalls
 	}
 }
 
@@ -21,7 +21,10 @@ package seccomp
 
 import (
 	"bytes"
+	"fmt"
+	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/snap"
@@ -33,6 +36,9 @@ type Specification struct {
 	// Snippets are indexed by security tag.
 	snippets     map[string][]string
 	securityTags []string
+	// restrictedIoctl records the security tags for which ioctl(2) is
+	// only allowed with the requests allowed by the interfaces.
+	restrictedIoctl map[string]bool
 }
 
 func NewSpecification(appSet *interfaces.SnapAppSet) *Specification {
@@ -58,6 +64,85 @@ func (spec *Specification) AddSnippet(snippet string) {
 	}
 }
 
+// restrictIoctl records that ioctl(2) is only allowed with the requests
+// allowed by the interfaces, instead of with any request.
+func (spec *Specification) restrictIoctl() {
+	if spec.restrictedIoctl == nil {
+		spec.restrictedIoctl = make(map[string]bool)
+	}
+	for _, tag := range spec.securityTags {
+		spec.restrictedIoctl[tag] = true
+	}
+}
+
+// AllowIoctl adds a rule allowing ioctl(2) with the given request numbers.
+// Only the lower 32 bits of the request argument are compared, in the same
+// way the default template denies TIOCSTI (see CVE-2019-7303).
+//
+// Using it restricts ioctl(2) to the requests allowed by the interfaces and
+// to the common file and terminal requests allowed by the default template.
+func (spec *Specification) AllowIoctl(requests ...uint32) error {
+	if len(requests) == 0 {
+		return fmt.Errorf("cannot allow ioctl: no requests specified")
+	}
+	lines := make([]string, 0, len(requests))
+	for _, req := range requests {
+		lines = append(lines, fmt.Sprintf("ioctl - 4294967295|%d", req))
+	}
+	spec.AddSnippet(strings.Join(lines, "\n"))
+	spec.restrictIoctl()
+	return nil
+}
+
+// AllowIoctlRange adds a rule allowing ioctl(2) with request numbers between
+// first and last, inclusive. Like with AllowIoctl, ioctl(2) is then
+// restricted to the requests allowed by the interfaces.
+//
+// Ranges require a snap-seccomp with the "bpf-argument-ranges" feature.
+func (spec *Specification) AllowIoctlRange(first, last uint32) error {
+	if first > last {
+		return fmt.Errorf("cannot allow ioctl: invalid range %d..%d", first, last)
+	}
+	spec.AddSnippet(fmt.Sprintf("ioctl - %d..%d", first, last))
+	spec.restrictIoctl()
+	return nil
+}
+
+// RestrictsIoctl returns whether ioctl(2) is restricted to the requests
+// allowed by the interfaces for the given security tag.
+func (spec *Specification) RestrictsIoctl(tag string) bool {
+	return spec.restrictedIoctl[tag]
+}
+
+var (
+	socketFamilyRegexp   = regexp.MustCompile(`^[AP]F_[A-Z0-9_]+$`)
+	socketProtocolRegexp = regexp.MustCompile(`^([A-Z][A-Z0-9_]*|[0-9]+)$`)
+)
+
+// AllowSocket adds a rule allowing socket(2) for the given address family,
+// e.g. "AF_NETLINK". When protocols are given, e.g. "NETLINK_ROUTE" or
+// "IPPROTO_TCP", sockets of the family are only allowed with one of those
+// protocols. The socket type is never filtered as it may carry flags such as
+// SOCK_NONBLOCK.
+func (spec *Specification) AllowSocket(family string, protocols ...string) error {
+	if !socketFamilyRegexp.MatchString(family) {
+		return fmt.Errorf("cannot allow socket: invalid address family %q", family)
+	}
+	if len(protocols) == 0 {
+		spec.AddSnippet(fmt.Sprintf("socket %s", family))
+		return nil
+	}
+	lines := make([]string, 0, len(protocols))
+	for _, proto := range protocols {
+		if !socketProtocolRegexp.MatchString(proto) {
+			return fmt.Errorf("cannot allow socket: invalid protocol %q", proto)
+		}
+		lines = append(lines, fmt.Sprintf("socket %s - %s", family, proto))
+	}
+	spec.AddSnippet(strings.Join(lines, "\n"))
+	return nil
+}
+
 // Snippets returns a deep copy of all the added snippets.
 func (spec *Specification) Snippets() map[string][]string {
 	result := make(map[string][]string, len(spec.snippets))
@@ -107,3 +107,69 @@ func (s *specSuite) TestSpecificationIface(c *C) {
 
 	c.Assert(spec.SnippetForTag("non-existing"), Equals, "")
 }
+
+func (s *specSuite) TestAllowIoctlAndSocket(c *C) {
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "test",
+		SecCompConnectedPlugCallback: func(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			if err := spec.AllowIoctl(0x5413, 0x5414); err != nil {
+				return err
+			}
+			if err := spec.AllowIoctlRange(0x4000, 0x40ff); err != nil {
+				return err
+			}
+			if err := spec.AllowSocket("AF_NETLINK", "NETLINK_ROUTE", "NETLINK_GENERIC"); err != nil {
+				return err
+			}
+			return spec.AllowSocket("AF_CAN")
+		},
+	}
+
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := seccomp.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, map[string][]string{
+		"snap.snap1.app1": {
+			"ioctl - 4294967295|21523\nioctl - 4294967295|21524",
+			"ioctl - 16384..16639",
+			"socket AF_NETLINK - NETLINK_ROUTE\nsocket AF_NETLINK - NETLINK_GENERIC",
+			"socket AF_CAN",
+		},
+	})
+	c.Check(spec.RestrictsIoctl("snap.snap1.app1"), Equals, true)
+	c.Check(spec.RestrictsIoctl("snap.snap1.other"), Equals, false)
+}
+
+func (s *specSuite) TestAllowSocketDoesNotRestrictIoctl(c *C) {
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "test",
+		SecCompConnectedPlugCallback: func(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("ioctl - 4294967295|21523")
+			return spec.AllowSocket("AF_CAN")
+		},
+	}
+
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := seccomp.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(iface, s.plug, s.slot), IsNil)
+	c.Check(spec.RestrictsIoctl("snap.snap1.app1"), Equals, false)
+}
+
+func (s *specSuite) TestAllowIoctlAndSocketErrors(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := seccomp.NewSpecification(appSet)
+
+	c.Check(spec.AllowIoctl(), ErrorMatches, "cannot allow ioctl: no requests specified")
+	c.Check(spec.AllowIoctlRange(2, 1), ErrorMatches, "cannot allow ioctl: invalid range 2..1")
+	for _, family := range []string{"", "NETLINK", "AF_", "af_inet", "AF_INET - 1"} {
+		c.Check(spec.AllowSocket(family), ErrorMatches, `cannot allow socket: invalid address family ".*"`, Commentf("%q", family))
+	}
+	for _, proto := range []string{"", "netlink_route", "1a", "NETLINK_ROUTE\nptrace"} {
+		c.Check(spec.AllowSocket("AF_NETLINK", proto), ErrorMatches, `cannot allow socket: invalid protocol ".*"`, Commentf("%q", proto))
+	}
+	// numeric protocols are fine
+	c.Check(spec.AllowSocket("AF_NETLINK", "18"), IsNil)
+}
@@ -223,7 +223,8 @@ inotify_rm_watch
 # see CVE-2019-7303
 ~ioctl - 4294967295|TIOCSTI
 ~ioctl - 4294967295|TIOCLINUX
-ioctl
+# ioctl() itself is added after the snippets of the interfaces, see
+# unrestrictedIoctlSyscall and restrictedIoctlSyscalls
 
 io_cancel
 io_destroy
@@ -648,6 +649,36 @@ const socketcallSyscallDeprecated = `
 socketcall
 `
 
+// ioctl() is allowed with any request, unless the interfaces restrict it to
+// the requests they allow with AllowIoctl() or AllowIoctlRange().
+var unrestrictedIoctlSyscall = `
+ioctl
+`
+
+// When the interfaces restrict ioctl(), the common requests on files and
+// terminals that most programs rely on are still allowed. As with TIOCSTI
+// above, only the lower 32 bits of the request are compared.
+var restrictedIoctlSyscalls = `
+# Add the common file and terminal ioctl() requests, the interfaces allow
+# the others
+ioctl - 4294967295|FIOCLEX
+ioctl - 4294967295|FIONCLEX
+ioctl - 4294967295|FIONBIO
+ioctl - 4294967295|FIOASYNC
+ioctl - 4294967295|FIONREAD
+ioctl - 4294967295|TCGETS
+ioctl - 4294967295|TCSETS
+ioctl - 4294967295|TCSETSW
+ioctl - 4294967295|TCSETSF
+ioctl - 4294967295|TCFLSH
+ioctl - 4294967295|TIOCGWINSZ
+ioctl - 4294967295|TIOCSWINSZ
+ioctl - 4294967295|TIOCGPGRP
+ioctl - 4294967295|TIOCSPGRP
+ioctl - 4294967295|TIOCGPTN
+ioctl - 4294967295|TIOCSPTLCK
+`
+
 // Historically snapd has allowed the use of the various setuid, setgid and
 // setgroups syscalls, relying on AppArmor for mediation of the CAP_SETUID and
 // CAP_SETGID. In core20, these can be dropped.
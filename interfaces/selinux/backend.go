@@ -0,0 +1,247 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package selinux implements integration between snapd and SELinux.
+//
+// On systems without AppArmor where SELinux is enabled, snapd installs one
+// SELinux policy module for each app and hook of a snap. The module declares
+// the domain the application runs in and the rules derived from interface
+// connections. The rules common to all applications are kept in a shared
+// module. snap-confine transitions to the domain of the application right
+// before executing it. Snaps in devmode run in permissive domains. Classic
+// snaps get no module and keep running unconfined.
+//
+// The modules are written in the Common Intermediate Language (CIL) and are
+// kept in /var/lib/snapd/selinux, from where they are installed with semodule.
+package selinux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/osutil"
+	selinux_sandbox "github.com/snapcore/snapd/sandbox/selinux"
+	"github.com/snapcore/snapd/timings"
+)
+
+// Backend is responsible for maintaining SELinux policy modules for snap
+// applications.
+type Backend struct{}
+
+// Initialize does nothing.
+func (b *Backend) Initialize(*interfaces.SecurityBackendOptions) error {
+	return nil
+}
+
+// Name returns the name of the backend.
+func (b *Backend) Name() interfaces.SecuritySystem {
+	return interfaces.SecuritySELinux
+}
+
+// escapeName turns a security tag into an SELinux identifier. Dots are
+// replaced with two underscores and any other character which is neither a
+// letter nor a digit is replaced with an underscore followed by its
+// hexadecimal code. The escaping is reversible, therefore distinct security
+// tags never map to the same identifier. Asterisks are preserved so that
+// security tag globs can be escaped too.
+//
+// NOTE: This is replicated by snap-confine.
+func escapeName(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '*':
+			sb.WriteRune(r)
+		case r == '.':
+			sb.WriteString("__")
+		default:
+			fmt.Fprintf(&sb, "_%02x", r)
+		}
+	}
+	return sb.String()
+}
+
+// ModuleName returns the name of the policy module of the application with
+// the given security tag, e.g. snap__foo__bar for snap.foo.bar.
+func ModuleName(securityTag string) string {
+	return escapeName(securityTag)
+}
+
+// TypeName returns the name of the domain the application with the given
+// security tag runs in, e.g. snap__foo__bar_t for snap.foo.bar.
+func TypeName(securityTag string) string {
+	return ModuleName(securityTag) + "_t"
+}
+
+func moduleGlobs(snapName string) []string {
+	var globs []string
+	for _, g := range interfaces.SecurityTagGlobs(snapName) {
+		globs = append(globs, escapeName(g)+".cil")
+	}
+	return globs
+}
+
+// Setup creates and installs the SELinux policy modules specific to a given
+// snap.
+//
+// This method should be called after changing plug, slots, connections
+// between them or application present in the snap.
+func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions, repo *interfaces.Repository, tm timings.Measurer) error {
+	snapName := appSet.InstanceName()
+	spec, err := repo.SnapSpecification(b.Name(), appSet, opts)
+	if err != nil {
+		return fmt.Errorf("cannot obtain SELinux specification for snap %q: %s", snapName, err)
+	}
+
+	content := deriveContent(spec.(*Specification), opts, appSet)
+
+	dir := dirs.SnapSELinuxDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create directory for SELinux policy modules %q: %s", dir, err)
+	}
+
+	baseFile := baseModuleName + ".cil"
+	changedBase, _, err := osutil.EnsureDirState(dir, baseFile, map[string]osutil.FileState{
+		baseFile: &osutil.MemoryFileState{Content: []byte(baseModule), Mode: 0644},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot synchronize SELinux policy modules: %s", err)
+	}
+	changed, removed, err := osutil.EnsureDirStateGlobs(dir, moduleGlobs(snapName), content)
+	if err != nil {
+		return fmt.Errorf("cannot synchronize SELinux policy modules for snap %q: %s", snapName, err)
+	}
+
+	if err := removeModules(removed); err != nil {
+		return fmt.Errorf("cannot remove SELinux policy modules for snap %q: %s", snapName, err)
+	}
+	// The shared module must be installed first as the other ones depend on
+	// it.
+	var paths []string
+	for _, name := range append(changedBase, changed...) {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	if err := selinux_sandbox.InstallModules(paths); err != nil {
+		// Remove the files so that installing the modules is attempted
+		// again on the next setup.
+		for _, path := range paths {
+			os.Remove(path)
+		}
+		return fmt.Errorf("cannot install SELinux policy modules for snap %q: %s", snapName, err)
+	}
+	return nil
+}
+
+// Remove removes the SELinux policy modules of a given snap.
+func (b *Backend) Remove(snapName string) error {
+	_, removed, err := osutil.EnsureDirStateGlobs(dirs.SnapSELinuxDir, moduleGlobs(snapName), nil)
+	if err != nil {
+		return fmt.Errorf("cannot synchronize SELinux policy modules for snap %q: %s", snapName, err)
+	}
+	if err := removeModules(removed); err != nil {
+		return fmt.Errorf("cannot remove SELinux policy modules for snap %q: %s", snapName, err)
+	}
+	return nil
+}
+
+// RemoveAll removes the SELinux policy modules of all snaps along with the
+// shared module. snap-confine switches to the domain of an application as
+// long as the file of its module exists, so the modules must be removed once
+// snaps are not confined with SELinux anymore.
+func RemoveAll() error {
+	// the modules of the applications depend on the shared one, which is
+	// removed last
+	_, removed, err := osutil.EnsureDirStateGlobs(dirs.SnapSELinuxDir, []string{"snap__*.cil", baseModuleName + ".cil"}, nil)
+	if err != nil {
+		return fmt.Errorf("cannot remove SELinux policy modules: %s", err)
+	}
+	if err := removeModules(removed); err != nil {
+		return fmt.Errorf("cannot remove SELinux policy modules: %s", err)
+	}
+	return nil
+}
+
+func removeModules(files []string) error {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(file, ".cil"))
+	}
+	return selinux_sandbox.RemoveModules(names)
+}
+
+// deriveContent combines the template with the snippets collected from all
+// the interfaces affecting a given snap into a content map applicable to
+// EnsureDirState.
+func deriveContent(spec *Specification, opts interfaces.ConfinementOptions, appSet *interfaces.SnapAppSet) map[string]osutil.FileState {
+	if opts.Classic && !opts.JailMode {
+		// Classic snaps keep running unconfined.
+		return nil
+	}
+	var content map[string]osutil.FileState
+	for _, r := range appSet.Runnables() {
+		if content == nil {
+			content = make(map[string]osutil.FileState)
+		}
+		content[ModuleName(r.SecurityTag)+".cil"] = &osutil.MemoryFileState{
+			Content: []byte(generateContent(spec, opts, r.SecurityTag)),
+			Mode:    0644,
+		}
+	}
+	return content
+}
+
+func generateContent(spec *Specification, opts interfaces.ConfinementOptions, tag string) string {
+	var sb strings.Builder
+	sb.WriteString(appTemplate)
+	if opts.DevMode && !opts.JailMode {
+		sb.WriteString("\n; Description: snap in devmode, denials are only logged\n")
+		sb.WriteString("(typepermissive ###TYPE###)\n")
+	}
+	if snippet := spec.SnippetForTag(tag); snippet != "" {
+		sb.WriteString("\n; Description: rules of connected interfaces\n")
+		sb.WriteString(snippet)
+	}
+	replacer := strings.NewReplacer(
+		"###SECURITY_TAG###", tag,
+		"###TYPE###", TypeName(tag),
+	)
+	return replacer.Replace(sb.String())
+}
+
+// NewSpecification returns a new, empty SELinux specification.
+func (b *Backend) NewSpecification(appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) interfaces.Specification {
+	return NewSpecification(appSet)
+}
+
+// SandboxFeatures returns the list of features supported by the SELinux
+// backend.
+func (b *Backend) SandboxFeatures() []string {
+	features := []string{"policy-modules"}
+	switch selinux_sandbox.ProbedLevel() {
+	case selinux_sandbox.Enforcing:
+		features = append(features, "mode:enforcing")
+	case selinux_sandbox.Permissive:
+		features = append(features, "mode:permissive")
+	}
+	return features
+}
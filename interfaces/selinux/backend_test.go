@@ -0,0 +1,256 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package selinux_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/selinux"
+	selinux_sandbox "github.com/snapcore/snapd/sandbox/selinux"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type backendSuite struct {
+	ifacetest.BackendSuite
+
+	semoduleCmd *testutil.MockCmd
+}
+
+var _ = Suite(&backendSuite{})
+
+func (s *backendSuite) SetUpTest(c *C) {
+	s.Backend = &selinux.Backend{}
+	s.BackendSuite.SetUpTest(c)
+	c.Assert(s.Repo.AddBackend(s.Backend), IsNil)
+
+	s.semoduleCmd = testutil.MockCommand(c, "semodule", "")
+}
+
+func (s *backendSuite) TearDownTest(c *C) {
+	s.semoduleCmd.Restore()
+	s.BackendSuite.TearDownTest(c)
+}
+
+func (s *backendSuite) TestName(c *C) {
+	c.Check(s.Backend.Name(), Equals, interfaces.SecuritySELinux)
+}
+
+func (s *backendSuite) TestNames(c *C) {
+	for _, tc := range []struct {
+		tag, module string
+	}{
+		{"snap.foo.bar", "snap__foo__bar"},
+		{"snap.foo-bar.baz", "snap__foo_2dbar__baz"},
+		{"snap.foo_instance.Bar-1", "snap__foo_5finstance__Bar_2d1"},
+		{"snap.foo.hook.configure", "snap__foo__hook__configure"},
+		{"snap.foo+comp.hook.install", "snap__foo_2bcomp__hook__install"},
+	} {
+		c.Check(selinux.ModuleName(tc.tag), Equals, tc.module)
+		c.Check(selinux.TypeName(tc.tag), Equals, tc.module+"_t")
+	}
+}
+
+func (s *backendSuite) TestInstallingSnapInstallsModules(c *C) {
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlWithHook, 0)
+
+	base := filepath.Join(dirs.SnapSELinuxDir, "snappy_apps.cil")
+	c.Check(base, testutil.FileContains, "(typeattribute snappy_app_domain)\n")
+	modules := map[string]string{
+		"snap.samba.hook.configure": "snap__samba__hook__configure",
+		"snap.samba.nmbd":           "snap__samba__nmbd",
+		"snap.samba.smbd":           "snap__samba__smbd",
+	}
+	for tag, module := range modules {
+		path := filepath.Join(dirs.SnapSELinuxDir, module+".cil")
+		c.Check(path, testutil.FileEquals, `; SELinux policy module for `+tag+`
+; This file is generated by snapd, do not edit.
+
+(type `+module+`_t)
+(roletype system_r `+module+`_t)
+(roletype unconfined_r `+module+`_t)
+(typeattributeset snappy_app_domain (`+module+`_t))
+`)
+	}
+	// the shared module is installed first, all in one go
+	c.Check(s.semoduleCmd.Calls(), DeepEquals, [][]string{{
+		"semodule",
+		"-i", base,
+		"-i", filepath.Join(dirs.SnapSELinuxDir, "snap__samba__hook__configure.cil"),
+		"-i", filepath.Join(dirs.SnapSELinuxDir, "snap__samba__nmbd.cil"),
+		"-i", filepath.Join(dirs.SnapSELinuxDir, "snap__samba__smbd.cil"),
+	}})
+
+	s.semoduleCmd.ForgetCalls()
+	s.RemoveSnap(c, snapInfo)
+	c.Check(s.semoduleCmd.Calls(), DeepEquals, [][]string{{
+		"semodule",
+		"-r", "snap__samba__hook__configure",
+		"-r", "snap__samba__nmbd",
+		"-r", "snap__samba__smbd",
+	}})
+	matches, err := filepath.Glob(filepath.Join(dirs.SnapSELinuxDir, "snap__*"))
+	c.Assert(err, IsNil)
+	c.Check(matches, HasLen, 0)
+	// the shared module is kept
+	c.Check(base, testutil.FilePresent)
+}
+
+func (s *backendSuite) TestRemoveAll(c *C) {
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 0)
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SomeSnapYamlV1, 0)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapSELinuxDir, "unrelated"), nil, 0644), IsNil)
+
+	s.semoduleCmd.ForgetCalls()
+	c.Assert(selinux.RemoveAll(), IsNil)
+	// the shared module is removed last
+	c.Check(s.semoduleCmd.Calls(), DeepEquals, [][]string{{
+		"semodule",
+		"-r", "snap__samba__smbd",
+		"-r", "snap__some_2dsnap__someapp",
+		"-r", "snappy_apps",
+	}})
+	matches, err := filepath.Glob(filepath.Join(dirs.SnapSELinuxDir, "*"))
+	c.Assert(err, IsNil)
+	c.Check(matches, DeepEquals, []string{filepath.Join(dirs.SnapSELinuxDir, "unrelated")})
+
+	// nothing to do the second time around
+	s.semoduleCmd.ForgetCalls()
+	c.Assert(selinux.RemoveAll(), IsNil)
+	c.Check(s.semoduleCmd.Calls(), HasLen, 0)
+}
+
+func (s *backendSuite) TestRemoveAllFails(c *C) {
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 0)
+
+	cmd := testutil.MockCommand(c, "semodule", "echo 'cannot remove'; exit 1")
+	defer cmd.Restore()
+	c.Assert(selinux.RemoveAll(), ErrorMatches, "cannot remove SELinux policy modules: semodule failed: cannot remove")
+}
+
+func (s *backendSuite) TestInstallingSimilarlyNamedSnaps(c *C) {
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "samba_foo", ifacetest.SambaYamlV1, 0)
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba_5ffoo__smbd.cil"), testutil.FileContains, "(type snap__samba_5ffoo__smbd_t)\n")
+
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 0)
+	s.semoduleCmd.ForgetCalls()
+	s.RemoveSnap(c, snapInfo)
+	// the modules of the parallel instance are untouched
+	c.Check(s.semoduleCmd.Calls(), DeepEquals, [][]string{{"semodule", "-r", "snap__samba__smbd"}})
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba_5ffoo__smbd.cil"), testutil.FilePresent)
+}
+
+func (s *backendSuite) TestInstallingSnapWithInterfaceRules(c *C) {
+	s.Iface.SELinuxPermanentSlotCallback = func(spec *selinux.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet("(allow ###TYPE### self (tcp_socket (create)))")
+		return nil
+	}
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 0)
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba__smbd.cil"), testutil.FileContains, `
+; Description: rules of connected interfaces
+(allow snap__samba__smbd_t self (tcp_socket (create)))
+`)
+}
+
+func (s *backendSuite) TestUpdatingSnapReinstallsChangedModules(c *C) {
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1WithNmbd, 0)
+
+	// nothing changed
+	s.semoduleCmd.ForgetCalls()
+	snapInfo = s.UpdateSnap(c, snapInfo, interfaces.ConfinementOptions{}, ifacetest.SambaYamlV1WithNmbd, 0)
+	c.Check(s.semoduleCmd.Calls(), HasLen, 0)
+
+	// nmbd is gone and smbd is in devmode now
+	snapInfo = s.UpdateSnap(c, snapInfo, interfaces.ConfinementOptions{DevMode: true}, ifacetest.SambaYamlV1, 0)
+	c.Check(s.semoduleCmd.Calls(), DeepEquals, [][]string{
+		{"semodule", "-r", "snap__samba__nmbd"},
+		{"semodule", "-i", filepath.Join(dirs.SnapSELinuxDir, "snap__samba__smbd.cil")},
+	})
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba__nmbd.cil"), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba__smbd.cil"), testutil.FileContains, `
+; Description: snap in devmode, denials are only logged
+(typepermissive snap__samba__smbd_t)
+`)
+	s.RemoveSnap(c, snapInfo)
+}
+
+func (s *backendSuite) TestInstallingClassicSnap(c *C) {
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{Classic: true}, "", ifacetest.SambaYamlV1, 0)
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba__smbd.cil"), testutil.FileAbsent)
+	s.RemoveSnap(c, snapInfo)
+
+	// unless in jailmode
+	snapInfo = s.InstallSnap(c, interfaces.ConfinementOptions{Classic: true, JailMode: true}, "", ifacetest.SambaYamlV1, 0)
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba__smbd.cil"), Not(testutil.FileContains), "typepermissive")
+	s.RemoveSnap(c, snapInfo)
+}
+
+func (s *backendSuite) TestSetupFailsToInstallModules(c *C) {
+	cmd := testutil.MockCommand(c, "semodule", "echo 'Failed to resolve allow statement'; exit 1")
+	defer cmd.Restore()
+
+	appSet := s.mockAppSet(c, ifacetest.SambaYamlV1)
+	err := s.Backend.Setup(appSet, interfaces.ConfinementOptions{}, s.Repo, nil)
+	c.Assert(err, ErrorMatches, `cannot install SELinux policy modules for snap "samba": semodule failed: Failed to resolve allow statement`)
+	// the modules are installed again on the next attempt
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snappy_apps.cil"), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapSELinuxDir, "snap__samba__smbd.cil"), testutil.FileAbsent)
+}
+
+func (s *backendSuite) TestSetupFailsWhenDirectoryCannotBeCreated(c *C) {
+	c.Assert(os.MkdirAll(filepath.Dir(dirs.SnapSELinuxDir), 0755), IsNil)
+	c.Assert(os.WriteFile(dirs.SnapSELinuxDir, nil, 0644), IsNil)
+
+	appSet := s.mockAppSet(c, ifacetest.SambaYamlV1)
+	err := s.Backend.Setup(appSet, interfaces.ConfinementOptions{}, s.Repo, nil)
+	c.Assert(err, ErrorMatches, `cannot create directory for SELinux policy modules ".*": .*`)
+}
+
+func (s *backendSuite) mockAppSet(c *C, snapYaml string) *interfaces.SnapAppSet {
+	snapInfo := snaptest.MockInfo(c, snapYaml, &snap.SideInfo{Revision: snap.R(1)})
+	appSet, err := interfaces.NewSnapAppSet(snapInfo, nil)
+	c.Assert(err, IsNil)
+	c.Assert(s.Repo.AddAppSet(appSet), IsNil)
+	return appSet
+}
+
+func (s *backendSuite) TestSandboxFeatures(c *C) {
+	restore := selinux_sandbox.MockIsEnabled(func() (bool, error) { return true, nil })
+	defer restore()
+	restore = selinux_sandbox.MockIsEnforcing(func() (bool, error) { return true, nil })
+	defer restore()
+	c.Check(s.Backend.SandboxFeatures(), DeepEquals, []string{"policy-modules", "mode:enforcing"})
+
+	restore = selinux_sandbox.MockIsEnforcing(func() (bool, error) { return false, nil })
+	defer restore()
+	c.Check(s.Backend.SandboxFeatures(), DeepEquals, []string{"policy-modules", "mode:permissive"})
+}
@@ -0,0 +1,108 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package selinux
+
+// baseModuleName is the name of the policy module shared by the policy
+// modules of all snap applications. It depends on the snappy policy module
+// shipped with snapd.
+const baseModuleName = "snappy_apps"
+
+// baseModule defines the snappy_app_domain attribute, which the domains of all
+// the snap applications belong to, and the rules common to all of them. This
+// is the equivalent of the default AppArmor template.
+const baseModule = `; SELinux policy module shared by all snap applications.
+; This file is generated by snapd, do not edit.
+
+(typeattribute snappy_app_domain)
+(typeattributeset domain (snappy_app_domain))
+
+; snap-confine transitions to the domain of the application right before
+; executing snap-exec, which is either provided by the host or by the snapd or
+; core snap.
+(allow snappy_confine_t snappy_app_domain (process (transition noatsecure rlimitinh siginh)))
+(allow snappy_app_domain snappy_confine_t (fd (use)))
+(allow snappy_app_domain snappy_confine_t (process (sigchld)))
+(allow snappy_app_domain snappy_exec_t (file (entrypoint execute execute_no_trans getattr map open read)))
+(allow snappy_app_domain snappy_snap_t (file (entrypoint execute execute_no_trans getattr ioctl lock map open read)))
+(allow snappy_app_domain snappy_snap_t (dir (getattr ioctl lock open read search)))
+(allow snappy_app_domain snappy_snap_t (lnk_file (getattr read)))
+
+; Processes started by the user from a terminal.
+(allow snappy_app_domain unconfined_t (fd (use)))
+(allow snappy_app_domain unconfined_t (fifo_file (append getattr ioctl read write)))
+(allow snappy_app_domain unconfined_t (process (sigchld)))
+(allow snappy_app_domain user_devpts_t (chr_file (append getattr ioctl read write)))
+
+; The application itself.
+(allow snappy_app_domain self (process (fork getattr getcap getpgid getsched setcap setpgid setrlimit setsched sigchld sigkill signal signull sigstop)))
+(allow snappy_app_domain self (fifo_file (append create getattr ioctl lock open read write)))
+(allow snappy_app_domain self (unix_stream_socket (accept bind connect create getattr getopt listen read setopt shutdown write)))
+(allow snappy_app_domain self (unix_dgram_socket (bind connect create getattr getopt read setopt shutdown write)))
+(allow snappy_app_domain self (netlink_route_socket (bind create getattr getopt nlmsg_read read setopt write)))
+(allow snappy_app_domain proc_t (dir (getattr open read search)))
+(allow snappy_app_domain proc_t (file (getattr open read)))
+(allow snappy_app_domain proc_t (lnk_file (getattr read)))
+(allow snappy_app_domain self (dir (getattr open read search)))
+(allow snappy_app_domain self (file (getattr open read write)))
+(allow snappy_app_domain self (lnk_file (getattr read)))
+
+; Read-only access to the base system, as seen in the snap mount namespace.
+(typeattribute snappy_app_readable)
+(typeattributeset snappy_app_readable (root_t bin_t lib_t usr_t etc_t ld_so_t ld_so_cache_t locale_t textrel_shlib_t shell_exec_t fonts_t fonts_cache_t cert_t net_conf_t sysfs_t sysctl_t var_t var_lib_t var_run_t snappy_var_lib_t))
+(allow snappy_app_domain snappy_app_readable (dir (getattr ioctl lock open read search)))
+(allow snappy_app_domain snappy_app_readable (file (getattr ioctl lock map open read)))
+(allow snappy_app_domain snappy_app_readable (lnk_file (getattr read)))
+(typeattribute snappy_app_executable)
+(typeattributeset snappy_app_executable (bin_t lib_t usr_t ld_so_t textrel_shlib_t shell_exec_t))
+(allow snappy_app_domain snappy_app_executable (file (execute execute_no_trans)))
+
+; Common devices.
+(typeattribute snappy_app_device)
+(typeattributeset snappy_app_device (null_device_t zero_device_t random_device_t urandom_device_t))
+(allow snappy_app_domain device_t (dir (getattr open read search)))
+(allow snappy_app_domain snappy_app_device (chr_file (append getattr ioctl map open read write)))
+(allow snappy_app_domain devpts_t (dir (getattr open read search)))
+
+; Writable data of the snap: SNAP_DATA, SNAP_COMMON, SNAP_USER_DATA and the
+; private /tmp of the snap.
+(typeattribute snappy_app_writable)
+(typeattributeset snappy_app_writable (snappy_var_t snappy_home_t snappy_var_run_t tmp_t user_tmp_t tmpfs_t))
+(allow snappy_app_domain snappy_app_writable (dir (add_name create getattr ioctl lock open read remove_name rename reparent rmdir search setattr write)))
+(allow snappy_app_domain snappy_app_writable (file (append create getattr ioctl link lock map open read rename setattr unlink write)))
+(allow snappy_app_domain snappy_app_writable (lnk_file (create getattr read rename unlink)))
+(allow snappy_app_domain snappy_app_writable (sock_file (create getattr open read setattr unlink write)))
+(allow snappy_app_domain snappy_app_writable (fifo_file (create getattr open read setattr unlink write)))
+(allow snappy_app_domain home_root_t (dir (getattr search)))
+(allow snappy_app_domain user_home_dir_t (dir (getattr search)))
+`
+
+// appTemplate is the template of the policy module of a snap application.
+//
+// Each application runs in its own domain, named after its security tag, which
+// is granted the rules of the connected interfaces on top of the rules
+// common to all the applications.
+const appTemplate = `; SELinux policy module for ###SECURITY_TAG###
+; This file is generated by snapd, do not edit.
+
+(type ###TYPE###)
+(roletype system_r ###TYPE###)
+(roletype unconfined_r ###TYPE###)
+(typeattributeset snappy_app_domain (###TYPE###))
+`
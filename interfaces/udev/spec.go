@@ -45,7 +45,10 @@ type Specification struct {
 
 	appSet *interfaces.SnapAppSet
 
-	securityTags             []string
+	securityTags []string
+	// activatedServices maps security tags of the current connection to
+	// the device target of the service activated by its devices
+	activatedServices        map[string]string
 	udevadmSubsystemTriggers []string
 	controlsDeviceCgroup     bool
 }
@@ -120,7 +123,31 @@ func (spec *Specification) TagDevice(snippet string) {
 		// snap-device-helper expects devices only, not modules nor subsystems
 		spec.addEntry(fmt.Sprintf("TAG==\"%s\", SUBSYSTEM!=\"module\", SUBSYSTEM!=\"subsystem\", RUN+=\"%s/snap-device-helper $env{ACTION} %s $devpath $major:$minor\"",
 			tag, dirs.StripRootDir(dirs.DistroLibExecDir), tag), tag)
+		if target := spec.activatedServices[securityTag]; target != "" {
+			// have systemd pull in the device target of the
+			// service once the device appears
+			spec.addEntry(fmt.Sprintf("TAG==\"%s\", SUBSYSTEM!=\"module\", SUBSYSTEM!=\"subsystem\", TAG+=\"systemd\", ENV{SYSTEMD_WANTS}+=\"%s\"",
+				tag, target), tag)
+		}
+	}
+}
+
+// deviceActivatedServices returns the device targets of the services
+// activated by devices of the given plug, keyed by security tag.
+func (spec *Specification) deviceActivatedServices(plug *interfaces.ConnectedPlug) map[string]string {
+	var activated map[string]string
+	for _, app := range spec.appSet.Info().Apps {
+		for _, p := range app.ActivatesOnDevice {
+			if p.Name != plug.Name() {
+				continue
+			}
+			if activated == nil {
+				activated = make(map[string]string)
+			}
+			activated[app.SecurityTag()] = app.DeviceTargetName()
+		}
 	}
+	return activated
 }
 
 type byTagAndSnippet []entry
@@ -170,8 +197,9 @@ func (spec *Specification) AddConnectedPlug(iface interfaces.Interface, plug *in
 		}
 
 		spec.securityTags = tags
+		spec.activatedServices = spec.deviceActivatedServices(plug)
 		spec.iface = ifname
-		defer func() { spec.securityTags = nil; spec.iface = "" }()
+		defer func() { spec.securityTags = nil; spec.activatedServices = nil; spec.iface = "" }()
 		return iface.UDevConnectedPlug(spec, plug, slot)
 	}
 	return nil
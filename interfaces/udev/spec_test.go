@@ -159,6 +159,45 @@ func (s *specSuite) TestTagDeviceAltLibexecdir(c *C) {
 	s.testTagDevice(c, "/usr/libexec/snapd")
 }
 
+func (s *specSuite) TestTagDeviceActivatesService(c *C) {
+	const plugYaml = `name: snap1
+version: 0
+plugs:
+  serial:
+    interface: serial-port
+apps:
+  svc:
+    command: bin/svc
+    daemon: simple
+    activates-on-device: [serial]
+  foo:
+    command: bin/foo
+    plugs: [serial]
+`
+	plug, _ := ifacetest.MockConnectedPlug(c, plugYaml, nil, "serial")
+	spec := udev.NewSpecification(plug.AppSet())
+
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "serial-port",
+		UDevConnectedPlugCallback: func(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.TagDevice(`SUBSYSTEM=="tty", KERNEL=="ttyUSB0"`)
+			return nil
+		},
+	}
+	c.Assert(spec.AddConnectedPlug(iface, plug, s.slot), IsNil)
+
+	// only the device activated service pulls in its device target
+	c.Assert(spec.Snippets(), DeepEquals, []string{
+		`# serial-port
+SUBSYSTEM=="tty", KERNEL=="ttyUSB0", TAG+="snap_snap1_foo"`,
+		`TAG=="snap_snap1_foo", SUBSYSTEM!="module", SUBSYSTEM!="subsystem", RUN+="/usr/lib/snapd/snap-device-helper $env{ACTION} snap_snap1_foo $devpath $major:$minor"`,
+		`# serial-port
+SUBSYSTEM=="tty", KERNEL=="ttyUSB0", TAG+="snap_snap1_svc"`,
+		`TAG=="snap_snap1_svc", SUBSYSTEM!="module", SUBSYSTEM!="subsystem", RUN+="/usr/lib/snapd/snap-device-helper $env{ACTION} snap_snap1_svc $devpath $major:$minor"`,
+		`TAG=="snap_snap1_svc", SUBSYSTEM!="module", SUBSYSTEM!="subsystem", TAG+="systemd", ENV{SYSTEMD_WANTS}+="snap.snap1.svc.device.target"`,
+	})
+}
+
 // The spec.Specification can be used through the interfaces.Specification interface
 func (s *specSuite) TestSpecificationIface(c *C) {
 	appSet, err := interfaces.NewSnapAppSet(s.plugInfo.Snap, nil)
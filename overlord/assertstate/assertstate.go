@@ -684,6 +684,15 @@ func validationSetAssertionForMonitor(st *state.State, accountID, name string, s
 		as = vs.(*asserts.ValidationSet)
 	}
 
+	if db.IsLocallyTrustedAccount(accountID) {
+		// validation sets signed with locally trusted keys are not in
+		// the store
+		if as == nil {
+			return nil, false, fmt.Errorf("validation set assertion signed with locally trusted key must be acknowledged first")
+		}
+		return as, true, nil
+	}
+
 	// try to resolve or update with pool
 	pool := asserts.NewPool(db, maxGroups)
 	atSeq := &asserts.AtSequence{
@@ -799,6 +808,21 @@ func validationSetAssertionForEnforce(st *state.State, accountID, name string, s
 		return nil
 	}
 
+	if db.IsLocallyTrustedAccount(accountID) {
+		// validation sets signed with locally trusted keys are not in
+		// the store, they must have been acknowledged already
+		if errors.Is(err, &asserts.NotFoundError{}) {
+			return nil, fmt.Errorf("cannot enforce locally trusted validation set %s: validation set assertion must be acknowledged first", ValidationSetKey(accountID, name))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkForConflicts(); err != nil {
+			return nil, err
+		}
+		return vs, nil
+	}
+
 	// found locally
 	if err == nil {
 		// check if we were tracking it already; if not, that
@@ -1194,8 +1218,9 @@ func FetchAndApplyEnforcedValidationSet(st *state.State, accountID, name string,
 		Name:      name,
 		Mode:      Enforce,
 		// note, modelSeq may be 0, meaning not pinned.
-		PinnedAt: modelSeq,
-		Current:  vs.Sequence(),
+		PinnedAt:  modelSeq,
+		Current:   vs.Sequence(),
+		LocalOnly: cachedDB(st).IsLocallyTrustedAccount(accountID),
 	}
 
 	UpdateValidationSet(st, &tr)
@@ -3840,6 +3840,106 @@ func (s *assertMgrSuite) TestEnforceValidationSetAssertion(c *C) {
 	}})
 }
 
+func (s *assertMgrSuite) TestEnforceLocallyTrustedValidationSet(c *C) {
+	st := s.state
+
+	st.Lock()
+	defer st.Unlock()
+
+	s.setupModelAndStore(c)
+
+	// trust an on-prem key locally
+	localKey, _ := assertstest.GenerateKey(752)
+	localSigning := assertstest.NewSigningDB("on-prem", localKey)
+	localAcct := assertstest.NewAccount(localSigning, "on-prem", map[string]any{
+		"account-id": "on-prem",
+	}, "")
+	localAcctKey := assertstest.NewAccountKey(localSigning, localAcct, nil, localKey.PublicKey(), "")
+	c.Assert(os.MkdirAll(dirs.SnapAssertsLocalTrustDir, 0755), IsNil)
+	trust := append(asserts.Encode(localAcct), '\n')
+	trust = append(trust, asserts.Encode(localAcctKey)...)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapAssertsLocalTrustDir, "on-prem.assert"), trust, 0644), IsNil)
+	db, err := sysdb.Open()
+	c.Assert(err, IsNil)
+	assertstate.ReplaceDB(st, db)
+
+	vsetAs, err := localSigning.Sign(asserts.ValidationSetType, map[string]any{
+		"series":     "16",
+		"account-id": "on-prem",
+		"name":       "fleet",
+		"sequence":   "3",
+		"snaps": []any{map[string]any{
+			"id":       "qOqKhntON3vR7kwEbVPsILm7bUViPDzz",
+			"name":     "foo",
+			"presence": "required",
+			"revision": "1",
+		}},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+
+	snaps := []*snapasserts.InstalledSnap{
+		snapasserts.NewInstalledSnap("foo", "qOqKhntON3vR7kwEbVPsILm7bUViPDzz", snap.Revision{N: 1}, nil),
+	}
+
+	// the store is never asked for locally trusted validation sets
+	s.fakeStore.(*fakeStore).snapActionErr = errors.New("store not reachable")
+
+	_, err = assertstate.FetchAndApplyEnforcedValidationSet(st, "on-prem", "fleet", 3, 0, snaps, nil)
+	c.Assert(err, ErrorMatches, `cannot enforce locally trusted validation set on-prem/fleet: validation set assertion must be acknowledged first`)
+
+	// acknowledge the validation set
+	c.Assert(assertstate.Add(st, vsetAs), IsNil)
+
+	tracking, err := assertstate.FetchAndApplyEnforcedValidationSet(st, "on-prem", "fleet", 3, 0, snaps, nil)
+	c.Assert(err, IsNil)
+	c.Check(*tracking, DeepEquals, assertstate.ValidationSetTracking{
+		AccountID: "on-prem",
+		Name:      "fleet",
+		Mode:      assertstate.Enforce,
+		PinnedAt:  3,
+		Current:   3,
+		LocalOnly: true,
+	})
+
+	// constraints are checked
+	_, err = assertstate.FetchAndApplyEnforcedValidationSet(st, "on-prem", "fleet", 3, 0, nil, nil)
+	c.Assert(err, ErrorMatches, `(?s)validation sets assertions are not met:.*missing required snaps:.*foo \(required at revision 1 by sets on-prem/fleet\).*`)
+
+	// and refreshes do not look for it in the store
+	s.fakeStore.(*fakeStore).snapActionErr = nil
+	c.Assert(assertstate.RefreshValidationSetAssertions(st, 0, nil), IsNil)
+	for _, types := range s.fakeStore.(*fakeStore).requestedTypes {
+		c.Check(types, Not(testutil.Contains), "validation-set")
+	}
+	var tr assertstate.ValidationSetTracking
+	c.Assert(assertstate.GetValidationSet(st, "on-prem", "fleet", &tr), IsNil)
+	c.Check(tr.Current, Equals, 3)
+}
+
+func (s *assertMgrSuite) TestLocallyTrustedKeyCannotSignOtherAssertions(c *C) {
+	st := s.state
+
+	st.Lock()
+	defer st.Unlock()
+
+	localKey, _ := assertstest.GenerateKey(752)
+	localSigning := assertstest.NewSigningDB("on-prem", localKey)
+	localAcct := assertstest.NewAccount(localSigning, "on-prem", map[string]any{
+		"account-id": "on-prem",
+	}, "")
+	localAcctKey := assertstest.NewAccountKey(localSigning, localAcct, nil, localKey.PublicKey(), "")
+	c.Assert(os.MkdirAll(dirs.SnapAssertsLocalTrustDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapAssertsLocalTrustDir, "on-prem.assert"), asserts.Encode(localAcctKey), 0644), IsNil)
+	db, err := sysdb.Open()
+	c.Assert(err, IsNil)
+	assertstate.ReplaceDB(st, db)
+
+	otherAcct := assertstest.NewAccount(localSigning, "other", nil, "")
+	err = assertstate.Add(st, otherAcct)
+	c.Check(err, ErrorMatches, `cannot accept account assertion signed by locally trusted key ".*": only validation-set assertions are allowed`)
+}
+
 func (s *assertMgrSuite) TestEnforceValidationSetAssertionUpdate(c *C) {
 	st := s.state
 
@@ -169,6 +169,10 @@ func bulkRefreshValidationSetAsserts(s *state.State, vsets map[string]*Validatio
 	ignoreNotFound := make(map[string]bool)
 
 	for _, vs := range vsets {
+		if db.IsLocallyTrustedAccount(vs.AccountID) {
+			// signed with locally trusted keys, not in the store
+			continue
+		}
 		var atSeq *asserts.AtSequence
 		if vs.PinnedAt > 0 {
 			// pinned to specific sequence, update to latest revision for same
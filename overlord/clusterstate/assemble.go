@@ -0,0 +1,552 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/cluster/discovery"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+	"github.com/snapcore/snapd/randutil"
+)
+
+var assembleClusterChangeKind = swfeats.RegisterChangeKind("assemble-cluster")
+
+var (
+	signWithDeviceKey = devicestate.SignWithDeviceKey
+	assembleListen    = net.Listen
+	discoveryListen   = discovery.Listen
+	newTransport      = func() assemblestate.Transport {
+		return assemblestate.NewHTTPSTransport()
+	}
+)
+
+const (
+	// defaultAssemblePeriod is how often routes are published to peers
+	// and how often the known peers are contacted again.
+	defaultAssemblePeriod = 5 * time.Second
+
+	// defaultDiscoveryAddress is listened on when peers are discovered and
+	// no address is given.
+	defaultDiscoveryAddress = "0.0.0.0:7070"
+)
+
+// AssembleOptions are the parameters of an assemble session.
+type AssembleOptions struct {
+	// Secret is the secret shared by all the devices taking part in the
+	// assemble session. It is kept out of the state, see assembleSecrets.
+	Secret string `json:"-"`
+	// Address is the ip:port address this device listens on for its peers.
+	// It is optional when discovering peers, in which case all the
+	// addresses are listened on.
+	Address string `json:"address"`
+	// ExpectedSize is the number of devices expected in the cluster, the
+	// session finishes once they are all connected. When unset the session
+	// runs until the timeout.
+	ExpectedSize int `json:"expected-size,omitempty"`
+	// Peers are the addresses of peers known upfront.
+	Peers []string `json:"peers,omitempty"`
+	// Discover enables the discovery of peers on the local network with
+	// multicast DNS, in addition to the peers known upfront.
+	Discover bool `json:"discover,omitempty"`
+	// Interface is the network interface peers are discovered on, the
+	// default multicast interface when unset.
+	Interface string `json:"interface,omitempty"`
+	// Subnet restricts the discovered peers to the ones within the given
+	// subnet, in CIDR notation.
+	Subnet string `json:"subnet,omitempty"`
+	// Timeout bounds the duration of the session, it defaults to and
+	// cannot exceed the maximum length of an assemble session.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Period is how often routes are published to the peers.
+	Period time.Duration `json:"period,omitempty"`
+}
+
+// assembleIdentity is the identity of this device in an assemble session, it
+// is generated when the session starts.
+type assembleIdentity struct {
+	RDT     assemblestate.DeviceToken `json:"rdt"`
+	TLSCert []byte                    `json:"tls-cert"`
+	// TLSKey is kept out of the state, see assembleSecrets.
+	TLSKey []byte `json:"-"`
+}
+
+// assembleSecrets are the secrets of an assemble session. They are stored in
+// a file only readable by root rather than in the state, which is written to
+// disk as is.
+type assembleSecrets struct {
+	Secret string `json:"secret"`
+	TLSKey []byte `json:"tls-key,omitempty"`
+}
+
+func assembleSecretsFile(t *state.Task) string {
+	return filepath.Join(dirs.SnapdStateDir(dirs.GlobalRootDir), "cluster", fmt.Sprintf("assemble-%s.json", t.ID()))
+}
+
+func readAssembleSecrets(t *state.Task) (*assembleSecrets, error) {
+	data, err := os.ReadFile(assembleSecretsFile(t))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read assemble secrets: %v", err)
+	}
+	var secrets assembleSecrets
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("cannot decode assemble secrets: %v", err)
+	}
+	return &secrets, nil
+}
+
+func writeAssembleSecrets(t *state.Task, secrets *assembleSecrets) error {
+	path := assembleSecretsFile(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("cannot write assemble secrets: %v", err)
+	}
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	if err := osutil.AtomicWriteFile(path, data, 0600, 0); err != nil {
+		return fmt.Errorf("cannot write assemble secrets: %v", err)
+	}
+	return nil
+}
+
+// lockedAssertDB gives access to the assertion database while the assemble
+// session runs with the state unlocked, by taking the state lock around each
+// access.
+type lockedAssertDB struct {
+	st *state.State
+	db asserts.RODatabase
+}
+
+func (l *lockedAssertDB) IsTrustedAccount(accountID string) bool {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.IsTrustedAccount(accountID)
+}
+
+func (l *lockedAssertDB) WithStackedBackstore(backstore asserts.Backstore) *asserts.Database {
+	l.st.Lock()
+	defer l.st.Unlock()
+	// the backstores of the returned database guard their own accesses
+	return l.db.WithStackedBackstore(backstore)
+}
+
+func (l *lockedAssertDB) Find(assertionType *asserts.AssertionType, headers map[string]string) (asserts.Assertion, error) {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.Find(assertionType, headers)
+}
+
+func (l *lockedAssertDB) FindPredefined(assertionType *asserts.AssertionType, headers map[string]string) (asserts.Assertion, error) {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.FindPredefined(assertionType, headers)
+}
+
+func (l *lockedAssertDB) FindTrusted(assertionType *asserts.AssertionType, headers map[string]string) (asserts.Assertion, error) {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.FindTrusted(assertionType, headers)
+}
+
+func (l *lockedAssertDB) FindMany(assertionType *asserts.AssertionType, headers map[string]string) ([]asserts.Assertion, error) {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.FindMany(assertionType, headers)
+}
+
+func (l *lockedAssertDB) FindManyPredefined(assertionType *asserts.AssertionType, headers map[string]string) ([]asserts.Assertion, error) {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.FindManyPredefined(assertionType, headers)
+}
+
+func (l *lockedAssertDB) FindSequence(assertType *asserts.AssertionType, sequenceHeaders map[string]string, after, maxFormat int) (asserts.SequenceMember, error) {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.FindSequence(assertType, sequenceHeaders, after, maxFormat)
+}
+
+func (l *lockedAssertDB) Check(assert asserts.Assertion) error {
+	l.st.Lock()
+	defer l.st.Unlock()
+	return l.db.Check(assert)
+}
+
+// AssembledCluster is the result of the last successful assemble session.
+type AssembledCluster struct {
+	ChangeID  string    `json:"change-id"`
+	Completed time.Time `json:"completed"`
+	// Devices is suitable for the devices header of a cluster assertion.
+	Devices []any `json:"devices"`
+}
+
+func validateAssembleOptions(opts *AssembleOptions) error {
+	if opts.Secret == "" {
+		return errors.New("cannot assemble a cluster without a secret")
+	}
+	if !opts.Discover && (opts.Interface != "" || opts.Subnet != "") {
+		return errors.New("cannot restrict peer discovery without discovering peers")
+	}
+	if opts.Discover && opts.Address == "" {
+		opts.Address = defaultDiscoveryAddress
+	}
+	if opts.Subnet != "" {
+		if _, _, err := net.ParseCIDR(opts.Subnet); err != nil {
+			return fmt.Errorf("invalid discovery subnet %q: %v", opts.Subnet, err)
+		}
+	}
+	host, port, err := net.SplitHostPort(opts.Address)
+	if err != nil {
+		return fmt.Errorf("invalid assemble address %q: %v", opts.Address, err)
+	}
+	if net.ParseIP(host) == nil || port == "" {
+		return fmt.Errorf("invalid assemble address %q: must be an ip:port pair", opts.Address)
+	}
+	for _, peer := range opts.Peers {
+		if _, _, err := net.SplitHostPort(peer); err != nil {
+			return fmt.Errorf("invalid peer address %q: %v", peer, err)
+		}
+	}
+	if opts.ExpectedSize < 0 {
+		return fmt.Errorf("invalid expected cluster size %d", opts.ExpectedSize)
+	}
+	if opts.Timeout < 0 || opts.Timeout > assemblestate.AssembleSessionLength {
+		return fmt.Errorf("assemble timeout must be positive and at most %v", assemblestate.AssembleSessionLength)
+	}
+	if opts.Period < 0 {
+		return errors.New("assemble period must be positive")
+	}
+	return nil
+}
+
+// Assemble creates a change that runs an assemble session with the devices
+// that share the given secret. Once the session finishes the devices that
+// were found are available from Status, to be used in a cluster assertion.
+// Callers must hold the state lock.
+func Assemble(st *state.State, opts AssembleOptions) (*state.Change, error) {
+	tr := config.NewTransaction(st)
+	enabled, err := features.Flag(tr, features.Clustering)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, errors.New("experimental feature disabled - test it by setting 'experimental.clustering' to true")
+	}
+
+	if err := validateAssembleOptions(&opts); err != nil {
+		return nil, err
+	}
+	if _, err := devicestate.Serial(st); err != nil {
+		return nil, fmt.Errorf("cannot assemble a cluster without a serial: %v", err)
+	}
+
+	for _, chg := range st.Changes() {
+		if chg.Kind() == assembleClusterChangeKind && !chg.Status().Ready() {
+			return nil, &snapstate.ChangeConflictError{
+				Message:    "cluster assembly already in progress",
+				ChangeKind: assembleClusterChangeKind,
+				ChangeID:   chg.ID(),
+			}
+		}
+	}
+
+	t := st.NewTask("assemble-cluster", "Assemble cluster")
+	t.Set("assemble-options", opts)
+	if err := writeAssembleSecrets(t, &assembleSecrets{Secret: opts.Secret}); err != nil {
+		return nil, err
+	}
+
+	chg := st.NewChange(assembleClusterChangeKind, "Assemble cluster")
+	chg.AddTask(t)
+	return chg, nil
+}
+
+func generateAssembleIdentity() (*assembleIdentity, error) {
+	rdt, err := randutil.CryptoToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: rdt},
+		NotBefore:    now,
+		NotAfter:     now.Add(assemblestate.AssembleSessionLength),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, &template, &template, pub, priv)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &assembleIdentity{
+		RDT:     assemblestate.DeviceToken(rdt),
+		TLSCert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}),
+		TLSKey:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}, nil
+}
+
+// publishPeers sends the known peer addresses to the session periodically,
+// so that peers that were not up yet are eventually reached.
+func publishPeers(ctx context.Context, peers []string, period time.Duration, discoveries chan<- []string) {
+	if len(peers) == 0 {
+		return
+	}
+	for {
+		select {
+		case discoveries <- peers:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-time.After(period):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startDiscovery joins the multicast DNS group on which peers are discovered,
+// returning the connection and the configuration to run the discovery with.
+func startDiscovery(opts *AssembleOptions, period time.Duration) (net.PacketConn, discovery.Config, error) {
+	var subnet *net.IPNet
+	if opts.Subnet != "" {
+		var err error
+		if _, subnet, err = net.ParseCIDR(opts.Subnet); err != nil {
+			return nil, discovery.Config{}, fmt.Errorf("invalid discovery subnet %q: %v", opts.Subnet, err)
+		}
+	}
+
+	advertised, err := discovery.AdvertiseAddress(opts.Address, opts.Interface, subnet)
+	if err != nil {
+		return nil, discovery.Config{}, fmt.Errorf("cannot discover peers: %v", err)
+	}
+
+	// the instance name is only used to tell apart the devices announcing
+	// themselves, it is not tied to the identity of the device
+	token, err := randutil.CryptoTokenBytes(8)
+	if err != nil {
+		return nil, discovery.Config{}, err
+	}
+	instance := fmt.Sprintf("snapd-%x", token)
+
+	conn, err := discoveryListen(opts.Interface)
+	if err != nil {
+		return nil, discovery.Config{}, fmt.Errorf("cannot listen for peer discovery: %v", err)
+	}
+
+	return conn, discovery.Config{
+		Instance: instance,
+		Address:  advertised,
+		Subnet:   subnet,
+		Period:   period,
+	}, nil
+}
+
+func (m *ClusterManager) doAssembleCluster(t *state.Task, tb *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var opts AssembleOptions
+	if err := t.Get("assemble-options", &opts); err != nil {
+		return err
+	}
+
+	secrets, err := readAssembleSecrets(t)
+	if err != nil {
+		return err
+	}
+	opts.Secret = secrets.Secret
+
+	var id assembleIdentity
+	if err := t.Get("assemble-identity", &id); err != nil {
+		if !errors.Is(err, state.ErrNoState) {
+			return err
+		}
+		generated, err := generateAssembleIdentity()
+		if err != nil {
+			return fmt.Errorf("cannot generate assemble identity: %v", err)
+		}
+		secrets.TLSKey = generated.TLSKey
+		if err := writeAssembleSecrets(t, secrets); err != nil {
+			return err
+		}
+		id = *generated
+		t.Set("assemble-identity", id)
+	}
+	id.TLSKey = secrets.TLSKey
+
+	var session assemblestate.AssembleSession
+	if err := t.Get("assemble-session", &session); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+
+	serial, err := devicestate.Serial(st)
+	if err != nil {
+		return fmt.Errorf("cannot assemble a cluster without a serial: %v", err)
+	}
+
+	config := assemblestate.AssembleConfig{
+		Secret:       opts.Secret,
+		RDT:          id.RDT,
+		TLSCert:      id.TLSCert,
+		TLSKey:       id.TLSKey,
+		ExpectedSize: opts.ExpectedSize,
+		Serial:       serial,
+		Signer: func(data []byte) ([]byte, error) {
+			st.Lock()
+			defer st.Unlock()
+			return signWithDeviceKey(st, data)
+		},
+	}
+	selector := func(self assemblestate.DeviceToken, identified func(assemblestate.DeviceToken) bool) (assemblestate.RouteSelector, error) {
+		return assemblestate.NewPrioritySelector(self, nil, identified), nil
+	}
+	commit := func(s assemblestate.AssembleSession) {
+		st.Lock()
+		defer st.Unlock()
+		t.Set("assemble-session", s)
+	}
+	db := &lockedAssertDB{st: st, db: assertstate.DB(st)}
+
+	ln, err := assembleListen("tcp", opts.Address)
+	if err != nil {
+		return fmt.Errorf("cannot listen for peers: %v", err)
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = assemblestate.AssembleSessionLength
+	}
+	period := opts.Period
+	if period == 0 {
+		period = defaultAssemblePeriod
+	}
+
+	var discoveryConn net.PacketConn
+	var discoveryConfig discovery.Config
+	if opts.Discover {
+		discoveryConn, discoveryConfig, err = startDiscovery(&opts, period)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+	}
+
+	// the assertion database and the device key are accessed under the
+	// state lock from now on
+	st.Unlock()
+	as, err := assemblestate.NewAssembleState(config, session, selector, commit, db)
+	if err != nil {
+		ln.Close()
+		if discoveryConn != nil {
+			discoveryConn.Close()
+		}
+		st.Lock()
+		return fmt.Errorf("cannot start assemble session: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(tb.Context(context.Background()), timeout)
+	discoveries := make(chan []string)
+	go publishPeers(ctx, opts.Peers, period, discoveries)
+	if discoveryConn != nil {
+		go func() {
+			// the session goes on with the peers known upfront if
+			// discovery fails
+			if err := discovery.Run(ctx, discoveryConn, discoveryConfig, discoveries); err != nil {
+				logger.Noticef("cannot discover assemble peers: %v", err)
+			}
+		}()
+	}
+	ids, routes, err := as.Run(ctx, ln, newTransport(), discoveries, assemblestate.RunOptions{
+		Period: period,
+	})
+	cancel()
+	st.Lock()
+
+	if !tb.Alive() {
+		// the session is resumed from what was committed so far
+		return &state.Retry{}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot assemble cluster: %v", err)
+	}
+
+	devices, err := assemblestate.AssertionDevices(ids, routes)
+	if err != nil {
+		return fmt.Errorf("cannot assemble cluster: %v", err)
+	}
+
+	t.Logf("Assembled cluster of %d devices", len(devices))
+	st.Set("cluster-assembled", AssembledCluster{
+		ChangeID:  t.Change().ID(),
+		Completed: time.Now(),
+		Devices:   devices,
+	})
+	return nil
+}
+
+func (m *ClusterManager) cleanupAssembleCluster(t *state.Task, _ *tomb.Tomb) error {
+	if err := os.Remove(assembleSecretsFile(t)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
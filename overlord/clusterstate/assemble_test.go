@@ -0,0 +1,441 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type assembleSuite struct {
+	testutil.BaseTest
+
+	st     *state.State
+	runner *state.TaskRunner
+
+	listened []string
+}
+
+var _ = check.Suite(&assembleSuite{})
+
+func (s *assembleSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	st, stack := newStateWithStoreStack(c)
+	s.st = st
+	s.runner = state.NewTaskRunner(st)
+	clusterstate.Manager(st, s.runner)
+	s.listened = nil
+
+	deviceKey, _ := assertstest.GenerateKey(752)
+	encodedKey, err := asserts.EncodePublicKey(deviceKey.PublicKey())
+	c.Assert(err, check.IsNil)
+	a, err := stack.Sign(asserts.SerialType, map[string]any{
+		"authority-id":        "canonical",
+		"brand-id":            "canonical",
+		"model":               "ubuntu-core-24-amd64",
+		"serial":              "serial-1",
+		"device-key":          string(encodedKey),
+		"device-key-sha3-384": deviceKey.PublicKey().ID(),
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, check.IsNil)
+
+	st.Lock()
+	addSerialToState(c, st, a.(*asserts.Serial))
+	st.Unlock()
+
+	restore := clusterstate.MockSignWithDeviceKey(func(_ *state.State, data []byte) ([]byte, error) {
+		return asserts.RawSignWithKey(data, deviceKey)
+	})
+	s.AddCleanup(restore)
+	restore = clusterstate.MockAssembleListen(func(network, address string) (net.Listener, error) {
+		s.listened = append(s.listened, address)
+		return net.Listen(network, "127.0.0.1:0")
+	})
+	s.AddCleanup(restore)
+	restore = clusterstate.MockNewTransport(func() assemblestate.Transport {
+		return &idleTransport{}
+	})
+	s.AddCleanup(restore)
+}
+
+func (s *assembleSuite) TearDownTest(c *check.C) {
+	s.runner.Stop()
+	s.BaseTest.TearDownTest(c)
+}
+
+// idleTransport is a transport that never reaches any peer.
+type idleTransport struct{}
+
+func (t *idleTransport) Serve(ctx context.Context, ln net.Listener, cert tls.Certificate, pa assemblestate.PeerAuthenticator) error {
+	<-ctx.Done()
+	return ln.Close()
+}
+
+func (t *idleTransport) NewClient(cert tls.Certificate) assemblestate.Client {
+	return &idleClient{}
+}
+
+func (t *idleTransport) Stats() assemblestate.TransportStats {
+	return assemblestate.TransportStats{}
+}
+
+type idleClient struct{}
+
+func (c *idleClient) Trusted(ctx context.Context, addr string, fp assemblestate.Fingerprint, kind string, message any) error {
+	return errors.New("unreachable")
+}
+
+func (c *idleClient) Untrusted(ctx context.Context, addr string, kind string, message any) (assemblestate.Fingerprint, error) {
+	return assemblestate.Fingerprint{}, errors.New("unreachable")
+}
+
+func (s *assembleSuite) TestAssembleValidation(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	for _, tc := range []struct {
+		opts clusterstate.AssembleOptions
+		err  string
+	}{
+		{clusterstate.AssembleOptions{Address: "10.0.0.1:8001"}, "cannot assemble a cluster without a secret"},
+		{clusterstate.AssembleOptions{Secret: "s"}, `invalid assemble address "": .*`},
+		{clusterstate.AssembleOptions{Secret: "s", Address: "host:8001"}, `invalid assemble address "host:8001": must be an ip:port pair`},
+		{clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001", Peers: []string{"10.0.0.2"}}, `invalid peer address "10.0.0.2": .*`},
+		{clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001", ExpectedSize: -1}, "invalid expected cluster size -1"},
+		{clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001", Timeout: 2 * time.Hour}, "assemble timeout must be positive and at most 1h0m0s"},
+		{clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001", Period: -time.Second}, "assemble period must be positive"},
+		{clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001", Interface: "eth0"}, "cannot restrict peer discovery without discovering peers"},
+		{clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001", Subnet: "10.0.0.0/24"}, "cannot restrict peer discovery without discovering peers"},
+		{clusterstate.AssembleOptions{Secret: "s", Discover: true, Subnet: "10.0.0.0"}, `invalid discovery subnet "10.0.0.0": .*`},
+	} {
+		_, err := clusterstate.Assemble(s.st, tc.opts)
+		c.Check(err, check.ErrorMatches, tc.err, check.Commentf("%+v", tc.opts))
+	}
+	c.Check(s.st.Changes(), check.HasLen, 0)
+}
+
+func (s *assembleSuite) TestAssembleClusteringDisabled(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	tr := config.NewTransaction(s.st)
+	c.Assert(tr.Set("core", "experimental.clustering", false), check.IsNil)
+	tr.Commit()
+
+	_, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001"})
+	c.Check(err, check.ErrorMatches, `experimental feature disabled - test it by setting 'experimental.clustering' to true`)
+}
+
+func (s *assembleSuite) TestAssembleConflict(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	opts := clusterstate.AssembleOptions{Secret: "s", Address: "10.0.0.1:8001"}
+	chg, err := clusterstate.Assemble(s.st, opts)
+	c.Assert(err, check.IsNil)
+	c.Check(chg.Kind(), check.Equals, "assemble-cluster")
+	c.Assert(chg.Tasks(), check.HasLen, 1)
+
+	// the secret is not kept in the state
+	var stored clusterstate.AssembleOptions
+	c.Assert(chg.Tasks()[0].Get("assemble-options", &stored), check.IsNil)
+	c.Check(stored, check.DeepEquals, clusterstate.AssembleOptions{Address: "10.0.0.1:8001"})
+
+	_, err = clusterstate.Assemble(s.st, opts)
+	var conflict *snapstate.ChangeConflictError
+	c.Assert(errors.As(err, &conflict), check.Equals, true)
+	c.Check(conflict.ChangeID, check.Equals, chg.ID())
+	c.Check(err, check.ErrorMatches, "cluster assembly already in progress")
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.AssembleChange, check.Equals, chg.ID())
+}
+
+func (s *assembleSuite) TestDoAssembleClusterNoPeers(c *check.C) {
+	s.st.Lock()
+	chg, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:  "secret",
+		Address: "10.0.0.1:8001",
+		Timeout: 50 * time.Millisecond,
+		Period:  10 * time.Millisecond,
+	})
+	c.Assert(err, check.IsNil)
+	s.st.Unlock()
+
+	s.runner.Ensure()
+	s.runner.Wait()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	c.Check(s.listened, check.DeepEquals, []string{"10.0.0.1:8001"})
+	c.Check(chg.Status(), check.Equals, state.ErrorStatus)
+	c.Check(chg.Err(), check.ErrorMatches, `(?s).*cannot assemble cluster: no addresses available for device .*`)
+
+	// the identity is kept for when the session is resumed, but not its
+	// private key
+	var id map[string]any
+	c.Assert(chg.Tasks()[0].Get("assemble-identity", &id), check.IsNil)
+	c.Check(id["rdt"], check.Not(check.Equals), "")
+	c.Check(id["tls-cert"], check.NotNil)
+	c.Check(id["tls-key"], check.IsNil)
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.AssembleChange, check.Equals, "")
+	c.Check(status.Assembled, check.IsNil)
+}
+
+func (s *assembleSuite) TestAssembleSecretsKeptOutOfState(c *check.C) {
+	restore := clusterstate.MockAssembleListen(func(network, address string) (net.Listener, error) {
+		return nil, errors.New("address in use")
+	})
+	defer restore()
+
+	s.st.Lock()
+	chg, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:  "very-secret",
+		Address: "10.0.0.1:8001",
+	})
+	c.Assert(err, check.IsNil)
+	t := chg.Tasks()[0]
+	s.st.Unlock()
+
+	secretsFile := filepath.Join(dirs.SnapdStateDir(dirs.GlobalRootDir), "cluster", fmt.Sprintf("assemble-%s.json", t.ID()))
+	fi, err := os.Stat(secretsFile)
+	c.Assert(err, check.IsNil)
+	c.Check(fi.Mode().Perm(), check.Equals, os.FileMode(0600))
+	c.Check(secretsFile, testutil.FileContains, `"secret":"very-secret"`)
+
+	s.runner.Ensure()
+	s.runner.Wait()
+
+	s.st.Lock()
+	c.Check(chg.Status(), check.Equals, state.ErrorStatus)
+	data, err := json.Marshal(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Not(testutil.Contains), "very-secret")
+	c.Check(string(data), check.Not(testutil.Contains), "PRIVATE KEY")
+	s.st.Unlock()
+
+	// the secrets are removed once the change is ready
+	s.runner.Ensure()
+	s.runner.Wait()
+	c.Check(secretsFile, testutil.FileAbsent)
+}
+
+func (s *assembleSuite) TestDoAssembleClusterListenError(c *check.C) {
+	restore := clusterstate.MockAssembleListen(func(network, address string) (net.Listener, error) {
+		return nil, errors.New("address in use")
+	})
+	defer restore()
+
+	s.st.Lock()
+	chg, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:  "secret",
+		Address: "10.0.0.1:8001",
+	})
+	c.Assert(err, check.IsNil)
+	s.st.Unlock()
+
+	s.runner.Ensure()
+	s.runner.Wait()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	c.Check(chg.Status(), check.Equals, state.ErrorStatus)
+	c.Check(chg.Err(), check.ErrorMatches, `(?s).*cannot listen for peers: address in use.*`)
+}
+
+func (s *assembleSuite) TestAssembleDiscoverDefaultAddress(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	chg, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{Secret: "s", Discover: true})
+	c.Assert(err, check.IsNil)
+
+	var stored clusterstate.AssembleOptions
+	c.Assert(chg.Tasks()[0].Get("assemble-options", &stored), check.IsNil)
+	c.Check(stored, check.DeepEquals, clusterstate.AssembleOptions{
+		Address:  "0.0.0.0:7070",
+		Discover: true,
+	})
+}
+
+// recordingConn is a multicast connection that never receives anything and
+// records what is sent.
+type recordingConn struct {
+	lock   sync.Mutex
+	sent   [][]byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (r *recordingConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	<-r.closed
+	return 0, nil, net.ErrClosed
+}
+
+func (r *recordingConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sent = append(r.sent, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (r *recordingConn) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+func (r *recordingConn) LocalAddr() net.Addr                { return &net.UDPAddr{} }
+func (r *recordingConn) SetDeadline(t time.Time) error      { return nil }
+func (r *recordingConn) SetReadDeadline(t time.Time) error  { return nil }
+func (r *recordingConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func (s *assembleSuite) TestDoAssembleClusterDiscovery(c *check.C) {
+	conn := &recordingConn{closed: make(chan struct{})}
+	var ifaces []string
+	restore := clusterstate.MockDiscoveryListen(func(iface string) (net.PacketConn, error) {
+		ifaces = append(ifaces, iface)
+		return conn, nil
+	})
+	defer restore()
+
+	s.st.Lock()
+	chg, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:   "secret",
+		Address:  "10.0.0.1:8001",
+		Discover: true,
+		Subnet:   "10.0.0.0/24",
+		Timeout:  50 * time.Millisecond,
+		Period:   10 * time.Millisecond,
+	})
+	c.Assert(err, check.IsNil)
+	s.st.Unlock()
+
+	s.runner.Ensure()
+	s.runner.Wait()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	// nobody answered
+	c.Check(chg.Err(), check.ErrorMatches, `(?s).*cannot assemble cluster: no addresses available for device .*`)
+	c.Check(ifaces, check.DeepEquals, []string{""})
+
+	// the listener was announced until the session stopped
+	select {
+	case <-conn.closed:
+	case <-time.After(5 * time.Second):
+		c.Fatal("discovery connection not closed")
+	}
+	conn.lock.Lock()
+	defer conn.lock.Unlock()
+	c.Assert(len(conn.sent) > 0, check.Equals, true)
+	c.Check(bytes.Contains(conn.sent[0], []byte("_snapd-assemble")), check.Equals, true)
+	c.Check(bytes.Contains(conn.sent[0], []byte{10, 0, 0, 1}), check.Equals, true)
+}
+
+func (s *assembleSuite) TestDoAssembleClusterDiscoveryErrors(c *check.C) {
+	restore := clusterstate.MockDiscoveryListen(func(iface string) (net.PacketConn, error) {
+		c.Check(iface, check.Equals, "eth0")
+		return nil, errors.New("no multicast")
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		opts clusterstate.AssembleOptions
+		err  string
+	}{{
+		opts: clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:8001", Discover: true, Interface: "eth0"},
+		err:  "cannot listen for peer discovery: no multicast",
+	}, {
+		opts: clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:8001", Discover: true, Subnet: "192.168.0.0/24"},
+		err:  "cannot discover peers: cannot advertise address 10.0.0.1 outside of subnet 192.168.0.0/24",
+	}} {
+		s.st.Lock()
+		chg, err := clusterstate.Assemble(s.st, tc.opts)
+		c.Assert(err, check.IsNil)
+		s.st.Unlock()
+
+		s.runner.Ensure()
+		s.runner.Wait()
+
+		s.st.Lock()
+		c.Check(chg.Status(), check.Equals, state.ErrorStatus)
+		c.Check(chg.Err(), check.ErrorMatches, `(?s).*`+tc.err+`.*`)
+		s.st.Unlock()
+	}
+}
+
+func (s *assembleSuite) TestClusterStatus(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status, check.DeepEquals, &clusterstate.Status{})
+
+	completed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.st.Set("cluster-assembled", clusterstate.AssembledCluster{
+		ChangeID:  "7",
+		Completed: completed,
+		Devices: []any{map[string]any{
+			"id":        "1",
+			"device":    "serial-1.ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"10.0.0.1:8001"},
+		}},
+	})
+
+	status, err = clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Assembled, check.NotNil)
+	c.Check(status.Assembled.ChangeID, check.Equals, "7")
+	c.Check(status.Assembled.Completed.Equal(completed), check.Equals, true)
+	c.Check(status.Assembled.Devices, check.HasLen, 1)
+}
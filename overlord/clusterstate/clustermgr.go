@@ -22,6 +22,7 @@ package clusterstate
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/overlord/configstate/config"
@@ -31,19 +32,29 @@ import (
 
 var applyClusterSubclusterChangeKind = swfeats.RegisterChangeKind("apply-cluster-subcluster")
 
+var timeNow = time.Now
+
 type ClusterManager struct {
 	state *state.State
 }
 
 // Manager returns a new ClusterManager.
-func Manager(st *state.State) *ClusterManager {
-	return &ClusterManager{
+func Manager(st *state.State, runner *state.TaskRunner) *ClusterManager {
+	m := &ClusterManager{
 		state: st,
 	}
+
+	runner.AddHandler("assemble-cluster", m.doAssembleCluster, nil)
+	runner.AddCleanup("assemble-cluster", m.cleanupAssembleCluster)
+
+	return m
 }
 
 // Ensure ensures that the device state matches the expectations defined by the
-// cluster assertion.
+// cluster assertion. The result of the comparison is recorded as the
+// compliance of the device. Once the state of an assertion has been applied,
+// later drift is either corrected or only reported as warnings, depending on
+// the cluster.drift option.
 func (m *ClusterManager) Ensure() error {
 	enabled, err := clusteringEnabled(m.state)
 	if err != nil {
@@ -65,19 +76,73 @@ func (m *ClusterManager) Ensure() error {
 		return fmt.Errorf("cannot get cluster assertion: %w", err)
 	}
 
-	tasksets, err := applyClusterState(m.state, cluster)
+	plans, err := planClusterState(m.state, cluster)
+	if err != nil {
+		return err
+	}
+
+	var drift []Drift
+	for _, plan := range plans {
+		drift = append(drift, plan.drift...)
+	}
+
+	previous, err := compliance(m.state)
 	if err != nil {
 		return err
 	}
+	added := newDrift(previous, cluster.ClusterID(), cluster.Sequence(), drift)
+	// only record the result when it changes, the comparison is done on
+	// every pass
+	changed := previous == nil || previous.ClusterID != cluster.ClusterID() ||
+		previous.Sequence != cluster.Sequence() ||
+		len(added) > 0 || len(previous.Drift) != len(drift)
+	if changed {
+		m.state.Set("cluster-compliance", Compliance{
+			ClusterID: cluster.ClusterID(),
+			Sequence:  cluster.Sequence(),
+			Checked:   timeNow(),
+			Drift:     drift,
+		})
+	}
 
-	if len(tasksets) == 0 {
+	var cs clusterState
+	if err := m.state.Get("cluster", &cs); err != nil {
+		return err
+	}
+
+	if len(drift) == 0 {
+		if cs.Applied == nil || *cs.Applied != cs.Current {
+			current := cs.Current
+			cs.Applied = &current
+			m.state.Set("cluster", cs)
+		}
+		return nil
+	}
+
+	policy, err := driftPolicy(m.state)
+	if err != nil {
+		return err
+	}
+
+	// the state described by a new cluster assertion is always applied,
+	// drift from a state that was already applied is only corrected if
+	// requested
+	if policy == DriftPolicyReport && cs.Applied != nil && *cs.Applied == cs.Current {
+		// drift that was already reported is not warned about again
+		for _, d := range added {
+			m.state.Warnf("cluster %q: %s", cluster.ClusterID(), d)
+		}
 		return nil
 	}
 
 	clusterChanges := inProgressClusterChanges(m.state)
 
-	for name, tasks := range tasksets {
-		ref := clusterChangeRef{ClusterID: cluster.ClusterID(), Subcluster: name}
+	for _, plan := range plans {
+		if plan.empty() {
+			continue
+		}
+
+		ref := clusterChangeRef{ClusterID: cluster.ClusterID(), Subcluster: plan.name}
 
 		// if we already have a change going on for this cluster id/subcluster
 		// pair, do not create another one
@@ -85,10 +150,15 @@ func (m *ClusterManager) Ensure() error {
 			continue
 		}
 
-		chg := m.state.NewChange(applyClusterSubclusterChangeKind, fmt.Sprintf("Apply subcluster %q state", name))
+		ts, err := applySubcluster(m.state, plan)
+		if err != nil {
+			return err
+		}
+
+		chg := m.state.NewChange(applyClusterSubclusterChangeKind, fmt.Sprintf("Apply subcluster %q state", plan.name))
 		chg.Set("cluster-change-ref", ref)
 
-		chg.AddAll(tasks)
+		chg.AddAll(ts)
 	}
 
 	return nil
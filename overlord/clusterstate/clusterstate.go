@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 
 	"github.com/snapcore/snapd/asserts"
@@ -53,6 +54,11 @@ type clusterState struct {
 	// assertion. Maybe we should consider some sort of sequence container, like
 	// we use in snapstate?
 	Current clusterAssertionState `json:"current"`
+	// Applied is set once the snaps of this device matched the state
+	// described by the current cluster assertion. From then on, any
+	// difference is drift and handled according to the cluster.drift
+	// option.
+	Applied *clusterAssertionState `json:"applied,omitempty"`
 }
 
 // clusterAssertionState contains the information needed to find a specific
@@ -195,6 +201,79 @@ func CurrentCluster(st *state.State) (*asserts.Cluster, error) {
 	return cluster, nil
 }
 
+// Status describes the clustering state of the device.
+type Status struct {
+	// ClusterID and Sequence identify the current cluster assertion, if
+	// any.
+	ClusterID string
+	Sequence  int
+	// DeviceID is the ID of this device in the cluster assertion, 0 when
+	// the device is not part of the cluster.
+	DeviceID int
+	// Subclusters are the subclusters this device is part of.
+	Subclusters []string
+	// ApplyChanges are the IDs of the changes applying the state of the
+	// subclusters that are in progress.
+	ApplyChanges []string
+	// AssembleChange is the ID of the assemble session in progress, if any.
+	AssembleChange string
+	// Assembled is the result of the last assemble session, if any.
+	Assembled *AssembledCluster
+	// Compliance is the result of the last comparison of the snaps of this
+	// device with the state expected by its subclusters, if any.
+	Compliance *Compliance
+}
+
+// ClusterStatus returns the clustering state of the device. Callers must hold
+// the state lock.
+func ClusterStatus(st *state.State) (*Status, error) {
+	var status Status
+
+	cluster, err := CurrentCluster(st)
+	if err != nil && !errors.Is(err, ErrNoClusterAssertion) {
+		return nil, err
+	}
+	if cluster != nil {
+		status.ClusterID = cluster.ClusterID()
+		status.Sequence = cluster.Sequence()
+		if serial, err := devicestate.Serial(st); err == nil {
+			status.DeviceID, _ = clusterDeviceIDBySerial(cluster, serial.Serial())
+		}
+		for _, subcluster := range cluster.Subclusters() {
+			if status.DeviceID != 0 && deviceInSubcluster(subcluster, status.DeviceID) {
+				status.Subclusters = append(status.Subclusters, subcluster.Name)
+			}
+		}
+	}
+
+	for _, chg := range st.Changes() {
+		if chg.Status().Ready() {
+			continue
+		}
+		switch chg.Kind() {
+		case applyClusterSubclusterChangeKind:
+			status.ApplyChanges = append(status.ApplyChanges, chg.ID())
+		case assembleClusterChangeKind:
+			status.AssembleChange = chg.ID()
+		}
+	}
+	sort.Strings(status.ApplyChanges)
+
+	var assembled AssembledCluster
+	if err := st.Get("cluster-assembled", &assembled); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	} else if err == nil {
+		status.Assembled = &assembled
+	}
+
+	status.Compliance, err = compliance(st)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
 func decodeClusterBundle(bundle io.Reader) (*asserts.Batch, *asserts.Cluster, error) {
 	var cluster *asserts.Cluster
 	batch := asserts.NewBatch(nil)
@@ -230,9 +309,23 @@ func decodeClusterBundle(bundle io.Reader) (*asserts.Batch, *asserts.Cluster, er
 	return batch, cluster, nil
 }
 
-// applyClusterState creates the tasks needed to apply the state described by
-// the cluster assertion on this device.
-func applyClusterState(st *state.State, cluster *asserts.Cluster) (map[string]*state.TaskSet, error) {
+// subclusterPlan contains the actions needed to bring the snaps of this device
+// to the state expected by a subcluster, and the drift they correct.
+type subclusterPlan struct {
+	name     string
+	installs []snapstate.StoreSnap
+	removals []string
+	updates  []snapstate.StoreUpdate
+	drift    []Drift
+}
+
+func (p *subclusterPlan) empty() bool {
+	return len(p.installs) == 0 && len(p.removals) == 0 && len(p.updates) == 0
+}
+
+// planClusterState compares the snaps of this device with the state described
+// by the cluster assertion for each of the subclusters the device is part of.
+func planClusterState(st *state.State, cluster *asserts.Cluster) ([]*subclusterPlan, error) {
 	serial, err := devicestate.Serial(st)
 	if err != nil {
 		return nil, err
@@ -243,36 +336,27 @@ func applyClusterState(st *state.State, cluster *asserts.Cluster) (map[string]*s
 		return nil, fmt.Errorf("device with serial %q not found in cluster assertion", serial.Serial())
 	}
 
-	// mapping of subcluster name to tasks to match desired subcluster state
-	tasksets := make(map[string]*state.TaskSet)
+	var plans []*subclusterPlan
 	for _, subcluster := range cluster.Subclusters() {
 		if !deviceInSubcluster(subcluster, deviceID) {
 			continue
 		}
 
-		ts, err := applySubcluster(st, subcluster)
+		plan, err := planSubcluster(st, subcluster)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(ts.Tasks()) == 0 {
-			continue
-		}
-
-		tasksets[subcluster.Name] = ts
+		plans = append(plans, plan)
 	}
 
-	return tasksets, nil
+	return plans, nil
 }
 
-func applySubcluster(st *state.State, subcluster asserts.Subcluster) (*state.TaskSet, error) {
-	installs, removals, updates, err := snapsForSubcluster(st, subcluster)
-	if err != nil {
-		return nil, err
-	}
-
+// applySubcluster creates the tasks needed to carry out the given plan.
+func applySubcluster(st *state.State, plan *subclusterPlan) (*state.TaskSet, error) {
 	combined := state.NewTaskSet()
-	if len(installs) == 0 && len(removals) == 0 && len(updates) == 0 {
+	if plan.empty() {
 		return combined, nil
 	}
 
@@ -284,9 +368,9 @@ func applySubcluster(st *state.State, subcluster asserts.Subcluster) (*state.Tas
 		}
 	}
 
-	if len(removals) > 0 {
+	if len(plan.removals) > 0 {
 		// TODO: handle conflict errors from remove
-		_, removeTS, err := removeMany(st, removals, &snapstate.RemoveFlags{})
+		_, removeTS, err := removeMany(st, plan.removals, &snapstate.RemoveFlags{})
 		if err != nil {
 			return nil, fmt.Errorf("cannot create snap removal tasks: %w", err)
 		}
@@ -294,11 +378,11 @@ func applySubcluster(st *state.State, subcluster asserts.Subcluster) (*state.Tas
 		appendTaskSets(removeTS)
 	}
 
-	if len(updates) > 0 {
+	if len(plan.updates) > 0 {
 		// TODO: handle busy snap errors here (potentially just do a switch in
 		// that case?)
 		// TODO: handle conflict errors from refresh
-		goal := storeUpdateGoal(updates...)
+		goal := storeUpdateGoal(plan.updates...)
 		_, updateTS, err := updateWithGoal(context.Background(), st, goal, nil, snapstate.Options{})
 		if err != nil {
 			return nil, fmt.Errorf("cannot create snap update tasks: %w", err)
@@ -307,8 +391,8 @@ func applySubcluster(st *state.State, subcluster asserts.Subcluster) (*state.Tas
 		appendTaskSets(updateTS.Refresh)
 	}
 
-	if len(installs) > 0 {
-		goal := storeInstallGoal(installs...)
+	if len(plan.installs) > 0 {
+		goal := storeInstallGoal(plan.installs...)
 		_, installTS, err := installWithGoal(context.Background(), st, goal, snapstate.Options{})
 		if err != nil {
 			return nil, fmt.Errorf("cannot create snap installation tasks: %w", err)
@@ -320,18 +404,17 @@ func applySubcluster(st *state.State, subcluster asserts.Subcluster) (*state.Tas
 	return combined, nil
 }
 
-func snapsForSubcluster(
-	st *state.State, subcluster asserts.Subcluster,
-) (
-	installs []snapstate.StoreSnap,
-	removals []string,
-	updates []snapstate.StoreUpdate,
-	err error,
-) {
+// planSubcluster compares the snaps of this device with the ones expected by
+// the subcluster.
+//
+// TODO: the cluster assertion does not carry snap revisions yet, so only
+// missing, present and channel drift can be detected.
+func planSubcluster(st *state.State, subcluster asserts.Subcluster) (*subclusterPlan, error) {
+	plan := &subclusterPlan{name: subcluster.Name}
 	for _, sn := range subcluster.Snaps {
 		var snapst snapstate.SnapState
 		if err := snapstate.Get(st, sn.Instance, &snapst); err != nil && !errors.Is(err, state.ErrNoState) {
-			return nil, nil, nil, err
+			return nil, err
 		}
 
 		// TODO: handle [asserts.ClusterSnapStateEvacuated]
@@ -339,12 +422,19 @@ func snapsForSubcluster(
 		case asserts.ClusterSnapStateClustered:
 			if snapst.IsInstalled() {
 				if sn.Channel != "" && snapst.TrackingChannel != sn.Channel {
-					updates = append(updates, snapstate.StoreUpdate{
+					plan.updates = append(plan.updates, snapstate.StoreUpdate{
 						InstanceName: sn.Instance,
 						RevOpts: snapstate.RevisionOptions{
 							Channel: sn.Channel,
 						},
 					})
+					plan.drift = append(plan.drift, Drift{
+						Subcluster: subcluster.Name,
+						Snap:       sn.Instance,
+						Kind:       DriftChannel,
+						Expected:   sn.Channel,
+						Current:    snapst.TrackingChannel,
+					})
 				}
 				continue
 			}
@@ -357,16 +447,26 @@ func snapsForSubcluster(
 				},
 			}
 
-			installs = append(installs, ss)
+			plan.installs = append(plan.installs, ss)
+			plan.drift = append(plan.drift, Drift{
+				Subcluster: subcluster.Name,
+				Snap:       sn.Instance,
+				Kind:       DriftMissing,
+			})
 		case asserts.ClusterSnapStateRemoved:
 			if !snapst.IsInstalled() {
 				continue
 			}
-			removals = append(removals, sn.Instance)
+			plan.removals = append(plan.removals, sn.Instance)
+			plan.drift = append(plan.drift, Drift{
+				Subcluster: subcluster.Name,
+				Snap:       sn.Instance,
+				Kind:       DriftPresent,
+			})
 		}
 	}
 
-	return installs, removals, updates, nil
+	return plan, nil
 }
 
 func deviceInSubcluster(subcluster asserts.Subcluster, deviceID int) bool {
@@ -0,0 +1,138 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// DriftKind describes how the state of a snap differs from the state
+// expected by a subcluster.
+type DriftKind string
+
+const (
+	// DriftMissing is a snap that should be installed but is not.
+	DriftMissing DriftKind = "missing"
+	// DriftChannel is a snap that tracks a different channel than the one
+	// expected.
+	DriftChannel DriftKind = "channel"
+	// DriftPresent is a snap that should be removed but is installed.
+	DriftPresent DriftKind = "present"
+)
+
+// Drift describes a snap of this device that differs from the state expected
+// by one of its subclusters.
+type Drift struct {
+	Subcluster string    `json:"subcluster"`
+	Snap       string    `json:"snap"`
+	Kind       DriftKind `json:"kind"`
+	// Expected and Current are the expected and current channels of
+	// DriftChannel drift.
+	Expected string `json:"expected,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+func (d Drift) String() string {
+	switch d.Kind {
+	case DriftMissing:
+		return fmt.Sprintf("snap %q of subcluster %q is not installed", d.Snap, d.Subcluster)
+	case DriftChannel:
+		return fmt.Sprintf("snap %q of subcluster %q tracks channel %q instead of %q", d.Snap, d.Subcluster, d.Current, d.Expected)
+	case DriftPresent:
+		return fmt.Sprintf("snap %q removed from subcluster %q is installed", d.Snap, d.Subcluster)
+	}
+	return fmt.Sprintf("snap %q of subcluster %q has drifted (%s)", d.Snap, d.Subcluster, d.Kind)
+}
+
+// Compliance is the result of the last comparison of the snaps of this
+// device with the state expected by its subclusters. Checked is when that
+// result was first found, it is not updated while the result stays the same.
+type Compliance struct {
+	ClusterID string    `json:"cluster-id"`
+	Sequence  int       `json:"sequence"`
+	Checked   time.Time `json:"checked"`
+	Drift     []Drift   `json:"drift,omitempty"`
+}
+
+// Compliant returns whether the snaps of the device matched the expected
+// state.
+func (c *Compliance) Compliant() bool {
+	return len(c.Drift) == 0
+}
+
+// newDrift returns the drift that was not found by the previous comparison
+// with the state expected by the same cluster assertion, if any.
+func newDrift(previous *Compliance, clusterID string, sequence int, drift []Drift) []Drift {
+	if previous == nil || previous.ClusterID != clusterID || previous.Sequence != sequence {
+		return drift
+	}
+	var added []Drift
+	for _, d := range drift {
+		known := false
+		for _, p := range previous.Drift {
+			if p == d {
+				known = true
+				break
+			}
+		}
+		if !known {
+			added = append(added, d)
+		}
+	}
+	return added
+}
+
+const (
+	// DriftPolicyCorrect corrects any drift from the state expected by the
+	// subclusters of the device.
+	DriftPolicyCorrect = "correct"
+	// DriftPolicyReport only reports drift, once the state expected by the
+	// current cluster assertion has been applied.
+	DriftPolicyReport = "report"
+)
+
+// driftPolicy returns the value of the cluster.drift system option.
+func driftPolicy(st *state.State) (string, error) {
+	tr := config.NewTransaction(st)
+	var policy string
+	if err := tr.Get("core", "cluster.drift", &policy); err != nil && !config.IsNoOption(err) {
+		return "", err
+	}
+	if policy == "" {
+		return DriftPolicyCorrect, nil
+	}
+	return policy, nil
+}
+
+func compliance(st *state.State) (*Compliance, error) {
+	var c Compliance
+	if err := st.Get("cluster-compliance", &c); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
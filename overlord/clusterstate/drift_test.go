@@ -0,0 +1,311 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/sequence"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type driftSuite struct {
+	testutil.BaseTest
+
+	st    *state.State
+	stack *assertstest.StoreStack
+	sa    *assertstest.SigningAccounts
+	mgr   *clusterstate.ClusterManager
+	now   time.Time
+
+	installs []snapstate.StoreSnap
+	updates  []snapstate.StoreUpdate
+	removals []string
+}
+
+var _ = check.Suite(&driftSuite{})
+
+func (s *driftSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+
+	s.st, s.stack = newStateWithStoreStack(c)
+	s.installs, s.updates, s.removals = nil, nil, nil
+
+	s.now = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.AddCleanup(clusterstate.MockTimeNow(func() time.Time { return s.now }))
+
+	s.AddCleanup(clusterstate.MockStoreInstallGoal(func(snaps ...snapstate.StoreSnap) snapstate.InstallGoal {
+		s.installs = append(s.installs, snaps...)
+		return snapstate.StoreInstallGoal(snaps...)
+	}))
+	s.AddCleanup(clusterstate.MockStoreUpdateGoal(func(upds ...snapstate.StoreUpdate) snapstate.UpdateGoal {
+		s.updates = append(s.updates, upds...)
+		return snapstate.StoreUpdateGoal(upds...)
+	}))
+	s.AddCleanup(clusterstate.MockInstallWithGoal(func(ctx context.Context, st *state.State, goal snapstate.InstallGoal, opts snapstate.Options) ([]*snap.Info, []*state.TaskSet, error) {
+		return nil, []*state.TaskSet{state.NewTaskSet(st.NewTask("install", "install snaps"))}, nil
+	}))
+	s.AddCleanup(clusterstate.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, goal snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		return nil, &snapstate.UpdateTaskSets{
+			Refresh: []*state.TaskSet{state.NewTaskSet(st.NewTask("update", "update snaps"))},
+		}, nil
+	}))
+	s.AddCleanup(clusterstate.MockRemoveMany(func(st *state.State, names []string, flags *snapstate.RemoveFlags) ([]string, []*state.TaskSet, error) {
+		s.removals = append(s.removals, names...)
+		return names, []*state.TaskSet{state.NewTaskSet(st.NewTask("remove", "remove snaps"))}, nil
+	}))
+
+	s.sa = registerAccount(s.stack, "cluster-brand")
+	bundle, _ := makeClusterBundleWithSigning(c, s.sa, "cluster-brand", "cluster-id", 1, []map[string]any{
+		{
+			"id":        "1",
+			"device":    "serial-1.ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"192.168.0.10"},
+		},
+	}, []map[string]any{{
+		"name":    "default",
+		"devices": []any{"1"},
+		"snaps": []any{
+			map[string]any{
+				"state":    "clustered",
+				"instance": "snap-one",
+				"channel":  "latest/stable",
+			},
+			map[string]any{
+				"state":    "removed",
+				"instance": "snap-two",
+				"channel":  "latest/stable",
+			},
+		},
+	}})
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	addSerialToState(c, s.st, makeSerialAssertion(c, s.stack, "serial-1"))
+	c.Assert(clusterstate.InitializeNewCluster(s.st, bytes.NewReader(bundle)), check.IsNil)
+
+	s.mgr = clusterstate.Manager(s.st, state.NewTaskRunner(s.st))
+}
+
+func (s *driftSuite) setSnap(name, channel string) {
+	if channel == "" {
+		snapstate.Set(s.st, name, nil)
+		return
+	}
+	snapstate.Set(s.st, name, &snapstate.SnapState{
+		Current:         snap.R(1),
+		TrackingChannel: channel,
+		Sequence: sequence.SnapSequence{
+			Revisions: []*sequence.RevisionSideState{
+				sequence.NewRevisionSideState(&snap.SideInfo{RealName: name, Revision: snap.R(1)}, nil),
+			},
+		},
+	})
+}
+
+func (s *driftSuite) setDriftPolicy(c *check.C, policy string) {
+	tr := config.NewTransaction(s.st)
+	c.Assert(tr.Set("core", "cluster.drift", policy), check.IsNil)
+	tr.Commit()
+}
+
+func (s *driftSuite) ensure(c *check.C) {
+	s.st.Unlock()
+	defer s.st.Lock()
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+}
+
+// applied sets up the device so that it matches the cluster assertion and runs
+// a first ensure pass.
+func (s *driftSuite) applied(c *check.C) {
+	s.setSnap("snap-one", "latest/stable")
+	s.ensure(c)
+
+	c.Assert(s.st.Changes(), check.HasLen, 0)
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Compliance, check.DeepEquals, &clusterstate.Compliance{
+		ClusterID: "cluster-id",
+		Sequence:  1,
+		Checked:   s.now,
+	})
+	c.Assert(status.Compliance.Compliant(), check.Equals, true)
+}
+
+func (s *driftSuite) TestComplianceNewAssertion(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	// a new assertion is applied even when only reporting drift
+	s.setDriftPolicy(c, "report")
+	s.setSnap("snap-two", "latest/stable")
+	s.ensure(c)
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.Compliance, check.DeepEquals, &clusterstate.Compliance{
+		ClusterID: "cluster-id",
+		Sequence:  1,
+		Checked:   s.now,
+		Drift: []clusterstate.Drift{
+			{Subcluster: "default", Snap: "snap-one", Kind: clusterstate.DriftMissing},
+			{Subcluster: "default", Snap: "snap-two", Kind: clusterstate.DriftPresent},
+		},
+	})
+	c.Check(status.Compliance.Compliant(), check.Equals, false)
+
+	c.Assert(s.st.Changes(), check.HasLen, 1)
+	c.Check(s.st.Changes()[0].Kind(), check.Equals, "apply-cluster-subcluster")
+	c.Check(s.installs, check.HasLen, 1)
+	c.Check(s.removals, check.DeepEquals, []string{"snap-two"})
+	c.Check(s.st.AllWarnings(), check.HasLen, 0)
+}
+
+func (s *driftSuite) TestDriftCorrected(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.applied(c)
+
+	// the snap was switched to another channel behind our back
+	s.setSnap("snap-one", "latest/edge")
+	s.ensure(c)
+
+	c.Assert(s.st.Changes(), check.HasLen, 1)
+	c.Check(s.updates, check.DeepEquals, []snapstate.StoreUpdate{{
+		InstanceName: "snap-one",
+		RevOpts:      snapstate.RevisionOptions{Channel: "latest/stable"},
+	}})
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.Compliance.Drift, check.DeepEquals, []clusterstate.Drift{{
+		Subcluster: "default",
+		Snap:       "snap-one",
+		Kind:       clusterstate.DriftChannel,
+		Expected:   "latest/stable",
+		Current:    "latest/edge",
+	}})
+	c.Check(s.st.AllWarnings(), check.HasLen, 0)
+
+	// no other change is created while the first one is in progress
+	s.ensure(c)
+	c.Check(s.st.Changes(), check.HasLen, 1)
+}
+
+func (s *driftSuite) TestDriftReported(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.setDriftPolicy(c, "report")
+	s.applied(c)
+
+	// the snaps were changed behind our back
+	s.setSnap("snap-one", "")
+	s.setSnap("snap-two", "latest/stable")
+	s.ensure(c)
+
+	c.Check(s.st.Changes(), check.HasLen, 0)
+	c.Check(s.installs, check.HasLen, 0)
+	c.Check(s.removals, check.HasLen, 0)
+
+	var warnings []string
+	for _, w := range s.st.AllWarnings() {
+		warnings = append(warnings, w.String())
+	}
+	c.Check(warnings, check.DeepEquals, []string{
+		`cluster "cluster-id": snap "snap-one" of subcluster "default" is not installed`,
+		`cluster "cluster-id": snap "snap-two" removed from subcluster "default" is installed`,
+	})
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.Compliance.Drift, check.HasLen, 2)
+	c.Check(status.Compliance.Checked, check.Equals, s.now)
+
+	// the same drift is neither recorded nor warned about again
+	reported, err := json.Marshal(s.st.AllWarnings())
+	c.Assert(err, check.IsNil)
+	checked := s.now
+	s.now = s.now.Add(time.Minute)
+	s.ensure(c)
+
+	status, err = clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.Compliance.Drift, check.HasLen, 2)
+	c.Check(status.Compliance.Checked, check.Equals, checked)
+	again, err := json.Marshal(s.st.AllWarnings())
+	c.Assert(err, check.IsNil)
+	c.Check(string(again), check.Equals, string(reported))
+
+	// once reported drift is corrected by hand, the device is compliant again
+	s.setSnap("snap-one", "latest/stable")
+	s.setSnap("snap-two", "")
+	s.ensure(c)
+
+	status, err = clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.Compliance.Compliant(), check.Equals, true)
+	c.Check(status.Compliance.Checked, check.Equals, s.now)
+}
+
+func (s *driftSuite) TestDriftReportedNewSequenceApplied(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.setDriftPolicy(c, "report")
+	s.applied(c)
+
+	bundle, _ := makeClusterBundleWithSigning(c, s.sa, "cluster-brand", "cluster-id", 2, []map[string]any{
+		{
+			"id":        "1",
+			"device":    "serial-1.ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"192.168.0.10"},
+		},
+	}, []map[string]any{{
+		"name":    "default",
+		"devices": []any{"1"},
+		"snaps": []any{
+			map[string]any{
+				"state":    "removed",
+				"instance": "snap-one",
+				"channel":  "latest/stable",
+			},
+		},
+	}})
+	c.Assert(clusterstate.UpdateCluster(s.st, bytes.NewReader(bundle)), check.IsNil)
+	s.ensure(c)
+
+	c.Check(s.removals, check.DeepEquals, []string{"snap-one"})
+	c.Check(s.st.Changes(), check.HasLen, 1)
+	c.Check(s.st.AllWarnings(), check.HasLen, 0)
+}
@@ -21,6 +21,10 @@ package clusterstate
 
 import (
 	"context"
+	"net"
+	"time"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
 
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -57,3 +61,33 @@ func MockStoreInstallGoal(f func(...snapstate.StoreSnap) snapstate.InstallGoal)
 	storeInstallGoal = f
 	return restore
 }
+
+func MockSignWithDeviceKey(f func(*state.State, []byte) ([]byte, error)) func() {
+	restore := testutil.Backup(&signWithDeviceKey)
+	signWithDeviceKey = f
+	return restore
+}
+
+func MockAssembleListen(f func(network, address string) (net.Listener, error)) func() {
+	restore := testutil.Backup(&assembleListen)
+	assembleListen = f
+	return restore
+}
+
+func MockNewTransport(f func() assemblestate.Transport) func() {
+	restore := testutil.Backup(&newTransport)
+	newTransport = f
+	return restore
+}
+
+func MockTimeNow(f func() time.Time) func() {
+	restore := testutil.Backup(&timeNow)
+	timeNow = f
+	return restore
+}
+
+func MockDiscoveryListen(f func(iface string) (net.PacketConn, error)) func() {
+	restore := testutil.Backup(&discoveryListen)
+	discoveryListen = f
+	return restore
+}
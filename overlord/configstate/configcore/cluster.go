@@ -0,0 +1,42 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"errors"
+)
+
+func init() {
+	// add supported configuration of this module
+	supportedConfigurations["core.cluster.drift"] = true
+}
+
+func validateClusterDrift(tr RunTransaction) error {
+	drift, err := coreCfg(tr, "cluster.drift")
+	if err != nil {
+		return err
+	}
+	switch drift {
+	case "", "correct", "report":
+		return nil
+	}
+	return errors.New(`cluster.drift can only be set to "correct" or "report"`)
+}
@@ -23,9 +23,11 @@ package configcore
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timeutil"
 )
@@ -43,6 +45,8 @@ func init() {
 	supportedConfigurations["core.refresh.retain"] = true
 	supportedConfigurations["core.refresh.rate-limit"] = true
 	supportedConfigurations["core.refresh.max-inhibition-days"] = true
+	supportedConfigurations["core.refresh.health-revert"] = true
+	supportedConfigurations["core.refresh.health-revert-timeout"] = true
 }
 
 func reportOrIgnoreInvalidManageRefreshes(tr RunTransaction, optName string) error {
@@ -158,8 +162,30 @@ func validateRefreshRateLimit(tr RunTransaction) error {
 	if len(refreshRateLimit) == 0 {
 		return nil
 	}
+	// time of day dependent limits, e.g. 08:00-18:00/512K,18:00-08:00/unlimited
+	if strings.Contains(refreshRateLimit, "/") {
+		_, err := store.ParseRateLimitSchedule(refreshRateLimit)
+		return err
+	}
 	if _, err := strutil.ParseByteSize(refreshRateLimit); err != nil {
 		return err
 	}
 	return nil
 }
+
+func validateRefreshHealthRevert(tr RunTransaction) error {
+	if err := validateBoolFlag(tr, "refresh.health-revert"); err != nil {
+		return err
+	}
+	timeoutStr, err := coreCfg(tr, "refresh.health-revert-timeout")
+	if err != nil {
+		return err
+	}
+	if timeoutStr == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(timeoutStr); err != nil || d <= 0 {
+		return fmt.Errorf("refresh.health-revert-timeout must be a positive duration, not %q", timeoutStr)
+	}
+	return nil
+}
@@ -252,3 +252,62 @@ func (s *refreshSuite) TestConfigureRefreshMaxInhibitionDays(c *C) {
 		}
 	}
 }
+
+func (s *refreshSuite) TestConfigureRefreshHealthRevert(c *C) {
+	data := []struct {
+		conf map[string]any
+		err  string
+	}{
+		{conf: map[string]any{"refresh.health-revert": "maybe"}, err: `refresh.health-revert can only be set to 'true' or 'false'`},
+		{conf: map[string]any{"refresh.health-revert-timeout": "soon"}, err: `refresh.health-revert-timeout must be a positive duration, not "soon"`},
+		{conf: map[string]any{"refresh.health-revert-timeout": "-1m"}, err: `refresh.health-revert-timeout must be a positive duration, not "-1m"`},
+		{conf: map[string]any{"refresh.health-revert-timeout": "0s"}, err: `refresh.health-revert-timeout must be a positive duration, not "0s"`},
+		// happy cases
+		{conf: map[string]any{"refresh.health-revert": ""}},
+		{conf: map[string]any{"refresh.health-revert": true}},
+		{conf: map[string]any{"refresh.health-revert": "false"}},
+		{conf: map[string]any{"refresh.health-revert": true, "refresh.health-revert-timeout": "10m"}},
+	}
+	for _, tc := range data {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  tc.conf,
+		})
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.conf))
+		} else {
+			c.Check(err, IsNil, Commentf("%v", tc.conf))
+		}
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshRateLimit(c *C) {
+	data := []struct {
+		conf map[string]any
+		err  string
+	}{
+		{conf: map[string]any{"refresh.rate-limit": "fast"}, err: `cannot parse "fast": .*`},
+		{conf: map[string]any{"refresh.rate-limit": "08:00-18:00"}, err: `cannot parse "08:00-18:00": .*`},
+		{conf: map[string]any{"refresh.rate-limit": "08:00-18:00/fast"}, err: `cannot parse rate limit window "08:00-18:00/fast": cannot parse "fast": .*`},
+		{conf: map[string]any{"refresh.rate-limit": "08:00/512K"}, err: `cannot parse rate limit window "08:00/512K": expected <start>-<end>`},
+		{conf: map[string]any{"refresh.rate-limit": "08:00-08:00/512K"}, err: `cannot parse rate limit window "08:00-08:00/512K": window is empty`},
+		{conf: map[string]any{"refresh.rate-limit": "8am-6pm/512K"}, err: `cannot parse rate limit window "8am-6pm/512K": cannot parse "8am"`},
+		{conf: map[string]any{"refresh.rate-limit": "08:00-18:00/512K,17:00-20:00/1M"}, err: `cannot use rate limit window "17:00-20:00/1M": overlaps with "08:00-18:00/512K"`},
+		// happy cases
+		{conf: map[string]any{"refresh.rate-limit": ""}},
+		{conf: map[string]any{"refresh.rate-limit": "1MB"}},
+		{conf: map[string]any{"refresh.rate-limit": "08:00-18:00/512K,18:00-08:00/unlimited"}},
+		{conf: map[string]any{"refresh.rate-limit": "22:00-06:00/10MB"}},
+	}
+	for _, tc := range data {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  tc.conf,
+		})
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.conf))
+		} else {
+			c.Check(err, IsNil, Commentf("%v", tc.conf))
+		}
+	}
+}
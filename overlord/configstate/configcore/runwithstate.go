@@ -59,7 +59,9 @@ func init() {
 	validateOnly := &flags{validatedOnlyStateConfig: true}
 	addWithStateHandler(validateRefreshSchedule, nil, validateOnly)
 	addWithStateHandler(validateRefreshRateLimit, nil, validateOnly)
+	addWithStateHandler(validateRefreshHealthRevert, nil, validateOnly)
 	addWithStateHandler(validateAutomaticSnapshotsExpiration, nil, validateOnly)
+	addWithStateHandler(validateClusterDrift, nil, validateOnly)
 
 	// netplan.*
 	addWithStateHandler(validateNetplanSettings, handleNetplanConfiguration, coreOnly)
@@ -78,6 +80,9 @@ func init() {
 
 	// interface.*.allow-auto-connection
 	addWithStateHandler(validateAllowAutoConnectionValue, nil, &flags{validatedOnlyStateConfig: true})
+
+	// services.<snap>.<app>.*
+	addWithStateHandler(validateServiceOverrides, handleServiceOverrides, nil)
 }
 
 // RunTransaction is an interface describing how to access
@@ -176,6 +181,10 @@ func applyHandlers(dev sysconfig.Device, cfg RunTransaction, handlers []configHa
 			if err := validateInterfaceChange(k); err != nil {
 				return err
 			}
+		case isServiceOverridesChange(k):
+			if err := validateServiceOverridesChange(k); err != nil {
+				return err
+			}
 		case !supportedConfigurations[k]:
 			return fmt.Errorf("cannot set %q: unsupported system option", k)
 		}
@@ -0,0 +1,234 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/timeutil"
+	"github.com/snapcore/snapd/wrappers"
+)
+
+const serviceOverridesOpt = "services"
+
+// isServiceOverridesChange returns whether the given option is one of the
+// services.<snap>.<app>.* options overriding the settings of snap services.
+func isServiceOverridesChange(opt string) bool {
+	return opt == "core."+serviceOverridesOpt || strings.HasPrefix(opt, "core."+serviceOverridesOpt+".")
+}
+
+func validateServiceOverridesChange(opt string) error {
+	// core.services.<snap>.<app>.<option>[.<variable>], shorter options
+	// are only seen when unsetting all the overrides of a snap or service
+	tokens := strings.SplitN(opt, ".", 5)
+	if len(tokens) > 2 {
+		if err := naming.ValidateSnap(tokens[2]); err != nil {
+			return fmt.Errorf("cannot set %q: %v", opt, err)
+		}
+	}
+	if len(tokens) > 3 {
+		if err := naming.ValidateApp(tokens[3]); err != nil {
+			return fmt.Errorf("cannot set %q: %v", opt, err)
+		}
+	}
+	if len(tokens) > 4 {
+		name, _, hasVariable := strings.Cut(tokens[4], ".")
+		switch name {
+		case "restart-condition", "restart-delay", "timer":
+			if !hasVariable {
+				return nil
+			}
+		case "environment":
+			return nil
+		}
+		return fmt.Errorf("cannot set %q: unsupported service option", opt)
+	}
+	return nil
+}
+
+var validEnvironmentVariable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`).MatchString
+
+func validateServiceOverride(opt, name string, value any) error {
+	if name == "environment" {
+		env, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("cannot set %q: expected a map of environment variables", opt)
+		}
+		for variable, v := range env {
+			if !validEnvironmentVariable(variable) {
+				return fmt.Errorf("cannot set %q: invalid environment variable name %q", opt, variable)
+			}
+			switch v.(type) {
+			case string, bool, json.Number, float64:
+			default:
+				return fmt.Errorf("cannot set %q: value of environment variable %q must be a string", opt, variable)
+			}
+		}
+		return nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("cannot set %q: expected a string", opt)
+	}
+	switch name {
+	case "restart-condition":
+		if _, ok := snap.RestartMap[str]; !ok {
+			return fmt.Errorf("cannot set %q: unsupported restart condition %q", opt, str)
+		}
+	case "restart-delay":
+		delay, err := time.ParseDuration(str)
+		if err != nil {
+			return fmt.Errorf("cannot set %q: %v", opt, err)
+		}
+		if delay <= 0 {
+			return fmt.Errorf("cannot set %q: restart delay must be positive", opt)
+		}
+	case "timer":
+		if _, err := timeutil.ParseSchedule(str); err != nil {
+			return fmt.Errorf("cannot set %q: %v", opt, err)
+		}
+	default:
+		return fmt.Errorf("cannot set %q: unsupported service option", opt)
+	}
+	return nil
+}
+
+// validateServiceOverrides validates the settings of snap services
+// overridden through the services.<snap>.<app>.* options.
+func validateServiceOverrides(tr RunTransaction) error {
+	var overrides map[string]any
+	if err := tr.Get("core", serviceOverridesOpt, &overrides); err != nil && !config.IsNoOption(err) {
+		return err
+	}
+	for snapName, v := range overrides {
+		apps, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("cannot set %q: expected a map of services", serviceOverridesOpt+"."+snapName)
+		}
+		for app, v := range apps {
+			settings, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("cannot set %q: expected a map of service options", serviceOverridesOpt+"."+snapName+"."+app)
+			}
+			for name, value := range settings {
+				opt := strings.Join([]string{serviceOverridesOpt, snapName, app, name}, ".")
+				if err := validateServiceOverride(opt, name, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// handleServiceOverrides applies the changed overrides to the services of
+// installed snaps. As with the settings from the snap.yaml, the overrides
+// take effect the next time the services are started.
+func handleServiceOverrides(tr RunTransaction, opts *fsOnlyContext) error {
+	changed := make(map[string]bool)
+	for _, opt := range tr.Changes() {
+		if !isServiceOverridesChange(opt) {
+			continue
+		}
+		tokens := strings.SplitN(opt, ".", 4)
+		if len(tokens) > 2 {
+			changed[tokens[2]] = true
+			continue
+		}
+		// all the overrides were unset
+		var pristine map[string]any
+		if err := tr.GetPristine("core", serviceOverridesOpt, &pristine); err != nil && !config.IsNoOption(err) {
+			return err
+		}
+		for snapName := range pristine {
+			changed[snapName] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	st := tr.State()
+	st.Lock()
+	defer st.Unlock()
+
+	for snapName := range changed {
+		var snapst snapstate.SnapState
+		err := snapstate.Get(st, snapName, &snapst)
+		// not installed or not active, the overrides are applied when the
+		// services of the snap are written
+		if errors.Is(err, state.ErrNoState) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !snapst.Active {
+			continue
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			return err
+		}
+		if len(info.Services()) == 0 {
+			continue
+		}
+
+		// TODO: use sysconfig.Device instead
+		deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+		if err != nil {
+			return err
+		}
+		ensureOpts := &wrappers.EnsureSnapServicesOptions{}
+		if !deviceCtx.Classic() && deviceCtx.Model().Base() != "" {
+			ensureOpts.RequireMountedSnapdSnap = true
+		}
+
+		snapSvcOpts, err := servicestate.SnapServiceOptions(st, info, nil)
+		if err != nil {
+			return err
+		}
+		// use the overrides as set by this transaction
+		snapSvcOpts.Overrides, err = servicestate.ServiceOverrides(tr, snapName)
+		if err != nil {
+			return err
+		}
+
+		m := map[*snap.Info]*wrappers.SnapServiceOptions{info: snapSvcOpts}
+		if err := wrappers.EnsureSnapServices(m, ensureOpts, nil, progress.Null); err != nil {
+			return err
+		}
+	}
+	return nil
+}
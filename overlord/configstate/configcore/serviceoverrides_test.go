@@ -0,0 +1,158 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type serviceOverridesSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&serviceOverridesSuite{})
+
+func (s *serviceOverridesSuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	uc18model := assertstest.FakeAssertion(map[string]any{
+		"type":         "model",
+		"authority-id": "canonical",
+		"series":       "16",
+		"brand-id":     "canonical",
+		"model":        "pc",
+		"gadget":       "pc",
+		"kernel":       "kernel",
+		"architecture": "amd64",
+		"base":         "core18",
+	}).(*asserts.Model)
+	s.AddCleanup(snapstatetest.MockDeviceModel(uc18model))
+}
+
+func (s *serviceOverridesSuite) run(c *C, changes map[string]any) error {
+	s.state.Lock()
+	rt := configcore.NewRunTransaction(config.NewTransaction(s.state), nil)
+	s.state.Unlock()
+	for key, value := range changes {
+		c.Assert(rt.Set("core", key, value), IsNil)
+	}
+	if err := configcore.Run(classicDev, rt); err != nil {
+		return err
+	}
+	s.state.Lock()
+	rt.Commit()
+	s.state.Unlock()
+	return nil
+}
+
+func (s *serviceOverridesSuite) TestValidationErrors(c *C) {
+	for _, t := range []struct {
+		key   string
+		value any
+		err   string
+	}{
+		{"services.a-name-which-is-too-long-for-being-a-snap.svc.timer", "mon", `cannot set "core.services.a-name-which-is-too-long-for-being-a-snap.svc.timer": invalid snap name: .*`},
+		{"services.foo.svc.restart", "always", `cannot set "core.services.foo.svc.restart": unsupported service option`},
+		{"services.foo.svc.timer.at", "mon", `cannot set "core.services.foo.svc.timer.at": unsupported service option`},
+		{"services.foo.svc.restart-condition", "sometimes", `cannot set "services.foo.svc.restart-condition": unsupported restart condition "sometimes"`},
+		{"services.foo.svc.restart-condition", true, `cannot set "services.foo.svc.restart-condition": expected a string`},
+		{"services.foo.svc.restart-delay", "10", `cannot set "services.foo.svc.restart-delay": time: missing unit in duration "10"`},
+		{"services.foo.svc.restart-delay", "-1s", `cannot set "services.foo.svc.restart-delay": restart delay must be positive`},
+		{"services.foo.svc.timer", "mon,25:00", `cannot set "services.foo.svc.timer": cannot parse "25:00": .*`},
+		{"services.foo.svc.environment", "FOO=bar", `cannot set "services.foo.svc.environment": expected a map of environment variables`},
+		{"services.foo.svc.environment", map[string]any{"1FOO": "bar"}, `cannot set "services.foo.svc.environment": invalid environment variable name "1FOO"`},
+		{"services.foo.svc.environment", map[string]any{"FOO": []any{"bar"}}, `cannot set "services.foo.svc.environment": value of environment variable "FOO" must be a string`},
+		{"services.foo.svc", "always", `cannot set "services.foo.svc": expected a map of service options`},
+	} {
+		err := s.run(c, map[string]any{t.key: t.value})
+		c.Check(err, ErrorMatches, t.err, Commentf("%s", t.key))
+	}
+}
+
+func (s *serviceOverridesSuite) TestOverridesNotInstalled(c *C) {
+	err := s.run(c, map[string]any{"services.test-snap.foo.restart-delay": "10s"})
+	c.Assert(err, IsNil)
+	c.Check(s.systemctlArgs, HasLen, 0)
+}
+
+func (s *serviceOverridesSuite) TestOverridesApplied(c *C) {
+	si := &snap.SideInfo{RealName: "test-snap", Revision: snap.R(1)}
+	snaptest.MockSnap(c, mockSnapWithService, si)
+	s.state.Lock()
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  snap.R(1),
+		Active:   true,
+		SnapType: "app",
+	})
+	s.state.Unlock()
+
+	err := s.run(c, map[string]any{
+		"services.test-snap.foo.restart-condition": "on-failure",
+		"services.test-snap.foo.restart-delay":     "1m",
+		"services.test-snap.foo.environment":       map[string]any{"PORT": json.Number("8080")},
+	})
+	c.Assert(err, IsNil)
+	svcPath := filepath.Join(dirs.SnapServicesDir, "snap.test-snap.foo.service")
+	dropIn := svcPath + ".d/snap-overrides.conf"
+	c.Check(svcPath, testutil.FileContains, "\nWants=usr-lib-snapd.mount\n")
+	c.Check(dropIn, testutil.FileEquals, `[Service]
+# Auto-generated, DO NOT EDIT
+Restart=on-failure
+RestartSec=1m0s
+Environment="PORT=8080"
+`)
+	c.Check(s.systemctlArgs, DeepEquals, [][]string{{"daemon-reload"}})
+
+	// unsetting a single override
+	s.systemctlArgs = nil
+	err = s.run(c, map[string]any{
+		"services.test-snap.foo.restart-condition": nil,
+	})
+	c.Assert(err, IsNil)
+	c.Check(dropIn, testutil.FileEquals, `[Service]
+# Auto-generated, DO NOT EDIT
+RestartSec=1m0s
+Environment="PORT=8080"
+`)
+	c.Check(s.systemctlArgs, DeepEquals, [][]string{{"daemon-reload"}})
+
+	// unsetting all the overrides
+	s.systemctlArgs = nil
+	err = s.run(c, map[string]any{"services": nil})
+	c.Assert(err, IsNil)
+	c.Check(dropIn, testutil.FileAbsent)
+	c.Check(s.systemctlArgs, DeepEquals, [][]string{{"daemon-reload"}})
+}
@@ -1796,6 +1796,10 @@ func (m *DeviceManager) ensurePostFactoryReset() error {
 		}
 	}
 
+	if err := restorePreservedSnapData(m.state); err != nil {
+		return fmt.Errorf("cannot restore preserved snap data: %v", err)
+	}
+
 	return os.Remove(factoryResetMarker)
 }
 
@@ -2756,6 +2760,12 @@ func (m *DeviceManager) switchToSystemAndMode(systemLabel, mode string, sameSyst
 		return fmt.Errorf("internal error: unexpected manager system mode %q", systemMode)
 	}
 
+	if mode == "factory-reset" {
+		if err := m.preserveSnapDataForFactoryReset(systemMode); err != nil {
+			return fmt.Errorf("cannot preserve snap data across factory reset: %v", err)
+		}
+	}
+
 	m.state.Lock()
 	defer m.state.Unlock()
 
@@ -2889,6 +2899,20 @@ func (m *DeviceManager) hookKeyProtectorFactory(kernelInfo *snap.Info) (secboot.
 		return secboot.OPTEEKeyProtectorFactory(), nil
 	}
 
+	gadgetInfo, err := snapstate.GadgetInfo(st, deviceCtx)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("cannot get gadget info: %v", err)
+	}
+	if err == nil {
+		gadgetData, err := gadget.ReadInfo(gadgetInfo.MountDir(), deviceCtx.Model())
+		if err != nil {
+			return nil, fmt.Errorf("cannot read gadget: %v", err)
+		}
+		if gadgetData.FDEKeyfile != nil {
+			return secboot.KeyfileKeyProtectorFactory(*gadgetData.FDEKeyfile), nil
+		}
+	}
+
 	return nil, secboot.ErrNoKeyProtector
 }
 
@@ -143,6 +143,19 @@ func Serial(st *state.State) (*asserts.Serial, error) {
 	return findSerial(st, nil)
 }
 
+// SignWithDeviceKey signs the given data with the private device key, the
+// signature can be verified with the device key of the serial assertion.
+func SignWithDeviceKey(st *state.State, data []byte) ([]byte, error) {
+	privKey, err := deviceMgr(st).keyPair()
+	if err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, errors.New("cannot sign without a device key")
+		}
+		return nil, err
+	}
+	return asserts.RawSignWithKey(data, privKey)
+}
+
 // findKnownRevisionOfModel returns the model assertion revision if any in the
 // assertion database for the given model, otherwise it returns -1.
 func findKnownRevisionOfModel(st *state.State, mod *asserts.Model) (modRevision int, err error) {
@@ -1331,7 +1344,7 @@ func remodelTasks(ctx context.Context, st *state.State, current, new *asserts.Mo
 			return nil, err
 		}
 
-		createRecoveryTasks, err := createRecoverySystemTasks(st, label, snapsupTaskIDs, compsupTaskIDs, CreateRecoverySystemOptions{
+		createRecoveryTasks, err := createRecoverySystemTasks(st, label, snapsupTaskIDs, compsupTaskIDs, nil, CreateRecoverySystemOptions{
 			TestSystem: true,
 		})
 		if err != nil {
@@ -1712,6 +1725,12 @@ type recoverySystemSetup struct {
 	// MarkDefault is set to true if the new recovery system should be marked as
 	// the default recovery system.
 	MarkDefault bool `json:"mark-default,omitempty"`
+	// ExtraSnaps is a list of installed snaps that are not part of the model
+	// but should be added to the recovery system.
+	ExtraSnaps []string `json:"extra-snaps,omitempty"`
+	// CaptureConfig is set to true if the configuration of the snaps should
+	// be saved for the recovery system and applied when seeding from it.
+	CaptureConfig bool `json:"capture-config,omitempty"`
 }
 
 func pickRecoverySystemLabel(labelBase string) (string, error) {
@@ -1756,7 +1775,7 @@ func removeRecoverySystemTasks(st *state.State, label string) (*state.TaskSet, e
 	return state.NewTaskSet(remove), nil
 }
 
-func createRecoverySystemTasks(st *state.State, label string, snapSetupTasks, compSetupTasks []string, opts CreateRecoverySystemOptions) (*state.TaskSet, error) {
+func createRecoverySystemTasks(st *state.State, label string, snapSetupTasks, compSetupTasks, extraSnaps []string, opts CreateRecoverySystemOptions) (*state.TaskSet, error) {
 	// precondition check, the directory should not exist yet
 	systemDirectory := filepath.Join(boot.InitramfsUbuntuSeedDir, "systems", label)
 	exists, _, err := osutil.DirExists(systemDirectory)
@@ -1779,6 +1798,8 @@ func createRecoverySystemTasks(st *state.State, label string, snapSetupTasks, co
 		LocalComponents:     opts.LocalComponents,
 		TestSystem:          opts.TestSystem,
 		MarkDefault:         opts.MarkDefault,
+		ExtraSnaps:          extraSnaps,
+		CaptureConfig:       opts.CaptureState,
 	})
 
 	ts := state.NewTaskSet(create)
@@ -1845,6 +1866,18 @@ type CreateRecoverySystemOptions struct {
 	// Offline is true if the recovery system should be created without reaching
 	// out to the store. Offline must be set to true if LocalSnaps is provided.
 	Offline bool
+
+	// CaptureState is set to true if the new recovery system should capture
+	// the current state of the device: the installed revisions of all the
+	// snaps, including the ones that are not part of the model, and their
+	// configuration. Installing from the system or factory resetting into it
+	// then seeds exactly that state. Snaps that are not part of the model can
+	// only be captured with a model of grade dangerous. Note that the
+	// configuration is kept on ubuntu-save, so it is only applied by a
+	// factory reset, which keeps ubuntu-save, and not by a new install. It
+	// implies Offline and cannot be combined with ValidationSets, LocalSnaps
+	// or LocalComponents.
+	CaptureState bool
 }
 
 var ErrNoRecoverySystem = errors.New("recovery system does not exist")
@@ -1904,6 +1937,14 @@ func CreateRecoverySystem(st *state.State, label string, opts CreateRecoverySyst
 		return nil, errors.New("local snaps/components cannot be provided when creating a recovery system online")
 	}
 
+	if opts.CaptureState {
+		if len(opts.ValidationSets) > 0 || len(opts.LocalSnaps) > 0 || len(opts.LocalComponents) > 0 {
+			return nil, errors.New("cannot capture the current state in a recovery system with validation sets or local snaps/components")
+		}
+		// the current state is made of what is installed only
+		opts.Offline = true
+	}
+
 	var seeded bool
 	err := st.Get("seeded", &seeded)
 	if err != nil && !errors.Is(err, state.ErrNoState) {
@@ -2118,6 +2159,14 @@ func CreateRecoverySystem(st *state.State, label string, opts CreateRecoverySyst
 		}
 	}
 
+	var extraSnaps []string
+	if opts.CaptureState {
+		extraSnaps, err = installedSnapsNotInModel(st, model, tracker)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	warnings, errs := tracker.Check()
 	for _, w := range warnings {
 		logger.Noticef("create recovery system prerequisites warning: %v", w)
@@ -2146,7 +2195,7 @@ func CreateRecoverySystem(st *state.State, label string, opts CreateRecoverySyst
 	opts.LocalSnaps = usedLocalSnaps
 
 	chg := st.NewChange(createRecoverySystemChangeKind, fmt.Sprintf("Create new recovery system with label %q", label))
-	createTS, err := createRecoverySystemTasks(st, label, snapsupTaskIDs, compsupTaskIDs, opts)
+	createTS, err := createRecoverySystemTasks(st, label, snapsupTaskIDs, compsupTaskIDs, extraSnaps, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -2161,6 +2210,48 @@ func CreateRecoverySystem(st *state.State, label string, opts CreateRecoverySyst
 	return chg, nil
 }
 
+// installedSnapsNotInModel returns the sorted names of the installed snaps that
+// are not part of the given model, adding them to the tracker.
+func installedSnapsNotInModel(st *state.State, model *asserts.Model, tracker *snap.SelfContainedSetPrereqTracker) ([]string, error) {
+	all, err := snapstate.All(st)
+	if err != nil {
+		return nil, err
+	}
+
+	inModel := map[string]bool{
+		// snapd is implicitly part of every model
+		"snapd": true,
+	}
+	for _, sn := range model.AllSnaps() {
+		inModel[sn.SnapName()] = true
+	}
+
+	var extra []string
+	for name, snapst := range all {
+		if inModel[name] {
+			continue
+		}
+		if snapst.InstanceKey != "" {
+			return nil, fmt.Errorf("cannot capture parallel instance of snap %q in a recovery system", name)
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			return nil, err
+		}
+		tracker.Add(info)
+		extra = append(extra, name)
+	}
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	sort.Strings(extra)
+
+	if model.Grade() != asserts.ModelDangerous {
+		return nil, fmt.Errorf("cannot capture snaps that are not in the model with a model of grade higher than dangerous: %s", strutil.Quoted(extra))
+	}
+	return extra, nil
+}
+
 func checkForSnapIDs(model *asserts.Model, localSnaps []snapstate.PathSnap) error {
 	for _, sn := range model.AllSnaps() {
 		if sn.ID() == "" {
@@ -477,6 +477,79 @@ func (s *deviceMgrGadgetSuite) TestUpdateGadgetOnCoreNoUpdateNeeded(c *C) {
 	c.Check(s.restartRequests, HasLen, 0)
 }
 
+var uc20gadgetYamlWithSlots = uc20gadgetYaml + `
+      - name: firmware-a
+        slot: a
+        type: 21686148-6449-6E6F-744E-656564454649
+        size: 1M
+        update:
+          edition: %[1]d
+      - name: firmware-b
+        slot: b
+        type: 21686148-6449-6E6F-744E-656564454649
+        size: 1M
+        update:
+          edition: %[1]d
+`
+
+func (s *deviceMgrGadgetSuite) TestUpdateGadgetOnUC20CoreWithSlots(c *C) {
+	bl := bootloadertest.Mock("mock", c.MkDir()).WithBootSlots()
+	bootloader.Force(bl)
+	defer bootloader.Force(nil)
+
+	var updated []string
+	restore := devicestate.MockGadgetUpdate(func(model gadget.Model, current, update gadget.GadgetData, path string, policy gadget.UpdatePolicyFunc, _ gadget.ContentUpdateObserver) error {
+		for i, to := range update.Info.Volumes["pc"].Structure {
+			from := current.Info.Volumes["pc"].Structure[i]
+			if ok, _ := policy(&gadget.LaidOutStructure{VolumeStructure: &from}, &gadget.LaidOutStructure{VolumeStructure: &to}); ok {
+				updated = append(updated, to.Name)
+			}
+		}
+		return nil
+	})
+	defer restore()
+
+	isClassic := false
+	chg, t := s.setupGadgetUpdate(c, "dangerous", fmt.Sprintf(uc20gadgetYamlWithSlots, 1), fmt.Sprintf(uc20gadgetYamlWithSlots, 2), isClassic)
+
+	modeenv := boot.Modeenv{
+		Mode:            "run",
+		CurrentBootSlot: "a",
+	}
+	c.Assert(modeenv.WriteTo(""), IsNil)
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	s.state.Lock()
+	s.state.Set("seeded", true)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(t.Status(), Equals, state.WaitStatus)
+	c.Check(chg.Err(), IsNil)
+	c.Check(s.restartRequests, DeepEquals, []restart.RestartType{restart.RestartSystem})
+
+	// only the inactive slot was updated
+	c.Check(updated, DeepEquals, []string{"firmware-b"})
+
+	m, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	c.Check(m.CurrentBootSlot, Equals, "a")
+	c.Check(m.TryBootSlot, Equals, "b")
+	c.Check(bl.BootVars, DeepEquals, map[string]string{
+		"snapd_try_boot_slot": "b",
+		"boot_slot_status":    "try",
+	})
+	c.Check(strings.Join(t.Log(), "\n"), Matches, `(?s).* INFO Boot slot "b" will be tried on the next boot.*`)
+
+	// the tried slot is checked once restarted
+	var trySlot string
+	c.Assert(chg.Get("try-boot-slot", &trySlot), IsNil)
+	c.Check(trySlot, Equals, "b")
+}
+
 func (s *deviceMgrGadgetSuite) TestUpdateGadgetOnCoreRollbackDirCreateFailed(c *C) {
 	if os.Geteuid() == 0 {
 		c.Skip("this test cannot run as root (permissions are not honored)")
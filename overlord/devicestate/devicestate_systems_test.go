@@ -1767,7 +1767,7 @@ func (s *deviceMgrSystemsCreateSuite) TestDeviceManagerCreateRecoverySystemRemod
 	tSnapsup1.Set("snap-setup", snapsupFoo)
 	tSnapsup2.Set("snap-setup", snapsupBar)
 
-	tss, err := devicestate.CreateRecoverySystemTasks(s.state, "1234", []string{tSnapsup1.ID(), tSnapsup2.ID()}, nil, devicestate.CreateRecoverySystemOptions{
+	tss, err := devicestate.CreateRecoverySystemTasks(s.state, "1234", []string{tSnapsup1.ID(), tSnapsup2.ID()}, nil, nil, devicestate.CreateRecoverySystemOptions{
 		TestSystem: true,
 	})
 	c.Assert(err, IsNil)
@@ -1932,7 +1932,7 @@ func (s *deviceMgrSystemsCreateSuite) TestDeviceManagerCreateRecoverySystemRemod
 
 	s.state.Lock()
 
-	tss, err := devicestate.CreateRecoverySystemTasks(s.state, "1234", nil, nil, devicestate.CreateRecoverySystemOptions{
+	tss, err := devicestate.CreateRecoverySystemTasks(s.state, "1234", nil, nil, nil, devicestate.CreateRecoverySystemOptions{
 		TestSystem: true,
 	})
 	c.Assert(err, IsNil)
@@ -2146,7 +2146,7 @@ func (s *deviceMgrSystemsCreateSuite) TestDeviceManagerCreateRecoverySystemRemod
 	}
 	tSnapsup1.Set("snap-setup", snapsupFoo)
 
-	tss, err := devicestate.CreateRecoverySystemTasks(s.state, "1234missingdownload", []string{tSnapsup1.ID()}, nil, devicestate.CreateRecoverySystemOptions{
+	tss, err := devicestate.CreateRecoverySystemTasks(s.state, "1234missingdownload", []string{tSnapsup1.ID()}, nil, nil, devicestate.CreateRecoverySystemOptions{
 		TestSystem: true,
 	})
 	c.Assert(err, IsNil)
@@ -5025,6 +5025,54 @@ func (s *deviceMgrSystemsCreateSuite) TestDeviceManagerCreateRecoverySystemOnlin
 	c.Assert(err, ErrorMatches, "local snaps/components cannot be provided when creating a recovery system online")
 }
 
+func (s *deviceMgrSystemsCreateSuite) TestDeviceManagerCreateRecoverySystemCaptureStateTasks(c *C) {
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.makeSnapInState(c, "other-present", snap.R(5), nil, nil)
+
+	chg, err := devicestate.CreateRecoverySystem(s.state, "1234", devicestate.CreateRecoverySystemOptions{
+		TestSystem:   true,
+		CaptureState: true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(chg, NotNil)
+	tsks := chg.Tasks()
+	// nothing is downloaded, create system + finalize system
+	c.Check(tsks, HasLen, 2)
+	tskCreate := tsks[0]
+	var systemSetupData map[string]any
+	err = tskCreate.Get("recovery-system-setup", &systemSetupData)
+	c.Assert(err, IsNil)
+	c.Assert(systemSetupData, DeepEquals, map[string]any{
+		"label":          "1234",
+		"directory":      filepath.Join(boot.InitramfsUbuntuSeedDir, "systems/1234"),
+		"test-system":    true,
+		"extra-snaps":    []any{"other-present"},
+		"capture-config": true,
+	})
+}
+
+func (s *deviceMgrSystemsCreateSuite) TestDeviceManagerCreateRecoverySystemCaptureStateWithValidationSetsOrLocalError(c *C) {
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, opts := range []devicestate.CreateRecoverySystemOptions{{
+		CaptureState:   true,
+		ValidationSets: []*asserts.ValidationSet{{}},
+	}, {
+		CaptureState: true,
+		LocalSnaps:   []snapstate.PathSnap{{SideInfo: &snap.SideInfo{}, Path: "/some/path"}},
+	}} {
+		_, err := devicestate.CreateRecoverySystem(s.state, "1234", opts)
+		c.Check(err, ErrorMatches, "cannot capture the current state in a recovery system with validation sets or local snaps/components")
+	}
+}
+
 func (s *deviceMgrSystemsCreateSuite) TestDeviceManagerCreateRecoverySystemOfflinePreinstalled(c *C) {
 	devicestate.SetBootOkRan(s.mgr, true)
 
@@ -20,6 +20,7 @@
 package devicestate_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -38,8 +39,10 @@ import (
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/bootloader/bootloadertest"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/kernel/fde"
@@ -55,6 +58,7 @@ import (
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
 	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/snapshotstate/backend"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
 	"github.com/snapcore/snapd/overlord/state"
@@ -1864,6 +1868,54 @@ func (s *deviceMgrSuite) TestHasFdeSetupHookOtherKernel(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *deviceMgrSuite) TestHookKeyProtectorFactoryKeyfile(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	s.makeModelAssertionInState(c, "canonical", "pc", map[string]any{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "pc",
+	})
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc",
+	})
+	makeInstalledMockKernelSnap(c, st, kernelYamlNoFdeSetup)
+
+	si := &snap.SideInfo{RealName: "pc", Revision: snap.R(1), SnapID: "pc-id"}
+	snapstate.Set(st, "pc", &snapstate.SnapState{
+		SnapType: "gadget",
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  si.Revision,
+		Active:   true,
+	})
+	snaptest.MockSnapWithFiles(c, pcGadgetSnapYaml, si, [][]string{
+		{"meta/gadget.yaml", gadgetYaml},
+	})
+
+	// the gadget does not declare a keyfile
+	_, err := devicestate.DeviceManagerHookKeyProtectorFactory(s.mgr, nil)
+	c.Assert(err, testutil.ErrorIs, secboot.ErrNoKeyProtector)
+
+	snaptest.MockSnapWithFiles(c, pcGadgetSnapYaml, si, [][]string{
+		{"meta/gadget.yaml", gadgetYaml + "fde-keyfile:\n  filesystem-label: fde-key\n  path: keys/device.key\n"},
+	})
+	kpf, err := devicestate.DeviceManagerHookKeyProtectorFactory(s.mgr, nil)
+	c.Assert(err, IsNil)
+	c.Check(secboot.ProtectorKeyfileLocation(kpf), DeepEquals, &device.KeyfileLocation{
+		FilesystemLabel: "fde-key",
+		Path:            "keys/device.key",
+	})
+
+	// the fde-setup hook takes precedence
+	makeInstalledMockKernelSnap(c, st, kernelYamlWithFdeSetup)
+	kpf, err = devicestate.DeviceManagerHookKeyProtectorFactory(s.mgr, nil)
+	c.Assert(err, IsNil)
+	c.Check(secboot.ProtectorKeyfileLocation(kpf), IsNil)
+}
+
 func (s *deviceMgrSuite) TestRunFDESetupHookHappy(c *C) {
 	st := s.state
 
@@ -2392,6 +2444,319 @@ func (s *deviceMgrSuite) TestDeviceManagerEnsurePostFactoryResetUnencrypted(c *C
 	c.Assert(err, IsNil)
 }
 
+var gadgetYamlPreserveData = gadgetYaml + `
+factory-reset:
+  preserve-data:
+    - foo
+    - not-installed
+`
+
+func (s *deviceMgrSuite) mockInstalledSnapWithFiles(c *C, name, yaml string, files [][]string) *snap.Info {
+	si := &snap.SideInfo{RealName: name, Revision: snap.R(1)}
+	info := snaptest.MockSnapWithFiles(c, yaml, si, files)
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, info.InstanceName(), &snapstate.SnapState{
+		SnapType: string(info.Type()),
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  si.Revision,
+	})
+	return info
+}
+
+func (s *deviceMgrSuite) TestDeviceManagerPreserveSnapDataForFactoryReset(c *C) {
+	s.setPCModelInState(c)
+	s.mockInstalledSnapWithFiles(c, "pc", "name: pc\nversion: 1\ntype: gadget", [][]string{
+		{"meta/gadget.yaml", gadgetYamlPreserveData},
+	})
+	s.mockInstalledSnapWithFiles(c, "foo", "name: foo\nversion: 1", nil)
+
+	// leftover from an earlier request
+	c.Assert(os.MkdirAll(devicestate.PreservedSnapDataDir(), 0755), IsNil)
+	leftover := filepath.Join(devicestate.PreservedSnapDataDir(), "0_old_1_1.zip")
+	c.Assert(os.WriteFile(leftover, nil, 0644), IsNil)
+
+	var saved []string
+	restore := devicestate.MockSnapshotSaveSystemData(func(ctx context.Context, dir string, id uint64, si *snap.Info) (*client.Snapshot, error) {
+		c.Check(dir, Equals, devicestate.PreservedSnapDataDir())
+		c.Check(id, Equals, uint64(1))
+		saved = append(saved, si.InstanceName())
+		return &client.Snapshot{Snap: si.InstanceName()}, nil
+	})
+	defer restore()
+
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	err := s.mgr.PreserveSnapDataForFactoryReset("run")
+	c.Assert(err, IsNil)
+	// snaps that are not installed are skipped
+	c.Check(saved, DeepEquals, []string{"foo"})
+	c.Check(leftover, testutil.FileAbsent)
+	c.Check(logbuf.String(), testutil.Contains, `not preserving data of snap "not-installed" across factory reset: snap is not installed`)
+
+	// nothing is saved outside of run mode
+	saved = nil
+	err = s.mgr.PreserveSnapDataForFactoryReset("recover")
+	c.Assert(err, IsNil)
+	c.Check(saved, HasLen, 0)
+}
+
+func (s *deviceMgrSuite) TestDeviceManagerPreserveSnapDataForFactoryResetFromModel(c *C) {
+	s.state.Lock()
+	s.makeModelAssertionInState(c, "canonical", "pc-20", map[string]any{
+		"architecture": "amd64",
+		"grade":        "dangerous",
+		"base":         "core20",
+		"snaps": []any{
+			map[string]any{
+				"name":            "pc-kernel",
+				"id":              snaptest.AssertedSnapID("pc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+			},
+			map[string]any{
+				"name":            "pc",
+				"id":              snaptest.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+		"factory-reset-preserve-data": []any{"bar", "foo"},
+	})
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc-20",
+	})
+	s.state.Unlock()
+
+	s.mockInstalledSnapWithFiles(c, "pc", "name: pc\nversion: 1\ntype: gadget", [][]string{
+		{"meta/gadget.yaml", gadgetYamlPreserveData},
+	})
+	s.mockInstalledSnapWithFiles(c, "foo", "name: foo\nversion: 1", nil)
+	s.mockInstalledSnapWithFiles(c, "bar", "name: bar\nversion: 1", nil)
+
+	var saved []string
+	restore := devicestate.MockSnapshotSaveSystemData(func(ctx context.Context, dir string, id uint64, si *snap.Info) (*client.Snapshot, error) {
+		saved = append(saved, si.InstanceName())
+		return &client.Snapshot{Snap: si.InstanceName()}, nil
+	})
+	defer restore()
+
+	err := s.mgr.PreserveSnapDataForFactoryReset("run")
+	c.Assert(err, IsNil)
+	// the snaps declared by the model are preserved along with the ones
+	// declared by the gadget, each only once
+	c.Check(saved, DeepEquals, []string{"bar", "foo"})
+
+	// the model declaration is used also without a gadget
+	s.state.Lock()
+	snapstate.Set(s.state, "pc", nil)
+	s.state.Unlock()
+	saved = nil
+	err = s.mgr.PreserveSnapDataForFactoryReset("run")
+	c.Assert(err, IsNil)
+	c.Check(saved, DeepEquals, []string{"bar", "foo"})
+}
+
+func (s *deviceMgrSuite) TestDeviceManagerPreserveSnapDataForFactoryResetError(c *C) {
+	s.setPCModelInState(c)
+	s.mockInstalledSnapWithFiles(c, "pc", "name: pc\nversion: 1\ntype: gadget", [][]string{
+		{"meta/gadget.yaml", gadgetYamlPreserveData},
+	})
+	s.mockInstalledSnapWithFiles(c, "foo", "name: foo\nversion: 1", nil)
+
+	restore := devicestate.MockSnapshotSaveSystemData(func(ctx context.Context, dir string, id uint64, si *snap.Info) (*client.Snapshot, error) {
+		c.Assert(os.MkdirAll(dir, 0700), IsNil)
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	err := s.mgr.PreserveSnapDataForFactoryReset("run")
+	c.Assert(err, ErrorMatches, `cannot save data of snap "foo": boom`)
+	c.Check(devicestate.PreservedSnapDataDir(), testutil.FileAbsent)
+}
+
+func (s *deviceMgrSuite) TestDeviceManagerEnsurePostFactoryResetRestoresPreservedData(c *C) {
+	defer release.MockOnClassic(false)
+
+	s.state.Lock()
+	s.state.Set("seeded", true)
+	s.state.Unlock()
+	devicestate.SetBootOkRan(s.mgr, false)
+	devicestate.SetSystemMode(s.mgr, "run")
+
+	info := s.mockInstalledSnapWithFiles(c, "foo", "name: foo\nversion: 1", nil)
+	dataFile := filepath.Join(info.DataDir(), "calibration")
+	c.Assert(os.MkdirAll(info.DataDir(), 0755), IsNil)
+	c.Assert(os.WriteFile(dataFile, []byte("calibrated"), 0644), IsNil)
+
+	_, err := backend.SaveSystemData(context.TODO(), devicestate.PreservedSnapDataDir(), 1, info)
+	c.Assert(err, IsNil)
+	// data of a snap which is no longer installed
+	gone := &snap.Info{SideInfo: snap.SideInfo{RealName: "gone", Revision: snap.R(1)}, Version: "1"}
+	c.Assert(os.MkdirAll(gone.DataDir(), 0755), IsNil)
+	_, err = backend.SaveSystemData(context.TODO(), devicestate.PreservedSnapDataDir(), 1, gone)
+	c.Assert(err, IsNil)
+
+	// the data was wiped by the factory reset
+	c.Assert(os.RemoveAll(snap.BaseDataDir("foo")), IsNil)
+
+	c.Assert(os.MkdirAll(dirs.SnapDeviceDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapDeviceDir, "factory-reset"), []byte("{}"), 0644), IsNil)
+
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	err = s.mgr.Ensure()
+	c.Assert(err, IsNil)
+
+	c.Check(dataFile, testutil.FileEquals, "calibrated")
+	c.Check(logbuf.String(), testutil.Contains, `restored data of snap "foo" preserved across factory reset`)
+	c.Check(logbuf.String(), Matches, `(?s).*cannot restore snap data preserved across factory reset from "1_gone_1_1.zip": snap "gone" is not installed.*`)
+	c.Check(filepath.Join(dirs.SnapDeviceDir, "factory-reset"), testutil.FileAbsent)
+
+	// the data that was restored is removed, the rest is kept
+	c.Check(filepath.Join(devicestate.PreservedSnapDataDir(), "1_foo_1_1.zip"), testutil.FileAbsent)
+	kept := filepath.Join(devicestate.PreservedSnapDataDir(), "1_gone_1_1.zip")
+	c.Check(kept, testutil.FilePresent)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	warns := s.state.AllWarnings()
+	c.Assert(warns, HasLen, 1)
+	c.Check(warns[0].String(), Equals, fmt.Sprintf(`cannot restore snap data preserved across factory reset, it is kept in %s: snap "gone" is not installed`, kept))
+}
+
+func (s *deviceMgrSuite) TestDeviceManagerEnsurePostFactoryResetRemovesRestoredData(c *C) {
+	defer release.MockOnClassic(false)
+
+	s.state.Lock()
+	s.state.Set("seeded", true)
+	s.state.Unlock()
+	devicestate.SetBootOkRan(s.mgr, false)
+	devicestate.SetSystemMode(s.mgr, "run")
+
+	info := s.mockInstalledSnapWithFiles(c, "foo", "name: foo\nversion: 1", nil)
+	c.Assert(os.MkdirAll(info.DataDir(), 0755), IsNil)
+	_, err := backend.SaveSystemData(context.TODO(), devicestate.PreservedSnapDataDir(), 1, info)
+	c.Assert(err, IsNil)
+
+	c.Assert(os.MkdirAll(dirs.SnapDeviceDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapDeviceDir, "factory-reset"), []byte("{}"), 0644), IsNil)
+
+	err = s.mgr.Ensure()
+	c.Assert(err, IsNil)
+
+	// all the data was restored
+	c.Check(devicestate.PreservedSnapDataDir(), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapDeviceDir, "factory-reset"), testutil.FileAbsent)
+}
+
+func (s *deviceMgrSuite) TestInstalledSnapsNotInModel(c *C) {
+	for _, name := range []string{"pc", "pc-kernel", "core20", "snapd", "foo", "bar"} {
+		s.mockInstalledSnapWithFiles(c, name, fmt.Sprintf("name: %s\nversion: 1", name), nil)
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	modelHeaders := map[string]any{
+		"architecture": "amd64",
+		"base":         "core20",
+		"grade":        "dangerous",
+		"snaps": []any{
+			map[string]any{
+				"name":            "pc-kernel",
+				"id":              snaptest.AssertedSnapID("pc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+			},
+			map[string]any{
+				"name":            "pc",
+				"id":              snaptest.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+	}
+	tracker := snap.NewSelfContainedSetPrereqTracker()
+	extra, err := devicestate.InstalledSnapsNotInModel(s.state, fakeMyModel(modelHeaders), tracker)
+	c.Assert(err, IsNil)
+	c.Check(extra, DeepEquals, []string{"bar", "foo"})
+
+	modelHeaders["grade"] = "signed"
+	_, err = devicestate.InstalledSnapsNotInModel(s.state, fakeMyModel(modelHeaders), snap.NewSelfContainedSetPrereqTracker())
+	c.Assert(err, ErrorMatches, `cannot capture snaps that are not in the model with a model of grade higher than dangerous: "bar", "foo"`)
+}
+
+func (s *deviceMgrSuite) TestCaptureSnapConfigAndTasks(c *C) {
+	s.mockInstalledSnapWithFiles(c, "foo", "name: foo\nversion: 1", nil)
+	s.mockInstalledSnapWithFiles(c, "bar", "name: bar\nversion: 1", nil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "experimental.parallel-instances", true), IsNil)
+	c.Assert(tr.Set("foo", "calibration", 42), IsNil)
+	c.Assert(tr.Set("bar", "key", "value"), IsNil)
+	tr.Commit()
+
+	// the configuration is only kept on ubuntu-save
+	err := devicestate.CaptureSnapConfig(s.state, "1234")
+	c.Assert(err, ErrorMatches, "ubuntu-save is not available")
+
+	c.Assert(os.MkdirAll(boot.InitramfsUbuntuSaveDir, 0755), IsNil)
+	err = devicestate.CaptureSnapConfig(s.state, "1234")
+	c.Assert(err, IsNil)
+	capturedConfig := filepath.Join(boot.InitramfsUbuntuSaveDir, "device/systems/1234/captured-config.json")
+	c.Check(capturedConfig, testutil.FileEquals,
+		`{"bar":{"key":"value"},"core":{"experimental":{"parallel-instances":true}},"foo":{"calibration":42}}`)
+	st, err := os.Stat(capturedConfig)
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0600))
+
+	// only the configuration of the given snaps and of the system is set,
+	// in order and through the configure hooks
+	ts, err := devicestate.CapturedSnapConfigTasks(s.state, "1234", []string{"foo"})
+	c.Assert(err, IsNil)
+	tasks := ts.Tasks()
+	c.Assert(tasks, HasLen, 2)
+	for i, exp := range []struct {
+		snap  string
+		patch map[string]any
+	}{
+		{"core", map[string]any{"experimental.parallel-instances": true}},
+		{"foo", map[string]any{"calibration": float64(42)}},
+	} {
+		t := tasks[i]
+		c.Check(t.Kind(), Equals, "run-hook")
+		var hooksup hookstate.HookSetup
+		c.Assert(t.Get("hook-setup", &hooksup), IsNil)
+		c.Check(hooksup, DeepEquals, hookstate.HookSetup{
+			Snap:        exp.snap,
+			Hook:        "configure",
+			Optional:    true,
+			Always:      true,
+			IgnoreError: exp.snap == "core",
+		})
+		var hookContext map[string]any
+		c.Assert(t.Get("hook-context", &hookContext), IsNil)
+		c.Check(hookContext, DeepEquals, map[string]any{"patch": exp.patch})
+	}
+	c.Check(tasks[1].WaitTasks(), DeepEquals, []*state.Task{tasks[0]})
+
+	// nothing to do for systems which did not capture configuration
+	ts, err = devicestate.CapturedSnapConfigTasks(s.state, "other", []string{"foo"})
+	c.Assert(err, IsNil)
+	c.Check(ts, IsNil)
+
+	c.Assert(devicestate.RemoveCapturedSnapConfig("1234"), IsNil)
+	c.Check(filepath.Dir(capturedConfig), testutil.FileAbsent)
+}
+
 func (s *deviceMgrSuite) mockSystemUser(c *C, username string, expiration time.Time) {
 	_, err := auth.NewUser(s.state, auth.NewUserParams{
 		Username:   username,
@@ -27,6 +27,7 @@ import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/snapasserts"
 	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/gadget/install"
@@ -710,3 +711,20 @@ func MockFdestateGetKeyslots(f func(st *state.State, keyslotRefs []fdestate.Keys
 func MockSnapstateGadgetInfo(f func(st *state.State, deviceCtx snapstate.DeviceContext) (*snap.Info, error)) (restore func()) {
 	return testutil.Mock(&snapstateGadgetInfo, f)
 }
+
+func MockSnapshotSaveSystemData(f func(ctx context.Context, dir string, id uint64, si *snap.Info) (*client.Snapshot, error)) (restore func()) {
+	return testutil.Mock(&snapshotSaveSystemData, f)
+}
+
+func (m *DeviceManager) PreserveSnapDataForFactoryReset(systemMode string) error {
+	return m.preserveSnapDataForFactoryReset(systemMode)
+}
+
+var PreservedSnapDataDir = preservedSnapDataDir
+
+var (
+	InstalledSnapsNotInModel = installedSnapsNotInModel
+	CaptureSnapConfig        = captureSnapConfig
+	CapturedSnapConfigTasks  = capturedSnapConfigTasks
+	RemoveCapturedSnapConfig = removeCapturedSnapConfig
+)
@@ -0,0 +1,174 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/snapshotstate/backend"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var snapshotSaveSystemData = backend.SaveSystemData
+
+// preservedSnapDataSetID is the set ID of the snapshots of the data preserved
+// across factory reset, they are kept apart from the regular snapshots so
+// the ID only needs to be valid.
+const preservedSnapDataSetID = 1
+
+// preservedSnapDataDir returns the directory on ubuntu-save that holds the
+// snapshots of the system data of the snaps preserved across factory reset.
+func preservedSnapDataDir() string {
+	return filepath.Join(boot.InitramfsUbuntuSaveDir, "device", "factory-reset-data")
+}
+
+// preserveSnapDataForFactoryReset saves the system data of the snaps that the
+// model declares in factory-reset-preserve-data or the gadget declares in
+// factory-reset/preserve-data to ubuntu-save, which is
+// kept across factory reset. The data of the snaps is only accessible in run
+// mode, from other modes nothing is preserved. The state must not be locked.
+func (m *DeviceManager) preserveSnapDataForFactoryReset(systemMode string) error {
+	dir := preservedSnapDataDir()
+	// drop anything left behind by an earlier factory reset request
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if systemMode != "run" {
+		return nil
+	}
+
+	m.state.Lock()
+	infos, err := snapsToPreserveAcrossFactoryReset(m.state)
+	m.state.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if _, err := snapshotSaveSystemData(context.TODO(), dir, preservedSnapDataSetID, info); err != nil {
+			os.RemoveAll(dir)
+			return fmt.Errorf("cannot save data of snap %q: %v", info.InstanceName(), err)
+		}
+		logger.Noticef("preserved data of snap %q across factory reset", info.InstanceName())
+	}
+	return nil
+}
+
+// snapsToPreserveAcrossFactoryReset returns the installed snaps whose data
+// is preserved across factory reset, as declared by the model and the gadget.
+func snapsToPreserveAcrossFactoryReset(st *state.State) ([]*snap.Info, error) {
+	deviceCtx, err := DeviceCtx(st, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	// copy, the list of the gadget is appended
+	names := append([]string(nil), deviceCtx.Model().FactoryResetPreserveData()...)
+
+	gadgetSnapInfo, err := snapstate.GadgetInfo(st, deviceCtx)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if err == nil {
+		gadgetInfo, err := gadget.ReadInfo(gadgetSnapInfo.MountDir(), deviceCtx.Model())
+		if err != nil {
+			return nil, err
+		}
+		names = strutil.Deduplicate(append(names, gadgetInfo.FactoryReset.PreserveData...))
+	}
+
+	var infos []*snap.Info
+	for _, name := range names {
+		info, err := snapstate.CurrentInfo(st, name)
+		if err != nil {
+			var notInstalled *snap.NotInstalledError
+			if errors.As(err, &notInstalled) {
+				logger.Noticef("not preserving data of snap %q across factory reset: snap is not installed", name)
+				continue
+			}
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// restorePreservedSnapData restores the data of the snaps that was preserved
+// across factory reset into their current revision and then removes it from
+// ubuntu-save. The data that cannot be restored, for instance because the snap
+// is no longer installed, is kept on ubuntu-save. The state must be locked.
+func restorePreservedSnapData(st *state.State) error {
+	dir := preservedSnapDataDir()
+	fns, err := filepath.Glob(filepath.Join(dir, "*.zip"))
+	if err != nil {
+		return err
+	}
+	for _, fn := range fns {
+		if err := restorePreservedSnapshot(st, fn); err != nil {
+			// do not block the completion of the factory reset on
+			// the data of a single snap
+			logger.Noticef("cannot restore snap data preserved across factory reset from %q: %v", filepath.Base(fn), err)
+			st.Warnf("cannot restore snap data preserved across factory reset, it is kept in %s: %v", fn, err)
+			continue
+		}
+		if err := os.Remove(fn); err != nil {
+			logger.Noticef("cannot remove restored snap data preserved across factory reset: %v", err)
+		}
+	}
+	// only drop the directory once all the data was restored
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTEMPTY) {
+		return err
+	}
+	return nil
+}
+
+func restorePreservedSnapshot(st *state.State, fn string) error {
+	r, err := backend.Open(fn, backend.ExtractFnameSetID)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	info, err := snapstate.CurrentInfo(st, r.Snap)
+	if err != nil {
+		return err
+	}
+
+	st.Unlock()
+	defer st.Lock()
+
+	rs, err := r.Restore(context.TODO(), info.Revision, nil, logger.Debugf, nil)
+	if err != nil {
+		return err
+	}
+	rs.Cleanup()
+	logger.Noticef("restored data of snap %q preserved across factory reset", r.Snap)
+	return nil
+}
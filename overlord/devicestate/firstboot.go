@@ -410,6 +410,28 @@ func (m *DeviceManager) populateStateFromSeedImpl(tm timings.Measurer) ([]*state
 	ts := tsAll[len(tsAll)-1]
 	endTs := state.NewTaskSet()
 
+	// a recovery system that captured the state of a device also carries
+	// the configuration of its snaps, which is set once all the snaps are
+	// installed and configured with the defaults from the gadget
+	if hasModeenv && mode == "run" && sysLabel != "" {
+		names := make([]string, 0, len(infos))
+		for _, info := range infos {
+			names = append(names, info.InstanceName())
+		}
+		capturedConfigTs, err := capturedSnapConfigTasks(st, sysLabel, names)
+		if err != nil {
+			return nil, err
+		}
+		if capturedConfigTs != nil {
+			if preseed {
+				capturedConfigTs.WaitFor(preseedDoneTask)
+			}
+			capturedConfigTs.WaitAll(ts)
+			capturedConfigTs.JoinLane(nonEssentialLane)
+			endTs.AddAll(capturedConfigTs)
+		}
+	}
+
 	// Start tracking any validation sets included in the seed after
 	// installing the included snaps.
 	if trackVss, err := maybeEnforceValidationSetsTask(st, model, mode); err != nil {
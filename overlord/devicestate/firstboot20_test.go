@@ -39,7 +39,9 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/configstate"
 	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/restart"
@@ -51,6 +53,7 @@ import (
 	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/sysconfig"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -642,6 +645,82 @@ func (s *firstBoot20Suite) testPopulateFromSeedCore20Happy(c *C, m *boot.Modeenv
 	}
 }
 
+func (s *firstBoot20Suite) TestPopulateFromSeedCore20RunModeCapturedConfig(c *C) {
+	restore := systemd.MockSystemctl(func(cmd ...string) ([]byte, error) {
+		return []byte("ActiveState=inactive\n"), nil
+	})
+	defer restore()
+
+	m := boot.Modeenv{
+		Mode:           "run",
+		RecoverySystem: "20191018",
+		Base:           "core20_1.snap",
+	}
+	defaultsGadgetYaml := `
+defaults:
+   system:
+      service:
+        foo: default
+        bar: default
+`
+	s.earlySetup(c, &m, asserts.ModelDangerous, defaultsGadgetYaml, populateFromSeedCore20Opts{})
+
+	// the configuration captured with the recovery system conflicts with
+	// the defaults of the gadget
+	capturedConfig := filepath.Join(boot.InitramfsUbuntuSaveDir, "device", "systems", "20191018", "captured-config.json")
+	c.Assert(os.MkdirAll(filepath.Dir(capturedConfig), 0700), IsNil)
+	c.Assert(os.WriteFile(capturedConfig, []byte(`{"core":{"service":{"foo":"captured"}},"pc":{"key":"value"}}`), 0600), IsNil)
+
+	var configcoreRuns []string
+	restore = configstate.MockConfigcoreRun(func(_ sysconfig.Device, tr configcore.RunTransaction) error {
+		var foo string
+		c.Assert(tr.Get("core", "service.foo", &foo), IsNil)
+		configcoreRuns = append(configcoreRuns, foo)
+		return nil
+	})
+	defer restore()
+
+	s.startOverlord(c)
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(s.overlord.DeviceManager(), s.perfTimings)
+	c.Assert(err, IsNil)
+
+	chg := st.NewChange("seed", "run the populate from seed changes")
+	for _, ts := range tsAll {
+		chg.AddAll(ts)
+	}
+	// avoid device reg
+	chg1 := st.NewChange("become-operational", "init device")
+	chg1.SetStatus(state.DoingStatus)
+
+	st.Unlock()
+	err = s.overlord.Settle(settleTimeout)
+	st.Lock()
+	c.Assert(err, IsNil)
+	restart.MockPending(st, restart.RestartUnset)
+	st.Unlock()
+	err = s.overlord.Settle(settleTimeout)
+	st.Lock()
+	c.Assert(err, IsNil)
+	c.Assert(chg.Status(), Equals, state.DoneStatus, Commentf("%s", chg.Err()))
+
+	// the system configuration went through configcore, first with the
+	// defaults from the gadget then with the captured values on top
+	c.Check(configcoreRuns, DeepEquals, []string{"default", "captured"})
+
+	tr := config.NewTransaction(st)
+	var val string
+	c.Assert(tr.Get("core", "service.foo", &val), IsNil)
+	c.Check(val, Equals, "captured")
+	c.Assert(tr.Get("core", "service.bar", &val), IsNil)
+	c.Check(val, Equals, "default")
+	// the configuration is set for snaps without a configure hook too
+	c.Assert(tr.Get("pc", "key", &val), IsNil)
+	c.Check(val, Equals, "value")
+}
+
 func (s *firstBoot20Suite) TestPopulateFromSeedCore20RunModeDangerousWithDevmode(c *C) {
 	m := boot.Modeenv{
 		Mode:           "run",
@@ -191,6 +191,20 @@ func (m *DeviceManager) doUpdateGadgetAssets(t *state.Task, _ *tomb.Tomb) error
 		updatePolicy = gadget.RemodelUpdatePolicy
 	}
 
+	// structures in A/B slots are written to the inactive slot only,
+	// which is then tried on the next boot
+	var activeSlot string
+	slotUpdated := false
+	if groundDeviceCtx.HasModeenv() && updateData.Info.HasSlots() {
+		activeSlot, err = boot.ActiveBootSlot(groundDeviceCtx)
+		if err != nil {
+			return fmt.Errorf("cannot get the active boot slot: %v", err)
+		}
+		updatePolicy = gadget.SlotUpdatePolicy(activeSlot, updatePolicy, func() {
+			slotUpdated = true
+		})
+	}
+
 	err = func() error {
 		var updateObserver gadget.ContentUpdateObserver
 		observeTrustedBootAssets, err := boot.TrustedAssetsUpdateObserverForModel(model, updateData.RootDir)
@@ -226,6 +240,17 @@ func (m *DeviceManager) doUpdateGadgetAssets(t *state.Task, _ *tomb.Tomb) error
 		logger.Noticef("failed to remove gadget update rollback directory %q: %v", snapRollbackDir, err)
 	}
 
+	if slotUpdated {
+		trySlot := gadget.OtherSlot(activeSlot)
+		if err := boot.SetTryBootSlot(groundDeviceCtx, trySlot); err != nil {
+			return fmt.Errorf("cannot set boot slot %q to be tried: %v", trySlot, err)
+		}
+		t.Logf("Boot slot %q will be tried on the next boot", trySlot)
+		// checked once restarted, to detect that the bootloader fell
+		// back to the active slot
+		t.Change().Set("try-boot-slot", trySlot)
+	}
+
 	// TODO: consider having the option to do this early via recovery in
 	// core20, have fallback code as well there
 	setGadgetRestartRequired(t)
@@ -275,6 +275,10 @@ func (m *DeviceManager) doRemoveRecoverySystem(t *state.Task, _ *tomb.Tomb) erro
 		return fmt.Errorf("cannot remove recovery system %q: %w", setup.Label, err)
 	}
 
+	if err := removeCapturedSnapConfig(setup.Label); err != nil {
+		return fmt.Errorf("cannot remove configuration captured with recovery system %q: %w", setup.Label, err)
+	}
+
 	t.SetStatus(state.DoneStatus)
 
 	return nil
@@ -360,12 +364,18 @@ func (m *DeviceManager) doCreateRecoverySystem(t *state.Task, _ *tomb.Tomb) (err
 	// creation could have been interrupted by an unexpected reboot;
 	// consider clearing the recovery system directory and restarting from
 	// scratch
-	_, err = createSystemForModelFromValidatedSnaps(st, model, label, db, &infoGetter, observeSnapFileWrite)
+	_, err = createSystemForModelFromValidatedSnaps(st, model, label, db, &infoGetter, setup.ExtraSnaps, observeSnapFileWrite)
 	if err != nil {
 		return fmt.Errorf("cannot create a recovery system with label %q for %v: %v", label, model.Model(), err)
 	}
 	logger.Debugf("recovery system dir: %v", systemDirectory)
 
+	if setup.CaptureConfig {
+		if err := captureSnapConfig(st, label); err != nil {
+			return fmt.Errorf("cannot capture snap configuration in recovery system %q: %v", label, err)
+		}
+	}
+
 	// 2. keep track of the system in task state
 	if err := setTaskRecoverySystemSetup(t, setup); err != nil {
 		return fmt.Errorf("cannot record recovery system setup state: %v", err)
@@ -451,6 +461,11 @@ func (m *DeviceManager) undoCreateRecoverySystem(t *state.Task, _ *tomb.Tomb) er
 	} else {
 		t.Logf("removed recovery system directory %v", setup.Directory)
 	}
+	if setup.CaptureConfig {
+		if err := removeCapturedSnapConfig(label); err != nil {
+			t.Logf("when removing configuration captured with recovery system %q: %v", label, err)
+		}
+	}
 
 	if err := boot.DropRecoverySystem(remodelCtx, label); err != nil {
 		return fmt.Errorf("cannot drop a current recovery system %q: %v", label, err)
@@ -20,16 +20,21 @@
 package devicestate
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/seed"
@@ -398,7 +403,8 @@ type snapWriteObserveFunc func(systemDir, where string) error
 
 // createSystemForModelFromValidatedSnaps creates a new recovery system for the
 // specified model with the specified label using the snaps in the database and
-// the getInfo function.
+// the getInfo function. The extra snaps, which are not part of the model, are
+// added to the recovery system too.
 //
 // The function returns the directory of the new recovery system as well as the
 // set of absolute file paths to the new snap files that were written for the
@@ -411,6 +417,7 @@ func createSystemForModelFromValidatedSnaps(
 	label string,
 	db asserts.RODatabase,
 	getInfo infoGetter,
+	extraSnaps []string,
 	observeWrite snapWriteObserveFunc,
 ) (dir string, err error) {
 	if model.Grade() == asserts.ModelGradeUnset {
@@ -523,6 +530,40 @@ func createSystemForModelFromValidatedSnaps(
 			return "", err
 		}
 	}
+	for _, name := range extraSnaps {
+		snapInfo, snapPath, present, err := getInfo.SnapInfo(st, name)
+		if err != nil {
+			return "", fmt.Errorf("cannot obtain extra snap information: %v", err)
+		}
+		if !present {
+			return "", fmt.Errorf("internal error: extra snap %q not present", name)
+		}
+		logger.Debugf("extra snap: %v", name)
+
+		// all the components of the snap which are present are added
+		var comps []seedwriter.OptionsComponent
+		modelComponents[name] = make(map[string]*snap.ComponentInfo)
+		for compName := range snapInfo.Components {
+			cref := naming.NewComponentRef(name, compName)
+			compInfo, compPath, present, err := getInfo.ComponentInfo(st, cref, snapInfo)
+			if err != nil {
+				return "", fmt.Errorf("cannot obtain component %q information: %v", cref, err)
+			}
+			if !present {
+				continue
+			}
+			comps = append(comps, seedwriter.OptionsComponent{
+				Path: compPath,
+			})
+			modelComponents[name][compPath] = compInfo
+		}
+
+		optsSnaps = append(optsSnaps, &seedwriter.OptionsSnap{
+			Path:       snapPath,
+			Components: comps,
+		})
+		modelSnaps[snapPath] = snapInfo
+	}
 	if err := w.SetOptionsSnaps(optsSnaps); err != nil {
 		return "", err
 	}
@@ -704,3 +745,122 @@ func createSystemForModelFromValidatedSnaps(
 
 	return recoverySystemDir, nil
 }
+
+// capturedSnapConfigFile returns the path of the file that holds the
+// configuration of the snaps captured with the given recovery system. The
+// configuration may carry secrets so it is kept on ubuntu-save, which is
+// encrypted along with ubuntu-data, rather than in the recovery system on
+// ubuntu-seed. As a consequence it is only applied when the system is seeded
+// on a device that kept ubuntu-save, like after a factory reset.
+func capturedSnapConfigFile(label string) string {
+	return filepath.Join(boot.InitramfsUbuntuSaveDir, "device", "systems", label, "captured-config.json")
+}
+
+// captureSnapConfig saves the configuration of the system and of all the
+// installed snaps for the given recovery system.
+func captureSnapConfig(st *state.State, label string) error {
+	if !osutil.IsDirectory(boot.InitramfsUbuntuSaveDir) {
+		return errors.New("ubuntu-save is not available")
+	}
+
+	all, err := snapstate.All(st)
+	if err != nil {
+		return err
+	}
+	// the system configuration is kept under core
+	names := []string{"core"}
+	for name := range all {
+		names = append(names, name)
+	}
+
+	captured := make(map[string]*json.RawMessage, len(names))
+	for _, name := range names {
+		cfg, err := config.GetSnapConfig(st, name)
+		if err != nil {
+			return err
+		}
+		if cfg != nil {
+			captured[name] = cfg
+		}
+	}
+
+	data, err := json.Marshal(captured)
+	if err != nil {
+		return err
+	}
+	fn := capturedSnapConfigFile(label)
+	if err := os.MkdirAll(filepath.Dir(fn), 0700); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(fn, data, 0600, 0)
+}
+
+// removeCapturedSnapConfig removes the configuration captured with the given
+// recovery system, if there is any.
+func removeCapturedSnapConfig(label string) error {
+	return os.RemoveAll(filepath.Dir(capturedSnapConfigFile(label)))
+}
+
+// capturedSnapConfigTasks returns the tasks that set the configuration
+// captured with the given recovery system for the system and for the given
+// snaps, if there is any. The configuration is set like any other with the
+// configure hooks, so that it is validated and the system options are
+// applied, but it is also set for snaps without a configure hook.
+func capturedSnapConfigTasks(st *state.State, label string, snapNames []string) (*state.TaskSet, error) {
+	data, err := os.ReadFile(capturedSnapConfigFile(label))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var captured map[string]map[string]any
+	if err := json.Unmarshal(data, &captured); err != nil {
+		return nil, fmt.Errorf("cannot decode captured configuration: %v", err)
+	}
+
+	ts := state.NewTaskSet()
+	var prev *state.Task
+	for _, name := range append([]string{"core"}, snapNames...) {
+		if len(captured[name]) == 0 {
+			continue
+		}
+		// the captured values are set one by one on top of the
+		// configuration from the gadget defaults
+		patch := make(map[string]any)
+		flattenCapturedSnapConfig("", captured[name], patch)
+		hooksup := &hookstate.HookSetup{
+			Snap:     name,
+			Hook:     "configure",
+			Optional: true,
+			Always:   true,
+			// as for the other configuration of core, see
+			// snapstate.ConfigureSnap
+			IgnoreError: name == "core",
+		}
+		summary := fmt.Sprintf(i18n.G("Apply configuration of %q snap captured with recovery system %q"), name, label)
+		t := hookstate.HookTask(st, summary, hooksup, map[string]any{"patch": patch})
+		if prev != nil {
+			t.WaitFor(prev)
+		}
+		ts.AddTask(t)
+		prev = t
+	}
+	if prev == nil {
+		return nil, nil
+	}
+	return ts, nil
+}
+
+func flattenCapturedSnapConfig(prefix string, cfg, patch map[string]any) {
+	for k, v := range cfg {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			flattenCapturedSnapConfig(k, m, patch)
+			continue
+		}
+		patch[k] = v
+	}
+}
@@ -344,7 +344,7 @@ func (s *createSystemSuite) TestCreateSystemFromAssertedSnaps(c *C) {
 		return nil
 	}
 
-	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, snapWriteObserver)
+	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, nil, snapWriteObserver)
 	c.Assert(err, IsNil)
 	c.Check(newFiles, DeepEquals, []string{
 		filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/snapd_4.snap"),
@@ -499,7 +499,7 @@ func (s *createSystemSuite) TestCreateSystemFromAssertedSnapsComponents(c *C) {
 		return nil
 	}
 
-	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, snapWriteObserver)
+	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, nil, snapWriteObserver)
 	c.Assert(err, IsNil)
 	c.Check(newFiles, DeepEquals, []string{
 		filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/snapd_4.snap"),
@@ -602,7 +602,7 @@ func (s *createSystemSuite) TestCreateSystemFromUnassertedSnaps(c *C) {
 		return nil
 	}
 
-	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, snapWriteObserver)
+	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, nil, snapWriteObserver)
 	c.Assert(err, IsNil)
 	c.Check(newFiles, DeepEquals, []string{
 		filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/snapd_4.snap"),
@@ -696,7 +696,7 @@ func (s *createSystemSuite) TestCreateSystemFromUnassertedSnapsComponents(c *C)
 		return nil
 	}
 
-	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, snapWriteObserver)
+	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, nil, snapWriteObserver)
 	c.Assert(err, IsNil)
 	c.Check(newFiles, DeepEquals, []string{
 		filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/snapd_4.snap"),
@@ -799,7 +799,7 @@ func (s *createSystemSuite) TestCreateSystemWithSomeSnapsAlreadyExisting(c *C) {
 
 	// when a given snap in asserted snaps directory already exists, it is
 	// not copied over
-	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, snapWriteObserver)
+	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db, &infoGetter, nil, snapWriteObserver)
 	c.Assert(err, IsNil)
 	c.Check(newFiles, DeepEquals, []string{
 		filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/snapd_4.snap"),
@@ -866,7 +866,7 @@ func (s *createSystemSuite) TestCreateSystemWithSomeSnapsAlreadyExisting(c *C) {
 	// directory, which triggers the error in creating the directory by
 	// seed writer
 	dir, err = devicestate.CreateSystemForModelFromValidatedSnaps(s.state, modelWithUnasserted, "1234unasserted", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 
 	c.Assert(err, ErrorMatches, `system "1234unasserted" already exists`)
 	// we failed early, no files were written yet
@@ -942,7 +942,7 @@ func (s *createSystemSuite) TestCreateSystemInfoAndAssertsChecks(c *C) {
 	// when a given snap in asserted snaps directory already exists, it is
 	// not copied over
 	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, `internal error: essential snap "pc" not present`)
 	c.Check(dir, Equals, "")
 	c.Check(observerCalls, Equals, 0)
@@ -955,7 +955,7 @@ func (s *createSystemSuite) TestCreateSystemInfoAndAssertsChecks(c *C) {
 
 	// and try with with a non essential snap
 	dir, err = devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, `internal error: non-essential but required snap "other-required" not present`)
 	c.Check(dir, Equals, "")
 	c.Check(observerCalls, Equals, 0)
@@ -966,7 +966,7 @@ func (s *createSystemSuite) TestCreateSystemInfoAndAssertsChecks(c *C) {
 	infos["other-required"] = s.makeSnap(c, "other-required", snap.R(5))
 
 	_, err = devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, `internal error: required component "snap-with-components\+comp-1" not present`)
 
 	info, comps := s.makeSnapWithComponents(c, "snap-with-components", snap.R(2), map[string]snap.Revision{
@@ -980,7 +980,7 @@ func (s *createSystemSuite) TestCreateSystemInfoAndAssertsChecks(c *C) {
 version: 1`, nil)
 	c.Assert(osutil.CopyFile(randomSnap, infos["pc"].MountFile(), osutil.CopyFlagOverwrite), IsNil)
 	_, err = devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, `internal error: no assertions for asserted snap with ID: pcididididididididididididididid`)
 	// we're past the start, so the system directory is there
 	c.Check(osutil.IsDirectory(systemDir), Equals, true)
@@ -1056,7 +1056,7 @@ func (s *createSystemSuite) TestCreateSystemGetInfoErr(c *C) {
 
 	failOn["pc"] = true
 	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, `cannot obtain essential snap information: mock failure for snap "pc"`)
 	c.Check(dir, Equals, "")
 	c.Check(observerCalls, Equals, 0)
@@ -1065,7 +1065,7 @@ func (s *createSystemSuite) TestCreateSystemGetInfoErr(c *C) {
 	failOn["pc"] = false
 	failOn["other-required"] = true
 	dir, err = devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, `cannot obtain non-essential but required snap information: mock failure for snap "other-required"`)
 	c.Check(dir, Equals, "")
 	c.Check(observerCalls, Equals, 0)
@@ -1096,7 +1096,7 @@ func (s *createSystemSuite) TestCreateSystemNonUC20(c *C) {
 		return fmt.Errorf("unexpected call")
 	}
 	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, `cannot create a system for pre-UC20 model`)
 	c.Check(dir, Equals, "")
 }
@@ -1142,7 +1142,7 @@ func (s *createSystemSuite) TestCreateSystemImplicitSnaps(c *C) {
 	}
 
 	dir, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, IsNil)
 	c.Check(newFiles, DeepEquals, []string{
 		filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/snapd_4.snap"),
@@ -1197,7 +1197,7 @@ func (s *createSystemSuite) TestCreateSystemObserverErr(c *C) {
 	}
 
 	_, err := devicestate.CreateSystemForModelFromValidatedSnaps(s.state, model, "1234", s.db,
-		&infoGetter, snapWriteObserver)
+		&infoGetter, nil, snapWriteObserver)
 	c.Assert(err, ErrorMatches, "mocked observer failure")
 	c.Check(newFiles, DeepEquals, []string{
 		filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/snapd_4.snap"),
@@ -25,6 +25,7 @@ import (
 
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/secboot"
@@ -185,13 +186,14 @@ func sealFallbackObjectKeys(
 	return nil
 }
 
-func sealKeyForBootChainsHook(method device.SealingMethod, key, saveKey secboot.BootstrappedContainer, params *boot.SealKeyForBootChainsParams) error {
+func sealKeyForBootChainsHook(method device.SealingMethod, key, saveKey secboot.BootstrappedContainer, volumesAuth *device.VolumesAuthOptions, params *boot.SealKeyForBootChainsParams) error {
 	if method != device.SealingMethodFDESetupHook {
 		return fmt.Errorf("internal error: sealKeyForBootChainsHook called with unsupported method %q", method)
 	}
 
 	sealingParams := secboot.SealKeysWithFDESetupHookParams{
-		PrimaryKey: params.PrimaryKey,
+		PrimaryKey:  params.PrimaryKey,
+		VolumesAuth: volumesAuth,
 	}
 
 	if !params.UseTokens {
@@ -212,6 +214,13 @@ func sealKeyForBootChainsHook(method device.SealingMethod, key, saveKey secboot.
 		return err
 	}
 
+	// remember where the keyfile is so that its passphrase can be changed
+	if loc := secboot.ProtectorKeyfileLocation(params.KeyProtectorFactory); loc != nil {
+		if err := device.WriteKeyfileLocation(dirs.SnapFDEDirUnder(params.InstallHostWritableDir), loc); err != nil {
+			return err
+		}
+	}
+
 	for _, container := range []secboot.BootstrappedContainer{
 		key,
 		saveKey,
@@ -236,8 +245,9 @@ func sealKeyForBootChainsBackend(
 	params *boot.SealKeyForBootChainsParams,
 ) error {
 	if method == device.SealingMethodFDESetupHook {
-		// volumes authentication is not supported when using secboot hooks
-		return sealKeyForBootChainsHook(method, key, saveKey, params)
+		// volumes authentication is only supported when the keys are
+		// protected by a keyfile
+		return sealKeyForBootChainsHook(method, key, saveKey, volumesAuth, params)
 	}
 
 	pbc := boot.ToPredictableBootChains(append(params.RunModeBootChains, params.RecoveryBootChains...))
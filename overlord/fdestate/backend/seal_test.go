@@ -637,6 +637,51 @@ func (s *sealSuite) TestSealToModeenvWithFdeHookHappyTokens(c *C) {
 	s.testSealToModeenvWithFdeHookHappy(c, useTokens)
 }
 
+func (s *sealSuite) TestSealToModeenvWithKeyfile(c *C) {
+	model := boottest.MakeMockUC20Model()
+
+	loc := device.KeyfileLocation{FilesystemLabel: "fde-key"}
+	factory := secboot.KeyfileKeyProtectorFactory(loc)
+	volumesAuth := &device.VolumesAuthOptions{Mode: device.AuthModePassphrase, Passphrase: "secret"}
+
+	calls := 0
+	restore := fdeBackend.MockSecbootSealKeysWithProtector(func(kf secboot.KeyProtectorFactory, keys []secboot.SealKeyRequest, params *secboot.SealKeysWithFDESetupHookParams) error {
+		calls++
+		c.Check(kf, Equals, factory)
+		c.Check(params.VolumesAuth, Equals, volumesAuth)
+		return nil
+	})
+	defer restore()
+
+	params := &boot.SealKeyForBootChainsParams{
+		BootChains: boot.BootChains{
+			RunModeBootChains: []boot.BootChain{
+				{
+					BrandID:        model.BrandID(),
+					Model:          model.Model(),
+					Classic:        model.Classic(),
+					Grade:          model.Grade(),
+					ModelSignKeyID: model.SignKeyID(),
+				},
+			},
+		},
+		InstallHostWritableDir: filepath.Join(boot.InstallUbuntuDataDir, "system-data"),
+		UseTokens:              true,
+		KeyProtectorFactory:    factory,
+	}
+	dataContainer := secboot.CreateMockBootstrappedContainer()
+	saveContainer := secboot.CreateMockBootstrappedContainer()
+	err := boot.SealKeyForBootChains(device.SealingMethodFDESetupHook, dataContainer, saveContainer, nil, volumesAuth, nil, params)
+	c.Assert(err, IsNil)
+	c.Check(calls, Equals, 1)
+
+	fdeDir := dirs.SnapFDEDirUnder(filepath.Join(dirs.GlobalRootDir, "/run/mnt/ubuntu-data/system-data"))
+	c.Check(filepath.Join(fdeDir, "sealed-keys"), testutil.FileEquals, "fde-setup-hook")
+	recorded, err := device.ReadKeyfileLocation(fdeDir)
+	c.Assert(err, IsNil)
+	c.Check(recorded, DeepEquals, &loc)
+}
+
 func (s *sealSuite) TestSealToModeenvWithFdeHookSad(c *C) {
 	model := boottest.MakeMockUC20Model()
 
@@ -137,6 +137,10 @@ func MockSecbootRenameContainerKey(f func(devicePath string, oldName string, new
 	return testutil.Mock(&secbootRenameContainerKey, f)
 }
 
+func MockSecbootChangeKeyfilePassphrase(f func(loc device.KeyfileLocation, oldPassphrase, newPassphrase string) error) (restore func()) {
+	return testutil.Mock(&secbootChangeKeyfilePassphrase, f)
+}
+
 func MockChangeAuthOptionsInCache(st *state.State, old, new string) (restore func()) {
 	st.Lock()
 	defer st.Unlock()
@@ -139,6 +139,7 @@ func Manager(st *state.State, runner *state.TaskRunner) (*FDEManager, error) {
 	runner.AddHandler("fde-remove-keys", m.doRemoveKeys, nil)
 	runner.AddHandler("fde-rename-keys", m.doRenameKeys, nil)
 	runner.AddHandler("fde-change-auth", m.doChangeAuth, nil)
+	runner.AddHandler("fde-change-keyfile-passphrase", m.doChangeKeyfilePassphrase, nil)
 	runner.AddHandler("fde-add-platform-keys", m.doAddPlatformKeys, nil)
 	runner.AddBlocked(func(t *state.Task, running []*state.Task) bool {
 		if isFDETask(t) {
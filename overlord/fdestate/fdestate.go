@@ -715,6 +715,9 @@ type changeAuthOptionsKey struct{}
 //   - container-role: system-data, name: default
 //   - container-role: system-data, name: default-fallback
 //   - container-role: system-save, name: default-fallback
+//
+// On systems where those key slots are protected by a keyfile, the
+// passphrase of the keyfile is changed instead.
 func ChangeAuth(st *state.State, authMode device.AuthMode, old, new string, keyslotRefs []KeyslotRef) (*state.TaskSet, error) {
 	switch authMode {
 	case device.AuthModePassphrase:
@@ -731,7 +734,8 @@ func ChangeAuth(st *state.State, authMode device.AuthMode, old, new string, keys
 		return nil, fmt.Errorf("internal error: unexpected authentication mode %q", authMode)
 	}
 
-	if len(keyslotRefs) == 0 {
+	defaultKeyslots := len(keyslotRefs) == 0
+	if defaultKeyslots {
 		// By default, target keys that would have been PIN/passphrase protected during installation.
 		keyslotRefs = append(keyslotRefs,
 			KeyslotRef{ContainerRole: "system-data", Name: "default"},
@@ -754,6 +758,16 @@ func ChangeAuth(st *state.State, authMode device.AuthMode, old, new string, keys
 		return nil, err
 	}
 
+	if authMode == device.AuthModePassphrase && defaultKeyslots {
+		loc, err := device.ReadKeyfileLocation(dirs.SnapFDEDir)
+		if err == nil {
+			return changeKeyfilePassphrase(st, loc, old, new), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
 	mgr := fdeMgr(st)
 
 	keyslots, missing, err := mgr.GetKeyslots(keyslotRefs)
@@ -801,6 +815,18 @@ func ChangeAuth(st *state.State, authMode device.AuthMode, old, new string, keys
 	return ts, nil
 }
 
+func changeKeyfilePassphrase(st *state.State, loc *device.KeyfileLocation, old, new string) *state.TaskSet {
+	// Auth data must be in memory to avoid leaking credentials.
+	if st.Cached(changeAuthOptionsKey{}) != nil {
+		logger.Noticef("WARNING: authentication change options already exists in memory")
+	}
+	st.Cache(changeAuthOptionsKey{}, &changeAuthOptions{old: old, new: new})
+
+	changePassphrase := st.NewTask("fde-change-keyfile-passphrase", "Change passphrase of keyfile")
+	changePassphrase.Set("keyfile-location", loc)
+	return state.NewTaskSet(changePassphrase)
+}
+
 // SystemEncryptedFromState reports whether FDE is enabled on the system.
 // It returns true if FDE is enabled, or false otherwise.
 func SystemEncryptedFromState(st *state.State) (bool, error) {
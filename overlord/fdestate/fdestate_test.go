@@ -568,6 +568,35 @@ func (s *fdeMgrSuite) TestChangeAuthModePIN(c *C) {
 	s.testChangeAuth(c, authMode, withWarning, defaultKeyslots)
 }
 
+func (s *fdeMgrSuite) TestChangeAuthKeyfile(c *C) {
+	defer fdestate.MockSecbootReadContainerKeyData(func(devicePath, slotName string) (secboot.KeyData, error) {
+		panic("unexpected key data read")
+	})()
+
+	loc := &device.KeyfileLocation{FilesystemLabel: "fde-key"}
+	c.Assert(device.WriteKeyfileLocation(dirs.SnapFDEDir, loc), IsNil)
+
+	onClassic := true
+	s.startedManager(c, onClassic)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	ts, err := fdestate.ChangeAuth(s.st, device.AuthModePassphrase, "old", "new", nil)
+	c.Assert(err, IsNil)
+	tsks := ts.Tasks()
+	c.Assert(tsks, HasLen, 1)
+	c.Check(tsks[0].Kind(), Equals, "fde-change-keyfile-passphrase")
+	c.Check(tsks[0].Summary(), Equals, "Change passphrase of keyfile")
+	var tskLoc device.KeyfileLocation
+	c.Assert(tsks[0].Get("keyfile-location", &tskLoc), IsNil)
+	c.Check(&tskLoc, DeepEquals, loc)
+
+	authOptions := fdestate.GetChangeAuthOptionsFromCache(s.st)
+	c.Check(authOptions.Old(), Equals, "old")
+	c.Check(authOptions.New(), Equals, "new")
+}
+
 func (s *fdeMgrSuite) TestChangeAuthErrors(c *C) {
 	defer fdestate.MockSecbootReadContainerKeyData(func(devicePath, slotName string) (secboot.KeyData, error) {
 		switch fmt.Sprintf("%s:%s", devicePath, slotName) {
@@ -38,6 +38,7 @@ var (
 	secbootAddContainerTPMProtectedKey = secboot.AddContainerTPMProtectedKey
 	secbootDeleteContainerKey          = secboot.DeleteContainerKey
 	secbootRenameContainerKey          = secboot.RenameContainerKey
+	secbootChangeKeyfilePassphrase     = secboot.ChangeKeyfilePassphrase
 )
 
 func (m *FDEManager) doAddRecoveryKeys(t *state.Task, tomb *tomb.Tomb) (err error) {
@@ -477,3 +478,38 @@ func (m *FDEManager) doChangeAuth(t *state.Task, _ *tomb.Tomb) (err error) {
 
 	return nil
 }
+
+func (m *FDEManager) doChangeKeyfilePassphrase(t *state.Task, _ *tomb.Tomb) error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	var loc device.KeyfileLocation
+	if err := t.Get("keyfile-location", &loc); err != nil {
+		return err
+	}
+
+	cached := m.state.Cached(changeAuthOptionsKey{})
+	if cached == nil {
+		return errors.New("cannot find authentication options in memory: unexpected snapd restart")
+	}
+	opts, ok := cached.(*changeAuthOptions)
+	if !ok {
+		return fmt.Errorf("internal error: wrong data type under changeAuthOptionsKey: %T", cached)
+	}
+
+	if opts.old != opts.new {
+		// the keyfile device may take a while to show up
+		m.state.Unlock()
+		err := secbootChangeKeyfilePassphrase(loc, opts.old, opts.new)
+		m.state.Lock()
+		if err != nil {
+			return fmt.Errorf("cannot change passphrase of keyfile: %v", err)
+		}
+	}
+
+	// avoid re-runs in case of abrupt shutdown since the keyfile is now updated.
+	t.SetStatus(state.DoneStatus)
+	m.state.Cache(changeAuthOptionsKey{}, nil)
+
+	return nil
+}
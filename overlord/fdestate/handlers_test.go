@@ -886,6 +886,77 @@ func (s *fdeMgrSuite) TestDoChangeAuthKeysNoop(c *C) {
 	c.Check(chg.Status(), Equals, state.DoneStatus)
 }
 
+func (s *fdeMgrSuite) TestDoChangeKeyfilePassphrase(c *C) {
+	const onClassic = true
+	s.startedManager(c, onClassic)
+
+	loc := device.KeyfileLocation{FilesystemLabel: "fde-key", Path: "device.key"}
+	for _, tc := range []struct {
+		old, new    string
+		err         error
+		expectedErr string
+		calls       int
+	}{
+		{old: "old", new: "new", calls: 1},
+		{old: "old", new: "old"},
+		{old: "old", new: "new", err: errors.New("boom"), calls: 1, expectedErr: `(?s).*cannot change passphrase of keyfile: boom.*`},
+	} {
+		calls := 0
+		restore := fdestate.MockSecbootChangeKeyfilePassphrase(func(l device.KeyfileLocation, old, new string) error {
+			calls++
+			c.Check(l, Equals, loc)
+			c.Check(old, Equals, tc.old)
+			c.Check(new, Equals, tc.new)
+			return tc.err
+		})
+
+		restoreCache := fdestate.MockChangeAuthOptionsInCache(s.st, tc.old, tc.new)
+
+		s.st.Lock()
+		task := s.st.NewTask("fde-change-keyfile-passphrase", "test")
+		task.Set("keyfile-location", loc)
+		chg := s.st.NewChange("sample", "...")
+		chg.AddTask(task)
+
+		s.settle(c)
+
+		c.Check(calls, Equals, tc.calls)
+		if tc.expectedErr != "" {
+			c.Check(chg.Status(), Equals, state.ErrorStatus)
+			c.Check(chg.Err(), ErrorMatches, tc.expectedErr)
+		} else {
+			c.Check(chg.Status(), Equals, state.DoneStatus)
+			c.Check(fdestate.GetChangeAuthOptionsFromCache(s.st), IsNil)
+		}
+		restoreCache()
+		s.st.Unlock()
+
+		restore()
+	}
+}
+
+func (s *fdeMgrSuite) TestDoChangeKeyfilePassphraseNoAuthOptions(c *C) {
+	const onClassic = true
+	s.startedManager(c, onClassic)
+
+	defer fdestate.MockSecbootChangeKeyfilePassphrase(func(l device.KeyfileLocation, old, new string) error {
+		panic("unexpected")
+	})()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	task := s.st.NewTask("fde-change-keyfile-passphrase", "test")
+	task.Set("keyfile-location", device.KeyfileLocation{FilesystemLabel: "fde-key"})
+	chg := s.st.NewChange("sample", "...")
+	chg.AddTask(task)
+
+	s.settle(c)
+
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	c.Check(chg.Err(), ErrorMatches, `(?s).*cannot find authentication options in memory: unexpected snapd restart.*`)
+}
+
 func (s *fdeMgrSuite) TestDoAddPlatformKeys(c *C) {
 	const onClassic = true
 	s.startedManager(c, onClassic)
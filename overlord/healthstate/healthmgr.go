@@ -0,0 +1,231 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package healthstate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	checkHealthChangeKind    = swfeats.RegisterChangeKind("check-snap-health")
+	restartServiceChangeKind = swfeats.RegisterChangeKind("restart-unhealthy-services")
+)
+
+func init() {
+	swfeats.RegisterEnsure("HealthManager", "ensurePeriodicChecks")
+}
+
+// scanInterval is how often snaps are looked at for due periodic health
+// checks.
+var scanInterval = time.Minute
+
+var timeNow = time.Now
+
+var servicestateControl = servicestate.Control
+
+// HealthManager runs the check-health hook of snaps that declare a periodic
+// health interval, and restarts the services of snaps that keep reporting
+// an error health if they ask for it.
+type HealthManager struct {
+	state    *state.State
+	nextScan time.Time
+}
+
+// Manager returns a new HealthManager.
+func Manager(st *state.State) *HealthManager {
+	return &HealthManager{state: st}
+}
+
+// Ensure implements StateManager.Ensure.
+func (m *HealthManager) Ensure() error {
+	now := timeNow()
+	if now.Before(m.nextScan) {
+		return nil
+	}
+	m.nextScan = now.Add(scanInterval)
+
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	return m.ensurePeriodicChecks(now)
+}
+
+func (m *HealthManager) ensurePeriodicChecks(now time.Time) error {
+	st := m.state
+
+	var seeded bool
+	if err := st.Get("seeded", &seeded); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	logger.Trace("ensure", "manager", "HealthManager", "func", "ensurePeriodicChecks")
+
+	snapStates, err := snapstate.All(st)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(snapStates))
+	for name := range snapStates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pending := pendingHealthChanges(st)
+	for _, name := range names {
+		snapst := snapStates[name]
+		if !snapst.Active || pending[name] {
+			continue
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			logger.Debugf("cannot read current info of snap %q: %v", name, err)
+			continue
+		}
+		if info.Health == nil || info.Health.Interval <= 0 {
+			continue
+		}
+		// anything else going on with the snap takes precedence
+		if err := snapstate.CheckChangeConflict(st, name, nil); err != nil {
+			continue
+		}
+
+		if err := m.maybeRestartServices(info); err != nil {
+			logger.Noticef("cannot restart services of unhealthy snap %q: %v", name, err)
+		}
+
+		health, err := Get(st, name)
+		if err != nil {
+			return err
+		}
+		if health != nil && health.Revision == snapst.Current && now.Sub(health.Timestamp) < time.Duration(info.Health.Interval) {
+			continue
+		}
+
+		chg := st.NewChange(checkHealthChangeKind, fmt.Sprintf("Run periodic health check of %q snap", name))
+		chg.AddTask(Hook(st, name, snapst.Current))
+		chg.Set("snap-names", []string{name})
+	}
+
+	return nil
+}
+
+// pendingHealthChanges returns the snaps that have a periodic health check
+// or a restart of their services still in progress.
+func pendingHealthChanges(st *state.State) map[string]bool {
+	pending := make(map[string]bool)
+	for _, chg := range st.Changes() {
+		if chg.IsReady() || (chg.Kind() != checkHealthChangeKind && chg.Kind() != restartServiceChangeKind) {
+			continue
+		}
+		var snapNames []string
+		if err := chg.Get("snap-names", &snapNames); err != nil {
+			continue
+		}
+		for _, name := range snapNames {
+			pending[name] = true
+		}
+	}
+	return pending
+}
+
+// consecutiveErrors returns the number of error healths the snap reported
+// in a row for the given revision since the given time.
+func consecutiveErrors(history []*HealthState, rev snap.Revision, since time.Time) int {
+	n := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if h.Status != ErrorStatus || h.Revision != rev || !h.Timestamp.After(since) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// maybeRestartServices restarts the services of the snap if it reported
+// enough consecutive error healths since they were last restarted.
+func (m *HealthManager) maybeRestartServices(info *snap.Info) error {
+	if info.Health.RestartServicesAfter <= 0 {
+		return nil
+	}
+	st := m.state
+	name := info.InstanceName()
+
+	var restarts map[string]time.Time
+	if err := st.Get("health-restarts", &restarts); err != nil {
+		if !errors.Is(err, state.ErrNoState) {
+			return err
+		}
+		restarts = map[string]time.Time{}
+	}
+
+	history, err := History(st, name)
+	if err != nil {
+		return err
+	}
+	n := consecutiveErrors(history, info.Revision, restarts[name])
+	if n < info.Health.RestartServicesAfter {
+		return nil
+	}
+
+	var svcs []*snap.AppInfo
+	for _, app := range info.Services() {
+		if app.DaemonScope == snap.SystemDaemon {
+			svcs = append(svcs, app)
+		}
+	}
+	if len(svcs) == 0 {
+		return nil
+	}
+
+	inst := &servicestate.Instruction{
+		Action: "restart",
+		Scope:  client.ScopeSelector{"system"},
+	}
+	tss, err := servicestateControl(st, svcs, inst, nil, &servicestate.Flags{}, nil)
+	if err != nil {
+		return err
+	}
+	chg := st.NewChange(restartServiceChangeKind, fmt.Sprintf("Restart services of %q snap after %d failed health checks", name, n))
+	for _, ts := range tss {
+		chg.AddAll(ts)
+	}
+	chg.Set("snap-names", []string{name})
+
+	restarts[name] = history[len(history)-1].Timestamp
+	st.Set("health-restarts", restarts)
+	logger.Noticef("restarting services of snap %q after %d consecutive failed health checks", name, n)
+
+	return nil
+}
@@ -0,0 +1,245 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package healthstate_test
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil/user"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+const periodicSnapYaml = `name: periodic-snap
+version: v1
+apps:
+  svc:
+    command: bin/svc
+    daemon: simple
+hooks:
+  check-health:
+health:
+  interval: 5m
+  restart-services-after: 2
+`
+
+type healthMgrSuite struct {
+	testutil.BaseTest
+	state *state.State
+	mgr   *healthstate.HealthManager
+	now   time.Time
+
+	controlCalls []*servicestate.Instruction
+}
+
+var _ = check.Suite(&healthMgrSuite{})
+
+func (s *healthMgrSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	s.now = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.AddCleanup(healthstate.MockTimeNow(func() time.Time { return s.now }))
+	s.controlCalls = nil
+	s.AddCleanup(healthstate.MockServicestateControl(func(st *state.State, appInfos []*snap.AppInfo, inst *servicestate.Instruction, cu *user.User, flags *servicestate.Flags, context *hookstate.Context) ([]*state.TaskSet, error) {
+		c.Assert(appInfos, check.HasLen, 1)
+		c.Check(appInfos[0].Name, check.Equals, "svc")
+		s.controlCalls = append(s.controlCalls, inst)
+		return []*state.TaskSet{state.NewTaskSet(st.NewTask("exec-command", "restart"))}, nil
+	}))
+
+	s.state = state.New(nil)
+	s.mgr = healthstate.Manager(s.state)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("seeded", true)
+	sideInfo := &snap.SideInfo{RealName: "periodic-snap", Revision: snap.R(7)}
+	snapstate.Set(s.state, "periodic-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{sideInfo}),
+		Current:  snap.R(7),
+		Active:   true,
+		SnapType: "app",
+	})
+	snaptest.MockSnapCurrent(c, periodicSnapYaml, sideInfo)
+}
+
+func (s *healthMgrSuite) changes(kind string) []*state.Change {
+	var chgs []*state.Change
+	for _, chg := range s.state.Changes() {
+		if chg.Kind() == kind {
+			chgs = append(chgs, chg)
+		}
+	}
+	return chgs
+}
+
+func (s *healthMgrSuite) TestEnsureSchedulesCheck(c *check.C) {
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chgs := s.changes("check-snap-health")
+	c.Assert(chgs, check.HasLen, 1)
+	c.Check(chgs[0].Summary(), check.Equals, `Run periodic health check of "periodic-snap" snap`)
+	tasks := chgs[0].Tasks()
+	c.Assert(tasks, check.HasLen, 1)
+	var hooksup hookstate.HookSetup
+	c.Assert(tasks[0].Get("hook-setup", &hooksup), check.IsNil)
+	c.Check(hooksup.Snap, check.Equals, "periodic-snap")
+	c.Check(hooksup.Hook, check.Equals, "check-health")
+	c.Check(hooksup.Revision, check.Equals, snap.R(7))
+}
+
+func (s *healthMgrSuite) TestEnsureNotSeeded(c *check.C) {
+	s.state.Lock()
+	s.state.Set("seeded", false)
+	s.state.Unlock()
+
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.state.Changes(), check.HasLen, 0)
+}
+
+func (s *healthMgrSuite) TestEnsureNotDue(c *check.C) {
+	s.state.Lock()
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"periodic-snap": {Revision: snap.R(7), Timestamp: s.now.Add(-4*time.Minute - 30*time.Second), Status: healthstate.OkayStatus},
+	})
+	s.state.Unlock()
+
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+
+	s.state.Lock()
+	c.Check(s.state.Changes(), check.HasLen, 0)
+	s.state.Unlock()
+
+	// the check becomes due once the interval has passed, but only
+	// after the next scan
+	s.now = s.now.Add(40 * time.Second)
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+	s.state.Lock()
+	c.Check(s.state.Changes(), check.HasLen, 0)
+	s.state.Unlock()
+
+	s.now = s.now.Add(30 * time.Second)
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+	s.state.Lock()
+	c.Check(s.changes("check-snap-health"), check.HasLen, 1)
+	s.state.Unlock()
+}
+
+func (s *healthMgrSuite) TestEnsureSkipsSnapsWithChanges(c *check.C) {
+	s.state.Lock()
+	chg := s.state.NewChange("check-snap-health", "...")
+	chg.AddTask(healthstate.Hook(s.state, "periodic-snap", snap.R(7)))
+	chg.Set("snap-names", []string{"periodic-snap"})
+	s.state.Unlock()
+
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.changes("check-snap-health"), check.HasLen, 1)
+}
+
+func (s *healthMgrSuite) TestEnsureRestartsUnhealthyServices(c *check.C) {
+	s.state.Lock()
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"periodic-snap": {Revision: snap.R(7), Timestamp: s.now.Add(-time.Minute), Status: healthstate.ErrorStatus},
+	})
+	s.state.Set("health-history", map[string][]*healthstate.HealthState{
+		"periodic-snap": {
+			{Revision: snap.R(7), Timestamp: s.now.Add(-11 * time.Minute), Status: healthstate.ErrorStatus},
+			{Revision: snap.R(7), Timestamp: s.now.Add(-6 * time.Minute), Status: healthstate.OkayStatus},
+			{Revision: snap.R(7), Timestamp: s.now.Add(-time.Minute), Status: healthstate.ErrorStatus},
+		},
+	})
+	s.state.Unlock()
+
+	// a single error since the last okay health is not enough
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+	c.Check(s.controlCalls, check.HasLen, 0)
+
+	s.state.Lock()
+	var history map[string][]*healthstate.HealthState
+	c.Assert(s.state.Get("health-history", &history), check.IsNil)
+	history["periodic-snap"] = append(history["periodic-snap"], &healthstate.HealthState{
+		Revision: snap.R(7), Timestamp: s.now.Add(-30 * time.Second), Status: healthstate.ErrorStatus,
+	})
+	s.state.Set("health-history", history)
+	s.state.Unlock()
+
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+	c.Assert(s.controlCalls, check.HasLen, 1)
+	c.Check(s.controlCalls[0].Action, check.Equals, "restart")
+
+	s.state.Lock()
+	chgs := s.changes("restart-unhealthy-services")
+	c.Assert(chgs, check.HasLen, 1)
+	c.Check(chgs[0].Summary(), check.Equals, `Restart services of "periodic-snap" snap after 2 failed health checks`)
+	// make the restart look finished
+	for _, t := range chgs[0].Tasks() {
+		t.SetStatus(state.DoneStatus)
+	}
+	s.state.Unlock()
+
+	// errors from before the restart do not count again
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+	c.Check(s.controlCalls, check.HasLen, 1)
+}
+
+func (s *healthMgrSuite) TestHistoryIsCapped(c *check.C) {
+	s.AddCleanup(healthstate.MockMaxHistory(2))
+
+	ctx, err := hookstate.NewContext(nil, s.state, &hookstate.HookSetup{Snap: "periodic-snap", Revision: snap.R(7)}, nil, "")
+	c.Assert(err, check.IsNil)
+
+	ctx.Lock()
+	defer ctx.Unlock()
+
+	for _, msg := range []string{"one", "two", "three"} {
+		ctx.Set("health", &healthstate.HealthState{Status: healthstate.ErrorStatus, Message: msg})
+		c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+	}
+
+	history, err := healthstate.History(s.state, "periodic-snap")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 2)
+	c.Check(history[0].Message, check.Equals, "two")
+	c.Check(history[1].Message, check.Equals, "three")
+}
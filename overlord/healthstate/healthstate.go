@@ -47,6 +47,44 @@ func init() {
 	}
 
 	snapstate.CheckHealthHook = Hook
+	snapstate.GetSnapHealth = snapHealth
+	snapstate.DiscardSnapHealth = discardSnapHealth
+}
+
+// discardSnapHealth removes the health, the health history and the record of
+// services restarts of the given snap.
+func discardSnapHealth(st *state.State, instanceName string) error {
+	for _, key := range []string{"health", "health-history", "health-restarts"} {
+		var entries map[string]json.RawMessage
+		if err := st.Get(key, &entries); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				continue
+			}
+			return err
+		}
+		if _, ok := entries[instanceName]; !ok {
+			continue
+		}
+		delete(entries, instanceName)
+		if len(entries) == 0 {
+			st.Set(key, nil)
+		} else {
+			st.Set(key, entries)
+		}
+	}
+	return nil
+}
+
+func snapHealth(st *state.State, instanceName string) (*snapstate.SnapHealth, error) {
+	health, err := Get(st, instanceName)
+	if err != nil || health == nil {
+		return nil, err
+	}
+	return &snapstate.SnapHealth{
+		Revision: health.Revision,
+		Status:   health.Status.String(),
+		Message:  health.Message,
+	}, nil
 }
 
 func Hook(st *state.State, snapName string, snapRev snap.Revision) *state.Task {
@@ -177,6 +215,27 @@ func appendHealth(ctx *hookstate.Context, health *HealthState) error {
 	hs[ctx.InstanceName()] = health
 	st.Set("health", hs)
 
+	return appendHistory(st, ctx.InstanceName(), health)
+}
+
+// maxHistory is the number of health entries kept per snap.
+var maxHistory = 10
+
+func appendHistory(st *state.State, snapName string, health *HealthState) error {
+	var history map[string][]*HealthState
+	if err := st.Get("health-history", &history); err != nil {
+		if !errors.Is(err, state.ErrNoState) {
+			return err
+		}
+		history = map[string][]*HealthState{}
+	}
+	entries := append(history[snapName], health)
+	if len(entries) > maxHistory {
+		entries = entries[len(entries)-maxHistory:]
+	}
+	history[snapName] = entries
+	st.Set("health-history", history)
+
 	return nil
 }
 
@@ -225,3 +284,12 @@ func Get(st *state.State, snap string) (*HealthState, error) {
 
 	return &health, nil
 }
+
+// History returns the most recent healths of the given snap, oldest first.
+func History(st *state.State, snap string) ([]*HealthState, error) {
+	var history map[string][]*HealthState
+	if err := st.Get("health-history", &history); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	return history[snap], nil
+}
@@ -253,3 +253,68 @@ func (s *healthSuite) TestSetFromHookContextEmpty(c *check.C) {
 	// no health in the context -> no health in state
 	c.Check(s.state.Get("health", &hs), testutil.ErrorIs, state.ErrNoState)
 }
+
+func (s *healthSuite) TestGetSnapHealth(c *check.C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	health, err := snapstate.GetSnapHealth(s.state, "test-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(health, check.IsNil)
+
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(42), Status: healthstate.ErrorStatus, Message: "on fire"},
+	})
+
+	health, err = snapstate.GetSnapHealth(s.state, "test-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(health, check.DeepEquals, &snapstate.SnapHealth{
+		Revision: snap.R(42),
+		Status:   "error",
+		Message:  "on fire",
+	})
+}
+
+func (s *healthSuite) TestDiscardSnapHealth(c *check.C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// nothing in state is fine
+	c.Assert(snapstate.DiscardSnapHealth(s.state, "test-snap"), check.IsNil)
+
+	hs := &healthstate.HealthState{Revision: snap.R(42), Status: healthstate.ErrorStatus, Message: "on fire"}
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"test-snap":  hs,
+		"other-snap": hs,
+	})
+	s.state.Set("health-history", map[string][]*healthstate.HealthState{
+		"test-snap": {hs},
+	})
+	s.state.Set("health-restarts", map[string]time.Time{
+		"test-snap":  time.Now(),
+		"other-snap": time.Now(),
+	})
+
+	c.Assert(snapstate.DiscardSnapHealth(s.state, "test-snap"), check.IsNil)
+
+	var health map[string]*healthstate.HealthState
+	c.Assert(s.state.Get("health", &health), check.IsNil)
+	c.Check(health, check.HasLen, 1)
+	c.Check(health["other-snap"], check.NotNil)
+
+	var histories map[string][]*healthstate.HealthState
+	c.Check(s.state.Get("health-history", &histories), testutil.ErrorIs, state.ErrNoState)
+
+	var restarts map[string]time.Time
+	c.Assert(s.state.Get("health-restarts", &restarts), check.IsNil)
+	c.Check(restarts, check.HasLen, 1)
+	c.Check(restarts["other-snap"].IsZero(), check.Equals, false)
+
+	history, err := healthstate.History(s.state, "test-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(history, check.HasLen, 0)
+}
+
+func (s *healthSuite) TestEnsureLoopLogging(c *check.C) {
+	testutil.CheckEnsureLoopLogging("healthmgr.go", c, true)
+}
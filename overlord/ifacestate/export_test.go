@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
+	"github.com/snapcore/snapd/wrappers"
 )
 
 var (
@@ -102,6 +103,12 @@ func MockRemoveStaleConnections(f func(st *state.State) error) (restore func())
 	return func() { removeStaleConnections = old }
 }
 
+func MockWrappersEnsureSnapServiceDependencies(f func(s *snap.Info, deps map[string][]string, opts *wrappers.EnsureSnapServicesOptions, inter wrappers.Interacter) error) (restore func()) {
+	r := testutil.Backup(&wrappersEnsureSnapServiceDependencies)
+	wrappersEnsureSnapServiceDependencies = f
+	return r
+}
+
 func MockSnapdAppArmorServiceIsDisabled(f func() bool) (restore func()) {
 	r := testutil.Backup(&snapdAppArmorServiceIsDisabled)
 	snapdAppArmorServiceIsDisabled = f
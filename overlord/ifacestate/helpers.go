@@ -42,9 +42,11 @@ import (
 	"github.com/snapcore/snapd/overlord/ifacestate/schema"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/timings"
+	"github.com/snapcore/snapd/wrappers"
 )
 
 func init() {
@@ -55,6 +57,8 @@ var (
 	snapdAppArmorServiceIsDisabled = snapdAppArmorServiceIsDisabledImpl
 
 	writeSystemKey = interfaces.WriteSystemKey
+
+	wrappersEnsureSnapServiceDependencies = wrappers.EnsureSnapServiceDependencies
 )
 
 func (m *InterfaceManager) selectInterfaceMapper(appSets []*interfaces.SnapAppSet) {
@@ -604,6 +608,45 @@ func (m *InterfaceManager) setupSecurityByBackend(task *state.Task, appSets []*i
 		}
 	}
 
+	for _, set := range appSets {
+		if err := m.setupServiceDependencies(set.Info()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setupServiceDependencies orders the services of the given snap after the
+// services of the snaps providing the slots connected to the plugs listed in
+// their after-connected attribute. It must be called with the state unlocked.
+func (m *InterfaceManager) setupServiceDependencies(snapInfo *snap.Info) error {
+	deps := make(map[string][]string)
+	for _, app := range snapInfo.Services() {
+		for _, plug := range app.AfterConnected {
+			connRefs, err := m.repo.Connected(snapInfo.InstanceName(), plug.Name)
+			if err != nil {
+				return err
+			}
+			for _, connRef := range connRefs {
+				slot := m.repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+				if slot == nil {
+					continue
+				}
+				for _, slotApp := range slot.Apps {
+					if !slotApp.IsService() || slotApp.DaemonScope != snap.SystemDaemon {
+						continue
+					}
+					deps[app.Name] = append(deps[app.Name], slotApp.ServiceName())
+				}
+			}
+		}
+	}
+
+	opts := &wrappers.EnsureSnapServicesOptions{Preseeding: m.preseed}
+	if err := wrappersEnsureSnapServiceDependencies(snapInfo, deps, opts, progress.Null); err != nil {
+		return fmt.Errorf("cannot setup service dependencies of snap %q: %v", snapInfo.InstanceName(), err)
+	}
 	return nil
 }
 
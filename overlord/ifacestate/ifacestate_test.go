@@ -65,6 +65,7 @@ import (
 	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
+	"github.com/snapcore/snapd/wrappers"
 )
 
 func TestInterfaceManager(t *testing.T) { TestingT(t) }
@@ -5340,6 +5341,90 @@ func (s *interfaceManagerSuite) TestConnectSetsUpSecurity(c *C) {
 	c.Check(s.secBackend.SetupCalls[1].Options, DeepEquals, interfaces.ConfinementOptions{KernelSnap: "krnl"})
 }
 
+func (s *interfaceManagerSuite) TestConnectDisconnectServiceDependencies(c *C) {
+	s.MockModel(c, nil)
+
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, `name: consumer
+version: 1
+plugs:
+ plug:
+  interface: test
+apps:
+ app:
+  daemon: simple
+  after-connected: [plug]
+ other:
+  daemon: simple
+`)
+	s.mockSnap(c, `name: producer
+version: 1
+slots:
+ slot:
+  interface: test
+apps:
+ db:
+  daemon: simple
+  slots: [slot]
+ user-db:
+  daemon: simple
+  daemon-scope: user
+  slots: [slot]
+ cli:
+  slots: [slot]
+`)
+
+	deps := make(map[string]map[string][]string)
+	restore := ifacestate.MockWrappersEnsureSnapServiceDependencies(func(s *snap.Info, d map[string][]string, opts *wrappers.EnsureSnapServicesOptions, inter wrappers.Interacter) error {
+		c.Check(opts, DeepEquals, &wrappers.EnsureSnapServicesOptions{})
+		deps[s.InstanceName()] = d
+		return nil
+	})
+	defer restore()
+
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	ts, err := ifacestate.Connect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	change := s.state.NewChange("connect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	c.Assert(change.Err(), IsNil)
+	s.state.Unlock()
+
+	// only system services of the slot side are waited for
+	c.Check(deps, DeepEquals, map[string]map[string][]string{
+		"consumer": {"app": {"snap.producer.db.service"}},
+		"producer": {},
+	})
+
+	conn := s.getConnection(c, "consumer", "plug", "producer", "slot")
+	s.state.Lock()
+	ts, err = ifacestate.Disconnect(s.state, conn)
+	c.Assert(err, IsNil)
+	change = s.state.NewChange("disconnect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(change.Err(), IsNil)
+	c.Check(mgr.Repository().Interfaces().Connections, HasLen, 0)
+
+	// the dependencies are dropped with the connection
+	c.Check(deps, DeepEquals, map[string]map[string][]string{
+		"consumer": {},
+		"producer": {},
+	})
+}
+
 func (s *interfaceManagerSuite) TestConnectWithComponentsSetsUpSecurity(c *C) {
 	s.MockModel(c, nil)
 
@@ -279,10 +279,11 @@ func GetEncryptionSupportInfo(constraints EncryptionConstraints, runSetupHook fd
 		return res, nil
 	}
 
-	// check encryption: this can either be provided by one of three mechanisms,
+	// check encryption: this can either be provided by one of four mechanisms,
 	// and they are checked in this order:
 	// - the fde-setup hook
 	// - the optee trusted application
+	// - a keyfile declared by the gadget
 	// - the built-in secboot based encryption
 
 	checkFDESetupHookEncryption := hasFDESetupHookInKernel(constraints.Kernel)
@@ -291,13 +292,20 @@ func GetEncryptionSupportInfo(constraints EncryptionConstraints, runSetupHook fd
 	// tpm if we can't see optee. this means that this function won't return any
 	// optee-specific errors/warnings. change this?
 	checkOPTEEEncryption := !checkFDESetupHookEncryption && secbootFDEOpteeTAPresent()
-	checkSecbootEncryption := !checkOPTEEEncryption
+	// the keyfile does not need any support from the hardware and is meant
+	// for devices that cannot protect the keys otherwise
+	checkKeyfileEncryption := !checkFDESetupHookEncryption && !checkOPTEEEncryption &&
+		constraints.Gadget != nil && constraints.Gadget.FDEKeyfile != nil
+	// note that this is also set when the fde-setup hook is used, which is
+	// checked first below, so that passphrase and PIN authentication are
+	// disabled for hook based encryption too
+	checkSecbootEncryption := !checkOPTEEEncryption && !checkKeyfileEncryption
 
 	var checkEncryptionErr error
 	switch {
 	case checkFDESetupHookEncryption:
 		res.Type, checkEncryptionErr = checkFDEFeatures(runSetupHook)
-	case checkOPTEEEncryption:
+	case checkOPTEEEncryption, checkKeyfileEncryption:
 		res.Type = device.EncryptionTypeLUKS
 	case checkSecbootEncryption:
 		preinstallCheckContext, unavailableReason, preinstallErrorDetails, err := encryptionAvailabilityCheck(
@@ -351,6 +359,10 @@ func GetEncryptionSupportInfo(constraints EncryptionConstraints, runSetupHook fd
 			res.PassphraseAuthAvailable = false
 			res.PINAuthAvailable = false
 		}
+		if checkKeyfileEncryption {
+			// the keyfile can optionally be protected by a passphrase
+			res.PassphraseAuthAvailable = true
+		}
 		opts := &gadget.ValidationConstraints{
 			EncryptedData: true,
 		}
@@ -1186,6 +1186,61 @@ func (s *installSuite) TestEncryptionSupportInfoFallbacks(c *C) {
 	}
 }
 
+func (s *installSuite) TestEncryptionSupportInfoKeyfile(c *C) {
+	kernelInfo := s.kernelSnap(c, "pc-kernel=20")
+	gadgetInfo, _ := s.mountedGadget(c)
+	gadgetInfo.FDEKeyfile = &device.KeyfileLocation{FilesystemLabel: "fde-key"}
+
+	opteeChecked := false
+	restore := install.MockSecbootFDEOpteeTAPresent(func() bool {
+		opteeChecked = true
+		return false
+	})
+	defer restore()
+	restore = install.MockSecbootCheckTPMKeySealingSupported(func(secboot.TPMProvisionMode) error {
+		c.Fatalf("unexpected tpm check")
+		return nil
+	})
+	defer restore()
+
+	model := s.mockModel(map[string]any{
+		"grade": "secured",
+	})
+	constraints := install.EncryptionConstraints{
+		Model:   model,
+		Kernel:  kernelInfo,
+		Gadget:  gadgetInfo,
+		TPMMode: secboot.TPMProvisionFull,
+	}
+
+	res, err := install.GetEncryptionSupportInfo(constraints, nil)
+	c.Assert(err, IsNil)
+	c.Check(res, DeepEquals, install.EncryptionSupportInfo{
+		StorageSafety:           asserts.StorageSafetyEncrypted,
+		Available:               true,
+		Type:                    device.EncryptionTypeLUKS,
+		PassphraseAuthAvailable: true,
+	})
+	c.Check(opteeChecked, Equals, true)
+
+	// the fde-setup hook still takes precedence
+	kernelInfo.Hooks["fde-setup"] = &snap.HookInfo{}
+	hookRan := false
+	res, err = install.GetEncryptionSupportInfo(constraints, func(req *fde.SetupRequest) ([]byte, error) {
+		hookRan = true
+		return []byte(`{"features": []}`), nil
+	})
+	c.Assert(err, IsNil)
+	c.Check(hookRan, Equals, true)
+	// passphrase authentication is not offered for hook based encryption
+	c.Check(res, DeepEquals, install.EncryptionSupportInfo{
+		StorageSafety:           asserts.StorageSafetyEncrypted,
+		Available:               true,
+		Type:                    device.EncryptionTypeLUKS,
+		PassphraseAuthAvailable: false,
+	})
+}
+
 func (s *installSuite) TestEncryptionSupportInfoForceUnencrypted(c *C) {
 	kernelInfo := s.kernelSnap(c, "pc-kernel=20")
 
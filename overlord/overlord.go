@@ -194,7 +194,7 @@ func New(restartHandler restart.Handler) (*Overlord, error) {
 	deviceMgr.AddOnInit(fdeMgr)
 	o.addManager(deviceMgr)
 
-	o.addManager(clusterstate.Manager(s))
+	o.addManager(clusterstate.Manager(s, o.runner))
 
 	o.addManager(cmdstate.Manager(s, o.runner))
 	o.addManager(snapshotstate.Manager(s, o.runner))
@@ -204,6 +204,7 @@ func New(restartHandler restart.Handler) (*Overlord, error) {
 		return nil, err
 	}
 	healthstate.Init(hookMgr)
+	o.addManager(healthstate.Manager(s))
 
 	// the shared task runner should be added last!
 	o.stateEng.AddManager(o.runner)
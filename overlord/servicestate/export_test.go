@@ -20,6 +20,8 @@
 package servicestate
 
 import (
+	"time"
+
 	tomb "gopkg.in/tomb.v2"
 
 	"github.com/snapcore/snapd/overlord/state"
@@ -63,3 +65,19 @@ func MockResourcesCheckFeatureRequirements(f func(*quota.Resources) error) (rest
 	resourcesCheckFeatureRequirements = f
 	return r
 }
+
+func (m *ServiceManager) EnsureServiceHistory() error {
+	return m.ensureServiceHistory()
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	r := testutil.Backup(&timeNow)
+	timeNow = f
+	return r
+}
+
+func MockMaxServiceHistory(n int) (restore func()) {
+	r := testutil.Backup(&maxServiceHistory)
+	maxServiceHistory = n
+	return r
+}
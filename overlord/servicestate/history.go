@@ -0,0 +1,254 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/systemd"
+)
+
+const (
+	// ServiceActive is the state of a running service.
+	ServiceActive = "active"
+	// ServiceInactive is the state of a service which is not running.
+	ServiceInactive = "inactive"
+	// ServiceFailed is the state of a service which exited with an
+	// error, was killed or failed to start.
+	ServiceFailed = "failed"
+)
+
+// ServiceStatusEvent records the transition of a snap service to a state.
+type ServiceStatusEvent struct {
+	Time  time.Time `json:"time"`
+	State string    `json:"state"`
+}
+
+// historyPollInterval is how often the state of snap services is polled to
+// track their transitions.
+var historyPollInterval = time.Minute
+
+// maxServiceHistory is the number of transitions kept per service.
+var maxServiceHistory = 20
+
+var timeNow = time.Now
+
+// serviceHistory maps snap instance names to the names of their services to
+// the transitions of those.
+type serviceHistory map[string]map[string][]*ServiceStatusEvent
+
+func getServiceHistory(st *state.State) (serviceHistory, error) {
+	var history serviceHistory
+	if err := st.Get("service-history", &history); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return serviceHistory{}, nil
+		}
+		return nil, err
+	}
+	return history, nil
+}
+
+// ServiceHistory returns the tracked transitions of the given service of the
+// given snap, oldest first. Only system services are tracked.
+func ServiceHistory(st *state.State, instanceName, app string) ([]*ServiceStatusEvent, error) {
+	history, err := getServiceHistory(st)
+	if err != nil {
+		return nil, err
+	}
+	return history[instanceName][app], nil
+}
+
+func unitStatusState(status *systemd.UnitStatus) string {
+	switch {
+	case status.Active:
+		return ServiceActive
+	case status.Failed:
+		return ServiceFailed
+	default:
+		return ServiceInactive
+	}
+}
+
+// serviceRestarted returns whether the service was restarted automatically
+// since the last poll, given the restarts counted then and now.
+func serviceRestarted(prev uint64, known bool, now uint64) bool {
+	// the count is reset when the service is started explicitly, the
+	// restarts after that are still counted
+	return known && now != prev && now > 0
+}
+
+// ensureServiceHistory polls the state of the system services of all active
+// snaps and records their transitions, adding a service-failed notice when
+// a service enters the failed state. Services which failed and were restarted
+// by systemd in between two polls are detected through their restart count.
+func (m *ServiceManager) ensureServiceHistory() error {
+	// the regular ensure interval is longer than the poll interval, ask
+	// for an ensure pass in time for the next poll
+	now := timeNow()
+	if now.Before(m.nextHistoryPoll) {
+		if !m.historyPollScheduled {
+			m.state.EnsureBefore(m.nextHistoryPoll.Sub(now))
+			m.historyPollScheduled = true
+		}
+		return nil
+	}
+	m.nextHistoryPoll = now.Add(historyPollInterval)
+	m.state.EnsureBefore(historyPollInterval)
+	m.historyPollScheduled = true
+
+	st := m.state
+	st.Lock()
+	defer st.Unlock()
+
+	var seeded bool
+	if err := st.Get("seeded", &seeded); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	logger.Trace("ensure", "manager", "ServiceManager", "func", "ensureServiceHistory")
+
+	snapStates, err := snapstate.All(st)
+	if err != nil {
+		return err
+	}
+	var apps []*snap.AppInfo
+	for name, snapst := range snapStates {
+		if !snapst.Active {
+			continue
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			logger.Debugf("cannot read current info of snap %q: %v", name, err)
+			continue
+		}
+		for _, app := range info.Services() {
+			if app.DaemonScope == snap.SystemDaemon {
+				apps = append(apps, app)
+			}
+		}
+	}
+
+	var statuses []*systemd.UnitStatus
+	var restarts []*systemd.ServiceRestartStatus
+	if len(apps) > 0 {
+		units := make([]string, len(apps))
+		for i, app := range apps {
+			units[i] = app.ServiceName()
+		}
+		st.Unlock()
+		sysd := systemd.New(systemd.SystemMode, progress.Null)
+		statuses, err = sysd.Status(units)
+		if err == nil {
+			restarts, err = sysd.ServiceRestarts(units)
+		}
+		st.Lock()
+		if err != nil {
+			// not fatal, the next poll may succeed
+			logger.Noticef("cannot get status of snap services: %v", err)
+			return nil
+		}
+	}
+
+	old, err := getServiceHistory(st)
+	if err != nil {
+		return err
+	}
+	var oldCount int
+	for _, svcs := range old {
+		oldCount += len(svcs)
+	}
+
+	// services of snaps which are gone are dropped from the history
+	history := make(serviceHistory, len(old))
+	serviceRestarts := make(map[string]uint64, len(apps))
+	changed := false
+	addEvent := func(events []*ServiceStatusEvent, newState string) []*ServiceStatusEvent {
+		changed = true
+		events = append(events, &ServiceStatusEvent{Time: now, State: newState})
+		if len(events) > maxServiceHistory {
+			events = events[len(events)-maxServiceHistory:]
+		}
+		return events
+	}
+	for i, app := range apps {
+		snapName := app.Snap.InstanceName()
+		if history[snapName] == nil {
+			history[snapName] = make(map[string][]*ServiceStatusEvent)
+		}
+		events := old[snapName][app.Name]
+		newState := unitStatusState(statuses[i])
+
+		unit := app.ServiceName()
+		prevRestarts, known := m.serviceRestarts[unit]
+		serviceRestarts[unit] = restarts[i].NRestarts
+		restarted := serviceRestarted(prevRestarts, known, restarts[i].NRestarts)
+
+		var lastState string
+		if len(events) > 0 {
+			lastState = events[len(events)-1].State
+		}
+		failed := newState == ServiceFailed && lastState != ServiceFailed
+		if restarted && newState != ServiceFailed {
+			// the service failed and was restarted since the last poll
+			events = addEvent(events, ServiceFailed)
+			lastState = ServiceFailed
+			failed = true
+		}
+		if newState != lastState {
+			events = addEvent(events, newState)
+		}
+		if failed {
+			opts := &state.AddNoticeOptions{
+				Data: map[string]string{
+					"snap":        snapName,
+					"app":         app.Name,
+					"exit-status": strconv.Itoa(restarts[i].ExecMainStatus),
+				},
+			}
+			if _, err := st.AddNotice(nil, state.ServiceFailedNotice, snapName+"."+app.Name, opts); err != nil {
+				return err
+			}
+		}
+		history[snapName][app.Name] = events
+	}
+	m.serviceRestarts = serviceRestarts
+
+	// only write the history when there is something new or some services
+	// are gone
+	if !changed && len(apps) == oldCount {
+		return nil
+	}
+	if len(history) == 0 {
+		st.Set("service-history", nil)
+		return nil
+	}
+	st.Set("service-history", history)
+	return nil
+}
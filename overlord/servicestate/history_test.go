@@ -0,0 +1,315 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type serviceHistorySuite struct {
+	baseServiceMgrTestSuite
+
+	now         time.Time
+	activeState string
+	nRestarts   int
+	exitStatus  int
+	systemctl   [][]string
+}
+
+var _ = Suite(&serviceHistorySuite{})
+
+func (s *serviceHistorySuite) SetUpTest(c *C) {
+	s.baseServiceMgrTestSuite.SetUpTest(c)
+
+	// past the delay of the first poll after the manager is created
+	s.now = time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	s.AddCleanup(servicestate.MockTimeNow(func() time.Time { return s.now }))
+
+	s.systemctl = nil
+	s.nRestarts = 0
+	s.exitStatus = 0
+	s.AddCleanup(systemd.MockSystemctl(func(args ...string) ([]byte, error) {
+		s.systemctl = append(s.systemctl, args)
+		if args[1] == "--property=Id,NRestarts,ExecMainStatus" {
+			return []byte(fmt.Sprintf(`Id=snap.test-snap.svc1.service
+NRestarts=%d
+ExecMainStatus=%d
+`, s.nRestarts, s.exitStatus)), nil
+		}
+		return []byte(fmt.Sprintf(`Type=simple
+Id=snap.test-snap.svc1.service
+Names=snap.test-snap.svc1.service
+ActiveState=%s
+UnitFileState=enabled
+NeedDaemonReload=no
+`, s.activeState)), nil
+	}))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, "test-snap", s.testSnapState)
+	snaptest.MockSnapCurrent(c, testYaml+`
+  user-svc:
+    command: bin.sh
+    daemon: simple
+    daemon-scope: user
+  cli:
+    command: bin.sh
+`, s.testSnapSideInfo)
+}
+
+func (s *serviceHistorySuite) poll(c *C, activeState string) {
+	s.activeState = activeState
+	c.Assert(s.mgr.EnsureServiceHistory(), IsNil)
+	s.now = s.now.Add(time.Minute)
+}
+
+func (s *serviceHistorySuite) history(c *C) []*servicestate.ServiceStatusEvent {
+	s.state.Lock()
+	defer s.state.Unlock()
+	events, err := servicestate.ServiceHistory(s.state, "test-snap", "svc1")
+	c.Assert(err, IsNil)
+	return events
+}
+
+func (s *serviceHistorySuite) failedNotices() []*state.Notice {
+	s.state.Lock()
+	defer s.state.Unlock()
+	return s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.ServiceFailedNotice}})
+}
+
+func (s *serviceHistorySuite) TestTracksTransitions(c *C) {
+	t0 := s.now
+	s.poll(c, "active")
+	// only system services are queried
+	c.Check(s.systemctl, DeepEquals, [][]string{
+		{"show", "--property=Id,ActiveState,UnitFileState,Type,Names,NeedDaemonReload", "snap.test-snap.svc1.service"},
+		{"show", "--property=Id,NRestarts,ExecMainStatus", "snap.test-snap.svc1.service"},
+	})
+	// no transition
+	s.poll(c, "active")
+	s.poll(c, "reloading")
+	t3 := s.now
+	s.poll(c, "inactive")
+	t4 := s.now
+	s.exitStatus = 1
+	s.poll(c, "failed")
+	s.poll(c, "failed")
+	t6 := s.now
+	s.poll(c, "active")
+
+	c.Check(s.history(c), DeepEquals, []*servicestate.ServiceStatusEvent{
+		{Time: t0, State: servicestate.ServiceActive},
+		{Time: t3, State: servicestate.ServiceInactive},
+		{Time: t4, State: servicestate.ServiceFailed},
+		{Time: t6, State: servicestate.ServiceActive},
+	})
+
+	// a single notice for entering the failed state
+	notices := s.failedNotices()
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["key"], Equals, "test-snap.svc1")
+	c.Check(n["occurrences"], Equals, 1.0)
+	c.Check(n["last-data"], DeepEquals, map[string]any{"snap": "test-snap", "app": "svc1", "exit-status": "1"})
+
+	// failing again repeats the notice
+	s.poll(c, "failed")
+	notices = s.failedNotices()
+	c.Assert(notices, HasLen, 1)
+	c.Check(noticeToMap(c, notices[0])["occurrences"], Equals, 2.0)
+}
+
+func (s *serviceHistorySuite) TestRestartsBetweenPolls(c *C) {
+	t0 := s.now
+	// restarts which happened before the first poll are not reported
+	s.nRestarts = 2
+	s.poll(c, "active")
+	s.poll(c, "active")
+	c.Check(s.failedNotices(), HasLen, 0)
+
+	// the service crashed and was restarted by systemd
+	s.nRestarts = 3
+	s.exitStatus = 11
+	t2 := s.now
+	s.poll(c, "active")
+	c.Check(s.history(c), DeepEquals, []*servicestate.ServiceStatusEvent{
+		{Time: t0, State: servicestate.ServiceActive},
+		{Time: t2, State: servicestate.ServiceFailed},
+		{Time: t2, State: servicestate.ServiceActive},
+	})
+	notices := s.failedNotices()
+	c.Assert(notices, HasLen, 1)
+	c.Check(noticeToMap(c, notices[0])["last-data"], DeepEquals, map[string]any{"snap": "test-snap", "app": "svc1", "exit-status": "11"})
+
+	// the count is reset when the service is started explicitly
+	s.nRestarts = 0
+	s.poll(c, "active")
+	c.Check(s.history(c), HasLen, 3)
+	// and it failed again after that
+	s.nRestarts = 1
+	s.poll(c, "active")
+	c.Check(s.history(c), HasLen, 5)
+	c.Check(noticeToMap(c, s.failedNotices()[0])["occurrences"], Equals, 2.0)
+}
+
+func (s *serviceHistorySuite) TestStateOnlyWrittenOnChanges(c *C) {
+	t0 := s.now
+	s.poll(c, "active")
+
+	// an unknown field in the stored history is dropped when it is
+	// written again
+	marked := map[string]any{
+		"test-snap": map[string]any{
+			"svc1": []any{
+				map[string]any{"time": t0.Format(time.RFC3339), "state": "active", "marker": true},
+			},
+		},
+	}
+	s.state.Lock()
+	s.state.Set("service-history", marked)
+	s.state.Unlock()
+
+	storedHistory := func() map[string]any {
+		s.state.Lock()
+		defer s.state.Unlock()
+		var history map[string]any
+		c.Assert(s.state.Get("service-history", &history), IsNil)
+		return history
+	}
+
+	// nothing changed, the history is left alone
+	s.poll(c, "active")
+	c.Check(storedHistory(), DeepEquals, marked)
+
+	// a transition is recorded
+	t2 := s.now
+	s.poll(c, "inactive")
+	c.Check(storedHistory(), Not(DeepEquals), marked)
+	c.Check(s.history(c), DeepEquals, []*servicestate.ServiceStatusEvent{
+		{Time: t0, State: servicestate.ServiceActive},
+		{Time: t2, State: servicestate.ServiceInactive},
+	})
+}
+
+func (s *serviceHistorySuite) TestHistoryIsBounded(c *C) {
+	restore := servicestate.MockMaxServiceHistory(3)
+	defer restore()
+
+	for _, activeState := range []string{"active", "inactive", "active", "failed", "inactive"} {
+		s.poll(c, activeState)
+	}
+	events := s.history(c)
+	c.Assert(events, HasLen, 3)
+	c.Check(events[0].State, Equals, servicestate.ServiceActive)
+	c.Check(events[1].State, Equals, servicestate.ServiceFailed)
+	c.Check(events[2].State, Equals, servicestate.ServiceInactive)
+}
+
+func (s *serviceHistorySuite) TestPollInterval(c *C) {
+	s.poll(c, "active")
+	c.Check(s.systemctl, HasLen, 2)
+
+	// not yet time to poll again
+	s.now = s.now.Add(-30 * time.Second)
+	s.poll(c, "failed")
+	c.Check(s.systemctl, HasLen, 2)
+	c.Check(s.history(c), HasLen, 1)
+
+	s.poll(c, "failed")
+	c.Check(s.systemctl, HasLen, 4)
+	c.Check(s.history(c), HasLen, 2)
+}
+
+type ensureBeforeBackend struct {
+	ensureBefore []time.Duration
+}
+
+func (b *ensureBeforeBackend) Checkpoint(data []byte) error { return nil }
+
+func (b *ensureBeforeBackend) EnsureBefore(d time.Duration) {
+	b.ensureBefore = append(b.ensureBefore, d)
+}
+
+func (s *serviceHistorySuite) TestPollIsScheduled(c *C) {
+	backend := &ensureBeforeBackend{}
+	st := state.New(backend)
+	mgr := servicestate.Manager(st, state.NewTaskRunner(st))
+
+	// the first poll is a poll interval after the manager was created
+	c.Assert(mgr.EnsureServiceHistory(), IsNil)
+	s.now = s.now.Add(30 * time.Second)
+	c.Assert(mgr.EnsureServiceHistory(), IsNil)
+	c.Check(backend.ensureBefore, DeepEquals, []time.Duration{time.Minute})
+
+	// the next poll is scheduled when polling
+	s.now = s.now.Add(30 * time.Second)
+	c.Assert(mgr.EnsureServiceHistory(), IsNil)
+	c.Check(backend.ensureBefore, DeepEquals, []time.Duration{time.Minute, time.Minute})
+	c.Assert(mgr.EnsureServiceHistory(), IsNil)
+	c.Check(backend.ensureBefore, HasLen, 2)
+}
+
+func (s *serviceHistorySuite) TestRemovedSnapsAreDropped(c *C) {
+	s.poll(c, "active")
+	c.Check(s.history(c), HasLen, 1)
+
+	s.state.Lock()
+	snapstate.Set(s.state, "test-snap", nil)
+	s.state.Unlock()
+
+	s.poll(c, "active")
+	c.Check(s.history(c), HasLen, 0)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	var history map[string]any
+	c.Check(s.state.Get("service-history", &history), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *serviceHistorySuite) TestNotSeeded(c *C) {
+	s.state.Lock()
+	s.state.Set("seeded", false)
+	s.state.Unlock()
+
+	s.poll(c, "failed")
+	c.Check(s.systemctl, HasLen, 0)
+	c.Check(s.history(c), HasLen, 0)
+	c.Check(s.failedNotices(), HasLen, 0)
+}
+
+func noticeToMap(c *C, notice *state.Notice) map[string]any {
+	buf, err := json.Marshal(notice)
+	c.Assert(err, IsNil)
+	var n map[string]any
+	c.Assert(json.Unmarshal(buf, &n), IsNil)
+	return n
+}
@@ -46,6 +46,7 @@ import (
 
 func init() {
 	swfeats.RegisterEnsure("ServiceManager", "ensureSnapServicesUpdated")
+	swfeats.RegisterEnsure("ServiceManager", "ensureServiceHistory")
 }
 
 // ServiceManager is responsible for starting and stopping snap services.
@@ -53,6 +54,13 @@ type ServiceManager struct {
 	state *state.State
 
 	ensuredSnapSvcs bool
+	nextHistoryPoll time.Time
+	// historyPollScheduled is set once an ensure pass was requested for
+	// the next poll
+	historyPollScheduled bool
+	// serviceRestarts maps the units of snap services to the number of
+	// their automatic restarts at the last poll
+	serviceRestarts map[string]uint64
 }
 
 // Manager returns a new service manager.
@@ -60,6 +68,8 @@ func Manager(st *state.State, runner *state.TaskRunner) *ServiceManager {
 	delayedCrossMgrInit()
 	m := &ServiceManager{
 		state: st,
+		// give the services a chance to start before tracking them
+		nextHistoryPoll: timeNow().Add(historyPollInterval),
 	}
 	// TODO: undo handler
 	runner.AddHandler("service-control", m.doServiceControl, nil)
@@ -211,6 +221,9 @@ func (m *ServiceManager) Ensure() error {
 	if err := m.ensureSnapServicesUpdated(); err != nil {
 		return err
 	}
+	if err := m.ensureServiceHistory(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -478,6 +478,9 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 	if snapApp.Timer != nil {
 		extra++
 	}
+	if len(snapApp.WatchPaths) > 0 {
+		extra++
+	}
 	serviceNames := make([]string, 0, 1+extra)
 	serviceNames = append(serviceNames, snapApp.ServiceName())
 
@@ -491,6 +494,10 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 		timerUnit := filepath.Base(snapApp.Timer.File())
 		serviceNames = append(serviceNames, timerUnit)
 	}
+	if len(snapApp.WatchPaths) > 0 {
+		pathUnit := filepath.Base(snapApp.WatchPathsFile())
+		serviceNames = append(serviceNames, pathUnit)
+	}
 
 	sts, err := sd.queryServiceStatus(snapApp.DaemonScope, serviceNames)
 	if err != nil {
@@ -509,6 +516,13 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 				Active:  st.Active,
 				Type:    "timer",
 			})
+		case ".path":
+			appInfo.Activators = append(appInfo.Activators, client.AppActivator{
+				Name:    snapApp.Name,
+				Enabled: st.Enabled,
+				Active:  st.Active,
+				Type:    "path",
+			})
 		case ".socket":
 			appInfo.Activators = append(appInfo.Activators, client.AppActivator{
 				Name:    sockSvcFileToName[st.Name],
@@ -536,6 +550,17 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 			Type:    "dbus",
 		})
 	}
+	// Decorate with the plugs whose devices activate this service
+	for _, plug := range snapApp.ActivatesOnDevice {
+		// Device activation is enabled together with the service,
+		// which is hooked into its device target when enabled.
+		appInfo.Activators = append(appInfo.Activators, client.AppActivator{
+			Name:    plug.Name,
+			Enabled: appInfo.Enabled,
+			Active:  appInfo.Enabled,
+			Type:    "device",
+		})
+	}
 	// For activated services, the service tends to be reported as Static, meaning
 	// it can't be disabled. However, if all the activators are disabled, then we change
 	// this to appear disabled.
@@ -583,31 +608,133 @@ func SnapServiceOptions(st *state.State, snapInfo *snap.Info, quotaGroups map[st
 		}
 	}
 
+	opts.Overrides, err = ServiceOverrides(tr, snapInfo.InstanceName())
+	if err != nil {
+		return nil, err
+	}
+
 	return opts, nil
 }
 
+// ConfGetter is the subset of a configuration transaction needed to read the
+// overrides of services.
+type ConfGetter interface {
+	GetMaybe(instanceName, key string, result any) error
+}
+
+// ServiceOverrides returns the settings of the services of the given snap
+// overridden by the system administrator through the
+// services.<snap>.<app>.* system options, as seen by the given
+// configuration transaction. The options are expected to be validated
+// already.
+func ServiceOverrides(tr ConfGetter, instanceName string) (map[string]*wrappers.ServiceOverrides, error) {
+	// instance keys cannot be part of the name of options
+	if _, instanceKey := snap.SplitInstanceName(instanceName); instanceKey != "" {
+		return nil, nil
+	}
+
+	var cfg map[string]struct {
+		RestartCondition snap.RestartCondition `json:"restart-condition"`
+		RestartDelay     string                `json:"restart-delay"`
+		Timer            string                `json:"timer"`
+		Environment      map[string]any        `json:"environment"`
+	}
+	if err := tr.GetMaybe("core", "services."+instanceName, &cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[string]*wrappers.ServiceOverrides, len(cfg))
+	for app, settings := range cfg {
+		ovr := &wrappers.ServiceOverrides{
+			RestartCondition: settings.RestartCondition,
+			Timer:            settings.Timer,
+		}
+		if settings.RestartDelay != "" {
+			delay, err := time.ParseDuration(settings.RestartDelay)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse restart delay of service %s.%s: %v", instanceName, app, err)
+			}
+			ovr.RestartDelay = delay
+		}
+		if len(settings.Environment) > 0 {
+			ovr.Environment = make(map[string]string, len(settings.Environment))
+			for name, value := range settings.Environment {
+				// numbers and booleans are taken verbatim
+				ovr.Environment[name] = fmt.Sprint(value)
+			}
+		}
+		overrides[app] = ovr
+	}
+	return overrides, nil
+}
+
 // LogReader returns an io.ReadCloser which produce logs for the provided
 // snap AppInfo's. It is a convenience wrapper around the systemd.LogReader
-// implementation.
-func LogReader(appInfos []*snap.AppInfo, n int, follow bool) (io.ReadCloser, error) {
-	serviceNames := make([]string, len(appInfos))
-	for i, appInfo := range appInfos {
+// implementation. The logs of user daemons are read for all users from the
+// system journal, which requires root. The optional filter restricts the logs
+// returned.
+func LogReader(appInfos []*snap.AppInfo, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+	var serviceNames, userServiceNames []string
+	for _, appInfo := range appInfos {
 		if !appInfo.IsService() {
 			return nil, fmt.Errorf("cannot read logs for app %q: not a service", appInfo.Name)
 		}
-		serviceNames[i] = appInfo.ServiceName()
+		if appInfo.DaemonScope == snap.UserDaemon {
+			userServiceNames = append(userServiceNames, appInfo.ServiceName())
+		} else {
+			serviceNames = append(serviceNames, appInfo.ServiceName())
+		}
+	}
+
+	var readers []io.ReadCloser
+	if len(serviceNames) > 0 || len(userServiceNames) == 0 {
+		// Include journal namespaces if supported. The --namespace option was
+		// introduced in systemd version 245. If systemd is older than that then
+		// we cannot use journal quotas in any case and don't include them.
+		includeNamespaces := false
+		if err := systemd.EnsureAtLeast(245); err == nil {
+			includeNamespaces = true
+		} else if !systemd.IsSystemdTooOld(err) {
+			return nil, fmt.Errorf("cannot get systemd version: %v", err)
+		}
+
+		sysd := systemd.New(systemd.SystemMode, progress.Null)
+		reader, err := sysd.LogReader(serviceNames, n, follow, includeNamespaces, filter)
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, reader)
+	}
+	if len(userServiceNames) > 0 {
+		// user daemons are not placed in journal namespaces
+		reader, err := systemd.UserUnitsLogReader(userServiceNames, n, follow, filter)
+		if err != nil {
+			for _, r := range readers {
+				r.Close()
+			}
+			return nil, err
+		}
+		readers = append(readers, reader)
 	}
+	return systemd.MergeLogReaders(readers, n, follow), nil
+}
 
-	// Include journal namespaces if supported. The --namespace option was
-	// introduced in systemd version 245. If systemd is older than that then
-	// we cannot use journal quotas in any case and don't include them.
-	includeNamespaces := false
-	if err := systemd.EnsureAtLeast(245); err == nil {
-		includeNamespaces = true
-	} else if !systemd.IsSystemdTooOld(err) {
-		return nil, fmt.Errorf("cannot get systemd version: %v", err)
+// UserLogReader returns an io.ReadCloser which produce logs for the provided
+// snap AppInfo's of user daemons, read from the journal of the user with the
+// given uid through the session agent of the user. The optional filter
+// restricts the logs returned.
+func UserLogReader(ctx context.Context, uid int, appInfos []*snap.AppInfo, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+	serviceNames := make([]string, len(appInfos))
+	for i, appInfo := range appInfos {
+		if !appInfo.IsService() || appInfo.DaemonScope != snap.UserDaemon {
+			return nil, fmt.Errorf("cannot read user logs for app %q: not a user service", appInfo.Name)
+		}
+		serviceNames[i] = appInfo.ServiceName()
 	}
 
-	sysd := systemd.New(systemd.SystemMode, progress.Null)
-	return sysd.LogReader(serviceNames, n, follow, includeNamespaces)
+	cli := usc.NewForUids(uid)
+	return cli.ServiceLogs(ctx, uid, serviceNames, n, follow, filter)
 }
@@ -28,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -103,7 +104,7 @@ func (s *statusDecoratorSuite) TestDecorateWithStatus(c *C) {
 				activeState = "inactive"
 				unitState = "disabled"
 			}
-			if strings.HasSuffix(unit, ".timer") || strings.HasSuffix(unit, ".socket") || strings.HasSuffix(unit, ".target") {
+			if strings.HasSuffix(unit, ".timer") || strings.HasSuffix(unit, ".socket") || strings.HasSuffix(unit, ".target") || strings.HasSuffix(unit, ".path") {
 				// Units using the baseProperties query
 				return []byte(fmt.Sprintf(`Id=%s
 Names=%[1]s
@@ -193,6 +194,54 @@ NeedDaemonReload=no
 			{Name: "svc", Type: "timer", Active: enabled, Enabled: enabled},
 		})
 
+		// service + path
+		app = &client.AppInfo{
+			Snap:   snp.InstanceName(),
+			Name:   "svc",
+			Daemon: "simple",
+		}
+		snapApp = &snap.AppInfo{
+			Snap:        snp,
+			Name:        "svc",
+			Daemon:      "simple",
+			DaemonScope: snap.SystemDaemon,
+		}
+		snapApp.WatchPaths = []*snap.WatchPathInfo{{
+			App:       snapApp,
+			Path:      "$SNAP_COMMON/incoming",
+			Condition: snap.WatchPathDirectoryNotEmpty,
+		}}
+
+		err = sd.DecorateWithStatus(app, snapApp)
+		c.Assert(err, IsNil)
+		c.Check(app.Active, Equals, enabled)
+		c.Check(app.Enabled, Equals, enabled)
+		c.Check(app.Activators, DeepEquals, []client.AppActivator{
+			{Name: "svc", Type: "path", Active: enabled, Enabled: enabled},
+		})
+
+		// service activated by devices follows the service enablement
+		app = &client.AppInfo{
+			Snap:   snp.InstanceName(),
+			Name:   "svc",
+			Daemon: "simple",
+		}
+		snapApp = &snap.AppInfo{
+			Snap:        snp,
+			Name:        "svc",
+			Daemon:      "simple",
+			DaemonScope: snap.SystemDaemon,
+		}
+		snapApp.ActivatesOnDevice = []*snap.PlugInfo{{Snap: snp, Name: "serial", Interface: "serial-port"}}
+
+		err = sd.DecorateWithStatus(app, snapApp)
+		c.Assert(err, IsNil)
+		c.Check(app.Active, Equals, enabled)
+		c.Check(app.Enabled, Equals, enabled)
+		c.Check(app.Activators, DeepEquals, []client.AppActivator{
+			{Name: "serial", Type: "device", Active: enabled, Enabled: enabled},
+		})
+
 		// service with socket
 		app = &client.AppInfo{
 			Snap:   snp.InstanceName(),
@@ -302,6 +351,59 @@ NeedDaemonReload=no
 	}
 }
 
+func (s *statusDecoratorSuite) TestUserLogReader(c *C) {
+	snp := &snap.Info{SideInfo: snap.SideInfo{RealName: "foo", Revision: snap.R(1)}}
+	appInfos := []*snap.AppInfo{
+		{
+			Snap:        snp,
+			Name:        "svc1",
+			Daemon:      "simple",
+			DaemonScope: snap.UserDaemon,
+		},
+		{
+			Snap:        snp,
+			Name:        "svc2",
+			Daemon:      "simple",
+			DaemonScope: snap.UserDaemon,
+		},
+	}
+
+	var jctlCalls int
+	restore := systemd.MockUserJournalctl(func(svcs []string, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+		jctlCalls++
+		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
+		c.Check(n, Equals, 100)
+		c.Check(follow, Equals, false)
+		c.Check(filter, DeepEquals, &systemd.LogFilter{Priority: "err", Grep: "oops"})
+		return io.NopCloser(strings.NewReader(`{"MESSAGE": "hello"}` + "\n")), nil
+	})
+	defer restore()
+
+	// the logs are read through the session agent of the user
+	reader, err := servicestate.UserLogReader(context.Background(), os.Getuid(), appInfos, 100, false, &systemd.LogFilter{Priority: "err", Grep: "oops"})
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, `{"MESSAGE": "hello"}`+"\n")
+	c.Check(jctlCalls, Equals, 1)
+}
+
+func (s *statusDecoratorSuite) TestUserLogReaderFailsWithSystemServices(c *C) {
+	snp := &snap.Info{SideInfo: snap.SideInfo{RealName: "foo", Revision: snap.R(1)}}
+	appInfos := []*snap.AppInfo{
+		{
+			Snap:        snp,
+			Name:        "svc1",
+			Daemon:      "simple",
+			DaemonScope: snap.SystemDaemon,
+		},
+	}
+
+	_, err := servicestate.UserLogReader(context.Background(), os.Getuid(), appInfos, 100, false, nil)
+	c.Assert(err, ErrorMatches, `cannot read user logs for app "svc1": not a user service`)
+}
+
 func (s *statusDecoratorSuite) TestUserServiceDecorateWithStatus(c *C) {
 	snp := &snap.Info{
 		SideInfo: snap.SideInfo{
@@ -626,6 +728,50 @@ func (s *snapServiceOptionsSuite) TestSnapServiceOptionsVitalityRank(c *C) {
 	})
 }
 
+func (s *snapServiceOptionsSuite) TestSnapServiceOptionsOverrides(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+	t := config.NewTransaction(st)
+	c.Assert(t.Set("core", "services.foo.svc1.restart-condition", "always"), IsNil)
+	c.Assert(t.Set("core", "services.foo.svc1.restart-delay", "1m30s"), IsNil)
+	c.Assert(t.Set("core", "services.foo.svc2.timer", "mon,10:00"), IsNil)
+	c.Assert(t.Set("core", "services.foo.svc2.environment", map[string]any{
+		"FOO":  "bar",
+		"PORT": json.Number("8080"),
+	}), IsNil)
+	t.Commit()
+
+	fooInfo := snaptest.MockInfo(c, "name: foo\nversion: 0", nil)
+	barInfo := snaptest.MockInfo(c, "name: bar\nversion: 0", nil)
+	fooInstanceInfo := snaptest.MockInfo(c, "name: foo\nversion: 0", nil)
+	fooInstanceInfo.InstanceKey = "instance"
+
+	opts, err := servicestate.SnapServiceOptions(st, fooInfo, nil)
+	c.Assert(err, IsNil)
+	c.Check(opts, DeepEquals, &wrappers.SnapServiceOptions{
+		Overrides: map[string]*wrappers.ServiceOverrides{
+			"svc1": {
+				RestartCondition: snap.RestartAlways,
+				RestartDelay:     90 * time.Second,
+			},
+			"svc2": {
+				Timer:       "mon,10:00",
+				Environment: map[string]string{"FOO": "bar", "PORT": "8080"},
+			},
+		},
+	})
+
+	opts, err = servicestate.SnapServiceOptions(st, barInfo, nil)
+	c.Assert(err, IsNil)
+	c.Check(opts, DeepEquals, &wrappers.SnapServiceOptions{})
+
+	// parallel instances cannot be configured
+	opts, err = servicestate.SnapServiceOptions(st, fooInstanceInfo, nil)
+	c.Assert(err, IsNil)
+	c.Check(opts, DeepEquals, &wrappers.SnapServiceOptions{})
+}
+
 func (s *snapServiceOptionsSuite) TestSnapServiceOptionsQuotaGroups(c *C) {
 	st := s.state
 	st.Lock()
@@ -934,7 +1080,7 @@ func (s *snapServiceOptionsSuite) TestLogReader(c *C) {
 			Snap:        snp,
 			Name:        "svc1",
 			Daemon:      "simple",
-			DaemonScope: snap.UserDaemon,
+			DaemonScope: snap.SystemDaemon,
 		},
 		{
 			Snap:        snp,
@@ -948,19 +1094,40 @@ func (s *snapServiceOptionsSuite) TestLogReader(c *C) {
 	defer restore()
 
 	var jctlCalls int
-	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool, filter *systemd.LogFilter) (rc io.ReadCloser, err error) {
 		jctlCalls++
-		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
+		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service"})
 		c.Check(n, Equals, 100)
 		c.Check(follow, Equals, false)
 		c.Check(namespaces, Equals, false)
-		return io.NopCloser(strings.NewReader("")), nil
+		c.Check(filter, DeepEquals, &systemd.LogFilter{Priority: "err", Grep: "oops"})
+		return io.NopCloser(strings.NewReader(`{"MESSAGE": "system", "__REALTIME_TIMESTAMP": "42"}` + "\n")), nil
+	})
+	defer restore()
+
+	// user daemons are matched as user units in the system journal
+	var userJctlCalls int
+	restore = systemd.MockUserUnitsJournalctl(func(svcs []string, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+		userJctlCalls++
+		c.Check(svcs, DeepEquals, []string{"snap.foo.svc2.service"})
+		c.Check(n, Equals, 100)
+		c.Check(follow, Equals, false)
+		c.Check(filter, DeepEquals, &systemd.LogFilter{Priority: "err", Grep: "oops"})
+		return io.NopCloser(strings.NewReader(`{"MESSAGE": "user", "__REALTIME_TIMESTAMP": "41"}` + "\n")), nil
 	})
 	defer restore()
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	reader, err := servicestate.LogReader(appInfos, 100, false, &systemd.LogFilter{Priority: "err", Grep: "oops"})
 	c.Assert(err, IsNil)
+	defer reader.Close()
 	c.Check(jctlCalls, Equals, 1)
+	c.Check(userJctlCalls, Equals, 1)
+
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, `{"MESSAGE": "user", "__REALTIME_TIMESTAMP": "41"}
+{"MESSAGE": "system", "__REALTIME_TIMESTAMP": "42"}
+`)
 }
 
 func (s *snapServiceOptionsSuite) TestLogReaderFailsWithNonServices(c *C) {
@@ -990,7 +1157,7 @@ func (s *snapServiceOptionsSuite) TestLogReaderFailsWithNonServices(c *C) {
 		},
 	}
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, nil)
 	c.Assert(err.Error(), Equals, `cannot read logs for app "app1": not a service`)
 }
 
@@ -1012,13 +1179,13 @@ func (s *snapServiceOptionsSuite) TestLogReaderNamespaces(c *C) {
 			Snap:        snp,
 			Name:        "svc1",
 			Daemon:      "simple",
-			DaemonScope: snap.UserDaemon,
+			DaemonScope: snap.SystemDaemon,
 		},
 		{
 			Snap:        snp,
 			Name:        "svc2",
 			Daemon:      "simple",
-			DaemonScope: snap.UserDaemon,
+			DaemonScope: snap.SystemDaemon,
 		},
 	}
 
@@ -1026,7 +1193,7 @@ func (s *snapServiceOptionsSuite) TestLogReaderNamespaces(c *C) {
 
 	restore := systemd.MockSystemdVersion(245, nil)
 	defer restore()
-	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool, filter *systemd.LogFilter) (rc io.ReadCloser, err error) {
 		jctlCalls++
 		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
 		c.Check(n, Equals, 100)
@@ -1036,7 +1203,7 @@ func (s *snapServiceOptionsSuite) TestLogReaderNamespaces(c *C) {
 	})
 	defer restore()
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(jctlCalls, Equals, 1)
 }
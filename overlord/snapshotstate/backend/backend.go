@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/user"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -307,7 +308,38 @@ func Save(ctx context.Context, id uint64, si *snap.Info, cfg map[string]any, use
 		return nil, err
 	}
 
-	snapshot := &client.Snapshot{
+	snapshot := newSnapshot(id, si, cfg, dynSnapshotOpts)
+	users := func() ([]*user.User, error) {
+		return usersForUsernames(usernames, dirOpts)
+	}
+	if err := save(ctx, Filename(snapshot), snapshot, si, users, dirOpts); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// SaveSystemData saves a snapshot of the system data of the given snap, but
+// not of the data of its users, into the given directory rather than the
+// snapshots directory. The snapshot can then be restored with Open and
+// Reader.Restore.
+func SaveSystemData(ctx context.Context, dir string, id uint64, si *snap.Info) (*client.Snapshot, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	snapshot := newSnapshot(id, si, nil, nil)
+	noUsers := func() ([]*user.User, error) {
+		return nil, nil
+	}
+	fn := filepath.Join(dir, filepath.Base(Filename(snapshot)))
+	if err := save(ctx, fn, snapshot, si, noUsers, nil); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func newSnapshot(id uint64, si *snap.Info, cfg map[string]any, dynSnapshotOpts *snap.SnapshotOptions) *client.Snapshot {
+	return &client.Snapshot{
 		SetID:    id,
 		Snap:     si.InstanceName(),
 		SnapID:   si.SnapID,
@@ -323,21 +355,25 @@ func Save(ctx context.Context, id uint64, si *snap.Info, cfg map[string]any, use
 		Conf:     cfg,
 		// Note: Auto is no longer set in the Snapshot.
 	}
+}
 
+// save writes the snapshot of the system data of the snap and of the data of
+// the given users to fn.
+func save(ctx context.Context, fn string, snapshot *client.Snapshot, si *snap.Info, usersToSave func() ([]*user.User, error), dirOpts *dirs.SnapDirOptions) error {
 	snapshotOptions, err := snapReadSnapshotYaml(si)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if dynSnapshotOpts != nil {
-		if err := snapshotOptions.MergeDynamicExcludes(dynSnapshotOpts.Exclude); err != nil {
-			return nil, err
+	if snapshot.Options != nil {
+		if err := snapshotOptions.MergeDynamicExcludes(snapshot.Options.Exclude); err != nil {
+			return err
 		}
 	}
 
-	aw, err := osutil.NewAtomicFile(Filename(snapshot), 0600, 0, osutil.NoChown, osutil.NoChown)
+	aw, err := osutil.NewAtomicFile(fn, 0600, 0, osutil.NoChown, osutil.NoChown)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	// if things worked, we'll commit (and Cancel becomes a NOP)
 	defer aw.Cancel()
@@ -347,51 +383,51 @@ func Save(ctx context.Context, id uint64, si *snap.Info, cfg map[string]any, use
 	savingUserData := false
 	baseDataDir := snap.BaseDataDir(si.InstanceName())
 	if err := addSnapDirToZip(ctx, snapshot, w, "root", archiveName, baseDataDir, savingUserData, snapshotOptions.Exclude); err != nil {
-		return nil, err
+		return err
 	}
 
-	users, err := usersForUsernames(usernames, dirOpts)
+	users, err := usersToSave()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	savingUserData = true
 	for _, usr := range users {
 		snapDataDir := filepath.Dir(si.UserDataDir(usr.HomeDir, dirOpts))
 		if err := addSnapDirToZip(ctx, snapshot, w, usr.Username, userArchiveName(usr), snapDataDir, savingUserData, snapshotOptions.Exclude); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	metaWriter, err := w.Create(metadataName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	hasher := crypto.SHA3_384.New()
 	enc := json.NewEncoder(io.MultiWriter(metaWriter, hasher))
 	if err := enc.Encode(snapshot); err != nil {
-		return nil, err
+		return err
 	}
 
 	hashWriter, err := w.Create(metaHashName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	fmt.Fprintf(hashWriter, "%x\n", hasher.Sum(nil))
 	if err := w.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
 	if err := ctx.Err(); err != nil {
-		return nil, err
+		return err
 	}
 
 	if err := aw.Commit(); err != nil {
-		return nil, err
+		return err
 	}
 
-	return snapshot, nil
+	return nil
 }
 
 var isTesting = snapdenv.Testing()
@@ -824,6 +824,43 @@ func (s *snapshotSuite) TestOpenSetIDoverride(c *check.C) {
 	c.Check(shr.SetID, check.Equals, uint64(99))
 }
 
+func (s *snapshotSuite) TestSaveSystemDataRoundtrip(c *check.C) {
+	if os.Geteuid() == 0 {
+		c.Skip("this test cannot run as root (runuser will fail)")
+	}
+	logger.SimpleSetup(nil)
+
+	info := &snap.Info{SideInfo: snap.SideInfo{RealName: "hello-snap", Revision: snap.R(42), SnapID: "hello-id"}, Version: "v1.33"}
+	dir := filepath.Join(c.MkDir(), "preserved")
+
+	shw, err := backend.SaveSystemData(context.TODO(), dir, 1, info)
+	c.Assert(err, check.IsNil)
+	c.Check(shw.SetID, check.Equals, uint64(1))
+	c.Check(shw.Conf, check.IsNil)
+	// only the system data is saved
+	c.Check(hashkeys(shw), check.DeepEquals, []string{"archive.tgz"})
+
+	fn := filepath.Join(dir, "1_hello-snap_v1.33_42.zip")
+	c.Check(fn, testutil.FilePresent)
+	// nothing was written to the snapshots directory
+	c.Check(backend.Filename(shw), testutil.FileAbsent)
+
+	shr, err := backend.Open(fn, backend.ExtractFnameSetID)
+	c.Assert(err, check.IsNil)
+	defer shr.Close()
+
+	newroot := c.MkDir()
+	dirs.SetRootDir(newroot)
+
+	rs, err := shr.Restore(context.TODO(), snap.R(42), nil, logger.Debugf, nil)
+	c.Assert(err, check.IsNil)
+	rs.Cleanup()
+
+	out, err := exec.Command("diff", "-urN", filepath.Join(s.root, "var", "snap"), filepath.Join(newroot, "var", "snap")).CombinedOutput()
+	c.Check(err, check.IsNil, check.Commentf("%s", out))
+	c.Check(filepath.Join(newroot, "home", "snapuser", "snap"), testutil.FileAbsent)
+}
+
 func (s *snapshotSuite) TestRestoreRoundtripDifferentRevision(c *check.C) {
 	if os.Geteuid() == 0 {
 		c.Skip("this test cannot run as root (runuser will fail)")
@@ -1013,6 +1013,10 @@ func incrementSnapRefreshFailures(st *state.State, snapsup *SnapSetup, severity
 	snapst.RefreshFailures.LastFailureSeverity = severity
 	Set(st, snapsup.InstanceName(), &snapst)
 
+	if severity == snap.RefreshFailureSeverityHealthCheck {
+		logger.Noticef("snap %q auto-refresh to revision %s has failed its health check, auto-refreshes to this revision are held", snapsup.InstanceName(), snapsup.Revision())
+		return nil
+	}
 	delay := computeSnapRefreshRemainingDelay(snapst.RefreshFailures).Round(time.Hour)
 	logger.Noticef("snap %q auto-refresh to revision %s has failed, next auto-refresh attempt will be delayed by %v hours", snapsup.InstanceName(), snapsup.Revision(), delay.Hours())
 	return nil
@@ -1026,6 +1030,20 @@ func computeSnapRefreshFailureSeverity(chg *state.Change, unlinkTask *state.Task
 	}
 
 	laneTasks := chg.LaneTasks(unlinkTask.Lanes()...)
+	// A failed health check after the refresh holds the revision for good.
+	for _, t := range laneTasks {
+		if t.Kind() != "wait-snap-health" || t.Status() != state.ErrorStatus {
+			continue
+		}
+		snapsup, err := TaskSnapSetup(t)
+		if err != nil {
+			logger.Debugf("internal error: failed to get snap associated with task %s: %v", t.ID(), err)
+			continue
+		}
+		if snapsup.InstanceName() == snapName {
+			return snap.RefreshFailureSeverityHealthCheck
+		}
+	}
 	// Look for a tasks marked as a restart boundary.
 	for _, t := range laneTasks {
 		// If a task is found in an Undone state with its restart boundary in the "do"
@@ -1158,6 +1176,11 @@ func shouldSkipSnapRefresh(snapst *SnapState, targetRevision snap.Revision, opts
 		return false
 	}
 
+	if snapst.RefreshFailures.LastFailureSeverity == snap.RefreshFailureSeverityHealthCheck {
+		logger.Noticef("snap %q auto-refresh to revision %s was skipped because the revision failed its health check", snapst.InstanceName(), targetRevision)
+		return true
+	}
+
 	// Here we are certain that the attempted target revision refresh is known to fail.
 	// Let's compute delay according to RefreshFailures.
 	delay := computeSnapRefreshRemainingDelay(snapst.RefreshFailures)
@@ -519,6 +519,45 @@ func (bs *bootedSuite) TestFinishRestartKernelClassicWithModes(c *C) {
 	c.Check(err, ErrorMatches, `cannot finish kernel installation, there was a rollback across reboot`)
 }
 
+func (bs *bootedSuite) TestFinishRestartGadgetBootSlotRollback(c *C) {
+	r := snapstatetest.MockDeviceModel(MakeModel20("pc", nil))
+	defer r()
+
+	st := bs.state
+	st.Lock()
+	defer st.Unlock()
+
+	chg := st.NewChange("refresh", "...")
+	task := st.NewTask("auto-connect", "...")
+	chg.AddTask(task)
+
+	si := &snap.SideInfo{RealName: "pc", Revision: snap.R(2)}
+	snapsup := &snapstate.SnapSetup{SideInfo: si, Type: snap.TypeGadget}
+
+	// no boot slot was tried
+	err := snapstate.FinishRestart(task, snapsup, snapstate.FinishRestartOptions{FinishRestartDefault: true})
+	c.Check(err, IsNil)
+
+	// boot slot tried, waiting for the boot to be marked successful
+	chg.Set("try-boot-slot", "b")
+	m := &boot.Modeenv{Mode: "run", TryBootSlot: "b"}
+	c.Assert(m.WriteTo(""), IsNil)
+	err = snapstate.FinishRestart(task, snapsup, snapstate.FinishRestartOptions{FinishRestartDefault: true})
+	c.Check(err, DeepEquals, &state.Retry{After: 5 * time.Second})
+
+	// the tried boot slot was committed
+	m = &boot.Modeenv{Mode: "run", CurrentBootSlot: "b"}
+	c.Assert(m.WriteTo(""), IsNil)
+	err = snapstate.FinishRestart(task, snapsup, snapstate.FinishRestartOptions{FinishRestartDefault: true})
+	c.Check(err, IsNil)
+
+	// the bootloader fell back to the previous boot slot, rollback!
+	m = &boot.Modeenv{Mode: "run"}
+	c.Assert(m.WriteTo(""), IsNil)
+	err = snapstate.FinishRestart(task, snapsup, snapstate.FinishRestartOptions{FinishRestartDefault: true})
+	c.Check(err, ErrorMatches, `cannot finish pc installation, there was a rollback of the boot slot across reboot`)
+}
+
 func (bs *bootedSuite) TestFinishRestartEphemeralModeSkipsRollbackDetection(c *C) {
 	r := snapstatetest.MockDeviceModel(DefaultModel())
 	defer r()
@@ -609,3 +609,9 @@ type CustomInstallGoal struct {
 func (c *CustomInstallGoal) toInstall(ctx context.Context, st *state.State, opts Options) ([]Target, error) {
 	return c.ToInstall(ctx, st, opts)
 }
+
+func MockGetSnapHealth(f func(st *state.State, instanceName string) (*SnapHealth, error)) (restore func()) {
+	return testutil.Mock(&GetSnapHealth, f)
+}
+
+var HealthRevertTimeout = healthRevertTimeout
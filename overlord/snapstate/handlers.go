@@ -706,22 +706,30 @@ func sendOneInstallActionUnlocked(ctx context.Context, st *state.State, snaps St
 	return sendOneInstallAction(ctx, st, snaps, opts)
 }
 
-// autoRefreshRateLimited returns the rate limit of auto-refreshes or 0 if
-// there is no limit.
-func autoRefreshRateLimited(st *state.State) (rate int64) {
+// RefreshRateLimit returns the rate limit of auto-refreshes set with
+// refresh.rate-limit, either as a fixed rate or as a schedule. A zero rate
+// and a nil schedule mean there is no limit.
+func RefreshRateLimit(st *state.State) (rate int64, sched *store.RateLimitSchedule) {
 	tr := config.NewTransaction(st)
 
 	var rateLimit string
 	err := tr.Get("core", "refresh.rate-limit", &rateLimit)
 	if err != nil {
-		return 0
+		return 0, nil
+	}
+	if strings.Contains(rateLimit, "/") {
+		sched, err := store.ParseRateLimitSchedule(rateLimit)
+		if err != nil {
+			return 0, nil
+		}
+		return 0, sched
 	}
 	// NOTE ParseByteSize errors on negative rates
 	val, err := strutil.ParseByteSize(rateLimit)
 	if err != nil {
-		return 0
+		return 0, nil
 	}
-	return val
+	return val, nil
 }
 
 func downloadSnapParams(st *state.State, t *state.Task) (*SnapSetup, StoreService, *auth.UserState, error) {
@@ -759,6 +767,7 @@ func maybeCloudName(st *state.State) (name string, err error) {
 func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	st := t.State()
 	var rate int64
+	var rateSched *store.RateLimitSchedule
 	var cloud string
 
 	st.Lock()
@@ -766,7 +775,7 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	snapsup, theStore, user, err := downloadSnapParams(st, t)
 	if snapsup != nil && snapsup.IsAutoRefresh {
 		// NOTE rate is never negative
-		rate = autoRefreshRateLimited(st)
+		rate, rateSched = RefreshRateLimit(st)
 	}
 
 	if err == nil {
@@ -788,8 +797,9 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	iconURL := snapsup.Media.IconURL()
 
 	dlOpts := &store.DownloadOptions{
-		Scheduled: snapsup.IsAutoRefresh,
-		RateLimit: rate,
+		Scheduled:         snapsup.IsAutoRefresh,
+		RateLimit:         rate,
+		RateLimitSchedule: rateSched,
 	}
 	if snapsup.DownloadInfo == nil {
 		vsets, err := EnforcedValidationSets(st)
@@ -902,10 +912,12 @@ func (m *SnapManager) doPreDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	}
 
 	targetFn := snapsup.BlobPath()
+	rate, rateSched := RefreshRateLimit(st)
 	dlOpts := &store.DownloadOptions{
 		// pre-downloads are only triggered in auto-refreshes
-		Scheduled: true,
-		RateLimit: autoRefreshRateLimited(st),
+		Scheduled:         true,
+		RateLimit:         rate,
+		RateLimitSchedule: rateSched,
 	}
 
 	perfTimings := state.TimingsForTask(t)
@@ -3891,6 +3903,9 @@ func (m *SnapManager) doDiscardSnap(t *state.Task, _ *tomb.Tomb) error {
 		if err := pruneSnapsHold(st, snapsup.InstanceName()); err != nil {
 			return err
 		}
+		if err := DiscardSnapHealth(st, snapsup.InstanceName()); err != nil {
+			return err
+		}
 
 		// Remove configuration associated with this snap.
 		err = config.DeleteSnapConfig(st, snapsup.InstanceName())
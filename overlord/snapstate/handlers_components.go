@@ -187,8 +187,9 @@ func (m *SnapManager) doDownloadComponent(t *state.Task, tomb *tomb.Tomb) error
 	}
 
 	var rate int64
+	var rateSched *store.RateLimitSchedule
 	if snapsup.IsAutoRefresh {
-		rate = autoRefreshRateLimited(st)
+		rate, rateSched = RefreshRateLimit(st)
 	}
 
 	target := compsup.BlobPath(snapsup.InstanceName())
@@ -201,8 +202,9 @@ func (m *SnapManager) doDownloadComponent(t *state.Task, tomb *tomb.Tomb) error
 	timings.Run(perf, "download", fmt.Sprintf("download component %q", compsup.ComponentName()), func(timings.Measurer) {
 		compRef := compsup.CompSideInfo.Component.String()
 		opts := &store.DownloadOptions{
-			Scheduled: snapsup.IsAutoRefresh,
-			RateLimit: rate,
+			Scheduled:         snapsup.IsAutoRefresh,
+			RateLimit:         rate,
+			RateLimitSchedule: rateSched,
 		}
 
 		err = sto.Download(tomb.Context(nil), compRef, target, compsup.DownloadInfo, meter, user, opts)
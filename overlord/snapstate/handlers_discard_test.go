@@ -136,6 +136,14 @@ func (s *discardSnapSuite) TestDoDiscardSnapInQuotaGroup(c *C) {
 }
 
 func (s *discardSnapSuite) TestDoDiscardSnapToEmpty(c *C) {
+	var discardedHealth []string
+	oldDiscardSnapHealth := snapstate.DiscardSnapHealth
+	snapstate.DiscardSnapHealth = func(st *state.State, instanceName string) error {
+		discardedHealth = append(discardedHealth, instanceName)
+		return nil
+	}
+	s.AddCleanup(func() { snapstate.DiscardSnapHealth = oldDiscardSnapHealth })
+
 	s.state.Lock()
 	snapstate.Set(s.state, "foo", &snapstate.SnapState{
 		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
@@ -163,6 +171,7 @@ func (s *discardSnapSuite) TestDoDiscardSnapToEmpty(c *C) {
 	var snapst snapstate.SnapState
 	err := snapstate.Get(s.state, "foo", &snapst)
 	c.Assert(err, testutil.ErrorIs, state.ErrNoState)
+	c.Check(discardedHealth, DeepEquals, []string{"foo"})
 }
 
 func (s *discardSnapSuite) TestDoDiscardSnapErrorsForActive(c *C) {
@@ -521,3 +521,50 @@ func (s *downloadSnapSuite) TestDoDownloadRateLimitedIntegration(c *C) {
 	})
 
 }
+
+func (s *downloadSnapSuite) TestDoDownloadRateLimitScheduleIntegration(c *C) {
+	s.state.Lock()
+
+	// set auto-refresh rate-limit
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.rate-limit", "08:00-18:00/512K,18:00-08:00/unlimited")
+	tr.Commit()
+
+	// setup fake auto-refresh download
+	si := &snap.SideInfo{
+		RealName: "foo",
+		SnapID:   "foo-id",
+		Revision: snap.R(11),
+	}
+	t := s.state.NewTask("download-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: si,
+		DownloadInfo: &snap.DownloadInfo{
+			DownloadURL: "http://some-url.com/snap",
+		},
+		Flags: snapstate.Flags{
+			IsAutoRefresh: true,
+		},
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	sched, err := store.ParseRateLimitSchedule("08:00-18:00/512K,18:00-08:00/unlimited")
+	c.Assert(err, IsNil)
+	// ensure that rate limit schedule was honored
+	c.Assert(s.fakeStore.downloads, DeepEquals, []fakeDownload{
+		{
+			name:   "foo",
+			target: filepath.Join(dirs.SnapBlobDir, "foo_11.snap"),
+			opts: &store.DownloadOptions{
+				RateLimitSchedule: sched,
+				Scheduled:         true,
+			},
+		},
+	})
+
+}
@@ -0,0 +1,171 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+type waitSnapHealthSuite struct {
+	baseHandlerSuite
+
+	health *snapstate.SnapHealth
+}
+
+var _ = Suite(&waitSnapHealthSuite{})
+
+func (s *waitSnapHealthSuite) SetUpTest(c *C) {
+	s.baseHandlerSuite.SetUpTest(c)
+
+	s.health = nil
+	s.AddCleanup(snapstate.MockGetSnapHealth(func(st *state.State, instanceName string) (*snapstate.SnapHealth, error) {
+		c.Check(instanceName, Equals, "foo")
+		return s.health, nil
+	}))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, "foo", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "foo", Revision: snap.R(3)},
+			{RealName: "foo", Revision: snap.R(33)},
+		}),
+		Current:  snap.R(33),
+		SnapType: "app",
+	})
+}
+
+func (s *waitSnapHealthSuite) newTask(c *C) *state.Task {
+	t := s.state.NewTask("wait-snap-health", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(33),
+		},
+		Flags: snapstate.Flags{IsAutoRefresh: true},
+	})
+	t.Set("health-timeout", time.Minute)
+	s.state.NewChange("auto-refresh", "...").AddTask(t)
+	return t
+}
+
+func (s *waitSnapHealthSuite) runTask(c *C, t *state.Task) {
+	s.state.Unlock()
+	defer s.state.Lock()
+	s.se.Ensure()
+	s.se.Wait()
+}
+
+func (s *waitSnapHealthSuite) TestOkay(c *C) {
+	s.health = &snapstate.SnapHealth{Revision: snap.R(33), Status: "okay"}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	t := s.newTask(c)
+	s.runTask(c, t)
+
+	c.Check(t.Status(), Equals, state.DoneStatus)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
+func (s *waitSnapHealthSuite) TestError(c *C) {
+	s.health = &snapstate.SnapHealth{Revision: snap.R(33), Status: "error", Message: "database is gone"}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	t := s.newTask(c)
+	s.runTask(c, t)
+
+	c.Check(t.Status(), Equals, state.ErrorStatus)
+	c.Check(t.Change().Err(), ErrorMatches, `(?s).*cannot keep snap "foo" at revision 33: it reported an error health: database is gone.*`)
+
+	warns := s.state.AllWarnings()
+	c.Assert(warns, HasLen, 1)
+	c.Check(warns[0].String(), Equals, `snap "foo" is being reverted from revision 33 to revision 3 because it reported an error health: database is gone; refreshes to revision 33 are held`)
+
+	notices := s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.SnapHealthRevertNotice}})
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0].Key(), Equals, "foo")
+	c.Check(notices[0].LastData(), DeepEquals, map[string]string{
+		"revision":     "33",
+		"old-revision": "3",
+		"reason":       "it reported an error health: database is gone",
+	})
+}
+
+func (s *waitSnapHealthSuite) TestWaitingRetries(c *C) {
+	// health of the previous revision does not count
+	s.health = &snapstate.SnapHealth{Revision: snap.R(3), Status: "okay"}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	t := s.newTask(c)
+	s.runTask(c, t)
+
+	c.Check(t.Status(), Equals, state.DoingStatus)
+	var since time.Time
+	c.Check(t.Get("waiting-since", &since), IsNil)
+	c.Check(since.IsZero(), Equals, false)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
+func (s *waitSnapHealthSuite) TestWaitingTimesOut(c *C) {
+	s.health = &snapstate.SnapHealth{Revision: snap.R(33), Status: "waiting"}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	t := s.newTask(c)
+	t.Set("waiting-since", time.Now().Add(-2*time.Minute))
+	s.runTask(c, t)
+
+	c.Check(t.Status(), Equals, state.ErrorStatus)
+	c.Check(t.Change().Err(), ErrorMatches, `(?s).*cannot keep snap "foo" at revision 33: it did not report okay health within 1m0s.*`)
+	c.Check(s.state.AllWarnings(), HasLen, 1)
+}
+
+func (s *waitSnapHealthSuite) TestHealthRevertTimeoutInvalid(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, tc := range []struct {
+		revert any
+		err    string
+	}{
+		{"maybe", `refresh.health-revert can only be set to 'true' or 'false', got maybe`},
+		{42, `refresh.health-revert can only be set to 'true' or 'false', got 42`},
+	} {
+		tr := config.NewTransaction(s.state)
+		tr.Set("core", "refresh.health-revert", tc.revert)
+		tr.Commit()
+
+		enabled, _, err := snapstate.HealthRevertTimeout(s.state)
+		c.Check(err, ErrorMatches, tc.err)
+		c.Check(enabled, Equals, false)
+	}
+}
@@ -0,0 +1,188 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+const (
+	// defaultHealthRevertTimeout is how long a snap has after an
+	// auto-refresh to report okay health before it is reverted, unless
+	// refresh.health-revert-timeout says otherwise.
+	defaultHealthRevertTimeout = 5 * time.Minute
+	// healthRevertPollInterval is how often the health of a snap is
+	// re-checked while waiting for it to become okay.
+	healthRevertPollInterval = 10 * time.Second
+)
+
+// SnapHealth carries the health last reported by a snap through its
+// check-health hook or snapctl set-health.
+type SnapHealth struct {
+	Revision snap.Revision
+	// Status is one of "unknown", "okay", "waiting", "blocked" or "error".
+	Status  string
+	Message string
+}
+
+// GetSnapHealth returns the health last reported by the given snap, or nil
+// if it never reported any.
+var GetSnapHealth = func(st *state.State, instanceName string) (*SnapHealth, error) {
+	panic("internal error: snapstate.GetSnapHealth is unset")
+}
+
+// DiscardSnapHealth forgets the health reported by the given snap, it is
+// called when the snap is removed. It is a hook set by healthstate, there is
+// nothing to forget without it.
+var DiscardSnapHealth = func(st *state.State, instanceName string) error {
+	return nil
+}
+
+// healthRevertTimeout returns whether snaps reporting an error health after
+// an auto-refresh should be reverted, along with how long they have to
+// report okay health.
+func healthRevertTimeout(st *state.State) (enabled bool, timeout time.Duration, err error) {
+	tr := config.NewTransaction(st)
+
+	enabled, err = healthRevertEnabled(tr)
+	if err != nil || !enabled {
+		return false, 0, err
+	}
+
+	timeout = defaultHealthRevertTimeout
+	var timeoutStr string
+	if err := tr.Get("core", "refresh.health-revert-timeout", &timeoutStr); err == nil && timeoutStr != "" {
+		d, err := time.ParseDuration(timeoutStr)
+		if err != nil || d <= 0 {
+			logger.Noticef("internal error: refresh.health-revert-timeout system option is not valid: %q", timeoutStr)
+		} else {
+			timeout = d
+		}
+	}
+	return true, timeout, nil
+}
+
+// healthRevertEnabled reads the refresh.health-revert system option, which
+// may be set either as a boolean or as a "true" or "false" string.
+func healthRevertEnabled(tr *config.Transaction) (bool, error) {
+	var revert any
+	if err := tr.GetMaybe("core", "refresh.health-revert", &revert); err != nil {
+		return false, err
+	}
+	switch revert {
+	case true, "true":
+		return true, nil
+	case false, "false", nil, "":
+		return false, nil
+	}
+	return false, fmt.Errorf("refresh.health-revert can only be set to 'true' or 'false', got %v", revert)
+}
+
+// doWaitSnapHealth waits for an auto-refreshed snap to report okay health.
+// If the snap reports an error, or does not report okay within the
+// configured timeout, the task fails so that the refresh of the snap is
+// undone and the previous revision is restored.
+func (m *SnapManager) doWaitSnapHealth(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	snapsup, err := TaskSnapSetup(t)
+	if err != nil {
+		return err
+	}
+
+	health, err := GetSnapHealth(st, snapsup.InstanceName())
+	if err != nil {
+		return err
+	}
+	if health != nil && health.Revision == snapsup.Revision() {
+		switch health.Status {
+		case "okay":
+			return nil
+		case "error":
+			reason := "it reported an error health"
+			if health.Message != "" {
+				reason = fmt.Sprintf("%s: %s", reason, health.Message)
+			}
+			return healthRevert(t, snapsup, reason)
+		}
+	}
+
+	var timeout time.Duration
+	if err := t.Get("health-timeout", &timeout); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	var since time.Time
+	if err := t.Get("waiting-since", &since); err != nil {
+		if !errors.Is(err, state.ErrNoState) {
+			return err
+		}
+		since = timeNow()
+		t.Set("waiting-since", since)
+	}
+
+	remaining := since.Add(timeout).Sub(timeNow())
+	if remaining <= 0 {
+		return healthRevert(t, snapsup, fmt.Sprintf("it did not report okay health within %v", timeout))
+	}
+	if remaining > healthRevertPollInterval {
+		remaining = healthRevertPollInterval
+	}
+	return &state.Retry{After: remaining}
+}
+
+// healthRevert records a warning and a notice about the upcoming revert of
+// the snap and returns the error that fails the task.
+func healthRevert(t *state.Task, snapsup *SnapSetup, reason string) error {
+	st := t.State()
+
+	var prevRev snap.Revision
+	var snapst SnapState
+	if err := Get(st, snapsup.InstanceName(), &snapst); err == nil {
+		if idx := snapst.LastIndex(snapsup.Revision()); idx > 0 {
+			prevRev = snapst.Sequence.Revisions[idx-1].Snap.Revision
+		}
+	}
+
+	st.Warnf("snap %q is being reverted from revision %s to revision %s because %s; refreshes to revision %s are held",
+		snapsup.InstanceName(), snapsup.Revision(), prevRev, reason, snapsup.Revision())
+	opts := &state.AddNoticeOptions{
+		Data: map[string]string{
+			"revision":     snapsup.Revision().String(),
+			"old-revision": prevRev.String(),
+			"reason":       reason,
+		},
+	}
+	if _, err := st.AddNotice(nil, state.SnapHealthRevertNotice, snapsup.InstanceName(), opts); err != nil {
+		logger.Noticef("cannot record health revert notice for snap %q: %v", snapsup.InstanceName(), err)
+	}
+
+	return fmt.Errorf("cannot keep snap %q at revision %s: %s", snapsup.InstanceName(), snapsup.Revision(), reason)
+}
@@ -861,7 +861,25 @@ func doInstall(st *state.State, snapst *SnapState, snapsup SnapSetup, compsups [
 	healthCheck := CheckHealthHook(st, snapsup.InstanceName(), snapsup.Revision())
 	healthCheck.WaitAll(installSet)
 	installSet.AddTask(healthCheck)
-	installSet.MarkEdge(healthCheck, EndEdge)
+	lastTask := healthCheck
+
+	// with the opt-in health revert policy, an auto-refreshed snap must
+	// report okay health or its refresh is undone
+	if snapsup.IsAutoRefresh {
+		enabled, timeout, err := healthRevertTimeout(st)
+		if err != nil {
+			return nil, err
+		}
+		if enabled {
+			waitHealth := st.NewTask("wait-snap-health", fmt.Sprintf(i18n.G("Wait for snap %q%s to report okay health"), snapsup.InstanceName(), revisionStr))
+			waitHealth.Set("snap-setup-task", prepare.ID())
+			waitHealth.Set("health-timeout", timeout)
+			waitHealth.WaitFor(healthCheck)
+			installSet.AddTask(waitHealth)
+			lastTask = waitHealth
+		}
+	}
+	installSet.MarkEdge(lastTask, EndEdge)
 
 	return installSet, nil
 }
@@ -1104,6 +1122,14 @@ func FinishRestart(task *state.Task, snapsup *SnapSetup, opts FinishRestartOptio
 		return err
 	}
 
+	// A gadget update may have written the structures of the inactive A/B
+	// slot, check that the bootloader did not fall back from it.
+	if deviceCtx.RunMode() && snapsup.Type == snap.TypeGadget {
+		if err := checkBootSlotRollback(task, snapsup, deviceCtx); err != nil {
+			return err
+		}
+	}
+
 	// Check if there was a rollback. A reboot can be triggered by:
 	// - core (old core16 world, system-reboot)
 	// - bootable base snap (new core18 world, system-reboot)
@@ -1163,6 +1189,31 @@ func FinishRestart(task *state.Task, snapsup *SnapSetup, opts FinishRestartOptio
 	return nil
 }
 
+func checkBootSlotRollback(task *state.Task, snapsup *SnapSetup, deviceCtx DeviceContext) error {
+	chg := task.Change()
+	if chg == nil {
+		return nil
+	}
+	var trySlot string
+	if err := chg.Get("try-boot-slot", &trySlot); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil
+		}
+		return err
+	}
+	current, err := boot.BootedSlot(deviceCtx)
+	if err == boot.ErrBootSlotNotReady {
+		return &state.Retry{After: 5 * time.Second}
+	}
+	if err != nil {
+		return err
+	}
+	if current != trySlot {
+		return fmt.Errorf("cannot finish %s installation, there was a rollback of the boot slot across reboot", snapsup.InstanceName())
+	}
+	return nil
+}
+
 // FinishTaskWithRestart will finish a task that needs a restart, by
 // setting its status and requesting a restart.
 // It should usually be invoked returning its result immediately
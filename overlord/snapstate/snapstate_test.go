@@ -10392,6 +10392,47 @@ func (s *snapmgrTestSuite) TestBackoffOnAutoRefreshAfterReboot(c *C) {
 	s.testBackoffOnAutoRefresh(c, afterReboot)
 }
 
+func (s *snapmgrTestSuite) TestBackoffOnAutoRefreshAfterHealthCheck(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	badRevison := snap.R(12)
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+		RefreshFailures: &snap.RefreshFailuresInfo{
+			Revision:            badRevison,
+			FailureCount:        1,
+			LastFailureTime:     time.Now().Add(-100 * 7 * 24 * time.Hour),
+			LastFailureSeverity: snap.RefreshFailureSeverityHealthCheck,
+		},
+	})
+	snapstate.Set(s.state, "some-other-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-other-snap", SnapID: "some-other-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+
+	s.fakeStore.refreshRevnos["some-snap-id"] = badRevison
+	names, _, err := snapstate.AutoRefresh(context.Background(), s.state)
+	c.Assert(err, IsNil)
+	// a revision that failed its health check is held no matter how long ago
+	c.Check(names, DeepEquals, []string{"some-other-snap"})
+
+	// but a new revision is picked up
+	s.fakeStore.refreshRevnos["some-snap-id"] = snap.R(13)
+	names, _, err = snapstate.AutoRefresh(context.Background(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"some-other-snap", "some-snap"})
+}
+
 func (s *snapmgrTestSuite) TestBackoffOnAutoRefreshWithNewRevision(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
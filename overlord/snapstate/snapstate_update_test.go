@@ -783,6 +783,88 @@ func (s *snapmgrTestSuite) TestUpdateTasks(c *C) {
 	c.Check(snapsup.Channel, Equals, "some-channel")
 }
 
+func (s *snapmgrTestSuite) TestUpdateTasksAutoRefreshHealthRevert(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:          true,
+		TrackingChannel: "latest/edge",
+		Sequence:        snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)}}),
+		Current:         snap.R(7),
+		SnapType:        "app",
+	})
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.health-revert", true)
+	tr.Set("core", "refresh.health-revert-timeout", "2m")
+	tr.Commit()
+
+	// only auto-refreshes wait for the snap health
+	ts, err := snapstate.Update(s.state, "some-snap", nil, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	c.Check(ts.MaybeEdge(snapstate.EndEdge).Kind(), Equals, "run-hook")
+
+	names, tss, err := snapstate.AutoRefresh(context.Background(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"some-snap"})
+	var waitHealth *state.Task
+	for _, ts := range tss.Refresh {
+		if t := ts.MaybeEdge(snapstate.EndEdge); t != nil && t.Kind() == "wait-snap-health" {
+			waitHealth = t
+		}
+	}
+	c.Assert(waitHealth, NotNil)
+	c.Check(waitHealth.WaitTasks()[0].Kind(), Equals, "run-hook")
+	var timeout time.Duration
+	c.Assert(waitHealth.Get("health-timeout", &timeout), IsNil)
+	c.Check(timeout, Equals, 2*time.Minute)
+}
+
+func (s *snapmgrTestSuite) TestUpdateTasksAutoRefreshHealthRevertConfig(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, tc := range []struct {
+		revert  any
+		wait    bool
+		skipped bool
+	}{
+		{revert: "true", wait: true},
+		{revert: false},
+		{revert: "false"},
+		// the snap is not refreshed with an invalid option
+		{revert: "maybe", skipped: true},
+	} {
+		snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+			Active:          true,
+			TrackingChannel: "latest/edge",
+			Sequence:        snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)}}),
+			Current:         snap.R(7),
+			SnapType:        "app",
+		})
+
+		tr := config.NewTransaction(s.state)
+		tr.Set("core", "refresh.health-revert", tc.revert)
+		tr.Commit()
+
+		names, tss, err := snapstate.AutoRefresh(context.Background(), s.state)
+		c.Assert(err, IsNil, Commentf("%v", tc.revert))
+		if tc.skipped {
+			c.Check(names, HasLen, 0)
+			continue
+		}
+		c.Check(names, DeepEquals, []string{"some-snap"})
+		isWait := false
+		for _, ts := range tss.Refresh {
+			if t := ts.MaybeEdge(snapstate.EndEdge); t != nil && t.Kind() == "wait-snap-health" {
+				isWait = true
+			}
+		}
+		c.Check(isWait, Equals, tc.wait, Commentf("%v", tc.revert))
+	}
+}
+
 func (s *snapmgrTestSuite) TestUpdateAmendRunThrough(c *C) {
 	const tryMode = false
 	s.testUpdateAmendRunThrough(c, tryMode, nil)
@@ -11193,6 +11275,59 @@ func (s *snapmgrTestSuite) TestAutoRefreshRecordsFailuresAfterReboot(c *C) {
 	s.testAutoRefreshRecordsFailures(c, afterReboot)
 }
 
+func (s *snapmgrTestSuite) TestAutoRefreshHealthRevert(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: string(snap.TypeApp),
+	})
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.health-revert", true)
+	tr.Commit()
+
+	snapstate.CanAutoRefresh = func(*state.State) (bool, error) { return true, nil }
+	s.fakeStore.refreshRevnos["some-snap-id"] = snap.R(12)
+
+	restore := snapstate.MockGetSnapHealth(func(st *state.State, instanceName string) (*snapstate.SnapHealth, error) {
+		c.Check(instanceName, Equals, "some-snap")
+		return &snapstate.SnapHealth{Revision: snap.R(12), Status: "error"}, nil
+	})
+	defer restore()
+
+	s.state.Unlock()
+	s.snapmgr.MockNextRefresh(time.Now())
+	err := s.snapmgr.Ensure()
+	if errors.Is(err, advisor.ErrNotSupported) {
+		c.Skip("bolt is not supported")
+	}
+	c.Assert(err, IsNil)
+	s.state.Lock()
+	s.settle(c)
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	c.Check(chgs[0].Kind(), Equals, "auto-refresh")
+	c.Check(chgs[0].Err(), ErrorMatches, `(?s).*cannot keep snap "some-snap" at revision 12: it reported an error health.*`)
+
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "some-snap", &snapst), IsNil)
+	// reverted to the previous revision
+	c.Check(snapst.Current, Equals, snap.R(1))
+	// and refreshes to the bad revision are held
+	c.Assert(snapst.RefreshFailures, NotNil)
+	c.Check(snapst.RefreshFailures.Revision, Equals, snap.R(12))
+	c.Check(snapst.RefreshFailures.LastFailureSeverity, Equals, snap.RefreshFailureSeverityHealthCheck)
+
+	c.Check(s.state.AllWarnings(), HasLen, 1)
+	c.Check(s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.SnapHealthRevertNotice}}), HasLen, 1)
+}
+
 func (s *snapmgrTestSuite) testAutoRefreshRefreshInhibitNoticeRecorded(c *C, markerInterfaceConnected bool, warningFallback bool) {
 	refreshAppsCheckCalled := 0
 	restore := snapstate.MockRefreshAppsCheck(func(si *snap.Info) error {
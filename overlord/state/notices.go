@@ -307,11 +307,20 @@ const (
 	// expired. The key for interfaces-requests-rule-update notices is the
 	// rule ID.
 	InterfacesRequestsRuleUpdateNotice NoticeType = "interfaces-requests-rule-update"
+
+	// Recorded whenever an auto-refreshed snap is reverted because it
+	// reported an error health. The key is the snap instance name.
+	SnapHealthRevertNotice NoticeType = "snap-health-revert"
+
+	// Recorded whenever a snap service enters the failed state, or failed
+	// and was restarted automatically. The key is the name of the service
+	// in the <snap>.<app> format.
+	ServiceFailedNotice NoticeType = "service-failed"
 )
 
 func (t NoticeType) Valid() bool {
 	switch t {
-	case ChangeUpdateNotice, WarningNotice, RefreshInhibitNotice, SnapRunInhibitNotice, InterfacesRequestsPromptNotice, InterfacesRequestsRuleUpdateNotice:
+	case ChangeUpdateNotice, WarningNotice, RefreshInhibitNotice, SnapRunInhibitNotice, InterfacesRequestsPromptNotice, InterfacesRequestsRuleUpdateNotice, SnapHealthRevertNotice, ServiceFailedNotice:
 		return true
 	}
 	return false
@@ -22,7 +22,9 @@
 package sandbox
 
 import (
+	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/sandbox/apparmor"
+	"github.com/snapcore/snapd/sandbox/selinux"
 )
 
 // For testing only
@@ -36,7 +38,15 @@ func ForceDevMode() bool {
 	}
 
 	apparmorFull := apparmor.ProbedLevel() == apparmor.Full
-	return !apparmorFull
+	if apparmorFull {
+		return false
+	}
+	// Without AppArmor, snaps are confined with per-app SELinux policy
+	// modules when the experimental feature is enabled, provided those can
+	// be installed and are enforced. The checks are ordered by cost.
+	selinuxStrict := features.SELinuxConfinement.IsEnabled() &&
+		selinux.PolicyModulesSupported() && selinux.ProbedLevel() == selinux.Enforcing
+	return !selinuxStrict
 }
 
 // MockForceDevMode fake the system to believe its in a distro
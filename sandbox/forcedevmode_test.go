@@ -20,13 +20,17 @@
 package sandbox_test
 
 import (
+	"os"
 	"testing"
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/sandbox"
 	"github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/sandbox/selinux"
 )
 
 func Test(t *testing.T) { TestingT(t) }
@@ -36,8 +40,19 @@ type forceDevModeSuite struct {
 
 var _ = Suite(&forceDevModeSuite{})
 
+func (s *forceDevModeSuite) SetUpTest(c *C) {
+	dirs.SetRootDir(c.MkDir())
+}
+
+func (s *forceDevModeSuite) TearDownTest(c *C) {
+	dirs.SetRootDir("")
+}
+
 func (s *forceDevModeSuite) TestForceDevMode(c *C) {
 
+	restore := selinux.MockPolicyModulesSupported(false)
+	defer restore()
+
 	runTest := func(apparmorLevel apparmor.LevelType, cgroupVersion int, expect bool) {
 		restore := apparmor.MockLevel(apparmorLevel)
 		defer restore()
@@ -64,6 +79,40 @@ func (s *forceDevModeSuite) TestForceDevMode(c *C) {
 	}
 }
 
+func (s *forceDevModeSuite) TestForceDevModeSELinux(c *C) {
+	restore := apparmor.MockLevel(apparmor.Unsupported)
+	defer restore()
+
+	for _, tc := range []struct {
+		feature            bool
+		enabled, enforcing bool
+		modules            bool
+		exp                bool
+	}{
+		{true, false, false, true, true},
+		{true, true, false, true, true},
+		{true, true, true, false, true},
+		{true, true, true, true, false},
+		// strict confinement with SELinux is experimental
+		{false, true, true, true, true},
+	} {
+		if tc.feature {
+			c.Assert(os.MkdirAll(dirs.FeaturesDir, 0755), IsNil)
+			c.Assert(os.WriteFile(features.SELinuxConfinement.ControlFile(), nil, 0644), IsNil)
+		} else {
+			c.Assert(os.RemoveAll(features.SELinuxConfinement.ControlFile()), IsNil)
+		}
+		restore := selinux.MockIsEnabled(func() (bool, error) { return tc.enabled, nil })
+		defer restore()
+		restore = selinux.MockIsEnforcing(func() (bool, error) { return tc.enforcing, nil })
+		defer restore()
+		restore = selinux.MockPolicyModulesSupported(tc.modules)
+		defer restore()
+
+		c.Check(sandbox.ForceDevMode(), Equals, tc.exp, Commentf("unexpected force-dev-mode for %+v", tc))
+	}
+}
+
 func (s *forceDevModeSuite) TestMockForceDevMode(c *C) {
 	for _, devmode := range []bool{true, false} {
 		restore := sandbox.MockForceDevMode(devmode)
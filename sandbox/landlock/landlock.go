@@ -0,0 +1,112 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package landlock offers detection of the Landlock LSM.
+//
+// Landlock allows an unprivileged process to restrict its own access to the
+// filesystem and network. The set of access rights that can be restricted
+// grows with each revision of the kernel ABI, see
+// https://docs.kernel.org/userspace-api/landlock.html
+package landlock
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	probeOnce sync.Once
+	probedABI int
+	probeErr  error
+
+	probeABIVersion = landlockABIVersion
+)
+
+// ABIVersion returns the Landlock ABI version supported by the running
+// kernel. An error is returned when Landlock is not supported or disabled.
+func ABIVersion() (int, error) {
+	probeOnce.Do(func() {
+		probedABI, probeErr = probeABIVersion()
+		if probeErr == nil && probedABI < 1 {
+			probeErr = fmt.Errorf("unexpected landlock ABI version %d", probedABI)
+		}
+	})
+	return probedABI, probeErr
+}
+
+// IsSupported returns true when Landlock is enabled in the running kernel.
+func IsSupported() bool {
+	_, err := ABIVersion()
+	return err == nil
+}
+
+// Summary returns a human readable description of Landlock support.
+func Summary() string {
+	abi, err := ABIVersion()
+	if err != nil {
+		return fmt.Sprintf("landlock not available: %v", err)
+	}
+	return fmt.Sprintf("landlock ABI version %d is supported", abi)
+}
+
+// featuresByABI lists features introduced by each revision of the Landlock
+// ABI, the first entry describes ABI version 1.
+var featuresByABI = [][]string{
+	// restricting basic filesystem access
+	{"fs"},
+	// LANDLOCK_ACCESS_FS_REFER
+	{"fs-refer"},
+	// LANDLOCK_ACCESS_FS_TRUNCATE
+	{"fs-truncate"},
+	// LANDLOCK_ACCESS_NET_BIND_TCP, LANDLOCK_ACCESS_NET_CONNECT_TCP
+	{"net-tcp"},
+	// LANDLOCK_ACCESS_FS_IOCTL_DEV
+	{"fs-ioctl-dev"},
+	// LANDLOCK_SCOPE_ABSTRACT_UNIX_SOCKET, LANDLOCK_SCOPE_SIGNAL
+	{"scope"},
+}
+
+// Features returns the list of Landlock features supported by the running
+// kernel, prefixed with the ABI version, e.g. "abi:4". The list is empty when
+// Landlock is not supported.
+func Features() []string {
+	abi, err := ABIVersion()
+	if err != nil {
+		return nil
+	}
+	features := []string{fmt.Sprintf("abi:%d", abi)}
+	for i := 0; i < abi && i < len(featuresByABI); i++ {
+		features = append(features, featuresByABI[i]...)
+	}
+	return features
+}
+
+// MockABIVersion makes the system appear to support the given Landlock ABI
+// version, or to fail probing with the given error.
+func MockABIVersion(abi int, err error) (restore func()) {
+	old := probeABIVersion
+	probeABIVersion = func() (int, error) {
+		return abi, err
+	}
+	probeOnce = sync.Once{}
+	return func() {
+		probeABIVersion = old
+		probeOnce = sync.Once{}
+	}
+}
@@ -0,0 +1,38 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func landlockABIVersion() (int, error) {
+	// see https://docs.kernel.org/userspace-api/landlock.html#landlock-abi-versions,
+	// the call returns EOPNOTSUPP when Landlock is built in but disabled at
+	// boot and ENOSYS when it is not built in at all
+	r1, _, errno := syscall.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET,
+		0, 0, uintptr(unix.LANDLOCK_CREATE_RULESET_VERSION))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(r1), nil
+}
@@ -0,0 +1,86 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock_test
+
+import (
+	"errors"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/sandbox/landlock"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type landlockSuite struct{}
+
+var _ = Suite(&landlockSuite{})
+
+func (s *landlockSuite) TestUnsupported(c *C) {
+	restore := landlock.MockABIVersion(0, errors.New("boom"))
+	defer restore()
+
+	_, err := landlock.ABIVersion()
+	c.Check(err, ErrorMatches, "boom")
+	c.Check(landlock.IsSupported(), Equals, false)
+	c.Check(landlock.Features(), HasLen, 0)
+	c.Check(landlock.Summary(), Equals, "landlock not available: boom")
+}
+
+func (s *landlockSuite) TestBogusVersion(c *C) {
+	restore := landlock.MockABIVersion(0, nil)
+	defer restore()
+
+	_, err := landlock.ABIVersion()
+	c.Check(err, ErrorMatches, "unexpected landlock ABI version 0")
+	c.Check(landlock.IsSupported(), Equals, false)
+}
+
+func (s *landlockSuite) TestFeatures(c *C) {
+	for _, t := range []struct {
+		abi      int
+		features []string
+	}{
+		{1, []string{"abi:1", "fs"}},
+		{3, []string{"abi:3", "fs", "fs-refer", "fs-truncate"}},
+		{4, []string{"abi:4", "fs", "fs-refer", "fs-truncate", "net-tcp"}},
+		{6, []string{"abi:6", "fs", "fs-refer", "fs-truncate", "net-tcp", "fs-ioctl-dev", "scope"}},
+		// future ABI versions
+		{7, []string{"abi:7", "fs", "fs-refer", "fs-truncate", "net-tcp", "fs-ioctl-dev", "scope"}},
+	} {
+		restore := landlock.MockABIVersion(t.abi, nil)
+		abi, err := landlock.ABIVersion()
+		c.Check(err, IsNil)
+		c.Check(abi, Equals, t.abi)
+		c.Check(landlock.IsSupported(), Equals, true)
+		c.Check(landlock.Features(), DeepEquals, t.features, Commentf("abi %d", t.abi))
+		restore()
+	}
+}
+
+func (s *landlockSuite) TestSummary(c *C) {
+	restore := landlock.MockABIVersion(4, nil)
+	defer restore()
+
+	c.Check(landlock.Summary(), Equals, "landlock ABI version 4 is supported")
+}
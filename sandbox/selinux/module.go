@@ -0,0 +1,86 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/snapcore/snapd/osutil"
+)
+
+var (
+	semoduleCmd = "semodule"
+
+	policyModulesSupported = func() bool {
+		return osutil.ExecutableExists(semoduleCmd)
+	}
+)
+
+// PolicyModulesSupported tells whether policy modules can be installed on the
+// system, that is whether semodule is available.
+func PolicyModulesSupported() bool {
+	return policyModulesSupported()
+}
+
+// InstallModules installs or replaces the policy modules stored in the given
+// files. Modules are named after the file, without the extension. All the
+// modules are installed in a single policy transaction.
+func InstallModules(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	args := make([]string, 0, 2*len(paths))
+	for _, path := range paths {
+		args = append(args, "-i", path)
+	}
+	return runSemodule(args)
+}
+
+// RemoveModules removes the policy modules with the given names in a single
+// policy transaction.
+func RemoveModules(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	args := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		args = append(args, "-r", name)
+	}
+	return runSemodule(args)
+}
+
+func runSemodule(args []string) error {
+	output, err := exec.Command(semoduleCmd, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("semodule failed: %v", osutil.OutputErr(output, err))
+	}
+	return nil
+}
+
+// MockPolicyModulesSupported makes the system believe policy modules can, or
+// cannot, be installed.
+func MockPolicyModulesSupported(supported bool) (restore func()) {
+	old := policyModulesSupported
+	policyModulesSupported = func() bool { return supported }
+	return func() {
+		policyModulesSupported = old
+	}
+}
@@ -0,0 +1,82 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package selinux_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/sandbox/selinux"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type moduleSuite struct{}
+
+var _ = Suite(&moduleSuite{})
+
+func (s *moduleSuite) TestPolicyModulesSupported(c *C) {
+	c.Check(selinux.PolicyModulesSupported(), Equals, false)
+
+	cmd := testutil.MockCommand(c, "semodule", "")
+	defer cmd.Restore()
+	c.Check(selinux.PolicyModulesSupported(), Equals, true)
+
+	restore := selinux.MockPolicyModulesSupported(false)
+	defer restore()
+	c.Check(selinux.PolicyModulesSupported(), Equals, false)
+}
+
+func (s *moduleSuite) TestInstallModules(c *C) {
+	cmd := testutil.MockCommand(c, "semodule", "")
+	defer cmd.Restore()
+
+	err := selinux.InstallModules([]string{"/path/to/foo.cil", "/path/to/bar.cil"})
+	c.Assert(err, IsNil)
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		{"semodule", "-i", "/path/to/foo.cil", "-i", "/path/to/bar.cil"},
+	})
+
+	// nothing to do
+	cmd.ForgetCalls()
+	c.Assert(selinux.InstallModules(nil), IsNil)
+	c.Check(cmd.Calls(), HasLen, 0)
+}
+
+func (s *moduleSuite) TestRemoveModules(c *C) {
+	cmd := testutil.MockCommand(c, "semodule", "")
+	defer cmd.Restore()
+
+	err := selinux.RemoveModules([]string{"foo", "bar"})
+	c.Assert(err, IsNil)
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		{"semodule", "-r", "foo", "-r", "bar"},
+	})
+
+	cmd.ForgetCalls()
+	c.Assert(selinux.RemoveModules(nil), IsNil)
+	c.Check(cmd.Calls(), HasLen, 0)
+}
+
+func (s *moduleSuite) TestSemoduleFails(c *C) {
+	cmd := testutil.MockCommand(c, "semodule", "echo 'libsemanage.semanage_direct_remove_key: Unable to remove module foo'; exit 1")
+	defer cmd.Restore()
+
+	err := selinux.RemoveModules([]string{"foo"})
+	c.Assert(err, ErrorMatches, "semodule failed: libsemanage.semanage_direct_remove_key: Unable to remove module foo")
+}
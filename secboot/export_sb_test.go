@@ -35,6 +35,7 @@ import (
 	sb_hooks "github.com/snapcore/secboot/hooks"
 	sb_tpm2 "github.com/snapcore/secboot/tpm2"
 
+	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -618,3 +619,28 @@ func MockSbWithExternalUnlockKey(f func(name string, key sb.DiskUnlockKey, src s
 func MockSbWithAuthRequestorUserVisibleName(f func(name string) sb.ActivateOption) (restore func()) {
 	return testutil.Mock(&sbWithAuthRequestorUserVisibleName, f)
 }
+
+func MockKeyfileMount(f func(devicePath, mountPoint string, writable bool) error) (restore func()) {
+	return testutil.Mock(&keyfileMount, f)
+}
+
+func MockKeyfileUnmount(f func(mountPoint string) error) (restore func()) {
+	return testutil.Mock(&keyfileUnmount, f)
+}
+
+func MockKeyfileAuthRequestor(f func() sb.AuthRequestor) (restore func()) {
+	return testutil.Mock(&keyfileAuthRequestor, f)
+}
+
+func MockKeyfileDeviceTimeout(timeout time.Duration) (restore func()) {
+	restoreTimeout := testutil.Mock(&keyfileDeviceTimeout, timeout)
+	restoreInterval := testutil.Mock(&keyfileDevicePollInterval, time.Millisecond)
+	return func() {
+		restoreInterval()
+		restoreTimeout()
+	}
+}
+
+func KeyfileFactoryWithVolumesAuth(kpf KeyProtectorFactory, volumesAuth *device.VolumesAuthOptions) (KeyProtectorFactory, error) {
+	return kpf.(*keyfileKeyProtectorFactory).withVolumesAuth(volumesAuth)
+}
@@ -0,0 +1,499 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nosecboot
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package secboot
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	sb "github.com/snapcore/secboot"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/disks"
+)
+
+var (
+	keyfileMount         = mountKeyfileFilesystem
+	keyfileUnmount       = unmountKeyfileFilesystem
+	keyfileAuthRequestor = NewSystemdAuthRequestor
+
+	// the keyfile may be on a removable device that shows up late
+	keyfileDeviceTimeout      = 30 * time.Second
+	keyfileDevicePollInterval = 500 * time.Millisecond
+)
+
+const (
+	keyfileSize      = 32
+	keyfileAuthTries = 3
+
+	// argon2id parameters used to protect keyfiles with a passphrase
+	keyfileKDFTime    = 4
+	keyfileKDFMemory  = 64 * 1024
+	keyfileKDFThreads = 4
+)
+
+var errKeyfileWrongPassphrase = errors.New("cannot unlock keyfile: wrong passphrase")
+
+// keyfileEnvelope is the format of a keyfile protected by a passphrase. A
+// keyfile that cannot be decoded as an envelope is used as is. The public key
+// of the keyfile is kept in the clear so that new keys can be protected
+// without the passphrase, e.g. when resetting the device.
+type keyfileEnvelope struct {
+	KDF        string `json:"kdf"`
+	Time       uint32 `json:"time"`
+	Memory     uint32 `json:"memory"`
+	Threads    uint8  `json:"threads"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+	PublicKey  []byte `json:"public-key"`
+}
+
+// keyfileHandle is the handle of keys protected with a keyfile, it carries
+// everything needed to find the keyfile again when revealing the key.
+type keyfileHandle struct {
+	Location device.KeyfileLocation `json:"location"`
+	Nonce    []byte                 `json:"nonce"`
+	// EphemeralPublicKey is combined with the private key of the keyfile
+	// to derive the key protecting the disk unlock key
+	EphemeralPublicKey []byte `json:"ephemeral-public-key"`
+}
+
+func mountKeyfileFilesystem(devicePath, mountPoint string, writable bool) error {
+	mode := "ro"
+	if writable {
+		mode = "rw"
+	}
+	// not using syscall.Mount() because we don't know the fs type in advance
+	cmd := exec.Command("mount", "-t", "ext4,vfat", "-o", mode+",nodev,nosuid,noexec", "--make-private", devicePath, mountPoint)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cannot mount %s: %v", devicePath, osutil.OutputErr(output, err))
+	}
+	return nil
+}
+
+func unmountKeyfileFilesystem(mountPoint string) error {
+	return syscall.Unmount(mountPoint, 0)
+}
+
+func findKeyfileDevice(label string) (string, error) {
+	deadline := time.Now().Add(keyfileDeviceTimeout)
+	for {
+		devicePath, err := disks.CandidateByLabelPath(label)
+		if err == nil {
+			return devicePath, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("cannot find keyfile filesystem with label %q: %v", label, err)
+		}
+		time.Sleep(keyfileDevicePollInterval)
+	}
+}
+
+// withKeyfileFilesystem mounts the filesystem carrying the keyfile and calls
+// f with the path of the keyfile.
+func withKeyfileFilesystem(loc *device.KeyfileLocation, writable bool, f func(keyfilePath string) error) error {
+	devicePath, err := findKeyfileDevice(loc.FilesystemLabel)
+	if err != nil {
+		return err
+	}
+	// /run is available both in the initramfs and in the run system
+	if err := os.MkdirAll(dirs.SnapRunDir, 0755); err != nil {
+		return err
+	}
+	mountPoint, err := os.MkdirTemp(dirs.SnapRunDir, "fde-keyfile-")
+	if err != nil {
+		return fmt.Errorf("cannot create keyfile mount point: %v", err)
+	}
+	defer os.Remove(mountPoint)
+
+	if err := keyfileMount(devicePath, mountPoint, writable); err != nil {
+		return err
+	}
+	defer func() {
+		if err := keyfileUnmount(mountPoint); err != nil {
+			logger.Noticef("WARNING: cannot unmount keyfile filesystem: %v", err)
+		}
+	}()
+
+	return f(filepath.Join(mountPoint, loc.KeyfilePath()))
+}
+
+func keyfileKDF(passphrase string, env *keyfileEnvelope) []byte {
+	return argon2.IDKey([]byte(passphrase), env.Salt, env.Time, env.Memory, env.Threads, 32)
+}
+
+func wrapKeyfile(keyfile []byte, passphrase string) ([]byte, error) {
+	_, publicKey, err := keyfileKeyPair(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	env := &keyfileEnvelope{
+		KDF:     "argon2id",
+		Time:    keyfileKDFTime,
+		Memory:  keyfileKDFMemory,
+		Threads: keyfileKDFThreads,
+		Salt:    make([]byte, 16),
+
+		PublicKey: publicKey,
+	}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := newKeyfileAEAD(keyfileKDF(passphrase, env))
+	if err != nil {
+		return nil, err
+	}
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, err
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, keyfile, nil)
+	return json.Marshal(env)
+}
+
+// decodeKeyfileEnvelope returns the envelope of a passphrase protected
+// keyfile or nil if the keyfile is not protected.
+func decodeKeyfileEnvelope(content []byte) *keyfileEnvelope {
+	var env keyfileEnvelope
+	if json.Unmarshal(content, &env) != nil || env.KDF == "" {
+		return nil
+	}
+	return &env
+}
+
+func unwrapKeyfile(env *keyfileEnvelope, passphrase string) ([]byte, error) {
+	if env.KDF != "argon2id" {
+		return nil, fmt.Errorf("cannot unlock keyfile: unsupported kdf %q", env.KDF)
+	}
+	aead, err := newKeyfileAEAD(keyfileKDF(passphrase, env))
+	if err != nil {
+		return nil, err
+	}
+	keyfile, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, errKeyfileWrongPassphrase
+	}
+	return keyfile, nil
+}
+
+func newKeyfileAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyfileKeyPair derives the X25519 key pair of the keyfile from its content.
+func keyfileKeyPair(keyfile []byte) (privateKey, publicKey []byte, err error) {
+	privateKey = make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, keyfile, nil, []byte("snapd fde keyfile x25519")), privateKey); err != nil {
+		return nil, nil, err
+	}
+	publicKey, err = curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, publicKey, nil
+}
+
+// keyfileProtectorKey derives the key protecting a disk unlock key from the
+// secret shared between the keyfile and the ephemeral key of the handle.
+func keyfileProtectorKey(shared, ephemeralPublicKey, publicKey []byte) ([]byte, error) {
+	salt := append(append([]byte(nil), ephemeralPublicKey...), publicKey...)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte("snapd fde keyfile")), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// loadOrCreateKeyfilePublicKey returns the public key of the keyfile at the
+// given location, creating the keyfile first if it does not exist. A new
+// keyfile is protected by the passphrase, if one is given. The public key of
+// an existing keyfile protected by a passphrase is available without the
+// passphrase, but a given passphrase is still checked.
+func loadOrCreateKeyfilePublicKey(loc *device.KeyfileLocation, passphrase string) (publicKey []byte, err error) {
+	err = withKeyfileFilesystem(loc, true, func(keyfilePath string) error {
+		content, err := os.ReadFile(keyfilePath)
+		if err == nil {
+			keyfile := content
+			env := decodeKeyfileEnvelope(content)
+			switch {
+			case env == nil && passphrase != "":
+				return fmt.Errorf("cannot use existing keyfile %q with a passphrase: keyfile is not protected by a passphrase", loc.KeyfilePath())
+			case env != nil && passphrase == "":
+				if len(env.PublicKey) != curve25519.PointSize {
+					return fmt.Errorf("cannot use existing keyfile %q without its passphrase: invalid public key", loc.KeyfilePath())
+				}
+				publicKey = env.PublicKey
+				return nil
+			case env != nil:
+				if keyfile, err = unwrapKeyfile(env, passphrase); err != nil {
+					return err
+				}
+			}
+			_, publicKey, err = keyfileKeyPair(keyfile)
+			return err
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		logger.Noticef("creating keyfile %q on filesystem %q", loc.KeyfilePath(), loc.FilesystemLabel)
+		keyfile := make([]byte, keyfileSize)
+		if _, err := rand.Read(keyfile); err != nil {
+			return err
+		}
+		if _, publicKey, err = keyfileKeyPair(keyfile); err != nil {
+			return err
+		}
+		content = keyfile
+		if passphrase != "" {
+			if content, err = wrapKeyfile(keyfile, passphrase); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(keyfilePath), 0700); err != nil {
+			return err
+		}
+		return osutil.AtomicWriteFile(keyfilePath, content, 0600, 0)
+	})
+	return publicKey, err
+}
+
+// loadKeyfile returns the content of the keyfile at the given location,
+// asking for the passphrase if the keyfile is protected by one.
+func loadKeyfile(loc *device.KeyfileLocation) (keyfile []byte, err error) {
+	err = withKeyfileFilesystem(loc, false, func(keyfilePath string) error {
+		content, err := os.ReadFile(keyfilePath)
+		if err != nil {
+			return fmt.Errorf("cannot read keyfile: %v", err)
+		}
+		env := decodeKeyfileEnvelope(content)
+		if env == nil {
+			keyfile = content
+			return nil
+		}
+		requestor := keyfileAuthRequestor()
+		for i := 0; i < keyfileAuthTries; i++ {
+			passphrase, err := requestor.RequestUserCredential(context.Background(), "FDE keyfile", loc.FilesystemLabel, sb.UserAuthTypePassphrase)
+			if err != nil {
+				return err
+			}
+			keyfile, err = unwrapKeyfile(env, passphrase)
+			if err != errKeyfileWrongPassphrase {
+				return err
+			}
+			logger.Noticef("cannot unlock keyfile: wrong passphrase")
+		}
+		return errKeyfileWrongPassphrase
+	})
+	return keyfile, err
+}
+
+type keyfileKeyProtectorFactory struct {
+	location   device.KeyfileLocation
+	passphrase string
+
+	// publicKey of the keyfile, set once the keyfile is loaded
+	publicKey []byte
+}
+
+// KeyfileKeyProtectorFactory returns a [KeyProtectorFactory] that will protect
+// keys with the keyfile at the given location, for devices without a TPM. The
+// keyfile is created when it does not exist yet.
+func KeyfileKeyProtectorFactory(loc device.KeyfileLocation) KeyProtectorFactory {
+	return &keyfileKeyProtectorFactory{location: loc}
+}
+
+// ProtectorKeyfileLocation returns the location of the keyfile used by a
+// factory returned by [KeyfileKeyProtectorFactory], or nil for other
+// factories.
+func ProtectorKeyfileLocation(kpf KeyProtectorFactory) *device.KeyfileLocation {
+	f, ok := kpf.(*keyfileKeyProtectorFactory)
+	if !ok {
+		return nil
+	}
+	loc := f.location
+	return &loc
+}
+
+func (f *keyfileKeyProtectorFactory) ForKeyName(name string) KeyProtector {
+	return &keyfileKeyProtector{factory: f}
+}
+
+func (f *keyfileKeyProtectorFactory) keyfilePublicKey() ([]byte, error) {
+	if f.publicKey != nil {
+		return f.publicKey, nil
+	}
+	publicKey, err := loadOrCreateKeyfilePublicKey(&f.location, f.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	f.publicKey = publicKey
+	return f.publicKey, nil
+}
+
+// withVolumesAuth returns a factory protecting the keyfile it creates with
+// the passphrase of the volumes authentication options.
+func (f *keyfileKeyProtectorFactory) withVolumesAuth(volumesAuth *device.VolumesAuthOptions) (KeyProtectorFactory, error) {
+	if volumesAuth.Mode != device.AuthModePassphrase {
+		return nil, fmt.Errorf("%q authentication mode is not supported for keys protected by a keyfile", volumesAuth.Mode)
+	}
+	return &keyfileKeyProtectorFactory{
+		location:   f.location,
+		passphrase: volumesAuth.Passphrase,
+	}, nil
+}
+
+type keyfileKeyProtector struct {
+	factory *keyfileKeyProtectorFactory
+}
+
+func (k *keyfileKeyProtector) ProtectKey(rand io.Reader, cleartext, aad []byte) (ciphertext []byte, handle []byte, err error) {
+	publicKey, err := k.factory.keyfilePublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	ephemeralKey := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand, ephemeralKey); err != nil {
+		return nil, nil, err
+	}
+	ephemeralPublicKey, err := curve25519.X25519(ephemeralKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	shared, err := curve25519.X25519(ephemeralKey, publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := keyfileProtectorKey(shared, ephemeralPublicKey, publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newKeyfileAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return nil, nil, err
+	}
+
+	rawHandle, err := json.Marshal(&keyfileHandle{
+		Location: k.factory.location,
+		Nonce:    nonce,
+
+		EphemeralPublicKey: ephemeralPublicKey,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	handleJSON, err := json.Marshal(taggedHandle{
+		Method: "keyfile",
+		Handle: rawHandle,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return aead.Seal(nil, nonce, cleartext, aad), handleJSON, nil
+}
+
+func revealWithKeyfile(handleJSON, ciphertext, aad []byte) ([]byte, error) {
+	var handle keyfileHandle
+	if err := json.Unmarshal(handleJSON, &handle); err != nil {
+		return nil, fmt.Errorf("cannot decode keyfile handle: %v", err)
+	}
+	keyfile, err := loadKeyfile(&handle.Location)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, publicKey, err := keyfileKeyPair(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := curve25519.X25519(privateKey, handle.EphemeralPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reveal key with keyfile: %v", err)
+	}
+	key, err := keyfileProtectorKey(shared, handle.EphemeralPublicKey, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newKeyfileAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, handle.Nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reveal key with keyfile: %v", err)
+	}
+	return plaintext, nil
+}
+
+// ChangeKeyfilePassphrase changes the passphrase protecting the keyfile at the
+// given location. An empty old passphrase is used for keyfiles that are not
+// protected by a passphrase yet, an empty new one removes the protection.
+func ChangeKeyfilePassphrase(loc device.KeyfileLocation, oldPassphrase, newPassphrase string) error {
+	return withKeyfileFilesystem(&loc, true, func(keyfilePath string) error {
+		content, err := os.ReadFile(keyfilePath)
+		if err != nil {
+			return fmt.Errorf("cannot read keyfile: %v", err)
+		}
+		keyfile := content
+		if env := decodeKeyfileEnvelope(content); env != nil {
+			if keyfile, err = unwrapKeyfile(env, oldPassphrase); err != nil {
+				return err
+			}
+		} else if oldPassphrase != "" {
+			return errors.New("cannot unlock keyfile: keyfile is not protected by a passphrase")
+		}
+
+		content = keyfile
+		if newPassphrase != "" {
+			if content, err = wrapKeyfile(keyfile, newPassphrase); err != nil {
+				return err
+			}
+		}
+		return osutil.AtomicWriteFile(keyfilePath, content, 0600, 0)
+	})
+}
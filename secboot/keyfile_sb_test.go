@@ -0,0 +1,388 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nosecboot
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package secboot_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	sb "github.com/snapcore/secboot"
+	sb_hooks "github.com/snapcore/secboot/hooks"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/osutil/disks"
+	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type keyfileSuite struct {
+	testutil.BaseTest
+
+	// fsDir is the content of the filesystem carrying the keyfile
+	fsDir  string
+	mounts []string
+}
+
+var _ = Suite(&keyfileSuite{})
+
+type mockAuthRequestor struct {
+	passphrases []string
+	requests    int
+}
+
+func (r *mockAuthRequestor) RequestUserCredential(ctx context.Context, name, path string, authTypes sb.UserAuthType) (string, error) {
+	r.requests++
+	if len(r.passphrases) == 0 {
+		return "", os.ErrNotExist
+	}
+	passphrase := r.passphrases[0]
+	r.passphrases = r.passphrases[1:]
+	return passphrase, nil
+}
+
+func (s *keyfileSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	rootDir := c.MkDir()
+	dirs.SetRootDir(rootDir)
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+
+	byLabel := filepath.Join(rootDir, "/dev/disk/by-label")
+	c.Assert(os.MkdirAll(byLabel, 0755), IsNil)
+	c.Assert(os.Symlink("../../sdb1", filepath.Join(byLabel, "fde-key")), IsNil)
+
+	s.fsDir = c.MkDir()
+	s.mounts = nil
+	s.AddCleanup(secboot.MockKeyfileMount(func(devicePath, mountPoint string, writable bool) error {
+		c.Check(devicePath, Equals, filepath.Join(byLabel, "fde-key"))
+		mode := "ro"
+		if writable {
+			mode = "rw"
+		}
+		s.mounts = append(s.mounts, mode)
+		// "mount" the filesystem by replacing the mount point with a
+		// link to its content
+		if err := os.Remove(mountPoint); err != nil {
+			return err
+		}
+		return os.Symlink(s.fsDir, mountPoint)
+	}))
+	s.AddCleanup(secboot.MockKeyfileUnmount(func(mountPoint string) error {
+		return os.Remove(mountPoint)
+	}))
+	s.AddCleanup(secboot.MockKeyfileAuthRequestor(func() sb.AuthRequestor {
+		c.Fatalf("unexpected passphrase request")
+		return nil
+	}))
+	s.AddCleanup(secboot.MockKeyfileDeviceTimeout(0))
+}
+
+func (s *keyfileSuite) location() device.KeyfileLocation {
+	return device.KeyfileLocation{FilesystemLabel: "fde-key", Path: "keys/device.key"}
+}
+
+func (s *keyfileSuite) protect(c *C, kpf secboot.KeyProtectorFactory, key, aad []byte) (ciphertext, handle []byte) {
+	ciphertext, handle, err := kpf.ForKeyName("default").ProtectKey(rand.Reader, key, aad)
+	c.Assert(err, IsNil)
+	c.Check(ciphertext, Not(DeepEquals), key)
+	return ciphertext, handle
+}
+
+func (s *keyfileSuite) TestProtectAndReveal(c *C) {
+	kpf := secboot.KeyfileKeyProtectorFactory(s.location())
+	c.Check(secboot.ProtectorKeyfileLocation(kpf), DeepEquals, &device.KeyfileLocation{
+		FilesystemLabel: "fde-key",
+		Path:            "keys/device.key",
+	})
+
+	key := []byte("some-unlock-key")
+	ciphertext, handle := s.protect(c, kpf, key, []byte("aad"))
+	// the keyfile was loaded only once
+	_, handle2 := s.protect(c, kpf, []byte("other-key"), nil)
+	c.Check(s.mounts, DeepEquals, []string{"rw"})
+	c.Check(handle2, Not(DeepEquals), handle)
+
+	keyfile, err := os.ReadFile(filepath.Join(s.fsDir, "keys/device.key"))
+	c.Assert(err, IsNil)
+	c.Check(keyfile, HasLen, 32)
+
+	var tagged secboot.TaggedHandle
+	c.Assert(json.Unmarshal(handle, &tagged), IsNil)
+	c.Check(tagged.Method, Equals, "keyfile")
+
+	var k secboot.KeyRevealerV3
+	plain, err := k.RevealKey(handle, ciphertext, []byte("aad"))
+	c.Assert(err, IsNil)
+	c.Check(plain, DeepEquals, key)
+	c.Check(s.mounts, DeepEquals, []string{"rw", "ro"})
+
+	// the aad is authenticated
+	_, err = k.RevealKey(handle, ciphertext, []byte("other"))
+	c.Check(err, ErrorMatches, "cannot reveal key with keyfile: .*")
+}
+
+func (s *keyfileSuite) TestProtectUsesExistingKeyfile(c *C) {
+	c.Assert(os.WriteFile(filepath.Join(s.fsDir, "ubuntu-fde.keyfile"), []byte("existing-keyfile"), 0600), IsNil)
+
+	kpf := secboot.KeyfileKeyProtectorFactory(device.KeyfileLocation{FilesystemLabel: "fde-key"})
+	ciphertext, handle := s.protect(c, kpf, []byte("key"), nil)
+
+	keyfile, err := os.ReadFile(filepath.Join(s.fsDir, "ubuntu-fde.keyfile"))
+	c.Assert(err, IsNil)
+	c.Check(string(keyfile), Equals, "existing-keyfile")
+
+	var k secboot.KeyRevealerV3
+	plain, err := k.RevealKey(handle, ciphertext, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(plain), Equals, "key")
+}
+
+func (s *keyfileSuite) TestProtectAndRevealWithPassphrase(c *C) {
+	kpf, err := secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode:       device.AuthModePassphrase,
+		Passphrase: "secret",
+	})
+	c.Assert(err, IsNil)
+	ciphertext, handle := s.protect(c, kpf, []byte("key"), nil)
+
+	// the keyfile is protected by the passphrase
+	content, err := os.ReadFile(filepath.Join(s.fsDir, "keys/device.key"))
+	c.Assert(err, IsNil)
+	var envelope map[string]any
+	c.Assert(json.Unmarshal(content, &envelope), IsNil)
+	c.Check(envelope["kdf"], Equals, "argon2id")
+
+	requestor := &mockAuthRequestor{passphrases: []string{"wrong", "secret"}}
+	restore := secboot.MockKeyfileAuthRequestor(func() sb.AuthRequestor { return requestor })
+	defer restore()
+
+	var k secboot.KeyRevealerV3
+	plain, err := k.RevealKey(handle, ciphertext, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(plain), Equals, "key")
+	c.Check(requestor.requests, Equals, 2)
+}
+
+func (s *keyfileSuite) TestProtectFactoryResetPassphraseProtectedKeyfile(c *C) {
+	kpf, err := secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode:       device.AuthModePassphrase,
+		Passphrase: "secret",
+	})
+	c.Assert(err, IsNil)
+	ciphertext, handle := s.protect(c, kpf, []byte("key"), nil)
+	keyfile, err := os.ReadFile(filepath.Join(s.fsDir, "keys/device.key"))
+	c.Assert(err, IsNil)
+
+	// factory reset protects new keys with the existing keyfile and without
+	// the passphrase, which is not asked for
+	kpf = secboot.KeyfileKeyProtectorFactory(s.location())
+	resetCiphertext, resetHandle := s.protect(c, kpf, []byte("reset-key"), nil)
+	c.Check(s.mounts, DeepEquals, []string{"rw", "rw"})
+
+	// the keyfile is left untouched
+	content, err := os.ReadFile(filepath.Join(s.fsDir, "keys/device.key"))
+	c.Assert(err, IsNil)
+	c.Check(content, DeepEquals, keyfile)
+
+	requestor := &mockAuthRequestor{passphrases: []string{"secret", "secret"}}
+	restore := secboot.MockKeyfileAuthRequestor(func() sb.AuthRequestor { return requestor })
+	defer restore()
+
+	var k secboot.KeyRevealerV3
+	plain, err := k.RevealKey(resetHandle, resetCiphertext, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(plain), Equals, "reset-key")
+	plain, err = k.RevealKey(handle, ciphertext, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(plain), Equals, "key")
+	c.Check(requestor.requests, Equals, 2)
+}
+
+func (s *keyfileSuite) TestProtectPassphraseProtectedKeyfileWrongPassphrase(c *C) {
+	kpf, err := secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode:       device.AuthModePassphrase,
+		Passphrase: "secret",
+	})
+	c.Assert(err, IsNil)
+	s.protect(c, kpf, []byte("key"), nil)
+
+	kpf, err = secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode:       device.AuthModePassphrase,
+		Passphrase: "wrong",
+	})
+	c.Assert(err, IsNil)
+	_, _, err = kpf.ForKeyName("default").ProtectKey(rand.Reader, []byte("key"), nil)
+	c.Check(err, ErrorMatches, "cannot unlock keyfile: wrong passphrase")
+}
+
+func (s *keyfileSuite) TestUnlockVolumeUsingSealedKeyWithKeyfile(c *C) {
+	kpf, err := secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode:       device.AuthModePassphrase,
+		Passphrase: "secret",
+	})
+	c.Assert(err, IsNil)
+	ciphertext, handle := s.protect(c, kpf, []byte("unlock-key"), []byte("aad"))
+
+	requestor := &mockAuthRequestor{passphrases: []string{"secret"}}
+	s.AddCleanup(secboot.MockKeyfileAuthRequestor(func() sb.AuthRequestor { return requestor }))
+	s.AddCleanup(secboot.MockRandomKernelUUID(func() (string, error) {
+		return "random-uuid-for-test", nil
+	}))
+	s.AddCleanup(secboot.MockSbSetBootMode(func(mode string) {}))
+	var revealer sb_hooks.KeyRevealer
+	s.AddCleanup(secboot.MockSbSetKeyRevealer(func(kr sb_hooks.KeyRevealer) {
+		if kr != nil {
+			revealer = kr
+		}
+	}))
+	storage := &mockStorageContainer{name: "storage"}
+	s.AddCleanup(secboot.MockSbFindStorageContainer(func(ctx context.Context, path string) (sb.StorageContainer, error) {
+		c.Check(path, Equals, "/dev/disk/by-uuid/enc-dev-uuid")
+		return storage, nil
+	}))
+
+	activated := 0
+	activateContext := newMockActivateContext(
+		func(ctx context.Context, container sb.StorageContainer, opts ...sb.ActivateOption) error {
+			activated++
+			c.Check(container, Equals, storage)
+			// the unlock key is revealed with the keyfile, like
+			// secboot does when activating the container
+			c.Assert(revealer, NotNil)
+			plain, err := revealer.RevealKey(handle, ciphertext, []byte("aad"))
+			c.Assert(err, IsNil)
+			c.Check(string(plain), Equals, "unlock-key")
+			return nil
+		},
+	)
+
+	mockDiskWithEncDev := &disks.MockDiskMapping{
+		Structure: []disks.Partition{
+			{
+				FilesystemLabel: "device-name-enc",
+				FilesystemUUID:  "enc-dev-uuid",
+				PartitionUUID:   "enc-dev-partuuid",
+			},
+		},
+	}
+	keyPath := filepath.Join(c.MkDir(), "device-name.sealed-key")
+	res, err := secboot.UnlockVolumeUsingSealedKeyIfEncrypted(activateContext, mockDiskWithEncDev, "device-name", keyPath, &secboot.UnlockVolumeUsingSealedKeyOptions{})
+	c.Assert(err, IsNil)
+	c.Check(res, DeepEquals, secboot.UnlockResult{
+		UnlockMethod: secboot.UnlockedWithSealedKey,
+		IsEncrypted:  true,
+		PartDevice:   "/dev/disk/by-partuuid/enc-dev-partuuid",
+		FsDevice:     "/dev/mapper/device-name-random-uuid-for-test",
+	})
+	c.Check(activated, Equals, 1)
+	c.Check(requestor.requests, Equals, 1)
+	// the keyfile filesystem was mounted read-only to reveal the key
+	c.Check(s.mounts, DeepEquals, []string{"rw", "ro"})
+}
+
+func (s *keyfileSuite) TestRevealWrongPassphrase(c *C) {
+	kpf, err := secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode:       device.AuthModePassphrase,
+		Passphrase: "secret",
+	})
+	c.Assert(err, IsNil)
+	ciphertext, handle := s.protect(c, kpf, []byte("key"), nil)
+
+	requestor := &mockAuthRequestor{passphrases: []string{"one", "two", "three", "secret"}}
+	restore := secboot.MockKeyfileAuthRequestor(func() sb.AuthRequestor { return requestor })
+	defer restore()
+
+	var k secboot.KeyRevealerV3
+	_, err = k.RevealKey(handle, ciphertext, nil)
+	c.Check(err, ErrorMatches, "cannot unlock keyfile: wrong passphrase")
+	c.Check(requestor.requests, Equals, 3)
+}
+
+func (s *keyfileSuite) TestProtectWithPassphraseExistingUnprotectedKeyfile(c *C) {
+	c.Assert(os.MkdirAll(filepath.Join(s.fsDir, "keys"), 0700), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(s.fsDir, "keys/device.key"), []byte("existing-keyfile"), 0600), IsNil)
+
+	kpf, err := secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode:       device.AuthModePassphrase,
+		Passphrase: "secret",
+	})
+	c.Assert(err, IsNil)
+	_, _, err = kpf.ForKeyName("default").ProtectKey(rand.Reader, []byte("key"), nil)
+	c.Check(err, ErrorMatches, `cannot use existing keyfile "keys/device.key" with a passphrase: keyfile is not protected by a passphrase`)
+}
+
+func (s *keyfileSuite) TestVolumesAuthUnsupportedMode(c *C) {
+	_, err := secboot.KeyfileFactoryWithVolumesAuth(secboot.KeyfileKeyProtectorFactory(s.location()), &device.VolumesAuthOptions{
+		Mode: device.AuthModePIN,
+		PIN:  "1234",
+	})
+	c.Check(err, ErrorMatches, `"pin" authentication mode is not supported for keys protected by a keyfile`)
+}
+
+func (s *keyfileSuite) TestSealKeysWithProtectorVolumesAuthNotKeyfile(c *C) {
+	err := secboot.SealKeysWithProtector(secboot.OPTEEKeyProtectorFactory(), nil, &secboot.SealKeysWithFDESetupHookParams{
+		VolumesAuth: &device.VolumesAuthOptions{Mode: device.AuthModePassphrase, Passphrase: "secret"},
+	})
+	c.Check(err, ErrorMatches, "volumes authentication is only supported for keys protected by a keyfile")
+}
+
+func (s *keyfileSuite) TestKeyfileDeviceMissing(c *C) {
+	kpf := secboot.KeyfileKeyProtectorFactory(device.KeyfileLocation{FilesystemLabel: "other"})
+	_, _, err := kpf.ForKeyName("default").ProtectKey(rand.Reader, []byte("key"), nil)
+	c.Check(err, ErrorMatches, `cannot find keyfile filesystem with label "other": .*`)
+	c.Check(s.mounts, HasLen, 0)
+}
+
+func (s *keyfileSuite) TestChangeKeyfilePassphrase(c *C) {
+	kpf := secboot.KeyfileKeyProtectorFactory(s.location())
+	ciphertext, handle := s.protect(c, kpf, []byte("key"), nil)
+
+	err := secboot.ChangeKeyfilePassphrase(s.location(), "wrong", "secret")
+	c.Check(err, ErrorMatches, "cannot unlock keyfile: keyfile is not protected by a passphrase")
+
+	err = secboot.ChangeKeyfilePassphrase(s.location(), "", "secret")
+	c.Assert(err, IsNil)
+	err = secboot.ChangeKeyfilePassphrase(s.location(), "wrong", "other")
+	c.Check(err, ErrorMatches, "cannot unlock keyfile: wrong passphrase")
+
+	requestor := &mockAuthRequestor{passphrases: []string{"secret"}}
+	restore := secboot.MockKeyfileAuthRequestor(func() sb.AuthRequestor { return requestor })
+	defer restore()
+
+	// keys protected before the change can still be revealed
+	var k secboot.KeyRevealerV3
+	plain, err := k.RevealKey(handle, ciphertext, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(plain), Equals, "key")
+
+	// and the passphrase can be removed again
+	err = secboot.ChangeKeyfilePassphrase(s.location(), "secret", "")
+	c.Assert(err, IsNil)
+	content, err := os.ReadFile(filepath.Join(s.fsDir, "keys/device.key"))
+	c.Assert(err, IsNil)
+	c.Check(content, HasLen, 32)
+}
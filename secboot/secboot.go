@@ -161,6 +161,9 @@ type SealKeysWithFDESetupHookParams struct {
 	AuxKeyFile string
 	// The primary key to use, nil if needs to be generated
 	PrimaryKey []byte
+	// The volumes authentication options, only supported for keys
+	// protected by a keyfile
+	VolumesAuth *device.VolumesAuthOptions
 }
 
 // KeyDataLocation represents the possible places where key data
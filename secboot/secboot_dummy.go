@@ -26,6 +26,7 @@ import (
 	"errors"
 	"io"
 
+	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/kernel/fde"
 	"github.com/snapcore/snapd/secboot/keys"
 )
@@ -50,6 +51,18 @@ func OPTEEKeyProtectorFactory() KeyProtectorFactory {
 	return nil
 }
 
+func KeyfileKeyProtectorFactory(loc device.KeyfileLocation) KeyProtectorFactory {
+	return nil
+}
+
+func ProtectorKeyfileLocation(kpf KeyProtectorFactory) *device.KeyfileLocation {
+	return nil
+}
+
+func ChangeKeyfilePassphrase(loc device.KeyfileLocation, oldPassphrase, newPassphrase string) error {
+	return errBuildWithoutSecboot
+}
+
 func FDEOpteeTAPresent() bool {
 	return false
 }
@@ -154,6 +154,17 @@ func SealKeysWithProtector(kpf KeyProtectorFactory, keys []SealKeyRequest, param
 		primaryKey = params.PrimaryKey
 	}
 
+	if params.VolumesAuth != nil {
+		kkpf, ok := kpf.(*keyfileKeyProtectorFactory)
+		if !ok {
+			return fmt.Errorf("volumes authentication is only supported for keys protected by a keyfile")
+		}
+		var err error
+		if kpf, err = kkpf.withVolumesAuth(params.VolumesAuth); err != nil {
+			return err
+		}
+	}
+
 	// if we have any keys, then we'll be replacing the singleton key protector
 	// in sb_hooks. make sure we reset it before leaving this function.
 	if len(keys) > 0 {
@@ -350,6 +361,8 @@ func (kr *keyRevealerV3) RevealKey(data, ciphertext, aad []byte) (plaintext []by
 	switch tagged.Method {
 	case "optee":
 		return revealWithOPTEE(tagged.Handle, ciphertext)
+	case "keyfile":
+		return revealWithKeyfile(tagged.Handle, ciphertext, aad)
 	default:
 		return nil, fmt.Errorf("unknown key revealer method: %s", tagged.Method)
 	}
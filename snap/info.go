@@ -28,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -452,6 +453,9 @@ type Info struct {
 
 	// IntegrityData available for this snap
 	IntegrityData *IntegrityDataInfo
+
+	// Health holds the periodic health check settings of the snap, if any.
+	Health *HealthInfo
 }
 
 // StoreAccount holds information about a store account, for example of snap
@@ -1287,6 +1291,60 @@ type TimerInfo struct {
 	Timer string
 }
 
+// WatchPathInfo provides information on a path watched to activate an
+// application.
+type WatchPathInfo struct {
+	App *AppInfo
+
+	Path      string
+	Condition WatchPathCondition
+}
+
+// WatchPathCondition is the condition under which a watched path activates
+// an application.
+type WatchPathCondition string
+
+const (
+	// WatchPathExists activates when the path exists.
+	WatchPathExists WatchPathCondition = "exists"
+	// WatchPathExistsGlob activates when a file matching the glob exists.
+	WatchPathExistsGlob WatchPathCondition = "exists-glob"
+	// WatchPathChanged activates when the file is closed after a write or
+	// when it is renamed or removed.
+	WatchPathChanged WatchPathCondition = "changed"
+	// WatchPathModified activates on every write to the file.
+	WatchPathModified WatchPathCondition = "modified"
+	// WatchPathDirectoryNotEmpty activates when the directory contains at
+	// least one file.
+	WatchPathDirectoryNotEmpty WatchPathCondition = "directory-not-empty"
+)
+
+// ResourcesInfo provides information on the resource limits and scheduling
+// settings of a service. Unset settings are nil or empty.
+type ResourcesInfo struct {
+	LimitNOFILE *uint64
+	// LimitCORE is a size, either in bytes or with a unit like "64MB"
+	LimitCORE string
+	// Nice is the niceness of the service, only lowering the priority
+	// is supported (0 to 19)
+	Nice              *int
+	IOSchedulingClass string
+	OOMScoreAdjust    *int
+	CPUWeight         *uint64
+}
+
+// LimitCOREBytes returns the LimitCORE setting in bytes.
+func (res *ResourcesInfo) LimitCOREBytes() (uint64, error) {
+	if v, err := strconv.ParseUint(res.LimitCORE, 10, 64); err == nil {
+		return v, nil
+	}
+	v, err := strutil.ParseByteSize(res.LimitCORE)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(v), nil
+}
+
 // StopModeType is the type for the "stop-mode:" of a snap app
 type StopModeType string
 
@@ -1369,9 +1427,21 @@ type AppInfo struct {
 	// before
 	After  []string
 	Before []string
+	// AfterConnected lists the plugs whose connected slots are served by
+	// services of other snaps that this service will start after
+	AfterConnected []*PlugInfo
 
 	Timer *TimerInfo
 
+	// WatchPaths lists the paths which activate the service through a
+	// systemd .path unit.
+	WatchPaths []*WatchPathInfo
+	// ActivatesOnDevice lists the plugs whose connected devices
+	// activate the service when they appear.
+	ActivatesOnDevice []*PlugInfo
+
+	Resources *ResourcesInfo
+
 	Autostart string
 }
 
@@ -1457,6 +1527,15 @@ type SystemUsernameInfo struct {
 	Attrs map[string]any
 }
 
+// HealthInfo provides information about the periodic health checks of a
+// snap, which run its check-health hook every Interval.
+type HealthInfo struct {
+	Interval timeout.Timeout
+	// RestartServicesAfter is the number of consecutive error healths after
+	// which the services of the snap are restarted, 0 means never.
+	RestartServicesAfter int
+}
+
 type CategoryInfo struct {
 	Name     string `json:"name"`
 	Featured bool   `json:"featured"`
@@ -1472,6 +1551,23 @@ func (timer *TimerInfo) File() string {
 	return filepath.Join(timer.App.serviceDir(), timer.App.SecurityTag()+".timer")
 }
 
+// WatchPathsFile returns the path to the *.path file activating the
+// application.
+func (app *AppInfo) WatchPathsFile() string {
+	return filepath.Join(app.serviceDir(), app.SecurityTag()+".path")
+}
+
+// DeviceTargetName returns the name of the systemd target which is pulled in
+// by udev when a device activating the application appears.
+func (app *AppInfo) DeviceTargetName() string {
+	return app.SecurityTag() + ".device.target"
+}
+
+// DeviceTargetFile returns the path to the *.device.target file.
+func (app *AppInfo) DeviceTargetFile() string {
+	return filepath.Join(app.serviceDir(), app.DeviceTargetName())
+}
+
 func (app *AppInfo) String() string {
 	return JoinSnapApp(app.Snap.InstanceName(), app.Name)
 }
@@ -2141,6 +2237,10 @@ type RefreshFailureSeverity string
 const (
 	RefreshFailureSeverityNone        RefreshFailureSeverity = ""
 	RefreshFailureSeverityAfterReboot RefreshFailureSeverity = "after-reboot"
+	// RefreshFailureSeverityHealthCheck marks a revision that was reverted
+	// because it did not report okay health, auto-refreshes to it are held
+	// until a different revision becomes available.
+	RefreshFailureSeverityHealthCheck RefreshFailureSeverity = "health-check"
 )
 
 // RefreshFailures holds information about snap failed refreshes.
@@ -57,6 +57,7 @@ type snapYaml struct {
 	SystemUsernames map[string]any           `yaml:"system-usernames,omitempty"`
 	Links           map[string][]string      `yaml:"links,omitempty"`
 	Components      map[string]componentYaml `yaml:"components,omitempty"`
+	Health          *healthYaml              `yaml:"health,omitempty"`
 
 	// TypoLayouts is used to detect the use of the incorrect plural form of "layout"
 	TypoLayouts typoDetector `yaml:"layouts,omitempty"`
@@ -104,14 +105,34 @@ type appYaml struct {
 
 	Sockets map[string]socketsYaml `yaml:"sockets,omitempty"`
 
-	After  []string `yaml:"after,omitempty"`
-	Before []string `yaml:"before,omitempty"`
+	After          []string `yaml:"after,omitempty"`
+	Before         []string `yaml:"before,omitempty"`
+	AfterConnected []string `yaml:"after-connected,omitempty"`
 
 	Timer string `yaml:"timer,omitempty"`
 
+	WatchPaths        []watchPathYaml `yaml:"watch-paths,omitempty"`
+	ActivatesOnDevice []string        `yaml:"activates-on-device,omitempty"`
+
+	Resources *resourcesYaml `yaml:"resources,omitempty"`
+
 	Autostart string `yaml:"autostart,omitempty"`
 }
 
+type watchPathYaml struct {
+	Path      string             `yaml:"path"`
+	Condition WatchPathCondition `yaml:"condition"`
+}
+
+type resourcesYaml struct {
+	LimitNOFILE       *uint64 `yaml:"limit-nofile,omitempty"`
+	LimitCORE         string  `yaml:"limit-core,omitempty"`
+	Nice              *int    `yaml:"nice,omitempty"`
+	IOSchedulingClass string  `yaml:"io-scheduling-class,omitempty"`
+	OOMScoreAdjust    *int    `yaml:"oom-score-adjust,omitempty"`
+	CPUWeight         *uint64 `yaml:"cpu-weight,omitempty"`
+}
+
 type hookYaml struct {
 	PlugNames    []string           `yaml:"plugs,omitempty"`
 	SlotNames    []string           `yaml:"slots,omitempty"`
@@ -119,6 +140,11 @@ type hookYaml struct {
 	CommandChain []string           `yaml:"command-chain,omitempty"`
 }
 
+type healthYaml struct {
+	Interval             timeout.Timeout `yaml:"interval,omitempty"`
+	RestartServicesAfter int             `yaml:"restart-services-after,omitempty"`
+}
+
 type componentYaml struct {
 	Type        ComponentType       `yaml:"type"`
 	Summary     string              `yaml:"summary"`
@@ -312,6 +338,13 @@ func infoSkeletonFromSnapYaml(y snapYaml) *Info {
 		OriginalLinks:       make(map[string][]string),
 	}
 
+	if y.Health != nil {
+		snap.Health = &HealthInfo{
+			Interval:             y.Health.Interval,
+			RestartServicesAfter: y.Health.RestartServicesAfter,
+		}
+	}
+
 	sort.Strings(snap.Assumes)
 
 	return snap
@@ -472,6 +505,12 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 		if len(yApp.ActivatesOn) > 0 {
 			app.ActivatesOn = make([]*SlotInfo, 0, len(yApp.ActivatesOn))
 		}
+		if app.Plugs == nil && (len(yApp.ActivatesOnDevice) > 0 || len(yApp.AfterConnected) > 0) {
+			app.Plugs = make(map[string]*PlugInfo)
+		}
+		if len(yApp.ActivatesOnDevice) > 0 {
+			app.ActivatesOnDevice = make([]*PlugInfo, 0, len(yApp.ActivatesOnDevice))
+		}
 		// Daemons default to being system daemons
 		if app.Daemon != "" && app.DaemonScope == "" {
 			app.DaemonScope = SystemDaemon
@@ -529,6 +568,28 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 			app.Slots[slotName] = slot
 			slot.Apps[appName] = app
 		}
+		for _, plugName := range yApp.ActivatesOnDevice {
+			plug, ok := snap.Plugs[plugName]
+			if !ok {
+				return fmt.Errorf("invalid activates-on-device value %q on app %q: plug not found", plugName, appName)
+			}
+			app.ActivatesOnDevice = append(app.ActivatesOnDevice, plug)
+			// Implicitly add the plug to the app
+			strk.markPlug(plug)
+			app.Plugs[plugName] = plug
+			plug.Apps[appName] = app
+		}
+		for _, plugName := range yApp.AfterConnected {
+			plug, ok := snap.Plugs[plugName]
+			if !ok {
+				return fmt.Errorf("invalid after-connected value %q on app %q: plug not found", plugName, appName)
+			}
+			app.AfterConnected = append(app.AfterConnected, plug)
+			// Implicitly add the plug to the app
+			strk.markPlug(plug)
+			app.Plugs[plugName] = plug
+			plug.Apps[appName] = app
+		}
 		for name, data := range yApp.Sockets {
 			app.Sockets[name] = &SocketInfo{
 				App:          app,
@@ -543,6 +604,23 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 				Timer: yApp.Timer,
 			}
 		}
+		if yApp.Resources != nil {
+			app.Resources = &ResourcesInfo{
+				LimitNOFILE:       yApp.Resources.LimitNOFILE,
+				LimitCORE:         yApp.Resources.LimitCORE,
+				Nice:              yApp.Resources.Nice,
+				IOSchedulingClass: yApp.Resources.IOSchedulingClass,
+				OOMScoreAdjust:    yApp.Resources.OOMScoreAdjust,
+				CPUWeight:         yApp.Resources.CPUWeight,
+			}
+		}
+		for _, data := range yApp.WatchPaths {
+			app.WatchPaths = append(app.WatchPaths, &WatchPathInfo{
+				App:       app,
+				Path:      data.Path,
+				Condition: data.Condition,
+			})
+		}
 		// collect all common IDs
 		if app.CommonID != "" {
 			snap.CommonIDs = append(snap.CommonIDs, app.CommonID)
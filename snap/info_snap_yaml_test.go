@@ -1434,6 +1434,133 @@ apps:
 	c.Check(err, ErrorMatches, `invalid activates-on value "test-slot" on app "daemon": slot not found`)
 }
 
+func (s *YamlSuite) TestUnmarshalWatchPaths(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+apps:
+    daemon:
+        daemon: simple
+        watch-paths:
+          - path: $SNAP_COMMON/incoming
+            condition: directory-not-empty
+          - path: $SNAP_DATA/config.yaml
+            condition: changed
+`))
+	c.Assert(err, IsNil)
+	app := info.Apps["daemon"]
+	c.Assert(app, Not(IsNil))
+	c.Check(app.WatchPaths, DeepEquals, []*snap.WatchPathInfo{
+		{App: app, Path: "$SNAP_COMMON/incoming", Condition: snap.WatchPathDirectoryNotEmpty},
+		{App: app, Path: "$SNAP_DATA/config.yaml", Condition: snap.WatchPathChanged},
+	})
+}
+
+func (s *YamlSuite) TestUnmarshalActivatesOnDevice(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    serial:
+        interface: serial-port
+apps:
+    daemon:
+        daemon: simple
+        activates-on-device: [serial]
+    foo:
+`))
+	c.Assert(err, IsNil)
+
+	app1 := info.Apps["daemon"]
+	app2 := info.Apps["foo"]
+	plug := info.Plugs["serial"]
+	c.Assert(plug, Not(IsNil))
+	c.Check(app1.ActivatesOnDevice, DeepEquals, []*snap.PlugInfo{plug})
+	// activates-on-device plugs are implicitly added to the app
+	c.Check(app1.Plugs, DeepEquals, map[string]*snap.PlugInfo{plug.Name: plug})
+	// As plug has been bound to app1, it isn't implicitly applied here
+	c.Check(app2.Plugs, HasLen, 0)
+	c.Check(plug.Apps, DeepEquals, map[string]*snap.AppInfo{app1.Name: app1})
+}
+
+func (s *YamlSuite) TestUnmarshalActivatesOnDeviceUnknownPlug(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+apps:
+    daemon:
+        daemon: simple
+        activates-on-device: ["serial"]
+`))
+	c.Check(info, IsNil)
+	c.Check(err, ErrorMatches, `invalid activates-on-device value "serial" on app "daemon": plug not found`)
+}
+
+func (s *YamlSuite) TestUnmarshalAfterConnected(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    db:
+        interface: content
+        content: db-socket
+        target: $SNAP_DATA/db
+apps:
+    daemon:
+        daemon: simple
+        after-connected: [db]
+    foo:
+`))
+	c.Assert(err, IsNil)
+
+	app1 := info.Apps["daemon"]
+	app2 := info.Apps["foo"]
+	plug := info.Plugs["db"]
+	c.Assert(plug, Not(IsNil))
+	c.Check(app1.AfterConnected, DeepEquals, []*snap.PlugInfo{plug})
+	// after-connected plugs are implicitly added to the app
+	c.Check(app1.Plugs, DeepEquals, map[string]*snap.PlugInfo{plug.Name: plug})
+	c.Check(app2.Plugs, HasLen, 0)
+	c.Check(plug.Apps, DeepEquals, map[string]*snap.AppInfo{app1.Name: app1})
+}
+
+func (s *YamlSuite) TestUnmarshalAfterConnectedUnknownPlug(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+apps:
+    daemon:
+        daemon: simple
+        after-connected: [db]
+`))
+	c.Check(info, IsNil)
+	c.Check(err, ErrorMatches, `invalid after-connected value "db" on app "daemon": plug not found`)
+}
+
+func (s *YamlSuite) TestUnmarshalResources(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+apps:
+    daemon:
+        daemon: simple
+        resources:
+            limit-nofile: 65536
+            limit-core: 0
+            nice: 5
+            io-scheduling-class: idle
+            oom-score-adjust: 100
+            cpu-weight: 200
+    other:
+        daemon: simple
+`))
+	c.Assert(err, IsNil)
+	limitNOFILE, nice, oomScoreAdjust, cpuWeight := uint64(65536), 5, 100, uint64(200)
+	c.Check(info.Apps["daemon"].Resources, DeepEquals, &snap.ResourcesInfo{
+		LimitNOFILE:       &limitNOFILE,
+		LimitCORE:         "0",
+		Nice:              &nice,
+		IOSchedulingClass: "idle",
+		OOMScoreAdjust:    &oomScoreAdjust,
+		CPUWeight:         &cpuWeight,
+	})
+	c.Check(info.Apps["other"].Resources, IsNil)
+}
+
 // type and architectures
 
 func (s *YamlSuite) TestSnapYamlTypeDefault(c *C) {
@@ -2016,6 +2143,27 @@ apps:
 	c.Check(app.SuccessExitStatus, DeepEquals, []string{"42", "250"})
 }
 
+func (s *YamlSuite) TestSnapYamlHealth(c *C) {
+	y := []byte(`name: binary
+version: 1.0
+hooks:
+  check-health:
+health:
+  interval: 10m
+  restart-services-after: 3
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Health, DeepEquals, &snap.HealthInfo{
+		Interval:             timeout.Timeout(10 * time.Minute),
+		RestartServicesAfter: 3,
+	})
+
+	info, err = snap.InfoFromSnapYaml([]byte("name: binary\nversion: 1.0\n"))
+	c.Assert(err, IsNil)
+	c.Check(info.Health, IsNil)
+}
+
 func (s *YamlSuite) TestSnapYamlSystemUsernamesParsing(c *C) {
 	y := []byte(`name: binary
 version: 1.0
@@ -1607,6 +1607,25 @@ apps:
 	c.Check(app.Timer.File(), Equals, dirs.GlobalRootDir+"/etc/systemd/system/snap.pans_instance.app1.timer")
 }
 
+func (s *infoSuite) TestWatchPathsAndDeviceTargetFile(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: pans
+apps:
+  app1:
+    daemon: simple
+`))
+	c.Assert(err, IsNil)
+
+	app := info.Apps["app1"]
+	c.Check(app.WatchPathsFile(), Equals, dirs.GlobalRootDir+"/etc/systemd/system/snap.pans.app1.path")
+	c.Check(app.DeviceTargetName(), Equals, "snap.pans.app1.device.target")
+	c.Check(app.DeviceTargetFile(), Equals, dirs.GlobalRootDir+"/etc/systemd/system/snap.pans.app1.device.target")
+
+	// snap with instance key
+	info.InstanceKey = "instance"
+	c.Check(app.WatchPathsFile(), Equals, dirs.GlobalRootDir+"/etc/systemd/system/snap.pans_instance.app1.path")
+	c.Check(app.DeviceTargetFile(), Equals, dirs.GlobalRootDir+"/etc/systemd/system/snap.pans_instance.app1.device.target")
+}
+
 func (s *infoSuite) TestLayoutParsing(c *C) {
 	info, err := snap.InfoFromSnapYaml([]byte(`name: layout-demo
 layout:
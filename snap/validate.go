@@ -29,6 +29,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/snapcore/snapd/osutil"
@@ -196,6 +197,29 @@ func validateHooks(info *Info) error {
 	return nil
 }
 
+// minHealthInterval is the shortest interval allowed between periodic
+// health checks.
+const minHealthInterval = time.Minute
+
+func validateHealth(info *Info) error {
+	if info.Health == nil {
+		return nil
+	}
+	if info.Hooks["check-health"] == nil {
+		return fmt.Errorf(`cannot specify "health" without a "check-health" hook`)
+	}
+	if time.Duration(info.Health.Interval) < minHealthInterval {
+		return fmt.Errorf("health interval must be at least %v, not %v", minHealthInterval, info.Health.Interval)
+	}
+	if info.Health.RestartServicesAfter < 0 {
+		return fmt.Errorf("health restart-services-after cannot be negative")
+	}
+	if info.Health.RestartServicesAfter > 0 && len(info.Services()) == 0 {
+		return fmt.Errorf("cannot specify health restart-services-after for a snap without services")
+	}
+	return nil
+}
+
 // ValidateHook validates the content of the given HookInfo
 func ValidateHook(hook *HookInfo) error {
 	if err := naming.ValidateHook(hook.Name); err != nil {
@@ -515,6 +539,10 @@ func Validate(info *Info) error {
 		return err
 	}
 
+	if err := validateHealth(info); err != nil {
+		return err
+	}
+
 	// Ensure that plugs and slots have appropriate names and interface names.
 	if err := plugsSlotsInterfacesNames(info); err != nil {
 		return err
@@ -772,6 +800,58 @@ func validateAppTimeouts(app *AppInfo) error {
 	return nil
 }
 
+// The limits below keep the resources settings of a service from elevating it
+// above the system services or from lifting limits entirely. A service can
+// only lower its own priority and make itself a preferred target of the OOM
+// killer, the protection of services is left to the vitality-hint option.
+const (
+	maxResourcesLimitNOFILE    = 1048576
+	minResourcesNice           = 0
+	maxResourcesNice           = 19
+	minResourcesOOMScoreAdjust = 0
+	maxResourcesOOMScoreAdjust = 1000
+	minResourcesCPUWeight      = 1
+	maxResourcesCPUWeight      = 10000
+)
+
+func validateAppResources(app *AppInfo) error {
+	res := app.Resources
+	if res == nil {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("resources are only applicable to services")
+	}
+
+	if res.LimitNOFILE != nil && (*res.LimitNOFILE < 1 || *res.LimitNOFILE > maxResourcesLimitNOFILE) {
+		return fmt.Errorf("resources limit-nofile must be in the range 1 to %d", maxResourcesLimitNOFILE)
+	}
+	if res.LimitCORE != "" {
+		if _, err := res.LimitCOREBytes(); err != nil {
+			return fmt.Errorf("resources limit-core is invalid: %v", err)
+		}
+	}
+	if res.Nice != nil && (*res.Nice < minResourcesNice || *res.Nice > maxResourcesNice) {
+		return fmt.Errorf("resources nice must be in the range %d to %d, services can only lower their priority", minResourcesNice, maxResourcesNice)
+	}
+	switch res.IOSchedulingClass {
+	case "", "best-effort", "idle":
+		// valid
+	case "realtime":
+		return errors.New(`resources io-scheduling-class "realtime" is not allowed as it can starve system services`)
+	default:
+		return fmt.Errorf("resources io-scheduling-class contains invalid value %q", res.IOSchedulingClass)
+	}
+	if res.OOMScoreAdjust != nil && (*res.OOMScoreAdjust < minResourcesOOMScoreAdjust || *res.OOMScoreAdjust > maxResourcesOOMScoreAdjust) {
+		return fmt.Errorf("resources oom-score-adjust must be in the range %d to %d", minResourcesOOMScoreAdjust, maxResourcesOOMScoreAdjust)
+	}
+	if res.CPUWeight != nil && (*res.CPUWeight < minResourcesCPUWeight || *res.CPUWeight > maxResourcesCPUWeight) {
+		return fmt.Errorf("resources cpu-weight must be in the range %d to %d", minResourcesCPUWeight, maxResourcesCPUWeight)
+	}
+	return nil
+}
+
 func validateAppTimer(app *AppInfo) error {
 	if app.Timer == nil {
 		return nil
@@ -868,6 +948,102 @@ func validateAppActivatesOn(app *AppInfo) error {
 	return nil
 }
 
+func validateAppWatchPaths(app *AppInfo) error {
+	if len(app.WatchPaths) == 0 {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("watch-paths is only applicable to services")
+	}
+
+	for _, watch := range app.WatchPaths {
+		if err := validateWatchPath(watch); err != nil {
+			return fmt.Errorf("invalid watch-paths entry %q: %v", watch.Path, err)
+		}
+	}
+	return nil
+}
+
+func validateWatchPath(watch *WatchPathInfo) error {
+	switch watch.Condition {
+	case WatchPathExists, WatchPathExistsGlob, WatchPathChanged, WatchPathModified, WatchPathDirectoryNotEmpty:
+		// valid
+	case "":
+		return errors.New("condition must be set")
+	default:
+		return fmt.Errorf("unknown condition %q", watch.Condition)
+	}
+
+	path := watch.Path
+	if path == "" {
+		return errors.New("path must be set")
+	}
+	if watch.Condition != WatchPathExistsGlob && strings.ContainsAny(path, "*?[") {
+		return fmt.Errorf("path can only contain a glob with condition %q", WatchPathExistsGlob)
+	}
+	if err := validateField("path", path, watchPathContentWhitelist); err != nil {
+		return err
+	}
+	if clean := filepath.Clean(path); clean != path {
+		return fmt.Errorf("path should be written as %q", clean)
+	}
+	if strings.Count(path, "$") > 1 {
+		return errors.New("path cannot reference more than one variable")
+	}
+
+	switch watch.App.DaemonScope {
+	case SystemDaemon:
+		if !(strings.HasPrefix(path, "$SNAP_DATA/") || strings.HasPrefix(path, "$SNAP_COMMON/")) {
+			return errors.New("system daemon paths must have a prefix of $SNAP_DATA or $SNAP_COMMON")
+		}
+	case UserDaemon:
+		if !(strings.HasPrefix(path, "$SNAP_USER_DATA/") || strings.HasPrefix(path, "$SNAP_USER_COMMON/")) {
+			return errors.New("user daemon paths must have a prefix of $SNAP_USER_DATA or $SNAP_USER_COMMON")
+		}
+	default:
+		return fmt.Errorf("cannot validate paths for daemon-scope %q", watch.App.DaemonScope)
+	}
+	return nil
+}
+
+func validateAppAfterConnected(app *AppInfo) error {
+	if len(app.AfterConnected) == 0 {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("after-connected is only applicable to services")
+	}
+
+	// services of other snaps are only ordered within the system instance
+	// of systemd
+	if app.DaemonScope != SystemDaemon {
+		return fmt.Errorf("after-connected is not supported for daemon-scope %q", app.DaemonScope)
+	}
+	return nil
+}
+
+func validateAppActivatesOnDevice(app *AppInfo) error {
+	if len(app.ActivatesOnDevice) == 0 {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("activates-on-device is only applicable to services")
+	}
+
+	// udev can only request units from the system instance of systemd
+	if app.DaemonScope != SystemDaemon {
+		return fmt.Errorf("activates-on-device is not supported for daemon-scope %q", app.DaemonScope)
+	}
+
+	if len(app.Sockets) > 0 || app.Timer != nil || len(app.ActivatesOn) > 0 || len(app.WatchPaths) > 0 {
+		return errors.New("activates-on-device cannot be combined with other activation methods")
+	}
+	return nil
+}
+
 // appContentWhitelist is the whitelist of legal chars in the "apps"
 // section of snap.yaml. Do not allow any of [',",`] here or snap-exec
 // will get confused. chainContentWhitelist is the same, but for the
@@ -875,6 +1051,10 @@ func validateAppActivatesOn(app *AppInfo) error {
 var appContentWhitelist = regexp.MustCompile(`^[A-Za-z0-9/. _#:$-]*$`)
 var commandChainContentWhitelist = regexp.MustCompile(`^[A-Za-z0-9/._#:$-]*$`)
 
+// watchPathContentWhitelist is the whitelist of legal chars in the paths of
+// "watch-paths", it additionally allows glob characters.
+var watchPathContentWhitelist = regexp.MustCompile(`^[A-Za-z0-9/._#:$*?\[\]-]*$`)
+
 // ValidAppName tells whether a string is a valid application name.
 func ValidAppName(n string) bool {
 	return naming.ValidateApp(n) == nil
@@ -946,6 +1126,14 @@ func ValidateApp(app *AppInfo) error {
 		return err
 	}
 
+	if err := validateAppWatchPaths(app); err != nil {
+		return err
+	}
+
+	if err := validateAppActivatesOnDevice(app); err != nil {
+		return err
+	}
+
 	if err := validateAppRestart(app); err != nil {
 		return err
 	}
@@ -955,6 +1143,9 @@ func ValidateApp(app *AppInfo) error {
 	if err := validateAppOrderNames(app, app.After); err != nil {
 		return err
 	}
+	if err := validateAppAfterConnected(app); err != nil {
+		return err
+	}
 
 	if err := validateAppTimeouts(app); err != nil {
 		return err
@@ -964,6 +1155,10 @@ func ValidateApp(app *AppInfo) error {
 		return err
 	}
 
+	if err := validateAppResources(app); err != nil {
+		return err
+	}
+
 	// validate stop-mode
 	if err := app.StopMode.Validate(); err != nil {
 		return err
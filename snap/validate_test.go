@@ -685,6 +685,177 @@ apps:
 	c.Check(ValidateApp(app), ErrorMatches, `invalid activates-on value "dbus-slot": slot is also activatable on app "dup"`)
 }
 
+func (s *ValidateSuite) TestAppWatchPaths(c *C) {
+	for _, t := range []struct {
+		scope     string
+		path      string
+		condition string
+		err       string
+	}{
+		{"system", "$SNAP_COMMON/incoming", "directory-not-empty", ""},
+		{"system", "$SNAP_DATA/config", "changed", ""},
+		{"system", "$SNAP_DATA/config", "modified", ""},
+		{"system", "$SNAP_DATA/flag", "exists", ""},
+		{"system", "$SNAP_COMMON/spool/*.job", "exists-glob", ""},
+		{"user", "$SNAP_USER_COMMON/incoming", "directory-not-empty", ""},
+		// bad
+		{"system", "$SNAP_DATA/flag", "", `invalid watch-paths entry "\$SNAP_DATA/flag": condition must be set`},
+		{"system", "$SNAP_DATA/flag", "deleted", `invalid watch-paths entry "\$SNAP_DATA/flag": unknown condition "deleted"`},
+		{"system", "", "exists", `invalid watch-paths entry "": path must be set`},
+		{"system", "/etc/passwd", "changed", `invalid watch-paths entry "/etc/passwd": system daemon paths must have a prefix of \$SNAP_DATA or \$SNAP_COMMON`},
+		{"system", "$SNAP_USER_DATA/foo", "changed", `invalid watch-paths entry .*: system daemon paths must have a prefix of \$SNAP_DATA or \$SNAP_COMMON`},
+		{"user", "$SNAP_DATA/foo", "changed", `invalid watch-paths entry .*: user daemon paths must have a prefix of \$SNAP_USER_DATA or \$SNAP_USER_COMMON`},
+		{"system", "$SNAP_DATA/../foo", "changed", `invalid watch-paths entry .*: path should be written as "foo"`},
+		{"system", "$SNAP_DATA/$SNAP_COMMON", "changed", `invalid watch-paths entry .*: path cannot reference more than one variable`},
+		{"system", "$SNAP_DATA/*.job", "exists", `invalid watch-paths entry .*: path can only contain a glob with condition "exists-glob"`},
+		{"system", "$SNAP_DATA/foo bar\n", "exists", `invalid watch-paths entry .*: app description field 'path' contains illegal .*`},
+	} {
+		info, err := InfoFromSnapYaml([]byte(fmt.Sprintf(`name: foo
+version: 1.0
+apps:
+  server:
+    daemon: simple
+    daemon-scope: %s
+    watch-paths:
+      - path: "%s"
+        condition: "%s"
+`, t.scope, t.path, t.condition)))
+		c.Assert(err, IsNil)
+		err = ValidateApp(info.Apps["server"])
+		if t.err == "" {
+			c.Check(err, IsNil, Commentf("%q", t.path))
+		} else {
+			c.Check(err, ErrorMatches, t.err, Commentf("%q", t.path))
+		}
+	}
+}
+
+func (s *ValidateSuite) TestAppWatchPathsNotDaemon(c *C) {
+	info, err := InfoFromSnapYaml([]byte(`name: foo
+version: 1.0
+apps:
+  server:
+    watch-paths:
+      - path: $SNAP_DATA/flag
+        condition: exists
+`))
+	c.Assert(err, IsNil)
+	c.Check(ValidateApp(info.Apps["server"]), ErrorMatches, `watch-paths is only applicable to services`)
+}
+
+func (s *ValidateSuite) TestAppActivatesOnDevice(c *C) {
+	info, err := InfoFromSnapYaml([]byte(`name: foo
+version: 1.0
+plugs:
+  serial:
+    interface: serial-port
+apps:
+  server:
+    daemon: simple
+    activates-on-device: [serial]
+  cli:
+    activates-on-device: [serial]
+  user-server:
+    daemon: simple
+    daemon-scope: user
+    activates-on-device: [serial]
+  timed:
+    daemon: simple
+    timer: 10:00
+    activates-on-device: [serial]
+`))
+	c.Assert(err, IsNil)
+	c.Check(ValidateApp(info.Apps["server"]), IsNil)
+	c.Check(ValidateApp(info.Apps["cli"]), ErrorMatches, `activates-on-device is only applicable to services`)
+	c.Check(ValidateApp(info.Apps["user-server"]), ErrorMatches, `activates-on-device is not supported for daemon-scope "user"`)
+	c.Check(ValidateApp(info.Apps["timed"]), ErrorMatches, `activates-on-device cannot be combined with other activation methods`)
+}
+
+func (s *ValidateSuite) TestAppAfterConnected(c *C) {
+	info, err := InfoFromSnapYaml([]byte(`name: foo
+version: 1.0
+plugs:
+  db:
+    interface: network
+apps:
+  server:
+    daemon: simple
+    after-connected: [db]
+  cli:
+    after-connected: [db]
+  user-server:
+    daemon: simple
+    daemon-scope: user
+    after-connected: [db]
+`))
+	c.Assert(err, IsNil)
+	c.Check(ValidateApp(info.Apps["server"]), IsNil)
+	c.Check(ValidateApp(info.Apps["cli"]), ErrorMatches, `after-connected is only applicable to services`)
+	c.Check(ValidateApp(info.Apps["user-server"]), ErrorMatches, `after-connected is not supported for daemon-scope "user"`)
+}
+
+func (s *ValidateSuite) TestAppResources(c *C) {
+	for _, t := range []struct {
+		resources string
+		err       string
+	}{
+		{"limit-nofile: 1", ""},
+		{"limit-nofile: 1048576", ""},
+		{"limit-core: 0", ""},
+		{"limit-core: 1024", ""},
+		{"limit-core: 64MB", ""},
+		{"nice: 0", ""},
+		{"nice: 19", ""},
+		{"io-scheduling-class: best-effort", ""},
+		{"io-scheduling-class: idle", ""},
+		{"oom-score-adjust: 0", ""},
+		{"oom-score-adjust: 1000", ""},
+		{"cpu-weight: 1", ""},
+		{"cpu-weight: 10000", ""},
+		// bad
+		{"limit-nofile: 0", "resources limit-nofile must be in the range 1 to 1048576"},
+		{"limit-nofile: 1048577", "resources limit-nofile must be in the range 1 to 1048576"},
+		{"limit-core: infinity", `resources limit-core is invalid: cannot parse "infinity": no numerical prefix`},
+		{"limit-core: 10XB", `resources limit-core is invalid: cannot parse "10XB": try 'kB' or 'MB'`},
+		{"nice: -1", "resources nice must be in the range 0 to 19, services can only lower their priority"},
+		{"nice: 20", "resources nice must be in the range 0 to 19, services can only lower their priority"},
+		{"io-scheduling-class: realtime", `resources io-scheduling-class "realtime" is not allowed as it can starve system services`},
+		{"io-scheduling-class: potato", `resources io-scheduling-class contains invalid value "potato"`},
+		{"oom-score-adjust: -1", "resources oom-score-adjust must be in the range 0 to 1000"},
+		{"oom-score-adjust: 1001", "resources oom-score-adjust must be in the range 0 to 1000"},
+		{"cpu-weight: 0", "resources cpu-weight must be in the range 1 to 10000"},
+		{"cpu-weight: 10001", "resources cpu-weight must be in the range 1 to 10000"},
+	} {
+		info, err := InfoFromSnapYaml([]byte(fmt.Sprintf(`name: foo
+version: 1.0
+apps:
+  server:
+    daemon: simple
+    resources:
+      %s
+`, t.resources)))
+		c.Assert(err, IsNil)
+		err = ValidateApp(info.Apps["server"])
+		if t.err == "" {
+			c.Check(err, IsNil, Commentf(t.resources))
+		} else {
+			c.Check(err, ErrorMatches, t.err, Commentf(t.resources))
+		}
+	}
+}
+
+func (s *ValidateSuite) TestAppResourcesNotDaemon(c *C) {
+	info, err := InfoFromSnapYaml([]byte(`name: foo
+version: 1.0
+apps:
+  cli:
+    resources:
+      nice: 5
+`))
+	c.Assert(err, IsNil)
+	c.Check(ValidateApp(info.Apps["cli"]), ErrorMatches, `resources are only applicable to services`)
+}
+
 // Validate
 
 func (s *ValidateSuite) TestDetectInvalidProvenance(c *C) {
@@ -895,6 +1066,48 @@ hooks:
 	c.Check(err, ErrorMatches, "cannot specify \"default-configure\" hook without \"configure\" hook")
 }
 
+func (s *ValidateSuite) TestValidateHealthHappy(c *C) {
+	info, err := InfoFromSnapYaml([]byte(`name: foo
+version: 1.0
+apps:
+  svc:
+    daemon: simple
+hooks:
+  check-health:
+health:
+  interval: 5m
+  restart-services-after: 3
+`))
+	c.Assert(err, IsNil)
+	c.Check(Validate(info), IsNil)
+}
+
+func (s *ValidateSuite) TestValidateHealthErrors(c *C) {
+	for _, tc := range []struct {
+		yaml string
+		err  string
+	}{{
+		yaml: "health:\n  interval: 5m\n",
+		err:  `cannot specify "health" without a "check-health" hook`,
+	}, {
+		yaml: "hooks:\n  check-health:\nhealth:\n  interval: 30s\n",
+		err:  `health interval must be at least 1m0s, not 30s`,
+	}, {
+		yaml: "hooks:\n  check-health:\nhealth:\n  restart-services-after: 2\n",
+		err:  `health interval must be at least 1m0s, not 0s`,
+	}, {
+		yaml: "hooks:\n  check-health:\nhealth:\n  interval: 1m\n  restart-services-after: -1\n",
+		err:  `health restart-services-after cannot be negative`,
+	}, {
+		yaml: "hooks:\n  check-health:\nhealth:\n  interval: 1m\n  restart-services-after: 2\n",
+		err:  `cannot specify health restart-services-after for a snap without services`,
+	}} {
+		info, err := InfoFromSnapYaml([]byte("name: foo\nversion: 1.0\n" + tc.yaml))
+		c.Assert(err, IsNil)
+		c.Check(Validate(info), ErrorMatches, tc.err, Commentf(tc.yaml))
+	}
+}
+
 func (s *ValidateSuite) TestPlugSlotNamesUnique(c *C) {
 	info, err := InfoFromSnapYaml([]byte(`name: snap
 version: 0
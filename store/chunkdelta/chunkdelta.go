@@ -0,0 +1,305 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package chunkdelta implements a block level delta format for snaps that
+// does not need any external tool to be applied.
+//
+// Much like zsync, the source snap is split in fixed size blocks which are
+// indexed by a rolling checksum, and the target snap is described as a
+// sequence of copies of source ranges and of literal data not found in the
+// source. Squashfs images keep unchanged files in long identical runs, which
+// makes this work well for large kernel and gadget snaps.
+//
+// A delta is made of a header followed by operations:
+//
+//	header: "SNAPCHK1" | block size (uint32 BE) | target size (uint64 BE)
+//	copy:   'C' | source offset (uvarint) | length (uvarint)
+//	data:   'D' | length (uvarint) | bytes
+//	end:    'E'
+package chunkdelta
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Format is the name of the delta format as negotiated with the store.
+const Format = "snap-chunks"
+
+// DefaultBlockSize is the block size used by Generate when none is given.
+const DefaultBlockSize = 64 * 1024
+
+const (
+	magic = "SNAPCHK1"
+
+	opCopy = 'C'
+	opData = 'D'
+	opEnd  = 'E'
+
+	// maxBlockSize bounds the block size accepted from a delta header.
+	maxBlockSize = 16 * 1024 * 1024
+)
+
+// Apply rebuilds the target snap at targetPath from the source snap at
+// sourcePath and the delta at deltaPath. On error, the partially written
+// target is removed.
+func Apply(sourcePath, deltaPath, targetPath string) (err error) {
+	src, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	df, err := os.Open(deltaPath)
+	if err != nil {
+		return err
+	}
+	defer df.Close()
+
+	out, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(targetPath)
+		}
+	}()
+
+	w := bufio.NewWriter(out)
+	if err := apply(w, src, fi.Size(), bufio.NewReader(df)); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func apply(w io.Writer, src io.ReaderAt, srcSize int64, r *bufio.Reader) error {
+	var hdr [len(magic) + 4 + 8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return fmt.Errorf("cannot read delta header: %v", err)
+	}
+	if string(hdr[:len(magic)]) != magic {
+		return errors.New("cannot apply delta: not a snap-chunks delta")
+	}
+	blockSize := binary.BigEndian.Uint32(hdr[len(magic):])
+	if blockSize == 0 || blockSize > maxBlockSize {
+		return fmt.Errorf("cannot apply delta: invalid block size %d", blockSize)
+	}
+	targetSize := binary.BigEndian.Uint64(hdr[len(magic)+4:])
+
+	var written uint64
+	for {
+		op, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("cannot read delta operation: %v", err)
+		}
+		var n int64
+		switch op {
+		case opCopy:
+			off, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("cannot read delta copy offset: %v", err)
+			}
+			length, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("cannot read delta copy length: %v", err)
+			}
+			if off > uint64(srcSize) || length > uint64(srcSize)-off {
+				return fmt.Errorf("cannot apply delta: copy of %d bytes at offset %d is outside of the source (%d bytes)", length, off, srcSize)
+			}
+			if length > targetSize-written {
+				return fmt.Errorf("cannot apply delta: copy of %d bytes exceeds the target size", length)
+			}
+			n, err = io.Copy(w, io.NewSectionReader(src, int64(off), int64(length)))
+			if err != nil {
+				return err
+			}
+		case opData:
+			length, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("cannot read delta data length: %v", err)
+			}
+			if length > targetSize-written {
+				return fmt.Errorf("cannot apply delta: data of %d bytes exceeds the target size", length)
+			}
+			n, err = io.CopyN(w, r, int64(length))
+			if err != nil {
+				return fmt.Errorf("cannot read delta data: %v", err)
+			}
+		case opEnd:
+			if written != targetSize {
+				return fmt.Errorf("cannot apply delta: rebuilt %d bytes but expected %d", written, targetSize)
+			}
+			return nil
+		default:
+			return fmt.Errorf("cannot apply delta: unknown operation %q", op)
+		}
+		written += uint64(n)
+	}
+}
+
+// Generate writes to w a delta that rebuilds target from source. Both are
+// read into memory. If blockSize is 0, DefaultBlockSize is used.
+func Generate(w io.Writer, source, target io.Reader, blockSize int) error {
+	if blockSize == 0 {
+		blockSize = DefaultBlockSize
+	}
+	if blockSize < 0 || blockSize > maxBlockSize {
+		return fmt.Errorf("cannot generate delta: invalid block size %d", blockSize)
+	}
+	src, err := io.ReadAll(source)
+	if err != nil {
+		return err
+	}
+	tgt, err := io.ReadAll(target)
+	if err != nil {
+		return err
+	}
+
+	e := &encoder{w: bufio.NewWriter(w)}
+	var hdr [len(magic) + 4 + 8]byte
+	copy(hdr[:], magic)
+	binary.BigEndian.PutUint32(hdr[len(magic):], uint32(blockSize))
+	binary.BigEndian.PutUint64(hdr[len(magic)+4:], uint64(len(tgt)))
+	e.w.Write(hdr[:])
+
+	index := make(map[uint32][]int)
+	strong := make([][sha256.Size]byte, len(src)/blockSize)
+	for blk := range strong {
+		block := src[blk*blockSize : (blk+1)*blockSize]
+		weak := newRollingSum(block).sum()
+		index[weak] = append(index[weak], blk)
+		strong[blk] = sha256.Sum256(block)
+	}
+
+	lit := 0
+	i := 0
+	var rs rollingSum
+	if len(tgt) >= blockSize {
+		rs = newRollingSum(tgt[:blockSize])
+	}
+	for i+blockSize <= len(tgt) {
+		if blocks, ok := index[rs.sum()]; ok {
+			match := -1
+			sum := sha256.Sum256(tgt[i : i+blockSize])
+			for _, blk := range blocks {
+				if strong[blk] == sum {
+					match = blk
+					break
+				}
+			}
+			if match >= 0 {
+				e.data(tgt[lit:i])
+				e.copy(uint64(match*blockSize), uint64(blockSize))
+				i += blockSize
+				lit = i
+				if i+blockSize <= len(tgt) {
+					rs = newRollingSum(tgt[i : i+blockSize])
+				}
+				continue
+			}
+		}
+		if i+blockSize < len(tgt) {
+			rs.roll(tgt[i], tgt[i+blockSize])
+		}
+		i++
+	}
+	e.data(tgt[lit:])
+	e.flushCopy()
+	e.w.WriteByte(opEnd)
+
+	return e.w.Flush()
+}
+
+type encoder struct {
+	w *bufio.Writer
+
+	// pending copy, merged with the following one when contiguous
+	copyOff, copyLen uint64
+}
+
+func (e *encoder) putUvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	e.w.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func (e *encoder) flushCopy() {
+	if e.copyLen == 0 {
+		return
+	}
+	e.w.WriteByte(opCopy)
+	e.putUvarint(e.copyOff)
+	e.putUvarint(e.copyLen)
+	e.copyLen = 0
+}
+
+func (e *encoder) copy(off, length uint64) {
+	if e.copyLen > 0 && e.copyOff+e.copyLen == off {
+		e.copyLen += length
+		return
+	}
+	e.flushCopy()
+	e.copyOff, e.copyLen = off, length
+}
+
+func (e *encoder) data(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.flushCopy()
+	e.w.WriteByte(opData)
+	e.putUvarint(uint64(len(b)))
+	e.w.Write(b)
+}
+
+// rollingSum is the rsync weak checksum of a window of bytes.
+type rollingSum struct {
+	a, b uint32
+	n    uint32
+}
+
+func newRollingSum(window []byte) rollingSum {
+	rs := rollingSum{n: uint32(len(window))}
+	for i, c := range window {
+		rs.a += uint32(c)
+		rs.b += uint32(len(window)-i) * uint32(c)
+	}
+	return rs
+}
+
+func (rs *rollingSum) roll(out, in byte) {
+	rs.a += uint32(in) - uint32(out)
+	rs.b += rs.a - rs.n*uint32(out)
+}
+
+func (rs rollingSum) sum() uint32 {
+	return (rs.b&0xffff)<<16 | rs.a&0xffff
+}
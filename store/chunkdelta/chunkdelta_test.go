@@ -0,0 +1,159 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package chunkdelta_test
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/store/chunkdelta"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type chunkDeltaSuite struct {
+	dir string
+}
+
+var _ = Suite(&chunkDeltaSuite{})
+
+func (s *chunkDeltaSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+}
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	r.Read(b)
+	return b
+}
+
+func (s *chunkDeltaSuite) roundTrip(c *C, source, target []byte, blockSize int) (deltaSize int) {
+	var delta bytes.Buffer
+	err := chunkdelta.Generate(&delta, bytes.NewReader(source), bytes.NewReader(target), blockSize)
+	c.Assert(err, IsNil)
+
+	sourcePath := filepath.Join(s.dir, "source.snap")
+	deltaPath := filepath.Join(s.dir, "the.delta")
+	targetPath := filepath.Join(s.dir, "target.snap")
+	c.Assert(os.WriteFile(sourcePath, source, 0644), IsNil)
+	c.Assert(os.WriteFile(deltaPath, delta.Bytes(), 0644), IsNil)
+
+	c.Assert(chunkdelta.Apply(sourcePath, deltaPath, targetPath), IsNil)
+	c.Check(targetPath, testutil.FileEquals, target)
+	return delta.Len()
+}
+
+func (s *chunkDeltaSuite) TestRoundTrip(c *C) {
+	r := rand.New(rand.NewSource(42))
+	source := randomBytes(r, 64*1024)
+
+	// change a few bytes in the middle, insert some data and drop the tail
+	target := append([]byte(nil), source[:10000]...)
+	target = append(target, randomBytes(r, 123)...)
+	target = append(target, source[10000:30000]...)
+	target = append(target, 'x')
+	target = append(target, source[30001:60000]...)
+
+	deltaSize := s.roundTrip(c, source, target, 1024)
+	// most of the target is copied from the source
+	c.Check(deltaSize < 4*1024, Equals, true, Commentf("delta is %d bytes", deltaSize))
+}
+
+func (s *chunkDeltaSuite) TestRoundTripEdgeCases(c *C) {
+	r := rand.New(rand.NewSource(1))
+	data := randomBytes(r, 5000)
+
+	for _, t := range []struct {
+		source, target []byte
+	}{
+		{nil, nil},
+		{data, nil},
+		{nil, data},
+		{data, data},
+		// shorter than a block
+		{data[:10], data[:20]},
+		{data, data[100:4000]},
+		// blocks reordered
+		{data, append(append([]byte(nil), data[2048:4096]...), data[:2048]...)},
+	} {
+		s.roundTrip(c, t.source, t.target, 512)
+	}
+}
+
+func (s *chunkDeltaSuite) TestGenerateInvalidBlockSize(c *C) {
+	var delta bytes.Buffer
+	err := chunkdelta.Generate(&delta, bytes.NewReader(nil), bytes.NewReader(nil), -1)
+	c.Check(err, ErrorMatches, "cannot generate delta: invalid block size -1")
+}
+
+func (s *chunkDeltaSuite) TestApplyErrors(c *C) {
+	sourcePath := filepath.Join(s.dir, "source.snap")
+	c.Assert(os.WriteFile(sourcePath, []byte("0123456789"), 0644), IsNil)
+
+	header := func(blockSize, targetSize byte) []byte {
+		return []byte{'S', 'N', 'A', 'P', 'C', 'H', 'K', '1', 0, 0, 0, blockSize, 0, 0, 0, 0, 0, 0, 0, targetSize}
+	}
+	with := func(b []byte, ops ...byte) []byte {
+		return append(append([]byte(nil), b...), ops...)
+	}
+
+	for _, t := range []struct {
+		delta []byte
+		err   string
+	}{
+		{[]byte("short"), "cannot read delta header: unexpected EOF"},
+		{[]byte("NOTADELTA-AT-ALL-NOPE"), "cannot apply delta: not a snap-chunks delta"},
+		{header(0, 4), "cannot apply delta: invalid block size 0"},
+		{header(4, 4), "cannot read delta operation: EOF"},
+		{with(header(4, 4), 'X'), `cannot apply delta: unknown operation 'X'`},
+		{with(header(4, 4), 'C', 8, 4, 'E'), `cannot apply delta: copy of 4 bytes at offset 8 is outside of the source \(10 bytes\)`},
+		{with(header(4, 4), 'C', 0, 8, 'E'), `cannot apply delta: copy of 8 bytes exceeds the target size`},
+		{with(header(4, 4), 'D', 8, 'a'), `cannot apply delta: data of 8 bytes exceeds the target size`},
+		{with(header(4, 4), 'D', 4, 'a'), `cannot read delta data: EOF`},
+		{with(header(4, 4), 'C', 0, 2, 'E'), `cannot apply delta: rebuilt 2 bytes but expected 4`},
+	} {
+		deltaPath := filepath.Join(s.dir, "the.delta")
+		targetPath := filepath.Join(s.dir, "target.snap")
+		c.Assert(os.WriteFile(deltaPath, t.delta, 0644), IsNil)
+
+		err := chunkdelta.Apply(sourcePath, deltaPath, targetPath)
+		c.Check(err, ErrorMatches, t.err)
+		c.Check(targetPath, testutil.FileAbsent)
+	}
+}
+
+func (s *chunkDeltaSuite) TestApplyValid(c *C) {
+	sourcePath := filepath.Join(s.dir, "source.snap")
+	deltaPath := filepath.Join(s.dir, "the.delta")
+	targetPath := filepath.Join(s.dir, "target.snap")
+	c.Assert(os.WriteFile(sourcePath, []byte("0123456789"), 0644), IsNil)
+	delta := []byte{'S', 'N', 'A', 'P', 'C', 'H', 'K', '1', 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0, 7,
+		'C', 6, 4, 'D', 3, 'a', 'b', 'c', 'E'}
+	c.Assert(os.WriteFile(deltaPath, delta, 0644), IsNil)
+
+	c.Assert(chunkdelta.Apply(sourcePath, deltaPath, targetPath), IsNil)
+	c.Check(targetPath, testutil.FileEquals, "6789abc")
+}
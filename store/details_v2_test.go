@@ -461,6 +461,7 @@ func (s *detailsV2Suite) TestInfoFromStoreSnap(c *C) {
 		"Layout",
 		"SideInfo.Channel",
 		"LegacyWebsite",
+		"Health", // only from snap.yaml
 	}
 	var checker func(string, reflect.Value)
 	checker = func(pfx string, x reflect.Value) {
@@ -31,6 +31,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -407,34 +408,34 @@ func (s *downloadSuite) TestUseDeltas(c *C) {
 		exeInHost bool
 		exeInCore bool
 
-		wantDelta bool
+		wantXdelta3 bool
 	}{
-		{env: "", classic: false, exeInHost: false, exeInCore: false, wantDelta: false},
-		{env: "", classic: false, exeInHost: false, exeInCore: true, wantDelta: true},
-		{env: "", classic: false, exeInHost: true, exeInCore: false, wantDelta: true},
-		{env: "", classic: false, exeInHost: true, exeInCore: true, wantDelta: true},
-		{env: "", classic: true, exeInHost: false, exeInCore: false, wantDelta: false},
-		{env: "", classic: true, exeInHost: false, exeInCore: true, wantDelta: true},
-		{env: "", classic: true, exeInHost: true, exeInCore: false, wantDelta: true},
-		{env: "", classic: true, exeInHost: true, exeInCore: true, wantDelta: true},
-
-		{env: "0", classic: false, exeInHost: false, exeInCore: false, wantDelta: false},
-		{env: "0", classic: false, exeInHost: false, exeInCore: true, wantDelta: false},
-		{env: "0", classic: false, exeInHost: true, exeInCore: false, wantDelta: false},
-		{env: "0", classic: false, exeInHost: true, exeInCore: true, wantDelta: false},
-		{env: "0", classic: true, exeInHost: false, exeInCore: false, wantDelta: false},
-		{env: "0", classic: true, exeInHost: false, exeInCore: true, wantDelta: false},
-		{env: "0", classic: true, exeInHost: true, exeInCore: false, wantDelta: false},
-		{env: "0", classic: true, exeInHost: true, exeInCore: true, wantDelta: false},
-
-		{env: "1", classic: false, exeInHost: false, exeInCore: false, wantDelta: false},
-		{env: "1", classic: false, exeInHost: false, exeInCore: true, wantDelta: true},
-		{env: "1", classic: false, exeInHost: true, exeInCore: false, wantDelta: true},
-		{env: "1", classic: false, exeInHost: true, exeInCore: true, wantDelta: true},
-		{env: "1", classic: true, exeInHost: false, exeInCore: false, wantDelta: false},
-		{env: "1", classic: true, exeInHost: false, exeInCore: true, wantDelta: true},
-		{env: "1", classic: true, exeInHost: true, exeInCore: false, wantDelta: true},
-		{env: "1", classic: true, exeInHost: true, exeInCore: true, wantDelta: true},
+		{env: "", classic: false, exeInHost: false, exeInCore: false, wantXdelta3: false},
+		{env: "", classic: false, exeInHost: false, exeInCore: true, wantXdelta3: true},
+		{env: "", classic: false, exeInHost: true, exeInCore: false, wantXdelta3: true},
+		{env: "", classic: false, exeInHost: true, exeInCore: true, wantXdelta3: true},
+		{env: "", classic: true, exeInHost: false, exeInCore: false, wantXdelta3: false},
+		{env: "", classic: true, exeInHost: false, exeInCore: true, wantXdelta3: true},
+		{env: "", classic: true, exeInHost: true, exeInCore: false, wantXdelta3: true},
+		{env: "", classic: true, exeInHost: true, exeInCore: true, wantXdelta3: true},
+
+		{env: "0", classic: false, exeInHost: false, exeInCore: false, wantXdelta3: false},
+		{env: "0", classic: false, exeInHost: false, exeInCore: true, wantXdelta3: false},
+		{env: "0", classic: false, exeInHost: true, exeInCore: false, wantXdelta3: false},
+		{env: "0", classic: false, exeInHost: true, exeInCore: true, wantXdelta3: false},
+		{env: "0", classic: true, exeInHost: false, exeInCore: false, wantXdelta3: false},
+		{env: "0", classic: true, exeInHost: false, exeInCore: true, wantXdelta3: false},
+		{env: "0", classic: true, exeInHost: true, exeInCore: false, wantXdelta3: false},
+		{env: "0", classic: true, exeInHost: true, exeInCore: true, wantXdelta3: false},
+
+		{env: "1", classic: false, exeInHost: false, exeInCore: false, wantXdelta3: false},
+		{env: "1", classic: false, exeInHost: false, exeInCore: true, wantXdelta3: true},
+		{env: "1", classic: false, exeInHost: true, exeInCore: false, wantXdelta3: true},
+		{env: "1", classic: false, exeInHost: true, exeInCore: true, wantXdelta3: true},
+		{env: "1", classic: true, exeInHost: false, exeInCore: false, wantXdelta3: false},
+		{env: "1", classic: true, exeInHost: false, exeInCore: true, wantXdelta3: true},
+		{env: "1", classic: true, exeInHost: true, exeInCore: false, wantXdelta3: true},
+		{env: "1", classic: true, exeInHost: true, exeInCore: true, wantXdelta3: true},
 	}
 
 	for _, scenario := range scenarios {
@@ -520,9 +521,29 @@ func (s *downloadSuite) TestUseDeltas(c *C) {
 			})
 		}
 
+		// when restricted to xdelta3, deltas are only used if xdelta3 works
+		xdelta3Sto := store.New(&store.Config{DeltaFormat: "xdelta3"}, nil)
+		c.Check(xdelta3Sto.UseDeltas(), Equals, scenario.wantXdelta3, comment)
+		if scenario.wantXdelta3 {
+			c.Check(xdelta3Sto.AcceptedDeltaFormats(), DeepEquals, []string{"xdelta3"}, comment)
+		} else {
+			c.Check(xdelta3Sto.AcceptedDeltaFormats(), HasLen, 0, comment)
+		}
+		// forget the calls of that check, the caching is verified below
+		if scenario.exeInCore {
+			coreInterpCmd.ForgetCalls()
+		}
+		if scenario.exeInHost {
+			hostXdelta3Cmd.ForgetCalls()
+		}
+
+		// snap-chunks deltas are only used when explicitly enabled by the
+		// environment
+		wantDeltas := scenario.env == "1" || scenario.wantXdelta3
+
 		// run the check for delta usage, we call it twice
 		sto := &store.Store{}
-		c.Check(sto.UseDeltas(), Equals, scenario.wantDelta, comment)
+		c.Check(sto.UseDeltas(), Equals, wantDeltas, comment)
 
 		// cleanup the files we may have created before calling the function
 		// again to ensure that the caching works as expected
@@ -539,9 +560,20 @@ func (s *downloadSuite) TestUseDeltas(c *C) {
 		// search path, we should still get the same result as above when
 		// we call UseDeltas() since it was cached, if it wasn't cached then
 		// this would fail
-		c.Check(sto.UseDeltas(), Equals, scenario.wantDelta, comment)
+		c.Check(sto.UseDeltas(), Equals, wantDeltas, comment)
+
+		switch {
+		case scenario.env == "1" && scenario.wantXdelta3:
+			c.Check(sto.AcceptedDeltaFormats(), DeepEquals, []string{"xdelta3", "snap-chunks"}, comment)
+		case scenario.env == "1":
+			c.Check(sto.AcceptedDeltaFormats(), DeepEquals, []string{"snap-chunks"}, comment)
+		case scenario.wantXdelta3:
+			c.Check(sto.AcceptedDeltaFormats(), DeepEquals, []string{"xdelta3"}, comment)
+		default:
+			c.Check(sto.AcceptedDeltaFormats(), HasLen, 0, comment)
+		}
 
-		if scenario.wantDelta {
+		if scenario.wantXdelta3 {
 			// if we should have been able to use deltas, make sure we picked
 			// the expected one, - if both were true we should have picked the
 			// one from core instead of the one from the host first
@@ -580,8 +612,8 @@ func (s *downloadSuite) TestUseDeltas(c *C) {
 			}
 		} else {
 			// quick check that the test case makes sense, if we didn't want
-			// deltas, the scenario should have either disabled via an env var,
-			// or had both exes missing
+			// xdelta3, the scenario should have either disabled deltas via an
+			// env var, or had both exes missing
 			c.Assert((scenario.env == "0") ||
 				(!scenario.exeInCore && !scenario.exeInHost),
 				Equals, true)
@@ -705,6 +737,109 @@ func (s *downloadSuite) TestActualDownloadRateLimited(c *C) {
 	c.Check(ratelimitReaderUsed, Equals, true)
 }
 
+func (s *downloadSuite) TestActualDownloadRateLimitShared(c *C) {
+	bucket, restore := store.MockDownloadBandwidth()
+	defer restore()
+
+	var buckets []*ratelimit.Bucket
+	restore = store.MockRatelimitReader(func(r io.Reader, bucket *ratelimit.Bucket) io.Reader {
+		buckets = append(buckets, bucket)
+		return r
+	})
+	defer restore()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "downloaded data")
+	}))
+	defer ts.Close()
+
+	theStore := store.New(&store.Config{}, nil)
+	for i := 0; i < 2; i++ {
+		var buf SillyBuffer
+		err := store.Download(context.TODO(), "example-name", "", ts.URL, nil, theStore, &buf, 0, nil, &store.DownloadOptions{RateLimit: 1000})
+		c.Assert(err, IsNil)
+	}
+
+	// both downloads used the same bucket
+	c.Assert(buckets, HasLen, 2)
+	c.Check(buckets[0], Equals, buckets[1])
+	c.Check(buckets[0], Equals, bucket(1000))
+}
+
+func (s *downloadSuite) TestActualDownloadRateLimitSchedule(c *C) {
+	bucket, restore := store.MockDownloadBandwidth()
+	defer restore()
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	restore = store.MockRateLimitTimeNow(func() time.Time { return now })
+	defer restore()
+
+	content := strings.Repeat("x", 1000)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, content)
+	}))
+	defer ts.Close()
+
+	sched, err := store.ParseRateLimitSchedule("08:00-18:00/1KB,18:00-08:00/2KB")
+	c.Assert(err, IsNil)
+
+	theStore := store.New(&store.Config{}, nil)
+	var buf SillyBuffer
+	err = store.Download(context.TODO(), "example-name", "", ts.URL, nil, theStore, &buf, 0, nil, &store.DownloadOptions{
+		RateLimit:         1,
+		RateLimitSchedule: sched,
+	})
+	c.Assert(err, IsNil)
+	c.Check(buf.String(), Equals, content)
+
+	// the day time budget was used, not the night time one nor the fixed
+	// rate which is overridden by the schedule
+	c.Check(bucket(1000).Available() < 2000, Equals, true)
+	c.Check(bucket(2000).Available(), Equals, int64(4000))
+	c.Check(bucket(1).Available(), Equals, int64(2))
+}
+
+func (s *downloadSuite) TestParseRateLimitSchedule(c *C) {
+	sched, err := store.ParseRateLimitSchedule("08:00-18:00/512K, 18:00-22:00/unlimited,22:00-08:00/1MB")
+	c.Assert(err, IsNil)
+	c.Check(sched.String(), Equals, "08:00-18:00/512000B,18:00-22:00/unlimited,22:00-08:00/1000000B")
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 1, hour, minute, 0, 0, time.Local)
+	}
+	c.Check(sched.RateAt(at(7, 59)), Equals, int64(1000*1000))
+	c.Check(sched.RateAt(at(8, 0)), Equals, int64(512*1000))
+	c.Check(sched.RateAt(at(17, 59)), Equals, int64(512*1000))
+	c.Check(sched.RateAt(at(18, 0)), Equals, int64(0))
+	c.Check(sched.RateAt(at(23, 30)), Equals, int64(1000*1000))
+	c.Check(sched.RateAt(at(0, 0)), Equals, int64(1000*1000))
+
+	// times outside of the windows are not limited
+	sched, err = store.ParseRateLimitSchedule("09:00-17:00/1MB")
+	c.Assert(err, IsNil)
+	c.Check(sched.RateAt(at(8, 0)), Equals, int64(0))
+	c.Check(sched.RateAt(at(9, 0)), Equals, int64(1000*1000))
+
+	sched, err = store.ParseRateLimitSchedule("00:00-24:00/1MB")
+	c.Assert(err, IsNil)
+	c.Check(sched.RateAt(at(0, 0)), Equals, int64(1000*1000))
+	c.Check(sched.RateAt(at(23, 59)), Equals, int64(1000*1000))
+
+	for _, t := range []struct {
+		spec, err string
+	}{
+		{"512K", `cannot parse rate limit window "512K": missing rate`},
+		{"08:00/512K", `cannot parse rate limit window "08:00/512K": expected <start>-<end>`},
+		{"08:00-25:00/512K", `cannot parse rate limit window "08:00-25:00/512K": cannot parse "25:00"`},
+		{"08:00-08:00/512K", `cannot parse rate limit window "08:00-08:00/512K": window is empty`},
+		{"08:00-18:00/-1KB", `cannot parse rate limit window "08:00-18:00/-1KB": cannot parse "-1KB": size cannot be negative`},
+		{"20:00-08:00/1MB,07:00-09:00/2MB", `cannot use rate limit window "07:00-09:00/2MB": overlaps with "20:00-08:00/1MB"`},
+	} {
+		_, err := store.ParseRateLimitSchedule(t.spec)
+		c.Check(err, ErrorMatches, t.err, Commentf(t.spec))
+	}
+}
+
 func (s *downloadSuite) TestActualDownloadIcon(c *C) {
 	n := 0
 	const existingEtag = ""
@@ -196,10 +196,16 @@ func MockHttputilNewHTTPClient(f func(opts *httputil.ClientOptions) *http.Client
 
 func (sto *Store) SetDeltaFormat(dfmt string) {
 	sto.deltaFormat = dfmt
+	// negotiate the delta formats again
+	sto.shouldUseDeltas = nil
 }
 
 func (sto *Store) DownloadDelta(deltaName string, downloadInfo *snap.DownloadInfo, w io.ReadWriteSeeker, pbar progress.Meter, user *auth.UserState, dlOpts *DownloadOptions) error {
-	return sto.downloadDelta(deltaName, downloadInfo, w, pbar, user, dlOpts)
+	deltaInfo, err := sto.pickDelta(downloadInfo)
+	if err != nil {
+		return err
+	}
+	return sto.downloadDelta(deltaName, deltaInfo, w, pbar, user, dlOpts)
 }
 
 func (sto *Store) DoRequest(ctx context.Context, client *http.Client, reqOptions *requestOptions, user *auth.UserState) (*http.Response, error) {
@@ -234,6 +240,10 @@ func (sto *Store) UseDeltas() bool {
 	return sto.useDeltas()
 }
 
+func (sto *Store) AcceptedDeltaFormats() []string {
+	return sto.acceptedDeltaFormats()
+}
+
 func (sto *Store) Xdelta3Cmd(args ...string) *exec.Cmd {
 	return sto.xdelta3CmdFunc(args...)
 }
@@ -275,3 +285,21 @@ type (
 )
 
 var ReportFetchAssertionsError = reportFetchAssertionsError
+
+func MockRateLimitTimeNow(f func() time.Time) (restore func()) {
+	old := rateLimitTimeNow
+	rateLimitTimeNow = f
+	return func() {
+		rateLimitTimeNow = old
+	}
+}
+
+// MockDownloadBandwidth resets the bandwidth shared by downloads and
+// returns a function to get the bucket used for a given rate.
+func MockDownloadBandwidth() (bucket func(rate int64) *ratelimit.Bucket, restore func()) {
+	old := downloadBandwidth
+	downloadBandwidth = &bandwidth{}
+	return downloadBandwidth.bucket, func() {
+		downloadBandwidth = old
+	}
+}
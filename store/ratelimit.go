@@ -0,0 +1,223 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/ratelimit"
+
+	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/timeutil"
+)
+
+const minutesInDay = 24 * 60
+
+// RateLimitWindow is a download rate limit applying between two times of
+// the day, the window wraps around midnight if End is before Start.
+type RateLimitWindow struct {
+	Start timeutil.Clock
+	End   timeutil.Clock
+	// Rate is in bytes per second, 0 means unlimited.
+	Rate int64
+}
+
+func (w *RateLimitWindow) String() string {
+	rate := "unlimited"
+	if w.Rate > 0 {
+		rate = fmt.Sprintf("%dB", w.Rate)
+	}
+	return fmt.Sprintf("%s-%s/%s", w.Start, w.End, rate)
+}
+
+func clockMinutes(c timeutil.Clock) int {
+	return c.Hour*60 + c.Minute
+}
+
+// includes returns whether the given minute of the day falls in the window.
+func (w *RateLimitWindow) includes(minute int) bool {
+	start, end := clockMinutes(w.Start), clockMinutes(w.End)
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// RateLimitSchedule is a download rate limit that depends on the time of
+// the day. Downloads are not limited outside of its windows.
+type RateLimitSchedule struct {
+	Windows []*RateLimitWindow
+}
+
+func (s *RateLimitSchedule) String() string {
+	l := make([]string, len(s.Windows))
+	for i, w := range s.Windows {
+		l[i] = w.String()
+	}
+	return strings.Join(l, ",")
+}
+
+// RateAt returns the rate limit in bytes per second at the given time, 0
+// meaning unlimited.
+func (s *RateLimitSchedule) RateAt(t time.Time) int64 {
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range s.Windows {
+		if w.includes(minute) {
+			return w.Rate
+		}
+	}
+	return 0
+}
+
+func parseRate(s string) (int64, error) {
+	if s == "unlimited" {
+		return 0, nil
+	}
+	// accept the 512K shorthand for 512KB
+	if upper := strings.ToUpper(s); strings.HasSuffix(upper, "K") || strings.HasSuffix(upper, "M") || strings.HasSuffix(upper, "G") {
+		s += "B"
+	}
+	return strutil.ParseByteSize(s)
+}
+
+// ParseRateLimitSchedule parses a comma separated list of
+// <start>-<end>/<rate> windows, such as
+// "08:00-18:00/512K,18:00-08:00/unlimited". Windows cannot overlap.
+func ParseRateLimitSchedule(spec string) (*RateLimitSchedule, error) {
+	var sched RateLimitSchedule
+	// the entry covering each minute of the day
+	var covered [minutesInDay]string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		span, rateStr, ok := strings.Cut(entry, "/")
+		if !ok {
+			return nil, fmt.Errorf("cannot parse rate limit window %q: missing rate", entry)
+		}
+		startStr, endStr, ok := strings.Cut(span, "-")
+		if !ok {
+			return nil, fmt.Errorf("cannot parse rate limit window %q: expected <start>-<end>", entry)
+		}
+		start, err := timeutil.ParseClock(startStr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse rate limit window %q: %v", entry, err)
+		}
+		end, err := timeutil.ParseClock(endStr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse rate limit window %q: %v", entry, err)
+		}
+		if clockMinutes(start) == clockMinutes(end) {
+			return nil, fmt.Errorf("cannot parse rate limit window %q: window is empty", entry)
+		}
+		rate, err := parseRate(rateStr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse rate limit window %q: %v", entry, err)
+		}
+
+		w := &RateLimitWindow{Start: start, End: end, Rate: rate}
+		for minute := 0; minute < minutesInDay; minute++ {
+			if !w.includes(minute) {
+				continue
+			}
+			if other := covered[minute]; other != "" {
+				return nil, fmt.Errorf("cannot use rate limit window %q: overlaps with %q", entry, other)
+			}
+			covered[minute] = entry
+		}
+		sched.Windows = append(sched.Windows, w)
+	}
+	return &sched, nil
+}
+
+// bandwidth shares the download bandwidth between all the rate limited
+// downloads in progress, so that together they stay within the limit.
+type bandwidth struct {
+	mu      sync.Mutex
+	buckets map[int64]*ratelimit.Bucket
+}
+
+var downloadBandwidth = &bandwidth{}
+
+// bucket returns the token bucket shared by the downloads limited to the
+// given rate.
+func (b *bandwidth) bucket(rate int64) *ratelimit.Bucket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buckets == nil {
+		b.buckets = make(map[int64]*ratelimit.Bucket)
+	}
+	bucket := b.buckets[rate]
+	if bucket == nil {
+		bucket = ratelimit.NewBucketWithRate(float64(rate), 2*rate)
+		b.buckets[rate] = bucket
+	}
+	return bucket
+}
+
+var rateLimitTimeNow = time.Now
+
+// scheduledReader limits the rate of reads from r according to a schedule,
+// which is looked at on every read so that long downloads follow it.
+type scheduledReader struct {
+	r     io.Reader
+	sched *RateLimitSchedule
+}
+
+func (sr *scheduledReader) Read(p []byte) (int, error) {
+	n, err := sr.r.Read(p)
+	if n > 0 {
+		if rate := sr.sched.RateAt(rateLimitTimeNow()); rate > 0 {
+			downloadBandwidth.bucket(rate).Wait(int64(n))
+		}
+	}
+	return n, err
+}
+
+// rateLimited wraps r so that it is read no faster than allowed by the
+// download options.
+func rateLimited(r io.Reader, dlOpts *DownloadOptions) io.Reader {
+	switch {
+	case dlOpts == nil:
+		return r
+	case dlOpts.RateLimitSchedule != nil:
+		return &scheduledReader{r: r, sched: dlOpts.RateLimitSchedule}
+	case dlOpts.RateLimit > 0:
+		return ratelimitReader(r, downloadBandwidth.bucket(dlOpts.RateLimit))
+	}
+	return r
+}
+
+// RateLimitedStream wraps a download stream so that it is read no faster
+// than allowed by the download options, sharing the bandwidth with the
+// other rate limited downloads.
+func RateLimitedStream(stream io.ReadCloser, dlOpts *DownloadOptions) io.ReadCloser {
+	r := rateLimited(stream, dlOpts)
+	if r == io.Reader(stream) {
+		return stream
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, stream}
+}
@@ -106,7 +106,11 @@ type Config struct {
 	DetailFields []string
 	InfoFields   []string
 	// search v2 fields
-	FindFields  []string
+	FindFields []string
+	// DeltaFormat restricts deltas to the given format. By default xdelta3
+	// deltas are accepted when xdelta3 is available, while the experimental
+	// snap-chunks deltas need to be asked for either with this option or
+	// with SNAPD_USE_DELTAS_EXPERIMENTAL.
 	DeltaFormat string
 
 	// CacheDownloads is the number of downloads that should be cached
@@ -174,6 +178,8 @@ type Store struct {
 	xdeltaCheckLock sync.Mutex
 	// whether we should use deltas or not
 	shouldUseDeltas *bool
+	// the delta formats we can apply, in order of preference
+	deltaFormats []string
 	// which xdelta3 we picked when we checked the deltas
 	xdelta3CmdFunc func(args ...string) *exec.Cmd
 }
@@ -355,9 +361,6 @@ type categoryResults struct {
 	Categories []CategoryDetails `json:"categories"`
 }
 
-// The default delta format if not configured.
-var defaultSupportedDeltaFormat = "xdelta3"
-
 // New creates a new Store with the given access configuration and for given the store id.
 func New(cfg *Config, dauthCtx DeviceAndAuthContext) *Store {
 	if cfg == nil {
@@ -389,11 +392,6 @@ func New(cfg *Config, dauthCtx DeviceAndAuthContext) *Store {
 		series = release.Series
 	}
 
-	deltaFormat := cfg.DeltaFormat
-	if deltaFormat == "" {
-		deltaFormat = defaultSupportedDeltaFormat
-	}
-
 	userAgent := snapdenv.UserAgent()
 	proxyConnectHeader := http.Header{"User-Agent": []string{userAgent}}
 
@@ -407,7 +405,7 @@ func New(cfg *Config, dauthCtx DeviceAndAuthContext) *Store {
 		infoFields:         infoFields,
 		findFields:         findFields,
 		dauthCtx:           dauthCtx,
-		deltaFormat:        deltaFormat,
+		deltaFormat:        cfg.DeltaFormat,
 		proxy:              cfg.Proxy,
 		proxyConnectHeader: proxyConnectHeader,
 		userAgent:          userAgent,
@@ -603,9 +603,10 @@ func (s *Store) snapAction(ctx context.Context, currentSnaps []*CurrentSnap, act
 		reqOptions.addHeader("Snap-Refresh-Reason", "scheduled")
 	}
 
-	if s.useDeltas() {
-		logger.Debugf("Deltas enabled. Adding header Snap-Accept-Delta-Format: %v", s.deltaFormat)
-		reqOptions.addHeader("Snap-Accept-Delta-Format", s.deltaFormat)
+	if formats := s.acceptedDeltaFormats(); len(formats) > 0 {
+		deltaFormats := strings.Join(formats, ",")
+		logger.Debugf("Deltas enabled. Adding header Snap-Accept-Delta-Format: %v", deltaFormats)
+		reqOptions.addHeader("Snap-Accept-Delta-Format", deltaFormats)
 	}
 	if opts.RefreshManaged {
 		reqOptions.addHeader("Snap-Refresh-Managed", "true")
@@ -50,6 +50,8 @@ import (
 	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snapdtool"
+	"github.com/snapcore/snapd/store/chunkdelta"
+	"github.com/snapcore/snapd/strutil"
 )
 
 var commandFromSystemSnap = snapdtool.CommandFromSystemSnap
@@ -105,9 +107,46 @@ func (s *Store) useDeltas() (use bool) {
 		return false
 	}
 
-	// TODO: have a per-format checker instead, we currently only support
-	// xdelta3 as a format for deltas
+	// xdelta3 produces the smallest deltas so prefer it when available,
+	// snap-chunks deltas are applied natively but are still experimental,
+	// they are only used when explicitly asked for either by the
+	// environment or by the configured format
+	var available []string
+	if s.findXdelta3() {
+		available = append(available, "xdelta3")
+	}
+	if osutil.GetenvBool("SNAPD_USE_DELTAS_EXPERIMENTAL") || s.deltaFormat == chunkdelta.Format {
+		available = append(available, chunkdelta.Format)
+	}
+
+	// only negotiate the configured format, if any
+	s.deltaFormats = nil
+	for _, format := range available {
+		if s.deltaFormat == "" || s.deltaFormat == format {
+			s.deltaFormats = append(s.deltaFormats, format)
+		}
+	}
+	if len(s.deltaFormats) == 0 {
+		if s.deltaFormat != "" {
+			logger.Noticef("cannot use %s deltas, only %s are available", s.deltaFormat, strings.Join(available, ", "))
+		}
+		return false
+	}
+	return true
+}
 
+// acceptedDeltaFormats returns the delta formats to accept from the store, in
+// order of preference.
+func (s *Store) acceptedDeltaFormats() []string {
+	if !s.useDeltas() {
+		return nil
+	}
+	return s.deltaFormats
+}
+
+// findXdelta3 looks for a working xdelta3, first from the system snap and
+// then from the host, and sets up xdelta3CmdFunc to run it.
+func (s *Store) findXdelta3() bool {
 	// check if the xdelta3 config command works from the system snap
 	cmd, err := commandFromSystemSnap("/usr/bin/xdelta3", "config")
 	if err == nil {
@@ -141,13 +180,13 @@ func (s *Store) useDeltas() (use bool) {
 	// trying xdelta3 from the system
 	loc, err := exec.LookPath("xdelta3")
 	if err != nil {
-		// no xdelta3 in the env, so no deltas
+		// no xdelta3 in the env, so no xdelta3 deltas
 		logger.Noticef("no host system xdelta3 available to use deltas")
 		return false
 	}
 
 	if err := exec.Command(loc, "config").Run(); err != nil {
-		// xdelta3 in the env failed to run, so no deltas
+		// xdelta3 in the env failed to run, so no xdelta3 deltas
 		logger.Noticef("unable to use host system xdelta3, running config command failed: %v", err)
 		return false
 	}
@@ -185,7 +224,12 @@ func (e HashError) Error() string {
 }
 
 type DownloadOptions struct {
-	RateLimit           int64
+	// RateLimit is a fixed rate limit in bytes per second, shared with
+	// all the other downloads limited to the same rate.
+	RateLimit int64
+	// RateLimitSchedule takes precedence over RateLimit and makes the rate
+	// limit depend on the time of the day.
+	RateLimitSchedule   *RateLimitSchedule
 	Scheduled           bool
 	LeavePartialOnError bool
 }
@@ -214,7 +258,7 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 	if s.useDeltas() {
 		logger.Debugf("Available deltas returned by store: %v", downloadInfo.Deltas)
 
-		if len(downloadInfo.Deltas) == 1 {
+		if len(downloadInfo.Deltas) > 0 {
 			err := s.downloadAndApplyDelta(name, targetPath, downloadInfo, pbar, user, dlOpts)
 			if err == nil {
 				// try to place the file in the cacher
@@ -630,12 +674,7 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 		}
 		pbar.Start(name, dlSize)
 		mw := io.MultiWriter(w, h, pbar, tc)
-		var limiter io.Reader
-		limiter = resp.Body
-		if limit := dlOpts.RateLimit; limit > 0 {
-			bucket := ratelimit.NewBucketWithRate(float64(limit), 2*limit)
-			limiter = ratelimitReader(resp.Body, bucket)
-		}
+		limiter := rateLimited(resp.Body, dlOpts)
 
 		stopMonitorCh := tc.Monitor()
 		_, finalErr = io.Copy(mw, limiter)
@@ -856,19 +895,33 @@ func doDownloadReqImpl(ctx context.Context, storeURL *url.URL, cdnHeader string,
 	return s.doRequest(ctx, cli, reqOptions, user)
 }
 
-// downloadDelta downloads the delta for the preferred format, returning the path.
-func (s *Store) downloadDelta(deltaName string, downloadInfo *snap.DownloadInfo, w io.ReadWriteSeeker, pbar progress.Meter, user *auth.UserState, dlOpts *DownloadOptions) error {
-
-	if len(downloadInfo.Deltas) != 1 {
-		return errors.New("store returned more than one download delta")
+// pickDelta returns the delta to use among the ones returned by the store,
+// picking the most preferred of the accepted formats.
+func (s *Store) pickDelta(downloadInfo *snap.DownloadInfo) (*snap.DeltaInfo, error) {
+	for _, format := range s.acceptedDeltaFormats() {
+		var picked *snap.DeltaInfo
+		for i := range downloadInfo.Deltas {
+			if downloadInfo.Deltas[i].Format != format {
+				continue
+			}
+			if picked != nil {
+				return nil, fmt.Errorf("store returned more than one %s download delta", format)
+			}
+			picked = &downloadInfo.Deltas[i]
+		}
+		if picked != nil {
+			return picked, nil
+		}
 	}
-
-	deltaInfo := downloadInfo.Deltas[0]
-
-	if deltaInfo.Format != s.deltaFormat {
-		return fmt.Errorf("store returned unsupported delta format %q (only xdelta3 currently)", deltaInfo.Format)
+	formats := make([]string, 0, len(downloadInfo.Deltas))
+	for _, delta := range downloadInfo.Deltas {
+		formats = append(formats, delta.Format)
 	}
+	return nil, fmt.Errorf("store returned unsupported delta formats %s (only %s currently)", strutil.Quoted(formats), strings.Join(s.acceptedDeltaFormats(), ", "))
+}
 
+// downloadDelta downloads the given delta.
+func (s *Store) downloadDelta(deltaName string, deltaInfo *snap.DeltaInfo, w io.ReadWriteSeeker, pbar progress.Meter, user *auth.UserState, dlOpts *DownloadOptions) error {
 	url := deltaInfo.DownloadURL
 
 	return download(context.TODO(), deltaName, deltaInfo.Sha3_384, url, user, s, w, 0, pbar, dlOpts)
@@ -887,27 +940,37 @@ func (s *Store) applyDeltaImpl(name string, deltaPath string, deltaInfo *snap.De
 		return fmt.Errorf("snap %q revision %d not found at %s", name, deltaInfo.FromRevision, snapPath)
 	}
 
-	if deltaInfo.Format != "xdelta3" {
-		return fmt.Errorf("cannot apply unsupported delta format %q (only xdelta3 currently)", deltaInfo.Format)
-	}
-
-	partialTargetPath := targetPath + ".partial"
-
-	xdelta3Args := []string{"-d", "-s", snapPath, deltaPath, partialTargetPath}
-
 	// validity check that deltas are available and that the path for the xdelta3
 	// command is set
 	if ok := s.useDeltas(); !ok {
 		return fmt.Errorf("internal error: applyDelta used when deltas are not available")
 	}
 
-	// run the xdelta3 command, cleaning up if we fail and logging about it
-	if runErr := s.xdelta3CmdFunc(xdelta3Args...).Run(); runErr != nil {
-		logger.Noticef("encountered error applying delta: %v", runErr)
-		if err := os.Remove(partialTargetPath); err != nil {
-			logger.Noticef("error cleaning up partial delta target %q: %s", partialTargetPath, err)
+	partialTargetPath := targetPath + ".partial"
+
+	switch deltaInfo.Format {
+	case "xdelta3":
+		if s.xdelta3CmdFunc == nil {
+			return fmt.Errorf("cannot apply xdelta3 delta: no working xdelta3 available")
+		}
+		xdelta3Args := []string{"-d", "-s", snapPath, deltaPath, partialTargetPath}
+
+		// run the xdelta3 command, cleaning up if we fail and logging about it
+		if runErr := s.xdelta3CmdFunc(xdelta3Args...).Run(); runErr != nil {
+			logger.Noticef("encountered error applying delta: %v", runErr)
+			if err := os.Remove(partialTargetPath); err != nil {
+				logger.Noticef("error cleaning up partial delta target %q: %s", partialTargetPath, err)
+			}
+			return runErr
 		}
-		return runErr
+	case chunkdelta.Format:
+		// the partial target is removed on error
+		if err := chunkdelta.Apply(snapPath, deltaPath, partialTargetPath); err != nil {
+			logger.Noticef("encountered error applying delta: %v", err)
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot apply unsupported delta format %q (only %s currently)", deltaInfo.Format, strings.Join(s.deltaFormats, ", "))
 	}
 
 	if err := os.Chmod(partialTargetPath, 0600); err != nil {
@@ -935,7 +998,10 @@ func (s *Store) applyDeltaImpl(name string, deltaPath string, deltaInfo *snap.De
 
 // downloadAndApplyDelta downloads and then applies the delta to the current snap.
 func (s *Store) downloadAndApplyDelta(name, targetPath string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, user *auth.UserState, dlOpts *DownloadOptions) error {
-	deltaInfo := &downloadInfo.Deltas[0]
+	deltaInfo, err := s.pickDelta(downloadInfo)
+	if err != nil {
+		return err
+	}
 
 	deltaPath := fmt.Sprintf("%s.%s-%d-to-%d.partial", targetPath, deltaInfo.Format, deltaInfo.FromRevision, deltaInfo.ToRevision)
 	deltaName := fmt.Sprintf(i18n.G("%s (delta)"), name)
@@ -951,7 +1017,7 @@ func (s *Store) downloadAndApplyDelta(name, targetPath string, downloadInfo *sna
 		os.Remove(deltaPath)
 	}()
 
-	err = s.downloadDelta(deltaName, downloadInfo, w, pbar, user, dlOpts)
+	err = s.downloadDelta(deltaName, deltaInfo, w, pbar, user, dlOpts)
 	if err != nil {
 		return err
 	}
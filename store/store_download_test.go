@@ -47,6 +47,7 @@ import (
 	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/chunkdelta"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -898,6 +899,87 @@ esac
 	c.Check(obs.cleanupCalls, Equals, 0)
 }
 
+func (s *storeDownloadSuite) TestDownloadChunksDelta(c *C) {
+	// the previous revision of the snap and the new one, which shares most
+	// of its content with it
+	oldContent := bytes.Repeat([]byte("0123456789abcdef"), 16*1024)
+	newContent := append([]byte("new header"), oldContent[:128*1024]...)
+	newContent = append(newContent, []byte("changed in the middle")...)
+	newContent = append(newContent, oldContent[160*1024:]...)
+
+	var delta bytes.Buffer
+	err := chunkdelta.Generate(&delta, bytes.NewReader(oldContent), bytes.NewReader(newContent), 4096)
+	c.Assert(err, IsNil)
+
+	origUseDeltas := os.Getenv("SNAPD_USE_DELTAS_EXPERIMENTAL")
+	defer os.Setenv("SNAPD_USE_DELTAS_EXPERIMENTAL", origUseDeltas)
+
+	for _, t := range []struct {
+		env         string
+		format      string
+		delta       []byte
+		expectedReq []string
+	}{
+		// the delta is applied locally
+		{"1", "", delta.Bytes(), []string{"/delta"}},
+		{"", "snap-chunks", delta.Bytes(), []string{"/delta"}},
+		// a bad delta falls back to a full download
+		{"1", "", delta.Bytes()[:100], []string{"/delta", "/full"}},
+		// snap-chunks deltas are not used unless asked for
+		{"", "", delta.Bytes(), []string{"/full"}},
+	} {
+		c.Assert(os.Setenv("SNAPD_USE_DELTAS_EXPERIMENTAL", t.env), IsNil)
+		// a fresh store as the use of deltas is cached
+		sto := store.New(&store.Config{DeltaFormat: t.format}, nil)
+
+		var requests []string
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.Path)
+			switch r.URL.Path {
+			case "/delta":
+				w.Write(t.delta)
+			case "/full":
+				w.Write(newContent)
+			default:
+				c.Errorf("unexpected request for %q", r.URL.Path)
+			}
+		}))
+		defer mockServer.Close()
+
+		oldRevBlob := filepath.Join(dirs.SnapBlobDir, "foo_1.snap")
+		c.Assert(os.MkdirAll(filepath.Dir(oldRevBlob), 0755), IsNil)
+		c.Assert(os.WriteFile(oldRevBlob, oldContent, 0644), IsNil)
+
+		downloadInfo := &snap.DownloadInfo{
+			DownloadURL: mockServer.URL + "/full",
+			Size:        int64(len(newContent)),
+			Sha3_384:    fmt.Sprintf("%x", sha3.Sum384(newContent)),
+			Deltas: []snap.DeltaInfo{{
+				FromRevision: 1,
+				ToRevision:   2,
+				Format:       "snap-chunks",
+				DownloadURL:  mockServer.URL + "/delta",
+				Sha3_384:     fmt.Sprintf("%x", sha3.Sum384(t.delta)),
+				Size:         int64(len(t.delta)),
+			}},
+		}
+
+		targetFn := filepath.Join(c.MkDir(), "foo_2.snap")
+		err = sto.Download(s.ctx, "foo", targetFn, downloadInfo, nil, nil, nil)
+		c.Assert(err, IsNil)
+		c.Check(targetFn, testutil.FileEquals, newContent)
+		c.Check(requests, DeepEquals, t.expectedReq, Commentf("%+v", t.expectedReq))
+		c.Check(delta.Len() < len(newContent)/10, Equals, true)
+	}
+	// xdelta3 was only checked for, once per store, but not needed
+	c.Check(s.mockXDelta.Calls(), DeepEquals, [][]string{
+		{"xdelta3", "config"},
+		{"xdelta3", "config"},
+		{"xdelta3", "config"},
+		{"xdelta3", "config"},
+	})
+}
+
 func (s *storeDownloadSuite) TestDownloadDeltaRebuitlButCachePutFail(c *C) {
 	obs := &cacheObserver{inCache: map[string]bool{}}
 	restore := s.store.MockCacher(obs)
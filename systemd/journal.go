@@ -20,10 +20,16 @@
 package systemd
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/syslog"
 	"net"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget/quantity"
@@ -96,3 +102,226 @@ func NewJournalStreamFile(params JournalStreamFileParams) (*os.File, error) {
 	}
 	return conn.File()
 }
+
+// MergeLogReaders returns a reader producing the journal entries of all the
+// given readers, each producing entries as output by journalctl -o json.
+//
+// If follow is false, the entries are merged by their time as they are read,
+// each of the readers producing its entries in time order. Unless n is
+// negative only the last n entries are produced, so that at most n entries
+// are kept in memory until all the readers reach their end. If follow is true,
+// the entries are produced in the order in which they are read from the
+// readers, until all of them reach their end.
+//
+// The given readers are closed when the returned reader is closed.
+func MergeLogReaders(readers []io.ReadCloser, n int, follow bool) io.ReadCloser {
+	if len(readers) == 1 {
+		return readers[0]
+	}
+	if follow {
+		return followLogReaders(readers)
+	}
+	return sortLogReaders(readers, n)
+}
+
+type logEntry struct {
+	time time.Time
+	raw  []byte
+}
+
+type sortedLogReader struct {
+	readers []io.ReadCloser
+	brs     []*bufio.Reader
+	// heads holds the next entry of each reader, nil once the reader
+	// reached its end
+	heads []*logEntry
+	// last is the index of the reader of the last returned entry
+	last int
+	n    int
+	// tail holds the last n entries, produced once all the readers
+	// reached their end
+	tail     []*logEntry
+	tailRead bool
+
+	started bool
+	buf     bytes.Buffer
+	err     error
+}
+
+// sortLogReaders merges the entries of the readers by their time.
+func sortLogReaders(readers []io.ReadCloser, n int) io.ReadCloser {
+	r := &sortedLogReader{
+		readers: readers,
+		brs:     make([]*bufio.Reader, len(readers)),
+		heads:   make([]*logEntry, len(readers)),
+		n:       n,
+	}
+	for i, rd := range readers {
+		r.brs[i] = bufio.NewReader(rd)
+	}
+	return r
+}
+
+func readLogEntry(br *bufio.Reader) (*logEntry, error) {
+	// journalctl -o json produces an entry per line
+	raw, err := br.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("cannot read journal entry: %v", err)
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		if err == io.EOF {
+			return nil, nil
+		}
+		// skip empty lines
+		return readLogEntry(br)
+	}
+	var log Log
+	if err := json.Unmarshal(raw, &log); err != nil {
+		return nil, fmt.Errorf("cannot read journal entry: %v", err)
+	}
+	// entries without a valid time sort first
+	t, _ := log.Time()
+	return &logEntry{time: t, raw: raw}, nil
+}
+
+// next returns the oldest of the next entries of the readers, or nil once
+// all of them reached their end. The reader of the returned entry is only
+// read again on the following call.
+func (r *sortedLogReader) next() (*logEntry, error) {
+	if !r.started {
+		r.started = true
+		for i, br := range r.brs {
+			e, err := readLogEntry(br)
+			if err != nil {
+				return nil, err
+			}
+			r.heads[i] = e
+		}
+	} else if r.last >= 0 {
+		e, err := readLogEntry(r.brs[r.last])
+		if err != nil {
+			return nil, err
+		}
+		r.heads[r.last] = e
+	}
+	r.last = -1
+	for i, e := range r.heads {
+		if e != nil && (r.last < 0 || e.time.Before(r.heads[r.last].time)) {
+			r.last = i
+		}
+	}
+	if r.last < 0 {
+		return nil, nil
+	}
+	return r.heads[r.last], nil
+}
+
+// fill writes the next entry to produce to the buffer.
+func (r *sortedLogReader) fill() error {
+	if r.n >= 0 {
+		if !r.tailRead {
+			r.tailRead = true
+			for {
+				e, err := r.next()
+				if err != nil {
+					return err
+				}
+				if e == nil {
+					break
+				}
+				r.tail = append(r.tail, e)
+				if len(r.tail) > r.n {
+					r.tail = r.tail[1:]
+				}
+			}
+		}
+		if len(r.tail) == 0 {
+			return io.EOF
+		}
+		r.buf.Write(r.tail[0].raw)
+		r.tail = r.tail[1:]
+	} else {
+		e, err := r.next()
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return io.EOF
+		}
+		r.buf.Write(e.raw)
+	}
+	r.buf.WriteByte('\n')
+	return nil
+}
+
+func (r *sortedLogReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.fill()
+	}
+	return r.buf.Read(p)
+}
+
+func (r *sortedLogReader) Close() error {
+	var firstErr error
+	for _, rc := range r.readers {
+		if err := rc.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+type mergedLogReader struct {
+	*io.PipeReader
+	readers []io.ReadCloser
+}
+
+func (r *mergedLogReader) Close() error {
+	r.PipeReader.Close()
+	var firstErr error
+	for _, rc := range r.readers {
+		if err := rc.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// followLogReaders copies whole lines from all the readers as they arrive.
+func followLogReaders(readers []io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, r := range readers {
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			br := bufio.NewReader(r)
+			for {
+				line, err := br.ReadBytes('\n')
+				if len(line) > 0 {
+					mu.Lock()
+					_, werr := pw.Write(line)
+					mu.Unlock()
+					if werr != nil {
+						return
+					}
+				}
+				if err != nil {
+					return
+				}
+			}
+		}(r)
+	}
+	go func() {
+		wg.Wait()
+		pw.Close()
+	}()
+
+	return &mergedLogReader{PipeReader: pr, readers: readers}
+}
@@ -20,10 +20,13 @@
 package systemd_test
 
 import (
+	"bufio"
+	"io"
 	"log/syslog"
 	"net"
 	"os"
 	"path"
+	"strings"
 
 	. "gopkg.in/check.v1"
 
@@ -120,3 +123,143 @@ func (j *journalTestSuite) TestStreamFileHeader(c *C) {
 func (j *journalTestSuite) TestNamespaceStream(c *C) {
 	j.testStreamFileHeader(c, j.journalNamespaceDir, "test")
 }
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (j *journalTestSuite) TestMergeLogReaders(c *C) {
+	r1 := &closeRecorder{Reader: strings.NewReader(`{"__REALTIME_TIMESTAMP":"1000","MESSAGE":"a"}
+{"__REALTIME_TIMESTAMP":"3000","MESSAGE":"c"}
+{"__REALTIME_TIMESTAMP":"5000","MESSAGE":"e"}
+`)}
+	r2 := &closeRecorder{Reader: strings.NewReader(`{"__REALTIME_TIMESTAMP":"2000","MESSAGE":"b"}
+{"__REALTIME_TIMESTAMP":"4000","MESSAGE":"d"}
+`)}
+
+	reader := MergeLogReaders([]io.ReadCloser{r1, r2}, 3, false)
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, `{"__REALTIME_TIMESTAMP":"3000","MESSAGE":"c"}
+{"__REALTIME_TIMESTAMP":"4000","MESSAGE":"d"}
+{"__REALTIME_TIMESTAMP":"5000","MESSAGE":"e"}
+`)
+
+	c.Check(r1.closed, Equals, false)
+	c.Check(r2.closed, Equals, false)
+	c.Assert(reader.Close(), IsNil)
+	c.Check(r1.closed, Equals, true)
+	c.Check(r2.closed, Equals, true)
+}
+
+func (j *journalTestSuite) TestMergeLogReadersAll(c *C) {
+	r1 := io.NopCloser(strings.NewReader(`{"__REALTIME_TIMESTAMP":"3000","MESSAGE":"b"}
+`))
+	r2 := io.NopCloser(strings.NewReader(`{"__REALTIME_TIMESTAMP":"1000","MESSAGE":"a"}
+`))
+
+	reader := MergeLogReaders([]io.ReadCloser{r1, r2}, -1, false)
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, `{"__REALTIME_TIMESTAMP":"1000","MESSAGE":"a"}
+{"__REALTIME_TIMESTAMP":"3000","MESSAGE":"b"}
+`)
+}
+
+func (j *journalTestSuite) TestMergeLogReadersNone(c *C) {
+	r1 := io.NopCloser(strings.NewReader(`{"__REALTIME_TIMESTAMP":"3000","MESSAGE":"b"}
+`))
+	r2 := io.NopCloser(strings.NewReader(""))
+
+	reader := MergeLogReaders([]io.ReadCloser{r1, r2}, 0, false)
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, "")
+}
+
+func (j *journalTestSuite) TestMergeLogReadersAllIsLazy(c *C) {
+	pr1, pw1 := io.Pipe()
+	pr2, pw2 := io.Pipe()
+
+	reader := MergeLogReaders([]io.ReadCloser{pr1, pr2}, -1, false)
+	defer reader.Close()
+
+	go func() {
+		pw1.Write([]byte(`{"__REALTIME_TIMESTAMP":"1000","MESSAGE":"a"}` + "\n"))
+		pw2.Write([]byte(`{"__REALTIME_TIMESTAMP":"2000","MESSAGE":"b"}` + "\n"))
+		pw1.Write([]byte(`{"__REALTIME_TIMESTAMP":"3000","MESSAGE":"c"}` + "\n"))
+	}()
+
+	br := bufio.NewReader(reader)
+	// the oldest entry is produced as soon as the next entry of each of the
+	// readers is known, without reading the readers to their end
+	line, err := br.ReadString('\n')
+	c.Assert(err, IsNil)
+	c.Check(line, Equals, `{"__REALTIME_TIMESTAMP":"1000","MESSAGE":"a"}`+"\n")
+	line, err = br.ReadString('\n')
+	c.Assert(err, IsNil)
+	c.Check(line, Equals, `{"__REALTIME_TIMESTAMP":"2000","MESSAGE":"b"}`+"\n")
+
+	pw2.Close()
+	line, err = br.ReadString('\n')
+	c.Assert(err, IsNil)
+	c.Check(line, Equals, `{"__REALTIME_TIMESTAMP":"3000","MESSAGE":"c"}`+"\n")
+
+	pw1.Close()
+	_, err = br.ReadString('\n')
+	c.Check(err, Equals, io.EOF)
+}
+
+func (j *journalTestSuite) TestMergeLogReadersSingle(c *C) {
+	r := io.NopCloser(strings.NewReader("not even json"))
+	reader := MergeLogReaders([]io.ReadCloser{r}, 10, false)
+	c.Check(reader, Equals, r)
+}
+
+func (j *journalTestSuite) TestMergeLogReadersError(c *C) {
+	r1 := &closeRecorder{Reader: strings.NewReader(`{"MESSAGE":"a"}`)}
+	r2 := &closeRecorder{Reader: strings.NewReader(`{"MESSAGE":`)}
+
+	reader := MergeLogReaders([]io.ReadCloser{r1, r2}, 10, false)
+	_, err := io.ReadAll(reader)
+	c.Check(err, ErrorMatches, "cannot read journal entry: unexpected end of JSON input")
+	c.Assert(reader.Close(), IsNil)
+	c.Check(r1.closed, Equals, true)
+	c.Check(r2.closed, Equals, true)
+}
+
+func (j *journalTestSuite) TestMergeLogReadersFollow(c *C) {
+	pr1, pw1 := io.Pipe()
+	pr2, pw2 := io.Pipe()
+
+	reader := MergeLogReaders([]io.ReadCloser{pr1, pr2}, 10, true)
+	defer reader.Close()
+
+	br := bufio.NewReader(reader)
+	// entries are produced as they arrive
+	go pw2.Write([]byte(`{"MESSAGE":"b"}` + "\n"))
+	line, err := br.ReadString('\n')
+	c.Assert(err, IsNil)
+	c.Check(line, Equals, `{"MESSAGE":"b"}`+"\n")
+
+	// partial lines are not interleaved
+	go func() {
+		pw1.Write([]byte(`{"MESSAGE":`))
+		pw1.Write([]byte(`"a"}` + "\n"))
+	}()
+	line, err = br.ReadString('\n')
+	c.Assert(err, IsNil)
+	c.Check(line, Equals, `{"MESSAGE":"a"}`+"\n")
+
+	// the reader ends when all of the readers end
+	pw1.Close()
+	pw2.Close()
+	_, err = br.ReadString('\n')
+	c.Check(err, Equals, io.EOF)
+}
@@ -274,12 +274,96 @@ func EnsureAtLeast(requiredVersion int) error {
 
 var osutilStreamCommand = osutil.StreamCommand
 
-// jctl calls journalctl to get the JSON logs of the given services.
-var jctl = func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+// LogFilter restricts the journal entries produced by a log reader. The zero
+// value does not filter anything.
+type LogFilter struct {
+	// Priority is a single syslog priority or a range of priorities, by name
+	// or number, in the format accepted by journalctl, e.g. "err" or
+	// "warning..emerg".
+	Priority string
+	// Since and Until limit the entries to the given time range.
+	Since time.Time
+	Until time.Time
+	// Grep is a (PCRE2) pattern the message of the entries must match.
+	Grep string
+	// Boot is the ID of the boot, or a boot offset relative to the current
+	// boot, the entries must be from.
+	Boot string
+}
+
+var logPriorityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+var validLogBoot = regexp.MustCompile(`^(?:[0-9a-f]{32}|[+-]?[0-9]+)$`)
+
+func validateLogPriority(prio string) error {
+	if strutil.ListContains(logPriorityNames, prio) {
+		return nil
+	}
+	if n, err := strconv.Atoi(prio); err == nil && n >= 0 && n < len(logPriorityNames) {
+		return nil
+	}
+	return fmt.Errorf("invalid log priority %q", prio)
+}
+
+// Validate checks that the filter is well formed.
+func (f *LogFilter) Validate() error {
+	if f.Priority != "" {
+		prios := strings.SplitN(f.Priority, "..", 2)
+		for _, prio := range prios {
+			if err := validateLogPriority(prio); err != nil {
+				return err
+			}
+		}
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return fmt.Errorf("invalid log time range: until is before since")
+	}
+	if f.Boot != "" && !validLogBoot.MatchString(f.Boot) {
+		return fmt.Errorf("invalid boot %q", f.Boot)
+	}
+	return nil
+}
+
+// args returns the journalctl arguments implementing the filter.
+func (f *LogFilter) args() []string {
+	if f == nil {
+		return nil
+	}
+	var args []string
+	if f.Priority != "" {
+		args = append(args, "--priority="+f.Priority)
+	}
+	// journalctl takes the time as seconds since the epoch prefixed with @
+	if !f.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=@%d", f.Since.Unix()))
+	}
+	if !f.Until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=@%d", f.Until.Unix()))
+	}
+	if f.Grep != "" {
+		args = append(args, "--grep="+f.Grep)
+	}
+	if f.Boot != "" {
+		args = append(args, "--boot="+f.Boot)
+	}
+	return args
+}
+
+// journalctlArgs returns the arguments for journalctl to get the JSON logs of
+// the given services, from the journal of the calling user if user is set.
+func journalctlArgs(svcs []string, n int, follow, namespaces, user bool, filter *LogFilter) []string {
+	filterArgs := filter.args()
 	// args will need two entries per service, plus a fixed number (give or take
-	// one) for the initial options.
-	args := make([]string, 0, 2*len(svcs)+7)        // We have at most 7 extra arguments
+	// one) for the initial options, plus the filter.
+	extra := 7 // We have at most 7 extra arguments ...
+	if user {
+		extra++ // ... or 8 for the user journal
+	}
+	args := make([]string, 0, 2*len(svcs)+extra+len(filterArgs))
 	args = append(args, "-o", "json", "--no-pager") //   3...
+	if user {
+		args = append(args, "--user")
+	}
 	if n < 0 {
 		args = append(args, "--no-tail") // < 2
 	} else {
@@ -291,15 +375,38 @@ var jctl = func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, e
 	if namespaces {
 		args = append(args, "--namespace=*") // ... + 1 == 7
 	}
+	args = append(args, filterArgs...)
 
 	for i := range svcs {
 		args = append(args, "-u", svcs[i]) // this is why 2×
 	}
 
+	return args
+}
+
+// jctl calls journalctl to get the JSON logs of the given services.
+var jctl = func(svcs []string, n int, follow, namespaces bool, filter *LogFilter) (io.ReadCloser, error) {
+	return osutilStreamCommand("journalctl", journalctlArgs(svcs, n, follow, namespaces, false, filter)...)
+}
+
+// userJctl calls journalctl to get the JSON logs of the given user services
+// from the journal of the calling user. Journal namespaces are not supported
+// for user services.
+var userJctl = func(svcs []string, n int, follow bool, filter *LogFilter) (io.ReadCloser, error) {
+	return osutilStreamCommand("journalctl", journalctlArgs(svcs, n, follow, false, true, filter)...)
+}
+
+// userUnitsJctl calls journalctl to get the JSON logs of the given user
+// services of all the users from the system journal, which requires root.
+var userUnitsJctl = func(svcs []string, n int, follow bool, filter *LogFilter) (io.ReadCloser, error) {
+	args := journalctlArgs(nil, n, follow, false, false, filter)
+	for _, svc := range svcs {
+		args = append(args, "--user-unit", svc)
+	}
 	return osutilStreamCommand("journalctl", args...)
 }
 
-func MockJournalctl(f func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error)) func() {
+func MockJournalctl(f func(svcs []string, n int, follow, namespaces bool, filter *LogFilter) (io.ReadCloser, error)) func() {
 	oldJctl := jctl
 	jctl = f
 	return func() {
@@ -307,6 +414,29 @@ func MockJournalctl(f func(svcs []string, n int, follow, namespaces bool) (io.Re
 	}
 }
 
+func MockUserJournalctl(f func(svcs []string, n int, follow bool, filter *LogFilter) (io.ReadCloser, error)) func() {
+	oldUserJctl := userJctl
+	userJctl = f
+	return func() {
+		userJctl = oldUserJctl
+	}
+}
+
+func MockUserUnitsJournalctl(f func(svcs []string, n int, follow bool, filter *LogFilter) (io.ReadCloser, error)) func() {
+	oldUserUnitsJctl := userUnitsJctl
+	userUnitsJctl = f
+	return func() {
+		userUnitsJctl = oldUserUnitsJctl
+	}
+}
+
+// UserUnitsLogReader returns an io.ReadCloser producing the JSON logs of the
+// given user services of all the users, as read from the system journal. Only
+// root can read the journal of other users.
+func UserUnitsLogReader(serviceNames []string, n int, follow bool, filter *LogFilter) (io.ReadCloser, error) {
+	return userUnitsJctl(serviceNames, n, follow, filter)
+}
+
 // MountUnitType is an enum for the supported mount unit types.
 type MountUnitType int
 
@@ -415,6 +545,10 @@ type Systemd interface {
 	// unit's transition to inactive.
 	// TODO: incorporate this result into Status instead?
 	InactiveEnterTimestamp(unit string) (time.Time, error)
+	// ServiceRestarts returns the number of automatic restarts of the given
+	// services and the exit status of their main process. The results are
+	// returned in the same order as the service names passed in argument.
+	ServiceRestarts(services []string) ([]*ServiceRestartStatus, error)
 	// IsEnabled checks whether the given service is enabled.
 	IsEnabled(service string) (bool, error)
 	// IsActive checks whether the given service is Active
@@ -424,7 +558,9 @@ type Systemd interface {
 	// as it grows.
 	// If namespaces is set to true, the log reader will include journal namespace
 	// logs, and is required to get logs for services which are in journal namespaces.
-	LogReader(services []string, n int, follow, namespaces bool) (io.ReadCloser, error)
+	// The optional filter restricts the entries returned.
+	// In UserMode the logs are read from the journal of the calling user.
+	LogReader(services []string, n int, follow, namespaces bool, filter *LogFilter) (io.ReadCloser, error)
 	// EnsureMountUnitFile adds/enables/starts a mount unit.
 	EnsureMountUnitFile(description, what, where, fstype string, flags EnsureMountUnitFlags) (string, error)
 	// EnsureMountUnitFileWithOptions adds/enables/starts a mount unit with options.
@@ -505,6 +641,9 @@ const (
 	// the default target for systemd timer units that we generate
 	TimersTarget = "timers.target"
 
+	// the default target for systemd path units that we generate
+	PathsTarget = "paths.target"
+
 	// the target for systemd user session units that we generate
 	UserServicesTarget = "default.target"
 )
@@ -684,8 +823,16 @@ func (s *systemd) StartNoBlock(serviceNames []string) error {
 	return err
 }
 
-func (*systemd) LogReader(serviceNames []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
-	return jctl(serviceNames, n, follow, namespaces)
+func (s *systemd) LogReader(serviceNames []string, n int, follow, namespaces bool, filter *LogFilter) (io.ReadCloser, error) {
+	switch s.mode {
+	case GlobalUserMode:
+		panic("cannot call log reader with GlobalUserMode")
+	case UserMode:
+		// user services log to the journal of the user, and cannot
+		// be placed in journal namespaces
+		return userJctl(serviceNames, n, follow, filter)
+	}
+	return jctl(serviceNames, n, follow, namespaces, filter)
 }
 
 var statusregex = regexp.MustCompile(`(?m)^(?:(.+?)=(.*)|(.*))?$`)
@@ -704,6 +851,8 @@ type UnitStatus struct {
 	Names   []string
 	Enabled bool
 	Active  bool
+	// Failed is true if the unit is in the failed state.
+	Failed bool
 	// Installed is false if the queried unit doesn't exist.
 	Installed bool
 	// NeedDaemonReload is true when systemd reports that the unit on disk
@@ -718,6 +867,7 @@ var unitProperties = map[string][]string{
 	".timer":  baseProperties,
 	".socket": baseProperties,
 	".target": baseProperties,
+	".path":   baseProperties,
 	// in service units, Type is the daemon type
 	".service": extendedProperties,
 	// in mount units, Type is the fs type
@@ -811,6 +961,7 @@ func (s *systemd) getUnitStatus(properties []string, unitNames []string) ([]*Uni
 		case "ActiveState":
 			// made to match “systemctl is-active” behaviour, at least at systemd 229
 			cur.Active = v == "active" || v == "reloading"
+			cur.Failed = v == "failed"
 		case "UnitFileState":
 			// "static" means it can't be disabled
 			cur.Enabled = v == "enabled" || v == "static"
@@ -952,6 +1103,59 @@ func (s *systemd) InactiveEnterTimestamp(unit string) (time.Time, error) {
 	return inactiveEnterTime, nil
 }
 
+// ServiceRestartStatus carries the restart information of a service.
+type ServiceRestartStatus struct {
+	Name string
+	// NRestarts is the number of automatic restarts of the service since
+	// it was last started explicitly. It is always 0 with systemd older
+	// than 235, which does not count the restarts.
+	NRestarts uint64
+	// ExecMainStatus is the exit status of the main process of the service,
+	// or the number of the signal that killed it.
+	ExecMainStatus int
+}
+
+func (s *systemd) ServiceRestarts(services []string) ([]*ServiceRestartStatus, error) {
+	if s.mode == GlobalUserMode {
+		panic("cannot call service restarts with GlobalUserMode")
+	}
+	out, err := s.systemctl(append([]string{"show", "--property=Id,NRestarts,ExecMainStatus"}, services...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the properties of each service are separated by an empty line
+	blocks := strings.Split(strings.TrimSpace(string(out)), "\n\n")
+	if len(blocks) != len(services) {
+		return nil, fmt.Errorf("cannot get restarts of services: expected %d results, got %d", len(services), len(blocks))
+	}
+	sts := make([]*ServiceRestartStatus, len(services))
+	for i, block := range blocks {
+		st := &ServiceRestartStatus{Name: services[i]}
+		for _, line := range strings.Split(block, "\n") {
+			k, v, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("cannot get restarts of services: bad line %q in ‘systemctl show’ output", line)
+			}
+			switch k {
+			case "Id":
+				// the name as requested is used
+			case "NRestarts":
+				st.NRestarts, err = strconv.ParseUint(v, 10, 64)
+			case "ExecMainStatus":
+				st.ExecMainStatus, err = strconv.Atoi(v)
+			default:
+				return nil, fmt.Errorf("cannot get restarts of services: unexpected field %q in ‘systemctl show’ output", k)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("cannot get restarts of services: invalid value of %s in ‘systemctl show’ output: %q", k, v)
+			}
+		}
+		sts[i] = st
+	}
+	return sts, nil
+}
+
 func (s *systemd) Status(unitNames []string) ([]*UnitStatus, error) {
 	if s.mode == GlobalUserMode {
 		return s.getGlobalUserStatus(unitNames...)
@@ -964,7 +1168,7 @@ func (s *systemd) Status(unitNames []string) ([]*UnitStatus, error) {
 	for _, name := range unitNames {
 		// Group units with the same query string together to
 		// optimize the number of 'systemctl' invocations.
-		if strings.HasSuffix(name, ".timer") || strings.HasSuffix(name, ".socket") || strings.HasSuffix(name, ".target") {
+		if strings.HasSuffix(name, ".timer") || strings.HasSuffix(name, ".socket") || strings.HasSuffix(name, ".target") || strings.HasSuffix(name, ".path") {
 			// Units using the baseProperties query
 			limitedUnits = append(limitedUnits, name)
 		} else {
@@ -1366,6 +1570,22 @@ func (l Log) PID() string {
 	return "-"
 }
 
+// Fields returns all the fields of the Log which can be represented as
+// strings. Fields with multiple values have their values joined by newlines.
+func (l Log) Fields() map[string]string {
+	fields := make(map[string]string, len(l))
+	for key := range l {
+		val, err := l.parseLogRawMessageString(key, func(stringSlice []string) (string, error) {
+			return strings.Join(stringSlice, "\n"), nil
+		})
+		if err != nil {
+			continue
+		}
+		fields[key] = val
+	}
+	return fields
+}
+
 type UnitLifetime int
 
 const (
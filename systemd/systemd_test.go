@@ -28,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -164,7 +165,7 @@ func (s *SystemdTestSuite) myRun(args ...string) (out []byte, delay time.Duratio
 	return out, delayReq, err
 }
 
-func (s *SystemdTestSuite) myJctl(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+func (s *SystemdTestSuite) myJctl(svcs []string, n int, follow, namespaces bool, filter *LogFilter) (io.ReadCloser, error) {
 	var err error
 	var out []byte
 
@@ -727,6 +728,58 @@ ActiveState=active
 	c.Check(out, IsNil)
 }
 
+func (s *SystemdTestSuite) TestStatusPathUnit(c *C) {
+	s.outs = [][]byte{
+		[]byte(`
+Id=foo.path
+Names=foo.path
+ActiveState=active
+UnitFileState=enabled
+`[1:]),
+	}
+	s.errors = []error{nil}
+	out, err := New(SystemMode, s.rep).Status([]string{"foo.path"})
+	c.Assert(err, IsNil)
+	c.Check(out, DeepEquals, []*UnitStatus{{
+		Id:        "foo.path",
+		Name:      "foo.path",
+		Names:     []string{"foo.path"},
+		Active:    true,
+		Enabled:   true,
+		Installed: true,
+	}})
+	// path units have no type
+	c.Check(s.argses, DeepEquals, [][]string{
+		{"show", "--property=Id,ActiveState,UnitFileState,Names", "foo.path"},
+	})
+}
+
+func (s *SystemdTestSuite) TestStatusFailed(c *C) {
+	s.outs = [][]byte{
+		[]byte(`
+Type=simple
+Id=foo.service
+Names=foo.service
+ActiveState=failed
+UnitFileState=enabled
+NeedDaemonReload=no
+`[1:]),
+	}
+	s.errors = []error{nil}
+	out, err := New(SystemMode, s.rep).Status([]string{"foo.service"})
+	c.Assert(err, IsNil)
+	c.Check(out, DeepEquals, []*UnitStatus{{
+		Daemon:    "simple",
+		Id:        "foo.service",
+		Name:      "foo.service",
+		Names:     []string{"foo.service"},
+		Active:    false,
+		Failed:    true,
+		Enabled:   true,
+		Installed: true,
+	}})
+}
+
 func (s *SystemdTestSuite) TestStatusDupeField(c *C) {
 	s.outs = [][]byte{
 		[]byte(`
@@ -938,7 +991,7 @@ func (s *SystemdTestSuite) TestKill(c *C) {
 func (s *SystemdTestSuite) TestLogErrJctl(c *C) {
 	s.jerrs = []error{errors.New("mock journalctl error")}
 
-	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false)
+	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false, nil)
 	c.Check(err, NotNil)
 	c.Check(reader, IsNil)
 	c.Check(s.jns, DeepEquals, []string{"24"})
@@ -954,7 +1007,7 @@ func (s *SystemdTestSuite) TestLogs(c *C) {
 `
 	s.jouts = [][]byte{[]byte(expected)}
 
-	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false)
+	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false, nil)
 	c.Check(err, IsNil)
 	logs, err := io.ReadAll(reader)
 	c.Assert(err, IsNil)
@@ -966,6 +1019,47 @@ func (s *SystemdTestSuite) TestLogs(c *C) {
 	c.Check(s.j, Equals, 1)
 }
 
+func (s *SystemdTestSuite) TestLogsUserMode(c *C) {
+	var args []string
+	defer MockOsutilStreamCommand(func(name string, myargs ...string) (io.ReadCloser, error) {
+		c.Check(name, Equals, "journalctl")
+		args = myargs
+		return io.NopCloser(strings.NewReader(`{"a": 1}` + "\n")), nil
+	})()
+
+	// namespaces are ignored for user services
+	reader, err := New(UserMode, s.rep).LogReader([]string{"snap.foo.bar.service"}, 10, true, true, nil)
+	c.Assert(err, IsNil)
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, `{"a": 1}`+"\n")
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--user", "-n", "10", "-f", "-u", "snap.foo.bar.service"})
+	// the system journal was not used
+	c.Check(s.j, Equals, 0)
+}
+
+func (s *SystemdTestSuite) TestUserUnitsLogReader(c *C) {
+	var args []string
+	defer MockOsutilStreamCommand(func(name string, myargs ...string) (io.ReadCloser, error) {
+		c.Check(name, Equals, "journalctl")
+		args = myargs
+		return io.NopCloser(strings.NewReader(`{"a": 1}` + "\n")), nil
+	})()
+
+	reader, err := UserUnitsLogReader([]string{"snap.foo.bar.service", "snap.foo.baz.service"}, -1, false, &LogFilter{Priority: "err"})
+	c.Assert(err, IsNil)
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, `{"a": 1}`+"\n")
+	// the user services are matched in the system journal
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail", "--priority=err", "--user-unit", "snap.foo.bar.service", "--user-unit", "snap.foo.baz.service"})
+	c.Check(s.j, Equals, 0)
+}
+
+func (s *SystemdTestSuite) TestLogsGlobalUserModePanics(c *C) {
+	c.Check(func() { New(GlobalUserMode, s.rep).LogReader([]string{"foo"}, 10, false, false, nil) }, PanicMatches, "cannot call log reader with GlobalUserMode")
+}
+
 // mustJSONMarshal panic's if the value cannot be marshaled
 func mustJSONMarshal(v any) *json.RawMessage {
 	b, err := json.Marshal(v)
@@ -1128,6 +1222,26 @@ func (s *SystemdTestSuite) TestLogPID(c *C) {
 	}.PID(), Equals, "42")
 }
 
+func (s *SystemdTestSuite) TestLogFields(c *C) {
+	c.Check(Log{}.Fields(), DeepEquals, map[string]string{})
+	c.Check(Log{
+		"MESSAGE":              mustJSONMarshal([]string{"m1", "m2"}),
+		"_PID":                 mustJSONMarshal("42"),
+		"PRIORITY":             mustJSONMarshal("6"),
+		"__REALTIME_TIMESTAMP": mustJSONMarshal("1555000000000000"),
+		"_BOOT_ID":             mustJSONMarshal([]rune{104, 101, 108, 108, 111}),
+		// fields which cannot be represented as strings are dropped
+		"TRUNCATED": nil,
+		"OTHER":     mustJSONMarshal(5),
+	}.Fields(), DeepEquals, map[string]string{
+		"MESSAGE":              "m1\nm2",
+		"_PID":                 "42",
+		"PRIORITY":             "6",
+		"__REALTIME_TIMESTAMP": "1555000000000000",
+		"_BOOT_ID":             "hello",
+	})
+}
+
 func (s *SystemdTestSuite) TestTime(c *C) {
 	t, err := Log{}.Time()
 	c.Check(t.IsZero(), Equals, true)
@@ -1727,20 +1841,82 @@ func (s *SystemdTestSuite) TestJctl(c *C) {
 		return nil, nil
 	})
 
-	_, err = Jctl([]string{"foo", "bar"}, 10, false, false)
+	_, err = Jctl([]string{"foo", "bar"}, 10, false, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "10", "-u", "foo", "-u", "bar"})
-	_, err = Jctl([]string{"foo", "bar", "baz"}, 99, true, false)
+	_, err = Jctl([]string{"foo", "bar", "baz"}, 99, true, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "99", "-f", "-u", "foo", "-u", "bar", "-u", "baz"})
-	_, err = Jctl([]string{"foo", "bar"}, -1, false, false)
+	_, err = Jctl([]string{"foo", "bar"}, -1, false, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail", "-u", "foo", "-u", "bar"})
-	_, err = Jctl([]string{"foo", "bar"}, -1, false, true)
+	_, err = Jctl([]string{"foo", "bar"}, -1, false, true, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail", "--namespace=*", "-u", "foo", "-u", "bar"})
 }
 
+func (s *SystemdTestSuite) TestJctlFilter(c *C) {
+	var args []string
+	MockOsutilStreamCommand(func(name string, myargs ...string) (io.ReadCloser, error) {
+		c.Check(cap(myargs) <= len(myargs)+3, Equals, true, Commentf("cap:%d, len:%d", cap(myargs), len(myargs)))
+		args = myargs
+		return nil, nil
+	})
+
+	_, err := Jctl([]string{"foo"}, 10, false, false, &LogFilter{})
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "10", "-u", "foo"})
+
+	filter := &LogFilter{
+		Priority: "warning..emerg",
+		Since:    time.Unix(1700000000, 0),
+		Until:    time.Unix(1700003600, 500),
+		Grep:     "oops.*",
+		Boot:     "-1",
+	}
+	_, err = Jctl([]string{"foo", "bar"}, -1, true, true, filter)
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{
+		"-o", "json", "--no-pager", "--no-tail", "-f", "--namespace=*",
+		"--priority=warning..emerg", "--since=@1700000000", "--until=@1700003600",
+		"--grep=oops.*", "--boot=-1",
+		"-u", "foo", "-u", "bar",
+	})
+}
+
+func (s *SystemdTestSuite) TestLogFilterValidate(c *C) {
+	since := time.Unix(1700000000, 0)
+	for _, t := range []struct {
+		filter LogFilter
+		err    string
+	}{
+		{LogFilter{}, ""},
+		{LogFilter{Priority: "err"}, ""},
+		{LogFilter{Priority: "3"}, ""},
+		{LogFilter{Priority: "debug..warning"}, ""},
+		{LogFilter{Priority: "0..7"}, ""},
+		{LogFilter{Since: since, Until: since.Add(time.Hour)}, ""},
+		{LogFilter{Until: since}, ""},
+		{LogFilter{Boot: "0"}, ""},
+		{LogFilter{Boot: "-3"}, ""},
+		{LogFilter{Boot: "0123456789abcdef0123456789abcdef"}, ""},
+		{LogFilter{Priority: "error"}, `invalid log priority "error"`},
+		{LogFilter{Priority: "8"}, `invalid log priority "8"`},
+		{LogFilter{Priority: "err..foo"}, `invalid log priority "foo"`},
+		{LogFilter{Priority: "err.."}, `invalid log priority ""`},
+		{LogFilter{Since: since, Until: since.Add(-time.Hour)}, `invalid log time range: until is before since`},
+		{LogFilter{Boot: "latest"}, `invalid boot "latest"`},
+		{LogFilter{Boot: "0123456789ABCDEF0123456789abcdef"}, `invalid boot "0123456789ABCDEF0123456789abcdef"`},
+	} {
+		err := t.filter.Validate()
+		if t.err == "" {
+			c.Check(err, IsNil, Commentf("%+v", t.filter))
+		} else {
+			c.Check(err, ErrorMatches, t.err, Commentf("%+v", t.filter))
+		}
+	}
+}
+
 func (s *SystemdTestSuite) TestIsActiveUnderRoot(c *C) {
 	sysErr := &Error{}
 	// manpage states that systemctl returns exit code 3 for inactive
@@ -2671,6 +2847,55 @@ func (s *SystemdTestSuite) TestInactiveEnterTimestampFailure(c *C) {
 	c.Check(stamp.IsZero(), Equals, true)
 }
 
+func (s *SystemdTestSuite) TestServiceRestarts(c *C) {
+	s.outs = [][]byte{
+		[]byte(`Id=foo.service
+NRestarts=3
+ExecMainStatus=11
+
+Id=bar.service
+NRestarts=0
+ExecMainStatus=0
+`),
+	}
+	sts, err := New(SystemMode, s.rep).ServiceRestarts([]string{"foo.service", "bar.service"})
+	c.Assert(err, IsNil)
+	c.Check(s.argses, DeepEquals, [][]string{
+		{"show", "--property=Id,NRestarts,ExecMainStatus", "foo.service", "bar.service"},
+	})
+	c.Check(sts, DeepEquals, []*ServiceRestartStatus{
+		{Name: "foo.service", NRestarts: 3, ExecMainStatus: 11},
+		{Name: "bar.service", NRestarts: 0, ExecMainStatus: 0},
+	})
+}
+
+func (s *SystemdTestSuite) TestServiceRestartsOldSystemd(c *C) {
+	// NRestarts is not known before systemd 235
+	s.outs = [][]byte{
+		[]byte("Id=foo.service\nExecMainStatus=1\n"),
+	}
+	sts, err := New(SystemMode, s.rep).ServiceRestarts([]string{"foo.service"})
+	c.Assert(err, IsNil)
+	c.Check(sts, DeepEquals, []*ServiceRestartStatus{
+		{Name: "foo.service", ExecMainStatus: 1},
+	})
+}
+
+func (s *SystemdTestSuite) TestServiceRestartsErrors(c *C) {
+	for _, tc := range []struct {
+		out, err string
+	}{
+		{"Id=foo.service\nNRestarts=1\n\nId=bar.service\n", "cannot get restarts of services: expected 1 results, got 2"},
+		{"Id=foo.service\nNRestarts=potato\n", `cannot get restarts of services: invalid value of NRestarts in ‘systemctl show’ output: "potato"`},
+		{"Id=foo.service\nFoo=bar\n", `cannot get restarts of services: unexpected field "Foo" in ‘systemctl show’ output`},
+		{"Id=foo.service\ngarbage\n", `cannot get restarts of services: bad line "garbage" in ‘systemctl show’ output`},
+	} {
+		s.outs = append(s.outs, []byte(tc.out))
+		_, err := New(SystemMode, s.rep).ServiceRestarts([]string{"foo.service"})
+		c.Check(err, ErrorMatches, tc.err)
+	}
+}
+
 func (s *SystemdTestSuite) TestInactiveEnterTimestampMalformed(c *C) {
 	s.outs = [][]byte{
 		[]byte(`InactiveEnterTimestamp`),
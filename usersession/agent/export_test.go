@@ -29,6 +29,7 @@ var (
 	SessionInfoCmd                     = sessionInfoCmd
 	ServiceControlCmd                  = serviceControlCmd
 	ServiceStatusCmd                   = serviceStatusCmd
+	ServiceLogsCmd                     = serviceLogsCmd
 	PendingRefreshNotificationCmd      = pendingRefreshNotificationCmd
 	FinishRefreshNotificationCmd       = finishRefreshNotificationCmd
 	GuessAppData                       = guessAppData
@@ -20,8 +20,10 @@
 package agent
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/snapcore/snapd/logger"
@@ -90,6 +92,44 @@ func (r *resp) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
 	w.Write(bs)
 }
 
+// journalResponse streams the entries of the journal, as output by
+// journalctl -o json (that is, one JSON object per line), to the client
+// unmodified. If follow is set, every entry is flushed as soon as it is read.
+//
+// The reader is always closed when done.
+type journalResponse struct {
+	io.ReadCloser
+	follow bool
+}
+
+func (jr *journalResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer jr.Close()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(200)
+
+	flusher, hasFlusher := w.(http.Flusher)
+	reader := bufio.NewReader(jr)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if _, err := w.Write(line); err != nil {
+				logger.Noticef("cannot stream journal; problem writing: %v", err)
+				return
+			}
+			if jr.follow && hasFlusher {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				logger.Noticef("cannot stream journal; problem reading: %v", err)
+			}
+			return
+		}
+	}
+}
+
 type errorKind string
 
 const (
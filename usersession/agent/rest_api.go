@@ -24,7 +24,9 @@ import (
 	"fmt"
 	"mime"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -46,6 +48,7 @@ var restApi = []*Command{
 	sessionInfoCmd,
 	serviceControlCmd,
 	serviceStatusCmd,
+	serviceLogsCmd,
 	pendingRefreshNotificationCmd,
 	finishRefreshNotificationCmd,
 }
@@ -71,6 +74,11 @@ var (
 		GET:  serviceStatus,
 	}
 
+	serviceLogsCmd = &Command{
+		Path: "/v1/service-logs",
+		GET:  serviceLogs,
+	}
+
 	pendingRefreshNotificationCmd = &Command{
 		Path: "/v1/notifications/pending-refresh",
 		POST: postPendingRefreshNotification,
@@ -309,6 +317,7 @@ func unitStatusToClientUnitStatus(units []*systemd.UnitStatus) []*client.Service
 			Names:            u.Names,
 			Enabled:          u.Enabled,
 			Active:           u.Active,
+			Failed:           u.Failed,
 			Installed:        u.Installed,
 			NeedDaemonReload: u.NeedDaemonReload,
 		})
@@ -358,6 +367,82 @@ func serviceStatus(c *Command, r *http.Request) Response {
 	return SyncResponse(unitStatusToClientUnitStatus(stss))
 }
 
+func logFilterFromQuery(query url.Values) (*systemd.LogFilter, error) {
+	filter := &systemd.LogFilter{
+		Priority: query.Get("priority"),
+		Grep:     query.Get("grep"),
+		Boot:     query.Get("boot"),
+	}
+	for _, t := range []struct {
+		name string
+		to   *time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	} {
+		s := query.Get(t.name)
+		if s == "" {
+			continue
+		}
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %q: %v", t.name, s, err)
+		}
+		*t.to = tm
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func serviceLogs(c *Command, r *http.Request) Response {
+	query := r.URL.Query()
+	services := strutil.CommaSeparatedList(query.Get("services"))
+	if len(services) == 0 {
+		return BadRequest("cannot read logs without a list of services")
+	}
+
+	// Refuse to accept any non-snap services
+	for _, service := range services {
+		if !strings.HasPrefix(service, "snap.") {
+			return InternalError("cannot read logs of non-snap service %v", service)
+		}
+	}
+
+	n := 10
+	if s := query.Get("n"); s != "" {
+		m, err := strconv.Atoi(s)
+		if err != nil {
+			return BadRequest("invalid value for n: %q: %v", s, err)
+		}
+		// negative values mean all of the log
+		n = m
+	}
+	follow := false
+	if s := query.Get("follow"); s != "" {
+		f, err := strconv.ParseBool(s)
+		if err != nil {
+			return BadRequest("invalid value for follow: %q: %v", s, err)
+		}
+		follow = f
+	}
+	filter, err := logFilterFromQuery(query)
+	if err != nil {
+		return BadRequest("%v", err)
+	}
+
+	sysd := systemd.New(systemd.UserMode, noopReporter{})
+	reader, err := sysd.LogReader(services, n, follow, false, filter)
+	if err != nil {
+		return InternalError("cannot read logs of user services %q: %v", services, err)
+	}
+	return &journalResponse{
+		ReadCloser: reader,
+		follow:     follow,
+	}
+}
+
 var currentLocale = i18n.CurrentLocale
 
 func getLocalizedAppNameFromDesktopFile(parser *goconfigparser.ConfigParser, defaultName string) string {
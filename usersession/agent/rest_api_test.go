@@ -24,10 +24,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/godbus/dbus/v5"
@@ -712,6 +714,7 @@ NeedDaemonReload=no
 			"active":    false,
 			"daemon":    "notify",
 			"enabled":   true,
+			"failed":    false,
 			"id":        "snap.foo.service",
 			"installed": true,
 			"name":      "snap.foo.service",
@@ -724,6 +727,7 @@ NeedDaemonReload=no
 			"active":    false,
 			"daemon":    "notify",
 			"enabled":   true,
+			"failed":    false,
 			"id":        "snap.bar.service",
 			"installed": true,
 			"name":      "snap.bar.service",
@@ -794,6 +798,93 @@ func (s *restSuite) TestServicesStatusReportsError(c *C) {
 	})
 }
 
+func (s *restSuite) TestServiceLogs(c *C) {
+	// the agent.ServiceLogs end point only supports GET requests
+	c.Check(agent.ServiceLogsCmd.PUT, IsNil)
+	c.Check(agent.ServiceLogsCmd.POST, IsNil)
+	c.Check(agent.ServiceLogsCmd.DELETE, IsNil)
+	c.Assert(agent.ServiceLogsCmd.GET, NotNil)
+
+	c.Check(agent.ServiceLogsCmd.Path, Equals, "/v1/service-logs")
+
+	var svcs []string
+	var n int
+	var follow bool
+	var filter *systemd.LogFilter
+	restore := systemd.MockUserJournalctl(func(s []string, nn int, f bool, flt *systemd.LogFilter) (io.ReadCloser, error) {
+		svcs, n, follow, filter = s, nn, f, flt
+		return io.NopCloser(strings.NewReader(`{"MESSAGE": "hello"}` + "\n" + `{"MESSAGE": "world"}` + "\n")), nil
+	})
+	defer restore()
+
+	req := httptest.NewRequest("GET", "/v1/service-logs?services=snap.foo.svc.service,snap.bar.svc.service&n=-1&follow=true&priority=err&since=2023-11-14T22:13:20Z&boot=-1", nil)
+	rec := httptest.NewRecorder()
+	agent.ServiceLogsCmd.GET(agent.ServiceLogsCmd, req).ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, 200)
+	c.Check(rec.Header().Get("Content-Type"), Equals, "application/x-ndjson")
+	c.Check(rec.Body.String(), Equals, `{"MESSAGE": "hello"}`+"\n"+`{"MESSAGE": "world"}`+"\n")
+	c.Check(rec.Flushed, Equals, true)
+
+	c.Check(svcs, DeepEquals, []string{"snap.foo.svc.service", "snap.bar.svc.service"})
+	c.Check(n, Equals, -1)
+	c.Check(follow, Equals, true)
+	c.Check(filter, DeepEquals, &systemd.LogFilter{
+		Priority: "err",
+		Since:    time.Unix(1700000000, 0).UTC(),
+		Boot:     "-1",
+	})
+}
+
+func (s *restSuite) TestServiceLogsDefaults(c *C) {
+	var n int
+	var follow bool
+	restore := systemd.MockUserJournalctl(func(s []string, nn int, f bool, flt *systemd.LogFilter) (io.ReadCloser, error) {
+		n, follow = nn, f
+		return io.NopCloser(strings.NewReader("")), nil
+	})
+	defer restore()
+
+	req := httptest.NewRequest("GET", "/v1/service-logs?services=snap.foo.svc.service", nil)
+	rec := httptest.NewRecorder()
+	agent.ServiceLogsCmd.GET(agent.ServiceLogsCmd, req).ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, 200)
+	c.Check(rec.Body.String(), Equals, "")
+	c.Check(rec.Flushed, Equals, false)
+	c.Check(n, Equals, 10)
+	c.Check(follow, Equals, false)
+}
+
+func (s *restSuite) TestServiceLogsErrors(c *C) {
+	restore := systemd.MockUserJournalctl(func(s []string, nn int, f bool, flt *systemd.LogFilter) (io.ReadCloser, error) {
+		return nil, errors.New("mock journalctl error")
+	})
+	defer restore()
+
+	for _, t := range []struct {
+		query  string
+		status int
+		msg    string
+	}{
+		{"", 400, "cannot read logs without a list of services"},
+		{"services=not-snap.bar.service", 500, "cannot read logs of non-snap service not-snap.bar.service"},
+		{"services=snap.foo.svc.service&n=x", 400, `invalid value for n: "x": .*`},
+		{"services=snap.foo.svc.service&follow=x", 400, `invalid value for follow: "x": .*`},
+		{"services=snap.foo.svc.service&since=x", 400, `invalid value for since: "x": .*`},
+		{"services=snap.foo.svc.service&priority=x", 400, `invalid log priority "x"`},
+		{"services=snap.foo.svc.service", 500, `cannot read logs of user services \["snap.foo.svc.service"\]: mock journalctl error`},
+	} {
+		req := httptest.NewRequest("GET", "/v1/service-logs?"+t.query, nil)
+		rec := httptest.NewRecorder()
+		agent.ServiceLogsCmd.GET(agent.ServiceLogsCmd, req).ServeHTTP(rec, req)
+		c.Check(rec.Code, Equals, t.status, Commentf(t.query))
+
+		var rsp resp
+		c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), IsNil)
+		c.Check(rsp.Type, Equals, agent.ResponseTypeError)
+		c.Check(rsp.Result.(map[string]any)["message"], Matches, t.msg, Commentf(t.query))
+	}
+}
+
 func (s *restSuite) TestPostPendingRefreshNotificationMalformedContentType(c *C) {
 	req := httptest.NewRequest("POST", "/v1/notifications/pending-refresh", bytes.NewBufferString(""))
 	req.Header.Set("Content-Type", "text/plain/joke")
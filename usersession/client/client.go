@@ -29,6 +29,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -450,6 +451,7 @@ type ServiceUnitStatus struct {
 	Names            []string `json:"names"`
 	Enabled          bool     `json:"enabled"`
 	Active           bool     `json:"active"`
+	Failed           bool     `json:"failed"`
 	Installed        bool     `json:"installed"`
 	NeedDaemonReload bool     `json:"needs-reload"`
 }
@@ -462,6 +464,7 @@ func (us *ServiceUnitStatus) SystemdUnitStatus() *systemd.UnitStatus {
 		Names:            us.Names,
 		Enabled:          us.Enabled,
 		Active:           us.Active,
+		Failed:           us.Failed,
 		Installed:        us.Installed,
 		NeedDaemonReload: us.NeedDaemonReload,
 	}
@@ -505,6 +508,59 @@ func (client *Client) ServiceStatus(ctx context.Context, services []string) (map
 	return stss, failures, respErr
 }
 
+// ServiceLogs returns a reader for the journal entries of the given user
+// services of the user with the given uid, in the format produced by
+// journalctl -o json. The optional filter restricts the entries returned. If
+// follow is set to true, the reader follows the journal as it grows until
+// the context is cancelled or the reader is closed.
+func (client *Client) ServiceLogs(ctx context.Context, uid int, services []string, n int, follow bool, filter *systemd.LogFilter) (io.ReadCloser, error) {
+	if !client.uidIsValidAsTarget(uid) {
+		return nil, fmt.Errorf("cannot read logs of user %d: not a target of the client", uid)
+	}
+
+	q := make(url.Values)
+	q.Add("services", strings.Join(services, ","))
+	q.Add("n", strconv.Itoa(n))
+	if follow {
+		q.Add("follow", "true")
+	}
+	if filter != nil {
+		if filter.Priority != "" {
+			q.Add("priority", filter.Priority)
+		}
+		if !filter.Since.IsZero() {
+			q.Add("since", filter.Since.Format(time.RFC3339))
+		}
+		if !filter.Until.IsZero() {
+			q.Add("until", filter.Until.Format(time.RFC3339))
+		}
+		if filter.Grep != "" {
+			q.Add("grep", filter.Grep)
+		}
+		if filter.Boot != "" {
+			q.Add("boot", filter.Boot)
+		}
+	}
+
+	httpResp, err := client.sendOneRaw(ctx, uid, "GET", "/v1/service-logs", q, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != 200 {
+		defer httpResp.Body.Close()
+		resp := &response{uid: uid, statusCode: httpResp.StatusCode}
+		if err := decodeInto(httpResp.Body, resp); err != nil {
+			return nil, err
+		}
+		resp.checkError()
+		if resp.err == nil {
+			resp.err = fmt.Errorf("server error: %q", http.StatusText(resp.statusCode))
+		}
+		return nil, resp.err
+	}
+	return httpResp.Body, nil
+}
+
 // PendingSnapRefreshInfo holds information about pending snap refresh provided to userd.
 type PendingSnapRefreshInfo struct {
 	InstanceName        string        `json:"instance-name"`
@@ -26,6 +26,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -35,6 +36,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/usersession/client"
 )
@@ -599,6 +601,7 @@ func (s *clientSuite) TestServiceStatus(c *C) {
 	"active": false,
 	"daemon": "notify",
 	"enabled": true,
+	"failed": true,
 	"id": "snap.foo.service",
 	"installed": true,
 	"name": "snap.foo.service",
@@ -619,6 +622,7 @@ func (s *clientSuite) TestServiceStatus(c *C) {
 				Names:            []string{"snap.foo.service"},
 				Enabled:          true,
 				Active:           false,
+				Failed:           true,
 				Installed:        true,
 				NeedDaemonReload: false,
 			},
@@ -631,6 +635,7 @@ func (s *clientSuite) TestServiceStatus(c *C) {
 				Names:            []string{"snap.foo.service"},
 				Enabled:          true,
 				Active:           false,
+				Failed:           true,
 				Installed:        true,
 				NeedDaemonReload: false,
 			},
@@ -638,6 +643,59 @@ func (s *clientSuite) TestServiceStatus(c *C) {
 	})
 }
 
+func (s *clientSuite) TestServiceLogs(c *C) {
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v1/service-logs")
+		c.Check(r.URL.Query(), DeepEquals, url.Values{
+			"services": {"snap.foo.svc.service,snap.bar.svc.service"},
+			"n":        {"-1"},
+			"follow":   {"true"},
+			"priority": {"err"},
+			"since":    {"2023-11-14T22:13:20Z"},
+			"grep":     {"oops"},
+		})
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(200)
+		w.Write([]byte(`{"MESSAGE": "hello"}` + "\n"))
+	})
+	cli := client.NewForUids(1000)
+	filter := &systemd.LogFilter{
+		Priority: "err",
+		Since:    time.Unix(1700000000, 0).UTC(),
+		Grep:     "oops",
+	}
+	reader, err := cli.ServiceLogs(context.Background(), 1000, []string{"snap.foo.svc.service", "snap.bar.svc.service"}, -1, true, filter)
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	logs, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(logs), Equals, `{"MESSAGE": "hello"}`+"\n")
+}
+
+func (s *clientSuite) TestServiceLogsError(c *C) {
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(500)
+		w.Write([]byte(`{
+  "type": "error",
+  "result": {
+    "message": "cannot read logs"
+  }
+}`))
+	})
+	reader, err := s.cli.ServiceLogs(context.Background(), 1000, []string{"snap.foo.svc.service"}, 10, false, nil)
+	c.Check(err, ErrorMatches, "cannot read logs")
+	c.Check(reader, IsNil)
+}
+
+func (s *clientSuite) TestServiceLogsNotTarget(c *C) {
+	cli := client.NewForUids(42)
+	reader, err := cli.ServiceLogs(context.Background(), 1000, []string{"snap.foo.svc.service"}, 10, false, nil)
+	c.Check(err, ErrorMatches, "cannot read logs of user 1000: not a target of the client")
+	c.Check(reader, IsNil)
+}
+
 func (s *clientSuite) TestServiceStatusFatalError(c *C) {
 	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
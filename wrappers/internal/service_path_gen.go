@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/systemd"
+)
+
+// pathDirectives maps watch-paths conditions to the directives of the
+// [Path] section, see systemd.path(5)
+var pathDirectives = map[snap.WatchPathCondition]string{
+	snap.WatchPathExists:            "PathExists",
+	snap.WatchPathExistsGlob:        "PathExistsGlob",
+	snap.WatchPathChanged:           "PathChanged",
+	snap.WatchPathModified:          "PathModified",
+	snap.WatchPathDirectoryNotEmpty: "DirectoryNotEmpty",
+}
+
+func renderWatchPath(watch *snap.WatchPathInfo) string {
+	s := watch.App.Snap
+	path := watch.Path
+	switch watch.App.DaemonScope {
+	case snap.SystemDaemon:
+		path = strings.Replace(path, "$SNAP_DATA", s.DataDir(), -1)
+		path = strings.Replace(path, "$SNAP_COMMON", s.CommonDataDir(), -1)
+	case snap.UserDaemon:
+		// TODO: use SnapDirOpts here, see renderListenStream
+		path = strings.Replace(path, "$SNAP_USER_DATA", s.UserDataDir("%h", nil), -1)
+		path = strings.Replace(path, "$SNAP_USER_COMMON", s.UserCommonDataDir("%h", nil), -1)
+	default:
+		panic("unknown snap.DaemonScope")
+	}
+	return path
+}
+
+// GenerateSnapServicePathUnitFile generates the systemd .path unit which
+// activates the service when any of its watch-paths triggers.
+func GenerateSnapServicePathUnitFile(app *snap.AppInfo) ([]byte, error) {
+	pathTemplate := `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Path watch for snap application {{.App.Snap.InstanceName}}.{{.App.Name}}
+{{- if .MountUnit}}
+Requires={{.MountUnit}}
+After={{.MountUnit}}
+{{- end}}
+X-Snappy=yes
+
+[Path]
+Unit={{.ServiceFileName}}
+{{ range .Directives }}{{ . }}
+{{ end }}
+[Install]
+WantedBy={{.PathsTarget}}
+`
+	var templateOut bytes.Buffer
+	t := template.Must(template.New("path-wrapper").Parse(pathTemplate))
+
+	if err := snap.ValidateApp(app); err != nil {
+		return nil, err
+	}
+
+	directives := make([]string, 0, len(app.WatchPaths))
+	for _, watch := range app.WatchPaths {
+		directive, ok := pathDirectives[watch.Condition]
+		if !ok {
+			// validation makes this impossible
+			return nil, fmt.Errorf("internal error: unknown watch-paths condition %q", watch.Condition)
+		}
+		directives = append(directives, fmt.Sprintf("%s=%s", directive, renderWatchPath(watch)))
+	}
+
+	wrapperData := struct {
+		App             *snap.AppInfo
+		ServiceFileName string
+		PathsTarget     string
+		MountUnit       string
+		Directives      []string
+	}{
+		App:             app,
+		ServiceFileName: filepath.Base(app.ServiceFile()),
+		PathsTarget:     systemd.PathsTarget,
+		Directives:      directives,
+	}
+	switch app.DaemonScope {
+	case snap.SystemDaemon:
+		wrapperData.MountUnit = filepath.Base(systemd.MountUnitPath(app.Snap.MountDir()))
+	case snap.UserDaemon:
+		// nothing
+	default:
+		panic("unknown snap.DaemonScope")
+	}
+
+	if err := t.Execute(&templateOut, wrapperData); err != nil {
+		// this can never happen, except we forget a variable
+		logger.Panicf("Unable to execute template: %v", err)
+	}
+
+	return templateOut.Bytes(), nil
+}
+
+// GenerateSnapServiceDeviceTargetUnitFile generates the systemd target which
+// is pulled in by udev when a device activating the service appears. Enabling
+// the service hooks it into the target, so disabling the service also stops
+// its activation by devices.
+func GenerateSnapServiceDeviceTargetUnitFile(app *snap.AppInfo) []byte {
+	targetTemplate := `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Device activation for snap application {{.App.Snap.InstanceName}}.{{.App.Name}}
+StopWhenUnneeded=yes
+X-Snappy=yes
+`
+	var templateOut bytes.Buffer
+	t := template.Must(template.New("device-target-wrapper").Parse(targetTemplate))
+
+	wrapperData := struct {
+		App *snap.AppInfo
+	}{
+		App: app,
+	}
+	if err := t.Execute(&templateOut, wrapperData); err != nil {
+		// this can never happen, except we forget a variable
+		logger.Panicf("Unable to execute template: %v", err)
+	}
+
+	return templateOut.Bytes()
+}
@@ -0,0 +1,161 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package internal_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+	"github.com/snapcore/snapd/timeout"
+	"github.com/snapcore/snapd/wrappers/internal"
+)
+
+type servicePathUnitGenSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&servicePathUnitGenSuite{})
+
+func (s *servicePathUnitGenSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir("/")
+}
+
+func (s *servicePathUnitGenSuite) serviceWithWatchPaths(scope snap.DaemonScope, watches ...*snap.WatchPathInfo) *snap.AppInfo {
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "simple",
+		DaemonScope: scope,
+		StopTimeout: timeout.DefaultTimeout,
+		WatchPaths:  watches,
+	}
+	for _, watch := range watches {
+		watch.App = service
+	}
+	return service
+}
+
+func (s *servicePathUnitGenSuite) TestServicePathUnit(c *C) {
+	const expectedPathFmt = `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Path watch for snap application snap.app
+Requires=%s-snap-44.mount
+After=%s-snap-44.mount
+X-Snappy=yes
+
+[Path]
+Unit=snap.snap.app.service
+DirectoryNotEmpty=/var/snap/snap/common/incoming
+PathChanged=/var/snap/snap/44/config
+PathModified=/var/snap/snap/44/log
+PathExists=/var/snap/snap/common/flag
+PathExistsGlob=/var/snap/snap/common/spool/*.job
+
+[Install]
+WantedBy=paths.target
+`
+	service := s.serviceWithWatchPaths(snap.SystemDaemon,
+		&snap.WatchPathInfo{Path: "$SNAP_COMMON/incoming", Condition: snap.WatchPathDirectoryNotEmpty},
+		&snap.WatchPathInfo{Path: "$SNAP_DATA/config", Condition: snap.WatchPathChanged},
+		&snap.WatchPathInfo{Path: "$SNAP_DATA/log", Condition: snap.WatchPathModified},
+		&snap.WatchPathInfo{Path: "$SNAP_COMMON/flag", Condition: snap.WatchPathExists},
+		&snap.WatchPathInfo{Path: "$SNAP_COMMON/spool/*.job", Condition: snap.WatchPathExistsGlob},
+	)
+
+	generatedWrapper, err := internal.GenerateSnapServicePathUnitFile(service)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), Equals, fmt.Sprintf(expectedPathFmt, mountUnitPrefix, mountUnitPrefix))
+}
+
+func (s *servicePathUnitGenSuite) TestServicePathUnitUserDaemon(c *C) {
+	const expectedPath = `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Path watch for snap application snap.app
+X-Snappy=yes
+
+[Path]
+Unit=snap.snap.app.service
+PathChanged=%h/snap/snap/44/config
+DirectoryNotEmpty=%h/snap/snap/common/incoming
+
+[Install]
+WantedBy=paths.target
+`
+	service := s.serviceWithWatchPaths(snap.UserDaemon,
+		&snap.WatchPathInfo{Path: "$SNAP_USER_DATA/config", Condition: snap.WatchPathChanged},
+		&snap.WatchPathInfo{Path: "$SNAP_USER_COMMON/incoming", Condition: snap.WatchPathDirectoryNotEmpty},
+	)
+
+	generatedWrapper, err := internal.GenerateSnapServicePathUnitFile(service)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), Equals, expectedPath)
+}
+
+func (s *servicePathUnitGenSuite) TestServicePathUnitInvalid(c *C) {
+	service := s.serviceWithWatchPaths(snap.SystemDaemon,
+		&snap.WatchPathInfo{Path: "/etc/passwd", Condition: snap.WatchPathChanged},
+	)
+
+	generatedWrapper, err := internal.GenerateSnapServicePathUnitFile(service)
+	c.Assert(err, ErrorMatches, `invalid watch-paths entry "/etc/passwd": system daemon paths must have a prefix of \$SNAP_DATA or \$SNAP_COMMON`)
+	c.Assert(generatedWrapper, IsNil)
+}
+
+func (s *servicePathUnitGenSuite) TestServicePathServiceUnit(c *C) {
+	service := s.serviceWithWatchPaths(snap.SystemDaemon,
+		&snap.WatchPathInfo{Path: "$SNAP_COMMON/incoming", Condition: snap.WatchPathDirectoryNotEmpty},
+	)
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+	// the service is activated by the path unit only
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "[Install]")
+}
+
+func (s *servicePathUnitGenSuite) TestServiceDeviceTargetUnit(c *C) {
+	service := s.serviceWithWatchPaths(snap.SystemDaemon)
+	service.ActivatesOnDevice = []*snap.PlugInfo{{Snap: service.Snap, Name: "serial", Interface: "serial-port"}}
+
+	c.Check(string(internal.GenerateSnapServiceDeviceTargetUnitFile(service)), Equals, `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Device activation for snap application snap.app
+StopWhenUnneeded=yes
+X-Snappy=yes
+`)
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+	// the service is hooked into the device target when enabled
+	c.Check(string(generatedWrapper), testutil.Contains, `
+[Install]
+WantedBy=snap.snap.app.device.target
+`)
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "multi-user.target")
+}
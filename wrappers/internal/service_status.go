@@ -255,5 +255,10 @@ func SnapServiceUnits(app *snap.AppInfo) (service string, activators []string) {
 	if app.Timer != nil {
 		activators = append(activators, filepath.Base(app.Timer.File()))
 	}
+
+	// Add application path unit
+	if len(app.WatchPaths) > 0 {
+		activators = append(activators, filepath.Base(app.WatchPathsFile()))
+	}
 	return app.ServiceName(), activators
 }
@@ -583,6 +583,9 @@ apps:
        listen-stream: $SNAP_DATA/sock1.socket
       sock2:
        listen-stream: $SNAP_DATA/sock2.socket
+    watch-paths:
+      - path: $SNAP_USER_DATA/flag
+        condition: exists
 `
 	info := snaptest.MockSnap(c, surviveYaml, &snap.SideInfo{Revision: snap.R(1)})
 
@@ -592,9 +595,11 @@ apps:
 	// The activators must appear the in following order:
 	// Sockets, sorted
 	// Timer unit
+	// Path unit
 	c.Check(activators, DeepEquals, []string{
 		"snap.test-snap.foo.sock1.socket",
 		"snap.test-snap.foo.sock2.socket",
 		"snap.test-snap.foo.timer",
+		"snap.test-snap.foo.path",
 	})
 }
@@ -57,6 +57,17 @@ func daysRange(start, end uint) string {
 	return buf.String()
 }
 
+// OnCalendarSchedules parses a timer schedule in the format of the snap.yaml
+// and converts it into OnCalendar schedules suitable for use in systemd
+// *.timer units.
+func OnCalendarSchedules(timer string) ([]string, error) {
+	schedule, err := timeutil.ParseSchedule(timer)
+	if err != nil {
+		return nil, err
+	}
+	return generateOnCalendarSchedules(schedule), nil
+}
+
 // generateOnCalendarSchedules converts a schedule into OnCalendar schedules
 // suitable for use in systemd *.timer units using systemd.time(7)
 // https://www.freedesktop.org/software/systemd/man/systemd.time.html
@@ -271,13 +282,11 @@ WantedBy={{.TimersTarget}}
 	var templateOut bytes.Buffer
 	t := template.Must(template.New("timer-wrapper").Parse(timerTemplate))
 
-	timerSchedule, err := timeutil.ParseSchedule(app.Timer.Timer)
+	schedules, err := OnCalendarSchedules(app.Timer.Timer)
 	if err != nil {
 		return nil, err
 	}
 
-	schedules := generateOnCalendarSchedules(timerSchedule)
-
 	wrapperData := struct {
 		App             *snap.AppInfo
 		ServiceFileName string
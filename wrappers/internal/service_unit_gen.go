@@ -82,6 +82,36 @@ func ensureMinSystemdDuration(app *snap.AppInfo, in timeout.Timeout, field strin
 	return inDur
 }
 
+// generateResourcesDirectives returns the [Service] section directives for
+// the validated resources settings of an app.
+func generateResourcesDirectives(res *snap.ResourcesInfo) ([]string, error) {
+	if res == nil {
+		return nil, nil
+	}
+	var directives []string
+	if res.LimitNOFILE != nil {
+		directives = append(directives, fmt.Sprintf("LimitNOFILE=%d", *res.LimitNOFILE))
+	}
+	if res.LimitCORE != "" {
+		limit, err := res.LimitCOREBytes()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, fmt.Sprintf("LimitCORE=%d", limit))
+	}
+	if res.Nice != nil {
+		directives = append(directives, fmt.Sprintf("Nice=%d", *res.Nice))
+	}
+	if res.IOSchedulingClass != "" {
+		// the allowed classes are named as in systemd.exec(5)
+		directives = append(directives, fmt.Sprintf("IOSchedulingClass=%s", res.IOSchedulingClass))
+	}
+	if res.CPUWeight != nil {
+		directives = append(directives, fmt.Sprintf("CPUWeight=%d", *res.CPUWeight))
+	}
+	return directives, nil
+}
+
 func GenerateSnapServiceUnitFile(appInfo *snap.AppInfo, opts *SnapServicesUnitOptions) ([]byte, error) {
 	if opts == nil {
 		opts = &SnapServicesUnitOptions{}
@@ -205,13 +235,20 @@ KillSignal={{.KillSignal}}
 {{- if .OOMAdjustScore }}
 OOMScoreAdjust={{.OOMAdjustScore}}
 {{- end}}
+{{- range .ResourcesDirectives}}
+{{.}}
+{{- end}}
 {{- if .InterfaceServiceSnippets}}
 {{.InterfaceServiceSnippets}}
 {{- end}}
 {{- if .SliceUnit}}
 Slice={{.SliceUnit}}
 {{- end}}
-{{- if not (or .App.Sockets .App.Timer .App.ActivatesOn) }}
+{{- if .App.ActivatesOnDevice }}
+
+[Install]
+WantedBy={{.App.DeviceTargetName}}
+{{- else if not (or .App.Sockets .App.Timer .App.ActivatesOn .App.WatchPaths) }}
 
 [Install]
 WantedBy={{.ServicesTarget}}
@@ -232,9 +269,20 @@ WantedBy={{.ServicesTarget}}
 	// use score -900+vitalityRank, where vitalityRank starts at 1
 	// and considering snapd itself has OOMScoreAdjust=-900
 	const baseOOMAdjustScore = -900
-	var oomAdjustScore int
+	// nil when no score is set, so that an explicit 0 is still rendered
+	var oomAdjustScore *int
 	if opts.VitalityRank > 0 {
-		oomAdjustScore = baseOOMAdjustScore + opts.VitalityRank
+		score := baseOOMAdjustScore + opts.VitalityRank
+		oomAdjustScore = &score
+	} else if appInfo.Resources != nil && appInfo.Resources.OOMScoreAdjust != nil {
+		// the vitality rank set by the administrator takes precedence
+		// over the snap's own setting
+		oomAdjustScore = appInfo.Resources.OOMScoreAdjust
+	}
+
+	resourcesDirectives, err := generateResourcesDirectives(appInfo.Resources)
+	if err != nil {
+		return nil, err
 	}
 
 	var remain string
@@ -279,7 +327,7 @@ WantedBy={{.ServicesTarget}}
 		Remain                   string
 		KillMode                 string
 		KillSignal               string
-		OOMAdjustScore           int
+		OOMAdjustScore           *int
 		BusName                  string
 		SuccessExitStatus        []string
 		Before                   []string
@@ -289,6 +337,7 @@ WantedBy={{.ServicesTarget}}
 		InterfaceUnitSnippets    string
 		SliceUnit                string
 		LogNamespace             string
+		ResourcesDirectives      []string
 
 		Home    string
 		EnvVars string
@@ -319,6 +368,8 @@ WantedBy={{.ServicesTarget}}
 		BusName:           busName,
 		SuccessExitStatus: appInfo.SuccessExitStatus,
 
+		ResourcesDirectives: resourcesDirectives,
+
 		Before: generateServiceNames(appInfo.Snap, appInfo.Before),
 		After:  generateServiceNames(appInfo.Snap, appInfo.After),
 
@@ -345,7 +396,9 @@ WantedBy={{.ServicesTarget}}
 	// check the quota group slice
 	if opts.QuotaGroup != nil {
 		wrapperData.SliceUnit = opts.QuotaGroup.SliceFileName()
-		if opts.QuotaGroup.JournalQuotaSet() {
+		// journal namespaces are only available to system services, the
+		// logs of user daemons always go to the journal of the user
+		if opts.QuotaGroup.JournalQuotaSet() && appInfo.DaemonScope == snap.SystemDaemon {
 			wrapperData.LogNamespace = opts.QuotaGroup.JournalNamespaceName()
 			wrapperData.Requires = append([]string{opts.QuotaGroup.JournalSocketName()}, wrapperData.Requires...)
 			wrapperData.After = append([]string{opts.QuotaGroup.JournalSocketName()}, wrapperData.After...)
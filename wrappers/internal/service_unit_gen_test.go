@@ -734,6 +734,91 @@ WantedBy=multi-user.target
 `, mountUnitPrefix, mountUnitPrefix))
 }
 
+func (s *serviceUnitGenSuite) TestServiceResources(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: snap
+version: 0.3.4
+apps:
+  app:
+    command: bin/foo start
+    daemon: simple
+    resources:
+      limit-nofile: 65536
+      limit-core: 64MB
+      nice: 5
+      io-scheduling-class: best-effort
+      oom-score-adjust: 500
+      cpu-weight: 500
+`))
+	c.Assert(err, IsNil)
+	info.Revision = snap.R(44)
+	service := info.Apps["app"]
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(string(generatedWrapper), Equals, fmt.Sprintf(`[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Service for snap application snap.app
+Requires=%s-snap-44.mount
+Wants=network.target
+After=%s-snap-44.mount network.target snapd.apparmor.service
+X-Snappy=yes
+
+[Service]
+EnvironmentFile=-/etc/environment
+ExecStart=/usr/bin/snap run snap.app
+SyslogIdentifier=snap.app
+Restart=on-failure
+WorkingDirectory=/var/snap/snap/44
+TimeoutStopSec=30s
+Type=simple
+OOMScoreAdjust=500
+LimitNOFILE=65536
+LimitCORE=64000000
+Nice=5
+IOSchedulingClass=best-effort
+CPUWeight=500
+
+[Install]
+WantedBy=multi-user.target
+`, mountUnitPrefix, mountUnitPrefix))
+
+	// the vitality rank takes precedence over oom-score-adjust
+	opts := &internal.SnapServicesUnitOptions{VitalityRank: 1}
+	generatedWrapper, err = internal.GenerateSnapServiceUnitFile(service, opts)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), testutil.Contains, "\nOOMScoreAdjust=-899\nLimitNOFILE=65536\n")
+
+	// an explicit zero is still set
+	zero := 0
+	service.Resources.OOMScoreAdjust = &zero
+	generatedWrapper, err = internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), testutil.Contains, "\nOOMScoreAdjust=0\nLimitNOFILE=65536\n")
+
+	// and nothing is set without a score
+	service.Resources.OOMScoreAdjust = nil
+	generatedWrapper, err = internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "OOMScoreAdjust=")
+}
+
+func (s *serviceUnitGenSuite) TestServiceResourcesInvalid(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: snap
+version: 0.3.4
+apps:
+  app:
+    command: bin/foo start
+    daemon: simple
+    resources:
+      io-scheduling-class: realtime
+`))
+	c.Assert(err, IsNil)
+
+	_, err = internal.GenerateSnapServiceUnitFile(info.Apps["app"], nil)
+	c.Check(err, ErrorMatches, `resources io-scheduling-class "realtime" is not allowed as it can starve system services`)
+}
+
 func (s *serviceUnitGenSuite) TestQuotaGroupSlice(c *C) {
 	service := &snap.AppInfo{
 		Snap: &snap.Info{
@@ -821,6 +906,47 @@ WantedBy=multi-user.target
 `, mountUnitPrefix, mountUnitPrefix))
 }
 
+func (s *serviceUnitGenSuite) TestQuotaGroupLogNamespaceUserDaemon(c *C) {
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "simple",
+		DaemonScope: snap.UserDaemon,
+	}
+
+	grp, err := quota.NewGroup("foo", quota.NewResourcesBuilder().WithJournalNamespace().Build())
+	c.Assert(err, IsNil)
+
+	// user daemons are placed in the slice, but not in the journal namespace
+	opts := &internal.SnapServicesUnitOptions{QuotaGroup: grp}
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, opts)
+	c.Assert(err, IsNil)
+
+	c.Check(string(generatedWrapper), Equals, `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Service for snap application snap.app
+X-Snappy=yes
+
+[Service]
+EnvironmentFile=-/etc/environment
+ExecStart=/usr/bin/snap run snap.app
+SyslogIdentifier=snap.app
+Restart=on-failure
+WorkingDirectory=/var/snap/snap/44
+TimeoutStopSec=30s
+Type=simple
+Slice=snap.foo.slice
+
+[Install]
+WantedBy=default.target
+`)
+}
+
 func (s *serviceUnitGenSuite) TestQuotaGroupLogNamespaceInheritParent(c *C) {
 	service := &snap.AppInfo{
 		Snap: &snap.Info{
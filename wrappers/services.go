@@ -20,11 +20,13 @@
 package wrappers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/snapcore/snapd/dirs"
@@ -184,7 +186,20 @@ func reloadOrRestartServices(sysd systemd.Systemd, cli *userServiceClient, reloa
 }
 
 func serviceIsActivated(app *snap.AppInfo) bool {
-	return len(app.Sockets) > 0 || app.Timer != nil || len(app.ActivatesOn) > 0
+	return len(app.Sockets) > 0 || app.Timer != nil || len(app.ActivatesOn) > 0 || len(app.WatchPaths) > 0 || len(app.ActivatesOnDevice) > 0
+}
+
+// deviceActivatedServiceUnits returns the service units of the apps which are
+// activated by devices. Such services are never started directly, but must be
+// enabled to be hooked into their device target.
+func deviceActivatedServiceUnits(apps []*snap.AppInfo) []string {
+	var svcs []string
+	for _, app := range apps {
+		if len(app.ActivatesOnDevice) > 0 {
+			svcs = append(svcs, app.ServiceName())
+		}
+	}
+	return svcs
 }
 
 func serviceIsSlotActivated(app *snap.AppInfo) bool {
@@ -273,6 +288,7 @@ func StartServices(apps []*snap.AppInfo, disabledSvcs *DisabledServices, opts *S
 
 	sysApps, userApps := filterServicesForStart(apps, disabledSvcs, opts.Scope)
 	systemServices := serviceUnitsFromApps(sysApps, includeActivatedServices)
+	systemServicesToEnable := append(systemServices, deviceActivatedServiceUnits(sysApps)...)
 	userAppsForGlobalEnable := filterUserServicesNotInDisabledMap(disabledSvcs, userApps)
 	userServicesForGlobalEnable := serviceUnitsFromApps(userAppsForGlobalEnable, includeActivatedServices)
 	var undoStart bool
@@ -305,9 +321,9 @@ func StartServices(apps []*snap.AppInfo, disabledSvcs *DisabledServices, opts *S
 
 		// always disable if enable was requested, as we do this pre-start
 		if opts.Enable {
-			if len(systemServices) != 0 {
-				if e := systemSysd.DisableNoReload(systemServices); e != nil {
-					inter.Notify(fmt.Sprintf("While trying to disable previously enabled services %q: %v", systemServices, e))
+			if len(systemServicesToEnable) != 0 {
+				if e := systemSysd.DisableNoReload(systemServicesToEnable); e != nil {
+					inter.Notify(fmt.Sprintf("While trying to disable previously enabled services %q: %v", systemServicesToEnable, e))
 				}
 				if e := systemSysd.DaemonReload(); e != nil {
 					inter.Notify(fmt.Sprintf("While trying to do daemon-reload: %v", e))
@@ -324,9 +340,9 @@ func StartServices(apps []*snap.AppInfo, disabledSvcs *DisabledServices, opts *S
 	}()
 
 	if opts.Enable {
-		timings.Run(tm, "enable-services", fmt.Sprintf("enable services %q", systemServices), func(nested timings.Measurer) {
-			if len(systemServices) != 0 {
-				if err = systemSysd.EnableNoReload(systemServices); err != nil {
+		timings.Run(tm, "enable-services", fmt.Sprintf("enable services %q", systemServicesToEnable), func(nested timings.Measurer) {
+			if len(systemServicesToEnable) != 0 {
+				if err = systemSysd.EnableNoReload(systemServicesToEnable); err != nil {
 					return
 				}
 				if err = systemSysd.DaemonReload(); err != nil {
@@ -444,6 +460,26 @@ func tryFileUpdate(path string, desiredContent []byte) (old *osutil.MemoryFileSt
 	}
 }
 
+// tryFileRemove removes the file at the given path, returning its previous
+// state to roll back to, if it existed.
+func tryFileRemove(path string) (old *osutil.MemoryFileState, removed bool, err error) {
+	st, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, false, err
+	}
+	return &osutil.MemoryFileState{Content: b, Mode: st.Mode()}, true, nil
+}
+
 type SnapServiceOptions struct {
 	// VitalityRank is the rank of all services in the specified snap used by
 	// the OOM killer when OOM conditions are reached.
@@ -451,11 +487,29 @@ type SnapServiceOptions struct {
 
 	// QuotaGroup is the quota group for the specified snap.
 	QuotaGroup *quota.Group
+
+	// Overrides maps the names of services of the specified snap to the
+	// settings overridden for them by the system administrator.
+	Overrides map[string]*ServiceOverrides
+}
+
+// ServiceOverrides holds the settings of a service overridden by the system
+// administrator. They are applied as drop-ins on top of the units generated
+// from the snap.yaml, zero values leave the respective setting untouched.
+type ServiceOverrides struct {
+	RestartCondition snap.RestartCondition
+	RestartDelay     time.Duration
+	// Timer replaces the schedule of the timer of the service, it is
+	// ignored for services without a timer.
+	Timer       string
+	Environment map[string]string
 }
 
 // ObserveChangeCallback can be invoked by EnsureSnapServices to observe
 // the previous content of a unit and the new on a change.
-// unitType can be "service", "socket", "timer". name is empty for a timer.
+// unitType can be "service", "socket", "timer", "path", "target" or
+// "override". name is empty for a timer, a path or a target, and is "service"
+// or "timer" for the drop-in overriding the respective unit.
 type ObserveChangeCallback func(app *snap.AppInfo, grp *quota.Group, unitType string, name, old, new string)
 
 // EnsureSnapServicesOptions is the set of options applying to the
@@ -496,6 +550,10 @@ type ensureSnapServicesContext struct {
 	sysd                     systemd.Systemd
 	systemDaemonReloadNeeded bool
 	userDaemonReloadNeeded   bool
+	// userQuotaGroups is the set of quota groups of snaps with user
+	// daemons, for which slices are needed in the user instances of
+	// systemd as well.
+	userQuotaGroups *quota.QuotaGroupSet
 	// modifiedUnits is the set of units that were modified and the previous
 	// state of the unit before modification that we can roll back to if there
 	// are any issues.
@@ -562,9 +620,16 @@ func (es *ensureSnapServicesContext) reloadModified() error {
 
 // ensureSnapServiceSystemdUnits takes care of writing .service files for all services
 // registered in snap.Info apps.
-func (es *ensureSnapServicesContext) ensureSnapServiceSystemdUnits(snapInfo *snap.Info, opts *internal.SnapServicesUnitOptions) error {
+func (es *ensureSnapServicesContext) ensureSnapServiceSystemdUnits(snapInfo *snap.Info, opts *internal.SnapServicesUnitOptions, overrides map[string]*ServiceOverrides) error {
 	handleFileModification := func(app *snap.AppInfo, unitType string, name, path string, content []byte) error {
-		old, modifiedFile, err := tryFileUpdate(path, content)
+		var old *osutil.MemoryFileState
+		var modifiedFile bool
+		var err error
+		if content != nil {
+			old, modifiedFile, err = tryFileUpdate(path, content)
+		} else {
+			old, modifiedFile, err = tryFileRemove(path)
+		}
 		if err != nil {
 			return err
 		}
@@ -666,6 +731,41 @@ func (es *ensureSnapServicesContext) ensureSnapServiceSystemdUnits(snapInfo *sna
 				return err
 			}
 		}
+
+		if len(svc.WatchPaths) > 0 {
+			content, err := internal.GenerateSnapServicePathUnitFile(svc)
+			if err != nil {
+				return err
+			}
+			path := svc.WatchPathsFile()
+			if err := handleFileModification(svc, "path", "", path, content); err != nil {
+				return err
+			}
+		}
+
+		if len(svc.ActivatesOnDevice) > 0 {
+			content := internal.GenerateSnapServiceDeviceTargetUnitFile(svc)
+			path := svc.DeviceTargetFile()
+			if err := handleFileModification(svc, "target", "", path, content); err != nil {
+				return err
+			}
+		}
+
+		// overrides no longer set are removed by writing no content
+		ovr := overrides[svc.Name]
+		content = generateServiceOverridesDropIn(ovr)
+		if err := handleFileModification(svc, "override", "service", serviceOverridesDropInFile(svc), content); err != nil {
+			return err
+		}
+		if svc.Timer != nil {
+			content, err := generateTimerOverridesDropIn(ovr)
+			if err != nil {
+				return err
+			}
+			if err := handleFileModification(svc, "override", "timer", timerOverridesDropInFile(svc.Timer), content); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -705,9 +805,14 @@ func (es *ensureSnapServicesContext) ensureSnapsSystemdServices() (*quota.QuotaG
 				// in the quota group tree
 				return nil, err
 			}
+			if hasUserDaemons(s) {
+				if err := es.userQuotaGroups.AddAllNecessaryGroups(snapSvcOpts.QuotaGroup); err != nil {
+					return nil, err
+				}
+			}
 		}
 
-		if err := es.ensureSnapServiceSystemdUnits(s, genServiceOpts); err != nil {
+		if err := es.ensureSnapServiceSystemdUnits(s, genServiceOpts, snapSvcOpts.Overrides); err != nil {
 			return nil, err
 		}
 	}
@@ -715,7 +820,7 @@ func (es *ensureSnapServicesContext) ensureSnapsSystemdServices() (*quota.QuotaG
 }
 
 func (es *ensureSnapServicesContext) ensureSnapSlices(quotaGroups *quota.QuotaGroupSet) error {
-	handleSliceModification := func(grp *quota.Group, path string, content []byte) error {
+	handleSliceModification := func(grp *quota.Group, unitType string, path string, content []byte) error {
 		old, modifiedFile, err := tryFileUpdate(path, content)
 		if err != nil {
 			return err
@@ -727,15 +832,19 @@ func (es *ensureSnapServicesContext) ensureSnapSlices(quotaGroups *quota.QuotaGr
 				if old != nil {
 					oldContent = old.Content
 				}
-				es.observeChange(nil, grp, "slice", grp.Name, string(oldContent), string(content))
+				es.observeChange(nil, grp, unitType, grp.Name, string(oldContent), string(content))
 			}
 
 			es.modifiedUnits[path] = old
 
-			// also mark that we need to reload the system instance of systemd
-			// TODO: also handle reloading the user instance of systemd when
-			// needed
-			es.systemDaemonReloadNeeded = true
+			// also mark that we need to reload either the system or
+			// user instance of systemd
+			switch unitType {
+			case "slice":
+				es.systemDaemonReloadNeeded = true
+			case "user-slice":
+				es.userDaemonReloadNeeded = true
+			}
 		}
 
 		return nil
@@ -747,13 +856,37 @@ func (es *ensureSnapServicesContext) ensureSnapSlices(quotaGroups *quota.QuotaGr
 
 		sliceFileName := grp.SliceFileName()
 		path := filepath.Join(dirs.SnapServicesDir, sliceFileName)
-		if err := handleSliceModification(grp, path, content); err != nil {
+		if err := handleSliceModification(grp, "slice", path, content); err != nil {
+			return err
+		}
+	}
+
+	// user daemons run in the user instances of systemd, which need
+	// their own copy of the slices; these are started implicitly by
+	// the services placed in them
+	for _, grp := range es.userQuotaGroups.AllQuotaGroups() {
+		content := internal.GenerateQuotaSliceUnitFile(grp)
+
+		sliceFileName := grp.SliceFileName()
+		path := filepath.Join(dirs.SnapUserServicesDir, sliceFileName)
+		if err := handleSliceModification(grp, "user-slice", path, content); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// hasUserDaemons returns whether the snap has any services running in the
+// user instances of systemd.
+func hasUserDaemons(s *snap.Info) bool {
+	for _, app := range s.Apps {
+		if app.IsService() && app.DaemonScope == snap.UserDaemon {
+			return true
+		}
+	}
+	return false
+}
+
 func (es *ensureSnapServicesContext) ensureSnapJournaldUnits(quotaGroups *quota.QuotaGroupSet) error {
 	handleJournalModification := func(grp *quota.Group, path string, content []byte) error {
 		old, fileModified, err := tryFileUpdate(path, content)
@@ -870,6 +1003,8 @@ func EnsureSnapServices(snaps map[*snap.Info]*SnapServiceOptions, opts *EnsureSn
 		inter:         inter,
 		sysd:          systemd.New(systemd.SystemMode, inter),
 		modifiedUnits: make(map[string]*osutil.MemoryFileState),
+
+		userQuotaGroups: &quota.QuotaGroupSet{},
 	}
 
 	defer func() {
@@ -1098,9 +1233,155 @@ func RemoveQuotaGroup(grp *quota.Group, inter Interacter) error {
 			return err
 		}
 	}
+
+	// remove the slice file of the user instances, if the group had
+	// any user daemons
+	err = os.Remove(filepath.Join(dirs.SnapUserServicesDir, grp.SliceFileName()))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err == nil {
+		if err := userDaemonReload(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// serviceDependenciesDropInFile returns the path of the drop-in file ordering
+// the service of the given app after the services of connected snaps.
+func serviceDependenciesDropInFile(app *snap.AppInfo) string {
+	return filepath.Join(app.ServiceFile()+".d", "snap-connected-dependencies.conf")
+}
+
+// serviceOverridesDropInFile returns the path of the drop-in file applying
+// the settings overridden by the system administrator to the service of the
+// given app.
+func serviceOverridesDropInFile(app *snap.AppInfo) string {
+	return filepath.Join(app.ServiceFile()+".d", "snap-overrides.conf")
+}
+
+// timerOverridesDropInFile returns the path of the drop-in file applying the
+// schedule overridden by the system administrator to the given timer.
+func timerOverridesDropInFile(timer *snap.TimerInfo) string {
+	return filepath.Join(timer.File()+".d", "snap-overrides.conf")
+}
+
+// escapeUnitValue escapes a value so that it can be used within double quotes
+// in a systemd unit, see systemd.syntax(7) and systemd.unit(5) for specifiers.
+func escapeUnitValue(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "\n", `\n`)
+	return r.Replace(value)
+}
+
+// generateServiceOverridesDropIn returns the content of the drop-in applying
+// the given overrides to a service, or nil if nothing is overridden.
+func generateServiceOverridesDropIn(ovr *ServiceOverrides) []byte {
+	if ovr == nil {
+		return nil
+	}
+	var directives []string
+	if ovr.RestartCondition != "" {
+		directives = append(directives, fmt.Sprintf("Restart=%s", ovr.RestartCondition))
+	}
+	if ovr.RestartDelay > 0 {
+		directives = append(directives, fmt.Sprintf("RestartSec=%s", ovr.RestartDelay))
+	}
+	names := make([]string, 0, len(ovr.Environment))
+	for name := range ovr.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		directives = append(directives, fmt.Sprintf(`Environment="%s=%s"`, name, escapeUnitValue(ovr.Environment[name])))
+	}
+	if len(directives) == 0 {
+		return nil
+	}
+	return []byte(fmt.Sprintf("[Service]\n# Auto-generated, DO NOT EDIT\n%s\n", strings.Join(directives, "\n")))
+}
+
+// generateTimerOverridesDropIn returns the content of the drop-in replacing
+// the schedule of a timer, or nil if it is not overridden.
+func generateTimerOverridesDropIn(ovr *ServiceOverrides) ([]byte, error) {
+	if ovr == nil || ovr.Timer == "" {
+		return nil, nil
+	}
+	schedules, err := internal.OnCalendarSchedules(ovr.Timer)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	// the empty assignment resets the schedules of the timer unit
+	buf.WriteString("[Timer]\n# Auto-generated, DO NOT EDIT\nOnCalendar=\n")
+	for _, schedule := range schedules {
+		fmt.Fprintf(&buf, "OnCalendar=%s\n", schedule)
+	}
+	return buf.Bytes(), nil
+}
+
+// EnsureSnapServiceDependencies makes sure the services of the given snap
+// start after the services of other snaps as described by deps, which maps
+// the names of apps to the service units they wait for. Previously written
+// dependencies of services which are not in deps are removed. The services
+// are not restarted, the dependencies apply on their next start. Of the
+// options only Preseeding is taken into account.
+func EnsureSnapServiceDependencies(s *snap.Info, deps map[string][]string, opts *EnsureSnapServicesOptions, inter Interacter) error {
+	if opts == nil {
+		opts = &EnsureSnapServicesOptions{}
+	}
+
+	var reloadNeeded bool
+	for _, app := range s.Services() {
+		if app.DaemonScope != snap.SystemDaemon {
+			continue
+		}
+		path := serviceDependenciesDropInFile(app)
+		units := deps[app.Name]
+		if len(units) == 0 {
+			err := os.Remove(path)
+			if err == nil {
+				reloadNeeded = true
+				// the drop-in directory is only removed if empty
+				os.Remove(filepath.Dir(path))
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+
+		sorted := strutil.Deduplicate(units)
+		sort.Strings(sorted)
+		// only the ordering is set, starting the service must not pull in
+		// services that are disabled or stopped on purpose
+		content := fmt.Sprintf(`[Unit]
+# Auto-generated, DO NOT EDIT
+After=%s
+`, strings.Join(sorted, " "))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		err := osutil.EnsureFileState(path, &osutil.MemoryFileState{
+			Content: []byte(content),
+			Mode:    0644,
+		})
+		if err == osutil.ErrSameState {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		reloadNeeded = true
+	}
+
+	if !reloadNeeded || opts.Preseeding {
+		return nil
+	}
+	return systemd.New(systemd.SystemMode, inter).DaemonReload()
+}
+
 // RemoveSnapServices disables and removes service units for the applications
 // from the snap which are services. The optional flag indicates whether
 // services are removed as part of undoing of first install of a given snap.
@@ -1156,6 +1437,26 @@ func RemoveSnapServices(s *snap.Info, inter Interacter) error {
 			systemUnitFiles = append(systemUnitFiles, path)
 		}
 
+		if len(app.WatchPaths) > 0 {
+			path := app.WatchPathsFile()
+
+			pathUnitName := filepath.Base(path)
+			logger.Noticef("RemoveSnapServices - path %s", pathUnitName)
+			switch app.DaemonScope {
+			case snap.SystemDaemon:
+				systemUnits = append(systemUnits, pathUnitName)
+			case snap.UserDaemon:
+				userUnits = append(userUnits, pathUnitName)
+			}
+			systemUnitFiles = append(systemUnitFiles, path)
+		}
+
+		if len(app.ActivatesOnDevice) > 0 {
+			// the target has no [Install] section, disabling the
+			// service below unhooks it from the target
+			systemUnitFiles = append(systemUnitFiles, app.DeviceTargetFile())
+		}
+
 		logger.Noticef("RemoveSnapServices - disabling %s", serviceName)
 		switch app.DaemonScope {
 		case snap.SystemDaemon:
@@ -1164,6 +1465,13 @@ func RemoveSnapServices(s *snap.Info, inter Interacter) error {
 			userUnits = append(userUnits, serviceName)
 		}
 		systemUnitFiles = append(systemUnitFiles, app.ServiceFile())
+		if len(app.AfterConnected) > 0 {
+			systemUnitFiles = append(systemUnitFiles, serviceDependenciesDropInFile(app))
+		}
+		systemUnitFiles = append(systemUnitFiles, serviceOverridesDropInFile(app))
+		if app.Timer != nil {
+			systemUnitFiles = append(systemUnitFiles, timerOverridesDropInFile(app.Timer))
+		}
 	}
 
 	// disable all collected systemd units
@@ -1182,6 +1490,13 @@ func RemoveSnapServices(s *snap.Info, inter Interacter) error {
 			logger.Noticef("Failed to remove socket file %q: %v", systemUnitFile, err)
 		}
 	}
+	// remove the drop-in directories of the services, when empty
+	for _, app := range s.Services() {
+		os.Remove(app.ServiceFile() + ".d")
+		if app.Timer != nil {
+			os.Remove(app.Timer.File() + ".d")
+		}
+	}
 
 	// only reload if we actually had services
 	if removedSystem {
@@ -333,6 +333,61 @@ TasksMax=%[5]d
 	c.Assert(svcFile, testutil.FileEquals, svcContent)
 }
 
+func (s *servicesTestSuite) TestEnsureSnapServicesWithQuotasUserDaemons(c *C) {
+	info := snaptest.MockSnap(c, packageHelloNoSrv+`
+ svc1:
+  daemon: simple
+  daemon-scope: user
+`, &snap.SideInfo{Revision: snap.R(12)})
+	svcFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/user/snap.hello-snap.svc1.service")
+	systemSliceFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap.foogroup.slice")
+	userSliceFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/user/snap.foogroup.slice")
+
+	resourceLimits := quota.NewResourcesBuilder().
+		WithMemoryLimit(quantity.SizeGiB).
+		WithJournalNamespace().
+		Build()
+	grp, err := quota.NewGroup("foogroup", resourceLimits)
+	c.Assert(err, IsNil)
+
+	m := map[*snap.Info]*wrappers.SnapServiceOptions{
+		info: {QuotaGroup: grp},
+	}
+
+	var unitTypes []string
+	observe := func(app *snap.AppInfo, grp *quota.Group, unitType, name, old, new string) {
+		unitTypes = append(unitTypes, unitType)
+	}
+	err = wrappers.EnsureSnapServices(m, nil, observe, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(unitTypes, DeepEquals, []string{"service", "slice", "user-slice", "journald", "service"})
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"daemon-reload"},
+		{"--user", "daemon-reload"},
+	})
+
+	// the user daemon is placed in the slice of the user instance of
+	// systemd, but logs to the journal of the user
+	c.Check(svcFile, testutil.FileContains, "\nSlice=snap.foogroup.slice\n")
+	c.Check(svcFile, Not(testutil.FileContains), "SNAPD_LOG_NAMESPACE")
+	c.Check(svcFile, Not(testutil.FileContains), "systemd-journald@")
+	c.Check(systemSliceFile, testutil.FileContains, "MemoryMax=1073741824\n")
+	sliceContent, err := os.ReadFile(systemSliceFile)
+	c.Assert(err, IsNil)
+	c.Check(userSliceFile, testutil.FileEquals, string(sliceContent))
+
+	// removing the group removes both slices
+	s.sysdLog = nil
+	err = wrappers.RemoveQuotaGroup(grp, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(systemSliceFile, testutil.FileAbsent)
+	c.Check(userSliceFile, testutil.FileAbsent)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"daemon-reload"},
+		{"--user", "daemon-reload"},
+	})
+}
+
 func (s *servicesTestSuite) TestEnsureSnapServicesWithZeroCpuCountQuotas(c *C) {
 	// Kind of a special case, if the cpu count is zero it needs to automatically scale
 	// at the moment of writing the service file to the current number of cpu cores
@@ -4806,6 +4861,283 @@ func (s *servicesTestSuite) TestAddRemoveSnapWithTimersAddsRemovesTimerFiles(c *
 	c.Check(osutil.FileExists(app.ServiceFile()), Equals, false)
 }
 
+func (s *servicesTestSuite) TestStartSnapWatchPathsEnableStart(c *C) {
+	svc1Name := "snap.hello-snap.svc1.service"
+	svc2Path := "snap.hello-snap.svc2.path"
+
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+  watch-paths:
+   - path: $SNAP_COMMON/incoming
+     condition: directory-not-empty
+`, &snap.SideInfo{Revision: snap.R(12)})
+
+	// fix the apps order to make the test stable
+	apps := []*snap.AppInfo{info.Apps["svc1"], info.Apps["svc2"]}
+	opts := &wrappers.StartServicesOptions{Enable: true}
+	err := wrappers.StartServices(apps, nil, opts, &progress.Null, s.perfTimings)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"--no-reload", "enable", svc2Path, svc1Name},
+		{"daemon-reload"},
+		{"start", svc2Path},
+		{"start", svc1Name},
+	}, Commentf("calls: %v", s.sysdLog))
+}
+
+func (s *servicesTestSuite) TestStartSnapDeviceActivatedEnableOnly(c *C) {
+	svc1Name := "snap.hello-snap.svc1.service"
+	svc2Name := "snap.hello-snap.svc2.service"
+
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+  activates-on-device: [serial]
+plugs:
+ serial:
+  interface: serial-port
+`, &snap.SideInfo{Revision: snap.R(12)})
+
+	// fix the apps order to make the test stable
+	apps := []*snap.AppInfo{info.Apps["svc1"], info.Apps["svc2"]}
+	opts := &wrappers.StartServicesOptions{Enable: true}
+	err := wrappers.StartServices(apps, nil, opts, &progress.Null, s.perfTimings)
+	c.Assert(err, IsNil)
+	// the device activated service is enabled, but only started by a
+	// device appearing
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"--no-reload", "enable", svc1Name, svc2Name},
+		{"daemon-reload"},
+		{"start", svc1Name},
+	}, Commentf("calls: %v", s.sysdLog))
+
+	// disabling the service removes it from the device target
+	c.Assert(s.addSnapServices(info, false), IsNil)
+	s.sysdLog = nil
+	err = wrappers.StopServices([]*snap.AppInfo{info.Apps["svc2"]}, &wrappers.StopServicesOptions{Disable: true}, "", &progress.Null, s.perfTimings)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"stop", svc2Name},
+		{"show", "--property=ActiveState", svc2Name},
+		{"--no-reload", "disable", svc2Name},
+		{"daemon-reload"},
+	}, Commentf("calls: %v", s.sysdLog))
+}
+
+func (s *servicesTestSuite) TestAddRemoveSnapWithWatchPathsAndDevices(c *C) {
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+  watch-paths:
+   - path: $SNAP_DATA/config
+     condition: changed
+ svc3:
+  command: bin/hello
+  daemon: simple
+  activates-on-device: [serial]
+plugs:
+ serial:
+  interface: serial-port
+`, &snap.SideInfo{Revision: snap.R(12)})
+
+	err := s.addSnapServices(info, false)
+	c.Assert(err, IsNil)
+
+	svc2 := info.Apps["svc2"]
+	svc3 := info.Apps["svc3"]
+	c.Check(svc2.WatchPathsFile(), testutil.FileContains, "PathChanged="+filepath.Join(dirs.SnapDataDir, "hello-snap/12/config"))
+	c.Check(osutil.FileExists(svc2.ServiceFile()), Equals, true)
+	c.Check(svc3.DeviceTargetFile(), testutil.FileContains, "StopWhenUnneeded=yes")
+	c.Check(svc3.ServiceFile(), testutil.FileContains, "WantedBy=snap.hello-snap.svc3.device.target")
+
+	s.sysdLog = nil
+	err = wrappers.RemoveSnapServices(info, &progress.Null)
+	c.Assert(err, IsNil)
+
+	c.Check(osutil.FileExists(svc2.WatchPathsFile()), Equals, false)
+	c.Check(osutil.FileExists(svc2.ServiceFile()), Equals, false)
+	c.Check(osutil.FileExists(svc3.DeviceTargetFile()), Equals, false)
+	c.Check(osutil.FileExists(svc3.ServiceFile()), Equals, false)
+
+	c.Assert(s.sysdLog, HasLen, 2, Commentf("calls: %v", s.sysdLog))
+	c.Check(s.sysdLog[0][:2], DeepEquals, []string{"--no-reload", "disable"})
+	c.Check(s.sysdLog[0][2:], testutil.DeepUnsortedMatches, []string{
+		"snap.hello-snap.svc1.service",
+		"snap.hello-snap.svc2.path",
+		"snap.hello-snap.svc2.service",
+		"snap.hello-snap.svc3.service",
+	})
+}
+
+func (s *servicesTestSuite) TestEnsureSnapServiceDependencies(c *C) {
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+  after-connected: [db]
+plugs:
+ db:
+  interface: network
+`, &snap.SideInfo{Revision: snap.R(12)})
+	svc2 := info.Apps["svc2"]
+	dropIn := svc2.ServiceFile() + ".d/snap-connected-dependencies.conf"
+
+	deps := map[string][]string{
+		"svc2": {"snap.db.server.service", "snap.db.cache.service", "snap.db.server.service"},
+	}
+	err := wrappers.EnsureSnapServiceDependencies(info, deps, nil, &progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(dropIn, testutil.FileEquals, `[Unit]
+# Auto-generated, DO NOT EDIT
+After=snap.db.cache.service snap.db.server.service
+`)
+	c.Check(s.sysdLog, DeepEquals, [][]string{{"daemon-reload"}})
+
+	// no changes, no reload
+	s.sysdLog = nil
+	err = wrappers.EnsureSnapServiceDependencies(info, deps, nil, &progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, HasLen, 0)
+
+	// no reload when preseeding
+	deps["svc2"] = []string{"snap.db.server.service"}
+	err = wrappers.EnsureSnapServiceDependencies(info, deps, &wrappers.EnsureSnapServicesOptions{Preseeding: true}, &progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(dropIn, testutil.FileContains, "After=snap.db.server.service\n")
+	c.Check(s.sysdLog, HasLen, 0)
+
+	// dependencies are removed once the services are disconnected
+	err = wrappers.EnsureSnapServiceDependencies(info, nil, nil, &progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(osutil.FileExists(dropIn), Equals, false)
+	c.Check(osutil.FileExists(filepath.Dir(dropIn)), Equals, false)
+	c.Check(s.sysdLog, DeepEquals, [][]string{{"daemon-reload"}})
+}
+
+func (s *servicesTestSuite) TestRemoveSnapServicesRemovesDependencies(c *C) {
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+  after-connected: [db]
+plugs:
+ db:
+  interface: network
+`, &snap.SideInfo{Revision: snap.R(12)})
+	svc2 := info.Apps["svc2"]
+
+	c.Assert(s.addSnapServices(info, false), IsNil)
+	deps := map[string][]string{"svc2": {"snap.db.server.service"}}
+	c.Assert(wrappers.EnsureSnapServiceDependencies(info, deps, nil, &progress.Null), IsNil)
+
+	err := wrappers.RemoveSnapServices(info, &progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(osutil.FileExists(svc2.ServiceFile()), Equals, false)
+	c.Check(osutil.FileExists(svc2.ServiceFile()+".d"), Equals, false)
+}
+
+func (s *servicesTestSuite) TestEnsureSnapServicesOverrides(c *C) {
+	seen := make(map[string]bool)
+	cb := func(app *snap.AppInfo, grp *quota.Group, unitType, name string, old, new string) {
+		seen[fmt.Sprintf("%s:%s:%s:%s", app.Snap.InstanceName(), app.Name, unitType, name)] = new != ""
+	}
+
+	info := snaptest.MockSnap(c, packageHello+`
+  timer: 10:00-12:00
+`, &snap.SideInfo{Revision: snap.R(12)})
+	svc1 := info.Apps["svc1"]
+	serviceDropIn := svc1.ServiceFile() + ".d/snap-overrides.conf"
+	timerDropIn := svc1.Timer.File() + ".d/snap-overrides.conf"
+
+	m := map[*snap.Info]*wrappers.SnapServiceOptions{
+		info: {Overrides: map[string]*wrappers.ServiceOverrides{
+			"svc1": {
+				RestartCondition: snap.RestartOnFailure,
+				RestartDelay:     10 * time.Second,
+				Timer:            "mon,23:00",
+				Environment: map[string]string{
+					"FOO":   "bar",
+					"QUOTE": `say "100%"`,
+				},
+			},
+		}},
+	}
+	err := wrappers.EnsureSnapServices(m, nil, cb, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(serviceDropIn, testutil.FileEquals, `[Service]
+# Auto-generated, DO NOT EDIT
+Restart=on-failure
+RestartSec=10s
+Environment="FOO=bar"
+Environment="QUOTE=say \"100%%\""
+`)
+	c.Check(timerDropIn, testutil.FileEquals, `[Timer]
+# Auto-generated, DO NOT EDIT
+OnCalendar=
+OnCalendar=Mon *-*-* 23:00
+`)
+	c.Check(seen["hello-snap:svc1:override:service"], Equals, true)
+	c.Check(seen["hello-snap:svc1:override:timer"], Equals, true)
+	c.Check(s.sysdLog, DeepEquals, [][]string{{"daemon-reload"}})
+
+	// no changes, no reload
+	s.sysdLog = nil
+	err = wrappers.EnsureSnapServices(m, nil, nil, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, HasLen, 0)
+
+	// only the environment is still overridden
+	m[info].Overrides["svc1"] = &wrappers.ServiceOverrides{
+		Environment: map[string]string{"FOO": "baz"},
+	}
+	seen = make(map[string]bool)
+	err = wrappers.EnsureSnapServices(m, nil, cb, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(serviceDropIn, testutil.FileEquals, `[Service]
+# Auto-generated, DO NOT EDIT
+Environment="FOO=baz"
+`)
+	c.Check(osutil.FileExists(timerDropIn), Equals, false)
+	c.Check(seen, DeepEquals, map[string]bool{
+		"hello-snap:svc1:override:service": true,
+		"hello-snap:svc1:override:timer":   false,
+	})
+	c.Check(s.sysdLog, DeepEquals, [][]string{{"daemon-reload"}})
+
+	// overrides are removed when no longer set
+	m[info].Overrides = nil
+	err = wrappers.EnsureSnapServices(m, nil, nil, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(osutil.FileExists(serviceDropIn), Equals, false)
+
+	// and the drop-in directories with the snap services
+	c.Assert(wrappers.RemoveSnapServices(info, &progress.Null), IsNil)
+	c.Check(osutil.FileExists(filepath.Dir(serviceDropIn)), Equals, false)
+	c.Check(osutil.FileExists(filepath.Dir(timerDropIn)), Equals, false)
+}
+
+func (s *servicesTestSuite) TestEnsureSnapServicesOverridesBadTimer(c *C) {
+	info := snaptest.MockSnap(c, packageHello+`
+  timer: 10:00-12:00
+`, &snap.SideInfo{Revision: snap.R(12)})
+	svc1 := info.Apps["svc1"]
+
+	m := map[*snap.Info]*wrappers.SnapServiceOptions{
+		info: {Overrides: map[string]*wrappers.ServiceOverrides{
+			"svc1": {RestartDelay: time.Second, Timer: "mon,25:00"},
+		}},
+	}
+	err := wrappers.EnsureSnapServices(m, nil, nil, progress.Null)
+	c.Assert(err, ErrorMatches, `cannot parse "25:00": .*`)
+	// nothing is left behind
+	c.Check(osutil.FileExists(svc1.ServiceFile()), Equals, false)
+	c.Check(osutil.FileExists(svc1.ServiceFile()+".d/snap-overrides.conf"), Equals, false)
+}
+
 func (s *servicesTestSuite) TestFailedAddSnapCleansUp(c *C) {
 	info := snaptest.MockSnap(c, packageHello+`
  svc2: